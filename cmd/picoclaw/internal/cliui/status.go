@@ -25,6 +25,8 @@ type StatusReport struct {
 	Model         string
 	Providers     []ProviderRow
 	OAuthLines    []string // each full line "provider (method): state"
+	// IsolationLines summarize which subprocess isolation features are enforceable.
+	IsolationLines []string
 }
 
 // PrintStatus renders picoclaw status (plain or fancy).
@@ -58,6 +60,12 @@ func printStatusPlain(r StatusReport) {
 				fmt.Printf("  %s\n", line)
 			}
 		}
+		if len(r.IsolationLines) > 0 {
+			fmt.Println("\nIsolation:")
+			for _, line := range r.IsolationLines {
+				fmt.Printf("  %s\n", line)
+			}
+		}
 	}
 }
 
@@ -107,6 +115,16 @@ func printStatusFancy(r StatusReport) {
 		fmt.Println()
 		fmt.Println(borderStyle().Width(inner).Render(ob.String()))
 	}
+
+	if len(r.IsolationLines) > 0 && r.ConfigOK {
+		var ib strings.Builder
+		ib.WriteString(titleBarStyle().Render("Isolation") + "\n\n")
+		for _, line := range r.IsolationLines {
+			ib.WriteString("  • " + line + "\n")
+		}
+		fmt.Println()
+		fmt.Println(borderStyle().Width(inner).Render(ib.String()))
+	}
 }
 
 func pathStatusPanel(r StatusReport, inner int) string {
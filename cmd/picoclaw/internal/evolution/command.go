@@ -0,0 +1,71 @@
+package evolution
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/checkpoint"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/evolution"
+	"github.com/sipeed/picoclaw/pkg/state"
+)
+
+func NewEvolutionCommand() *cobra.Command {
+	var cfg *config.Config
+
+	cmd := &cobra.Command{
+		Use:   "evolution",
+		Short: "Inspect and roll back evolved skills",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			var err error
+			cfg, err = internal.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cfgFn := func() *config.Config { return cfg }
+	cmd.AddCommand(
+		newHistoryCommand(cfgFn),
+		newRollbackCommand(cfgFn),
+	)
+
+	return cmd
+}
+
+// newRuntime opens the evolution state of the default workspace with the
+// applier the gateway uses, so rollbacks are checkpointed like applies.
+func newRuntime(cfg *config.Config) (*evolution.Runtime, *evolution.Store, string, error) {
+	workspace := cfg.WorkspacePath()
+	paths := evolution.NewPaths(workspace, cfg.Evolution.StateDir)
+	applier := evolution.NewApplier(paths, nil)
+	if cfg.Tools.IsToolEnabled("checkpoints") {
+		c := cfg.Tools.Checkpoints
+		applier = applier.WithCheckpoints(checkpoint.NewStore(
+			checkpoint.DefaultDir(state.ResolveDir(workspace)),
+			checkpoint.Options{
+				MaxTotalBytes: int64(c.MaxTotalMB) << 20,
+				MaxFileBytes:  int64(c.MaxFileKB) << 10,
+				MaxCount:      c.MaxCount,
+			},
+		))
+	}
+	store := evolution.NewStore(paths)
+	rt, err := evolution.NewRuntime(evolution.RuntimeOptions{
+		Config:  cfg.Evolution,
+		Store:   store,
+		Applier: applier,
+	})
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return rt, store, workspace, nil
+}
@@ -0,0 +1,132 @@
+package evolution
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/evolution"
+)
+
+func newHistoryCommand(cfgFn func() *config.Config) *cobra.Command {
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "history [skill]",
+		Short: "Show the version history of evolved skills",
+		Long: `Without a skill, list every skill evolution has changed with its current
+version. With a skill, list each version evolution applied or rolled back to,
+who did it, the records it was learned from and its success ratio.`,
+		Example: `picoclaw evolution history
+picoclaw evolution history weather --json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, store, workspace, err := newRuntime(cfgFn())
+			if err != nil {
+				return err
+			}
+			if len(args) == 0 {
+				return historyListCmd(cmd.OutOrStdout(), store, workspace, jsonOutput)
+			}
+			return historyShowCmd(cmd.OutOrStdout(), store, args[0], jsonOutput)
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print the history as JSON")
+
+	return cmd
+}
+
+func historyListCmd(out io.Writer, store *evolution.Store, workspace string, jsonOutput bool) error {
+	profiles, err := store.LoadProfiles()
+	if err != nil {
+		return err
+	}
+	evolved := make([]evolution.SkillProfile, 0, len(profiles))
+	for _, profile := range profiles {
+		if profile.WorkspaceID == workspace && len(profile.Versions()) > 0 {
+			evolved = append(evolved, profile)
+		}
+	}
+	sort.Slice(evolved, func(i, j int) bool { return evolved[i].SkillName < evolved[j].SkillName })
+
+	if jsonOutput {
+		return writeJSON(out, evolved)
+	}
+	if len(evolved) == 0 {
+		fmt.Fprintln(out, "No skills have been changed by evolution.")
+		return nil
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SKILL\tCURRENT\tVERSIONS\tSUCCESS\tSTATUS")
+	for _, profile := range evolved {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", profile.SkillName, profile.CurrentVersion,
+			len(profile.Versions()), successCell(profile.VersionEntry(profile.CurrentVersion)), profile.Status)
+	}
+	return w.Flush()
+}
+
+func historyShowCmd(out io.Writer, store *evolution.Store, skillName string, jsonOutput bool) error {
+	profile, err := store.LoadProfile(skillName)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("skill %s has no evolution history", skillName)
+	}
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		return writeJSON(out, profile)
+	}
+
+	fmt.Fprintf(out, "\n%s (current: %s)\n", profile.SkillName, valueOr(profile.CurrentVersion, "none"))
+	fmt.Fprintln(out, "--------------------")
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tACTION\tVERSION\tBY\tSUCCESS\tSUMMARY")
+	for i, entry := range profile.VersionHistory {
+		success := "-"
+		if version := profile.VersionEntry(entry.Version); version != nil && !entry.Rollback &&
+			version == &profile.VersionHistory[i] {
+			success = successCell(version)
+		}
+		summary := entry.Summary
+		if entry.RollbackReason != "" {
+			summary += " (" + entry.RollbackReason + ")"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", entry.Timestamp.Local().Format("2006-01-02 15:04"),
+			entry.Action, entry.Version, valueOr(entry.AppliedBy, "-"), success, summary)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if current := profile.VersionEntry(profile.CurrentVersion); current != nil && len(current.SourceRecordIDs) > 0 {
+		fmt.Fprintf(out, "\nCurrent version learned from: %v\n", current.SourceRecordIDs)
+	}
+	return nil
+}
+
+func successCell(entry *evolution.SkillVersionEntry) string {
+	if entry == nil || entry.UseCount == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d/%d (%.0f%%)", entry.SuccessCount, entry.UseCount, 100*entry.SuccessRatio())
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+func writeJSON(out io.Writer, v any) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,74 @@
+package evolution
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/evolution"
+)
+
+func TestHistoryAndRollback(t *testing.T) {
+	workspace := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.Workspace = workspace
+	cfg.Evolution.Enabled = true
+
+	skillPath := filepath.Join(workspace, "skills", "weather", "SKILL.md")
+	original := "---\nname: weather\ndescription: weather helper\n---\n# Weather\nAsk wttr.in.\n"
+	require.NoError(t, os.MkdirAll(filepath.Dir(skillPath), 0o755))
+	require.NoError(t, os.WriteFile(skillPath, []byte(original), 0o644))
+
+	rt, store, ws, err := newRuntime(cfg)
+	require.NoError(t, err)
+	require.NoError(t, store.SaveDrafts([]evolution.SkillDraft{{
+		ID:              "draft-1",
+		WorkspaceID:     ws,
+		CreatedAt:       time.Now().UTC(),
+		TargetSkillName: "weather",
+		DraftType:       evolution.DraftTypeShortcut,
+		ChangeKind:      evolution.ChangeKindReplace,
+		HumanSummary:    "guess instead",
+		BodyOrPatch:     "---\nname: weather\ndescription: weather helper\n---\n# Weather\nGuess.\n",
+		Status:          evolution.DraftStatusCandidate,
+	}}))
+	_, err = rt.AcceptDraft(context.Background(), ws, "draft-1", "tester")
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, historyListCmd(&out, store, ws, false))
+	assert.Contains(t, out.String(), "weather")
+	assert.Contains(t, out.String(), "draft-1")
+
+	out.Reset()
+	require.NoError(t, historyShowCmd(&out, store, "weather", true))
+	var profile evolution.SkillProfile
+	require.NoError(t, json.Unmarshal(out.Bytes(), &profile))
+	versions := profile.Versions()
+	require.Len(t, versions, 2)
+	assert.Equal(t, evolution.BaselineVersion, versions[0].Version)
+	assert.Equal(t, "tester", versions[1].AppliedBy)
+
+	out.Reset()
+	require.NoError(t, rollbackCmd(context.Background(), &out, rt, ws, "weather", rollbackOptions{reason: "worse"}))
+	assert.Contains(t, out.String(), "Rolled back weather from draft-1 to baseline")
+	data, err := os.ReadFile(skillPath)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(data))
+
+	out.Reset()
+	require.NoError(t, historyShowCmd(&out, store, "weather", false))
+	assert.Contains(t, out.String(), "current: baseline")
+	assert.Contains(t, out.String(), "rollback")
+
+	err = rollbackCmd(context.Background(), &out, rt, ws, "weather", rollbackOptions{})
+	assert.ErrorIs(t, err, evolution.ErrNoEarlierVersion)
+}
@@ -0,0 +1,63 @@
+package evolution
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/evolution"
+)
+
+type rollbackOptions struct {
+	to     string
+	reason string
+}
+
+func newRollbackCommand(cfgFn func() *config.Config) *cobra.Command {
+	var opts rollbackOptions
+
+	cmd := &cobra.Command{
+		Use:   "rollback <skill>",
+		Short: "Restore an earlier version of an evolved skill",
+		Long: `Restore the version an evolved skill replaced, or the version given with --to.
+"baseline" is the content the skill had before evolution first changed it.
+The draft behind the rolled-back version is marked rolled_back so its pattern
+is not drafted again.`,
+		Example: `picoclaw evolution rollback weather
+picoclaw evolution rollback weather --to baseline --reason "answers got worse"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, _, workspace, err := newRuntime(cfgFn())
+			if err != nil {
+				return err
+			}
+			return rollbackCmd(cmd.Context(), cmd.OutOrStdout(), rt, workspace, args[0], opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.to, "to", "", "Version to restore (default: the one the current version replaced)")
+	cmd.Flags().StringVar(&opts.reason, "reason", "", "Why the skill is rolled back, kept in its history")
+
+	return cmd
+}
+
+func rollbackCmd(
+	ctx context.Context,
+	out io.Writer,
+	rt *evolution.Runtime,
+	workspace, skillName string,
+	opts rollbackOptions,
+) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	entry, err := rt.RollbackSkill(ctx, workspace, skillName, opts.to, "cli", opts.reason)
+	if err != nil {
+		return fmt.Errorf("✗ %w", err)
+	}
+	fmt.Fprintf(out, "✓ Rolled back %s from %s to %s\n", skillName, entry.PreviousVersion, entry.Version)
+	return nil
+}
@@ -0,0 +1,202 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	pkgroot "github.com/sipeed/picoclaw/pkg"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/evolution"
+	"github.com/sipeed/picoclaw/pkg/memory"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/routing"
+)
+
+type evalOptions struct {
+	classifiers []string
+	thresholds  []float64
+	judge       string
+	model       string
+	neighbors   int
+	jsonOutput  bool
+}
+
+func newEvalCommand(cfgFn func() *config.Config) *cobra.Command {
+	opts := evalOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "eval",
+		Short: "Replay logged sessions to compare classifiers and thresholds",
+		Long: `Replay logged sessions to compare classifiers and thresholds.
+
+Every user message in the workspace sessions is scored by each classifier.
+Turns recorded by evolution are labeled by the tier that handled them and
+the success judge: a light turn that succeeded did not need the primary
+model, and a failed turn did. The knn classifier is scored leave-one-out.`,
+		Example: `  picoclaw routing eval
+  picoclaw routing eval --classifier rule,knn,llm --thresholds 0.2,0.35,0.5`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runEval(cmd.Context(), cmd.OutOrStdout(), cfgFn(), opts)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&opts.classifiers, "classifier", []string{"rule", "knn"},
+		"Classifiers to compare: rule, knn, llm")
+	cmd.Flags().Float64SliceVar(&opts.thresholds, "thresholds", []float64{0.2, 0.35, 0.5},
+		"Complexity thresholds to evaluate")
+	cmd.Flags().StringVar(&opts.judge, "judge", "heuristic", "Success judge for labels: heuristic or llm")
+	cmd.Flags().StringVar(&opts.model, "model", "",
+		"model_name for the llm classifier and judge (default: routing light_model)")
+	cmd.Flags().IntVar(&opts.neighbors, "knn-neighbors", 0, "Neighbors for the knn classifier (default: config or 7)")
+	cmd.Flags().BoolVar(&opts.jsonOutput, "json", false, "Print results as JSON")
+
+	return cmd
+}
+
+func runEval(ctx context.Context, out io.Writer, cfg *config.Config, opts evalOptions) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	workspace := cfg.WorkspacePath()
+	rc := cfg.Agents.Defaults.Routing
+	if rc == nil {
+		rc = &config.RoutingConfig{}
+	}
+
+	var (
+		provider providers.LLMProvider
+		modelID  string
+	)
+	needsModel := opts.judge == "llm"
+	for _, name := range opts.classifiers {
+		if strings.TrimSpace(name) == "llm" {
+			needsModel = true
+		}
+	}
+	if needsModel {
+		modelName := opts.model
+		if modelName == "" {
+			modelName = rc.LightModel
+		}
+		if modelName == "" {
+			return fmt.Errorf("the llm classifier and judge need --model or routing light_model")
+		}
+		modelCfg, err := cfg.GetModelConfig(modelName)
+		if err != nil {
+			return fmt.Errorf("model %q: %w", modelName, err)
+		}
+		provider, modelID, err = providers.CreateProviderFromConfig(modelCfg)
+		if err != nil {
+			return fmt.Errorf("create provider for %q: %w", modelName, err)
+		}
+	}
+
+	var judge evolution.SuccessJudge
+	switch opts.judge {
+	case "heuristic":
+		judge = &evolution.HeuristicSuccessJudge{}
+	case "llm":
+		judge = evolution.NewLLMTaskSuccessJudge(provider, modelID, nil)
+	default:
+		return fmt.Errorf("unknown judge %q (want heuristic or llm)", opts.judge)
+	}
+
+	examples, err := routing.LoadExamples(ctx, workspace, cfg.Evolution.StateDir, judge)
+	if err != nil {
+		return fmt.Errorf("load evolution records: %w", err)
+	}
+	histories, err := loadHistories(ctx, workspace)
+	if err != nil {
+		return err
+	}
+	turns := routing.ReplaySessions(histories, examples)
+	if len(turns) == 0 {
+		fmt.Fprintln(out, "No user messages found in workspace sessions.")
+		return nil
+	}
+
+	neighbors := opts.neighbors
+	if neighbors <= 0 {
+		neighbors = rc.KNNNeighbors
+	}
+	var results []routing.EvalResult
+	for _, name := range opts.classifiers {
+		name = strings.ToLower(strings.TrimSpace(name))
+		var c routing.Classifier
+		switch name {
+		case "rule":
+			c = &routing.RuleClassifier{}
+		case "knn":
+			c = routing.NewKNNClassifier(examples, neighbors, nil)
+		case "llm":
+			c = routing.NewLLMClassifier(provider, modelID, nil)
+		default:
+			return fmt.Errorf("unknown classifier %q (want rule, knn or llm)", name)
+		}
+		results = append(results, routing.Evaluate(ctx, turns, name, c, opts.thresholds)...)
+	}
+
+	if opts.jsonOutput {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+	printEvalResults(out, results)
+	return nil
+}
+
+func loadHistories(ctx context.Context, workspace string) (map[string][]providers.Message, error) {
+	dir := filepath.Join(workspace, "sessions")
+	if envDir := strings.TrimSpace(os.Getenv(pkgroot.SessionsDirEnv)); envDir != "" {
+		dir = envDir
+	}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, nil
+	}
+	store, err := memory.NewJSONLStore(dir)
+	if err != nil {
+		return nil, fmt.Errorf("open sessions: %w", err)
+	}
+	defer store.Close()
+
+	histories := make(map[string][]providers.Message)
+	for _, key := range store.ListSessions() {
+		history, err := store.GetHistory(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("read session %s: %w", key, err)
+		}
+		histories[key] = history
+	}
+	return histories, nil
+}
+
+func printEvalResults(out io.Writer, results []routing.EvalResult) {
+	if len(results) > 0 {
+		r := results[0]
+		fmt.Fprintf(out, "%d turns, %d labeled\n\n", r.Turns, r.Labeled)
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CLASSIFIER\tTHRESHOLD\tLIGHT\tACCURACY\tMISSED HEAVY\tNEEDLESS HEAVY")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%.2f\t%s\t%s\t%s\t%s\n",
+			r.Classifier, r.Threshold, percent(r.LightShare, r.Turns),
+			percent(r.Accuracy, r.Labeled), percent(r.MissedHeavy, r.Labeled), percent(r.NeedlessHeavy, r.Labeled))
+	}
+	w.Flush()
+}
+
+func percent(v float64, n int) string {
+	if n == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", v*100)
+}
@@ -0,0 +1,74 @@
+package routing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/evolution"
+	"github.com/sipeed/picoclaw/pkg/memory"
+	"github.com/sipeed/picoclaw/pkg/routing"
+)
+
+func TestNewEvalSubcommand(t *testing.T) {
+	cmd := newEvalCommand(func() *config.Config { return nil })
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "Replay logged sessions to compare classifiers and thresholds", cmd.Short)
+	for _, name := range []string{"classifier", "thresholds", "judge", "model", "knn-neighbors", "json"} {
+		assert.NotNil(t, cmd.Flags().Lookup(name), "missing flag %q", name)
+	}
+}
+
+func TestRunEval_ReplaysSessionsAgainstRecords(t *testing.T) {
+	workspace := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.Workspace = workspace
+
+	store, err := memory.NewJSONLStore(workspace + "/sessions")
+	require.NoError(t, err)
+	ctx := context.Background()
+	for _, msg := range []string{"hi there", "prove the lemma"} {
+		require.NoError(t, store.AddMessage(ctx, "s1", "user", msg))
+		require.NoError(t, store.AddMessage(ctx, "s1", "assistant", "ok"))
+	}
+	require.NoError(t, store.Close())
+
+	success, failure := true, false
+	evoStore := evolution.NewStore(evolution.NewPaths(workspace, ""))
+	require.NoError(t, evoStore.AppendTaskRecords(ctx, []evolution.LearningRecord{
+		{
+			ID: "r1", Kind: evolution.RecordKindTask, SessionKey: "s1", Summary: "hi there",
+			FinalOutput: "ok", Success: &success, Source: map[string]any{evolution.SourceRoutingTier: "light"},
+		},
+		{
+			ID: "r2", Kind: evolution.RecordKindTask, SessionKey: "s1", Summary: "prove the lemma",
+			Success: &failure, Source: map[string]any{evolution.SourceRoutingTier: "light"},
+		},
+	}))
+
+	var out bytes.Buffer
+	err = runEval(ctx, &out, cfg, evalOptions{
+		classifiers: []string{"rule"},
+		thresholds:  []float64{0.35},
+		judge:       "heuristic",
+		jsonOutput:  true,
+	})
+	require.NoError(t, err)
+
+	var results []routing.EvalResult
+	require.NoError(t, json.Unmarshal(out.Bytes(), &results))
+	require.Len(t, results, 1)
+	assert.Equal(t, 2, results[0].Turns)
+	assert.Equal(t, 2, results[0].Labeled)
+	// The rule classifier sends both short messages to the light model, so
+	// the failed one is a missed heavy turn.
+	assert.InDelta(t, 1.0, results[0].LightShare, 1e-9)
+	assert.InDelta(t, 0.5, results[0].MissedHeavy, 1e-9)
+}
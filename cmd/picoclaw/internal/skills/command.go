@@ -34,6 +34,7 @@ func NewSkillsCommand() *cobra.Command {
 			globalSkillsDir := filepath.Join(globalDir, "skills")
 			builtinSkillsDir := filepath.Join(globalDir, "picoclaw", "skills")
 			d.skillsLoader = skills.NewSkillsLoader(d.workspace, globalSkillsDir, builtinSkillsDir)
+			d.skillsLoader.SetRequirementChecker(skills.NewRequirementChecker(cfg))
 
 			return nil
 		},
@@ -64,6 +65,11 @@ func NewSkillsCommand() *cobra.Command {
 		newRemoveCommand(),
 		newSearchCommand(),
 		newShowCommand(loaderFn),
+		newDoctorCommand(loaderFn),
+		newOutdatedCommand(),
+		newUpdateCommand(),
+		newPublishCommand(),
+		newTestCommand(loaderFn),
 	)
 
 	return cmd
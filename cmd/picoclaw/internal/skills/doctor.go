@@ -0,0 +1,85 @@
+package skills
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+func newDoctorCommand(loaderFn func() (*skills.SkillsLoader, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor [skill]",
+		Short: "Check skill requirements",
+		Long: `Check whether the binaries, environment variables, tools, MCP servers,
+config keys and platform each skill declares are available, and explain how
+to fix what is missing.`,
+		Args: cobra.MaximumNArgs(1),
+		Example: `picoclaw skills doctor
+picoclaw skills doctor tmux`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			loader, err := loaderFn()
+			if err != nil {
+				return err
+			}
+			name := ""
+			if len(args) == 1 {
+				name = args[0]
+			}
+			return skillsDoctorCmd(cmd.OutOrStdout(), loader, name)
+		},
+	}
+
+	return cmd
+}
+
+// skillsDoctorCmd reports the requirement status of every skill, or of the
+// named one.
+func skillsDoctorCmd(out io.Writer, loader *skills.SkillsLoader, name string) error {
+	allSkills := loader.ListSkills()
+	if name != "" {
+		var matched []skills.SkillInfo
+		for _, skill := range allSkills {
+			if strings.EqualFold(skill.Name, name) {
+				matched = append(matched, skill)
+			}
+		}
+		if len(matched) == 0 {
+			return fmt.Errorf("skill '%s' not found", name)
+		}
+		allSkills = matched
+	}
+	if len(allSkills) == 0 {
+		fmt.Fprintln(out, "No skills installed.")
+		return nil
+	}
+
+	fmt.Fprintln(out, "\nSkill Requirements:")
+	fmt.Fprintln(out, "-------------------")
+	var ready, missing, unsupported int
+	for _, skill := range allSkills {
+		fmt.Fprintf(out, "  %s %s (%s)\n", skillStatusMark(skill), skill.Name, skill.Source)
+		switch {
+		case skill.PlatformUnsupported():
+			unsupported++
+		case !skill.Available():
+			missing++
+		default:
+			ready++
+		}
+		for _, m := range skill.Missing {
+			fmt.Fprintf(out, "    - %s: %s\n", m, m.Hint())
+		}
+		if skill.Tampered {
+			fmt.Fprintf(out, "    - %s: run 'picoclaw skills install --frozen' to restore it\n",
+				skills.TamperedWarning)
+		}
+	}
+
+	fmt.Fprintf(out, "\n%d ready, %d missing requirements, %d unsupported on this platform\n",
+		ready, missing, unsupported)
+	return nil
+}
@@ -0,0 +1,74 @@
+package skills
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+func TestNewDoctorSubcommand(t *testing.T) {
+	cmd := newDoctorCommand(nil)
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "doctor [skill]", cmd.Use)
+	assert.Equal(t, "Check skill requirements", cmd.Short)
+
+	assert.Nil(t, cmd.Run)
+	assert.NotNil(t, cmd.RunE)
+
+	assert.True(t, cmd.HasExample())
+	assert.False(t, cmd.HasSubCommands())
+	assert.False(t, cmd.HasFlags())
+}
+
+func TestSkillsDoctorCmd(t *testing.T) {
+	workspace := t.TempDir()
+	writeDoctorSkill(t, workspace, "ready", `{"nanobot":{"requires":{"bins":["sh"]}}}`)
+	writeDoctorSkill(t, workspace, "needs-env", `{"picoclaw":{"requires":{"env":["DOCTOR_TOKEN"]}}}`)
+	writeDoctorSkill(t, workspace, "mac-only", `{"nanobot":{"os":["darwin"]}}`)
+
+	loader := skills.NewSkillsLoader(workspace, "", "")
+	loader.SetRequirementChecker(&skills.RequirementChecker{
+		LookPath: func(file string) (string, error) {
+			if file == "sh" {
+				return "/bin/sh", nil
+			}
+			return "", errors.New("not found")
+		},
+		Getenv: func(string) string { return "" },
+		GOOS:   "linux",
+	})
+
+	var out bytes.Buffer
+	require.NoError(t, skillsDoctorCmd(&out, loader, ""))
+	report := out.String()
+	assert.Contains(t, report, "✓ ready (workspace)")
+	assert.Contains(t, report, "✗ needs-env (workspace)")
+	assert.Contains(t, report, "- env DOCTOR_TOKEN: set the DOCTOR_TOKEN environment variable")
+	assert.Contains(t, report, "⊘ mac-only (workspace)")
+	assert.Contains(t, report, "1 ready, 1 missing requirements, 1 unsupported on this platform")
+
+	out.Reset()
+	require.NoError(t, skillsDoctorCmd(&out, loader, "needs-env"))
+	assert.NotContains(t, out.String(), "ready (workspace)")
+
+	err := skillsDoctorCmd(&out, loader, "nope")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func writeDoctorSkill(t *testing.T, workspace, name, metadata string) {
+	t.Helper()
+	dir := filepath.Join(workspace, "skills", name)
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	content := "---\nname: " + name + "\ndescription: " + name + " skill\nmetadata: " + metadata + "\n---\n\n# " + name
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte(content), 0o644))
+}
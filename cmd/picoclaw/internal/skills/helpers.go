@@ -40,11 +40,29 @@ func skillsListCmd(loader *skills.SkillsLoader) {
 	fmt.Println("\nInstalled Skills:")
 	fmt.Println("------------------")
 	for _, skill := range allSkills {
-		fmt.Printf("  ✓ %s (%s)\n", skill.Name, skill.Source)
+		fmt.Printf("  %s %s (%s)\n", skillStatusMark(skill), skill.Name, skill.Source)
 		if skill.Description != "" {
 			fmt.Printf("    %s\n", skill.Description)
 		}
+		if !skill.Available() {
+			fmt.Printf("    %s\n", skills.UnavailableReason(skill))
+		}
+		if skill.Tampered {
+			fmt.Printf("    \u26a0\ufe0f  %s\n", skills.TamperedWarning)
+		}
+	}
+}
+
+// skillStatusMark is ✓ for a usable skill, ✗ for one with unmet requirements
+// and ⊘ for one that cannot run on this platform.
+func skillStatusMark(skill skills.SkillInfo) string {
+	switch {
+	case skill.PlatformUnsupported():
+		return "⊘"
+	case !skill.Available():
+		return "✗"
 	}
+	return "✓"
 }
 
 // skillsInstallFromRegistry installs a skill from a named registry (e.g. clawhub).
@@ -123,15 +141,35 @@ func skillsInstallFromRegistry(cfg *config.Config, registryName, target string)
 		_ = os.RemoveAll(targetDir)
 		return fmt.Errorf("✗ failed to persist skill metadata: %w", err)
 	}
+	if _, err := skills.RecordInstall(workspace, dirName, registry.Name(), normalizedSlug, result); err != nil {
+		fmt.Printf("\u26a0\ufe0f  Warning: failed to update %s: %v\n", skills.LockFileName, err)
+	}
 
 	fmt.Printf("\u2713 Skill '%s' v%s installed successfully!\n", dirName, result.Version)
 	if result.Summary != "" {
 		fmt.Printf("  %s\n", result.Summary)
 	}
+	warnUnmetSkillRequirements(os.Stdout, cfg, dirName)
 
 	return nil
 }
 
+// warnUnmetSkillRequirements prints what a freshly installed skill still
+// needs before the agent can use it.
+func warnUnmetSkillRequirements(out io.Writer, cfg *config.Config, dirName string) {
+	loader := skills.NewSkillsLoader(cfg.WorkspacePath(), "", "")
+	loader.SetRequirementChecker(skills.NewRequirementChecker(cfg))
+	skill, ok := loader.WorkspaceSkill(dirName)
+	if !ok || skill.Available() {
+		return
+	}
+	fmt.Fprintf(out, "\u26a0\ufe0f  Warning: skill '%s' has unmet requirements:\n", skill.Name)
+	for _, missing := range skill.Missing {
+		fmt.Fprintf(out, "    - %s: %s\n", missing, missing.Hint())
+	}
+	fmt.Fprintf(out, "  Run 'picoclaw skills doctor %s' after fixing them.\n", skill.Name)
+}
+
 func writeInstalledSkillOriginMeta(targetDir string, meta installedSkillOriginMeta) error {
 	data, err := json.MarshalIndent(meta, "", "  ")
 	if err != nil {
@@ -176,6 +214,9 @@ func skillsRemoveFromWorkspace(workspace string, toolsConfig config.SkillsToolsC
 	if err := os.RemoveAll(skillDir); err != nil {
 		return fmt.Errorf("failed to remove skill '%s': %w", name, err)
 	}
+	if err := skills.ForgetInstall(workspace, name); err != nil {
+		return fmt.Errorf("failed to update %s: %w", skills.LockFileName, err)
+	}
 	return nil
 }
 
@@ -9,7 +9,10 @@ import (
 )
 
 func newInstallCommand() *cobra.Command {
-	var registry string
+	var (
+		registry string
+		frozen   bool
+	)
 
 	cmd := &cobra.Command{
 		Use:   "install",
@@ -17,8 +20,16 @@ func newInstallCommand() *cobra.Command {
 		Example: `
 picoclaw skills install sipeed/picoclaw-skills/weather
 picoclaw skills install --registry clawhub github
+picoclaw skills install --frozen
 `,
 		Args: func(cmd *cobra.Command, args []string) error {
+			if frozen {
+				if registry != "" || len(args) != 0 {
+					return fmt.Errorf("--frozen installs from skills.lock and takes no arguments")
+				}
+				return nil
+			}
+
 			if registry != "" {
 				if len(args) != 1 {
 					return fmt.Errorf("when --registry is set, exactly 1 argument is required: <slug>")
@@ -37,6 +48,9 @@ picoclaw skills install --registry clawhub github
 			if err != nil {
 				return err
 			}
+			if frozen {
+				return skillsInstallFrozen(cfg)
+			}
 			if registry != "" {
 				return skillsInstallFromRegistry(cfg, registry, args[0])
 			}
@@ -46,6 +60,7 @@ picoclaw skills install --registry clawhub github
 	}
 
 	cmd.Flags().StringVar(&registry, "registry", "", "Install from registry: --registry <name> <slug>")
+	cmd.Flags().BoolVar(&frozen, "frozen", false, "Install exactly the skills pinned in skills.lock")
 
 	return cmd
 }
@@ -23,6 +23,7 @@ func TestNewInstallSubcommand(t *testing.T) {
 
 	assert.True(t, cmd.HasFlags())
 	assert.NotNil(t, cmd.Flags().Lookup("registry"))
+	assert.NotNil(t, cmd.Flags().Lookup("frozen"))
 
 	assert.Len(t, cmd.Aliases, 0)
 }
@@ -32,6 +33,7 @@ func TestInstallCommandArgs(t *testing.T) {
 		name        string
 		args        []string
 		registry    string
+		frozen      bool
 		expectError bool
 		errorMsg    string
 	}{
@@ -75,6 +77,19 @@ func TestInstallCommandArgs(t *testing.T) {
 			expectError: true,
 			errorMsg:    "when --registry is set, exactly 1 argument is required: <slug>",
 		},
+		{
+			name:        "frozen, no args",
+			args:        []string{},
+			frozen:      true,
+			expectError: false,
+		},
+		{
+			name:        "frozen, with arg",
+			args:        []string{"weather-skill"},
+			frozen:      true,
+			expectError: true,
+			errorMsg:    "--frozen installs from skills.lock and takes no arguments",
+		},
 	}
 
 	for _, tt := range tests {
@@ -84,6 +99,9 @@ func TestInstallCommandArgs(t *testing.T) {
 			if tt.registry != "" {
 				require.NoError(t, cmd.Flags().Set("registry", tt.registry))
 			}
+			if tt.frozen {
+				require.NoError(t, cmd.Flags().Set("frozen", "true"))
+			}
 
 			err := cmd.Args(cmd, tt.args)
 			if tt.expectError {
@@ -0,0 +1,305 @@
+package skills
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+// skillsInstallFrozen installs every skill pinned in skills.lock at its locked
+// revision and refuses content whose hash differs from the lock.
+func skillsInstallFrozen(cfg *config.Config) error {
+	return installFrozen(os.Stdout, cfg, skills.NewRegistryManagerFromToolsConfig(cfg.Tools.Skills))
+}
+
+func installFrozen(out io.Writer, cfg *config.Config, registryMgr *skills.RegistryManager) error {
+	workspace := cfg.WorkspacePath()
+	lock, err := skills.LoadLockFile(workspace)
+	if err != nil {
+		return fmt.Errorf("✗ %w", err)
+	}
+	if len(lock.Skills) == 0 {
+		return fmt.Errorf("✗ %s has no skills to install", skills.LockFileName)
+	}
+
+	var failed []string
+	for _, dirName := range lock.Names() {
+		locked := lock.Skills[dirName]
+		skillDir := filepath.Join(workspace, "skills", dirName)
+		if current, err := skills.HashSkillDir(skillDir); err == nil && locked.Matches(current) {
+			fmt.Fprintf(out, "✓ %s is up to date\n", dirName)
+			continue
+		}
+
+		err := func() error {
+			registry := registryMgr.GetRegistry(locked.Registry)
+			if registry == nil {
+				return fmt.Errorf("registry '%s' not found or not enabled", locked.Registry)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+			defer cancel()
+
+			staged, result, cleanup, err := stageRegistrySkill(ctx, registry, workspace, dirName,
+				locked.Slug, locked.InstallRef())
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			integrity, err := skills.HashSkillDir(staged)
+			if err != nil {
+				return err
+			}
+			if integrity != locked.Integrity {
+				return fmt.Errorf("integrity mismatch: %s locked %s, registry served %s",
+					skills.LockFileName, locked.Integrity, integrity)
+			}
+			// Keep the locked branch or tag rather than the commit used to fetch.
+			result.Version, result.Commit = locked.Version, locked.Commit
+			return commitStagedSkill(registry, workspace, dirName, staged, locked.Slug, result)
+		}()
+		if err != nil {
+			fmt.Fprintf(out, "✗ %s: %v\n", dirName, err)
+			failed = append(failed, dirName)
+			continue
+		}
+		fmt.Fprintf(out, "✓ %s installed at %s\n", dirName, shortRevision(skills.LockedRevision(locked)))
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("✗ failed to install %d locked skill(s): %s", len(failed), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// outdatedSkill is one row of `skills outdated`.
+type outdatedSkill struct {
+	Name    string
+	Locked  skills.LockedSkill
+	Current string
+	Latest  string
+	Err     error
+}
+
+func (o outdatedSkill) Outdated() bool {
+	return o.Err == nil && o.Latest != "" && o.Latest != o.Current
+}
+
+func findOutdatedSkills(
+	ctx context.Context,
+	registryMgr *skills.RegistryManager,
+	lock *skills.LockFile,
+	names []string,
+) ([]outdatedSkill, error) {
+	if len(names) == 0 {
+		names = lock.Names()
+	}
+	rows := make([]outdatedSkill, 0, len(names))
+	for _, name := range names {
+		locked, ok := lock.Skills[name]
+		if !ok {
+			return nil, fmt.Errorf("skill '%s' is not in %s", name, skills.LockFileName)
+		}
+		row := outdatedSkill{Name: name, Locked: locked, Current: skills.LockedRevision(locked)}
+		if registry := registryMgr.GetRegistry(locked.Registry); registry == nil {
+			row.Err = fmt.Errorf("registry '%s' not found or not enabled", locked.Registry)
+		} else {
+			row.Latest, row.Err = skills.LatestRevision(ctx, registry, locked)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func skillsOutdatedCmd(out io.Writer, cfg *config.Config, registryMgr *skills.RegistryManager) error {
+	lock, err := skills.LoadLockFile(cfg.WorkspacePath())
+	if err != nil {
+		return err
+	}
+	if len(lock.Skills) == 0 {
+		fmt.Fprintf(out, "No skills in %s.\n", skills.LockFileName)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	rows, err := findOutdatedSkills(ctx, registryMgr, lock, nil)
+	if err != nil {
+		return err
+	}
+
+	outdated := 0
+	for _, row := range rows {
+		switch {
+		case row.Err != nil:
+			fmt.Fprintf(out, "  ? %s (%s): %v\n", row.Name, row.Locked.Registry, row.Err)
+		case row.Outdated():
+			outdated++
+			fmt.Fprintf(out, "  ↑ %s (%s): %s → %s\n", row.Name, row.Locked.Registry,
+				shortRevision(row.Current), shortRevision(row.Latest))
+		default:
+			fmt.Fprintf(out, "  ✓ %s (%s): %s\n", row.Name, row.Locked.Registry, shortRevision(row.Current))
+		}
+	}
+	if outdated == 0 {
+		fmt.Fprintln(out, "\nAll locked skills are up to date.")
+	} else {
+		fmt.Fprintf(out, "\n%d skill(s) can be updated with 'picoclaw skills update'.\n", outdated)
+	}
+	return nil
+}
+
+func skillsUpdateCmd(out io.Writer, cfg *config.Config, registryMgr *skills.RegistryManager, names []string) error {
+	workspace := cfg.WorkspacePath()
+	lock, err := skills.LoadLockFile(workspace)
+	if err != nil {
+		return err
+	}
+	if len(lock.Skills) == 0 {
+		fmt.Fprintf(out, "No skills in %s.\n", skills.LockFileName)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	rows, err := findOutdatedSkills(ctx, registryMgr, lock, names)
+	if err != nil {
+		return err
+	}
+
+	updated := 0
+	var failed []string
+	for _, row := range rows {
+		if row.Err != nil {
+			fmt.Fprintf(out, "✗ %s: %v\n", row.Name, row.Err)
+			failed = append(failed, row.Name)
+			continue
+		}
+		if !row.Outdated() {
+			continue
+		}
+		if err := updateLockedSkill(ctx, registryMgr, workspace, row); err != nil {
+			fmt.Fprintf(out, "✗ %s: %v\n", row.Name, err)
+			failed = append(failed, row.Name)
+			continue
+		}
+		updated++
+		fmt.Fprintf(out, "✓ %s updated %s → %s\n", row.Name, shortRevision(row.Current), shortRevision(row.Latest))
+		warnUnmetSkillRequirements(out, cfg, row.Name)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to update %d skill(s): %s", len(failed), strings.Join(failed, ", "))
+	}
+	if updated == 0 {
+		fmt.Fprintln(out, "All locked skills are up to date.")
+	}
+	return nil
+}
+
+func updateLockedSkill(
+	ctx context.Context,
+	registryMgr *skills.RegistryManager,
+	workspace string,
+	row outdatedSkill,
+) error {
+	registry := registryMgr.GetRegistry(row.Locked.Registry)
+	// Git-backed skills follow the locked branch or tag; other registries
+	// move to their latest version.
+	version := row.Latest
+	if _, ok := registry.(skills.CommitResolver); ok && row.Locked.Commit != "" {
+		version = row.Locked.Version
+	}
+
+	staged, result, cleanup, err := stageRegistrySkill(ctx, registry, workspace, row.Name, row.Locked.Slug, version)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	return commitStagedSkill(registry, workspace, row.Name, staged, row.Locked.Slug, result)
+}
+
+// stageRegistrySkill downloads a skill into a temporary directory under
+// workspace/skills so it can be verified before replacing the installed copy.
+func stageRegistrySkill(
+	ctx context.Context,
+	registry skills.SkillRegistry,
+	workspace, dirName, slug, version string,
+) (string, *skills.InstallResult, func(), error) {
+	skillsDir := filepath.Join(workspace, "skills")
+	if err := os.MkdirAll(skillsDir, 0o755); err != nil {
+		return "", nil, nil, fmt.Errorf("failed to create skills directory: %w", err)
+	}
+	tmpDir, err := os.MkdirTemp(skillsDir, "."+dirName+".staging-")
+	if err != nil {
+		return "", nil, nil, err
+	}
+	cleanup := func() { _ = os.RemoveAll(tmpDir) }
+
+	staged := filepath.Join(tmpDir, dirName)
+	result, err := registry.DownloadAndInstall(ctx, slug, version, staged)
+	if err != nil {
+		cleanup()
+		return "", nil, nil, fmt.Errorf("download failed: %w", err)
+	}
+	if result.IsMalwareBlocked {
+		cleanup()
+		return "", nil, nil, fmt.Errorf("skill is flagged as malicious")
+	}
+	if _, err := os.Stat(filepath.Join(staged, "SKILL.md")); err != nil {
+		cleanup()
+		return "", nil, nil, fmt.Errorf("registry archive is not a valid skill")
+	}
+	return staged, result, cleanup, nil
+}
+
+// commitStagedSkill replaces workspace/skills/<dirName> with the staged copy
+// and records it in the origin metadata and the lockfile.
+func commitStagedSkill(
+	registry skills.SkillRegistry,
+	workspace, dirName, staged, slug string,
+	result *skills.InstallResult,
+) error {
+	targetDir := filepath.Join(workspace, "skills", dirName)
+	if err := os.RemoveAll(targetDir); err != nil {
+		return fmt.Errorf("failed to remove old copy: %w", err)
+	}
+	if err := os.Rename(staged, targetDir); err != nil {
+		return fmt.Errorf("failed to move skill into place: %w", err)
+	}
+
+	_, registryURL := skills.BuildInstallMetadataForRegistryInstance(registry, slug, result.Version)
+	if err := writeInstalledSkillOriginMeta(targetDir, installedSkillOriginMeta{
+		Version:          1,
+		OriginKind:       "third_party",
+		Registry:         registry.Name(),
+		Slug:             slug,
+		RegistryURL:      registryURL,
+		InstalledVersion: result.Version,
+		InstalledAt:      time.Now().UnixMilli(),
+	}); err != nil {
+		return fmt.Errorf("failed to persist skill metadata: %w", err)
+	}
+	if _, err := skills.RecordInstall(workspace, dirName, registry.Name(), slug, result); err != nil {
+		return fmt.Errorf("failed to update %s: %w", skills.LockFileName, err)
+	}
+	return nil
+}
+
+// shortRevision abbreviates commit SHAs for display.
+func shortRevision(rev string) string {
+	if len(rev) == 40 && strings.Trim(rev, "0123456789abcdef") == "" {
+		return rev[:12]
+	}
+	if rev == "" {
+		return "(unknown)"
+	}
+	return rev
+}
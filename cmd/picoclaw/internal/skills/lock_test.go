@@ -0,0 +1,182 @@
+package skills
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+// versionedRegistry serves a different SKILL.md body per version.
+type versionedRegistry struct {
+	latest   string
+	versions map[string]string
+}
+
+func (r *versionedRegistry) Name() string { return "clawhub" }
+
+func (r *versionedRegistry) ResolveInstallDirName(target string) (string, error) { return target, nil }
+
+func (r *versionedRegistry) SkillURL(slug, _ string) string { return "https://example.com/" + slug }
+
+func (r *versionedRegistry) Search(context.Context, string, int) ([]skills.SearchResult, error) {
+	return nil, nil
+}
+
+func (r *versionedRegistry) GetSkillMeta(_ context.Context, slug string) (*skills.SkillMeta, error) {
+	return &skills.SkillMeta{Slug: slug, LatestVersion: r.latest}, nil
+}
+
+func (r *versionedRegistry) DownloadAndInstall(
+	_ context.Context,
+	slug, version, targetDir string,
+) (*skills.InstallResult, error) {
+	if version == "" {
+		version = r.latest
+	}
+	body, ok := r.versions[version]
+	if !ok {
+		return nil, fmt.Errorf("version %s not found", version)
+	}
+	if err := os.MkdirAll(targetDir, 0o755); err != nil {
+		return nil, err
+	}
+	content := "---\nname: " + slug + "\ndescription: " + slug + " skill\n---\n\n" + body
+	if err := os.WriteFile(filepath.Join(targetDir, "SKILL.md"), []byte(content), 0o644); err != nil {
+		return nil, err
+	}
+	return &skills.InstallResult{Version: version}, nil
+}
+
+func newLockTestEnv(t *testing.T) (*config.Config, *versionedRegistry, *skills.RegistryManager) {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.Workspace = t.TempDir()
+	registry := &versionedRegistry{
+		latest:   "1.0.0",
+		versions: map[string]string{"1.0.0": "# Weather v1", "2.0.0": "# Weather v2"},
+	}
+	mgr := skills.NewRegistryManager()
+	mgr.AddRegistry(registry)
+	return cfg, registry, mgr
+}
+
+func installLockTestSkill(t *testing.T, cfg *config.Config, mgr *skills.RegistryManager, version string) {
+	t.Helper()
+	registry := mgr.GetRegistry("clawhub")
+	staged, result, cleanup, err := stageRegistrySkill(context.Background(), registry, cfg.WorkspacePath(),
+		"weather", "weather", version)
+	require.NoError(t, err)
+	defer cleanup()
+	require.NoError(t, commitStagedSkill(registry, cfg.WorkspacePath(), "weather", staged, "weather", result))
+}
+
+func TestInstallFrozen(t *testing.T) {
+	cfg, registry, mgr := newLockTestEnv(t)
+	installLockTestSkill(t, cfg, mgr, "1.0.0")
+	lockData, err := os.ReadFile(skills.LockFilePath(cfg.WorkspacePath()))
+	require.NoError(t, err)
+
+	// A fresh device with only the lockfile gets the pinned version even
+	// though the registry has moved on.
+	registry.latest = "2.0.0"
+	require.NoError(t, os.RemoveAll(filepath.Join(cfg.WorkspacePath(), "skills")))
+
+	var out bytes.Buffer
+	require.NoError(t, installFrozen(&out, cfg, mgr))
+	assert.Contains(t, out.String(), "✓ weather installed at 1.0.0")
+	content, err := os.ReadFile(filepath.Join(cfg.WorkspacePath(), "skills", "weather", "SKILL.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "# Weather v1")
+
+	out.Reset()
+	require.NoError(t, installFrozen(&out, cfg, mgr))
+	assert.Contains(t, out.String(), "✓ weather is up to date")
+
+	// Content served for the locked version changed upstream.
+	registry.versions["1.0.0"] = "# Weather v1 (republished)"
+	require.NoError(t, os.WriteFile(filepath.Join(cfg.WorkspacePath(), "skills", "weather", "SKILL.md"),
+		[]byte("---\nname: weather\ndescription: edited\n---\n"), 0o644))
+	out.Reset()
+	err = installFrozen(&out, cfg, mgr)
+	require.Error(t, err)
+	assert.Contains(t, out.String(), "integrity mismatch")
+	content, err = os.ReadFile(filepath.Join(cfg.WorkspacePath(), "skills", "weather", "SKILL.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "description: edited", "failed frozen install must not replace the skill")
+
+	after, err := os.ReadFile(skills.LockFilePath(cfg.WorkspacePath()))
+	require.NoError(t, err)
+	assert.Equal(t, lockEntryWithoutTime(t, lockData), lockEntryWithoutTime(t, after))
+}
+
+func TestInstallFrozenRequiresLockEntries(t *testing.T) {
+	cfg, _, mgr := newLockTestEnv(t)
+	err := installFrozen(&bytes.Buffer{}, cfg, mgr)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "has no skills to install")
+}
+
+func TestSkillsOutdatedAndUpdate(t *testing.T) {
+	cfg, registry, mgr := newLockTestEnv(t)
+	installLockTestSkill(t, cfg, mgr, "1.0.0")
+
+	var out bytes.Buffer
+	require.NoError(t, skillsOutdatedCmd(&out, cfg, mgr))
+	assert.Contains(t, out.String(), "✓ weather (clawhub): 1.0.0")
+	assert.Contains(t, out.String(), "All locked skills are up to date.")
+
+	registry.latest = "2.0.0"
+	out.Reset()
+	require.NoError(t, skillsOutdatedCmd(&out, cfg, mgr))
+	assert.Contains(t, out.String(), "↑ weather (clawhub): 1.0.0 → 2.0.0")
+
+	out.Reset()
+	err := skillsUpdateCmd(&out, cfg, mgr, []string{"nope"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not in skills.lock")
+
+	out.Reset()
+	require.NoError(t, skillsUpdateCmd(&out, cfg, mgr, nil))
+	assert.Contains(t, out.String(), "✓ weather updated 1.0.0 → 2.0.0")
+
+	lock, err := skills.LoadLockFile(cfg.WorkspacePath())
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.0", lock.Skills["weather"].Version)
+	integrity, err := skills.HashSkillDir(filepath.Join(cfg.WorkspacePath(), "skills", "weather"))
+	require.NoError(t, err)
+	assert.Equal(t, integrity, lock.Skills["weather"].Integrity)
+
+	entries, err := os.ReadDir(filepath.Join(cfg.WorkspacePath(), "skills"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "staging directories must be cleaned up")
+}
+
+func TestSkillsRemoveFromWorkspaceForgetsLockEntry(t *testing.T) {
+	cfg, _, mgr := newLockTestEnv(t)
+	installLockTestSkill(t, cfg, mgr, "1.0.0")
+
+	require.NoError(t, skillsRemoveFromWorkspace(cfg.WorkspacePath(), cfg.Tools.Skills, "weather"))
+	lock, err := skills.LoadLockFile(cfg.WorkspacePath())
+	require.NoError(t, err)
+	assert.Empty(t, lock.Skills)
+}
+
+func lockEntryWithoutTime(t *testing.T, data []byte) skills.LockedSkill {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), skills.LockFileName)
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+	lock, err := skills.LoadLockFile(filepath.Dir(path))
+	require.NoError(t, err)
+	entry := lock.Skills["weather"]
+	entry.InstalledAt = 0
+	return entry
+}
@@ -0,0 +1,26 @@
+package skills
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+func newOutdatedCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "outdated",
+		Short:   "List locked skills with newer registry versions",
+		Args:    cobra.NoArgs,
+		Example: `picoclaw skills outdated`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return err
+			}
+			return skillsOutdatedCmd(cmd.OutOrStdout(), cfg, skills.NewRegistryManagerFromToolsConfig(cfg.Tools.Skills))
+		},
+	}
+
+	return cmd
+}
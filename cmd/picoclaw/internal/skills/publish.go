@@ -0,0 +1,55 @@
+package skills
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+func newPublishCommand() *cobra.Command {
+	var (
+		outDir  string
+		version string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "publish <skills-dir>",
+		Short: "Build a static skill registry from a folder of skills",
+		Long: `Pack every skill under <skills-dir> into a tar.gz archive and merge it into
+the index.json of --out. Serve the output directory with any static file
+server (or point a registry at it directly) and configure it as the "static"
+skill registry. Versions come from the "version" frontmatter key, then
+--version, then the content hash.`,
+		Args: cobra.ExactArgs(1),
+		Example: `picoclaw skills publish ./my-skills --out /srv/www/skills
+picoclaw skills publish ./my-skills --out ./mirror --version 1.2.0`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return skillsPublishCmd(cmd.OutOrStdout(), args[0], outDir, version)
+		},
+	}
+
+	cmd.Flags().StringVarP(&outDir, "out", "o", "", "Registry output directory (required)")
+	cmd.Flags().StringVar(&version, "version", "", "Version for skills without a version in their frontmatter")
+	_ = cmd.MarkFlagRequired("out")
+
+	return cmd
+}
+
+func skillsPublishCmd(out io.Writer, srcDir, outDir, version string) error {
+	published, err := skills.PublishStaticIndex(srcDir, outDir, skills.PublishOptions{Version: version})
+	if err != nil {
+		return fmt.Errorf("✗ publish failed: %w", err)
+	}
+	for _, p := range published {
+		if p.Unchanged {
+			fmt.Fprintf(out, "  = %s %s (unchanged)\n", p.Slug, p.Version)
+			continue
+		}
+		fmt.Fprintf(out, "  ✓ %s %s → %s\n", p.Slug, p.Version, p.Path)
+	}
+	fmt.Fprintf(out, "\n✓ Published %d skill(s) to %s\n", len(published), outDir)
+	return nil
+}
@@ -0,0 +1,54 @@
+package skills
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+func TestNewPublishSubcommand(t *testing.T) {
+	cmd := newPublishCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "publish <skills-dir>", cmd.Use)
+	assert.Equal(t, "Build a static skill registry from a folder of skills", cmd.Short)
+
+	assert.Nil(t, cmd.Run)
+	assert.NotNil(t, cmd.RunE)
+
+	assert.True(t, cmd.HasExample())
+	assert.False(t, cmd.HasSubCommands())
+
+	assert.True(t, cmd.HasFlags())
+	assert.NotNil(t, cmd.Flags().Lookup("out"))
+	assert.NotNil(t, cmd.Flags().Lookup("version"))
+}
+
+func TestSkillsPublishCmd(t *testing.T) {
+	srcDir := t.TempDir()
+	outDir := t.TempDir()
+	skillDir := filepath.Join(srcDir, "weather")
+	require.NoError(t, os.MkdirAll(skillDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(skillDir, "SKILL.md"),
+		[]byte("---\nname: weather\ndescription: Weather forecasts\n---\n# Weather\n"), 0o644))
+
+	var out bytes.Buffer
+	require.NoError(t, skillsPublishCmd(&out, srcDir, outDir, "1.0.0"))
+	assert.Contains(t, out.String(), "✓ weather 1.0.0 → weather/weather-1.0.0.tar.gz")
+	assert.FileExists(t, filepath.Join(outDir, skills.StaticIndexFileName))
+
+	out.Reset()
+	require.NoError(t, skillsPublishCmd(&out, srcDir, outDir, "1.0.0"))
+	assert.Contains(t, out.String(), "= weather 1.0.0 (unchanged)")
+
+	err := skillsPublishCmd(&out, t.TempDir(), outDir, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no skills found")
+}
@@ -0,0 +1,146 @@
+package skills
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+type testOptions struct {
+	model      string
+	live       bool
+	jsonOutput bool
+}
+
+func newTestCommand(loaderFn func() (*skills.SkillsLoader, error)) *cobra.Command {
+	var opts testOptions
+
+	cmd := &cobra.Command{
+		Use:   "test <skill>",
+		Short: "Run a skill's test scenarios",
+		Long: `Run the scenarios in <skill>/tests/*.yaml through the agent loop and report
+which pass. A scenario has a user prompt and expects tool calls, a regex on
+the final answer, or both. Scenarios with a script replay it through a mock
+model; the others need a real model from --model or --live. The last report
+is saved under the workspace state directory.`,
+		Args: cobra.ExactArgs(1),
+		Example: `picoclaw skills test weather
+picoclaw skills test weather --model gpt-5.4
+picoclaw skills test weather --live --json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			loader, err := loaderFn()
+			if err != nil {
+				return err
+			}
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+			return skillsTestCmd(cmd.Context(), cmd.OutOrStdout(), cfg, loader, args[0], opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.model, "model", "", "model_name for scenarios without a script")
+	cmd.Flags().BoolVar(&opts.live, "live", false,
+		"Send every scenario to the model, ignoring scripts (default model unless --model is set)")
+	cmd.Flags().BoolVar(&opts.jsonOutput, "json", false, "Print the report as JSON")
+
+	return cmd
+}
+
+func skillsTestCmd(
+	ctx context.Context,
+	out io.Writer,
+	cfg *config.Config,
+	loader *skills.SkillsLoader,
+	name string,
+	opts testOptions,
+) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var skillDir string
+	for _, skill := range loader.ListSkills() {
+		if strings.EqualFold(skill.Name, name) {
+			skillDir = filepath.Dir(skill.Path)
+			break
+		}
+	}
+	if skillDir == "" {
+		return fmt.Errorf("skill '%s' not found", name)
+	}
+
+	runOpts := agent.SkillScenarioOptions{ModelName: opts.model, Live: opts.live}
+	if opts.live && runOpts.ModelName == "" {
+		runOpts.ModelName = cfg.Agents.Defaults.GetModelName()
+	}
+	if runOpts.ModelName != "" {
+		modelCfg, err := cfg.GetModelConfig(runOpts.ModelName)
+		if err != nil {
+			return fmt.Errorf("model %q: %w", runOpts.ModelName, err)
+		}
+		provider, _, err := providers.CreateProviderFromConfig(modelCfg)
+		if err != nil {
+			return fmt.Errorf("create provider for %q: %w", runOpts.ModelName, err)
+		}
+		runOpts.Provider = provider
+	}
+
+	report, err := agent.RunSkillScenarios(ctx, cfg, skillDir, runOpts)
+	if err != nil {
+		return fmt.Errorf("✗ %s: %w", name, err)
+	}
+	if len(report.Results) == 0 {
+		fmt.Fprintf(out, "No scenarios in %s\n", filepath.Join(skillDir, skills.ScenarioDirName))
+		return nil
+	}
+	if err := skills.SaveScenarioReport(cfg.WorkspacePath(), report); err != nil {
+		fmt.Fprintf(out, "⚠ could not save report: %v\n", err)
+	}
+
+	if opts.jsonOutput {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		printScenarioReport(out, report)
+	}
+	if _, failed, _ := report.Counts(); failed > 0 {
+		return fmt.Errorf("✗ %d scenario(s) failed", failed)
+	}
+	return nil
+}
+
+func printScenarioReport(out io.Writer, report *skills.ScenarioReport) {
+	fmt.Fprintf(out, "\nSkill Tests: %s\n", report.Skill)
+	fmt.Fprintln(out, "--------------------")
+	for _, res := range report.Results {
+		switch {
+		case res.Mode == skills.ScenarioModeSkipped:
+			fmt.Fprintf(out, "  - %s (skipped: %s)\n", res.Name, strings.Join(res.Failures, "; "))
+			continue
+		case res.Passed:
+			fmt.Fprintf(out, "  ✓ %s (%s, %dms)\n", res.Name, res.Mode, res.Duration)
+			continue
+		}
+		fmt.Fprintf(out, "  ✗ %s (%s, %dms)\n", res.Name, res.Mode, res.Duration)
+		for _, failure := range res.Failures {
+			fmt.Fprintf(out, "    - %s\n", failure)
+		}
+	}
+	passed, failed, skipped := report.Counts()
+	fmt.Fprintf(out, "\n%d passed, %d failed, %d skipped\n", passed, failed, skipped)
+}
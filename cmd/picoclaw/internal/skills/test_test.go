@@ -0,0 +1,70 @@
+package skills
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+func TestNewTestSubcommand(t *testing.T) {
+	cmd := newTestCommand(nil)
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "test <skill>", cmd.Use)
+	assert.Equal(t, "Run a skill's test scenarios", cmd.Short)
+
+	assert.Nil(t, cmd.Run)
+	assert.NotNil(t, cmd.RunE)
+
+	assert.True(t, cmd.HasExample())
+	assert.False(t, cmd.HasSubCommands())
+
+	assert.True(t, cmd.HasFlags())
+	assert.NotNil(t, cmd.Flags().Lookup("model"))
+	assert.NotNil(t, cmd.Flags().Lookup("live"))
+	assert.NotNil(t, cmd.Flags().Lookup("json"))
+}
+
+func TestSkillsTestCmd(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.Workspace = t.TempDir()
+	skillDir := filepath.Join(cfg.WorkspacePath(), "skills", "weather")
+	require.NoError(t, os.MkdirAll(filepath.Join(skillDir, skills.ScenarioDirName), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(skillDir, "SKILL.md"),
+		[]byte("---\nname: weather\ndescription: Weather forecasts\n---\n# Weather\n"), 0o644))
+	scenario := filepath.Join(skillDir, skills.ScenarioDirName, "sunny.yaml")
+	require.NoError(t, os.WriteFile(scenario,
+		[]byte("prompt: Weather?\nscript:\n  - content: Sunny.\nexpect:\n  answer_matches: Sunny\n"), 0o644))
+	loader := skills.NewSkillsLoader(cfg.WorkspacePath(), "", "")
+
+	var out bytes.Buffer
+	require.NoError(t, skillsTestCmd(context.Background(), &out, cfg, loader, "weather", testOptions{}))
+	assert.Contains(t, out.String(), "✓ sunny (scripted")
+	assert.Contains(t, out.String(), "1 passed, 0 failed, 0 skipped")
+
+	report, err := skills.LoadScenarioReport(cfg.WorkspacePath(), "weather")
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	assert.True(t, report.OK())
+
+	require.NoError(t, os.WriteFile(scenario,
+		[]byte("prompt: Weather?\nscript:\n  - content: Rain.\nexpect:\n  answer_matches: Sunny\n"), 0o644))
+	out.Reset()
+	err = skillsTestCmd(context.Background(), &out, cfg, loader, "weather", testOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 scenario(s) failed")
+	assert.Contains(t, out.String(), "answer does not match /Sunny/")
+
+	err = skillsTestCmd(context.Background(), &out, cfg, loader, "nope", testOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
@@ -0,0 +1,26 @@
+package skills
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+func newUpdateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update [skill...]",
+		Short: "Update locked skills and rewrite skills.lock",
+		Example: `picoclaw skills update
+picoclaw skills update weather`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return err
+			}
+			return skillsUpdateCmd(cmd.OutOrStdout(), cfg, skills.NewRegistryManagerFromToolsConfig(cfg.Tools.Skills), args)
+		},
+	}
+
+	return cmd
+}
@@ -3,11 +3,13 @@ package status
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/cliui"
 	"github.com/sipeed/picoclaw/pkg/auth"
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/isolation"
 	"github.com/sipeed/picoclaw/pkg/providers"
 )
 
@@ -139,7 +141,40 @@ func statusCmd() {
 					fmt.Sprintf("%s (%s): %s", provider, cred.AuthMethod, st))
 			}
 		}
+
+		report.IsolationLines = isolationLines(cfg)
 	}
 
 	cliui.PrintStatus(report)
 }
+
+// isolationLines reports which isolation features the host can enforce for
+// the configured profiles.
+func isolationLines(cfg *config.Config) []string {
+	isolation.Configure(cfg)
+	report, err := isolation.Preflight()
+	yes := func(ok bool) string {
+		if ok {
+			return "✓"
+		}
+		return "✗"
+	}
+	defaultProfile := cfg.Isolation.DefaultProfile
+	if defaultProfile == "" {
+		defaultProfile = isolation.ProfileNone
+	}
+	lines := []string{
+		fmt.Sprintf("Platform: %s", report.Platform),
+		fmt.Sprintf("Filesystem sandbox: %s", yes(report.Filesystem)),
+		fmt.Sprintf("Default profile: %s (available: %s)", defaultProfile, strings.Join(report.Profiles, ", ")),
+		fmt.Sprintf("Rlimits: %s  Cgroup v2: %s  Network namespace: %s  Seccomp: %s",
+			yes(report.Rlimits), yes(report.Cgroup), yes(report.NetworkNamespace), yes(report.Seccomp)),
+	}
+	if err != nil {
+		lines = append(lines, "Error: "+err.Error())
+	}
+	for _, note := range report.Notes {
+		lines = append(lines, "Note: "+note)
+	}
+	return lines
+}
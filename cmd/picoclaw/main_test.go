@@ -41,11 +41,13 @@ func TestNewPicoclawCommand(t *testing.T) {
 		"auth",
 		"config",
 		"cron",
+		"evolution",
 		"gateway",
 		"mcp",
 		"migrate",
 		"model",
 		"onboard",
+		"routing",
 		"skills",
 		"status",
 		"update",
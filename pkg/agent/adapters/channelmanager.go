@@ -8,6 +8,7 @@ import (
 	"github.com/sipeed/picoclaw/pkg/agent/interfaces"
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/channels/outbox"
 )
 
 // channelManagerAdapter wraps *channels.Manager to implement interfaces.ChannelManager.
@@ -49,3 +50,8 @@ func (a *channelManagerAdapter) DismissToolFeedback(
 ) {
 	a.inner.DismissToolFeedback(ctx, channel, chatID, outboundCtx)
 }
+
+// Outbox exposes the manager's durable outbound queue, or nil when disabled.
+func (a *channelManagerAdapter) Outbox() *outbox.Store {
+	return a.inner.Outbox()
+}
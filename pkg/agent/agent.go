@@ -22,12 +22,14 @@ import (
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/constants"
 	runtimeevents "github.com/sipeed/picoclaw/pkg/events"
+	"github.com/sipeed/picoclaw/pkg/identity"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/media"
 	"github.com/sipeed/picoclaw/pkg/providers"
 	"github.com/sipeed/picoclaw/pkg/routing"
 	"github.com/sipeed/picoclaw/pkg/session"
 	"github.com/sipeed/picoclaw/pkg/state"
+	"github.com/sipeed/picoclaw/pkg/tools"
 	"github.com/sipeed/picoclaw/pkg/utils"
 )
 
@@ -37,6 +39,7 @@ type AgentLoop struct {
 	cfg      *config.Config
 	registry *AgentRegistry
 	state    *state.Manager
+	links    *identity.LinkStore
 
 	// Runtime event system
 	runtimeEvents      runtimeevents.Bus
@@ -47,20 +50,21 @@ type AgentLoop struct {
 	hooks              *HookManager
 
 	// Runtime state
-	running        atomic.Bool
-	contextManager ContextManager
-	fallback       *providers.FallbackChain
-	channelManager interfaces.ChannelManager
-	mediaStore     media.MediaStore
-	transcriber    asr.Transcriber
-	cmdRegistry    *commands.Registry
-	mcp            mcpRuntime
-	evolution      *evolutionBridge
-	hookRuntime    hookRuntime
-	steering       *steeringQueue
-	pendingSkills  sync.Map
-	pendingStops   sync.Map
-	mu             sync.RWMutex
+	running         atomic.Bool
+	contextManager  ContextManager
+	fallback        *providers.FallbackChain
+	channelManager  interfaces.ChannelManager
+	mediaStore      media.MediaStore
+	transcriber     asr.Transcriber
+	remoteDelegator tools.RemoteDelegator
+	cmdRegistry     *commands.Registry
+	mcp             mcpRuntime
+	evolution       *evolutionBridge
+	hookRuntime     hookRuntime
+	steering        *steeringQueue
+	pendingSkills   sync.Map
+	pendingStops    sync.Map
+	mu              sync.RWMutex
 
 	// workerSem limits concurrent turn processing workers.
 	workerSem chan struct{}
@@ -170,6 +174,9 @@ func (al *AgentLoop) Run(ctx context.Context) error {
 			if !ok {
 				return nil
 			}
+			if resolveApprovalCallback(msg) {
+				continue
+			}
 
 			// Resolve the session key for this message
 			sessionKey, agentID, ok := al.resolveSteeringTarget(msg)
@@ -460,6 +467,10 @@ func (al *AgentLoop) ReloadProviderAndConfig(
 		return fmt.Errorf("context canceled after registry creation: %w", err)
 	}
 
+	if al.links != nil {
+		registry.SetIdentityLinkSource(al.links)
+	}
+
 	// Ensure shared tools are re-registered on the new registry
 	registerSharedTools(al, cfg, al.bus, registry, provider)
 
@@ -492,7 +503,9 @@ func (al *AgentLoop) ReloadProviderAndConfig(
 	for _, agentID := range registry.ListAgentIDs() {
 		if agent, ok := registry.GetAgent(agentID); ok {
 			newRL.RegisterCandidates(agent.Candidates)
-			newRL.RegisterCandidates(agent.LightCandidates)
+			for _, tier := range agent.RoutingTiers {
+				newRL.RegisterCandidates(tier.Candidates)
+			}
 		}
 	}
 	al.fallback = providers.NewFallbackChain(providers.NewCooldownTracker(), newRL)
@@ -587,10 +600,10 @@ func (al *AgentLoop) runAgentLoop(
 		return "", err
 	}
 
-	// Record last channel for heartbeat notifications (skip internal channels and cli)
-	if opts.Dispatch.Channel() != "" &&
-		opts.Dispatch.ChatID() != "" &&
-		!constants.IsInternalChannel(opts.Dispatch.Channel()) {
+	// Record last channel for heartbeat notifications (skip internal and
+	// service channels, which have no chat to notify)
+	if opts.Dispatch.ChatID() != "" &&
+		constants.IsDeliverableChannel(opts.Dispatch.Channel()) {
 		channelKey := fmt.Sprintf("%s:%s", opts.Dispatch.Channel(), opts.Dispatch.ChatID())
 		if recordErr := al.RecordLastChannel(channelKey); recordErr != nil {
 			logger.WarnCF(
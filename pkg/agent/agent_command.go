@@ -5,13 +5,17 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels/outbox"
+	"github.com/sipeed/picoclaw/pkg/checkpoint"
 	"github.com/sipeed/picoclaw/pkg/commands"
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/identity"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/providers"
 )
@@ -280,6 +284,32 @@ func (al *AgentLoop) buildCommandsRuntime(
 		}
 		return al.stopActiveTurnForSession(opts.Dispatch.SessionKey)
 	}
+	if links := al.links; links != nil {
+		rt.IssueIdentityLink = links.IssueCode
+		rt.ConfirmIdentityLink = links.Redeem
+		rt.GetIdentityLinks = links.LinkedIdentities
+		rt.UnlinkIdentity = func(channel, senderID string) (string, error) {
+			canonical, err := links.Unlink(identity.BuildCanonicalID(channel, senderID))
+			if errors.Is(err, identity.ErrNotLinked) {
+				return "", nil
+			}
+			return canonical, err
+		}
+	}
+	if store := al.channelOutbox(); store != nil {
+		rt.GetChannelDelivery = func(channel string) (*commands.ChannelDeliveryStatus, error) {
+			return channelDeliveryStatus(store, channel)
+		}
+		rt.ReplayChannelDelivery = func(channel, id string) (int, error) {
+			if id == "" {
+				return store.ReplayChannel(channel)
+			}
+			if _, err := store.Replay(channel, id); err != nil {
+				return 0, fmt.Errorf("replay %s: %w", id, err)
+			}
+			return 1, nil
+		}
+	}
 	if agent != nil && agent.ContextBuilder != nil {
 		rt.ListSkillNames = agent.ContextBuilder.ListSkillNames
 	}
@@ -368,10 +398,92 @@ func (al *AgentLoop) buildCommandsRuntime(
 				MessageCount:      len(history),
 			}
 		}
+
+		if store := agent.Checkpoints; store != nil && opts != nil {
+			sessionKey := opts.Dispatch.SessionKey
+			rt.ListCheckpoints = func() ([]commands.CheckpointInfo, error) {
+				list, err := sessionCheckpoints(store, sessionKey)
+				if err != nil {
+					return nil, err
+				}
+				infos := make([]commands.CheckpointInfo, 0, len(list))
+				for _, c := range list {
+					infos = append(infos, commands.CheckpointInfo{
+						ID:        c.ID,
+						CreatedAt: c.CreatedAt,
+						Reason:    c.Reason,
+						Summary:   c.Summary,
+						Files:     c.Paths(),
+						Restored:  c.RestoredAt != nil,
+					})
+				}
+				return infos, nil
+			}
+			rt.RestoreCheckpoint = func(id string) (*commands.CheckpointRestore, error) {
+				return restoreSessionCheckpoint(store, sessionKey, id)
+			}
+		}
+
+		if bridge := al.currentEvolutionBridge(); bridge != nil && bridge.runtime != nil &&
+			cfg != nil && cfg.Evolution.Enabled {
+			bindEvolutionDraftCommands(rt, bridge.runtime, agent.Workspace)
+		}
 	}
 	return rt
 }
 
+// sessionCheckpoints lists the checkpoints of a session, newest first.
+// Checkpoints taken outside any session (evolution applies, skill
+// reinstalls) are left to the evolution CLI and the web API.
+func sessionCheckpoints(store *checkpoint.Store, sessionKey string) ([]checkpoint.Checkpoint, error) {
+	if sessionKey == "" {
+		return nil, nil
+	}
+	return store.List(sessionKey)
+}
+
+// restoreSessionCheckpoint restores checkpoint id if it belongs to the
+// session, or undoes the session's newest checkpoint when id is empty.
+func restoreSessionCheckpoint(
+	store *checkpoint.Store,
+	sessionKey, id string,
+) (*commands.CheckpointRestore, error) {
+	var (
+		result checkpoint.RestoreResult
+		err    error
+	)
+	if id == "" {
+		if sessionKey == "" {
+			return nil, fmt.Errorf("no file changes to undo in this session")
+		}
+		var c checkpoint.Checkpoint
+		c, result, err = store.Undo(sessionKey)
+		if errors.Is(err, checkpoint.ErrNothingToUndo) {
+			return nil, fmt.Errorf("no file changes to undo in this session")
+		}
+		id = c.ID
+	} else {
+		c, getErr := store.Get(id)
+		if getErr != nil || sessionKey == "" || c.SessionKey != sessionKey {
+			return nil, fmt.Errorf("checkpoint %s not found in this session", id)
+		}
+		result, err = store.Restore(id)
+	}
+	if err != nil {
+		logger.WarnCF("agent", "Checkpoint restore failed", map[string]any{
+			"checkpoint": id,
+			"error":      err.Error(),
+		})
+		return nil, err
+	}
+	return &commands.CheckpointRestore{
+		ID:       id,
+		Restored: result.Restored,
+		Removed:  result.Removed,
+		Skipped:  result.Skipped,
+	}, nil
+}
+
 func summarizeMCPToolParameters(schema any) []commands.MCPToolParameterInfo {
 	schemaMap := normalizeMCPSchema(schema)
 	properties, ok := schemaMap["properties"].(map[string]any)
@@ -508,3 +620,34 @@ func (al *AgentLoop) clearPendingSkills(sessionKey string) {
 	}
 	al.pendingSkills.Delete(sessionKey)
 }
+
+// channelOutbox returns the channel manager's durable outbound queue, if any.
+func (al *AgentLoop) channelOutbox() *outbox.Store {
+	provider, ok := al.channelManager.(interface{ Outbox() *outbox.Store })
+	if !ok {
+		return nil
+	}
+	return provider.Outbox()
+}
+
+func channelDeliveryStatus(store *outbox.Store, channel string) (*commands.ChannelDeliveryStatus, error) {
+	summary, err := store.Summarize(channel)
+	if err != nil {
+		return nil, err
+	}
+	dead, err := store.Dead(channel)
+	if err != nil {
+		return nil, err
+	}
+	status := &commands.ChannelDeliveryStatus{Pending: summary.Pending, Failed: summary.Dead}
+	for _, entry := range dead {
+		status.RecentFailures = append(status.RecentFailures, commands.ChannelDeliveryFailure{
+			ID:       entry.ID,
+			ChatID:   entry.ChatID,
+			Error:    entry.LastError,
+			Attempts: entry.Attempts,
+			FailedAt: entry.DeadAt,
+		})
+	}
+	return status, nil
+}
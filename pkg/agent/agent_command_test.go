@@ -0,0 +1,55 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/checkpoint"
+)
+
+func TestSessionCheckpoints_ScopedToSession(t *testing.T) {
+	workspace := t.TempDir()
+	skill := filepath.Join(workspace, "skills", "weather", "SKILL.md")
+	if err := os.MkdirAll(filepath.Dir(skill), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(skill, []byte("v1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	store := checkpoint.NewStore(t.TempDir(), checkpoint.Options{})
+
+	// An evolution apply records its checkpoint outside any session.
+	evolution := store.Begin(workspace, "", "evolution apply", "weather")
+	if err := evolution.Capture(skill); err != nil {
+		t.Fatalf("Capture() error = %v", err)
+	}
+	own := store.Begin(workspace, "agent:main:telegram:1", "turn", "notes")
+	if err := own.Record("notes.txt", nil, false); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	for _, sessionKey := range []string{"agent:main:telegram:1", "agent:main:telegram:2", ""} {
+		list, err := sessionCheckpoints(store, sessionKey)
+		if err != nil {
+			t.Fatalf("sessionCheckpoints(%q) error = %v", sessionKey, err)
+		}
+		for _, c := range list {
+			if c.SessionKey != sessionKey {
+				t.Fatalf("sessionCheckpoints(%q) listed %s of session %q", sessionKey, c.ID, c.SessionKey)
+			}
+		}
+		if _, err := restoreSessionCheckpoint(store, sessionKey, evolution.ID()); err == nil {
+			t.Fatalf("session %q restored the evolution checkpoint", sessionKey)
+		}
+	}
+	if _, err := restoreSessionCheckpoint(store, "", ""); err == nil {
+		t.Fatal("undo without a session restored a checkpoint")
+	}
+	if _, err := restoreSessionCheckpoint(store, "agent:main:telegram:1", own.ID()); err != nil {
+		t.Fatalf("restoring the session's own checkpoint error = %v", err)
+	}
+	if c, err := store.Get(evolution.ID()); err != nil || c.RestoredAt != nil {
+		t.Fatalf("evolution checkpoint = %+v, %v; want untouched", c, err)
+	}
+}
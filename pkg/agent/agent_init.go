@@ -5,6 +5,7 @@ package agent
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,10 +16,13 @@ import (
 	"github.com/sipeed/picoclaw/pkg/commands"
 	"github.com/sipeed/picoclaw/pkg/config"
 	runtimeevents "github.com/sipeed/picoclaw/pkg/events"
+	"github.com/sipeed/picoclaw/pkg/identity"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/routing"
 	"github.com/sipeed/picoclaw/pkg/skills"
 	"github.com/sipeed/picoclaw/pkg/state"
+	"github.com/sipeed/picoclaw/pkg/team"
 	"github.com/sipeed/picoclaw/pkg/tools"
 )
 
@@ -38,16 +42,24 @@ func NewAgentLoop(
 	for _, agentID := range registry.ListAgentIDs() {
 		if agent, ok := registry.GetAgent(agentID); ok {
 			rl.RegisterCandidates(agent.Candidates)
-			rl.RegisterCandidates(agent.LightCandidates)
+			for _, tier := range agent.RoutingTiers {
+				rl.RegisterCandidates(tier.Candidates)
+			}
 		}
 	}
 	fallbackChain := providers.NewFallbackChain(cooldown, rl)
 
 	// Create state manager using default agent's workspace for channel recording
 	defaultAgent := registry.GetDefaultAgent()
-	var stateManager *state.Manager
+	var (
+		stateManager *state.Manager
+		links        *identity.LinkStore
+	)
 	if defaultAgent != nil {
 		stateManager = state.NewManager(defaultAgent.Workspace)
+		// Shared with the web identity-link API through routing.DefaultAgentWorkspace.
+		links = identity.NewLinkStore(identity.DefaultLinkStorePath(state.ResolveDir(routing.DefaultAgentWorkspace(cfg))))
+		registry.SetIdentityLinkSource(links)
 	}
 
 	bridge, err := newEvolutionBridge(registry, cfg, provider)
@@ -68,6 +80,7 @@ func NewAgentLoop(
 		cfg:               cfg,
 		registry:          registry,
 		state:             stateManager,
+		links:             links,
 		fallback:          fallbackChain,
 		cmdRegistry:       commands.NewRegistry(commands.BuiltinDefinitions()),
 		evolution:         bridge,
@@ -120,6 +133,7 @@ func registerSharedTools(
 			logger.WarnCF("voice-tts", "send_tts enabled but no TTS provider configured", nil)
 		}
 	}
+	teams := configuredTeams(cfg, registry)
 
 	for _, agentID := range registry.ListAgentIDs() {
 		agent, ok := registry.GetAgent(agentID)
@@ -148,8 +162,24 @@ func registerSharedTools(
 				agent.Tools.Register(fetchTool)
 			}
 		}
+		if cfg.Tools.IsToolEnabled("http_request") {
+			httpTool, err := tools.NewHTTPRequestTool(cfg.Tools.HTTPRequest, cfg.Tools.Web.Proxy)
+			if err != nil {
+				logger.ErrorCF("agent", "Failed to create http_request tool", map[string]any{"error": err.Error()})
+			} else {
+				agent.Tools.Register(httpTool)
+			}
+		}
+		if cfg.Tools.IsToolEnabled("home") {
+			homeTool, err := tools.NewHomeTool(cfg.Tools.Home)
+			if err != nil {
+				logger.ErrorCF("agent", "Failed to create home tool", map[string]any{"error": err.Error()})
+			} else {
+				agent.Tools.Register(homeTool)
+			}
+		}
 
-		// Hardware tools (I2C, SPI) - Linux only, returns error on other platforms
+		// Hardware tools (I2C, SPI, GPIO, PWM, 1-Wire, CAN) - Linux only, returns error on other platforms
 		if cfg.Tools.IsToolEnabled("i2c") {
 			agent.Tools.Register(tools.NewI2CTool())
 		}
@@ -159,6 +189,31 @@ func registerSharedTools(
 		if cfg.Tools.IsToolEnabled("serial") {
 			agent.Tools.Register(tools.NewSerialTool())
 		}
+		if cfg.Tools.IsToolEnabled("gpio") {
+			agent.Tools.Register(tools.NewGPIOTool())
+		}
+		if cfg.Tools.IsToolEnabled("pwm") {
+			agent.Tools.Register(tools.NewPWMTool())
+		}
+		if cfg.Tools.IsToolEnabled("onewire") {
+			agent.Tools.Register(tools.NewOneWireTool())
+		}
+		if cfg.Tools.IsToolEnabled("modbus") {
+			modbusTool, err := tools.NewModbusTool(cfg.Tools.Modbus)
+			if err != nil {
+				logger.ErrorCF("agent", "Failed to create modbus tool", map[string]any{"error": err.Error()})
+			} else {
+				agent.Tools.Register(modbusTool)
+			}
+		}
+		if cfg.Tools.IsToolEnabled("canbus") {
+			canTool, err := tools.NewCANBusTool(cfg.Tools.CANBus)
+			if err != nil {
+				logger.ErrorCF("agent", "Failed to create canbus tool", map[string]any{"error": err.Error()})
+			} else {
+				agent.Tools.Register(canTool)
+			}
+		}
 
 		// Message tool
 		if cfg.Tools.IsToolEnabled("message") {
@@ -216,6 +271,34 @@ func registerSharedTools(
 				defer pubCancel()
 				return msgBus.PublishOutbound(pubCtx, outboundMessage)
 			})
+			messageTool.SetInteractiveCallback(func(
+				ctx context.Context,
+				channel, chatID, content, replyToMessageID string,
+				payload *bus.InteractivePayload,
+			) error {
+				outboundAgentID, outboundSessionKey, outboundScope := outboundTurnMetadata(
+					tools.ToolAgentID(ctx),
+					tools.ToolSessionKey(ctx),
+					tools.ToolSessionScope(ctx),
+				)
+				outboundMessage := bus.OutboundMessage{
+					Channel:          channel,
+					ChatID:           chatID,
+					Context:          bus.NewOutboundContext(channel, chatID, replyToMessageID),
+					AgentID:          outboundAgentID,
+					SessionKey:       outboundSessionKey,
+					Scope:            outboundScope,
+					Content:          content,
+					ReplyToMessageID: replyToMessageID,
+					Interactive:      payload,
+				}
+				if al.channelManager != nil && channel != "" {
+					return al.channelManager.SendMessage(ctx, outboundMessage)
+				}
+				pubCtx, pubCancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer pubCancel()
+				return msgBus.PublishOutbound(pubCtx, outboundMessage)
+			})
 			agent.Tools.Register(messageTool)
 		}
 		if cfg.Tools.IsToolEnabled("reaction") {
@@ -389,12 +472,16 @@ func registerSharedTools(
 		}
 
 		// Register delegate tool for multi-agent setups.
-		// Auto-enabled when multiple agents exist. Delegation uses the SubTurn
+		// Auto-enabled when multiple agents exist or swarm mode lets agents
+		// of other instances take tasks. Delegation uses the SubTurn
 		// mechanism directly (not SubagentManager) and is independent of the
 		// subagent tool.
-		if len(registry.ListAgentIDs()) > 1 {
+		if len(registry.ListAgentIDs()) > 1 || cfg.Swarm.Enabled {
 			delegateTool := tools.NewDelegateTool()
 			delegateTool.SetSpawner(NewSubTurnSpawner(al))
+			if remote := al.RemoteDelegator(); remote != nil {
+				delegateTool.SetRemoteDelegator(remote)
+			}
 			currentAgentID := agentID
 			delegateTool.SetSelfAgentID(currentAgentID)
 			delegateTool.SetAllowlistChecker(func(targetAgentID string) bool {
@@ -403,6 +490,44 @@ func registerSharedTools(
 			agent.Tools.Register(delegateTool)
 		}
 
+		// Register the team tool when teams are configured. Members run as
+		// sub-turns of the calling agent and talk through a blackboard that
+		// lives for one team task.
+		if len(teams) > 0 {
+			teamTool := team.NewTeamTool(teams)
+			teamTool.SetSpawner(NewSubTurnSpawner(al))
+			teamTool.SetEventBus(al.runtimeEvents)
+			agent.Tools.Register(teamTool)
+		}
+
 		warnOnUnknownAgentToolDeclarations(agentID, agent.Workspace, agent.Definition, agent.Tools)
 	}
 }
+
+// configuredTeams returns the teams whose members are all registered agents.
+// Teams naming unknown agents are skipped with a warning.
+func configuredTeams(cfg *config.Config, registry *AgentRegistry) []config.TeamConfig {
+	teams := make([]config.TeamConfig, 0, len(cfg.Agents.Teams))
+	for _, t := range cfg.Agents.Teams {
+		if strings.TrimSpace(t.ID) == "" || len(t.Members) == 0 {
+			logger.WarnCF("agent", "Skipping team without id or members", map[string]any{"team": t.ID})
+			continue
+		}
+		members := append([]string{t.EffectiveLead()}, t.Members...)
+		var unknown []string
+		for _, member := range members {
+			if _, ok := registry.GetAgent(member); !ok {
+				unknown = append(unknown, member)
+			}
+		}
+		if len(unknown) > 0 {
+			logger.WarnCF("agent", "Skipping team with unknown agents", map[string]any{
+				"team":    t.ID,
+				"unknown": unknown,
+			})
+			continue
+		}
+		teams = append(teams, t)
+	}
+	return teams
+}
@@ -52,6 +52,26 @@ func (al *AgentLoop) SetMediaStore(s media.MediaStore) {
 	})
 }
 
+// SetRemoteDelegator lets the delegate tool reach agents of other
+// instances. It also applies to tools registered by later reloads.
+func (al *AgentLoop) SetRemoteDelegator(remote tools.RemoteDelegator) {
+	al.mu.Lock()
+	al.remoteDelegator = remote
+	al.mu.Unlock()
+
+	al.GetRegistry().ForEachTool("delegate", func(t tools.Tool) {
+		if dt, ok := t.(*tools.DelegateTool); ok {
+			dt.SetRemoteDelegator(remote)
+		}
+	})
+}
+
+func (al *AgentLoop) RemoteDelegator() tools.RemoteDelegator {
+	al.mu.RLock()
+	defer al.mu.RUnlock()
+	return al.remoteDelegator
+}
+
 func (al *AgentLoop) SetTranscriber(t asr.Transcriber) {
 	al.transcriber = t
 }
@@ -9,13 +9,18 @@ package agent
 import (
 	"bytes"
 	"encoding/base64"
+	"errors"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/h2non/filetype"
 
+	"github.com/sipeed/picoclaw/pkg/document"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/media"
 	"github.com/sipeed/picoclaw/pkg/providers"
@@ -55,7 +60,8 @@ func currentTurnMessages(messages []providers.Message, currentTurnStart int) []p
 // LLM APIs enforce.
 // Only tool messages from the current turn may emit the synthetic user
 // follow-up; historical tool results stay as plain path-tagged history.
-// Non-image files always get path tags regardless of role.
+// Non-image files always get path tags regardless of role. PDF, Office and
+// EPUB attachments on current-turn user messages also get a text preview.
 // Returns a new slice; original messages are not mutated.
 func resolveMediaRefs(
 	messages []providers.Message,
@@ -90,7 +96,7 @@ func resolveMediaRefs(
 
 		msg := m
 		resolved := make([]string, 0, len(m.Media))
-		var pathTags []string
+		var pathTags, previews []string
 
 		for _, ref := range m.Media {
 			if !strings.HasPrefix(ref, "media://") {
@@ -124,6 +130,12 @@ func resolveMediaRefs(
 			mime := detectMIME(localPath, meta)
 			pathTags = append(pathTags, buildPathTag(mime, localPath))
 
+			if m.Role == "user" && idx >= currentTurnStart {
+				if preview := documentPreview(localPath, meta.Filename, mime, info); preview != "" {
+					previews = append(previews, preview)
+				}
+			}
+
 			if m.Role == "tool" && idx >= currentTurnStart && strings.HasPrefix(mime, "image/") {
 				dataURL := encodeImageToDataURL(localPath, mime, info, maxSize)
 				if dataURL != "" {
@@ -136,6 +148,9 @@ func resolveMediaRefs(
 		if len(pathTags) > 0 {
 			msg.Content = injectPathTags(msg.Content, pathTags)
 		}
+		if len(previews) > 0 {
+			msg.Content = appendDocumentPreviews(msg.Content, previews, looksLikeJSON(m.Content))
+		}
 		result = append(result, msg)
 
 		// If this is the last message and we have pending images, flush them.
@@ -306,6 +321,105 @@ func injectPathTags(content string, tags []string) string {
 	return content
 }
 
+// documentPreviewMaxBytes bounds the extracted text inlined for one
+// attachment; the rest is left for read_file with pages.
+const documentPreviewMaxBytes = 6000
+
+// documentPreviewCache keeps rendered previews across the repeated
+// resolveMediaRefs calls of a turn's tool loop, keyed by path, size and
+// modification time.
+var documentPreviewCache = struct {
+	sync.Mutex
+	entries map[string]string
+}{entries: map[string]string{}}
+
+const documentPreviewCacheSize = 32
+
+// documentPreview extracts the leading text of a PDF, Office or EPUB
+// attachment. Returns "" for other files and when extraction fails.
+func documentPreview(localPath, filename, mime string, info os.FileInfo) string {
+	name := filename
+	if name == "" {
+		name = filepath.Base(localPath)
+	}
+	format := document.Detect(name, mime, nil)
+	if !format.Binary() || info.Size() > document.MaxSize {
+		return ""
+	}
+
+	key := fmt.Sprintf("%s|%d|%d", localPath, info.Size(), info.ModTime().UnixNano())
+	documentPreviewCache.Lock()
+	cached, ok := documentPreviewCache.entries[key]
+	documentPreviewCache.Unlock()
+	if ok {
+		return cached
+	}
+
+	preview := renderDocumentPreview(localPath, name, format)
+
+	documentPreviewCache.Lock()
+	if len(documentPreviewCache.entries) >= documentPreviewCacheSize {
+		clear(documentPreviewCache.entries)
+	}
+	documentPreviewCache.entries[key] = preview
+	documentPreviewCache.Unlock()
+	return preview
+}
+
+func renderDocumentPreview(localPath, name string, format document.Format) string {
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		return ""
+	}
+	doc, err := document.Extract(data, format)
+	if errors.Is(err, document.ErrEncrypted) {
+		return fmt.Sprintf("[document: %s | %s | password-protected, text not extracted]", name, format)
+	}
+	if err != nil {
+		logger.DebugCF("agent", "Document preview extraction failed", map[string]any{
+			"path":  localPath,
+			"error": err.Error(),
+		})
+		return ""
+	}
+
+	unit := format.Unit()
+	total := len(doc.Sections)
+	header := fmt.Sprintf("[document: %s | %s]", name, format)
+	if total > 1 {
+		header = fmt.Sprintf("[document: %s | %s | %d %ss]", name, format, total, unit)
+	}
+	if doc.Empty() {
+		return header + "\n[No extractable text; the document may contain only scanned images.]"
+	}
+
+	all, _ := doc.Select("")
+	text, full, cut := doc.RenderBudget(all, documentPreviewMaxBytes)
+	var footer string
+	switch {
+	case cut:
+		footer = fmt.Sprintf("\n[Preview cut inside %s 1. Call read_file on %s with pages=\"1\" to read it in full.]",
+			unit, localPath)
+	case full < total:
+		footer = fmt.Sprintf("\n[Preview shows %ss %s. Call read_file on %s with pages=%q to read more.]",
+			unit, document.FormatRanges(all[:full]), localPath, document.FormatRanges(all[full:]))
+	}
+	return header + "\n" + text + footer
+}
+
+// appendDocumentPreviews adds previews after the message text, or before a
+// structured (JSON) payload so the payload stays parseable.
+func appendDocumentPreviews(content string, previews []string, structured bool) string {
+	block := strings.Join(previews, "\n\n")
+	if structured {
+		return block + "\n" + content
+	}
+	if content == "" {
+		return block
+	}
+	return content + "\n\n" + block
+}
+
 func looksLikeJSON(s string) bool {
 	s = strings.TrimSpace(s)
 	return len(s) > 1 && s[0] == '{'
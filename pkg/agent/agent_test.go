@@ -1,6 +1,7 @@
 package agent
 
 import (
+	"archive/zip"
 	"context"
 	"encoding/json"
 	"errors"
@@ -4153,6 +4154,82 @@ func TestProcessMessage_ModelRoutingUsesLightProvider(t *testing.T) {
 	}
 }
 
+func TestProcessMessage_ModelRoutingEscalatesEmptyResponse(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	heavyCalls := 0
+	heavyServer := newStrictChatCompletionTestServer(t, "heavy", "gpt-4o", "heavy reply", &heavyCalls)
+	defer heavyServer.Close()
+
+	lightCalls := 0
+	lightServer := newStrictChatCompletionTestServer(t, "light", "qwen2.5:0.5b", "", &lightCalls)
+	defer lightServer.Close()
+
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         tmpDir,
+				ModelName:         "gemini-main",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+				Routing: &config.RoutingConfig{
+					Enabled:    true,
+					Threshold:  0.99,
+					Tiers:      []config.RoutingTierConfig{{Name: "nano", Model: "qwen-light"}},
+					Escalation: config.RoutingEscalationConfig{Enabled: true},
+				},
+			},
+		},
+		ModelList: []*config.ModelConfig{
+			{
+				ModelName: "gemini-main",
+				Model:     "openai/gpt-4o",
+				APIBase:   heavyServer.URL,
+				APIKeys:   config.SimpleSecureStrings("heavy-key"),
+			},
+			{
+				ModelName: "qwen-light",
+				Model:     "ollama/qwen2.5:0.5b",
+				APIBase:   lightServer.URL,
+				APIKeys:   config.SimpleSecureStrings("light-key"),
+			},
+		},
+	}
+
+	provider, _, err := providers.CreateProvider(cfg)
+	if err != nil {
+		t.Fatalf("CreateProvider() error = %v", err)
+	}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	runtimeCh, closeRuntimeEvents := subscribeRuntimeEventsForTest(t, al, 16, runtimeevents.KindAgentLLMRetry)
+	defer closeRuntimeEvents()
+
+	resp := testHelper{al: al}.executeAndGetResponse(t, context.Background(), bus.InboundMessage{
+		Channel:  "telegram",
+		SenderID: "user1",
+		ChatID:   "chat1",
+		Content:  "hi",
+	})
+	if resp != "heavy reply" {
+		t.Fatalf("response = %q, want %q", resp, "heavy reply")
+	}
+	if lightCalls != 1 || heavyCalls != 1 {
+		t.Fatalf("light/heavy calls = %d/%d, want 1/1", lightCalls, heavyCalls)
+	}
+
+	evt := waitForRuntimeEvent(t, runtimeCh, time.Second, func(runtimeevents.Event) bool { return true })
+	payload, ok := evt.Payload.(LLMRetryPayload)
+	if !ok {
+		t.Fatalf("payload = %T, want LLMRetryPayload", evt.Payload)
+	}
+	if payload.Reason != "escalation" || payload.Error != "empty_response" {
+		t.Fatalf("retry reason/error = %q/%q, want escalation/empty_response", payload.Reason, payload.Error)
+	}
+	if payload.FromTier != "nano" || payload.ToTier != routing.PrimaryTier {
+		t.Fatalf("tiers = %q -> %q, want nano -> %q", payload.FromTier, payload.ToTier, routing.PrimaryTier)
+	}
+}
+
 // TestProcessMessage_FallbackUsesPerCandidateProvider is the loop-level test for
 // bug #2140. It verifies that when the primary model returns a rate-limit error
 // the fallback closure routes the retry to the fallback model's own provider
@@ -7523,6 +7600,44 @@ func TestResolveMediaRefs_PDFInjectsFilePath(t *testing.T) {
 	}
 }
 
+func TestResolveMediaRefs_DocumentAddsTextPreview(t *testing.T) {
+	store := media.NewFileMediaStore()
+	dir := t.TempDir()
+
+	docPath := filepath.Join(dir, "upload.bin")
+	f, err := os.Create(docPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, _ := zw.Create("word/document.xml")
+	w.Write([]byte(`<document><body><p><r><t>Invoice total: 42 EUR</t></r></p></body></document>`))
+	zw.Close()
+	f.Close()
+	ref, _ := store.Store(docPath, media.MediaMeta{Filename: "invoice.docx"}, "test")
+
+	messages := []providers.Message{
+		{Role: "user", Content: "please check [file]", Media: []string{ref}},
+		{Role: "user", Content: "{\"type\":\"post\"}", Media: []string{ref}},
+	}
+	result := resolveMediaRefs(messages, store, config.DefaultMaxMediaSize, 0)
+
+	want := "please check [file:" + docPath + "]\n\n[document: invoice.docx | docx]\nInvoice total: 42 EUR"
+	if result[0].Content != want {
+		t.Fatalf("content = %q, want %q", result[0].Content, want)
+	}
+	if !strings.HasPrefix(result[1].Content, "[document: invoice.docx") ||
+		!strings.HasSuffix(result[1].Content, `{"type":"post"}`) {
+		t.Fatalf("structured content = %q", result[1].Content)
+	}
+
+	// Historical messages keep path tags only.
+	result = resolveMediaRefs(messages[:1], store, config.DefaultMaxMediaSize, 1)
+	if strings.Contains(result[0].Content, "[document:") {
+		t.Fatalf("historical content got a preview: %q", result[0].Content)
+	}
+}
+
 func TestResolveMediaRefs_AudioInjectsAudioPath(t *testing.T) {
 	store := media.NewFileMediaStore()
 	dir := t.TempDir()
@@ -553,11 +553,12 @@ func hasMediaRefs(messages []providers.Message) bool {
 	return false
 }
 
-func sideQuestionModelName(agent *AgentInstance, usedLight bool) string {
-	if usedLight && len(agent.LightCandidates) > 0 {
-		if name := resolvedCandidateModelName(agent.LightCandidates, ""); name != "" {
+func sideQuestionModelName(agent *AgentInstance, tier int) string {
+	if tier >= 0 && tier < len(agent.RoutingTiers) {
+		if name := resolvedCandidateModelName(agent.RoutingTiers[tier].Candidates, ""); name != "" {
 			return name
 		}
+		return agent.RoutingTiers[tier].ModelName
 	}
 	return agent.Model
 }
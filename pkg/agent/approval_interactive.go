@@ -0,0 +1,142 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// InteractiveApprovalHookName is the builtin hook that asks the chat a tool
+// call came from to approve it with a confirmation card.
+const InteractiveApprovalHookName = "interactive_approval"
+
+// interactiveApprovalConfig is the "config" of the interactive_approval
+// builtin. Tools lists the tools that need approval; "*" matches every tool.
+type interactiveApprovalConfig struct {
+	Tools []string `json:"tools"`
+}
+
+// interactiveApprover implements ToolApprover by sending a confirmation card
+// and waiting for the press. The wait is bounded by the hook manager's
+// approval timeout (hooks.defaults.approval_timeout_ms).
+type interactiveApprover struct {
+	tools []string
+	send  func(ctx context.Context, msg bus.OutboundMessage) error
+}
+
+type approvalWaiter struct {
+	channel  string
+	chatID   string
+	senderID string
+	decision chan bool
+}
+
+var (
+	// approvalWaiters holds the pending approvals by card id. It is shared by
+	// every loop of the process because the callback can reach any worker.
+	approvalWaiters sync.Map
+	approvalSeq     atomic.Uint64
+)
+
+func newInteractiveApprover(al *AgentLoop, spec config.BuiltinHookConfig) (*interactiveApprover, error) {
+	var cfg interactiveApprovalConfig
+	if len(spec.Config) > 0 {
+		if err := json.Unmarshal(spec.Config, &cfg); err != nil {
+			return nil, fmt.Errorf("decode %s config: %w", InteractiveApprovalHookName, err)
+		}
+	}
+	if len(cfg.Tools) == 0 {
+		return nil, fmt.Errorf("%s needs at least one tool in config.tools", InteractiveApprovalHookName)
+	}
+	return &interactiveApprover{
+		tools: cfg.Tools,
+		send: func(ctx context.Context, msg bus.OutboundMessage) error {
+			if al.channelManager != nil {
+				return al.channelManager.SendMessage(ctx, msg)
+			}
+			return al.bus.PublishOutbound(ctx, msg)
+		},
+	}, nil
+}
+
+func (a *interactiveApprover) ApproveTool(ctx context.Context, req *ToolApprovalRequest) (ApprovalDecision, error) {
+	if !slices.Contains(a.tools, req.Tool) && !slices.Contains(a.tools, "*") {
+		return ApprovalDecision{Approved: true}, nil
+	}
+	var inbound *bus.InboundContext
+	if req.Context != nil {
+		inbound = req.Context.Inbound
+	}
+	if inbound == nil || inbound.Channel == "" || inbound.ChatID == "" {
+		return ApprovalDecision{Reason: "no chat to ask for approval"}, nil
+	}
+
+	id := "approve-" + strconv.FormatUint(approvalSeq.Add(1), 36) + strconv.FormatInt(time.Now().UnixNano()%1e6, 36)
+	waiter := &approvalWaiter{
+		channel:  inbound.Channel,
+		chatID:   inbound.ChatID,
+		senderID: inbound.SenderID,
+		decision: make(chan bool, 1),
+	}
+	approvalWaiters.Store(id, waiter)
+	defer approvalWaiters.Delete(id)
+
+	args, _ := json.Marshal(req.Arguments)
+	err := a.send(ctx, bus.OutboundMessage{
+		Channel:     inbound.Channel,
+		ChatID:      inbound.ChatID,
+		Context:     bus.NewOutboundContext(inbound.Channel, inbound.ChatID, ""),
+		Content:     utils.Truncate(string(args), 500),
+		Interactive: bus.NewConfirmationPayload(id, fmt.Sprintf("Allow %s?", req.Tool), "Allow", "Deny"),
+	})
+	if err != nil {
+		return ApprovalDecision{}, fmt.Errorf("send approval request: %w", err)
+	}
+
+	select {
+	case approved := <-waiter.decision:
+		if !approved {
+			return ApprovalDecision{Reason: "denied in chat"}, nil
+		}
+		return ApprovalDecision{Approved: true}, nil
+	case <-ctx.Done():
+		return ApprovalDecision{}, ctx.Err()
+	}
+}
+
+// resolveApprovalCallback delivers a confirmation card press to the tool call
+// waiting for it. It reports whether msg was consumed. Presses from another
+// chat, or from someone other than the requester, are ignored.
+func resolveApprovalCallback(msg bus.InboundMessage) bool {
+	if msg.Interaction == nil {
+		return false
+	}
+	id, action, ok := strings.Cut(msg.Interaction.ActionID, ":")
+	if !ok || (action != "confirm" && action != "cancel") {
+		return false
+	}
+	v, ok := approvalWaiters.Load(id)
+	if !ok {
+		return false
+	}
+	waiter := v.(*approvalWaiter)
+	if msg.Channel != waiter.channel || msg.ChatID != waiter.chatID ||
+		(waiter.senderID != "" && msg.SenderID != waiter.senderID) {
+		return false
+	}
+	select {
+	case waiter.decision <- action == "confirm":
+	default:
+	}
+	return true
+}
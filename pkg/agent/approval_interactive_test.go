@@ -0,0 +1,103 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+func TestInteractiveApprover_WaitsForConfirmationCard(t *testing.T) {
+	sent := make(chan bus.OutboundMessage, 1)
+	approver := &interactiveApprover{
+		tools: []string{"exec"},
+		send: func(_ context.Context, msg bus.OutboundMessage) error {
+			sent <- msg
+			return nil
+		},
+	}
+	req := &ToolApprovalRequest{
+		Tool:      "exec",
+		Arguments: map[string]any{"command": "rm -rf build"},
+		Context: &TurnContext{Inbound: &bus.InboundContext{
+			Channel: "telegram", ChatID: "42", SenderID: "alice",
+		}},
+	}
+
+	// press answers the pending card as if a button had been pressed in
+	// chatID by senderID, leaving the card in sent for the next press.
+	press := func(button int, chatID, senderID string) bool {
+		msg := <-sent
+		sent <- msg
+		return resolveApprovalCallback(bus.InboundMessage{
+			Channel:     "telegram",
+			ChatID:      chatID,
+			SenderID:    senderID,
+			Interaction: &bus.InteractionCallback{ActionID: msg.Interactive.Rows[0][button].ID},
+		})
+	}
+
+	for _, tc := range []struct {
+		button int
+		want   bool
+	}{
+		{button: 0, want: true},
+		{button: 1, want: false},
+	} {
+		done := make(chan ApprovalDecision, 1)
+		go func() {
+			decision, err := approver.ApproveTool(context.Background(), req)
+			if err != nil {
+				t.Errorf("ApproveTool() error = %v", err)
+			}
+			done <- decision
+		}()
+
+		if press(tc.button, "other-chat", "alice") {
+			t.Fatal("press from another chat was consumed")
+		}
+		if press(tc.button, "42", "mallory") {
+			t.Fatal("press from another sender was consumed")
+		}
+		if !press(tc.button, "42", "alice") {
+			t.Fatalf("button %d press was not consumed", tc.button)
+		}
+		msg := <-sent
+		if msg.Interactive.Kind != bus.InteractiveKindConfirm || !strings.Contains(msg.Content, "rm -rf build") {
+			t.Fatalf("sent %+v, want a confirmation card describing the call", msg)
+		}
+
+		select {
+		case decision := <-done:
+			if decision.Approved != tc.want {
+				t.Fatalf("button %d: Approved = %v, want %v", tc.button, decision.Approved, tc.want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("button %d: ApproveTool did not return", tc.button)
+		}
+	}
+}
+
+func TestInteractiveApprover_SkipsUnlistedTools(t *testing.T) {
+	approver := &interactiveApprover{
+		tools: []string{"exec"},
+		send: func(context.Context, bus.OutboundMessage) error {
+			t.Fatal("unexpected approval request")
+			return nil
+		},
+	}
+	decision, err := approver.ApproveTool(context.Background(), &ToolApprovalRequest{Tool: "read_file"})
+	if err != nil || !decision.Approved {
+		t.Fatalf("ApproveTool() = %+v, %v, want approved", decision, err)
+	}
+}
+
+func TestInteractiveApprover_DeniesWithoutChat(t *testing.T) {
+	approver := &interactiveApprover{tools: []string{"*"}}
+	decision, err := approver.ApproveTool(context.Background(), &ToolApprovalRequest{Tool: "exec"})
+	if err != nil || decision.Approved {
+		t.Fatalf("ApproveTool() = %+v, %v, want denied", decision, err)
+	}
+}
@@ -66,6 +66,15 @@ func (cb *ContextBuilder) WithToolDiscovery(useBM25, useRegex bool) *ContextBuil
 	return cb
 }
 
+// WithSkillRequirements sets the checker that decides which skills are
+// flagged or hidden for unmet requirements.
+func (cb *ContextBuilder) WithSkillRequirements(checker *skills.RequirementChecker) *ContextBuilder {
+	if cb.skillsLoader != nil {
+		cb.skillsLoader.SetRequirementChecker(checker)
+	}
+	return cb
+}
+
 func (cb *ContextBuilder) WithSplitOnMarker(enabled bool) *ContextBuilder {
 	cb.splitOnMarker = enabled
 	return cb
@@ -443,6 +452,9 @@ func (cb *ContextBuilder) buildSkillsSummary(allowed []string) string {
 		if _, ok := allowedSet[strings.ToLower(strings.TrimSpace(s.Name))]; !ok {
 			continue
 		}
+		if s.PlatformUnsupported() {
+			continue
+		}
 		lines = append(lines, "  <skill>")
 		lines = append(lines, fmt.Sprintf("    <name>%s</name>", xmlEscapeForPrompt(s.Name)))
 		lines = append(
@@ -454,6 +466,15 @@ func (cb *ContextBuilder) buildSkillsSummary(allowed []string) string {
 			fmt.Sprintf("    <location>%s</location>", xmlEscapeForPrompt(s.Path)),
 		)
 		lines = append(lines, fmt.Sprintf("    <source>%s</source>", xmlEscapeForPrompt(s.Source)))
+		if unavailable := skills.UnavailableReason(s); unavailable != "" {
+			lines = append(
+				lines,
+				fmt.Sprintf("    <unavailable>%s</unavailable>", xmlEscapeForPrompt(unavailable)),
+			)
+		}
+		if s.Tampered {
+			lines = append(lines, "    <warning>"+skills.TamperedWarning+"</warning>")
+		}
 		lines = append(lines, "  </skill>")
 	}
 	if len(lines) == 1 {
@@ -1339,12 +1360,16 @@ func (cb *ContextBuilder) ResolveSkillName(name string) (string, bool) {
 func (cb *ContextBuilder) GetSkillsInfo() map[string]any {
 	allSkills := cb.skillsLoader.ListSkills()
 	skillNames := make([]string, 0, len(allSkills))
+	available := 0
 	for _, s := range allSkills {
 		skillNames = append(skillNames, s.Name)
+		if s.Available() {
+			available++
+		}
 	}
 	return map[string]any{
 		"total":     len(allSkills),
-		"available": len(allSkills),
+		"available": available,
 		"names":     skillNames,
 	}
 }
@@ -53,6 +53,10 @@ type TurnEndPayload struct {
 	SkillContextSnapshots []SkillContextSnapshot
 	ToolKinds             []string
 	ToolExecutions        []ToolExecutionRecord
+	// RoutingTier is "light" or "primary" when model routing picked the
+	// model, and empty when routing is off.
+	RoutingTier  string
+	RoutingModel string
 }
 
 // LLMRequestPayload describes an outbound LLM request.
@@ -84,6 +88,12 @@ type LLMRetryPayload struct {
 	Reason     string
 	Error      string
 	Backoff    time.Duration
+	// FromTier, ToTier, FromModel and ToModel are set when Reason is
+	// "escalation": the turn moved up a model routing tier.
+	FromTier  string
+	ToTier    string
+	FromModel string
+	ToModel   string
 }
 
 // ContextCompressReason identifies why emergency compression ran.
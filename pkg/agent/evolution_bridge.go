@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/sipeed/picoclaw/pkg/checkpoint"
 	"github.com/sipeed/picoclaw/pkg/config"
 	runtimeevents "github.com/sipeed/picoclaw/pkg/events"
 	"github.com/sipeed/picoclaw/pkg/evolution"
@@ -60,8 +61,10 @@ func newEvolutionBridge(
 			return evolution.NewLLMTaskSuccessJudge(provider, modelID, &evolution.HeuristicSuccessJudge{})
 		},
 		ApplierFactory: func(workspace string) *evolution.Applier {
-			return evolution.NewApplier(evolution.NewPaths(workspace, cfg.Evolution.StateDir), nil)
+			return evolution.NewApplier(evolution.NewPaths(workspace, cfg.Evolution.StateDir), nil).
+				WithCheckpoints(registryCheckpointStore(registry, workspace))
 		},
+		DraftTester: &skillScenarioTester{cfg: cfg, provider: provider},
 	})
 	if err != nil {
 		return nil, err
@@ -201,6 +204,8 @@ func (b *evolutionBridge) handleTurnEndAsync(meta EventMeta, payload TurnEndPayl
 		AttemptedSkillNames:   append([]string(nil), payload.AttemptedSkills...),
 		FinalSuccessfulPath:   append([]string(nil), payload.FinalSuccessfulPath...),
 		SkillContextSnapshots: toEvolutionSkillContextSnapshots(payload.SkillContextSnapshots),
+		RoutingTier:           payload.RoutingTier,
+		RoutingModel:          payload.RoutingModel,
 	}
 	if isEvolutionHeartbeatInput(input) {
 		return false
@@ -368,6 +373,23 @@ func registryWorkspaces(registry *AgentRegistry) []string {
 	return out
 }
 
+// registryCheckpointStore returns the checkpoint store of the agent that owns
+// workspace, or nil.
+func registryCheckpointStore(registry *AgentRegistry, workspace string) *checkpoint.Store {
+	if registry == nil {
+		return nil
+	}
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	for _, agent := range registry.agents {
+		if agent != nil && agent.Checkpoints != nil && agent.Workspace == workspace {
+			return agent.Checkpoints
+		}
+	}
+	return nil
+}
+
 type coldPathScheduleTime struct {
 	hour   int
 	minute int
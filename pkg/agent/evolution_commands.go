@@ -0,0 +1,91 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sipeed/picoclaw/pkg/commands"
+	"github.com/sipeed/picoclaw/pkg/evolution"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// bindEvolutionDraftCommands lets /evolution review the drafts of an agent's
+// workspace.
+func bindEvolutionDraftCommands(rt *commands.Runtime, evo *evolution.Runtime, workspace string) {
+	rt.ListEvolutionDrafts = func() ([]commands.EvolutionDraftInfo, error) {
+		drafts, err := evo.ListDrafts(workspace)
+		if err != nil {
+			return nil, err
+		}
+		infos := make([]commands.EvolutionDraftInfo, 0, len(drafts))
+		for _, draft := range drafts {
+			infos = append(infos, evolutionDraftInfo(draft))
+		}
+		return infos, nil
+	}
+	rt.ShowEvolutionDraft = func(id string) (*commands.EvolutionDraftDetail, error) {
+		detail, err := evo.DraftDetail(workspace, id)
+		if err != nil {
+			return nil, err
+		}
+		out := &commands.EvolutionDraftDetail{
+			EvolutionDraftInfo: evolutionDraftInfo(detail.Draft),
+			Diff:               detail.Preview.DiffPreview,
+			SuccessCount:       detail.SuccessCount,
+			JudgedCount:        detail.JudgedCount,
+		}
+		if detail.PreviewError != "" {
+			out.Findings = append(out.Findings, "preview: "+detail.PreviewError)
+		}
+		for _, task := range detail.Tasks {
+			out.Evidence = append(out.Evidence, evolutionEvidenceLine(task))
+		}
+		return out, nil
+	}
+	rt.AcceptEvolutionDraft = func(ctx context.Context, id, reviewer string) (*commands.EvolutionDraftInfo, error) {
+		draft, err := evo.AcceptDraft(ctx, workspace, id, reviewer)
+		if err != nil {
+			return nil, err
+		}
+		info := evolutionDraftInfo(draft)
+		return &info, nil
+	}
+	rt.RejectEvolutionDraft = func(id, reviewer, reason string) (*commands.EvolutionDraftInfo, error) {
+		draft, err := evo.RejectDraft(workspace, id, reviewer, reason)
+		if err != nil {
+			return nil, err
+		}
+		info := evolutionDraftInfo(draft)
+		return &info, nil
+	}
+}
+
+func evolutionDraftInfo(draft evolution.SkillDraft) commands.EvolutionDraftInfo {
+	return commands.EvolutionDraftInfo{
+		ID:          draft.ID,
+		TargetSkill: draft.TargetSkillName,
+		ChangeKind:  string(draft.ChangeKind),
+		Status:      string(draft.Status),
+		Summary:     draft.HumanSummary,
+		CreatedAt:   draft.CreatedAt,
+		ReviewedBy:  draft.ReviewedBy,
+		ReviewNotes: draft.ReviewNotes,
+		Findings:    draft.ScanFindings,
+	}
+}
+
+func evolutionEvidenceLine(task evolution.LearningRecord) string {
+	verdict := "unjudged"
+	if task.Success != nil {
+		verdict = "failed"
+		if *task.Success {
+			verdict = "succeeded"
+		}
+	}
+	summary := task.UserGoal
+	if summary == "" {
+		summary = task.Summary
+	}
+	return fmt.Sprintf("%s %s: %s", task.CreatedAt.Local().Format("2006-01-02"), verdict,
+		utils.Truncate(summary, 120))
+}
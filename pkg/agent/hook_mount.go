@@ -143,6 +143,13 @@ func (al *AgentLoop) loadConfiguredHooks(ctx context.Context) (err error) {
 	for _, name := range builtinNames {
 		spec := al.cfg.Hooks.Builtins[name]
 		factory, ok := lookupBuiltinHook(name)
+		if !ok && name == InteractiveApprovalHookName {
+			// Bound to this loop because it sends the card through its
+			// channels, so it cannot live in the global registry.
+			factory, ok = func(context.Context, config.BuiltinHookConfig) (any, error) {
+				return newInteractiveApprover(al, spec)
+			}, true
+		}
 		if !ok {
 			return fmt.Errorf("builtin hook %q is not registered", name)
 		}
@@ -235,6 +242,8 @@ func processHookOptionsFromConfig(spec config.ProcessHookConfig) (ProcessHookOpt
 		Command: append([]string(nil), spec.Command...),
 		Dir:     spec.Dir,
 		Env:     processHookEnvFromMap(spec.Env),
+
+		IsolationProfile: spec.IsolationProfile,
 	}
 
 	observeKinds, observeEnabled, err := processHookObserveKindsFromConfig(spec.Observe)
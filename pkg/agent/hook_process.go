@@ -33,6 +33,9 @@ type ProcessHookOptions struct {
 	InterceptLLM  bool
 	InterceptTool bool
 	ApproveTool   bool
+	// IsolationProfile selects the isolation resource profile for the hook
+	// process; empty uses the default profile.
+	IsolationProfile string
 }
 
 type ProcessHook struct {
@@ -126,7 +129,8 @@ func NewProcessHook(ctx context.Context, name string, opts ProcessHookOptions) (
 	}
 	// Route hook subprocess startup through the shared isolation entry point so
 	// process hooks inherit the same isolation behavior as other child processes.
-	if err := isolation.Start(cmd); err != nil {
+	// Hooks live as long as the agent loop, so no wall-clock limit applies.
+	if err := isolation.StartWith(cmd, isolation.Launch{Profile: opts.IsolationProfile, Service: true}); err != nil {
 		return nil, fmt.Errorf("start process hook: %w", err)
 	}
 
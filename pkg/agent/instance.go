@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	pkgroot "github.com/sipeed/picoclaw/pkg"
+	"github.com/sipeed/picoclaw/pkg/checkpoint"
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/isolation"
 	"github.com/sipeed/picoclaw/pkg/logger"
@@ -16,6 +17,8 @@ import (
 	"github.com/sipeed/picoclaw/pkg/providers"
 	"github.com/sipeed/picoclaw/pkg/routing"
 	"github.com/sipeed/picoclaw/pkg/session"
+	"github.com/sipeed/picoclaw/pkg/skills"
+	"github.com/sipeed/picoclaw/pkg/state"
 	"github.com/sipeed/picoclaw/pkg/tools"
 )
 
@@ -47,20 +50,21 @@ type AgentInstance struct {
 	Candidates                []providers.FallbackCandidate
 	ImageCandidates           []providers.FallbackCandidate
 
-	// Router is non-nil when model routing is configured and the light model
-	// was successfully resolved. It scores each incoming message and decides
-	// whether to route to LightCandidates or stay with Candidates.
+	// Router is non-nil when model routing is configured and at least one
+	// tier was successfully resolved. It scores each incoming message and
+	// decides which of RoutingTiers to use, or to stay with Candidates.
 	Router *routing.Router
-	// LightCandidates holds the resolved provider candidates for the light model.
-	// Pre-computed at agent creation to avoid repeated model_list lookups at runtime.
-	LightCandidates []providers.FallbackCandidate
-	// LightProvider is the concrete provider instance for the configured light model.
-	// It is only used when routing selects the light tier for a turn.
-	LightProvider providers.LLMProvider
+	// RoutingTiers holds the resolved tiers below the primary model, in the
+	// same order as Router.Tiers(). Pre-computed at agent creation to avoid
+	// repeated model_list lookups at runtime.
+	RoutingTiers []RoutingTier
 	// CandidateProviders maps "provider/model" keys to per-candidate LLMProvider
 	// instances. This allows each fallback model to use its own api_base and api_key
 	// from model_list, instead of inheriting the primary model's provider config.
 	CandidateProviders map[string]providers.LLMProvider
+	// Checkpoints stores the files captured before each turn modifies them.
+	// It is nil when tools.checkpoints is disabled.
+	Checkpoints *checkpoint.Store
 }
 
 // NewAgentInstance creates an agent instance from config.
@@ -76,7 +80,7 @@ func NewAgentInstance(
 		isolation.Configure(cfg)
 	}
 
-	workspace := resolveAgentWorkspace(agentCfg, defaults)
+	workspace := routing.AgentWorkspace(agentCfg, defaults)
 	os.MkdirAll(workspace, 0o755)
 
 	definition := loadAgentDefinition(workspace)
@@ -111,6 +115,9 @@ func NewAgentInstance(
 	if cfg.Tools.IsToolEnabled("append_file") {
 		toolsRegistry.Register(tools.NewAppendFileTool(workspace, restrict, allowWritePaths))
 	}
+	if cfg.Tools.IsToolEnabled("apply_patch") {
+		toolsRegistry.Register(tools.NewApplyPatchTool(workspace, restrict, allowWritePaths))
+	}
 	// Build write_file's copy from the registered editors so it steers the agent
 	// to edit_file/append_file only when those tools are actually available.
 	if cfg.Tools.IsToolEnabled("write_file") {
@@ -128,6 +135,12 @@ func NewAgentInstance(
 	if cfg.Tools.IsToolEnabled("list_dir") {
 		toolsRegistry.Register(tools.NewListDirTool(workspace, readRestrict, allowReadPaths))
 	}
+	if cfg.Tools.IsToolEnabled("grep_files") {
+		toolsRegistry.Register(tools.NewGrepFilesTool(workspace, readRestrict, allowReadPaths))
+	}
+	if cfg.Tools.IsToolEnabled("glob_files") {
+		toolsRegistry.Register(tools.NewGlobFilesTool(workspace, readRestrict, allowReadPaths))
+	}
 	if cfg.Tools.IsToolEnabled("exec") {
 		execTool, err := tools.NewExecToolWithConfig(workspace, restrict, cfg, allowReadPaths)
 		if err != nil {
@@ -137,6 +150,9 @@ func NewAgentInstance(
 			toolsRegistry.Register(execTool)
 		}
 	}
+	if cfg.Tools.IsToolEnabled("run_script") {
+		toolsRegistry.Register(tools.NewRunScriptTool(workspace, readRestrict, cfg.Tools.RunScript, allowReadPaths))
+	}
 
 	if cfg.Tools.IsToolEnabled("edit_file") {
 		toolsRegistry.Register(tools.NewEditFileTool(workspace, restrict, allowWritePaths))
@@ -154,7 +170,8 @@ func NewAgentInstance(
 			mcpDiscoveryActive && cfg.Tools.MCP.Discovery.UseBM25,
 			mcpDiscoveryActive && cfg.Tools.MCP.Discovery.UseRegex,
 		).
-		WithSplitOnMarker(cfg.Agents.Defaults.SplitOnMarker)
+		WithSplitOnMarker(cfg.Agents.Defaults.SplitOnMarker).
+		WithSkillRequirements(skills.NewRequirementChecker(cfg))
 
 	agentID := routing.DefaultAgentID
 	agentName := ""
@@ -234,45 +251,9 @@ func NewAgentInstance(
 		populateCandidateProvidersFromNames(cfg, workspace, imageNames, candidateProviders)
 	}
 
-	// Model routing setup: pre-resolve light model candidates at creation time
+	// Model routing setup: pre-resolve tier candidates at creation time
 	// to avoid repeated model_list lookups on every incoming message.
-	var router *routing.Router
-	var lightCandidates []providers.FallbackCandidate
-	var lightProvider providers.LLMProvider
-	if rc := defaults.Routing; rc != nil && rc.Enabled && rc.LightModel != "" {
-		resolved := resolveModelCandidates(cfg, defaults.Provider, rc.LightModel, nil)
-		if len(resolved) > 0 {
-			lightModelCfg, err := resolvedModelConfig(cfg, rc.LightModel, workspace)
-			if err != nil {
-				logger.WarnCF(
-					"agent",
-					"Routing light model config invalid; routing disabled",
-					map[string]any{
-						"light_model": rc.LightModel,
-						"agent_id":    agentID,
-						"error":       err.Error(),
-					},
-				)
-			} else {
-				lp, _, err := providers.CreateProviderFromConfig(lightModelCfg)
-				if err != nil {
-					logger.WarnCF("agent", "Routing light model provider init failed; routing disabled",
-						map[string]any{"light_model": rc.LightModel, "agent_id": agentID, "error": err.Error()})
-				} else {
-					router = routing.New(routing.RouterConfig{
-						LightModel: rc.LightModel,
-						Threshold:  rc.Threshold,
-					})
-					lightCandidates = resolved
-					lightProvider = lp
-					populateCandidateProvidersFromNames(cfg, workspace, []string{rc.LightModel}, candidateProviders)
-				}
-			}
-		} else {
-			logger.WarnCF("agent", "Routing light model not found; routing disabled",
-				map[string]any{"light_model": rc.LightModel, "agent_id": agentID})
-		}
-	}
+	router, routingTiers := newModelRouter(cfg, defaults, workspace, agentID, candidateProviders)
 
 	return &AgentInstance{
 		ID:                        agentID,
@@ -300,12 +281,25 @@ func NewAgentInstance(
 		Candidates:                candidates,
 		ImageCandidates:           imageCandidates,
 		Router:                    router,
-		LightCandidates:           lightCandidates,
-		LightProvider:             lightProvider,
+		RoutingTiers:              routingTiers,
 		CandidateProviders:        candidateProviders,
+		Checkpoints:               newCheckpointStore(cfg, workspace),
 	}
 }
 
+// newCheckpointStore opens the checkpoint store in the workspace state dir.
+func newCheckpointStore(cfg *config.Config, workspace string) *checkpoint.Store {
+	if cfg == nil || !cfg.Tools.IsToolEnabled("checkpoints") {
+		return nil
+	}
+	c := cfg.Tools.Checkpoints
+	return checkpoint.NewStore(checkpoint.DefaultDir(state.ResolveDir(workspace)), checkpoint.Options{
+		MaxTotalBytes: int64(c.MaxTotalMB) << 20,
+		MaxFileBytes:  int64(c.MaxFileKB) << 10,
+		MaxCount:      c.MaxCount,
+	})
+}
+
 // populateCandidateProvidersFromNames resolves each model name (alias or
 // "provider/model") via resolvedModelConfig and creates a dedicated LLMProvider
 // for it. This reuses the canonical config resolution path (GetModelConfig) so
@@ -383,21 +377,6 @@ func resolvePrimaryProviderForAgent(
 	return resolvedProvider
 }
 
-// resolveAgentWorkspace determines the workspace directory for an agent.
-func resolveAgentWorkspace(agentCfg *config.AgentConfig, defaults *config.AgentDefaults) string {
-	if agentCfg != nil && strings.TrimSpace(agentCfg.Workspace) != "" {
-		return expandHome(strings.TrimSpace(agentCfg.Workspace))
-	}
-	// Use the configured default workspace (respects PICOCLAW_HOME)
-	if agentCfg == nil || agentCfg.Default || agentCfg.ID == "" ||
-		routing.NormalizeAgentID(agentCfg.ID) == "main" {
-		return expandHome(defaults.Workspace)
-	}
-	// For named agents without explicit workspace, use default workspace with agent ID suffix
-	id := routing.NormalizeAgentID(agentCfg.ID)
-	return filepath.Join(expandHome(defaults.Workspace), "..", "workspace-"+id)
-}
-
 // resolveAgentModel resolves the primary model for an agent.
 func resolveAgentModel(
 	agentCfg *config.AgentConfig,
@@ -128,16 +128,21 @@ func (p *Pipeline) routeMediaTurn(ts *turnState, exec *turnExecution) error {
 	var targetCandidates []providers.FallbackCandidate
 	var targetModelName string
 	var routeReason string
+	targetTier := exec.tier
 
 	switch {
 	case len(ts.agent.ImageCandidates) > 0:
 		targetCandidates = append([]providers.FallbackCandidate(nil), ts.agent.ImageCandidates...)
 		targetModelName = strings.TrimSpace(p.Cfg.Agents.Defaults.ImageModel)
 		routeReason = "configured_image_model"
-	case exec.usedLight && len(ts.agent.Candidates) > 0:
-		targetCandidates = append([]providers.FallbackCandidate(nil), ts.agent.Candidates...)
-		targetModelName = strings.TrimSpace(ts.agent.Model)
-		routeReason = "bypass_light_model_for_media"
+		targetTier = len(ts.agent.RoutingTiers)
+	case exec.onCheaperTier(ts.agent) && !ts.agent.Router.Tiers()[exec.tier].Capabilities.Vision:
+		exec.routeReq.Vision = true
+		targetTier = ts.agent.Router.NextTier(exec.tier, exec.routeReq)
+		candidates, modelName, _ := ts.agent.routingTarget(targetTier)
+		targetCandidates = append([]providers.FallbackCandidate(nil), candidates...)
+		targetModelName = strings.TrimSpace(modelName)
+		routeReason = "bypass_tier_without_vision"
 	default:
 		return nil
 	}
@@ -179,7 +184,11 @@ func (p *Pipeline) routeMediaTurn(ts *turnState, exec *turnExecution) error {
 		p.Cfg.Agents.Defaults.Provider,
 	)
 	exec.llmModelName = resolvedModelName
-	exec.usedLight = false
+	if ts.agent.Router != nil && targetTier != exec.tier {
+		exec.tier = targetTier
+		exec.tierToolMark = len(ts.toolExecutionsSnapshot())
+		ts.setRouting(ts.agent.Router.TierName(targetTier), resolvedModelName)
+	}
 
 	logger.InfoCF("agent", "Media turn routing selected model", map[string]any{
 		"agent_id":       ts.agent.ID,
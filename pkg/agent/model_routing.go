@@ -0,0 +1,299 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	runtimeevents "github.com/sipeed/picoclaw/pkg/events"
+	"github.com/sipeed/picoclaw/pkg/evolution"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/routing"
+)
+
+// RoutingTier is a resolved model tier below the agent's primary model.
+type RoutingTier struct {
+	Name       string
+	ModelName  string
+	Candidates []providers.FallbackCandidate
+	Provider   providers.LLMProvider
+}
+
+// newModelRouter resolves the configured routing tiers. Tiers whose model
+// cannot be resolved are skipped with a warning; with none left, routing is
+// disabled and both results are nil.
+func newModelRouter(
+	cfg *config.Config,
+	defaults *config.AgentDefaults,
+	workspace, agentID string,
+	candidateProviders map[string]providers.LLMProvider,
+) (*routing.Router, []RoutingTier) {
+	rc := defaults.Routing
+	if rc == nil || !rc.Enabled {
+		return nil, nil
+	}
+	tierCfgs := append([]config.RoutingTierConfig(nil), rc.Tiers...)
+	if len(tierCfgs) == 0 && rc.LightModel != "" {
+		tierCfgs = []config.RoutingTierConfig{{Name: "light", Model: rc.LightModel}}
+	}
+	if len(tierCfgs) == 0 {
+		return nil, nil
+	}
+	// Unset bands use the routing threshold, as the single light tier does.
+	for i := range tierCfgs {
+		if tierCfgs[i].MaxScore <= 0 {
+			tierCfgs[i].MaxScore = rc.Threshold
+		}
+	}
+	sort.SliceStable(tierCfgs, func(i, j int) bool { return tierCfgs[i].MaxScore < tierCfgs[j].MaxScore })
+
+	var (
+		tiers           []routing.Tier
+		resolvedTiers   []RoutingTier
+		cheapestModelID string
+	)
+	for _, tc := range tierCfgs {
+		modelName := strings.TrimSpace(tc.Model)
+		fields := map[string]any{"tier_model": modelName, "agent_id": agentID}
+		resolved := resolveModelCandidates(cfg, defaults.Provider, modelName, nil)
+		if len(resolved) == 0 {
+			logger.WarnCF("agent", "Routing tier model not found; tier skipped", fields)
+			continue
+		}
+		modelCfg, err := resolvedModelConfig(cfg, modelName, workspace)
+		if err != nil {
+			fields["error"] = err.Error()
+			logger.WarnCF("agent", "Routing tier model config invalid; tier skipped", fields)
+			continue
+		}
+		provider, modelID, err := providers.CreateProviderFromConfig(modelCfg)
+		if err != nil {
+			fields["error"] = err.Error()
+			logger.WarnCF("agent", "Routing tier provider init failed; tier skipped", fields)
+			continue
+		}
+		populateCandidateProvidersFromNames(cfg, workspace, []string{modelName}, candidateProviders)
+
+		name := strings.TrimSpace(tc.Name)
+		if name == "" {
+			name = modelName
+		}
+		if len(tiers) == 0 {
+			cheapestModelID = modelID
+		}
+		tiers = append(tiers, routing.Tier{
+			Name:     name,
+			Model:    modelName,
+			MaxScore: tc.MaxScore,
+			Capabilities: routing.Capabilities{
+				Vision:        modelCfg.Vision,
+				Tools:         !modelCfg.DisableTools,
+				ContextWindow: modelCfg.ContextWindow,
+			},
+			InputCost:  modelCfg.InputCost,
+			OutputCost: modelCfg.OutputCost,
+			MaxCost:    tc.MaxCost,
+		})
+		resolvedTiers = append(resolvedTiers, RoutingTier{
+			Name:       name,
+			ModelName:  modelName,
+			Candidates: resolved,
+			Provider:   provider,
+		})
+	}
+	if len(tiers) == 0 {
+		logger.WarnCF("agent", "No routing tier resolved; routing disabled", map[string]any{"agent_id": agentID})
+		return nil, nil
+	}
+
+	router := routing.NewWithClassifier(routing.RouterConfig{
+		Threshold:          rc.Threshold,
+		Tiers:              tiers,
+		Escalate:           rc.Escalation.Enabled,
+		EscalateToolErrors: rc.Escalation.ToolErrors,
+	}, newRoutingClassifier(cfg, rc, resolvedTiers[0].Provider, cheapestModelID, workspace, agentID))
+	return router, resolvedTiers
+}
+
+// newRoutingClassifier builds the classifier named by routing.classifier.
+// Anything that cannot be set up falls back to the rule classifier.
+func newRoutingClassifier(
+	cfg *config.Config,
+	rc *config.RoutingConfig,
+	lightProvider providers.LLMProvider,
+	lightModelID, workspace, agentID string,
+) routing.Classifier {
+	switch strings.ToLower(strings.TrimSpace(rc.Classifier)) {
+	case "", "rule":
+		return &routing.RuleClassifier{}
+	case "llm":
+		return routing.NewLLMClassifier(lightProvider, lightModelID, nil)
+	case "knn":
+		examples, err := routing.LoadExamples(
+			context.Background(), workspace, cfg.Evolution.StateDir, &evolution.HeuristicSuccessJudge{})
+		if err != nil {
+			logger.WarnCF("agent", "Routing examples unavailable; using rule classifier",
+				map[string]any{"agent_id": agentID, "error": err.Error()})
+			return &routing.RuleClassifier{}
+		}
+		logger.InfoCF("agent", "Routing kNN classifier loaded",
+			map[string]any{"agent_id": agentID, "examples": len(examples)})
+		return routing.NewKNNClassifier(examples, rc.KNNNeighbors, nil)
+	default:
+		logger.WarnCF("agent", "Unknown routing classifier; using rule classifier",
+			map[string]any{"agent_id": agentID, "classifier": rc.Classifier})
+		return &routing.RuleClassifier{}
+	}
+}
+
+// routingTarget returns the candidates, model name and provider of tier i,
+// or of the primary model when i is past the last tier.
+func (a *AgentInstance) routingTarget(i int) ([]providers.FallbackCandidate, string, providers.LLMProvider) {
+	if i >= 0 && i < len(a.RoutingTiers) {
+		tier := a.RoutingTiers[i]
+		provider := tier.Provider
+		if provider == nil {
+			provider = a.Provider
+		}
+		return tier.Candidates, tier.ModelName, provider
+	}
+	return a.Candidates, a.Model, a.Provider
+}
+
+// routingRequirements describes what the next call needs from a model.
+func routingRequirements(
+	agent *AgentInstance,
+	messages []providers.Message,
+	toolDefs []providers.ToolDefinition,
+	vision bool,
+) routing.Requirements {
+	tokens := EstimateToolDefsTokens(toolDefs)
+	for _, msg := range messages {
+		tokens += EstimateMessageTokens(msg)
+	}
+	return routing.Requirements{
+		Vision:        vision,
+		Tools:         len(toolDefs) > 0,
+		ContextTokens: tokens,
+		OutputTokens:  agent.MaxTokens,
+	}
+}
+
+// onCheaperTier reports whether the turn runs on a tier below the primary
+// model.
+func (exec *turnExecution) onCheaperTier(agent *AgentInstance) bool {
+	return agent != nil && agent.Router != nil && exec.tier < len(agent.RoutingTiers)
+}
+
+// switchTier points the turn at tier i for the remaining LLM calls.
+func (p *Pipeline) switchTier(ts *turnState, exec *turnExecution, i int) {
+	candidates, modelName, provider := ts.agent.routingTarget(i)
+	exec.activeCandidates = candidates
+	exec.activeModel = resolvedCandidateModel(candidates, modelName)
+	exec.activeProvider = provider
+	exec.activeModelConfig = resolveActiveModelConfig(
+		p.Cfg,
+		ts.agent.Workspace,
+		candidates,
+		exec.activeModel,
+		p.Cfg.Agents.Defaults.Provider,
+	)
+	exec.llmModelName = resolvedCandidateModelName(candidates, strings.TrimSpace(sideQuestionModelName(ts.agent, i)))
+	exec.tier = i
+	exec.tierToolMark = len(ts.toolExecutionsSnapshot())
+	ts.setRouting(ts.agent.Router.TierName(i), exec.llmModelName)
+}
+
+// escalateTier moves the turn to the next routing tier after the current one
+// failed, and records the move as an agent.llm.retry event. It reports
+// whether the caller should retry on the new tier.
+func (p *Pipeline) escalateTier(ts *turnState, exec *turnExecution, cause, detail string) bool {
+	router := ts.agent.Router
+	if router == nil {
+		return false
+	}
+	if enabled, _ := router.Escalation(); !enabled {
+		return false
+	}
+	next := router.NextTier(exec.tier, exec.routeReq)
+	if next < 0 {
+		return false
+	}
+
+	fromTier, fromModel := router.TierName(exec.tier), exec.llmModelName
+	p.switchTier(ts, exec, next)
+	exec.escalations++
+
+	errText := cause
+	if detail != "" {
+		errText += ": " + detail
+	}
+	p.al.emitEvent(
+		runtimeevents.KindAgentLLMRetry,
+		ts.eventMeta("runTurn", "turn.llm.retry"),
+		LLMRetryPayload{
+			Attempt:    exec.escalations,
+			MaxRetries: len(ts.agent.RoutingTiers),
+			Reason:     "escalation",
+			Error:      errText,
+			FromTier:   fromTier,
+			ToTier:     router.TierName(next),
+			FromModel:  fromModel,
+			ToModel:    exec.llmModelName,
+		},
+	)
+	logger.WarnCF("agent", "Model routing: escalating to next tier", map[string]any{
+		"agent_id":   ts.agent.ID,
+		"cause":      cause,
+		"detail":     detail,
+		"from_tier":  fromTier,
+		"to_tier":    router.TierName(next),
+		"from_model": fromModel,
+		"to_model":   exec.llmModelName,
+	})
+	return true
+}
+
+// responseEscalationCause names the quality problem with a final response
+// that warrants another tier, or returns "" when there is none. Replies
+// already streamed to the user are kept.
+func responseEscalationCause(exec *turnExecution) string {
+	if exec.response == nil || len(exec.response.ToolCalls) > 0 || exec.gracefulTerminal {
+		return ""
+	}
+	if exec.streamingPublisher != nil && exec.streamingPublisher.Published() {
+		return ""
+	}
+	switch {
+	case strings.TrimSpace(exec.response.Content) == "":
+		return "empty_response"
+	case exec.response.FinishReason == "length":
+		return "truncated_response"
+	}
+	return ""
+}
+
+// escalateAfterToolErrors escalates when the current tier has produced the
+// configured number of failed tool calls.
+func (p *Pipeline) escalateAfterToolErrors(ts *turnState, exec *turnExecution) {
+	if !exec.onCheaperTier(ts.agent) {
+		return
+	}
+	enabled, limit := ts.agent.Router.Escalation()
+	if !enabled {
+		return
+	}
+	records := ts.toolExecutionsSnapshot()
+	failed := 0
+	for _, record := range records[min(exec.tierToolMark, len(records)):] {
+		if !record.Success {
+			failed++
+		}
+	}
+	if failed >= limit {
+		p.escalateTier(ts, exec, "tool_errors", fmt.Sprintf("%d failed tool calls", failed))
+	}
+}
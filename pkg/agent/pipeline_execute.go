@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/checkpoint"
 	"github.com/sipeed/picoclaw/pkg/constants"
 	runtimeevents "github.com/sipeed/picoclaw/pkg/events"
 	"github.com/sipeed/picoclaw/pkg/logger"
@@ -265,7 +266,7 @@ toolLoop:
 							Scope:      outboundScopeFromSessionScope(ts.opts.Dispatch.SessionScope),
 							Parts:      parts,
 						}
-						if al.channelManager != nil && ts.channel != "" && !constants.IsInternalChannel(ts.channel) {
+						if al.channelManager != nil && constants.IsDeliverableChannel(ts.channel) {
 							if err := al.channelManager.SendMedia(ctx, outboundMedia); err != nil {
 								logger.WarnCF("agent", "Failed to deliver hook media",
 									map[string]any{
@@ -576,6 +577,7 @@ toolLoop:
 			ts.sessionKey,
 			ts.opts.Dispatch.SessionScope,
 		)
+		execCtx = checkpoint.WithRecorder(execCtx, ts.checkpoints)
 		toolResult := ts.agent.Tools.ExecuteWithContext(
 			execCtx,
 			toolName,
@@ -655,7 +657,7 @@ toolLoop:
 				Scope:      outboundScopeFromSessionScope(ts.opts.Dispatch.SessionScope),
 				Parts:      parts,
 			}
-			if al.channelManager != nil && ts.channel != "" && !constants.IsInternalChannel(ts.channel) {
+			if al.channelManager != nil && constants.IsDeliverableChannel(ts.channel) {
 				if err := al.channelManager.SendMedia(ctx, outboundMedia); err != nil {
 					logger.WarnCF("agent", "Failed to deliver handled tool media",
 						map[string]any{
@@ -877,6 +879,7 @@ toolLoop:
 	// allResponsesHandled=false and no pending steering: continue so coordinator
 	// makes another LLM call. The tool result is in messages and the LLM will
 	// return it as finalContent in the next iteration.
+	p.escalateAfterToolErrors(ts, exec)
 	ts.agent.Tools.TickTTL()
 	logger.DebugCF("agent", "TTL tick after tool execution", map[string]any{
 		"agent_id": ts.agent.ID, "iteration": iteration,
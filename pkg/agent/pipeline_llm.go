@@ -349,7 +349,7 @@ func (p *Pipeline) CallLLM(
 				},
 			)
 
-			if retry == 0 && !constants.IsInternalChannel(ts.channel) {
+			if retry == 0 && constants.IsDeliverableChannel(ts.channel) {
 				al.bus.PublishOutbound(ctx, outboundMessageForTurn(
 					ts,
 					"Context window exceeded. Compressing history and retrying...",
@@ -451,6 +451,10 @@ func (p *Pipeline) CallLLM(
 		break
 	}
 
+	if err != nil && !errors.Is(err, context.Canceled) && !isConfiguredStreamingVisibleError(err) &&
+		p.escalateTier(ts, exec, "llm_error", err.Error()) {
+		return ControlContinue, nil
+	}
 	if err != nil {
 		al.emitEvent(
 			runtimeevents.KindAgentError,
@@ -495,6 +499,11 @@ func (p *Pipeline) CallLLM(
 		}
 	}
 
+	if cause := responseEscalationCause(exec); cause != "" && p.escalateTier(ts, exec, cause, "") {
+		cancelConfiguredStreamingLLM(turnCtx, exec)
+		return ControlContinue, nil
+	}
+
 	// Save finishReason and usage on the turn state. Use ts directly (the
 	// authoritative turn state for this call) rather than a context lookup:
 	// the raw ctx passed to CallLLM is not seeded with turnState (only turnCtx
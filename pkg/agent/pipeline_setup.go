@@ -130,15 +130,15 @@ func (p *Pipeline) SetupTurn(ctx context.Context, ts *turnState) (*turnExecution
 		ts.ingestMessage(ctx, p.al, rootMsg)
 	}
 
-	activeCandidates, activeModel, usedLight := p.al.selectCandidates(ts.agent, ts.userMessage, messages)
-	activeProvider := ts.agent.Provider
-	if usedLight && ts.agent.LightProvider != nil {
-		activeProvider = ts.agent.LightProvider
-	}
-	activeModelName := strings.TrimSpace(ts.agent.Model)
-	if usedLight {
-		activeModelName = strings.TrimSpace(sideQuestionModelName(ts.agent, true))
-	}
+	routeReq := routingRequirements(
+		ts.agent,
+		messages,
+		filterToolsByTurnProfile(ts.agent.Tools.ToProviderDefs(), ts.profile),
+		len(ts.media) > 0 || messagesContainCurrentTurnMediaTurn(currentTurnMessages(messages, currentTurnStart)),
+	)
+	activeCandidates, activeModel, tier := p.al.selectCandidates(ctx, ts.agent, ts.userMessage, messages, routeReq)
+	_, _, activeProvider := ts.agent.routingTarget(tier)
+	activeModelName := strings.TrimSpace(sideQuestionModelName(ts.agent, tier))
 	activeModelName = resolvedCandidateModelName(activeCandidates, activeModelName)
 
 	exec := newTurnExecution(
@@ -160,7 +160,11 @@ func (p *Pipeline) SetupTurn(ctx context.Context, ts *turnState) (*turnExecution
 	)
 	exec.llmModelName = activeModelName
 	exec.activeProvider = activeProvider
-	exec.usedLight = usedLight
+	exec.tier = tier
+	exec.routeReq = routeReq
+	if ts.agent.Router != nil && len(ts.agent.RoutingTiers) > 0 {
+		ts.setRouting(ts.agent.Router.TierName(tier), activeModelName)
+	}
 
 	return exec, nil
 }
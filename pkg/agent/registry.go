@@ -86,6 +86,12 @@ func (r *AgentRegistry) ResolveRoute(inbound bus.InboundContext) routing.Resolve
 	return r.resolver.ResolveRoute(inbound)
 }
 
+// SetIdentityLinkSource adds runtime identity links (from /link) to routing
+// and session allocation.
+func (r *AgentRegistry) SetIdentityLinkSource(source routing.IdentityLinkSource) {
+	r.resolver.SetIdentityLinkSource(source)
+}
+
 // ListAgentIDs returns all registered agent IDs.
 func (r *AgentRegistry) ListAgentIDs() []string {
 	r.mu.RLock()
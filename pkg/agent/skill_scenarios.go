@@ -0,0 +1,292 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/evolution"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/skills"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const scriptedScenarioModel = "skill-scenario-script"
+
+// SkillScenarioOptions controls RunSkillScenarios.
+type SkillScenarioOptions struct {
+	// Provider answers scenarios that have no script. Scenarios without a
+	// script are skipped when it is nil.
+	Provider providers.LLMProvider
+	// ModelName selects the model_list entry used with Provider.
+	ModelName string
+	// Live sends scripted scenarios to Provider as well, ignoring their
+	// scripts.
+	Live bool
+	Now  func() time.Time
+}
+
+// RunSkillScenarios runs every scenario in skillDir/tests through a fresh
+// AgentLoop. Each scenario gets a throwaway workspace that contains only the
+// skill under test, so sessions, memory and tool side effects never touch
+// the real workspace.
+func RunSkillScenarios(
+	ctx context.Context,
+	cfg *config.Config,
+	skillDir string,
+	opts SkillScenarioOptions,
+) (*skills.ScenarioReport, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config is required")
+	}
+	now := opts.Now
+	if now == nil {
+		now = time.Now
+	}
+	scenarios, err := skills.LoadScenarios(skillDir)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &skills.ScenarioReport{
+		Skill: filepath.Base(skillDir),
+		RanAt: now(),
+	}
+	if opts.Provider != nil {
+		report.Model = opts.ModelName
+		if report.Model == "" {
+			report.Model = opts.Provider.GetDefaultModel()
+		}
+	}
+	for _, scenario := range scenarios {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		report.Results = append(report.Results, runSkillScenario(ctx, cfg, skillDir, scenario, opts))
+	}
+	return report, nil
+}
+
+func runSkillScenario(
+	ctx context.Context,
+	cfg *config.Config,
+	skillDir string,
+	scenario skills.Scenario,
+	opts SkillScenarioOptions,
+) skills.ScenarioResult {
+	result := skills.ScenarioResult{Name: scenario.Name, File: scenario.File}
+
+	var provider providers.LLMProvider
+	switch {
+	case scenario.Scripted() && !opts.Live:
+		result.Mode = skills.ScenarioModeScripted
+		provider = &scriptedProvider{script: scenario.Script}
+	case opts.Provider != nil:
+		result.Mode = skills.ScenarioModeModel
+		provider = opts.Provider
+	default:
+		result.Mode = skills.ScenarioModeSkipped
+		result.Failures = []string{"no script; run with a model to test it"}
+		return result
+	}
+
+	fail := func(format string, args ...any) skills.ScenarioResult {
+		result.Failures = append(result.Failures, fmt.Sprintf(format, args...))
+		return result
+	}
+
+	workspace, err := os.MkdirTemp("", "picoclaw-skill-test-*")
+	if err != nil {
+		return fail("create workspace: %v", err)
+	}
+	defer os.RemoveAll(workspace)
+	if err := copySkillTree(skillDir, filepath.Join(workspace, "skills", filepath.Base(skillDir))); err != nil {
+		return fail("copy skill: %v", err)
+	}
+
+	modelName := scriptedScenarioModel
+	if result.Mode == skills.ScenarioModeModel {
+		modelName = opts.ModelName
+	}
+	al := NewAgentLoop(scenarioConfig(cfg, workspace, modelName), bus.NewMessageBus(), provider)
+	defer al.Close()
+	recorder := &scenarioToolRecorder{}
+	if err := al.MountHook(NamedHook("skill-scenario-recorder", recorder)); err != nil {
+		return fail("mount recorder: %v", err)
+	}
+
+	start := time.Now()
+	answer, err := al.ProcessDirect(ctx, scenario.Prompt, "skill-test:"+scenario.Name)
+	result.Duration = time.Since(start).Milliseconds()
+	result.ToolCalls = recorder.names()
+	result.Answer = utils.Truncate(answer, 500)
+	if err != nil {
+		return fail("agent error: %v", err)
+	}
+	result.Failures = scenario.Check(result.ToolCalls, answer)
+	result.Passed = len(result.Failures) == 0
+	return result
+}
+
+// scenarioConfig copies cfg for an isolated scenario run: one default agent
+// in workspace, no routing, fallbacks, hooks, MCP servers or evolution.
+func scenarioConfig(cfg *config.Config, workspace, modelName string) *config.Config {
+	clone := *cfg
+	clone.Agents.List = nil
+	clone.Agents.Dispatch = nil
+	clone.Agents.Defaults.Workspace = workspace
+	clone.Agents.Defaults.Routing = nil
+	clone.Agents.Defaults.ModelFallbacks = nil
+	if modelName != "" {
+		clone.Agents.Defaults.ModelName = modelName
+	}
+	clone.Hooks.Enabled = false
+	clone.Tools.MCP.Enabled = false
+	clone.Evolution.Enabled = false
+	return &clone
+}
+
+// copySkillTree copies regular files from src to dst. Symlinks are skipped
+// so a scenario cannot reach outside its workspace through the skill.
+func copySkillTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, info.Mode().Perm())
+	})
+}
+
+// scriptedProvider replays a scenario's script one response per call.
+type scriptedProvider struct {
+	mu     sync.Mutex
+	script []skills.ScriptedResponse
+	next   int
+}
+
+func (p *scriptedProvider) Chat(
+	ctx context.Context,
+	_ []providers.Message,
+	_ []providers.ToolDefinition,
+	_ string,
+	_ map[string]any,
+) (*providers.LLMResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.next >= len(p.script) {
+		return nil, fmt.Errorf("script exhausted after %d responses", len(p.script))
+	}
+	step := p.script[p.next]
+	p.next++
+
+	resp := &providers.LLMResponse{Content: step.Content, FinishReason: "stop"}
+	for i, call := range step.ToolCalls {
+		args := call.Arguments
+		if args == nil {
+			args = map[string]any{}
+		}
+		resp.ToolCalls = append(resp.ToolCalls, providers.ToolCall{
+			ID:        fmt.Sprintf("script-%d-%d", p.next, i),
+			Type:      "function",
+			Name:      call.Name,
+			Arguments: args,
+		})
+	}
+	if len(resp.ToolCalls) > 0 {
+		resp.FinishReason = "tool_calls"
+	}
+	return resp, nil
+}
+
+func (p *scriptedProvider) GetDefaultModel() string {
+	return scriptedScenarioModel
+}
+
+// scenarioToolRecorder records the name of every tool the agent runs.
+type scenarioToolRecorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *scenarioToolRecorder) BeforeTool(
+	_ context.Context,
+	call *ToolCallHookRequest,
+) (*ToolCallHookRequest, HookDecision, error) {
+	r.mu.Lock()
+	r.calls = append(r.calls, call.Tool)
+	r.mu.Unlock()
+	return call, HookDecision{Action: HookActionContinue}, nil
+}
+
+func (r *scenarioToolRecorder) AfterTool(
+	_ context.Context,
+	result *ToolResultHookResponse,
+) (*ToolResultHookResponse, HookDecision, error) {
+	return result, HookDecision{Action: HookActionContinue}, nil
+}
+
+func (r *scenarioToolRecorder) names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.calls...)
+}
+
+// NewSkillScenarioTester returns the evolution.DraftTester the agent loop
+// uses, for callers that apply drafts outside it. provider answers
+// unscripted scenarios and may be nil.
+func NewSkillScenarioTester(cfg *config.Config, provider providers.LLMProvider) evolution.DraftTester {
+	return &skillScenarioTester{cfg: cfg, provider: provider}
+}
+
+// skillScenarioTester lets evolution run a skill's scenarios before it
+// accepts a draft. Unscripted scenarios use the agent's own model.
+type skillScenarioTester struct {
+	cfg      *config.Config
+	provider providers.LLMProvider
+}
+
+func (t *skillScenarioTester) TestSkill(
+	ctx context.Context,
+	workspace, skillName string,
+) (*skills.ScenarioReport, error) {
+	report, err := RunSkillScenarios(ctx, t.cfg, filepath.Join(workspace, "skills", skillName), SkillScenarioOptions{
+		Provider:  t.provider,
+		ModelName: t.cfg.Agents.Defaults.GetModelName(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := skills.SaveScenarioReport(workspace, report); err != nil {
+		logger.WarnCF("agent", "Failed to save skill scenario report", map[string]any{
+			"skill": skillName,
+			"error": err.Error(),
+		})
+	}
+	return report, nil
+}
@@ -0,0 +1,108 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+func writeScenarioSkill(t *testing.T, scenarios map[string]string) string {
+	t.Helper()
+	skillDir := filepath.Join(t.TempDir(), "weather")
+	if err := os.MkdirAll(filepath.Join(skillDir, skills.ScenarioDirName), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	body := "---\nname: weather\ndescription: Look up the weather\n---\n\n# Weather\n\nSay it is sunny.\n"
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range scenarios {
+		path := filepath.Join(skillDir, skills.ScenarioDirName, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return skillDir
+}
+
+func TestRunSkillScenarios_Scripted(t *testing.T) {
+	skillDir := writeScenarioSkill(t, map[string]string{
+		"01-reads-skill.yaml": `prompt: What's the weather?
+script:
+  - tool_calls:
+      - name: read_file
+        arguments: {path: skills/weather/SKILL.md}
+  - content: It is sunny today.
+expect:
+  tool_calls: [read_file]
+  answer_matches: (?i)sunny
+`,
+		"02-wrong-answer.yaml": `prompt: And tomorrow?
+script:
+  - content: I have no idea.
+expect:
+  answer_matches: sunny
+`,
+		"03-needs-model.yaml": `prompt: Weather in Paris?
+expect:
+  answer_matches: Paris
+`,
+	})
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.Workspace = t.TempDir()
+
+	report, err := RunSkillScenarios(context.Background(), cfg, skillDir, SkillScenarioOptions{})
+	if err != nil {
+		t.Fatalf("RunSkillScenarios() error = %v", err)
+	}
+	if report.Skill != "weather" || len(report.Results) != 3 {
+		t.Fatalf("report = %+v", report)
+	}
+
+	first := report.Results[0]
+	if !first.Passed || first.Mode != skills.ScenarioModeScripted {
+		t.Fatalf("first scenario = %+v, want scripted pass", first)
+	}
+	if len(first.ToolCalls) != 1 || first.ToolCalls[0] != "read_file" {
+		t.Fatalf("first scenario tool calls = %v", first.ToolCalls)
+	}
+	if report.Results[1].Passed || len(report.Results[1].Failures) == 0 {
+		t.Fatalf("second scenario = %+v, want failure", report.Results[1])
+	}
+	if report.Results[2].Mode != skills.ScenarioModeSkipped {
+		t.Fatalf("third scenario mode = %q, want skipped", report.Results[2].Mode)
+	}
+
+	passed, failed, skipped := report.Counts()
+	if passed != 1 || failed != 1 || skipped != 1 {
+		t.Fatalf("Counts() = %d/%d/%d, want 1/1/1", passed, failed, skipped)
+	}
+	if entries, _ := os.ReadDir(cfg.Agents.Defaults.Workspace); len(entries) != 0 {
+		t.Fatalf("scenario run wrote into the real workspace: %v", entries)
+	}
+}
+
+func TestRunSkillScenarios_ModelProvider(t *testing.T) {
+	skillDir := writeScenarioSkill(t, map[string]string{
+		"paris.yaml": "prompt: Weather in Paris?\nexpect:\n  answer_matches: Mock response\n",
+	})
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.Workspace = t.TempDir()
+
+	report, err := RunSkillScenarios(context.Background(), cfg, skillDir, SkillScenarioOptions{
+		Provider: &mockProvider{},
+	})
+	if err != nil {
+		t.Fatalf("RunSkillScenarios() error = %v", err)
+	}
+	if len(report.Results) != 1 || !report.Results[0].Passed || report.Results[0].Mode != skills.ScenarioModeModel {
+		t.Fatalf("results = %+v", report.Results)
+	}
+	if report.Model != "mock-model" {
+		t.Fatalf("report.Model = %q", report.Model)
+	}
+}
@@ -178,6 +178,11 @@ type SubTurnConfig struct {
 	// The target agent's workspace, model, tools, and system prompt are used
 	// instead of the caller's. If empty, the sub-turn runs as the parent agent.
 	TargetAgentID string
+
+	// ExtraTools are registered on the child's cloned tool registry on top of
+	// the agent's own tools, e.g. the blackboard tools of a team task. They
+	// still go through the agent's tool allowlist.
+	ExtraTools []tools.Tool
 }
 
 // ====================== Context Keys ======================
@@ -236,6 +241,7 @@ func (s *AgentLoopSpawner) SpawnSubTurn(
 		Timeout:            cfg.Timeout,
 		MaxContextRunes:    cfg.MaxContextRunes,
 		TargetAgentID:      cfg.TargetAgentID,
+		ExtraTools:         cfg.ExtraTools,
 	}
 
 	return spawnSubTurn(ctx, s.al, parentTS, agentCfg)
@@ -366,6 +372,14 @@ func spawnSubTurn(
 	if baseAgent.Tools != nil {
 		agent.Tools = baseAgent.Tools.Clone()
 	}
+	if len(cfg.ExtraTools) > 0 {
+		if agent.Tools == nil {
+			agent.Tools = tools.NewToolRegistry()
+		}
+		for _, tool := range cfg.ExtraTools {
+			agent.Tools.Register(tool)
+		}
+	}
 
 	// Create processOptions for the child turn
 	dispatch := DispatchRequest{
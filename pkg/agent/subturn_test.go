@@ -2328,3 +2328,143 @@ func TestDelegateToolRegistered_MultiAgent(t *testing.T) {
 		}
 	}
 }
+
+// toolNamesRecordingProvider captures the tool names offered to Chat.
+type toolNamesRecordingProvider struct {
+	mu    sync.Mutex
+	names []string
+}
+
+func (rp *toolNamesRecordingProvider) Chat(
+	_ context.Context,
+	_ []providers.Message,
+	defs []providers.ToolDefinition,
+	_ string,
+	_ map[string]any,
+) (*providers.LLMResponse, error) {
+	rp.mu.Lock()
+	rp.names = rp.names[:0]
+	for _, def := range defs {
+		rp.names = append(rp.names, def.Function.Name)
+	}
+	rp.mu.Unlock()
+	return &providers.LLMResponse{Content: "Mock response"}, nil
+}
+
+func (rp *toolNamesRecordingProvider) GetDefaultModel() string { return "mock-model" }
+
+func TestSpawnSubTurn_ExtraToolsAreOfferedToChild(t *testing.T) {
+	rp := &toolNamesRecordingProvider{}
+	al, cleanup := newMultiAgentLoop(t, rp)
+	defer cleanup()
+
+	alphaAgent, _ := al.registry.GetAgent("alpha")
+	parent := &turnState{
+		ctx:            context.Background(),
+		turnID:         "parent-alpha",
+		childTurnIDs:   []string{},
+		pendingResults: make(chan *tools.ToolResult, 4),
+		concurrencySem: make(chan struct{}, testMaxConcurrentSubTurns),
+		session:        &ephemeralSessionStore{},
+		agent:          alphaAgent,
+	}
+
+	extra := &mockCustomTool{}
+	if _, err := spawnSubTurn(context.Background(), al, parent, SubTurnConfig{
+		TargetAgentID: "beta",
+		SystemPrompt:  "task for beta",
+		ExtraTools:    []tools.Tool{extra},
+	}); err != nil {
+		t.Fatalf("spawnSubTurn failed: %v", err)
+	}
+
+	rp.mu.Lock()
+	offered := strings.Join(rp.names, ",")
+	rp.mu.Unlock()
+	if !strings.Contains(offered, extra.Name()) {
+		t.Errorf("child was offered %q, want it to include %q", offered, extra.Name())
+	}
+	betaAgent, _ := al.registry.GetAgent("beta")
+	if _, leaked := betaAgent.Tools.Get(extra.Name()); leaked {
+		t.Error("extra tool leaked into the target agent's own registry")
+	}
+}
+
+func TestTeamToolRegistered_OnlyWithKnownMembers(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         tmpDir,
+				ModelName:         "default-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+			List: []config.AgentConfig{
+				{ID: "alpha", Workspace: filepath.Join(tmpDir, "alpha")},
+				{ID: "beta", Workspace: filepath.Join(tmpDir, "beta")},
+			},
+			Teams: []config.TeamConfig{
+				{ID: "duo", Members: []string{"alpha", "beta"}},
+				{ID: "ghost", Members: []string{"alpha", "gamma"}},
+			},
+		},
+	}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &mockProvider{})
+	defer al.Close()
+
+	agent, _ := al.registry.GetAgent("alpha")
+	teamTool, ok := agent.Tools.Get("team")
+	if !ok {
+		t.Fatal("alpha should have the team tool when a team is configured")
+	}
+	props := teamTool.Parameters()["properties"].(map[string]any)
+	ids := props["team"].(map[string]any)["enum"].([]string)
+	if len(ids) != 1 || ids[0] != "duo" {
+		t.Errorf("team ids = %v, want only duo", ids)
+	}
+}
+
+type stubRemoteDelegator struct{}
+
+func (stubRemoteDelegator) LocalInstance() string { return "desktop" }
+
+func (stubRemoteDelegator) RemoteAgents() []string { return []string{"sensors@pi"} }
+
+func (stubRemoteDelegator) DelegateRemote(context.Context, string, string, string) (string, error) {
+	return "21.5C", nil
+}
+
+func TestDelegateToolRegistered_WithSwarmForSingleAgent(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "default-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+		Swarm: config.SwarmConfig{Enabled: true},
+	}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &mockProvider{})
+	defer al.Close()
+
+	agent := al.registry.GetDefaultAgent()
+	delegateTool, ok := agent.Tools.Get("delegate")
+	if !ok {
+		t.Fatal("delegate tool should be registered when swarm mode is enabled")
+	}
+
+	al.SetRemoteDelegator(stubRemoteDelegator{})
+	result := delegateTool.Execute(context.Background(), map[string]any{
+		"agent_id": "sensors@pi",
+		"task":     "read the temperature",
+	})
+	if result.IsError || !strings.Contains(result.ForLLM, "21.5C") {
+		t.Fatalf("remote delegation result = %+v", result)
+	}
+	if !strings.Contains(delegateTool.Description(), "sensors@pi") {
+		t.Errorf("description should list remote agents: %s", delegateTool.Description())
+	}
+}
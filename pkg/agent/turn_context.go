@@ -72,8 +72,9 @@ func cloneResolvedRoute(route *routing.ResolvedRoute) *routing.ResolvedRoute {
 	}
 	cloned := *route
 	cloned.SessionPolicy = routing.SessionPolicy{
-		Dimensions:    append([]string(nil), route.SessionPolicy.Dimensions...),
-		IdentityLinks: cloneIdentityLinks(route.SessionPolicy.IdentityLinks),
+		Dimensions:          append([]string(nil), route.SessionPolicy.Dimensions...),
+		IdentityLinks:       cloneIdentityLinks(route.SessionPolicy.IdentityLinks),
+		SharedIdentityLinks: cloneIdentityLinks(route.SessionPolicy.SharedIdentityLinks),
 	}
 	return &cloned
 }
@@ -12,6 +12,7 @@ import (
 	runtimeevents "github.com/sipeed/picoclaw/pkg/events"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/routing"
 )
 
 func fallbackForEmptyFinalContent(ts *turnState, al *AgentLoop, exec *turnExecution) string {
@@ -48,6 +49,7 @@ func (al *AgentLoop) runTurn(ctx context.Context, ts *turnState, pipeline *Pipel
 		attemptedSkills := ts.attemptedSkillsSnapshot()
 		skillContextSnapshots := ts.skillContextSnapshotsSnapshot()
 		finalSuccessfulPath := []string(nil)
+		routingTier, routingModel := ts.routingSnapshot()
 		if turnStatus == TurnEndStatusCompleted {
 			if latest := ts.latestSkillContextSnapshot(); len(latest) > 0 {
 				finalSuccessfulPath = latest
@@ -72,6 +74,8 @@ func (al *AgentLoop) runTurn(ctx context.Context, ts *turnState, pipeline *Pipel
 				SkillContextSnapshots: skillContextSnapshots,
 				ToolKinds:             ts.toolKindsSnapshot(),
 				ToolExecutions:        ts.toolExecutionsSnapshot(),
+				RoutingTier:           routingTier,
+				RoutingModel:          routingModel,
 			},
 		)
 	}()
@@ -319,33 +323,33 @@ func (al *AgentLoop) abortTurn(ts *turnState) (turnResult, error) {
 }
 
 func (al *AgentLoop) selectCandidates(
+	ctx context.Context,
 	agent *AgentInstance,
 	userMsg string,
 	history []providers.Message,
-) (candidates []providers.FallbackCandidate, model string, usedLight bool) {
-	if agent.Router == nil || len(agent.LightCandidates) == 0 {
-		return agent.Candidates, resolvedCandidateModel(agent.Candidates, agent.Model), false
+	req routing.Requirements,
+) (candidates []providers.FallbackCandidate, model string, tier int) {
+	if agent.Router == nil || len(agent.RoutingTiers) == 0 {
+		return agent.Candidates, resolvedCandidateModel(agent.Candidates, agent.Model), len(agent.RoutingTiers)
 	}
 
-	_, usedLight, score := agent.Router.SelectModel(userMsg, history, agent.Model)
-	if !usedLight {
-		logger.DebugCF("agent", "Model routing: primary model selected",
-			map[string]any{
-				"agent_id":  agent.ID,
-				"score":     score,
-				"threshold": agent.Router.Threshold(),
-			})
-		return agent.Candidates, resolvedCandidateModel(agent.Candidates, agent.Model), false
+	sel := agent.Router.SelectTier(ctx, userMsg, history, req)
+	candidates, modelName, _ := agent.routingTarget(sel.Tier)
+	fields := map[string]any{
+		"agent_id": agent.ID,
+		"tier":     agent.Router.TierName(sel.Tier),
+		"model":    modelName,
+		"score":    sel.Score,
 	}
-
-	logger.InfoCF("agent", "Model routing: light model selected",
-		map[string]any{
-			"agent_id":    agent.ID,
-			"light_model": agent.Router.LightModel(),
-			"score":       score,
-			"threshold":   agent.Router.Threshold(),
-		})
-	return agent.LightCandidates, resolvedCandidateModel(agent.LightCandidates, agent.Router.LightModel()), true
+	if sel.Band != sel.Tier {
+		fields["score_tier"] = agent.Router.TierName(sel.Band)
+	}
+	if sel.Tier >= len(agent.RoutingTiers) {
+		logger.DebugCF("agent", "Model routing: primary model selected", fields)
+	} else {
+		logger.InfoCF("agent", "Model routing: tier selected", fields)
+	}
+	return candidates, resolvedCandidateModel(candidates, modelName), sel.Tier
 }
 
 func (al *AgentLoop) resolveContextManager() ContextManager {
@@ -453,8 +457,9 @@ func (al *AgentLoop) askSideQuestion(
 	}
 	messages = resolveMediaRefs(messages, al.mediaStore, maxMediaSize, currentTurnStart)
 
-	activeCandidates, activeModel, usedLight := al.selectCandidates(agent, question, messages)
-	selectedModelName := sideQuestionModelName(agent, usedLight)
+	activeCandidates, activeModel, tier := al.selectCandidates(ctx, agent, question, messages,
+		routingRequirements(agent, messages, nil, hasMediaRefs(messages)))
+	selectedModelName := sideQuestionModelName(agent, tier)
 
 	llmOpts := map[string]any{
 		"max_tokens":       agent.MaxTokens,
@@ -482,9 +482,13 @@ func TestPipeline_SetupTurn_UsesLightCandidateDisplayName(t *testing.T) {
 	agent.Candidates = []providers.FallbackCandidate{
 		{Provider: "openai", Model: "gpt-5.4", IdentityKey: "model_name:primary", DisplayName: "primary-model"},
 	}
-	agent.LightCandidates = []providers.FallbackCandidate{
-		{Provider: "openai", Model: "gpt-5.4-mini", IdentityKey: "model_name:light-model", DisplayName: "light-model"},
-	}
+	agent.RoutingTiers = []RoutingTier{{
+		Name:      "light",
+		ModelName: "light-model",
+		Candidates: []providers.FallbackCandidate{
+			{Provider: "openai", Model: "gpt-5.4-mini", IdentityKey: "model_name:light-model", DisplayName: "light-model"},
+		},
+	}}
 	agent.Router = routing.New(routing.RouterConfig{LightModel: "light-model", Threshold: 1})
 
 	pipeline := NewPipeline(al)
@@ -499,8 +503,8 @@ func TestPipeline_SetupTurn_UsesLightCandidateDisplayName(t *testing.T) {
 	if err != nil {
 		t.Fatalf("SetupTurn failed: %v", err)
 	}
-	if !exec.usedLight {
-		t.Fatal("expected light routing to be used")
+	if exec.tier != 0 {
+		t.Fatalf("exec.tier = %d, want light tier 0", exec.tier)
 	}
 	if exec.llmModelName != "light-model" {
 		t.Fatalf("exec.llmModelName = %q, want %q", exec.llmModelName, "light-model")
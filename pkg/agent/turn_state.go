@@ -11,9 +11,11 @@ import (
 	"time"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/checkpoint"
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/routing"
 	"github.com/sipeed/picoclaw/pkg/session"
 	"github.com/sipeed/picoclaw/pkg/tools"
 )
@@ -135,7 +137,15 @@ type turnExecution struct {
 	activeModel       string
 	activeModelConfig *config.ModelConfig
 	activeProvider    providers.LLMProvider
-	usedLight         bool
+
+	// Model routing state. tier indexes agent.RoutingTiers, and
+	// len(agent.RoutingTiers) means the primary model.
+	tier        int
+	routeReq    routing.Requirements
+	escalations int
+	// tierToolMark is the number of recorded tool executions when the
+	// current tier took over, so escalation counts only its own errors.
+	tierToolMark int
 
 	// LLM call per-iteration state
 	response            *providers.LLMResponse
@@ -201,6 +211,11 @@ type turnState struct {
 	toolExecutions    []ToolExecutionRecord
 	turnCtx           *TurnContext
 
+	// routingTier is "light" or "primary" when model routing picked the
+	// model for this turn; routingModel is the model it picked.
+	routingTier  string
+	routingModel string
+
 	channel     string
 	chatID      string
 	workspace   string
@@ -225,6 +240,10 @@ type turnState struct {
 	restorePointSummary string
 	persistedMessages   []providers.Message
 
+	// checkpoints captures workspace files before tools modify them; nil when
+	// checkpoints are disabled.
+	checkpoints *checkpoint.Recorder
+
 	// SubTurn support (from HEAD)
 	depth                int                    // SubTurn depth (0 for root turn)
 	parentTurnID         string                 // Parent turn ID (empty for root turn)
@@ -285,6 +304,14 @@ func newTurnState(agent *AgentInstance, opts processOptions, scope turnEventScop
 		ts.restorePointHistory = append([]providers.Message(nil), history...)
 		ts.restorePointSummary = agent.Sessions.GetSummary(opts.Dispatch.SessionKey)
 	}
+	if agent != nil && agent.Checkpoints != nil {
+		ts.checkpoints = agent.Checkpoints.Begin(
+			agent.Workspace,
+			opts.Dispatch.SessionKey,
+			"turn",
+			opts.Dispatch.UserMessage,
+		)
+	}
 
 	return ts
 }
@@ -435,6 +462,19 @@ func (ts *turnState) recordToolKind(tool string) {
 	ts.toolKinds = append(ts.toolKinds, tool)
 }
 
+func (ts *turnState) setRouting(tier, model string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.routingTier = tier
+	ts.routingModel = model
+}
+
+func (ts *turnState) routingSnapshot() (tier, model string) {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	return ts.routingTier, ts.routingModel
+}
+
 func (ts *turnState) toolKindsSnapshot() []string {
 	ts.mu.RLock()
 	defer ts.mu.RUnlock()
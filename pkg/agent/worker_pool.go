@@ -14,6 +14,7 @@ import (
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/media"
 	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/tools"
 )
 
 type WorkerPool struct {
@@ -140,6 +141,11 @@ func (p *WorkerPool) dispatchLoop() {
 			if !ok {
 				return
 			}
+			// Approval presses are answered here: the turn waiting for one
+			// holds the worker its session is routed to.
+			if resolveApprovalCallback(msg) {
+				continue
+			}
 
 			workerIdx := p.routeMessage(msg)
 			select {
@@ -190,6 +196,12 @@ func (p *WorkerPool) SetTranscriber(t asr.Transcriber) {
 	}
 }
 
+func (p *WorkerPool) SetRemoteDelegator(remote tools.RemoteDelegator) {
+	for _, worker := range p.workers {
+		worker.SetRemoteDelegator(remote)
+	}
+}
+
 func (p *WorkerPool) SetReloadFunc(fn func() error) {
 	for _, worker := range p.workers {
 		worker.SetReloadFunc(fn)
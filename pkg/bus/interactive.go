@@ -0,0 +1,268 @@
+package bus
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Interactive payload kinds.
+const (
+	InteractiveKindButtons = "buttons" // one or more rows of buttons
+	InteractiveKindSelect  = "select"  // a single select menu
+	InteractiveKindConfirm = "confirm" // a confirmation card (confirm / cancel)
+)
+
+// Interactive button styles. Channels map them to the closest native style and
+// ignore styles they cannot render.
+const (
+	InteractiveStyleDefault = ""
+	InteractiveStylePrimary = "primary"
+	InteractiveStyleDanger  = "danger"
+)
+
+// InteractiveButton is a single pressable element. ID is echoed back in the
+// InteractionCallback when the user presses it. A button with a URL opens the
+// link instead and never produces a callback.
+type InteractiveButton struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	Value string `json:"value,omitempty"`
+	Style string `json:"style,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
+// InteractiveOption is one choice of an InteractiveSelect.
+type InteractiveOption struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	Value string `json:"value,omitempty"`
+}
+
+// InteractiveSelect is a select menu. Pressing an option produces a callback
+// whose ActionID is the option ID.
+type InteractiveSelect struct {
+	ID          string              `json:"id"`
+	Placeholder string              `json:"placeholder,omitempty"`
+	Options     []InteractiveOption `json:"options"`
+}
+
+// InteractivePayload is the channel-neutral description of interactive
+// elements attached to an outbound message. Channels that implement
+// channels.InteractiveCapable render it natively; all other channels receive
+// FallbackText as plain text with numbered options.
+type InteractivePayload struct {
+	Kind   string                `json:"kind"`
+	Title  string                `json:"title,omitempty"`
+	Rows   [][]InteractiveButton `json:"rows,omitempty"`
+	Select *InteractiveSelect    `json:"select,omitempty"`
+}
+
+// InteractionCallback describes a button press or select choice coming back
+// from a channel.
+type InteractionCallback struct {
+	ActionID  string `json:"action_id"`
+	Value     string `json:"value,omitempty"`
+	Label     string `json:"label,omitempty"`
+	MessageID string `json:"message_id,omitempty"` // platform message that carried the element
+}
+
+// Content returns the text that represents the callback in the conversation.
+func (cb InteractionCallback) Content() string {
+	text := cb.Value
+	if text == "" {
+		text = cb.Label
+	}
+	if text == "" {
+		text = cb.ActionID
+	}
+	return fmt.Sprintf("[interactive action: %s] %s", cb.ActionID, text)
+}
+
+// NewConfirmationPayload builds a confirmation card with a confirm and a
+// cancel button. The pressed button reports "<id>:confirm" or "<id>:cancel".
+func NewConfirmationPayload(id, title, confirmLabel, cancelLabel string) *InteractivePayload {
+	if confirmLabel == "" {
+		confirmLabel = "Confirm"
+	}
+	if cancelLabel == "" {
+		cancelLabel = "Cancel"
+	}
+	return &InteractivePayload{
+		Kind:  InteractiveKindConfirm,
+		Title: title,
+		Rows: [][]InteractiveButton{{
+			{ID: id + ":confirm", Label: confirmLabel, Value: "confirm", Style: InteractiveStylePrimary},
+			{ID: id + ":cancel", Label: cancelLabel, Value: "cancel", Style: InteractiveStyleDanger},
+		}},
+	}
+}
+
+// Validate checks that the payload is well formed: it must carry at least one
+// actionable element and every element must have an ID and a label.
+func (p *InteractivePayload) Validate() error {
+	if p == nil {
+		return nil
+	}
+	switch p.Kind {
+	case InteractiveKindButtons, InteractiveKindConfirm:
+		if len(p.Rows) == 0 {
+			return fmt.Errorf("interactive %s payload requires at least one button row", p.Kind)
+		}
+	case InteractiveKindSelect:
+		if p.Select == nil || len(p.Select.Options) == 0 {
+			return fmt.Errorf("interactive select payload requires at least one option")
+		}
+	default:
+		return fmt.Errorf("unknown interactive kind %q", p.Kind)
+	}
+
+	seen := make(map[string]struct{})
+	check := func(id, label string) error {
+		if strings.TrimSpace(id) == "" {
+			return fmt.Errorf("interactive element %q has an empty id", label)
+		}
+		if strings.TrimSpace(label) == "" {
+			return fmt.Errorf("interactive element %q has an empty label", id)
+		}
+		if _, dup := seen[id]; dup {
+			return fmt.Errorf("duplicate interactive element id %q", id)
+		}
+		seen[id] = struct{}{}
+		return nil
+	}
+	for _, row := range p.Rows {
+		if len(row) == 0 {
+			return fmt.Errorf("interactive button row is empty")
+		}
+		for _, btn := range row {
+			if err := check(btn.ID, btn.Label); err != nil {
+				return err
+			}
+		}
+	}
+	if p.Select != nil {
+		for _, opt := range p.Select.Options {
+			if err := check(opt.ID, opt.Label); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Choices returns the actionable elements of the payload in display order as
+// callbacks. URL buttons are skipped because they never call back.
+func (p *InteractivePayload) Choices() []InteractionCallback {
+	if p == nil {
+		return nil
+	}
+	var out []InteractionCallback
+	for _, row := range p.Rows {
+		for _, btn := range row {
+			if btn.URL != "" {
+				continue
+			}
+			out = append(out, InteractionCallback{ActionID: btn.ID, Value: btn.Value, Label: btn.Label})
+		}
+	}
+	if p.Select != nil {
+		for _, opt := range p.Select.Options {
+			out = append(out, InteractionCallback{ActionID: opt.ID, Value: opt.Value, Label: opt.Label})
+		}
+	}
+	return out
+}
+
+// Lookup returns the choice with the given action ID.
+func (p *InteractivePayload) Lookup(actionID string) (InteractionCallback, bool) {
+	for _, choice := range p.Choices() {
+		if choice.ActionID == actionID {
+			return choice, true
+		}
+	}
+	return InteractionCallback{}, false
+}
+
+// FallbackText renders content plus the payload as plain text with numbered
+// options, for channels that cannot display interactive elements. Users answer
+// by replying with the option number or its label.
+func (p *InteractivePayload) FallbackText(content string) string {
+	if p == nil {
+		return content
+	}
+	var sb strings.Builder
+	if p.Title != "" {
+		sb.WriteString(p.Title)
+		sb.WriteString("\n\n")
+	}
+	if content != "" {
+		sb.WriteString(content)
+		sb.WriteString("\n\n")
+	}
+	n := 0
+	for _, row := range p.Rows {
+		for _, btn := range row {
+			if btn.URL != "" {
+				sb.WriteString("- " + btn.Label + ": " + btn.URL + "\n")
+				continue
+			}
+			n++
+			sb.WriteString(strconv.Itoa(n) + ". " + btn.Label + "\n")
+		}
+	}
+	if p.Select != nil {
+		if p.Select.Placeholder != "" {
+			sb.WriteString(p.Select.Placeholder + "\n")
+		}
+		for _, opt := range p.Select.Options {
+			n++
+			sb.WriteString(strconv.Itoa(n) + ". " + opt.Label + "\n")
+		}
+	}
+	if n > 0 {
+		sb.WriteString("\nReply with the number of your choice.")
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// MatchFallbackReply resolves a plain-text reply against the numbered options
+// produced by FallbackText. The reply may be the option number or its label.
+func (p *InteractivePayload) MatchFallbackReply(reply string) (InteractionCallback, bool) {
+	reply = strings.TrimSpace(reply)
+	if p == nil || reply == "" {
+		return InteractionCallback{}, false
+	}
+	choices := p.Choices()
+	if n, err := strconv.Atoi(strings.TrimSuffix(reply, ".")); err == nil {
+		if n >= 1 && n <= len(choices) {
+			return choices[n-1], true
+		}
+		return InteractionCallback{}, false
+	}
+	for _, choice := range choices {
+		if strings.EqualFold(choice.Label, reply) {
+			return choice, true
+		}
+	}
+	return InteractionCallback{}, false
+}
+
+func cloneInteractivePayload(p *InteractivePayload) *InteractivePayload {
+	if p == nil {
+		return nil
+	}
+	cloned := *p
+	if len(p.Rows) > 0 {
+		cloned.Rows = make([][]InteractiveButton, len(p.Rows))
+		for i, row := range p.Rows {
+			cloned.Rows[i] = append([]InteractiveButton(nil), row...)
+		}
+	}
+	if p.Select != nil {
+		sel := *p.Select
+		sel.Options = append([]InteractiveOption(nil), p.Select.Options...)
+		cloned.Select = &sel
+	}
+	return &cloned
+}
@@ -0,0 +1,126 @@
+package bus
+
+import (
+	"strings"
+	"testing"
+)
+
+func testButtonsPayload() *InteractivePayload {
+	return &InteractivePayload{
+		Kind:  InteractiveKindButtons,
+		Title: "Deploy?",
+		Rows: [][]InteractiveButton{
+			{
+				{ID: "deploy:prod", Label: "Production", Value: "prod", Style: InteractiveStyleDanger},
+				{ID: "deploy:staging", Label: "Staging", Value: "staging"},
+			},
+			{{ID: "docs", Label: "Docs", URL: "https://example.com/docs"}},
+		},
+	}
+}
+
+func TestInteractivePayloadValidate(t *testing.T) {
+	if err := testButtonsPayload().Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		payload *InteractivePayload
+	}{
+		{name: "unknown kind", payload: &InteractivePayload{Kind: "carousel"}},
+		{name: "no rows", payload: &InteractivePayload{Kind: InteractiveKindButtons}},
+		{name: "empty select", payload: &InteractivePayload{Kind: InteractiveKindSelect, Select: &InteractiveSelect{ID: "s"}}},
+		{
+			name: "duplicate id",
+			payload: &InteractivePayload{
+				Kind: InteractiveKindButtons,
+				Rows: [][]InteractiveButton{{{ID: "a", Label: "A"}, {ID: "a", Label: "B"}}},
+			},
+		},
+		{
+			name: "empty label",
+			payload: &InteractivePayload{
+				Kind: InteractiveKindButtons,
+				Rows: [][]InteractiveButton{{{ID: "a"}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.payload.Validate(); err == nil {
+				t.Fatal("Validate() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestInteractivePayloadFallbackText(t *testing.T) {
+	got := testButtonsPayload().FallbackText("Pick a target.")
+	want := "Deploy?\n\nPick a target.\n\n1. Production\n2. Staging\n- Docs: https://example.com/docs\n\n" +
+		"Reply with the number of your choice."
+	if got != want {
+		t.Fatalf("FallbackText() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestInteractivePayloadMatchFallbackReply(t *testing.T) {
+	p := testButtonsPayload()
+	p.Select = &InteractiveSelect{
+		ID:      "region",
+		Options: []InteractiveOption{{ID: "region:eu", Label: "Europe"}},
+	}
+
+	tests := []struct {
+		reply  string
+		wantID string
+		wantOK bool
+	}{
+		{reply: "1", wantID: "deploy:prod", wantOK: true},
+		{reply: " 2. ", wantID: "deploy:staging", wantOK: true},
+		{reply: "3", wantID: "region:eu", wantOK: true},
+		{reply: "europe", wantID: "region:eu", wantOK: true},
+		{reply: "4"},
+		{reply: "Docs"},
+		{reply: "something else"},
+	}
+	for _, tt := range tests {
+		got, ok := p.MatchFallbackReply(tt.reply)
+		if ok != tt.wantOK || got.ActionID != tt.wantID {
+			t.Errorf("MatchFallbackReply(%q) = (%q, %v), want (%q, %v)", tt.reply, got.ActionID, ok, tt.wantID, tt.wantOK)
+		}
+	}
+}
+
+func TestNewConfirmationPayload(t *testing.T) {
+	p := NewConfirmationPayload("approve-42", "Run rm?", "", "")
+	if err := p.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	choices := p.Choices()
+	if len(choices) != 2 || choices[0].ActionID != "approve-42:confirm" || choices[1].ActionID != "approve-42:cancel" {
+		t.Fatalf("Choices() = %+v", choices)
+	}
+	if choices[0].Label != "Confirm" || choices[1].Label != "Cancel" {
+		t.Fatalf("default labels = %q/%q", choices[0].Label, choices[1].Label)
+	}
+}
+
+func TestInteractionCallbackContent(t *testing.T) {
+	got := InteractionCallback{ActionID: "deploy:prod", Value: "prod", Label: "Production"}.Content()
+	if !strings.HasPrefix(got, "[interactive action: deploy:prod]") || !strings.HasSuffix(got, "prod") {
+		t.Fatalf("Content() = %q", got)
+	}
+	if got := (InteractionCallback{ActionID: "x"}).Content(); got != "[interactive action: x] x" {
+		t.Fatalf("Content() = %q", got)
+	}
+}
+
+func TestNormalizeOutboundMessageClonesInteractive(t *testing.T) {
+	p := testButtonsPayload()
+	msg := NormalizeOutboundMessage(OutboundMessage{Channel: "test", ChatID: "1", Interactive: p})
+	p.Rows[0][0].Label = "changed"
+	if msg.Interactive.Rows[0][0].Label != "Production" {
+		t.Fatal("NormalizeOutboundMessage() did not clone the interactive payload")
+	}
+}
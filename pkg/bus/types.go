@@ -43,6 +43,10 @@ type InboundMessage struct {
 	MediaScope string         `json:"media_scope,omitempty"` // media lifecycle scope
 	SessionKey string         `json:"session_key"`
 
+	// Interaction is set when the message is a button press or select choice
+	// on a previously sent interactive payload.
+	Interaction *InteractionCallback `json:"interaction,omitempty"`
+
 	// Convenience mirrors derived from Context for runtime consumers.
 	Channel   string `json:"channel"`
 	SenderID  string `json:"sender_id"`
@@ -82,6 +86,10 @@ type OutboundMessage struct {
 	Content          string         `json:"content"`
 	ReplyToMessageID string         `json:"reply_to_message_id,omitempty"`
 	ContextUsage     *ContextUsage  `json:"context_usage,omitempty"`
+
+	// Interactive optionally attaches buttons, a select menu or a confirmation
+	// card to the message.
+	Interactive *InteractivePayload `json:"interactive,omitempty"`
 }
 
 // MediaPart describes a single media attachment to send.
@@ -8,6 +8,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -94,6 +95,9 @@ type BaseChannel struct {
 	placeholderRecorder PlaceholderRecorder
 	owner               Channel // the concrete channel that embeds this BaseChannel
 	reasoningChannelID  string
+	inboundLimiter      *InboundLimiter
+	pendingInteractive  sync.Map // chatID → interactiveFallbackEntry
+	sentInteractive     sync.Map // platform messageID → interactiveMessageEntry
 }
 
 func NewBaseChannel(
@@ -266,6 +270,35 @@ func (c *BaseChannel) HandleMessageWithContext(
 	media []string,
 	inboundCtx bus.InboundContext,
 	senderOpts ...bus.SenderInfo,
+) error {
+	var interaction *bus.InteractionCallback
+	if len(media) == 0 {
+		if interaction = c.resolveFallbackReply(deliveryChatID, content); interaction != nil {
+			content = interaction.Content()
+		}
+	}
+	return c.handleInbound(ctx, deliveryChatID, content, media, inboundCtx, interaction, senderOpts...)
+}
+
+// HandleInteraction publishes a button press or select choice on an
+// interactive payload as an inbound message carrying the action ID.
+func (c *BaseChannel) HandleInteraction(
+	ctx context.Context,
+	deliveryChatID string,
+	callback bus.InteractionCallback,
+	inboundCtx bus.InboundContext,
+	senderOpts ...bus.SenderInfo,
+) error {
+	return c.handleInbound(ctx, deliveryChatID, callback.Content(), nil, inboundCtx, &callback, senderOpts...)
+}
+
+func (c *BaseChannel) handleInbound(
+	ctx context.Context,
+	deliveryChatID, content string,
+	media []string,
+	inboundCtx bus.InboundContext,
+	interaction *bus.InteractionCallback,
+	senderOpts ...bus.SenderInfo,
 ) error {
 	// Use SenderInfo-based allow check when available, else fall back to string
 	var sender bus.SenderInfo
@@ -301,14 +334,20 @@ func (c *BaseChannel) HandleMessageWithContext(
 		inboundCtx.SenderID = resolvedSenderID
 	}
 
+	if decision := c.inboundLimiter.Check(inboundCtx, content); !decision.Allowed {
+		c.rejectInbound(ctx, deliveryChatID, inboundCtx, decision)
+		return nil
+	}
+
 	scope := BuildMediaScope(c.name, deliveryChatID, inboundCtx.MessageID)
 
 	msg := bus.InboundMessage{
-		Context:    inboundCtx,
-		Sender:     sender,
-		Content:    content,
-		Media:      media,
-		MediaScope: scope,
+		Context:     inboundCtx,
+		Sender:      sender,
+		Content:     content,
+		Media:       media,
+		MediaScope:  scope,
+		Interaction: interaction,
 	}
 	msg = bus.NormalizeInboundMessage(msg)
 
@@ -355,6 +394,40 @@ func (c *BaseChannel) HandleMessageWithContext(
 	return nil
 }
 
+// rejectInbound drops a throttled message, answering with the limiter's
+// notice when it carries one.
+func (c *BaseChannel) rejectInbound(
+	ctx context.Context,
+	deliveryChatID string,
+	inboundCtx bus.InboundContext,
+	decision InboundDecision,
+) {
+	logger.DebugCF("channels", "Inbound message throttled", map[string]any{
+		"channel":     c.name,
+		"chat_id":     deliveryChatID,
+		"sender_id":   inboundCtx.SenderID,
+		"reason":      string(decision.Reason),
+		"retry_after": decision.RetryAfter.String(),
+	})
+	if decision.Reply == "" {
+		return
+	}
+	err := c.bus.PublishOutbound(ctx, bus.OutboundMessage{
+		Channel:          c.name,
+		ChatID:           deliveryChatID,
+		Context:          inboundCtx,
+		Content:          decision.Reply,
+		ReplyToMessageID: inboundCtx.MessageID,
+	})
+	if err != nil {
+		logger.WarnCF("channels", "Failed to send inbound limit notice", map[string]any{
+			"channel": c.name,
+			"chat_id": deliveryChatID,
+			"error":   err.Error(),
+		})
+	}
+}
+
 // HandleInboundContext publishes a normalized inbound message using only the
 // structured context.
 func (c *BaseChannel) HandleInboundContext(
@@ -382,6 +455,12 @@ func (c *BaseChannel) SetPlaceholderRecorder(r PlaceholderRecorder) {
 	c.placeholderRecorder = r
 }
 
+// SetInboundLimiter injects the rate and flood limiter applied to inbound
+// messages before they are published. A nil limiter disables throttling.
+func (c *BaseChannel) SetInboundLimiter(l *InboundLimiter) {
+	c.inboundLimiter = l
+}
+
 // GetPlaceholderRecorder returns the injected PlaceholderRecorder (may be nil).
 func (c *BaseChannel) GetPlaceholderRecorder() PlaceholderRecorder {
 	return c.placeholderRecorder
@@ -319,3 +319,42 @@ func TestHandleInboundContext_PublishesNormalizedContext(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleInboundContext_InboundLimiter(t *testing.T) {
+	msgBus := bus.NewMessageBus()
+	defer msgBus.Close()
+
+	ch := NewBaseChannel("test", nil, msgBus, nil)
+	ch.SetInboundLimiter(NewInboundLimiter(config.InboundLimitConfig{
+		Enabled:         true,
+		SenderPerMinute: 1,
+		SenderBurst:     1,
+		CooldownReply:   "slow down",
+	}, nil))
+	inbound := bus.InboundContext{
+		Channel:   "test",
+		ChatID:    "chat-1",
+		ChatType:  "direct",
+		SenderID:  "user-1",
+		MessageID: "msg-1",
+	}
+
+	if err := ch.HandleInboundContext(context.Background(), "chat-1", "hello", nil, inbound); err != nil {
+		t.Fatalf("first message: %v", err)
+	}
+	<-msgBus.InboundChan()
+
+	inbound.MessageID = "msg-2"
+	if err := ch.HandleInboundContext(context.Background(), "chat-1", "again", nil, inbound); err != nil {
+		t.Fatalf("throttled message: %v", err)
+	}
+	select {
+	case msg := <-msgBus.InboundChan():
+		t.Fatalf("throttled message was published: %+v", msg)
+	default:
+	}
+	reply := <-msgBus.OutboundChan()
+	if reply.Content != "slow down" || reply.ChatID != "chat-1" || reply.ReplyToMessageID != "msg-2" {
+		t.Fatalf("cooldown reply = %+v", reply)
+	}
+}
@@ -0,0 +1,88 @@
+package dingtalk
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/open-dingtalk/dingtalk-stream-sdk-go/chatbot"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+)
+
+// dingtalkSendMessageURL makes the DingTalk client post the given text as the
+// user's own message when a button is pressed. Stream mode does not deliver
+// ActionCard callbacks, so presses come back as ordinary replies that the
+// BaseChannel fallback tracker resolves to the pressed action.
+const dingtalkSendMessageURL = "dtmd://dingtalkclient/sendMessage?content="
+
+// SendInteractive implements channels.InteractiveCapable using an ActionCard.
+func (c *DingTalkChannel) SendInteractive(ctx context.Context, msg bus.OutboundMessage) ([]string, error) {
+	if !c.IsRunning() {
+		return nil, channels.ErrNotRunning
+	}
+	sessionWebhookRaw, ok := c.sessionWebhooks.Load(msg.ChatID)
+	if !ok {
+		return nil, fmt.Errorf("no session_webhook found for chat %s, cannot send message", msg.ChatID)
+	}
+	sessionWebhook, ok := sessionWebhookRaw.(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid session_webhook type for chat %s", msg.ChatID)
+	}
+
+	if err := chatbot.NewChatbotReplier().ReplyMessage(ctx, sessionWebhook, buildActionCard(msg)); err != nil {
+		return nil, fmt.Errorf("dingtalk send interactive: %w", channels.ErrTemporary)
+	}
+	c.RecordInteractiveFallback(msg.ChatID, msg.Interactive)
+	return nil, nil
+}
+
+func buildActionCard(msg bus.OutboundMessage) map[string]any {
+	p := msg.Interactive
+	title := p.Title
+	if title == "" {
+		title = "PicoClaw"
+	}
+	text := msg.Content
+	if p.Title != "" {
+		text = "### " + p.Title + "\n\n" + text
+	}
+	if p.Select != nil && p.Select.Placeholder != "" {
+		text += "\n\n" + p.Select.Placeholder
+	}
+
+	var btns []map[string]any
+	orientation := "0" // vertical
+	for _, row := range p.Rows {
+		if len(row) > 1 && len(p.Rows) == 1 {
+			orientation = "1" // a single row renders side by side
+		}
+		for _, btn := range row {
+			actionURL := btn.URL
+			if actionURL == "" {
+				actionURL = dingtalkSendMessageURL + url.QueryEscape(btn.Label)
+			}
+			btns = append(btns, map[string]any{"title": btn.Label, "actionURL": actionURL})
+		}
+	}
+	if p.Select != nil {
+		orientation = "0"
+		for _, opt := range p.Select.Options {
+			btns = append(btns, map[string]any{
+				"title":     opt.Label,
+				"actionURL": dingtalkSendMessageURL + url.QueryEscape(opt.Label),
+			})
+		}
+	}
+
+	return map[string]any{
+		"msgtype": "actionCard",
+		"actionCard": map[string]any{
+			"title":          title,
+			"text":           text,
+			"btnOrientation": orientation,
+			"btns":           btns,
+		},
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/sipeed/picoclaw/pkg/audio/tts"
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/channels/mdrender"
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/identity"
 	"github.com/sipeed/picoclaw/pkg/logger"
@@ -117,6 +118,7 @@ func (c *DiscordChannel) Start(ctx context.Context) error {
 	c.botUserID = botUser.ID
 
 	c.session.AddHandler(c.handleMessage)
+	c.session.AddHandler(c.handleInteraction)
 
 	go c.listenVoiceControl(c.ctx)
 
@@ -195,6 +197,10 @@ func (c *DiscordChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]s
 	content := msg.Content
 	if isToolFeedback {
 		content = channels.InitialAnimatedToolFeedbackContent(msg.Content)
+	} else if rendered := mdrender.Render(content, mdrender.Discord); len([]rune(rendered)) <= c.MaxMessageLength() {
+		// Tables become aligned code blocks, which can be wider than the
+		// source; keep the original text if that no longer fits.
+		content = rendered
 	}
 	msgID, err := c.sendChunk(ctx, channelID, content, msg.ReplyToMessageID)
 	if err != nil {
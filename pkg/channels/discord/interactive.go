@@ -0,0 +1,172 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/identity"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	discordCustomIDLimit     = 100
+	discordButtonsPerRow     = 5
+	discordSelectOptionLimit = 25
+)
+
+// SendInteractive implements channels.InteractiveCapable using message components.
+func (c *DiscordChannel) SendInteractive(ctx context.Context, msg bus.OutboundMessage) ([]string, error) {
+	if !c.IsRunning() {
+		return nil, channels.ErrNotRunning
+	}
+	channelID := msg.ChatID
+	if channelID == "" {
+		return nil, fmt.Errorf("channel ID is empty")
+	}
+
+	content := msg.Content
+	if title := msg.Interactive.Title; title != "" {
+		content = appendContent("**"+title+"**", content)
+	}
+	send := &discordgo.MessageSend{
+		Content:    content,
+		Components: buildDiscordComponents(msg.Interactive),
+	}
+	if msg.ReplyToMessageID != "" {
+		send.Reference = &discordgo.MessageReference{MessageID: msg.ReplyToMessageID, ChannelID: channelID}
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+	sent, err := c.session.ChannelMessageSendComplex(channelID, send, discordgo.WithContext(sendCtx))
+	if err != nil {
+		return nil, fmt.Errorf("discord send interactive: %w", channels.ErrTemporary)
+	}
+	c.RecordInteractiveMessage(sent.ID, msg.Interactive)
+	return []string{sent.ID}, nil
+}
+
+// buildDiscordComponents renders button rows (wrapping at Discord's five
+// buttons per row) and an optional string select menu.
+func buildDiscordComponents(p *bus.InteractivePayload) []discordgo.MessageComponent {
+	var rows []discordgo.MessageComponent
+	idx := 0
+	for _, row := range p.Rows {
+		var current []discordgo.MessageComponent
+		for _, btn := range row {
+			b := discordgo.Button{Label: btn.Label, Style: discordgo.SecondaryButton}
+			switch {
+			case btn.URL != "":
+				b.Style = discordgo.LinkButton
+				b.URL = btn.URL
+			default:
+				b.CustomID = channels.InteractiveActionRef(btn.ID, idx, discordCustomIDLimit)
+				idx++
+				switch btn.Style {
+				case bus.InteractiveStylePrimary:
+					b.Style = discordgo.PrimaryButton
+				case bus.InteractiveStyleDanger:
+					b.Style = discordgo.DangerButton
+				}
+			}
+			current = append(current, b)
+			if len(current) == discordButtonsPerRow {
+				rows = append(rows, discordgo.ActionsRow{Components: current})
+				current = nil
+			}
+		}
+		if len(current) > 0 {
+			rows = append(rows, discordgo.ActionsRow{Components: current})
+		}
+	}
+	if p.Select != nil {
+		// The menu's own custom_id is informational; callbacks resolve by value.
+		menuID := p.Select.ID
+		if len(menuID) > discordCustomIDLimit {
+			menuID = menuID[:discordCustomIDLimit]
+		}
+		menu := discordgo.SelectMenu{
+			MenuType:    discordgo.StringSelectMenu,
+			CustomID:    menuID,
+			Placeholder: p.Select.Placeholder,
+		}
+		for i, opt := range p.Select.Options {
+			if i == discordSelectOptionLimit {
+				break
+			}
+			menu.Options = append(menu.Options, discordgo.SelectMenuOption{
+				Label: opt.Label,
+				Value: channels.InteractiveActionRef(opt.ID, idx, discordCustomIDLimit),
+			})
+			idx++
+		}
+		rows = append(rows, discordgo.ActionsRow{Components: []discordgo.MessageComponent{menu}})
+	}
+	return rows
+}
+
+func (c *DiscordChannel) handleInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i == nil || i.Interaction == nil || i.Type != discordgo.InteractionMessageComponent {
+		return
+	}
+	// Acknowledge first; Discord invalidates interactions after three seconds.
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	}); err != nil {
+		logger.WarnCF("discord", "Failed to acknowledge interaction", map[string]any{
+			"error": err.Error(),
+		})
+	}
+
+	user := i.User
+	if i.Member != nil && i.Member.User != nil {
+		user = i.Member.User
+	}
+	if user == nil || i.Message == nil {
+		return
+	}
+
+	data := i.MessageComponentData()
+	actionRef := data.CustomID
+	if len(data.Values) > 0 {
+		actionRef = data.Values[0]
+	}
+
+	sender := bus.SenderInfo{
+		Platform:    "discord",
+		PlatformID:  user.ID,
+		CanonicalID: identity.BuildCanonicalID("discord", user.ID),
+		Username:    user.Username,
+		DisplayName: user.Username,
+	}
+	peerKind := "channel"
+	if i.GuildID == "" {
+		peerKind = "direct"
+	}
+	inboundCtx := bus.InboundContext{
+		Channel:          c.Name(),
+		ChatID:           i.ChannelID,
+		ChatType:         peerKind,
+		SenderID:         user.ID,
+		MessageID:        i.ID,
+		ReplyToMessageID: i.Message.ID,
+		Raw: map[string]string{
+			"user_id":        user.ID,
+			"username":       user.Username,
+			"guild_id":       i.GuildID,
+			"channel_id":     i.ChannelID,
+			"interaction_id": i.ID,
+		},
+	}
+	if i.GuildID != "" {
+		inboundCtx.SpaceID = i.GuildID
+		inboundCtx.SpaceType = "guild"
+	}
+
+	callback := c.InteractionFor(i.Message.ID, actionRef)
+	c.HandleInteraction(c.ctx, i.ChannelID, callback, inboundCtx, sender)
+}
@@ -0,0 +1,38 @@
+package discord
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+func TestBuildDiscordComponents_WrapsRowsAndAddsSelect(t *testing.T) {
+	row := make([]bus.InteractiveButton, 0, 6)
+	for _, id := range []string{"a", "b", "c", "d", "e", "f"} {
+		row = append(row, bus.InteractiveButton{ID: id, Label: id})
+	}
+	row[0].Style = bus.InteractiveStyleDanger
+
+	components := buildDiscordComponents(&bus.InteractivePayload{
+		Kind:   bus.InteractiveKindButtons,
+		Rows:   [][]bus.InteractiveButton{row},
+		Select: &bus.InteractiveSelect{ID: "pick", Options: []bus.InteractiveOption{{ID: "x", Label: "X"}}},
+	})
+
+	if len(components) != 3 {
+		t.Fatalf("action rows = %d, want 3", len(components))
+	}
+	first := components[0].(discordgo.ActionsRow)
+	if len(first.Components) != discordButtonsPerRow {
+		t.Fatalf("first row buttons = %d, want %d", len(first.Components), discordButtonsPerRow)
+	}
+	if btn := first.Components[0].(discordgo.Button); btn.Style != discordgo.DangerButton || btn.CustomID != "a" {
+		t.Fatalf("first button = %+v", btn)
+	}
+	menu := components[2].(discordgo.ActionsRow).Components[0].(discordgo.SelectMenu)
+	if menu.CustomID != "pick" || len(menu.Options) != 1 || menu.Options[0].Value != "x" {
+		t.Fatalf("select menu = %+v", menu)
+	}
+}
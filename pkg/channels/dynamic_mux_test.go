@@ -5,6 +5,7 @@ import (
 	"net/http/httptest"
 	"sync"
 	"testing"
+	"time"
 )
 
 func TestDynamicServeMuxExactMatch(t *testing.T) {
@@ -160,3 +161,33 @@ func TestDynamicServeMuxHandleUsesHandler(t *testing.T) {
 		t.Fatal("handler was not called")
 	}
 }
+
+func TestDynamicServeMuxHandleDuringLongRequest(t *testing.T) {
+	dm := newDynamicServeMux()
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	dm.HandleFunc("/stream", func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-release
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		dm.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/stream", nil))
+	}()
+	<-entered
+
+	registered := make(chan struct{})
+	go func() {
+		dm.HandleFunc("/other", func(w http.ResponseWriter, r *http.Request) {})
+		close(registered)
+	}()
+	select {
+	case <-registered:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Handle blocked while a request was being served")
+	}
+	close(release)
+	<-done
+}
@@ -1,6 +1,10 @@
 package channels
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 var (
 	// ErrNotRunning indicates the channel is not running.
@@ -19,3 +23,39 @@ var (
 	// Manager will not retry.
 	ErrSendFailed = errors.New("send failed")
 )
+
+// RetryAfterError wraps a send error with the delay the platform asked for
+// (e.g. Telegram's retry_after or an HTTP Retry-After header). It matches
+// ErrRateLimit with errors.Is.
+type RetryAfterError struct {
+	RetryAfter time.Duration
+	Err        error
+}
+
+// NewRetryAfterError returns a rate-limit error that carries retryAfter.
+func NewRetryAfterError(retryAfter time.Duration, err error) error {
+	return &RetryAfterError{RetryAfter: retryAfter, Err: err}
+}
+
+func (e *RetryAfterError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("%s: retry after %s", ErrRateLimit, e.RetryAfter)
+	}
+	return fmt.Sprintf("%s: retry after %s: %s", ErrRateLimit, e.RetryAfter, e.Err)
+}
+
+func (e *RetryAfterError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{ErrRateLimit}
+	}
+	return []error{ErrRateLimit, e.Err}
+}
+
+// RetryAfter returns the platform-requested delay carried by err, if any.
+func RetryAfter(err error) time.Duration {
+	var rae *RetryAfterError
+	if errors.As(err, &rae) && rae.RetryAfter > 0 {
+		return rae.RetryAfter
+	}
+	return 0
+}
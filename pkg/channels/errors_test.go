@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 )
 
 func TestErrorsIs(t *testing.T) {
@@ -54,3 +55,17 @@ func TestErrorMessages(t *testing.T) {
 		}
 	}
 }
+
+func TestRetryAfterError(t *testing.T) {
+	raw := errors.New("slow down")
+	err := fmt.Errorf("send: %w", NewRetryAfterError(3*time.Second, raw))
+	if !errors.Is(err, ErrRateLimit) || !errors.Is(err, raw) {
+		t.Fatalf("RetryAfterError should match ErrRateLimit and the cause: %v", err)
+	}
+	if got := RetryAfter(err); got != 3*time.Second {
+		t.Fatalf("RetryAfter() = %v, want 3s", got)
+	}
+	if got := RetryAfter(raw); got != 0 {
+		t.Fatalf("RetryAfter(plain) = %v, want 0", got)
+	}
+}
@@ -3,6 +3,9 @@ package channels
 import (
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // ClassifySendError wraps a raw error with the appropriate sentinel based on
@@ -21,6 +24,18 @@ func ClassifySendError(statusCode int, rawErr error) error {
 	}
 }
 
+// ClassifySendErrorWithRetryAfter is ClassifySendError for responses that
+// carry a Retry-After header (delta-seconds form). A 429 with a usable header
+// becomes a RetryAfterError so the Manager can honor the platform's delay.
+func ClassifySendErrorWithRetryAfter(statusCode int, retryAfter string, rawErr error) error {
+	if statusCode == http.StatusTooManyRequests {
+		if seconds, err := strconv.Atoi(strings.TrimSpace(retryAfter)); err == nil && seconds > 0 {
+			return NewRetryAfterError(time.Duration(seconds)*time.Second, rawErr)
+		}
+	}
+	return ClassifySendError(statusCode, rawErr)
+}
+
 // ClassifyNetError wraps a network/timeout error as ErrTemporary.
 func ClassifyNetError(err error) error {
 	if err == nil {
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 )
 
 func TestClassifySendError(t *testing.T) {
@@ -95,3 +96,28 @@ func TestClassifyNetError(t *testing.T) {
 		}
 	})
 }
+
+func TestClassifySendErrorWithRetryAfter(t *testing.T) {
+	raw := fmt.Errorf("too many requests")
+
+	err := ClassifySendErrorWithRetryAfter(429, "12", raw)
+	if !errors.Is(err, ErrRateLimit) {
+		t.Fatalf("expected ErrRateLimit, got %v", err)
+	}
+	if got := RetryAfter(err); got != 12*time.Second {
+		t.Fatalf("RetryAfter() = %v, want 12s", got)
+	}
+	if !errors.Is(err, raw) {
+		t.Fatalf("expected wrapped raw error, got %v", err)
+	}
+
+	err = ClassifySendErrorWithRetryAfter(429, "Wed, 21 Oct 2015 07:28:00 GMT", raw)
+	if !errors.Is(err, ErrRateLimit) || RetryAfter(err) != 0 {
+		t.Fatalf("unparseable Retry-After should fall back to plain ErrRateLimit, got %v", err)
+	}
+
+	err = ClassifySendErrorWithRetryAfter(503, "5", raw)
+	if !errors.Is(err, ErrTemporary) || RetryAfter(err) != 0 {
+		t.Fatalf("non-429 status should ignore Retry-After, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package channels
 
 import (
 	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
 	runtimeevents "github.com/sipeed/picoclaw/pkg/events"
 )
 
@@ -62,11 +63,58 @@ func channelEventAttrs(payload any) map[string]any {
 			attrs["retries"] = payload.Retries
 		}
 		return attrs
+	case ChannelInboundLimitedPayload:
+		attrs := map[string]any{"inbound": true, "reason": payload.Reason}
+		if payload.RetryAfterMS > 0 {
+			attrs["retry_after_ms"] = payload.RetryAfterMS
+		}
+		if payload.Muted {
+			attrs["muted"] = payload.Muted
+		}
+		if payload.Notified {
+			attrs["notified"] = payload.Notified
+		}
+		return attrs
+	case ChannelOutboxPayload:
+		attrs := map[string]any{"entry_id": payload.EntryID, "dead": payload.Dead}
+		if payload.Attempts > 0 {
+			attrs["attempts"] = payload.Attempts
+		}
+		setAttrString(attrs, "error", payload.Error)
+		return attrs
 	default:
 		return nil
 	}
 }
 
+// ChannelInboundLimitedPayload describes an inbound message dropped by the
+// channel's inbound limiter.
+type ChannelInboundLimitedPayload struct {
+	Reason       string `json:"reason"`
+	RetryAfterMS int64  `json:"retry_after_ms,omitempty"`
+	Muted        bool   `json:"muted,omitempty"`
+	Notified     bool   `json:"notified,omitempty"`
+}
+
+// newInboundLimiter builds a channel's inbound limiter that reports throttled
+// messages as channel.rate_limited events.
+func (m *Manager) newInboundLimiter(channelName string, cfg config.InboundLimitConfig) *InboundLimiter {
+	return NewInboundLimiter(cfg, func(inboundCtx bus.InboundContext, decision InboundDecision) {
+		m.publishChannelEvent(
+			runtimeevents.KindChannelRateLimited,
+			channelName,
+			scopeFromOutboundContext(inboundCtx),
+			runtimeevents.SeverityWarn,
+			ChannelInboundLimitedPayload{
+				Reason:       string(decision.Reason),
+				RetryAfterMS: decision.RetryAfter.Milliseconds(),
+				Muted:        decision.Muted,
+				Notified:     decision.Reply != "",
+			},
+		)
+	})
+}
+
 func setAttrString(attrs map[string]any, key, value string) {
 	if value != "" {
 		attrs[key] = value
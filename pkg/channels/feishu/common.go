@@ -7,6 +7,7 @@ import (
 
 	larkim "github.com/larksuite/oapi-sdk-go/v3/service/im/v1"
 
+	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
 )
 
@@ -42,6 +43,95 @@ func buildMarkdownCard(content string) (string, error) {
 	return string(data), nil
 }
 
+// feishuActionKey is the key of the callback value that carries the action ID.
+const feishuActionKey = "picoclaw_action"
+
+// buildInteractiveCard builds a JSON 2.0 card with markdown content followed by
+// the payload's buttons (one column set per row) and an optional static select.
+// Every element reports its action ID through a callback behavior.
+func buildInteractiveCard(content string, p *bus.InteractivePayload) (string, error) {
+	elements := []map[string]any{}
+	if content != "" {
+		elements = append(elements, map[string]any{"tag": "markdown", "content": content})
+	}
+
+	for _, row := range p.Rows {
+		columns := make([]map[string]any, 0, len(row))
+		for _, btn := range row {
+			button := map[string]any{
+				"tag":  "button",
+				"text": map[string]any{"tag": "plain_text", "content": btn.Label},
+				"type": feishuButtonType(btn.Style),
+			}
+			if btn.URL != "" {
+				button["behaviors"] = []map[string]any{{"type": "open_url", "default_url": btn.URL}}
+			} else {
+				button["behaviors"] = []map[string]any{{
+					"type":  "callback",
+					"value": map[string]any{feishuActionKey: btn.ID},
+				}}
+			}
+			columns = append(columns, map[string]any{
+				"tag":      "column",
+				"width":    "auto",
+				"elements": []map[string]any{button},
+			})
+		}
+		elements = append(elements, map[string]any{
+			"tag":     "column_set",
+			"columns": columns,
+		})
+	}
+
+	if p.Select != nil {
+		options := make([]map[string]any, 0, len(p.Select.Options))
+		for _, opt := range p.Select.Options {
+			options = append(options, map[string]any{
+				"text":  map[string]any{"tag": "plain_text", "content": opt.Label},
+				"value": opt.ID,
+			})
+		}
+		selectEl := map[string]any{
+			"tag":     "select_static",
+			"options": options,
+			"behaviors": []map[string]any{{
+				"type":  "callback",
+				"value": map[string]any{feishuActionKey: p.Select.ID},
+			}},
+		}
+		if p.Select.Placeholder != "" {
+			selectEl["placeholder"] = map[string]any{"tag": "plain_text", "content": p.Select.Placeholder}
+		}
+		elements = append(elements, selectEl)
+	}
+
+	card := map[string]any{
+		"schema": "2.0",
+		"body":   map[string]any{"elements": elements},
+	}
+	if p.Title != "" {
+		card["header"] = map[string]any{
+			"title": map[string]any{"tag": "plain_text", "content": p.Title},
+		}
+	}
+	data, err := json.Marshal(card)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func feishuButtonType(style string) string {
+	switch style {
+	case bus.InteractiveStylePrimary:
+		return "primary"
+	case bus.InteractiveStyleDanger:
+		return "danger"
+	default:
+		return "default"
+	}
+}
+
 // extractJSONStringField unmarshals content as JSON and returns the value of the given string field.
 // Returns "" if the content is invalid JSON or the field is missing/empty.
 func extractJSONStringField(content, field string) string {
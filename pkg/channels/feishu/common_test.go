@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	larkim "github.com/larksuite/oapi-sdk-go/v3/service/im/v1"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
 )
 
 func TestExtractJSONStringField(t *testing.T) {
@@ -500,3 +502,56 @@ func TestExtractCardImageKeys(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildInteractiveCard(t *testing.T) {
+	cardJSON, err := buildInteractiveCard("Pick one", &bus.InteractivePayload{
+		Kind:  bus.InteractiveKindButtons,
+		Title: "Deploy",
+		Rows: [][]bus.InteractiveButton{{
+			{ID: "prod", Label: "Production", Style: bus.InteractiveStyleDanger},
+			{ID: "docs", Label: "Docs", URL: "https://example.com"},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("buildInteractiveCard() error = %v", err)
+	}
+
+	var card struct {
+		Header struct {
+			Title struct {
+				Content string `json:"content"`
+			} `json:"title"`
+		} `json:"header"`
+		Body struct {
+			Elements []struct {
+				Tag     string `json:"tag"`
+				Columns []struct {
+					Elements []struct {
+						Type      string           `json:"type"`
+						Behaviors []map[string]any `json:"behaviors"`
+					} `json:"elements"`
+				} `json:"columns"`
+			} `json:"elements"`
+		} `json:"body"`
+	}
+	if err := json.Unmarshal([]byte(cardJSON), &card); err != nil {
+		t.Fatalf("unmarshal card: %v", err)
+	}
+	if card.Header.Title.Content != "Deploy" {
+		t.Fatalf("header title = %q", card.Header.Title.Content)
+	}
+	if len(card.Body.Elements) != 2 || card.Body.Elements[1].Tag != "column_set" {
+		t.Fatalf("elements = %+v", card.Body.Elements)
+	}
+	cols := card.Body.Elements[1].Columns
+	prod := cols[0].Elements[0]
+	if prod.Type != "danger" || prod.Behaviors[0]["type"] != "callback" {
+		t.Fatalf("prod button = %+v", prod)
+	}
+	if value := prod.Behaviors[0]["value"].(map[string]any); value[feishuActionKey] != "prod" {
+		t.Fatalf("callback value = %+v", value)
+	}
+	if docs := cols[1].Elements[0]; docs.Behaviors[0]["type"] != "open_url" {
+		t.Fatalf("docs button = %+v", docs)
+	}
+}
@@ -0,0 +1,106 @@
+//go:build amd64 || arm64 || riscv64 || mips64 || ppc64
+
+package feishu
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/larksuite/oapi-sdk-go/v3/event/dispatcher/callback"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/identity"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// SendInteractive implements channels.InteractiveCapable using a card with
+// callback buttons and a static select.
+func (c *FeishuChannel) SendInteractive(ctx context.Context, msg bus.OutboundMessage) ([]string, error) {
+	if !c.IsRunning() {
+		return nil, channels.ErrNotRunning
+	}
+	if msg.ChatID == "" {
+		return nil, fmt.Errorf("chat ID is empty: %w", channels.ErrSendFailed)
+	}
+
+	cardContent, err := buildInteractiveCard(msg.Content, msg.Interactive)
+	if err != nil {
+		return nil, fmt.Errorf("feishu interactive card build failed: %w", channels.ErrSendFailed)
+	}
+	msgID, err := c.sendCard(ctx, msg.ChatID, cardContent)
+	if err != nil {
+		return nil, err
+	}
+	c.RecordInteractiveMessage(msgID, msg.Interactive)
+	return []string{msgID}, nil
+}
+
+// handleCardAction turns a card button press or select choice into an inbound
+// interaction. Feishu expects a quick response, so only a toast is returned.
+func (c *FeishuChannel) handleCardAction(
+	ctx context.Context,
+	event *callback.CardActionTriggerEvent,
+) (*callback.CardActionTriggerResponse, error) {
+	if event == nil || event.Event == nil || event.Event.Action == nil {
+		return nil, nil
+	}
+	action := event.Event.Action
+	actionID, _ := action.Value[feishuActionKey].(string)
+	if action.Option != "" {
+		actionID = action.Option
+	}
+	if actionID == "" {
+		return nil, nil
+	}
+
+	var chatID, messageID string
+	if event.Event.Context != nil {
+		chatID = event.Event.Context.OpenChatID
+		messageID = event.Event.Context.OpenMessageID
+	}
+	senderID := ""
+	if op := event.Event.Operator; op != nil {
+		if op.UserID != nil && *op.UserID != "" {
+			senderID = *op.UserID
+		} else {
+			senderID = op.OpenID
+		}
+	}
+	if chatID == "" || senderID == "" {
+		return nil, nil
+	}
+
+	sender := bus.SenderInfo{
+		Platform:    "feishu",
+		PlatformID:  senderID,
+		CanonicalID: identity.BuildCanonicalID("feishu", senderID),
+	}
+	inboundCtx := bus.InboundContext{
+		Channel:          "feishu",
+		ChatID:           chatID,
+		SenderID:         senderID,
+		ReplyToMessageID: messageID,
+		Raw: map[string]string{
+			"open_message_id": messageID,
+			"card_action_tag": action.Tag,
+		},
+	}
+	if op := event.Event.Operator; op != nil && op.TenantKey != nil && *op.TenantKey != "" {
+		inboundCtx.SpaceType = "tenant"
+		inboundCtx.SpaceID = *op.TenantKey
+	}
+
+	logger.DebugCF("feishu", "Feishu card action received", map[string]any{
+		"sender_id": senderID,
+		"chat_id":   chatID,
+		"action_id": actionID,
+	})
+
+	if err := c.HandleInteraction(ctx, chatID, c.InteractionFor(messageID, actionID), inboundCtx, sender); err != nil {
+		return nil, err
+	}
+	return &callback.CardActionTriggerResponse{
+		Toast: &callback.Toast{Type: "info", Content: "Received"},
+	}, nil
+}
@@ -0,0 +1,378 @@
+package channels
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// InboundLimitReason explains why an inbound message was throttled.
+type InboundLimitReason string
+
+const (
+	InboundLimitSenderRate InboundLimitReason = "sender_rate"
+	InboundLimitChatRate   InboundLimitReason = "chat_rate"
+	InboundLimitRepeat     InboundLimitReason = "flood_repeat"
+	InboundLimitMentions   InboundLimitReason = "flood_mentions"
+	InboundLimitMuted      InboundLimitReason = "muted"
+)
+
+// Defaults used when an enabled inbound_limit leaves a value unset. Negative
+// values disable the corresponding check.
+const (
+	defaultInboundSenderPerMinute = 10
+	defaultInboundSenderBurst     = 5
+	defaultInboundChatPerMinute   = 30
+	defaultInboundChatBurst       = 10
+	defaultFloodRepeatThreshold   = 4
+	defaultFloodMentionThreshold  = 8
+	defaultFloodWindow            = time.Minute
+	defaultFloodMute              = 5 * time.Minute
+
+	defaultCooldownReply = "You're sending messages too quickly. Please wait {retry_after} and try again."
+	defaultMuteReply     = "Too many repeated messages. You are muted for {retry_after}."
+
+	// inboundLimitPruneInterval bounds how often idle sender/chat state is
+	// dropped.
+	inboundLimitPruneInterval = time.Minute
+)
+
+// InboundDecision is the result of InboundLimiter.Check.
+type InboundDecision struct {
+	Allowed bool
+	Reason  InboundLimitReason
+	// RetryAfter is how long the sender (or chat) has to wait.
+	RetryAfter time.Duration
+	// Muted is true when this message started a temporary mute.
+	Muted bool
+	// Reply is the notice to send back, if any. It is set at most once per
+	// cooldown so a throttled sender is not answered on every message.
+	Reply string
+}
+
+type inboundSenderState struct {
+	limiter    *rate.Limiter
+	recent     []inboundRecentMessage
+	mutedUntil time.Time
+	noticed    bool
+	lastSeen   time.Time
+}
+
+type inboundRecentMessage struct {
+	at        time.Time
+	text      string
+	mentioned bool
+}
+
+type inboundChatState struct {
+	limiter  *rate.Limiter
+	noticed  bool
+	lastSeen time.Time
+}
+
+// InboundLimiter applies per-sender and per-chat token buckets plus flood
+// detection to inbound messages of one channel. BaseChannel consults it
+// before publishing to the MessageBus.
+type InboundLimiter struct {
+	senderRate    rate.Limit
+	senderBurst   int
+	chatRate      rate.Limit
+	chatBurst     int
+	repeatLimit   int
+	mentionLimit  int
+	window        time.Duration
+	mute          time.Duration
+	cooldownReply string
+	muteReply     string
+	onLimited     func(bus.InboundContext, InboundDecision)
+	now           func() time.Time
+
+	mu        sync.Mutex
+	senders   map[string]*inboundSenderState
+	chats     map[string]*inboundChatState
+	lastPrune time.Time
+}
+
+// NewInboundLimiter builds a limiter from cfg, filling unset values with
+// defaults. onLimited, when non-nil, is called for every rejected message.
+func NewInboundLimiter(
+	cfg config.InboundLimitConfig,
+	onLimited func(bus.InboundContext, InboundDecision),
+) *InboundLimiter {
+	return &InboundLimiter{
+		senderRate:    perMinute(cfg.SenderPerMinute, defaultInboundSenderPerMinute),
+		senderBurst:   positiveOr(cfg.SenderBurst, defaultInboundSenderBurst),
+		chatRate:      perMinute(cfg.ChatPerMinute, defaultInboundChatPerMinute),
+		chatBurst:     positiveOr(cfg.ChatBurst, defaultInboundChatBurst),
+		repeatLimit:   thresholdOr(cfg.Flood.RepeatThreshold, defaultFloodRepeatThreshold),
+		mentionLimit:  thresholdOr(cfg.Flood.MentionThreshold, defaultFloodMentionThreshold),
+		window:        secondsOr(cfg.Flood.WindowSeconds, defaultFloodWindow),
+		mute:          secondsOr(cfg.Flood.MuteSeconds, defaultFloodMute),
+		cooldownReply: replyOr(cfg.CooldownReply, defaultCooldownReply),
+		muteReply:     replyOr(cfg.Flood.MuteReply, defaultMuteReply),
+		onLimited:     onLimited,
+		now:           time.Now,
+		senders:       make(map[string]*inboundSenderState),
+		chats:         make(map[string]*inboundChatState),
+	}
+}
+
+// Check records an inbound message and decides whether it may be published.
+// inboundCtx must already carry the resolved chat and sender IDs.
+func (l *InboundLimiter) Check(inboundCtx bus.InboundContext, content string) InboundDecision {
+	if l == nil {
+		return InboundDecision{Allowed: true}
+	}
+	decision := l.check(inboundCtx, content)
+	if !decision.Allowed && l.onLimited != nil {
+		l.onLimited(inboundCtx, decision)
+	}
+	return decision
+}
+
+func (l *InboundLimiter) check(inboundCtx bus.InboundContext, content string) InboundDecision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.pruneLocked(now)
+
+	var sender *inboundSenderState
+	if senderID := strings.TrimSpace(inboundCtx.SenderID); senderID != "" {
+		sender = l.senderLocked(senderID, now)
+		if now.Before(sender.mutedUntil) {
+			return InboundDecision{Reason: InboundLimitMuted, RetryAfter: sender.mutedUntil.Sub(now)}
+		}
+		if reason := l.recordFloodLocked(sender, content, inboundCtx.Mentioned, now); reason != "" {
+			sender.mutedUntil = now.Add(l.mute)
+			sender.recent = nil
+			return InboundDecision{
+				Reason:     reason,
+				RetryAfter: l.mute,
+				Muted:      true,
+				Reply:      formatInboundReply(l.muteReply, l.mute),
+			}
+		}
+	}
+
+	var senderRes *rate.Reservation
+	if sender != nil && sender.limiter != nil {
+		senderRes = sender.limiter.ReserveN(now, 1)
+		if delay := senderRes.DelayFrom(now); delay > 0 {
+			senderRes.CancelAt(now)
+			return l.cooldownLocked(&sender.noticed, InboundLimitSenderRate, delay)
+		}
+	}
+
+	var chat *inboundChatState
+	if chatID := strings.TrimSpace(inboundCtx.ChatID); chatID != "" {
+		chat = l.chatLocked(chatID, now)
+		if chat.limiter != nil {
+			res := chat.limiter.ReserveN(now, 1)
+			if delay := res.DelayFrom(now); delay > 0 {
+				res.CancelAt(now)
+				if senderRes != nil {
+					senderRes.CancelAt(now)
+				}
+				return l.cooldownLocked(&chat.noticed, InboundLimitChatRate, delay)
+			}
+		}
+		chat.noticed = false
+	}
+	if sender != nil {
+		sender.noticed = false
+	}
+	return InboundDecision{Allowed: true}
+}
+
+// cooldownLocked builds a rate decision, attaching the cooldown reply only for
+// the first rejection since the last accepted message.
+func (l *InboundLimiter) cooldownLocked(
+	noticed *bool,
+	reason InboundLimitReason,
+	delay time.Duration,
+) InboundDecision {
+	decision := InboundDecision{Reason: reason, RetryAfter: delay}
+	if !*noticed {
+		*noticed = true
+		decision.Reply = formatInboundReply(l.cooldownReply, delay)
+	}
+	return decision
+}
+
+// recordFloodLocked appends the message to the sender's recent history and
+// reports the flood rule it trips, if any.
+func (l *InboundLimiter) recordFloodLocked(
+	sender *inboundSenderState,
+	content string,
+	mentioned bool,
+	now time.Time,
+) InboundLimitReason {
+	if l.repeatLimit <= 0 && l.mentionLimit <= 0 {
+		return ""
+	}
+	cutoff := now.Add(-l.window)
+	kept := sender.recent[:0]
+	for _, msg := range sender.recent {
+		if msg.at.After(cutoff) {
+			kept = append(kept, msg)
+		}
+	}
+	text := normalizeFloodText(content)
+	sender.recent = append(kept, inboundRecentMessage{at: now, text: text, mentioned: mentioned})
+
+	if l.repeatLimit > 0 && text != "" {
+		repeats := 0
+		for _, msg := range sender.recent {
+			if msg.text == text {
+				repeats++
+			}
+		}
+		if repeats >= l.repeatLimit {
+			return InboundLimitRepeat
+		}
+	}
+	if l.mentionLimit > 0 && mentioned {
+		mentions := 0
+		for _, msg := range sender.recent {
+			if msg.mentioned {
+				mentions++
+			}
+		}
+		if mentions >= l.mentionLimit {
+			return InboundLimitMentions
+		}
+	}
+	return ""
+}
+
+func (l *InboundLimiter) senderLocked(id string, now time.Time) *inboundSenderState {
+	state, ok := l.senders[id]
+	if !ok {
+		state = &inboundSenderState{}
+		if l.senderRate > 0 {
+			state.limiter = rate.NewLimiter(l.senderRate, l.senderBurst)
+		}
+		l.senders[id] = state
+	}
+	state.lastSeen = now
+	return state
+}
+
+func (l *InboundLimiter) chatLocked(id string, now time.Time) *inboundChatState {
+	state, ok := l.chats[id]
+	if !ok {
+		state = &inboundChatState{}
+		if l.chatRate > 0 {
+			state.limiter = rate.NewLimiter(l.chatRate, l.chatBurst)
+		}
+		l.chats[id] = state
+	}
+	state.lastSeen = now
+	return state
+}
+
+// pruneLocked drops state that has been idle long enough for its bucket to
+// refill and its flood window to expire.
+func (l *InboundLimiter) pruneLocked(now time.Time) {
+	if now.Sub(l.lastPrune) < inboundLimitPruneInterval {
+		return
+	}
+	l.lastPrune = now
+	idle := l.window
+	if refill := bucketRefill(l.senderRate, l.senderBurst); refill > idle {
+		idle = refill
+	}
+	for id, state := range l.senders {
+		if now.Sub(state.lastSeen) > idle && !now.Before(state.mutedUntil) {
+			delete(l.senders, id)
+		}
+	}
+	chatIdle := bucketRefill(l.chatRate, l.chatBurst)
+	for id, state := range l.chats {
+		if now.Sub(state.lastSeen) > chatIdle {
+			delete(l.chats, id)
+		}
+	}
+}
+
+func bucketRefill(limit rate.Limit, burst int) time.Duration {
+	if limit <= 0 {
+		return 0
+	}
+	return time.Duration(float64(burst) / float64(limit) * float64(time.Second))
+}
+
+func normalizeFloodText(content string) string {
+	return strings.ToLower(strings.Join(strings.Fields(content), " "))
+}
+
+// formatInboundReply fills the {retry_after} placeholder of a notice.
+func formatInboundReply(reply string, wait time.Duration) string {
+	if reply == "" {
+		return ""
+	}
+	return strings.ReplaceAll(reply, "{retry_after}", formatRetryAfter(wait))
+}
+
+func formatRetryAfter(wait time.Duration) string {
+	seconds := int((wait + time.Second - 1) / time.Second)
+	switch {
+	case seconds < 60:
+		return fmt.Sprintf("%ds", max(seconds, 1))
+	case seconds%60 == 0:
+		return fmt.Sprintf("%d min", seconds/60)
+	default:
+		return fmt.Sprintf("%d min %ds", seconds/60, seconds%60)
+	}
+}
+
+func perMinute(value, fallback float64) rate.Limit {
+	if value < 0 {
+		return 0
+	}
+	if value == 0 {
+		value = fallback
+	}
+	return rate.Limit(value / 60)
+}
+
+func positiveOr(value, fallback int) int {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
+
+func thresholdOr(value, fallback int) int {
+	if value < 0 {
+		return 0
+	}
+	return positiveOr(value, fallback)
+}
+
+func secondsOr(value int, fallback time.Duration) time.Duration {
+	if value > 0 {
+		return time.Duration(value) * time.Second
+	}
+	return fallback
+}
+
+// replyOr returns the configured notice, the default when unset, or "" when
+// the notice is disabled with "-".
+func replyOr(value, fallback string) string {
+	switch value = strings.TrimSpace(value); value {
+	case "":
+		return fallback
+	case "-":
+		return ""
+	default:
+		return value
+	}
+}
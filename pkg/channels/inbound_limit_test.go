@@ -0,0 +1,149 @@
+package channels
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func newTestInboundLimiter(cfg config.InboundLimitConfig) (*InboundLimiter, *time.Time) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := NewInboundLimiter(cfg, nil)
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestInboundLimiter_SenderBucket(t *testing.T) {
+	l, now := newTestInboundLimiter(config.InboundLimitConfig{
+		Enabled:         true,
+		SenderPerMinute: 6,
+		SenderBurst:     2,
+		ChatPerMinute:   -1,
+		Flood:           config.FloodControlConfig{RepeatThreshold: -1, MentionThreshold: -1},
+	})
+	in := bus.InboundContext{ChatID: "c1", SenderID: "u1"}
+
+	for i := range 2 {
+		if d := l.Check(in, "hi"); !d.Allowed {
+			t.Fatalf("message %d rejected: %+v", i, d)
+		}
+	}
+	d := l.Check(in, "hi")
+	if d.Allowed || d.Reason != InboundLimitSenderRate {
+		t.Fatalf("third message = %+v, want sender_rate rejection", d)
+	}
+	if d.RetryAfter != 10*time.Second {
+		t.Fatalf("RetryAfter = %v, want 10s", d.RetryAfter)
+	}
+	if d.Reply == "" {
+		t.Fatal("first rejection should carry the cooldown reply")
+	}
+	if d := l.Check(in, "hi"); d.Allowed || d.Reply != "" {
+		t.Fatalf("repeat rejection = %+v, want no second notice", d)
+	}
+
+	// Another sender has its own bucket.
+	if d := l.Check(bus.InboundContext{ChatID: "c1", SenderID: "u2"}, "hi"); !d.Allowed {
+		t.Fatalf("other sender rejected: %+v", d)
+	}
+
+	*now = now.Add(10 * time.Second)
+	if d := l.Check(in, "hi"); !d.Allowed {
+		t.Fatalf("message after refill rejected: %+v", d)
+	}
+	*now = now.Add(time.Second)
+	if d := l.Check(in, "hi"); d.Allowed || d.Reply == "" {
+		t.Fatalf("notice should be re-armed after an accepted message: %+v", d)
+	}
+}
+
+func TestInboundLimiter_ChatBucketRefundsSender(t *testing.T) {
+	l, _ := newTestInboundLimiter(config.InboundLimitConfig{
+		Enabled:         true,
+		SenderPerMinute: 60,
+		SenderBurst:     1,
+		ChatPerMinute:   1,
+		ChatBurst:       1,
+		Flood:           config.FloodControlConfig{RepeatThreshold: -1, MentionThreshold: -1},
+	})
+	if d := l.Check(bus.InboundContext{ChatID: "g", SenderID: "a"}, "one"); !d.Allowed {
+		t.Fatalf("first message rejected: %+v", d)
+	}
+	d := l.Check(bus.InboundContext{ChatID: "g", SenderID: "b"}, "two")
+	if d.Allowed || d.Reason != InboundLimitChatRate {
+		t.Fatalf("second message = %+v, want chat_rate rejection", d)
+	}
+	// b's own token was refunded, so b can still talk in another chat.
+	if d := l.Check(bus.InboundContext{ChatID: "other", SenderID: "b"}, "three"); !d.Allowed {
+		t.Fatalf("sender token not refunded: %+v", d)
+	}
+}
+
+func TestInboundLimiter_FloodMutes(t *testing.T) {
+	var limited []InboundDecision
+	l := NewInboundLimiter(config.InboundLimitConfig{
+		Enabled:         true,
+		SenderPerMinute: -1,
+		ChatPerMinute:   -1,
+		CooldownReply:   "-",
+		Flood: config.FloodControlConfig{
+			RepeatThreshold:  3,
+			MentionThreshold: 4,
+			WindowSeconds:    30,
+			MuteSeconds:      120,
+			MuteReply:        "muted for {retry_after}",
+		},
+	}, func(_ bus.InboundContext, d InboundDecision) { limited = append(limited, d) })
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	in := bus.InboundContext{ChatID: "c", SenderID: "spammer"}
+
+	l.Check(in, "Buy   now")
+	l.Check(in, "buy now")
+	d := l.Check(in, "BUY NOW")
+	if d.Allowed || d.Reason != InboundLimitRepeat || !d.Muted {
+		t.Fatalf("third repeat = %+v, want flood_repeat mute", d)
+	}
+	if d.Reply != "muted for 2 min" {
+		t.Fatalf("Reply = %q", d.Reply)
+	}
+
+	now = now.Add(time.Minute)
+	d = l.Check(in, "something else")
+	if d.Allowed || d.Reason != InboundLimitMuted || d.RetryAfter != time.Minute || d.Reply != "" {
+		t.Fatalf("message while muted = %+v", d)
+	}
+	if len(limited) != 2 {
+		t.Fatalf("onLimited calls = %d, want 2", len(limited))
+	}
+
+	now = now.Add(time.Minute)
+	if d := l.Check(in, "hello again"); !d.Allowed {
+		t.Fatalf("message after mute rejected: %+v", d)
+	}
+
+	mention := bus.InboundContext{ChatID: "c", SenderID: "pinger", Mentioned: true}
+	for i := range 3 {
+		if d := l.Check(mention, string(rune('a'+i))); !d.Allowed {
+			t.Fatalf("mention %d rejected: %+v", i, d)
+		}
+	}
+	if d := l.Check(mention, "d"); d.Allowed || d.Reason != InboundLimitMentions {
+		t.Fatalf("fourth mention = %+v, want flood_mentions", d)
+	}
+}
+
+func TestInboundLimiter_PrunesIdleState(t *testing.T) {
+	l, now := newTestInboundLimiter(config.InboundLimitConfig{Enabled: true})
+	l.Check(bus.InboundContext{ChatID: "c", SenderID: "u"}, "hi")
+	*now = now.Add(10 * time.Minute)
+	l.Check(bus.InboundContext{ChatID: "c2", SenderID: "u2"}, "hi")
+	if _, ok := l.senders["u"]; ok {
+		t.Fatal("idle sender state was not pruned")
+	}
+	if _, ok := l.chats["c"]; ok {
+		t.Fatal("idle chat state was not pruned")
+	}
+}
@@ -0,0 +1,141 @@
+package channels
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+// interactiveFallbackTTL bounds how long a numbered plain-text reply can still
+// be resolved against the options of the last interactive payload in a chat.
+const interactiveFallbackTTL = 30 * time.Minute
+
+type interactiveFallbackEntry struct {
+	payload   *bus.InteractivePayload
+	createdAt time.Time
+}
+
+// interactiveFallbackRecorder is implemented by BaseChannel. The Manager uses
+// it to remember degraded payloads so numbered replies map back to action IDs.
+type interactiveFallbackRecorder interface {
+	RecordInteractiveFallback(chatID string, payload *bus.InteractivePayload)
+}
+
+// RecordInteractiveFallback remembers the options of a payload that was sent as
+// numbered plain text so the next reply in the chat can be resolved to an
+// InteractionCallback. Channels that only partially support interactivity
+// (e.g. link buttons that make the user send a text) can call it directly.
+func (c *BaseChannel) RecordInteractiveFallback(chatID string, payload *bus.InteractivePayload) {
+	chatID = strings.TrimSpace(chatID)
+	if chatID == "" || len(payload.Choices()) == 0 {
+		return
+	}
+	c.pendingInteractive.Store(chatID, interactiveFallbackEntry{payload: payload, createdAt: time.Now()})
+}
+
+// resolveFallbackReply maps a plain-text reply onto the pending payload for the
+// chat. A matched reply consumes the pending payload; unrelated replies leave
+// it in place until it expires.
+func (c *BaseChannel) resolveFallbackReply(chatID, content string) *bus.InteractionCallback {
+	v, ok := c.pendingInteractive.Load(chatID)
+	if !ok {
+		return nil
+	}
+	entry := v.(interactiveFallbackEntry)
+	if time.Since(entry.createdAt) > interactiveFallbackTTL {
+		c.pendingInteractive.Delete(chatID)
+		return nil
+	}
+	choice, ok := entry.payload.MatchFallbackReply(content)
+	if !ok {
+		return nil
+	}
+	c.pendingInteractive.Delete(chatID)
+	return &choice
+}
+
+// prepareInteractiveOutbound keeps msg.Interactive for channels that render it
+// natively and otherwise folds the payload into the text as numbered options.
+func prepareInteractiveOutbound(ch Channel, msg bus.OutboundMessage) bus.OutboundMessage {
+	if msg.Interactive == nil {
+		return msg
+	}
+	if _, ok := ch.(InteractiveCapable); ok {
+		return msg
+	}
+	msg.Content = msg.Interactive.FallbackText(msg.Content)
+	if recorder, ok := ch.(interactiveFallbackRecorder); ok {
+		recorder.RecordInteractiveFallback(outboundMessageChatID(msg), msg.Interactive)
+	}
+	msg.Interactive = nil
+	return msg
+}
+
+// interactiveChunk returns the i-th of n chunks of msg. Only the last chunk
+// keeps the interactive payload so buttons appear below the full text.
+func interactiveChunk(msg bus.OutboundMessage, chunk string, i, n int) bus.OutboundMessage {
+	msg.Content = chunk
+	if i != n-1 {
+		msg.Interactive = nil
+	}
+	return msg
+}
+
+type interactiveMessageEntry struct {
+	payload   *bus.InteractivePayload
+	createdAt time.Time
+}
+
+// RecordInteractiveMessage remembers the payload attached to a sent platform
+// message so later callbacks can be enriched with the pressed element's label
+// and value. Entries expire after interactiveFallbackTTL.
+func (c *BaseChannel) RecordInteractiveMessage(messageID string, payload *bus.InteractivePayload) {
+	if messageID == "" || payload == nil {
+		return
+	}
+	now := time.Now()
+	c.sentInteractive.Range(func(key, value any) bool {
+		if now.Sub(value.(interactiveMessageEntry).createdAt) > interactiveFallbackTTL {
+			c.sentInteractive.Delete(key)
+		}
+		return true
+	})
+	c.sentInteractive.Store(messageID, interactiveMessageEntry{payload: payload, createdAt: now})
+}
+
+// InteractionFor builds the callback for actionID pressed on messageID. Action
+// references of the form "#N" (used by platforms with short callback data
+// limits) resolve to the N-th choice of the recorded payload.
+func (c *BaseChannel) InteractionFor(messageID, actionID string) bus.InteractionCallback {
+	cb := bus.InteractionCallback{ActionID: actionID, MessageID: messageID}
+	v, ok := c.sentInteractive.Load(messageID)
+	if !ok {
+		return cb
+	}
+	payload := v.(interactiveMessageEntry).payload
+	if ref, found := strings.CutPrefix(actionID, "#"); found {
+		choices := payload.Choices()
+		if n, err := strconv.Atoi(ref); err == nil && n >= 0 && n < len(choices) {
+			choice := choices[n]
+			choice.MessageID = messageID
+			return choice
+		}
+	}
+	if choice, found := payload.Lookup(actionID); found {
+		choice.MessageID = messageID
+		return choice
+	}
+	return cb
+}
+
+// InteractiveActionRef returns the reference to embed in a platform element for
+// the idx-th choice: the action ID itself when it fits maxLen bytes, otherwise
+// the positional "#N" form understood by InteractionFor.
+func InteractiveActionRef(actionID string, idx, maxLen int) string {
+	if maxLen <= 0 || len(actionID) <= maxLen {
+		return actionID
+	}
+	return "#" + strconv.Itoa(idx)
+}
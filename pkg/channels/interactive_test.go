@@ -0,0 +1,144 @@
+package channels
+
+import (
+	"context"
+	"testing"
+
+	"golang.org/x/time/rate"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+type mockInteractiveChannel struct {
+	mockChannel
+	interactive []bus.OutboundMessage
+}
+
+func (m *mockInteractiveChannel) SendInteractive(_ context.Context, msg bus.OutboundMessage) ([]string, error) {
+	m.interactive = append(m.interactive, msg)
+	return []string{"sent-1"}, nil
+}
+
+func testInteractivePayload() *bus.InteractivePayload {
+	return &bus.InteractivePayload{
+		Kind: bus.InteractiveKindButtons,
+		Rows: [][]bus.InteractiveButton{{
+			{ID: "yes", Label: "Yes", Value: "y"},
+			{ID: "no", Label: "No", Value: "n"},
+		}},
+	}
+}
+
+func TestPrepareInteractiveOutbound_FallbackForPlainChannel(t *testing.T) {
+	ch := &mockChannel{BaseChannel: *NewBaseChannel("test", nil, nil, nil)}
+	msg := prepareInteractiveOutbound(ch, bus.OutboundMessage{
+		Channel:     "test",
+		ChatID:      "chat-1",
+		Content:     "Continue?",
+		Interactive: testInteractivePayload(),
+	})
+
+	if msg.Interactive != nil {
+		t.Fatal("Interactive should be cleared for plain-text channels")
+	}
+	want := "Continue?\n\n1. Yes\n2. No\n\nReply with the number of your choice."
+	if msg.Content != want {
+		t.Fatalf("Content = %q, want %q", msg.Content, want)
+	}
+	if _, ok := ch.pendingInteractive.Load("chat-1"); !ok {
+		t.Fatal("fallback payload was not recorded")
+	}
+}
+
+func TestPrepareInteractiveOutbound_KeepsPayloadForCapableChannel(t *testing.T) {
+	ch := &mockInteractiveChannel{}
+	msg := prepareInteractiveOutbound(ch, bus.OutboundMessage{
+		ChatID:      "chat-1",
+		Content:     "Continue?",
+		Interactive: testInteractivePayload(),
+	})
+	if msg.Interactive == nil || msg.Content != "Continue?" {
+		t.Fatalf("prepareInteractiveOutbound() = %+v, want payload untouched", msg)
+	}
+}
+
+func TestHandleMessageWithContext_ResolvesFallbackReply(t *testing.T) {
+	msgBus := bus.NewMessageBus()
+	defer msgBus.Close()
+
+	ch := NewBaseChannel("test", nil, msgBus, nil)
+	ch.RecordInteractiveFallback("chat-1", testInteractivePayload())
+
+	inbound := bus.InboundContext{Channel: "test", ChatID: "chat-1", ChatType: "direct", SenderID: "u1"}
+	if err := ch.HandleMessageWithContext(context.Background(), "chat-1", "2", nil, inbound); err != nil {
+		t.Fatalf("HandleMessageWithContext() error = %v", err)
+	}
+	msg := <-msgBus.InboundChan()
+	if msg.Interaction == nil || msg.Interaction.ActionID != "no" {
+		t.Fatalf("Interaction = %+v, want action no", msg.Interaction)
+	}
+	if msg.Content != "[interactive action: no] n" {
+		t.Fatalf("Content = %q", msg.Content)
+	}
+
+	// The pending payload is consumed by the first matching reply.
+	if err := ch.HandleMessageWithContext(context.Background(), "chat-1", "2", nil, inbound); err != nil {
+		t.Fatalf("HandleMessageWithContext() error = %v", err)
+	}
+	msg = <-msgBus.InboundChan()
+	if msg.Interaction != nil || msg.Content != "2" {
+		t.Fatalf("second reply = %+v, want plain text", msg)
+	}
+}
+
+func TestInteractionFor(t *testing.T) {
+	ch := NewBaseChannel("test", nil, nil, nil)
+	ch.RecordInteractiveMessage("m1", testInteractivePayload())
+
+	if got := ch.InteractionFor("m1", "no"); got.Label != "No" || got.Value != "n" || got.MessageID != "m1" {
+		t.Fatalf("InteractionFor(no) = %+v", got)
+	}
+	if got := ch.InteractionFor("m1", "#0"); got.ActionID != "yes" {
+		t.Fatalf("InteractionFor(#0) = %+v", got)
+	}
+	if got := ch.InteractionFor("unknown", "raw"); got.ActionID != "raw" || got.Label != "" {
+		t.Fatalf("InteractionFor(unknown) = %+v", got)
+	}
+}
+
+func TestInteractiveActionRef(t *testing.T) {
+	if got := InteractiveActionRef("short", 3, 10); got != "short" {
+		t.Fatalf("InteractiveActionRef() = %q", got)
+	}
+	if got := InteractiveActionRef("a-very-long-action-id", 3, 10); got != "#3" {
+		t.Fatalf("InteractiveActionRef() = %q", got)
+	}
+}
+
+func TestSendWithRetry_UsesSendInteractive(t *testing.T) {
+	m := newTestManager()
+	ch := &mockInteractiveChannel{}
+	w := &channelWorker{ch: ch, limiter: rate.NewLimiter(rate.Inf, 1)}
+
+	msg := testOutboundMessage(bus.OutboundMessage{
+		Channel:     "test",
+		ChatID:      "1",
+		Content:     "Continue?",
+		Interactive: testInteractivePayload(),
+	})
+	m.sendWithRetry(context.Background(), "test", w, msg)
+
+	if len(ch.interactive) != 1 || len(ch.sentMessages) != 0 {
+		t.Fatalf("SendInteractive calls = %d, Send calls = %d", len(ch.interactive), len(ch.sentMessages))
+	}
+}
+
+func TestInteractiveChunk_KeepsPayloadOnLastChunk(t *testing.T) {
+	msg := bus.OutboundMessage{Content: "ab", Interactive: testInteractivePayload()}
+	if got := interactiveChunk(msg, "a", 0, 2); got.Interactive != nil {
+		t.Fatal("first chunk should not carry the payload")
+	}
+	if got := interactiveChunk(msg, "b", 1, 2); got.Interactive == nil || got.Content != "b" {
+		t.Fatalf("last chunk = %+v", got)
+	}
+}
@@ -79,3 +79,11 @@ type PlaceholderRecorder interface {
 type CommandRegistrarCapable interface {
 	RegisterCommands(ctx context.Context, defs []commands.Definition) error
 }
+
+// InteractiveCapable — channels that can render bus.InteractivePayload natively
+// (inline keyboards, message components, Block Kit, cards, ...). Button presses
+// must come back through BaseChannel.HandleInteraction. Channels without this
+// capability receive the payload's numbered plain-text fallback instead.
+type InteractiveCapable interface {
+	SendInteractive(ctx context.Context, msg bus.OutboundMessage) ([]string, error)
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/channels/mdrender"
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/logger"
 )
@@ -147,7 +148,7 @@ func (c *IRCChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]strin
 	}
 
 	// Send each line separately (IRC is line-oriented)
-	lines := strings.Split(msg.Content, "\n")
+	lines := strings.Split(mdrender.Render(msg.Content, mdrender.IRC), "\n")
 	for _, line := range lines {
 		line = strings.TrimRight(line, "\r")
 		if line == "" {
@@ -14,6 +14,7 @@ import (
 
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/channels/mdrender"
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/identity"
 	"github.com/sipeed/picoclaw/pkg/logger"
@@ -470,7 +471,7 @@ func (c *LINEChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]stri
 	}
 
 	textMsg := messaging_api.TextMessage{
-		Text:       msg.Content,
+		Text:       mdrender.Render(msg.Content, mdrender.LINE),
 		QuoteToken: quoteToken,
 	}
 
@@ -595,7 +596,7 @@ func classifySDKError(resp *http.Response, err error) error {
 		return nil
 	}
 	if resp != nil {
-		return channels.ClassifySendError(resp.StatusCode, err)
+		return channels.ClassifySendErrorWithRetryAfter(resp.StatusCode, resp.Header.Get("Retry-After"), err)
 	}
 	return channels.ClassifyNetError(err)
 }
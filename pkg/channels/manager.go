@@ -22,6 +22,7 @@ import (
 	"golang.org/x/time/rate"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels/outbox"
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/constants"
 	runtimeevents "github.com/sipeed/picoclaw/pkg/events"
@@ -102,6 +103,7 @@ type Manager struct {
 	streamActive              sync.Map          // streamSuppressionKey → true (set when streamer.Finalize sent the message)
 	streamAuxiliaryTombstones sync.Map          // streamSuppressionKey → time.Time (drops late auxiliary messages after stream final)
 	channelHashes             map[string]string // channel name → config hash
+	outbox                    *outbox.Store     // durable queue for deliveries that exhausted their retries; may be nil
 }
 
 type mediaStoreSetter interface {
@@ -118,6 +120,14 @@ func WithRuntimeEvents(eventBus runtimeevents.Bus) ManagerOption {
 	}
 }
 
+// WithOutbox enables the durable outbound queue. Text messages that still fail
+// after the in-process retries are persisted to store and redelivered later.
+func WithOutbox(store *outbox.Store) ManagerOption {
+	return func(m *Manager) {
+		m.outbox = store
+	}
+}
+
 // ChannelLifecyclePayload describes channel lifecycle runtime events.
 type ChannelLifecyclePayload struct {
 	Type  string `json:"type,omitempty"`
@@ -192,6 +202,10 @@ func outboundMessageIsFinal(msg bus.OutboundMessage) bool {
 }
 
 func outboundMessageBypassesPlaceholderEdit(msg bus.OutboundMessage) bool {
+	// Placeholder edits carry text only and would drop interactive elements.
+	if msg.Interactive != nil {
+		return true
+	}
 	if len(msg.Context.Raw) == 0 {
 		return false
 	}
@@ -1007,6 +1021,12 @@ func (m *Manager) initChannel(typeName, channelName string) {
 		if setter, ok := ch.(interface{ SetOwner(ch Channel) }); ok {
 			setter.SetOwner(ch)
 		}
+		// Inject the inbound rate/flood limiter when the channel enables it
+		if bc := m.config.Channels.Get(channelName); bc != nil && bc.InboundLimit.Enabled {
+			if setter, ok := ch.(interface{ SetInboundLimiter(l *InboundLimiter) }); ok {
+				setter.SetInboundLimiter(m.newInboundLimiter(channelName, bc.InboundLimit))
+			}
+		}
 		m.channels[channelName] = ch
 		m.publishChannelEvent(
 			runtimeevents.KindChannelLifecycleInitialized,
@@ -1150,6 +1170,18 @@ func (m *Manager) SetupHTTPServerListeners(listeners []net.Listener, addr string
 	m.httpListeners = append([]net.Listener(nil), listeners...)
 }
 
+// HandleHTTP mounts handler on the shared HTTP server for services that are
+// not channels. It must be called after SetupHTTPServerListeners. Handlers
+// that stream longer than the server's write timeout extend their own
+// deadline with http.ResponseController.
+func (m *Manager) HandleHTTP(pattern string, handler http.Handler) error {
+	if m.mux == nil {
+		return errors.New("http server is not set up")
+	}
+	m.mux.Handle(pattern, handler)
+	return nil
+}
+
 // registerHTTPHandlersLocked registers webhook and health-check handlers for
 // all channels currently in m.channels. Caller must hold m.mu (or ensure
 // exclusive access).
@@ -1303,6 +1335,11 @@ func (m *Manager) StartAll(ctx context.Context) error {
 	// Start the TTL janitor that cleans up stale typing/placeholder entries
 	go m.runTTLJanitor(dispatchCtx)
 
+	// Start redelivering persisted outbound messages
+	if m.outbox != nil {
+		go m.runOutbox(dispatchCtx)
+	}
+
 	// Start shared HTTP server if configured
 	if m.httpServer != nil {
 		if len(m.httpListeners) > 0 {
@@ -1466,6 +1503,7 @@ func (m *Manager) runWorker(ctx context.Context, name string, w *channelWorker)
 			if !ok {
 				return
 			}
+			msg = prepareInteractiveOutbound(w.ch, msg)
 			maxLen := 0
 			if mlp, ok := w.ch.(MessageLengthProvider); ok {
 				maxLen = mlp.MaxMessageLength()
@@ -1496,10 +1534,8 @@ func (m *Manager) runWorker(ctx context.Context, name string, w *channelWorker)
 			}
 
 			// Step 3: Send all chunks
-			for _, chunk := range chunks {
-				chunkMsg := msg
-				chunkMsg.Content = chunk
-				m.sendWithRetry(ctx, name, w, chunkMsg)
+			for i, chunk := range chunks {
+				m.sendWithRetry(ctx, name, w, interactiveChunk(msg, chunk, i, len(chunks)))
 			}
 		case <-ctx.Done():
 			return
@@ -1577,7 +1613,11 @@ func (m *Manager) sendWithRetry(
 	var lastErr error
 	var msgIDs []string
 	for attempt := 0; attempt <= maxRetries; attempt++ {
-		msgIDs, lastErr = w.ch.Send(ctx, msg)
+		if ic, ok := w.ch.(InteractiveCapable); ok && msg.Interactive != nil {
+			msgIDs, lastErr = ic.SendInteractive(ctx, msg)
+		} else {
+			msgIDs, lastErr = w.ch.Send(ctx, msg)
+		}
 		if lastErr == nil {
 			m.publishOutboundSent(name, msg, msgIDs)
 			return msgIDs, true
@@ -1593,10 +1633,18 @@ func (m *Manager) sendWithRetry(
 			break
 		}
 
-		// Rate limit error — fixed delay
+		// Rate limit error — honor the platform delay, or a fixed delay.
+		// Delays longer than maxBackoff are left to the outbox.
 		if errors.Is(lastErr, ErrRateLimit) {
+			delay := rateLimitDelay
+			if retryAfter := RetryAfter(lastErr); retryAfter > 0 {
+				if retryAfter > maxBackoff && m.outbox != nil {
+					break
+				}
+				delay = retryAfter
+			}
 			select {
-			case <-time.After(rateLimitDelay):
+			case <-time.After(delay):
 				continue
 			case <-ctx.Done():
 				return nil, false
@@ -1620,6 +1668,7 @@ func (m *Manager) sendWithRetry(
 		"retries": maxRetries,
 	})
 	m.publishOutboundFailed(name, msg, lastErr, false)
+	m.deferToOutbox(name, msg, lastErr)
 
 	return nil, false
 }
@@ -1648,8 +1697,8 @@ func dispatchLoop[M any](
 
 			channel := getChannel(msg)
 
-			// Silently skip internal channels
-			if constants.IsInternalChannel(channel) {
+			// Silently skip internal and service channels
+			if channel != "" && !constants.IsDeliverableChannel(channel) {
 				continue
 			}
 
@@ -2054,15 +2103,14 @@ func (m *Manager) SendMessage(ctx context.Context, msg bus.OutboundMessage) erro
 		return fmt.Errorf("channel %s has no active worker", channelName)
 	}
 
+	msg = prepareInteractiveOutbound(w.ch, msg)
 	maxLen := 0
 	if mlp, ok := w.ch.(MessageLengthProvider); ok {
 		maxLen = mlp.MaxMessageLength()
 	}
 	if chunks := splitOutboundMessageContent(msg, maxLen); len(chunks) > 1 {
-		for _, chunk := range chunks {
-			chunkMsg := msg
-			chunkMsg.Content = chunk
-			m.sendWithRetry(ctx, channelName, w, chunkMsg)
+		for i, chunk := range chunks {
+			m.sendWithRetry(ctx, channelName, w, interactiveChunk(msg, chunk, i, len(chunks)))
 		}
 	} else {
 		if len(chunks) == 1 {
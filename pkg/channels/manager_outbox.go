@@ -0,0 +1,189 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels/outbox"
+	runtimeevents "github.com/sipeed/picoclaw/pkg/events"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// outboxPollInterval is how often the Manager looks for due outbox entries.
+// Replays requested by other processes (e.g. the launcher API) are picked up
+// on the next poll.
+const outboxPollInterval = 5 * time.Second
+
+// ChannelOutboxPayload describes outbox runtime events.
+type ChannelOutboxPayload struct {
+	EntryID  string `json:"entry_id"`
+	Attempts int    `json:"attempts,omitempty"`
+	Dead     bool   `json:"dead,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// outboundMessageDurable reports whether msg is worth persisting. Tool
+// feedback, tool call summaries and other auxiliary messages are only
+// meaningful while the turn is running.
+func outboundMessageDurable(msg bus.OutboundMessage) bool {
+	return !outboundMessageHasAuxiliaryKind(msg)
+}
+
+// deferToOutbox persists a message whose in-process retries failed. Permanent
+// failures go straight to the dead-letter directory.
+func (m *Manager) deferToOutbox(name string, msg bus.OutboundMessage, cause error) {
+	if m.outbox == nil || !outboundMessageDurable(msg) {
+		return
+	}
+
+	var (
+		entry outbox.Entry
+		err   error
+		dead  = errors.Is(cause, ErrSendFailed)
+	)
+	if dead {
+		entry, err = m.outbox.DeadLetter(name, msg, cause)
+	} else {
+		entry, err = m.outbox.Enqueue(name, msg, cause, RetryAfter(cause))
+	}
+	if err != nil {
+		logger.ErrorCF("channels", "Failed to persist outbound message", map[string]any{
+			"channel": name,
+			"chat_id": outboundMessageChatID(msg),
+			"error":   err.Error(),
+		})
+		return
+	}
+	logger.InfoCF("channels", "Outbound message deferred to outbox", map[string]any{
+		"channel":  name,
+		"chat_id":  outboundMessageChatID(msg),
+		"entry_id": entry.ID,
+		"dead":     dead,
+	})
+	m.publishOutboxEvent(name, msg, entry, dead, cause)
+}
+
+// runOutbox periodically redelivers due outbox entries.
+func (m *Manager) runOutbox(ctx context.Context) {
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.drainOutbox(ctx, now)
+		}
+	}
+}
+
+// drainOutbox makes one delivery attempt for every due entry whose channel has
+// a running worker. Entries for channels that are down keep waiting without
+// consuming attempts.
+func (m *Manager) drainOutbox(ctx context.Context, now time.Time) {
+	entries, err := m.outbox.Due(now)
+	if err != nil {
+		logger.WarnCF("channels", "Failed to read outbox", map[string]any{
+			"error": err.Error(),
+		})
+		return
+	}
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return
+		}
+		m.mu.RLock()
+		w, ok := m.workers[entry.Channel]
+		m.mu.RUnlock()
+		if !ok || w == nil || !w.ch.IsRunning() {
+			continue
+		}
+		m.redeliver(ctx, w, entry)
+	}
+}
+
+// redeliver makes a single delivery attempt for entry. The outbox itself
+// provides the backoff between attempts.
+func (m *Manager) redeliver(ctx context.Context, w *channelWorker, entry outbox.Entry) {
+	if err := w.limiter.Wait(ctx); err != nil {
+		return
+	}
+
+	msg := entry.Message
+	var (
+		msgIDs []string
+		err    error
+	)
+	if ic, ok := w.ch.(InteractiveCapable); ok && msg.Interactive != nil {
+		msgIDs, err = ic.SendInteractive(ctx, msg)
+	} else {
+		msgIDs, err = w.ch.Send(ctx, msg)
+	}
+	if err == nil {
+		if rmErr := m.outbox.Delivered(entry.ID); rmErr != nil {
+			logger.WarnCF("channels", "Failed to remove delivered outbox entry", map[string]any{
+				"entry_id": entry.ID,
+				"error":    rmErr.Error(),
+			})
+		}
+		logger.InfoCF("channels", "Outbox entry delivered", map[string]any{
+			"channel":  entry.Channel,
+			"chat_id":  entry.ChatID,
+			"entry_id": entry.ID,
+			"attempts": entry.Attempts + 1,
+		})
+		m.publishOutboundSent(entry.Channel, msg, msgIDs)
+		return
+	}
+
+	dead, failErr := m.outbox.Failed(entry, err, RetryAfter(err), errors.Is(err, ErrSendFailed))
+	if failErr != nil {
+		logger.ErrorCF("channels", "Failed to update outbox entry", map[string]any{
+			"entry_id": entry.ID,
+			"error":    failErr.Error(),
+		})
+		return
+	}
+	entry.Attempts++
+	logger.WarnCF("channels", "Outbox redelivery failed", map[string]any{
+		"channel":  entry.Channel,
+		"chat_id":  entry.ChatID,
+		"entry_id": entry.ID,
+		"attempts": entry.Attempts,
+		"dead":     dead,
+		"error":    err.Error(),
+	})
+	m.publishOutboxEvent(entry.Channel, msg, entry, dead, err)
+}
+
+func (m *Manager) publishOutboxEvent(
+	name string,
+	msg bus.OutboundMessage,
+	entry outbox.Entry,
+	dead bool,
+	cause error,
+) {
+	severity := runtimeevents.SeverityWarn
+	if dead {
+		severity = runtimeevents.SeverityError
+	}
+	payload := ChannelOutboxPayload{EntryID: entry.ID, Attempts: entry.Attempts, Dead: dead}
+	if cause != nil {
+		payload.Error = cause.Error()
+	}
+	m.publishChannelEvent(
+		runtimeevents.KindChannelMessageOutboundDeferred,
+		name,
+		scopeFromOutboundContext(msg.Context),
+		severity,
+		payload,
+	)
+}
+
+// Outbox returns the durable outbound queue, or nil when it is disabled.
+func (m *Manager) Outbox() *outbox.Store {
+	return m.outbox
+}
@@ -0,0 +1,123 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels/outbox"
+)
+
+func TestSendWithRetry_ExhaustedRetriesDeferToOutbox(t *testing.T) {
+	m := newTestManager()
+	m.outbox = outbox.NewStore(t.TempDir())
+	ch := &mockChannel{
+		sendFn: func(_ context.Context, _ bus.OutboundMessage) error {
+			return fmt.Errorf("timeout: %w", ErrTemporary)
+		},
+	}
+	w := &channelWorker{ch: ch, limiter: rate.NewLimiter(rate.Inf, 1)}
+	msg := testOutboundMessage(bus.OutboundMessage{Channel: "test", ChatID: "1", Content: "hello"})
+
+	m.sendWithRetry(context.Background(), "test", w, msg)
+
+	pending, err := m.outbox.Pending("test")
+	if err != nil {
+		t.Fatalf("Pending() error = %v", err)
+	}
+	if len(pending) != 1 || pending[0].Message.Content != "hello" {
+		t.Fatalf("pending = %+v, want the failed message", pending)
+	}
+}
+
+func TestSendWithRetry_PermanentFailureDeadLetters(t *testing.T) {
+	m := newTestManager()
+	m.outbox = outbox.NewStore(t.TempDir())
+	ch := &mockChannel{
+		sendFn: func(_ context.Context, _ bus.OutboundMessage) error {
+			return fmt.Errorf("bad chat ID: %w", ErrSendFailed)
+		},
+	}
+	w := &channelWorker{ch: ch, limiter: rate.NewLimiter(rate.Inf, 1)}
+	msg := testOutboundMessage(bus.OutboundMessage{Channel: "test", ChatID: "1", Content: "hello"})
+
+	m.sendWithRetry(context.Background(), "test", w, msg)
+
+	summary, err := m.outbox.Summarize("test")
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if summary.Pending != 0 || summary.Dead != 1 {
+		t.Fatalf("summary = %+v, want one dead entry", summary)
+	}
+}
+
+func TestSendWithRetry_AuxiliaryMessagesNotPersisted(t *testing.T) {
+	m := newTestManager()
+	m.outbox = outbox.NewStore(t.TempDir())
+	ch := &mockChannel{
+		sendFn: func(_ context.Context, _ bus.OutboundMessage) error {
+			return fmt.Errorf("bad chat ID: %w", ErrSendFailed)
+		},
+	}
+	w := &channelWorker{ch: ch, limiter: rate.NewLimiter(rate.Inf, 1)}
+	msg := testOutboundMessage(bus.OutboundMessage{Channel: "test", ChatID: "1", Content: "🔧 exec"})
+	msg.Context.Raw = map[string]string{"message_kind": "tool_feedback"}
+
+	m.sendWithRetry(context.Background(), "test", w, msg)
+
+	summary, _ := m.outbox.Summarize("")
+	if summary.Pending != 0 || summary.Dead != 0 {
+		t.Fatalf("summary = %+v, auxiliary message should not be persisted", summary)
+	}
+}
+
+func TestDrainOutbox_RedeliversDueEntries(t *testing.T) {
+	m := newTestManager()
+	m.outbox = outbox.NewStore(t.TempDir())
+	ch := &mockChannel{}
+	ch.SetRunning(true)
+	m.workers["test"] = &channelWorker{ch: ch, limiter: rate.NewLimiter(rate.Inf, 1)}
+
+	msg := testOutboundMessage(bus.OutboundMessage{Channel: "test", ChatID: "1", Content: "later"})
+	if _, err := m.outbox.Enqueue("test", msg, errors.New("offline"), 0); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	m.drainOutbox(context.Background(), time.Now())
+	if len(ch.sentMessages) != 0 {
+		t.Fatalf("entry should wait for its backoff, got %d sends", len(ch.sentMessages))
+	}
+
+	m.drainOutbox(context.Background(), time.Now().Add(time.Hour))
+	if len(ch.sentMessages) != 1 || ch.sentMessages[0].Content != "later" {
+		t.Fatalf("sentMessages = %+v, want redelivered entry", ch.sentMessages)
+	}
+	if pending, _ := m.outbox.Pending(""); len(pending) != 0 {
+		t.Fatalf("delivered entry still pending: %+v", pending)
+	}
+}
+
+func TestDrainOutbox_SkipsStoppedChannels(t *testing.T) {
+	m := newTestManager()
+	m.outbox = outbox.NewStore(t.TempDir())
+	ch := &mockChannel{}
+	m.workers["test"] = &channelWorker{ch: ch, limiter: rate.NewLimiter(rate.Inf, 1)}
+
+	msg := testOutboundMessage(bus.OutboundMessage{Channel: "test", ChatID: "1", Content: "later"})
+	if _, err := m.outbox.Enqueue("test", msg, nil, 0); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	m.drainOutbox(context.Background(), time.Now().Add(time.Hour))
+
+	pending, _ := m.outbox.Pending("test")
+	if len(ch.sentMessages) != 0 || len(pending) != 1 || pending[0].Attempts != 0 {
+		t.Fatalf("stopped channel should not consume attempts: sends=%d pending=%+v", len(ch.sentMessages), pending)
+	}
+}
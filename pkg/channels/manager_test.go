@@ -3436,3 +3436,43 @@ func TestSplitMarkerStreamerForwardsTurnUsage(t *testing.T) {
 		t.Errorf("inner usage = (%d, %d), want (1234, 567)", inner.inputTokens, inner.outputTokens)
 	}
 }
+
+func TestInboundLimiterPublishesRateLimitedEvent(t *testing.T) {
+	eventBus := runtimeevents.NewBus()
+	defer func() {
+		if err := eventBus.Close(); err != nil {
+			t.Errorf("event bus close failed: %v", err)
+		}
+	}()
+
+	_, eventsCh, err := eventBus.Channel().OfKind(
+		runtimeevents.KindChannelRateLimited,
+	).SubscribeChan(t.Context(), runtimeevents.SubscribeOptions{Name: "channel-inbound-limit", Buffer: 1})
+	if err != nil {
+		t.Fatalf("SubscribeChan failed: %v", err)
+	}
+
+	m := newTestManager()
+	m.runtimeEvents = eventBus
+
+	limiter := m.newInboundLimiter("test", config.InboundLimitConfig{
+		Enabled:         true,
+		SenderPerMinute: 1,
+		SenderBurst:     1,
+	})
+	inbound := bus.InboundContext{Channel: "test", ChatID: "chat-1", SenderID: "user-1"}
+	limiter.Check(inbound, "one")
+	limiter.Check(inbound, "two")
+
+	evt := receiveChannelRuntimeEvent(t, eventsCh)
+	if evt.Scope.ChatID != "chat-1" || evt.Scope.SenderID != "user-1" {
+		t.Fatalf("event scope = %+v", evt.Scope)
+	}
+	if evt.Severity != runtimeevents.SeverityWarn {
+		t.Fatalf("severity = %q, want warn", evt.Severity)
+	}
+	if evt.Attrs["inbound"] != true || evt.Attrs["reason"] != string(InboundLimitSenderRate) ||
+		evt.Attrs["notified"] != true {
+		t.Fatalf("attrs = %#v", evt.Attrs)
+	}
+}
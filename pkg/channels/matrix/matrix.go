@@ -15,9 +15,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/gomarkdown/markdown"
-	mdhtml "github.com/gomarkdown/markdown/html"
-	"github.com/gomarkdown/markdown/parser"
 	"go.mau.fi/util/dbutil"
 	"maunium.net/go/mautrix"
 	"maunium.net/go/mautrix/crypto/cryptohelper"
@@ -27,6 +24,7 @@ import (
 
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/channels/mdrender"
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/identity"
 	"github.com/sipeed/picoclaw/pkg/logger"
@@ -390,10 +388,7 @@ func (c *MatrixChannel) initCrypto(ctx context.Context) error {
 }
 
 func markdownToHTML(md string) string {
-	extensions := (parser.CommonExtensions | parser.NoEmptyLineBeforeBlock) &^ parser.DefinitionLists
-	p := parser.NewWithExtensions(extensions)
-	renderer := mdhtml.NewRenderer(mdhtml.RendererOptions{Flags: mdhtml.UseXHTML})
-	return strings.TrimSpace(string(markdown.ToHTML([]byte(md), p, renderer)))
+	return mdrender.Render(md, mdrender.MatrixHTML)
 }
 
 func (c *MatrixChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]string, error) {
@@ -0,0 +1,148 @@
+package mdrender
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+// BlockKind classifies a top-level markdown block.
+type BlockKind int
+
+const (
+	BlockOther BlockKind = iota
+	BlockParagraph
+	BlockHeading
+	BlockCode
+	BlockTable
+	BlockList
+	BlockQuote
+	BlockRule
+	BlockHTML
+)
+
+// Block is a top-level block of a markdown document.
+type Block struct {
+	Kind BlockKind
+	// Source is the block's markdown source without trailing blank lines.
+	Source string
+	// Separator is the whitespace between the previous block and this one.
+	Separator string
+
+	nodes []ast.Node
+}
+
+// topLevelProbe is how many leading bytes are compared to decide whether the
+// parser is looking at the document itself or at a nested buffer.
+const topLevelProbe = 64
+
+type blockMark struct {
+	offset   int
+	children int
+}
+
+// Blocks splits markdown text into its top-level AST blocks. gomarkdown does
+// not record source positions, so the parser hook notes where each top-level
+// parse step starts and how many blocks the document had at that point; a
+// block begins at the step during which it was added.
+func Blocks(text string) []Block {
+	data := parser.NormalizeNewlines([]byte(text))
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+
+	p := newParser()
+	var marks []blockMark
+	p.Opts.ParserHook = func(rest []byte) (ast.Node, []byte, int) {
+		if len(rest) == 0 || len(rest) > len(data) {
+			return nil, nil, 0
+		}
+		offset := len(data) - len(rest)
+		if len(marks) > 0 && offset < marks[len(marks)-1].offset {
+			return nil, nil, 0
+		}
+		probe := min(len(rest), topLevelProbe)
+		if !bytes.Equal(rest[:probe], data[offset:offset+probe]) {
+			return nil, nil, 0
+		}
+		marks = append(marks, blockMark{offset: offset, children: len(p.Doc.GetChildren())})
+		return nil, nil, 0
+	}
+	doc := p.Parse(data)
+	children := doc.GetChildren()
+	if len(children) == 0 {
+		return nil
+	}
+
+	starts := make([]int, len(children))
+	assigned := 0
+	for i, mark := range marks {
+		for ; assigned < mark.children && assigned < len(children); assigned++ {
+			if i > 0 {
+				starts[assigned] = marks[i-1].offset
+			}
+		}
+	}
+	last := 0
+	if len(marks) > 0 {
+		last = marks[len(marks)-1].offset
+	}
+	for ; assigned < len(children); assigned++ {
+		starts[assigned] = last
+	}
+
+	src := string(data)
+	var blocks []Block
+	prevEnd := 0
+	for i := 0; i < len(children); {
+		j := i + 1
+		for j < len(children) && starts[j] <= starts[i] {
+			j++
+		}
+		end := len(src)
+		if j < len(children) {
+			end = starts[j]
+		}
+		start := max(starts[i], prevEnd)
+		source := strings.TrimRight(src[start:end], " \t\n")
+		if source != "" {
+			block := Block{
+				Kind:   blockKind(children[i]),
+				Source: source,
+				nodes:  children[i:j],
+			}
+			if len(blocks) > 0 {
+				block.Separator = src[prevEnd:start]
+			}
+			blocks = append(blocks, block)
+			prevEnd = start + len(source)
+		}
+		i = j
+	}
+	return blocks
+}
+
+func blockKind(node ast.Node) BlockKind {
+	switch node.(type) {
+	case *ast.Paragraph:
+		return BlockParagraph
+	case *ast.Heading:
+		return BlockHeading
+	case *ast.CodeBlock:
+		return BlockCode
+	case *ast.Table:
+		return BlockTable
+	case *ast.List:
+		return BlockList
+	case *ast.BlockQuote:
+		return BlockQuote
+	case *ast.HorizontalRule:
+		return BlockRule
+	case *ast.HTMLBlock:
+		return BlockHTML
+	default:
+		return BlockOther
+	}
+}
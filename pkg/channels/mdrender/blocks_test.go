@@ -0,0 +1,58 @@
+package mdrender
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlocks(t *testing.T) {
+	in := "Intro line\nsecond line\n\n" +
+		"| A | B |\n|---|---|\n| 1 | 2 |\n\n\n" +
+		"> quoted\n> more\n\n" +
+		"1. one\n2. two\n   - nested\n" +
+		"```go\nx := 1\n```\n" +
+		"---\n\nend\n"
+
+	type want struct {
+		kind   BlockKind
+		source string
+		sep    string
+	}
+	expected := []want{
+		{BlockParagraph, "Intro line\nsecond line", ""},
+		{BlockTable, "| A | B |\n|---|---|\n| 1 | 2 |", "\n\n"},
+		{BlockQuote, "> quoted\n> more", "\n\n\n"},
+		{BlockList, "1. one\n2. two\n   - nested", "\n\n"},
+		{BlockCode, "```go\nx := 1\n```", "\n"},
+		{BlockRule, "---", "\n"},
+		{BlockParagraph, "end", "\n\n"},
+	}
+
+	blocks := Blocks(in)
+	got := make([]want, 0, len(blocks))
+	for _, b := range blocks {
+		got = append(got, want{b.Kind, b.Source, b.Separator})
+	}
+	assert.Equal(t, expected, got)
+}
+
+func TestBlocks_LongBlocks(t *testing.T) {
+	// Blocks longer than the top-level probe must still be found by offset.
+	long := ""
+	for i := 0; i < 40; i++ {
+		long += "word "
+	}
+	in := long + "\n\n```\n" + long + "\n```\n\n" + long
+
+	blocks := Blocks(in)
+	assert.Len(t, blocks, 3)
+	assert.Equal(t, BlockCode, blocks[1].Kind)
+	assert.Equal(t, "```\n"+long+"\n```", blocks[1].Source)
+	assert.Equal(t, long[:len(long)-1], blocks[2].Source)
+}
+
+func TestBlocks_Empty(t *testing.T) {
+	assert.Nil(t, Blocks(""))
+	assert.Nil(t, Blocks(" \n\n "))
+}
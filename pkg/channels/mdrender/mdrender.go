@@ -0,0 +1,147 @@
+// Package mdrender renders agent markdown for chat platforms.
+//
+// Messages are parsed once with gomarkdown and rendered for a Target, which
+// combines an output Format with the Capabilities of the platform. Blocks the
+// platform cannot display (tables on Discord, headings on Slack, ...) are
+// rewritten into something it can, while everything else keeps its meaning.
+// Blocks exposes the top-level AST blocks together with their source text so
+// message splitting can cut between blocks instead of counting runes.
+package mdrender
+
+import (
+	"strings"
+
+	"github.com/gomarkdown/markdown"
+	mdhtml "github.com/gomarkdown/markdown/html"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+// Format is the markup a Target emits.
+type Format int
+
+const (
+	// FormatMarkdown emits markdown. Supported blocks are kept verbatim.
+	FormatMarkdown Format = iota
+	// FormatSlack emits Slack mrkdwn.
+	FormatSlack
+	// FormatTelegramHTML emits the HTML subset accepted by Telegram's HTML
+	// parse mode.
+	FormatTelegramHTML
+	// FormatHTML emits full HTML, e.g. Matrix org.matrix.custom.html.
+	FormatHTML
+	// FormatPlain emits plain text without markup.
+	FormatPlain
+)
+
+// Capabilities describe which markdown constructs a platform renders natively.
+// They only matter for FormatMarkdown; the other formats define their own
+// mapping for every construct.
+type Capabilities struct {
+	// MaxHeadingLevel is the deepest heading rendered natively (0 = none).
+	// Deeper headings become bold lines.
+	MaxHeadingLevel int
+	// Tables reports native table support. Without it tables are rendered as
+	// aligned monospace blocks.
+	Tables bool
+	// Strikethrough reports native ~~strike~~ support.
+	Strikethrough bool
+}
+
+// Target is a rendering destination.
+type Target struct {
+	Name   string
+	Format Format
+	Caps   Capabilities
+	// MaxTableWidth is the widest table (in runes) that FormatSlack renders as
+	// text rows; wider tables become a code block. Zero uses the default.
+	MaxTableWidth int
+}
+
+const defaultMaxTableWidth = 60
+
+// Predefined targets for the built-in channels.
+var (
+	Markdown = Target{
+		Name:   "markdown",
+		Format: FormatMarkdown,
+		Caps:   Capabilities{MaxHeadingLevel: 6, Tables: true, Strikethrough: true},
+	}
+	Discord = Target{
+		Name:   "discord",
+		Format: FormatMarkdown,
+		Caps:   Capabilities{MaxHeadingLevel: 3, Strikethrough: true},
+	}
+	Slack = Target{
+		Name:          "slack",
+		Format:        FormatSlack,
+		MaxTableWidth: defaultMaxTableWidth,
+	}
+	TelegramHTML = Target{Name: "telegram", Format: FormatTelegramHTML}
+	MatrixHTML   = Target{Name: "matrix", Format: FormatHTML}
+	LINE         = Target{Name: "line", Format: FormatPlain}
+	IRC          = Target{Name: "irc", Format: FormatPlain}
+)
+
+var targetsByChannel = map[string]Target{
+	"discord":       Discord,
+	"slack":         Slack,
+	"slack_webhook": Slack,
+	"telegram":      TelegramHTML,
+	"matrix":        MatrixHTML,
+	"line":          LINE,
+	"irc":           IRC,
+}
+
+// TargetFor returns the predefined target of a channel type.
+func TargetFor(channelType string) (Target, bool) {
+	t, ok := targetsByChannel[strings.ToLower(strings.TrimSpace(channelType))]
+	return t, ok
+}
+
+// extensions is the markdown dialect accepted from the agent. Definition
+// lists and math are left out: chat text such as "Term\n: value" or prices
+// like "$5 and $10" must stay literal.
+const extensions = (parser.CommonExtensions | parser.NoEmptyLineBeforeBlock) &^
+	(parser.DefinitionLists | parser.MathJax)
+
+func newParser() *parser.Parser {
+	return parser.NewWithExtensions(extensions)
+}
+
+// Render converts markdown text for target t.
+func Render(text string, t Target) string {
+	if strings.TrimSpace(text) == "" {
+		return ""
+	}
+	if t.Format == FormatHTML {
+		return renderHTML(text)
+	}
+
+	r := renderer{target: t}
+	var b strings.Builder
+	for i, block := range Blocks(text) {
+		if i > 0 {
+			b.WriteString(blockSeparator(block.Separator))
+		}
+		if t.Format == FormatMarkdown && r.keepsVerbatim(block) {
+			b.WriteString(block.Source)
+			continue
+		}
+		b.WriteString(r.blocks(block.nodes))
+	}
+	return b.String()
+}
+
+func renderHTML(text string) string {
+	renderer := mdhtml.NewRenderer(mdhtml.RendererOptions{Flags: mdhtml.UseXHTML})
+	return strings.TrimSpace(string(markdown.ToHTML([]byte(text), newParser(), renderer)))
+}
+
+// blockSeparator keeps a blank line between blocks when the source had one
+// and a single newline otherwise.
+func blockSeparator(sep string) string {
+	if strings.Count(sep, "\n") >= 2 {
+		return "\n\n"
+	}
+	return "\n"
+}
@@ -0,0 +1,130 @@
+package mdrender
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender_Slack(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"bold double asterisk", "This is **bold** text", "This is *bold* text"},
+		{"italic single asterisk", "This is *italic* text", "This is _italic_ text"},
+		{"italic underscore", "This is _italic_ text", "This is _italic_ text"},
+		{"strikethrough", "This is ~~struck~~ text", "This is ~struck~ text"},
+		{"inline code unchanged", "Use `code` here", "Use `code` here"},
+		{"link conversion", "Click [here](https://example.com) now", "Click <https://example.com|here> now"},
+		{"bare url", "See https://example.com", "See <https://example.com>"},
+		{"header to bold", "# Header One", "*Header One*"},
+		{"header level 2", "## Header Two", "*Header Two*"},
+		{"bullet list", "- item one\n- item two", "• item one\n• item two"},
+		{
+			"mixed formatting",
+			"**bold** and *italic* and [link](http://x.com)",
+			"*bold* and _italic_ and <http://x.com|link>",
+		},
+		{"code block unchanged", "```\ncode here\n```", "```\ncode here\n```"},
+		{"code block language dropped", "```go\nx := 1\n```", "```\nx := 1\n```"},
+		{"control characters escaped", "a < b & c", "a &lt; b &amp; c"},
+		{"quote", "> quoted\n> more", "> quoted\n> more"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Render(tt.input, Slack))
+		})
+	}
+}
+
+func TestRender_SlackTables(t *testing.T) {
+	narrow := Render("| Name | Status |\n|---|---|\n| foo | OK |", Slack)
+	assert.Equal(t, "*Name* | *Status*\nfoo | OK", narrow)
+
+	wide := Render(
+		"| This is a very long column header | Another extremely long column header here |\n"+
+			"|---|---|\n| Short | Longer value here |",
+		Slack,
+	)
+	assert.True(t, strings.HasPrefix(wide, "```\n"), wide)
+	var header, row string
+	for _, line := range strings.Split(wide, "\n") {
+		if strings.Contains(line, "This is a very long") {
+			header = line
+		}
+		if strings.Contains(line, "Short") {
+			row = line
+		}
+	}
+	assert.Equal(t, len(header), len(row), "columns should be aligned")
+}
+
+func TestRender_TelegramHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"heading", "## Title", "<b>Title</b>"},
+		{"strikethrough", "~~gone~~", "<s>gone</s>"},
+		{"inline code escaped", "`a<b`", "<code>a&lt;b</code>"},
+		{"quote", "> quoted\n> more", "<blockquote>quoted\nmore</blockquote>"},
+		{"ordered list", "1. one\n2. two", "1. one\n2. two"},
+		{"nested list", "- a\n  - b", "• a\n  • b"},
+		{"table", "| A | B |\n|---|---|\n| 1 | 2 |", "<pre>| A | B |\n|---|---|\n| 1 | 2 |</pre>"},
+		{"dollar amounts stay literal", "costs $5 and $10", "costs $5 and $10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Render(tt.input, TelegramHTML))
+		})
+	}
+}
+
+func TestRender_Discord(t *testing.T) {
+	// Supported blocks are kept as written.
+	in := "# Title\n\nSome **bold** text\n\n```go\nx := 1\n```"
+	assert.Equal(t, in, Render(in, Discord))
+
+	// Headings deeper than Discord supports become bold lines.
+	assert.Equal(t, "**Deep**", Render("#### Deep", Discord))
+
+	// Tables become monospace blocks.
+	assert.Equal(t,
+		"Scores:\n\n```\n| Name  | Score |\n|-------|-------|\n| alice | 10    |\n```",
+		Render("Scores:\n\n| Name | Score |\n|---|---|\n| alice | 10 |", Discord),
+	)
+}
+
+func TestRender_Plain(t *testing.T) {
+	in := "# Title\n\nSome **bold** and [a link](https://example.com).\n\n- one\n- two\n\n```\ncode\n```"
+	assert.Equal(t,
+		"Title\n\nSome bold and a link (https://example.com).\n\n• one\n• two\n\ncode",
+		Render(in, LINE),
+	)
+	assert.Equal(t, "https://example.com", Render("<https://example.com>", IRC))
+}
+
+func TestRender_MatrixHTML(t *testing.T) {
+	assert.Equal(t, "<p><strong>bold</strong></p>", Render("**bold**", MatrixHTML))
+}
+
+func TestRender_Empty(t *testing.T) {
+	for _, target := range []Target{Markdown, Slack, TelegramHTML, MatrixHTML, LINE} {
+		assert.Empty(t, Render("  \n", target), target.Name)
+	}
+}
+
+func TestTargetFor(t *testing.T) {
+	target, ok := TargetFor("Slack_Webhook")
+	assert.True(t, ok)
+	assert.Equal(t, "slack", target.Name)
+
+	_, ok = TargetFor("feishu")
+	assert.False(t, ok)
+}
@@ -0,0 +1,328 @@
+package mdrender
+
+import (
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+const ruleText = "──────────"
+
+type renderer struct {
+	target Target
+}
+
+// keepsVerbatim reports whether a FormatMarkdown target can display block as
+// written.
+func (r renderer) keepsVerbatim(block Block) bool {
+	for _, node := range block.nodes {
+		switch node := node.(type) {
+		case *ast.Table:
+			if !r.target.Caps.Tables {
+				return false
+			}
+		case *ast.Heading:
+			if node.Level > r.target.Caps.MaxHeadingLevel {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// blocks renders sibling block nodes separated by blank lines.
+func (r renderer) blocks(nodes []ast.Node) string {
+	parts := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		if part := r.block(node); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func (r renderer) block(node ast.Node) string {
+	switch node := node.(type) {
+	case *ast.Paragraph:
+		return strings.TrimSpace(r.inlines(node))
+	case *ast.Heading:
+		return r.heading(node)
+	case *ast.CodeBlock:
+		return r.codeBlock(node)
+	case *ast.Table:
+		return r.table(node)
+	case *ast.List:
+		return r.list(node, 0)
+	case *ast.BlockQuote:
+		return r.quote(node)
+	case *ast.HorizontalRule:
+		if r.target.Format == FormatMarkdown {
+			return "---"
+		}
+		return ruleText
+	case *ast.HTMLBlock:
+		return r.escape(strings.TrimSpace(string(node.Literal)))
+	default:
+		if leaf := node.AsLeaf(); leaf != nil {
+			return r.escape(string(leaf.Literal))
+		}
+		return r.blocks(node.GetChildren())
+	}
+}
+
+func (r renderer) heading(node *ast.Heading) string {
+	text := strings.TrimSpace(r.inlines(node))
+	switch r.target.Format {
+	case FormatMarkdown:
+		if node.Level <= r.target.Caps.MaxHeadingLevel {
+			return strings.Repeat("#", node.Level) + " " + text
+		}
+		return "**" + text + "**"
+	case FormatSlack:
+		return "*" + text + "*"
+	case FormatTelegramHTML:
+		return "<b>" + text + "</b>"
+	default:
+		return text
+	}
+}
+
+func (r renderer) codeBlock(node *ast.CodeBlock) string {
+	code := string(node.Literal)
+	switch r.target.Format {
+	case FormatTelegramHTML:
+		return "<pre><code>" + escapeHTML(code) + "</code></pre>"
+	case FormatPlain:
+		return strings.TrimRight(code, "\n")
+	case FormatSlack:
+		// Slack ignores language hints and shows them as code.
+		return "```\n" + strings.TrimRight(code, "\n") + "\n```"
+	default:
+		return "```" + string(node.Info) + "\n" + strings.TrimRight(code, "\n") + "\n```"
+	}
+}
+
+func (r renderer) quote(node *ast.BlockQuote) string {
+	inner := r.blocks(node.GetChildren())
+	if r.target.Format == FormatTelegramHTML {
+		return "<blockquote>" + inner + "</blockquote>"
+	}
+	lines := strings.Split(inner, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("> "+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r renderer) list(node *ast.List, depth int) string {
+	ordered := node.ListFlags&ast.ListTypeOrdered != 0
+	number := node.Start
+	if number <= 0 {
+		number = 1
+	}
+	indent := strings.Repeat("  ", depth)
+	var items []string
+	for _, child := range node.GetChildren() {
+		item, ok := child.(*ast.ListItem)
+		if !ok {
+			continue
+		}
+		marker := r.bullet()
+		if ordered {
+			marker = strconv.Itoa(number) + ". "
+			number++
+		}
+		items = append(items, r.listItem(item, indent, marker, depth))
+	}
+	sep := "\n"
+	if !node.Tight {
+		sep = "\n\n"
+	}
+	return strings.Join(items, sep)
+}
+
+func (r renderer) listItem(item *ast.ListItem, indent, marker string, depth int) string {
+	var parts []string
+	for _, child := range item.GetChildren() {
+		if nested, ok := child.(*ast.List); ok {
+			parts = append(parts, r.list(nested, depth+1))
+			continue
+		}
+		part := r.block(child)
+		if part == "" {
+			continue
+		}
+		// Continuation lines line up with the item text.
+		pad := indent + strings.Repeat(" ", len([]rune(marker)))
+		part = strings.ReplaceAll(part, "\n", "\n"+pad)
+		if len(parts) == 0 {
+			part = indent + marker + part
+		} else {
+			part = pad + part
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) == 0 {
+		return indent + strings.TrimRight(marker, " ")
+	}
+	return strings.Join(parts, "\n")
+}
+
+func (r renderer) bullet() string {
+	if r.target.Format == FormatMarkdown {
+		return "- "
+	}
+	return "• "
+}
+
+// inlines renders the inline children of node.
+func (r renderer) inlines(node ast.Node) string {
+	var b strings.Builder
+	for _, child := range node.GetChildren() {
+		b.WriteString(r.inline(child))
+	}
+	return b.String()
+}
+
+func (r renderer) inline(node ast.Node) string {
+	switch node := node.(type) {
+	case *ast.Text:
+		return r.escape(string(node.Literal))
+	case *ast.Softbreak, *ast.Hardbreak:
+		return "\n"
+	case *ast.Code:
+		return r.code(string(node.Literal))
+	case *ast.Strong:
+		return r.wrap(node, "**", "*", "<b>", "</b>")
+	case *ast.Emph:
+		return r.wrap(node, "*", "_", "<i>", "</i>")
+	case *ast.Del:
+		if r.target.Format == FormatMarkdown && !r.target.Caps.Strikethrough {
+			return r.inlines(node)
+		}
+		return r.wrap(node, "~~", "~", "<s>", "</s>")
+	case *ast.Link:
+		return r.link(string(node.Destination), r.inlines(node), plainText(node))
+	case *ast.Image:
+		return r.link(string(node.Destination), r.inlines(node), plainText(node))
+	case *ast.HTMLSpan:
+		return r.escape(string(node.Literal))
+	default:
+		if leaf := node.AsLeaf(); leaf != nil {
+			return r.escape(string(leaf.Literal))
+		}
+		return r.inlines(node)
+	}
+}
+
+func (r renderer) wrap(node ast.Node, md, slack, openTag, closeTag string) string {
+	inner := r.inlines(node)
+	if strings.TrimSpace(inner) == "" {
+		return inner
+	}
+	switch r.target.Format {
+	case FormatMarkdown:
+		return md + inner + md
+	case FormatSlack:
+		return slack + inner + slack
+	case FormatTelegramHTML:
+		return openTag + inner + closeTag
+	default:
+		return inner
+	}
+}
+
+func (r renderer) code(code string) string {
+	switch r.target.Format {
+	case FormatTelegramHTML:
+		return "<code>" + escapeHTML(code) + "</code>"
+	case FormatPlain:
+		return code
+	default:
+		return "`" + code + "`"
+	}
+}
+
+// link renders a link whose rendered label is label and whose unformatted
+// label is text.
+func (r renderer) link(dest, label, text string) string {
+	if dest == "" {
+		return label
+	}
+	switch r.target.Format {
+	case FormatMarkdown:
+		return "[" + label + "](" + dest + ")"
+	case FormatSlack:
+		if text == "" || text == dest {
+			return "<" + dest + ">"
+		}
+		return "<" + dest + "|" + label + ">"
+	case FormatTelegramHTML:
+		if label == "" {
+			label = escapeHTML(dest)
+		}
+		return `<a href="` + html.EscapeString(dest) + `">` + label + "</a>"
+	default:
+		if text == "" || text == dest || strings.TrimPrefix(dest, "mailto:") == text {
+			return dest
+		}
+		return label + " (" + dest + ")"
+	}
+}
+
+func (r renderer) escape(text string) string {
+	switch r.target.Format {
+	case FormatSlack, FormatTelegramHTML:
+		return escapeHTML(text)
+	case FormatMarkdown:
+		return markdownEscaper.Replace(text)
+	default:
+		return text
+	}
+}
+
+// markdownEscaper escapes literal text that is written back as markdown.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"*", `\*`,
+	"_", `\_`,
+	"`", "\\`",
+	"~", `\~`,
+	"[", `\[`,
+	"]", `\]`,
+)
+
+// escapeHTML escapes the characters Telegram and Slack treat as markup.
+// Quotes are left alone since text never ends up inside attributes.
+func escapeHTML(text string) string {
+	text = strings.ReplaceAll(text, "&", "&amp;")
+	text = strings.ReplaceAll(text, "<", "&lt;")
+	text = strings.ReplaceAll(text, ">", "&gt;")
+	return text
+}
+
+// plainText returns the text content of node without any markup.
+func plainText(node ast.Node) string {
+	var b strings.Builder
+	ast.WalkFunc(node, func(n ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch n := n.(type) {
+		case *ast.Text:
+			b.Write(n.Literal)
+		case *ast.Code:
+			b.Write(n.Literal)
+		case *ast.Softbreak, *ast.Hardbreak:
+			b.WriteByte('\n')
+		default:
+			if leaf := n.AsLeaf(); leaf != nil && n != node {
+				b.Write(leaf.Literal)
+			}
+		}
+		return ast.GoToNext
+	})
+	return b.String()
+}
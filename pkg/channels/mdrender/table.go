@@ -0,0 +1,138 @@
+package mdrender
+
+import (
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+type tableCell struct {
+	text     string // plain text, used for aligned layouts
+	rendered string // cell rendered for the target
+}
+
+func (r renderer) table(node *ast.Table) string {
+	rows := r.tableRows(node)
+	if len(rows) == 0 {
+		return ""
+	}
+	aligned := alignTable(rows)
+	switch r.target.Format {
+	case FormatSlack:
+		if tableWidth(rows) <= r.maxTableWidth() {
+			return slackTable(rows)
+		}
+		return "```\n" + aligned + "\n```"
+	case FormatTelegramHTML:
+		return "<pre>" + escapeHTML(aligned) + "</pre>"
+	case FormatPlain:
+		return aligned
+	default:
+		return "```\n" + aligned + "\n```"
+	}
+}
+
+func (r renderer) maxTableWidth() int {
+	if r.target.MaxTableWidth > 0 {
+		return r.target.MaxTableWidth
+	}
+	return defaultMaxTableWidth
+}
+
+// tableRows flattens header, body and footer rows; the header row comes
+// first.
+func (r renderer) tableRows(node *ast.Table) [][]tableCell {
+	var rows [][]tableCell
+	ast.WalkFunc(node, func(n ast.Node, entering bool) ast.WalkStatus {
+		row, ok := n.(*ast.TableRow)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+		var cells []tableCell
+		for _, child := range row.GetChildren() {
+			cells = append(cells, tableCell{
+				text:     strings.TrimSpace(plainText(child)),
+				rendered: strings.TrimSpace(r.inlines(child)),
+			})
+		}
+		if len(cells) > 0 {
+			rows = append(rows, cells)
+		}
+		return ast.SkipChildren
+	})
+	return rows
+}
+
+func columnWidths(rows [][]tableCell) []int {
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], len([]rune(cell.text)))
+		}
+	}
+	return widths
+}
+
+// tableWidth is the width of a row rendered as "a | b | c".
+func tableWidth(rows [][]tableCell) int {
+	widths := columnWidths(rows)
+	total := 3 * max(len(widths)-1, 0)
+	for _, w := range widths {
+		total += w
+	}
+	return total
+}
+
+// alignTable lays rows out as a padded pipe table with a rule under the
+// header.
+func alignTable(rows [][]tableCell) string {
+	widths := columnWidths(rows)
+	var b strings.Builder
+	for i, row := range rows {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString("|")
+		for j, w := range widths {
+			text := ""
+			if j < len(row) {
+				text = row[j].text
+			}
+			b.WriteString(" " + padRight(text, w) + " |")
+		}
+		if i == 0 {
+			b.WriteString("\n|")
+			for _, w := range widths {
+				b.WriteString(strings.Repeat("-", w+2) + "|")
+			}
+		}
+	}
+	return b.String()
+}
+
+// slackTable renders a narrow table as mrkdwn lines with a bold header.
+func slackTable(rows [][]tableCell) string {
+	lines := make([]string, 0, len(rows))
+	for i, row := range rows {
+		cells := make([]string, 0, len(row))
+		for _, cell := range row {
+			if i == 0 && cell.rendered != "" {
+				cells = append(cells, "*"+cell.rendered+"*")
+			} else {
+				cells = append(cells, cell.rendered)
+			}
+		}
+		lines = append(lines, strings.Join(cells, " | "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func padRight(s string, width int) string {
+	if n := len([]rune(s)); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
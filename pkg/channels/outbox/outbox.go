@@ -0,0 +1,388 @@
+// Package outbox persists outbound channel messages that could not be
+// delivered so they survive restarts and can be retried or inspected later.
+//
+// Entries live as one JSON file each under two directories:
+//
+//	<root>/pending/<id>.json  waiting for the next retry
+//	<root>/dead/<id>.json     gave up; kept for inspection and manual replay
+//
+// File-per-entry plus atomic writes keeps the store safe to share between the
+// gateway (which retries pending entries) and the launcher API (which lists and
+// replays dead letters) without any cross-process locking.
+package outbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+)
+
+const (
+	// DefaultMaxAttempts is the number of deferred delivery attempts before an
+	// entry is moved to the dead-letter directory.
+	DefaultMaxAttempts = 8
+	// DefaultMaxDead bounds the dead-letter directory; the oldest entries are
+	// pruned first.
+	DefaultMaxDead = 500
+
+	baseBackoff = 30 * time.Second
+	maxBackoff  = 30 * time.Minute
+
+	pendingDir = "pending"
+	deadDir    = "dead"
+)
+
+// ErrNotFound is returned when an entry does not exist.
+var ErrNotFound = errors.New("outbox entry not found")
+
+// Entry is one persisted outbound message.
+type Entry struct {
+	ID            string              `json:"id"`
+	Channel       string              `json:"channel"`
+	ChatID        string              `json:"chat_id"`
+	Message       bus.OutboundMessage `json:"message"`
+	Attempts      int                 `json:"attempts"`
+	LastError     string              `json:"last_error,omitempty"`
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+	NextAttemptAt time.Time           `json:"next_attempt_at,omitzero"`
+	DeadAt        time.Time           `json:"dead_at,omitzero"`
+}
+
+// Summary counts entries for one channel.
+type Summary struct {
+	Pending int `json:"pending"`
+	Dead    int `json:"dead"`
+}
+
+// Store is a file-backed outbox rooted at a directory.
+type Store struct {
+	root        string
+	maxAttempts int
+	maxDead     int
+	mu          sync.Mutex
+	now         func() time.Time
+}
+
+// NewStore creates a store rooted at dir. Directories are created lazily.
+func NewStore(dir string) *Store {
+	return &Store{
+		root:        dir,
+		maxAttempts: DefaultMaxAttempts,
+		maxDead:     DefaultMaxDead,
+		now:         time.Now,
+	}
+}
+
+// DefaultDir returns the outbox directory inside a workspace state dir.
+func DefaultDir(stateDir string) string {
+	return filepath.Join(stateDir, "outbox")
+}
+
+// Root returns the store directory.
+func (s *Store) Root() string {
+	return s.root
+}
+
+// MaxAttempts returns the number of deferred attempts before dead-lettering.
+func (s *Store) MaxAttempts() int {
+	return s.maxAttempts
+}
+
+// Backoff returns the delay before deferred attempt number attempts+1. A
+// platform supplied retryAfter wins when it is longer than the computed delay.
+func Backoff(attempts int, retryAfter time.Duration) time.Duration {
+	delay := maxBackoff
+	if attempts < 16 {
+		delay = min(time.Duration(float64(baseBackoff)*math.Pow(2, float64(attempts))), maxBackoff)
+	}
+	if retryAfter > delay {
+		return retryAfter
+	}
+	return delay
+}
+
+// Enqueue persists msg as a new pending entry scheduled after retryAfter (or
+// the first backoff step when retryAfter is zero).
+func (s *Store) Enqueue(channel string, msg bus.OutboundMessage, cause error, retryAfter time.Duration) (Entry, error) {
+	now := s.now()
+	entry := Entry{
+		ID:            newEntryID(now),
+		Channel:       channel,
+		ChatID:        msg.ChatID,
+		Message:       msg,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		NextAttemptAt: now.Add(Backoff(0, retryAfter)),
+	}
+	if cause != nil {
+		entry.LastError = cause.Error()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return entry, s.write(pendingDir, entry)
+}
+
+// DeadLetter persists msg directly into the dead-letter directory, used for
+// permanent failures that retrying cannot fix.
+func (s *Store) DeadLetter(channel string, msg bus.OutboundMessage, cause error) (Entry, error) {
+	now := s.now()
+	entry := Entry{
+		ID:        newEntryID(now),
+		Channel:   channel,
+		ChatID:    msg.ChatID,
+		Message:   msg,
+		CreatedAt: now,
+		UpdatedAt: now,
+		DeadAt:    now,
+	}
+	if cause != nil {
+		entry.LastError = cause.Error()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.write(deadDir, entry); err != nil {
+		return entry, err
+	}
+	s.pruneDeadLocked()
+	return entry, nil
+}
+
+// Due returns pending entries whose next attempt time has passed, oldest first.
+func (s *Store) Due(now time.Time) ([]Entry, error) {
+	entries, err := s.list(pendingDir, "")
+	if err != nil {
+		return nil, err
+	}
+	due := entries[:0]
+	for _, entry := range entries {
+		if !entry.NextAttemptAt.After(now) {
+			due = append(due, entry)
+		}
+	}
+	return due, nil
+}
+
+// Delivered removes a pending entry after a successful delivery.
+func (s *Store) Delivered(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := os.Remove(s.path(pendingDir, id))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Failed records a failed deferred attempt. The entry is rescheduled with
+// exponential backoff, or moved to the dead-letter directory when it has used
+// up its attempts or permanent is set. It reports whether the entry is dead.
+func (s *Store) Failed(entry Entry, cause error, retryAfter time.Duration, permanent bool) (bool, error) {
+	now := s.now()
+	entry.Attempts++
+	entry.UpdatedAt = now
+	if cause != nil {
+		entry.LastError = cause.Error()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if permanent || entry.Attempts >= s.maxAttempts {
+		entry.DeadAt = now
+		entry.NextAttemptAt = time.Time{}
+		if err := s.write(deadDir, entry); err != nil {
+			return false, err
+		}
+		if err := os.Remove(s.path(pendingDir, entry.ID)); err != nil && !os.IsNotExist(err) {
+			return true, err
+		}
+		s.pruneDeadLocked()
+		return true, nil
+	}
+	entry.NextAttemptAt = now.Add(Backoff(entry.Attempts, retryAfter))
+	return false, s.write(pendingDir, entry)
+}
+
+// Pending lists pending entries, optionally filtered by channel.
+func (s *Store) Pending(channel string) ([]Entry, error) {
+	return s.list(pendingDir, channel)
+}
+
+// Dead lists dead-letter entries, optionally filtered by channel, newest first.
+func (s *Store) Dead(channel string) ([]Entry, error) {
+	entries, err := s.list(deadDir, channel)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].DeadAt.After(entries[j].DeadAt)
+	})
+	return entries, nil
+}
+
+// Summarize counts pending and dead entries for channel ("" for all).
+func (s *Store) Summarize(channel string) (Summary, error) {
+	pending, err := s.list(pendingDir, channel)
+	if err != nil {
+		return Summary{}, err
+	}
+	dead, err := s.list(deadDir, channel)
+	if err != nil {
+		return Summary{}, err
+	}
+	return Summary{Pending: len(pending), Dead: len(dead)}, nil
+}
+
+// Replay moves a dead-letter entry back to pending with a fresh attempt budget
+// and schedules it immediately. A non-empty channel must match the entry.
+func (s *Store) Replay(channel, id string) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, err := s.read(deadDir, id)
+	if err != nil {
+		return Entry{}, err
+	}
+	if channel != "" && entry.Channel != channel {
+		return Entry{}, ErrNotFound
+	}
+	return entry, s.replayLocked(entry)
+}
+
+// ReplayChannel replays every dead-letter entry of channel ("" for all) and
+// returns how many entries were requeued.
+func (s *Store) ReplayChannel(channel string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, err := s.list(deadDir, channel)
+	if err != nil {
+		return 0, err
+	}
+	for i, entry := range entries {
+		if err := s.replayLocked(entry); err != nil {
+			return i, err
+		}
+	}
+	return len(entries), nil
+}
+
+// Discard deletes a dead-letter entry.
+func (s *Store) Discard(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := os.Remove(s.path(deadDir, id))
+	if os.IsNotExist(err) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *Store) replayLocked(entry Entry) error {
+	now := s.now()
+	entry.Attempts = 0
+	entry.DeadAt = time.Time{}
+	entry.NextAttemptAt = now
+	entry.UpdatedAt = now
+	if err := s.write(pendingDir, entry); err != nil {
+		return err
+	}
+	if err := os.Remove(s.path(deadDir, entry.ID)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *Store) pruneDeadLocked() {
+	if s.maxDead <= 0 {
+		return
+	}
+	entries, err := s.list(deadDir, "")
+	if err != nil || len(entries) <= s.maxDead {
+		return
+	}
+	// list returns oldest first by CreatedAt.
+	for _, entry := range entries[:len(entries)-s.maxDead] {
+		_ = os.Remove(s.path(deadDir, entry.ID))
+	}
+}
+
+func (s *Store) write(dir string, entry Entry) error {
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal outbox entry: %w", err)
+	}
+	return fileutil.WriteFileAtomic(s.path(dir, entry.ID), data, 0o600)
+}
+
+func (s *Store) read(dir, id string) (Entry, error) {
+	if !validEntryID(id) {
+		return Entry{}, ErrNotFound
+	}
+	data, err := os.ReadFile(s.path(dir, id))
+	if os.IsNotExist(err) {
+		return Entry{}, ErrNotFound
+	}
+	if err != nil {
+		return Entry{}, err
+	}
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return Entry{}, fmt.Errorf("decode outbox entry %s: %w", id, err)
+	}
+	return entry, nil
+}
+
+// list reads all entries of a directory, oldest first. Unreadable files are
+// skipped so a single corrupt entry cannot block the queue.
+func (s *Store) list(dir, channel string) ([]Entry, error) {
+	files, err := os.ReadDir(filepath.Join(s.root, dir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(files))
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		entry, err := s.read(dir, strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			continue
+		}
+		if channel != "" && entry.Channel != channel {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+	return entries, nil
+}
+
+func (s *Store) path(dir, id string) string {
+	return filepath.Join(s.root, dir, id+".json")
+}
+
+func newEntryID(now time.Time) string {
+	return now.UTC().Format("20060102T150405") + "-" + uuid.NewString()[:8]
+}
+
+// validEntryID rejects IDs that could escape the store directory.
+func validEntryID(id string) bool {
+	return id != "" && !strings.ContainsAny(id, `/\`) && id != "." && id != ".."
+}
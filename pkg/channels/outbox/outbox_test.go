@@ -0,0 +1,195 @@
+package outbox
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+func newTestStore(t *testing.T, now *time.Time) *Store {
+	t.Helper()
+	s := NewStore(t.TempDir())
+	s.now = func() time.Time { return *now }
+	return s
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts   int
+		retryAfter time.Duration
+		want       time.Duration
+	}{
+		{0, 0, 30 * time.Second},
+		{1, 0, time.Minute},
+		{3, 0, 4 * time.Minute},
+		{10, 0, 30 * time.Minute},
+		{100, 0, 30 * time.Minute},
+		{0, 2 * time.Minute, 2 * time.Minute},
+		{3, time.Second, 4 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := Backoff(tt.attempts, tt.retryAfter); got != tt.want {
+			t.Errorf("Backoff(%d, %v) = %v, want %v", tt.attempts, tt.retryAfter, got, tt.want)
+		}
+	}
+}
+
+func TestStore_EnqueueDueDelivered(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	s := newTestStore(t, &now)
+
+	entry, err := s.Enqueue("telegram", bus.OutboundMessage{ChatID: "42", Content: "hi"}, errors.New("offline"), 0)
+	if err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if entry.LastError != "offline" || entry.ChatID != "42" {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+
+	due, err := s.Due(now)
+	if err != nil || len(due) != 0 {
+		t.Fatalf("Due(now) = %v, %v; want nothing before backoff", due, err)
+	}
+	due, err = s.Due(now.Add(time.Minute))
+	if err != nil || len(due) != 1 || due[0].ID != entry.ID {
+		t.Fatalf("Due(+1m) = %v, %v; want the entry", due, err)
+	}
+
+	if err := s.Delivered(entry.ID); err != nil {
+		t.Fatalf("Delivered() error = %v", err)
+	}
+	if summary, _ := s.Summarize(""); summary != (Summary{}) {
+		t.Fatalf("summary after delivery = %+v, want empty", summary)
+	}
+}
+
+func TestStore_FailedReschedulesThenDeadLetters(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	s := newTestStore(t, &now)
+	s.maxAttempts = 2
+
+	entry, err := s.Enqueue("slack", bus.OutboundMessage{ChatID: "C1", Content: "hi"}, nil, 0)
+	if err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	dead, err := s.Failed(entry, errors.New("503"), 0, false)
+	if err != nil || dead {
+		t.Fatalf("first Failed() = %v, %v; want rescheduled", dead, err)
+	}
+	pending, _ := s.Pending("slack")
+	if len(pending) != 1 || pending[0].Attempts != 1 || !pending[0].NextAttemptAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("pending after failure = %+v", pending)
+	}
+
+	dead, err = s.Failed(pending[0], errors.New("503 again"), 0, false)
+	if err != nil || !dead {
+		t.Fatalf("second Failed() = %v, %v; want dead", dead, err)
+	}
+	summary, _ := s.Summarize("slack")
+	if summary.Pending != 0 || summary.Dead != 1 {
+		t.Fatalf("summary = %+v, want one dead entry", summary)
+	}
+	deadEntries, _ := s.Dead("slack")
+	if deadEntries[0].LastError != "503 again" || !deadEntries[0].DeadAt.Equal(now) {
+		t.Fatalf("dead entry = %+v", deadEntries[0])
+	}
+}
+
+func TestStore_ReplayChecksChannel(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	s := newTestStore(t, &now)
+
+	entry, err := s.DeadLetter("discord", bus.OutboundMessage{ChatID: "7", Content: "hi"}, errors.New("forbidden"))
+	if err != nil {
+		t.Fatalf("DeadLetter() error = %v", err)
+	}
+	if _, err := s.Replay("telegram", entry.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Replay(other channel) error = %v, want ErrNotFound", err)
+	}
+	if _, err := s.Replay("discord", "../escape"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Replay(bad id) error = %v, want ErrNotFound", err)
+	}
+
+	replayed, err := s.Replay("discord", entry.ID)
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if replayed.ID != entry.ID {
+		t.Fatalf("Replay() = %+v", replayed)
+	}
+	due, _ := s.Due(now)
+	if len(due) != 1 || due[0].Attempts != 0 || !due[0].DeadAt.IsZero() {
+		t.Fatalf("replayed entry should be due immediately with a fresh budget: %+v", due)
+	}
+}
+
+func TestStore_ReplayChannelAndDiscard(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	s := newTestStore(t, &now)
+
+	for _, channel := range []string{"a", "a", "b"} {
+		if _, err := s.DeadLetter(channel, bus.OutboundMessage{ChatID: "1"}, nil); err != nil {
+			t.Fatalf("DeadLetter() error = %v", err)
+		}
+	}
+	n, err := s.ReplayChannel("a")
+	if err != nil || n != 2 {
+		t.Fatalf("ReplayChannel(a) = %d, %v; want 2", n, err)
+	}
+	dead, _ := s.Dead("")
+	if len(dead) != 1 || dead[0].Channel != "b" {
+		t.Fatalf("dead after replay = %+v", dead)
+	}
+	if err := s.Discard(dead[0].ID); err != nil {
+		t.Fatalf("Discard() error = %v", err)
+	}
+	if err := s.Discard(dead[0].ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("second Discard() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestStore_PrunesOldestDeadLetters(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	s := newTestStore(t, &now)
+	s.maxDead = 2
+
+	var ids []string
+	for range 3 {
+		entry, err := s.DeadLetter("a", bus.OutboundMessage{ChatID: "1"}, nil)
+		if err != nil {
+			t.Fatalf("DeadLetter() error = %v", err)
+		}
+		ids = append(ids, entry.ID)
+		now = now.Add(time.Second)
+	}
+	dead, _ := s.Dead("a")
+	if len(dead) != 2 {
+		t.Fatalf("dead entries = %d, want 2", len(dead))
+	}
+	for _, entry := range dead {
+		if entry.ID == ids[0] {
+			t.Fatalf("oldest entry %s should have been pruned", ids[0])
+		}
+	}
+}
+
+func TestStore_SkipsCorruptEntries(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	s := newTestStore(t, &now)
+
+	if _, err := s.Enqueue("a", bus.OutboundMessage{ChatID: "1"}, nil, 0); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.Root(), pendingDir, "broken.json"), []byte("{"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	pending, err := s.Pending("")
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("Pending() = %v, %v; want the valid entry only", pending, err)
+	}
+}
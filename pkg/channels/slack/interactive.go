@@ -0,0 +1,185 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/socketmode"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/identity"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// slackActionIDLimit is the maximum length of a Block Kit action_id.
+const slackActionIDLimit = 255
+
+// SendInteractive implements channels.InteractiveCapable using Block Kit.
+func (c *SlackChannel) SendInteractive(ctx context.Context, msg bus.OutboundMessage) ([]string, error) {
+	if !c.IsRunning() {
+		return nil, channels.ErrNotRunning
+	}
+
+	_, channelID, threadTS := resolveSlackOutboundTarget(msg.ChatID, &msg.Context)
+	if channelID == "" {
+		return nil, fmt.Errorf("invalid slack chat ID: %s", msg.ChatID)
+	}
+
+	fallback := msg.Interactive.FallbackText(msg.Content)
+	opts := []slack.MsgOption{
+		// Text is shown in notifications and by clients that cannot render blocks.
+		slack.MsgOptionText(fallback, false),
+		slack.MsgOptionBlocks(buildSlackBlocks(msg.Content, msg.Interactive)...),
+	}
+	if msg.ReplyToMessageID != "" && threadTS == "" {
+		opts = append(opts, slack.MsgOptionTS(msg.ReplyToMessageID))
+	} else if threadTS != "" {
+		opts = append(opts, slack.MsgOptionTS(threadTS))
+	}
+
+	_, ts, err := c.api.PostMessageContext(ctx, channelID, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("slack send interactive: %w", channels.ErrTemporary)
+	}
+	c.RecordInteractiveMessage(ts, msg.Interactive)
+	return []string{ts}, nil
+}
+
+func buildSlackBlocks(content string, p *bus.InteractivePayload) []slack.Block {
+	var blocks []slack.Block
+	if p.Title != "" {
+		blocks = append(blocks, slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, p.Title, false, false)))
+	}
+	if content != "" {
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, content, false, false), nil, nil,
+		))
+	}
+
+	idx := 0
+	for i, row := range p.Rows {
+		elements := make([]slack.BlockElement, 0, len(row))
+		for _, btn := range row {
+			text := slack.NewTextBlockObject(slack.PlainTextType, btn.Label, false, false)
+			if btn.URL != "" {
+				elements = append(elements, slack.NewButtonBlockElement("", "", text).WithURL(btn.URL))
+				continue
+			}
+			el := slack.NewButtonBlockElement(
+				channels.InteractiveActionRef(btn.ID, idx, slackActionIDLimit), btn.Value, text,
+			)
+			idx++
+			switch btn.Style {
+			case bus.InteractiveStylePrimary:
+				el = el.WithStyle(slack.StylePrimary)
+			case bus.InteractiveStyleDanger:
+				el = el.WithStyle(slack.StyleDanger)
+			}
+			elements = append(elements, el)
+		}
+		blocks = append(blocks, slack.NewActionBlock(fmt.Sprintf("picoclaw_row_%d", i), elements...))
+	}
+
+	if p.Select != nil {
+		options := make([]*slack.OptionBlockObject, 0, len(p.Select.Options))
+		for _, opt := range p.Select.Options {
+			options = append(options, slack.NewOptionBlockObject(
+				channels.InteractiveActionRef(opt.ID, idx, slackActionIDLimit),
+				slack.NewTextBlockObject(slack.PlainTextType, opt.Label, false, false),
+				nil,
+			))
+			idx++
+		}
+		var placeholder *slack.TextBlockObject
+		if p.Select.Placeholder != "" {
+			placeholder = slack.NewTextBlockObject(slack.PlainTextType, p.Select.Placeholder, false, false)
+		}
+		menu := slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, placeholder, p.Select.ID, options...)
+		blocks = append(blocks, slack.NewActionBlock("picoclaw_select", menu))
+	}
+	return blocks
+}
+
+func (c *SlackChannel) handleInteractive(event socketmode.Event) {
+	if event.Request != nil {
+		c.socketClient.Ack(*event.Request)
+	}
+
+	callback, ok := event.Data.(slack.InteractionCallback)
+	if !ok || callback.Type != slack.InteractionTypeBlockActions {
+		return
+	}
+	if len(callback.ActionCallback.BlockActions) == 0 {
+		return
+	}
+	action := callback.ActionCallback.BlockActions[0]
+	actionRef := action.ActionID
+	if action.SelectedOption.Value != "" {
+		actionRef = action.SelectedOption.Value
+	}
+	if actionRef == "" {
+		// URL buttons report an empty action and need no handling.
+		return
+	}
+
+	userID := callback.User.ID
+	channelID := callback.Channel.ID
+	if channelID == "" {
+		channelID = callback.Container.ChannelID
+	}
+	messageTS := callback.Container.MessageTs
+	if messageTS == "" {
+		messageTS = callback.Message.Timestamp
+	}
+	threadTS := callback.Container.ThreadTs
+	if threadTS == "" {
+		threadTS = callback.Message.ThreadTimestamp
+	}
+
+	chatID := channelID
+	if threadTS != "" {
+		chatID = channelID + "/" + threadTS
+	}
+
+	sender := bus.SenderInfo{
+		Platform:    "slack",
+		PlatformID:  userID,
+		CanonicalID: identity.BuildCanonicalID("slack", userID),
+		Username:    callback.User.Name,
+	}
+	peerKind := "channel"
+	if len(channelID) > 0 && channelID[0] == 'D' {
+		peerKind = "direct"
+	}
+	inboundCtx := bus.InboundContext{
+		Channel:          c.Name(),
+		Account:          c.teamID,
+		ChatID:           channelID,
+		ChatType:         peerKind,
+		SenderID:         userID,
+		MessageID:        action.ActionTs,
+		ReplyToMessageID: messageTS,
+		SpaceID:          c.teamID,
+		SpaceType:        "workspace",
+		Raw: map[string]string{
+			"message_ts": messageTS,
+			"channel_id": channelID,
+			"thread_ts":  threadTS,
+			"platform":   "slack",
+			"team_id":    c.teamID,
+		},
+	}
+	if threadTS != "" {
+		inboundCtx.TopicID = threadTS
+	}
+
+	logger.DebugCF("slack", "Received block action", map[string]any{
+		"sender_id": userID,
+		"chat_id":   chatID,
+		"action_id": actionRef,
+	})
+
+	c.HandleInteraction(c.ctx, chatID, c.InteractionFor(messageTS, actionRef), inboundCtx, sender)
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/channels/mdrender"
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/logger"
 )
@@ -161,7 +162,9 @@ func (c *SlackWebhookChannel) Send(ctx context.Context, msg bus.OutboundMessage)
 			"response": respText,
 		})
 		sendErr := fmt.Errorf("status %d: %s", resp.StatusCode, respText)
-		return nil, fmt.Errorf("slack_webhook: %w", channels.ClassifySendError(resp.StatusCode, sendErr))
+		return nil, fmt.Errorf("slack_webhook: %w", channels.ClassifySendErrorWithRetryAfter(
+			resp.StatusCode, resp.Header.Get("Retry-After"), sendErr,
+		))
 	}
 
 	logger.DebugCF("slack_webhook", "Message sent successfully", map[string]any{
@@ -192,28 +195,34 @@ func (c *SlackWebhookChannel) buildPayload(msg bus.OutboundMessage, target confi
 	return payload
 }
 
+// buildBlocks renders content as mrkdwn sections. Tables get sections of their
+// own so a wide table rendered as a code block does not swallow the text
+// around it.
 func (c *SlackWebhookChannel) buildBlocks(content string) []map[string]any {
 	var blocks []map[string]any
+	addText := func(text string) {
+		if strings.TrimSpace(text) == "" {
+			return
+		}
+		for _, chunk := range splitText(text, maxTextBlockLength) {
+			blocks = append(blocks, c.textSection(chunk))
+		}
+	}
 
-	segments := splitContentWithTables(content)
-
-	for _, seg := range segments {
-		if seg.isTable {
-			tableText := renderTable(seg.content)
-			for _, chunk := range splitText(tableText, maxTextBlockLength) {
-				blocks = append(blocks, c.textSection(chunk))
-			}
-		} else {
-			text := strings.TrimSpace(seg.content)
-			if text == "" {
-				continue
-			}
-			converted := convertMarkdownToMrkdwn(text)
-			for _, chunk := range splitText(converted, maxTextBlockLength) {
-				blocks = append(blocks, c.textSection(chunk))
-			}
+	var text strings.Builder
+	for _, block := range mdrender.Blocks(content) {
+		if block.Kind == mdrender.BlockTable {
+			addText(mdrender.Render(text.String(), mdrender.Slack))
+			text.Reset()
+			addText(mdrender.Render(block.Source, mdrender.Slack))
+			continue
+		}
+		if text.Len() > 0 {
+			text.WriteString(block.Separator)
 		}
+		text.WriteString(block.Source)
 	}
+	addText(mdrender.Render(text.String(), mdrender.Slack))
 
 	if len(blocks) == 0 {
 		blocks = append(blocks, c.textSection("(empty message)"))
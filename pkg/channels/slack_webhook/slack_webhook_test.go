@@ -279,3 +279,16 @@ func TestSplitText_ShortText(t *testing.T) {
 	require.Len(t, chunks, 1)
 	assert.Equal(t, input, chunks[0])
 }
+
+func TestBuildBlocks_TablesGetOwnSections(t *testing.T) {
+	ch := &SlackWebhookChannel{}
+	content := "Before **now**\n\n| A | B |\n|---|---|\n| 1 | 2 |\n\nAfter"
+
+	blocks := ch.buildBlocks(content)
+
+	var texts []string
+	for _, block := range blocks {
+		texts = append(texts, block["text"].(map[string]any)["text"].(string))
+	}
+	assert.Equal(t, []string{"Before *now*", "*A* | *B*\n1 | 2", "After"}, texts)
+}
@@ -2,22 +2,234 @@ package channels
 
 import (
 	"strings"
+	"unicode/utf8"
+
+	"github.com/sipeed/picoclaw/pkg/channels/mdrender"
 )
 
-// SplitMessage splits long messages into chunks, preserving code block integrity.
-// The maxLen parameter is measured in runes (Unicode characters), not bytes.
-// The function reserves a buffer (10% of maxLen, min 50) to leave room for closing code blocks,
-// but may extend to maxLen when needed.
-// Call SplitMessage with the full text content and the maximum allowed length of a single message;
-// it returns a slice of message chunks that each respect maxLen and avoid splitting fenced code blocks.
+// SplitMessage splits long messages into chunks that each fit maxLen runes.
+// The content is parsed into top-level markdown blocks (paragraphs, lists,
+// tables, code blocks, ...) and chunks are cut between blocks whenever
+// possible. A block that alone exceeds maxLen is split on its own terms:
+// code blocks by lines with the fence closed and reopened, tables by rows with
+// the header repeated, and anything else by the rune-based splitter, which
+// prefers newlines and spaces.
 func SplitMessage(content string, maxLen int) []string {
-	if maxLen <= 0 {
+	if maxLen <= 0 || utf8.RuneCountInString(content) <= maxLen {
 		if content == "" {
 			return nil
 		}
 		return []string{content}
 	}
 
+	blocks := mdrender.Blocks(content)
+	if len(blocks) == 0 {
+		return splitRunes(content, maxLen)
+	}
+	s := blockSplitter{maxLen: maxLen}
+	for _, block := range blocks {
+		s.add(block)
+	}
+	return s.finish()
+}
+
+// blockSplitter packs markdown blocks into chunks of at most maxLen runes.
+type blockSplitter struct {
+	maxLen int
+	chunks []string
+	cur    string
+}
+
+func (s *blockSplitter) add(block mdrender.Block) {
+	sep := block.Separator
+	if s.cur == "" {
+		sep = ""
+	}
+	if s.fits(s.cur + sep + block.Source) {
+		s.cur += sep + block.Source
+		return
+	}
+	if s.fits(block.Source) {
+		s.flush()
+		s.cur = block.Source
+		return
+	}
+
+	switch block.Kind {
+	case mdrender.BlockCode:
+		s.addPieces(sep, splitCodeBlock(block.Source, s.room(sep), s.maxLen))
+	case mdrender.BlockTable:
+		s.addPieces(sep, splitTable(block.Source, s.room(sep), s.maxLen))
+	default:
+		pieces := splitRunes(s.cur+sep+block.Source, s.maxLen)
+		s.chunks = append(s.chunks, pieces[:len(pieces)-1]...)
+		s.cur = pieces[len(pieces)-1]
+	}
+}
+
+// room is how many runes the current chunk still has for a block that
+// follows sep.
+func (s *blockSplitter) room(sep string) int {
+	if s.cur == "" {
+		return s.maxLen
+	}
+	return s.maxLen - utf8.RuneCountInString(s.cur+sep)
+}
+
+// addPieces appends the pieces of an oversized block. The first piece may
+// share the current chunk; every following piece starts a new one.
+func (s *blockSplitter) addPieces(sep string, pieces []string) {
+	for i, piece := range pieces {
+		if i == 0 && s.fits(s.cur+sep+piece) {
+			if s.cur == "" {
+				sep = ""
+			}
+			s.cur += sep + piece
+			continue
+		}
+		s.flush()
+		s.cur = piece
+	}
+}
+
+func (s *blockSplitter) fits(text string) bool {
+	return utf8.RuneCountInString(text) <= s.maxLen
+}
+
+func (s *blockSplitter) flush() {
+	if chunk := strings.TrimRight(s.cur, " \t\n\r"); chunk != "" {
+		s.chunks = append(s.chunks, chunk)
+	}
+	s.cur = ""
+}
+
+func (s *blockSplitter) finish() []string {
+	s.flush()
+	return s.chunks
+}
+
+// splitCodeBlock splits a fenced code block by lines. Every piece is closed
+// with a fence and the next one reopens it with the original info string so
+// syntax highlighting survives. The first piece is limited to firstLen runes
+// when that leaves room for some code, later pieces to maxLen.
+func splitCodeBlock(source string, firstLen, maxLen int) []string {
+	lines := strings.Split(source, "\n")
+	opener := lines[0]
+	fence := opener[:len(opener)-len(strings.TrimLeft(opener, "`~"))]
+	if len(fence) < 3 {
+		// Indented code has no fence to reopen.
+		return splitRunes(source, maxLen)
+	}
+	body := lines[1:]
+	if len(body) > 0 && strings.TrimSpace(body[len(body)-1]) == fence {
+		body = body[:len(body)-1]
+	}
+	overhead := utf8.RuneCountInString(opener) + utf8.RuneCountInString(fence) + 2
+	if firstLen-overhead < minCodePieceRunes {
+		firstLen = maxLen
+	}
+	if maxLen-overhead < 1 {
+		return splitRunes(source, maxLen)
+	}
+
+	var pieces []string
+	var cur []string
+	curLen := 0
+	limit := firstLen - overhead
+	emit := func() {
+		pieces = append(pieces, opener+"\n"+strings.Join(cur, "\n")+"\n"+fence)
+		cur, curLen = nil, 0
+		limit = maxLen - overhead
+	}
+	for _, line := range body {
+		for _, part := range splitLine(line, maxLen-overhead) {
+			partLen := utf8.RuneCountInString(part)
+			if len(cur) > 0 && curLen+1+partLen > limit {
+				emit()
+			} else if len(cur) == 0 && partLen > limit {
+				// The shortened first piece cannot hold even this line; the
+				// piece then starts a new chunk.
+				limit = maxLen - overhead
+			}
+			if len(cur) > 0 {
+				curLen++
+			}
+			cur = append(cur, part)
+			curLen += partLen
+		}
+	}
+	if len(cur) > 0 {
+		emit()
+	}
+	return pieces
+}
+
+// minCodePieceRunes is the smallest amount of code worth starting next to the
+// preceding text instead of in a fresh message.
+const minCodePieceRunes = 20
+
+// splitTable splits a markdown table by rows, repeating the header and
+// delimiter rows in every piece.
+func splitTable(source string, firstLen, maxLen int) []string {
+	lines := strings.Split(source, "\n")
+	if len(lines) < 3 {
+		return splitRunes(source, maxLen)
+	}
+	header := lines[0] + "\n" + lines[1]
+	headerLen := utf8.RuneCountInString(header)
+	if headerLen+2 > maxLen {
+		return splitRunes(source, maxLen)
+	}
+
+	var pieces []string
+	cur := header
+	limit := firstLen
+	if limit < headerLen+2 {
+		limit = maxLen
+	}
+	rows := 0
+	for _, row := range lines[2:] {
+		if rows > 0 && utf8.RuneCountInString(cur)+1+utf8.RuneCountInString(row) > limit {
+			pieces = append(pieces, cur)
+			cur, rows, limit = header, 0, maxLen
+		}
+		if utf8.RuneCountInString(cur)+1+utf8.RuneCountInString(row) > limit {
+			// A single row wider than a message is cut like plain text.
+			for _, part := range splitRunes(row, limit-headerLen-1) {
+				pieces = append(pieces, header+"\n"+part)
+			}
+			cur, rows, limit = header, 0, maxLen
+			continue
+		}
+		cur += "\n" + row
+		rows++
+	}
+	if rows > 0 {
+		pieces = append(pieces, cur)
+	}
+	return pieces
+}
+
+// splitLine cuts a single line into parts of at most maxLen runes.
+func splitLine(line string, maxLen int) []string {
+	runes := []rune(line)
+	if len(runes) <= maxLen {
+		return []string{line}
+	}
+	var parts []string
+	for len(runes) > maxLen {
+		parts = append(parts, string(runes[:maxLen]))
+		runes = runes[maxLen:]
+	}
+	return append(parts, string(runes))
+}
+
+// splitRunes is the structure-unaware splitter used for blocks that cannot be
+// split on markdown boundaries.
+// The maxLen parameter is measured in runes (Unicode characters), not bytes.
+// The function reserves a buffer (10% of maxLen, min 50) to leave room for closing code blocks,
+// but may extend to maxLen when needed.
+func splitRunes(content string, maxLen int) []string {
 	runes := []rune(content)
 	totalLen := len(runes)
 	var messages []string
@@ -360,3 +360,55 @@ func TestSplitMessage_CodeBlockIntegrity(t *testing.T) {
 		t.Errorf("First chunk exceeded maxLen: length %d runes", len([]rune(chunks[0])))
 	}
 }
+
+func TestSplitMessage_PrefersBlockBoundaries(t *testing.T) {
+	para := func(c string) string { return strings.Repeat(c, 30) }
+	content := para("a") + "\n\n- " + para("b") + "\n- " + para("c") + "\n\n" + para("d")
+
+	chunks := SplitMessage(content, 80)
+
+	want := []string{
+		para("a"),
+		"- " + para("b") + "\n- " + para("c"),
+		para("d"),
+	}
+	if len(chunks) != len(want) {
+		t.Fatalf("Expected %d chunks, got %d: %q", len(want), len(chunks), chunks)
+	}
+	for i := range want {
+		if chunks[i] != want[i] {
+			t.Errorf("chunk %d = %q, want %q", i, chunks[i], want[i])
+		}
+	}
+}
+
+func TestSplitMessage_TableRepeatsHeader(t *testing.T) {
+	header := "| Name | Value |\n|------|-------|"
+	var rows []string
+	for i := 0; i < 12; i++ {
+		rows = append(rows, "| row  | "+strings.Repeat("x", 5)+" |")
+	}
+	content := "Results:\n\n" + header + "\n" + strings.Join(rows, "\n")
+
+	chunks := SplitMessage(content, 120)
+
+	if len(chunks) < 2 {
+		t.Fatalf("Expected table to be split, got %q", chunks)
+	}
+	total := 0
+	for i, chunk := range chunks {
+		if n := len([]rune(chunk)); n > 120 {
+			t.Errorf("chunk %d has %d runes", i, n)
+		}
+		if !strings.Contains(chunk, header) {
+			t.Errorf("chunk %d lacks the table header: %q", i, chunk)
+		}
+		total += strings.Count(chunk, "| row  |")
+	}
+	if total != len(rows) {
+		t.Errorf("Expected %d rows across chunks, got %d", len(rows), total)
+	}
+	if !strings.HasPrefix(chunks[0], "Results:\n\n"+header) {
+		t.Errorf("First chunk should keep the lead-in with the table: %q", chunks[0])
+	}
+}
@@ -0,0 +1,156 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/mymmrac/telego"
+	th "github.com/mymmrac/telego/telegohandler"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/identity"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// telegramCallbackDataLimit is the maximum size of inline keyboard callback_data.
+const telegramCallbackDataLimit = 64
+
+// SendInteractive implements channels.InteractiveCapable using an inline keyboard.
+func (c *TelegramChannel) SendInteractive(ctx context.Context, msg bus.OutboundMessage) ([]string, error) {
+	if !c.IsRunning() {
+		return nil, channels.ErrNotRunning
+	}
+
+	chatID, threadID, err := resolveTelegramOutboundTarget(msg.ChatID, &msg.Context)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chat ID %s: %w", msg.ChatID, channels.ErrSendFailed)
+	}
+
+	text := telegramInteractiveText(msg)
+	useMarkdownV2 := c.tgCfg.UseMarkdownV2
+	msgID, err := c.sendChunk(ctx, sendChunkParams{
+		chatID:        chatID,
+		threadID:      threadID,
+		content:       parseContent(text, useMarkdownV2),
+		replyToID:     msg.ReplyToMessageID,
+		mdFallback:    text,
+		useMarkdownV2: useMarkdownV2,
+		replyMarkup:   buildTelegramInlineKeyboard(msg.Interactive),
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.RecordInteractiveMessage(msgID, msg.Interactive)
+	return []string{msgID}, nil
+}
+
+func telegramInteractiveText(msg bus.OutboundMessage) string {
+	text := msg.Content
+	if title := msg.Interactive.Title; title != "" {
+		if text == "" {
+			text = "**" + title + "**"
+		} else {
+			text = "**" + title + "**\n\n" + text
+		}
+	}
+	if text == "" && msg.Interactive.Select != nil {
+		text = msg.Interactive.Select.Placeholder
+	}
+	if text == "" {
+		text = "Choose an option:"
+	}
+	return text
+}
+
+// buildTelegramInlineKeyboard maps button rows onto keyboard rows and renders
+// select options one per row, since Telegram has no native select menu.
+func buildTelegramInlineKeyboard(p *bus.InteractivePayload) *telego.InlineKeyboardMarkup {
+	markup := &telego.InlineKeyboardMarkup{}
+	idx := 0
+	for _, row := range p.Rows {
+		kbRow := make([]telego.InlineKeyboardButton, 0, len(row))
+		for _, btn := range row {
+			kb := telego.InlineKeyboardButton{Text: btn.Label}
+			if btn.URL != "" {
+				kb.URL = btn.URL
+			} else {
+				kb.CallbackData = channels.InteractiveActionRef(btn.ID, idx, telegramCallbackDataLimit)
+				idx++
+			}
+			switch btn.Style {
+			case bus.InteractiveStylePrimary:
+				kb.Style = "primary"
+			case bus.InteractiveStyleDanger:
+				kb.Style = "danger"
+			}
+			kbRow = append(kbRow, kb)
+		}
+		markup.InlineKeyboard = append(markup.InlineKeyboard, kbRow)
+	}
+	if p.Select != nil {
+		for _, opt := range p.Select.Options {
+			markup.InlineKeyboard = append(markup.InlineKeyboard, []telego.InlineKeyboardButton{{
+				Text:         opt.Label,
+				CallbackData: channels.InteractiveActionRef(opt.ID, idx, telegramCallbackDataLimit),
+			}})
+			idx++
+		}
+	}
+	return markup
+}
+
+func (c *TelegramChannel) handleCallbackQuery(ctx *th.Context, query telego.CallbackQuery) error {
+	// Always answer so the client stops showing the loading spinner.
+	defer func() {
+		_ = c.bot.AnswerCallbackQuery(ctx, &telego.AnswerCallbackQueryParams{CallbackQueryID: query.ID})
+	}()
+
+	if query.Data == "" || query.Message == nil {
+		return nil
+	}
+
+	platformID := strconv.FormatInt(query.From.ID, 10)
+	sender := bus.SenderInfo{
+		Platform:    "telegram",
+		PlatformID:  platformID,
+		CanonicalID: identity.BuildCanonicalID("telegram", platformID),
+		Username:    query.From.Username,
+		DisplayName: query.From.FirstName,
+	}
+	if !c.IsAllowedSender(sender) {
+		logger.DebugCF("telegram", "Callback query rejected by allowlist", map[string]any{
+			"user_id": platformID,
+		})
+		return nil
+	}
+
+	chat := query.Message.GetChat()
+	messageID := strconv.Itoa(query.Message.GetMessageID())
+	compositeChatID := strconv.FormatInt(chat.ID, 10)
+	inboundCtx := bus.InboundContext{
+		Channel:   c.Name(),
+		ChatType:  "direct",
+		SenderID:  platformID,
+		MessageID: query.ID,
+		Raw: map[string]string{
+			"user_id":           platformID,
+			"username":          query.From.Username,
+			"first_name":        query.From.FirstName,
+			"callback_query_id": query.ID,
+		},
+		ReplyToMessageID: messageID,
+	}
+	if chat.Type != "private" {
+		inboundCtx.ChatType = "group"
+	}
+	if m := query.Message.Message(); m != nil && chat.IsForum && m.MessageThreadID != 0 {
+		compositeChatID = fmt.Sprintf("%d/%d", chat.ID, m.MessageThreadID)
+		inboundCtx.TopicID = strconv.Itoa(m.MessageThreadID)
+	}
+	inboundCtx.ChatID = compositeChatID
+
+	callback := c.InteractionFor(messageID, query.Data)
+	return c.HandleInteraction(c.ctx, compositeChatID, callback, inboundCtx, sender)
+}
@@ -0,0 +1,41 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+func TestBuildTelegramInlineKeyboard(t *testing.T) {
+	longID := strings.Repeat("x", telegramCallbackDataLimit+1)
+	markup := buildTelegramInlineKeyboard(&bus.InteractivePayload{
+		Kind: bus.InteractiveKindButtons,
+		Rows: [][]bus.InteractiveButton{{
+			{ID: "ok", Label: "OK", Style: bus.InteractiveStylePrimary},
+			{ID: "docs", Label: "Docs", URL: "https://example.com"},
+			{ID: longID, Label: "Long"},
+		}},
+		Select: &bus.InteractiveSelect{
+			ID:      "pick",
+			Options: []bus.InteractiveOption{{ID: "a", Label: "A"}, {ID: "b", Label: "B"}},
+		},
+	})
+
+	if len(markup.InlineKeyboard) != 3 {
+		t.Fatalf("rows = %d, want 3 (buttons + one per option)", len(markup.InlineKeyboard))
+	}
+	row := markup.InlineKeyboard[0]
+	if row[0].CallbackData != "ok" || row[0].Style != "primary" {
+		t.Fatalf("button 0 = %+v", row[0])
+	}
+	if row[1].URL != "https://example.com" || row[1].CallbackData != "" {
+		t.Fatalf("link button = %+v", row[1])
+	}
+	if row[2].CallbackData != "#1" {
+		t.Fatalf("long id callback data = %q, want #1", row[2].CallbackData)
+	}
+	if got := markup.InlineKeyboard[2][0].CallbackData; got != "b" {
+		t.Fatalf("select option callback data = %q, want b", got)
+	}
+}
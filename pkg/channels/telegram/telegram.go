@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"github.com/mymmrac/telego"
+	"github.com/mymmrac/telego/telegoapi"
 	th "github.com/mymmrac/telego/telegohandler"
 	tu "github.com/mymmrac/telego/telegoutil"
 
@@ -32,16 +33,8 @@ import (
 )
 
 var (
-	reHeading    = regexp.MustCompile(`(?m)^#{1,6}\s+([^\n]+)`)
-	reBlockquote = regexp.MustCompile(`^>\s*(.*)$`)
-	reLink       = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
-	reBoldStar   = regexp.MustCompile(`\*\*(.+?)\*\*`)
-	reBoldUnder  = regexp.MustCompile(`__(.+?)__`)
-	reItalic     = regexp.MustCompile(`_([^_]+)_`)
-	reStrike     = regexp.MustCompile(`~~(.+?)~~`)
-	reListItem   = regexp.MustCompile(`^[-*]\s+`)
-	reCodeBlock  = regexp.MustCompile("```[\\w]*\\n?([\\s\\S]*?)```")
-	reInlineCode = regexp.MustCompile("`([^`]+)`")
+	reHeading  = regexp.MustCompile(`(?m)^#{1,6}\s+([^\n]+)`)
+	reBoldStar = regexp.MustCompile(`\*\*(.+?)\*\*`)
 )
 
 const (
@@ -172,6 +165,7 @@ func (c *TelegramChannel) Start(ctx context.Context) error {
 	bh.HandleMessage(func(ctx *th.Context, message telego.Message) error {
 		return c.handleMessage(ctx, &message)
 	}, th.AnyMessage())
+	bh.HandleCallbackQuery(c.handleCallbackQuery, th.AnyCallbackQueryWithMessage())
 
 	c.SetRunning(true)
 	logger.InfoCF("telegram", "Telegram bot connected", map[string]any{
@@ -357,6 +351,7 @@ type sendChunkParams struct {
 	replyToID     string
 	mdFallback    string
 	useMarkdownV2 bool
+	replyMarkup   *telego.InlineKeyboardMarkup
 }
 
 // sendChunk sends a single HTML/MarkdownV2 message, falling back to the original
@@ -367,6 +362,9 @@ func (c *TelegramChannel) sendChunk(
 ) (string, error) {
 	tgMsg := tu.Message(tu.ID(params.chatID), params.content)
 	tgMsg.MessageThreadID = params.threadID
+	if params.replyMarkup != nil {
+		tgMsg.ReplyMarkup = params.replyMarkup
+	}
 	if params.useMarkdownV2 {
 		tgMsg.WithParseMode(telego.ModeMarkdownV2)
 	} else {
@@ -389,7 +387,7 @@ func (c *TelegramChannel) sendChunk(
 		tgMsg.ParseMode = ""
 		pMsg, err = c.bot.SendMessage(ctx, tgMsg)
 		if err != nil {
-			return "", fmt.Errorf("telegram send: %w", channels.ErrTemporary)
+			return "", classifyTelegramSendError(err)
 		}
 	}
 
@@ -1543,6 +1541,25 @@ func resolveTelegramOutboundTarget(chatID string, outboundCtx *bus.InboundContex
 	return resolvedChatID, resolvedThreadID, nil
 }
 
+// classifyTelegramSendError maps a Bot API error onto the channel sentinels.
+// Flood-control responses keep Telegram's retry_after so the Manager can honor it.
+func classifyTelegramSendError(err error) error {
+	var apiErr *telegoapi.Error
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.ErrorCode == http.StatusTooManyRequests:
+			var retryAfter time.Duration
+			if apiErr.Parameters != nil {
+				retryAfter = time.Duration(apiErr.Parameters.RetryAfter) * time.Second
+			}
+			return fmt.Errorf("telegram send: %w", channels.NewRetryAfterError(retryAfter, err))
+		case apiErr.ErrorCode >= 400 && apiErr.ErrorCode < 500:
+			return fmt.Errorf("telegram send: %w: %w", channels.ErrSendFailed, err)
+		}
+	}
+	return fmt.Errorf("telegram send: %w", channels.ErrTemporary)
+}
+
 func logParseFailed(err error, useMarkdownV2 bool) {
 	parsingName := "HTML"
 	if useMarkdownV2 {
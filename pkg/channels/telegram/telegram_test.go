@@ -817,6 +817,31 @@ func TestSend_HTMLFallback_BothFail(t *testing.T) {
 	assert.Equal(t, 2, len(caller.calls), "should have HTML attempt + plain text attempt")
 }
 
+func TestSend_RateLimitedCarriesRetryAfter(t *testing.T) {
+	caller := &stubCaller{
+		callFn: func(ctx context.Context, url string, data *ta.RequestData) (*ta.Response, error) {
+			return &ta.Response{
+				Ok: false,
+				Error: &ta.Error{
+					ErrorCode:   429,
+					Description: "Too Many Requests: retry after 7",
+					Parameters:  &ta.ResponseParameters{RetryAfter: 7},
+				},
+			}, nil
+		},
+	}
+	ch := newTestChannel(t, caller)
+
+	_, err := ch.Send(context.Background(), bus.OutboundMessage{
+		ChatID:  "12345",
+		Content: "Hello",
+	})
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, channels.ErrRateLimit)
+	assert.Equal(t, 7*time.Second, channels.RetryAfter(err))
+}
+
 func TestSend_LongMessage_HTMLFallback_StopsOnError(t *testing.T) {
 	// With a long message that gets split into 2 chunks, if both HTML and
 	// plain text fail on the first chunk, Send should return early.
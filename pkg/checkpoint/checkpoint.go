@@ -0,0 +1,809 @@
+// Package checkpoint keeps restorable snapshots of workspace files taken
+// before the agent modifies them.
+//
+// A Recorder is opened per agent turn (or per evolution apply / skill
+// install) and carried in the context. Whoever is about to write a file calls
+// Capture or Record first; the first capture of a path within a recorder wins,
+// so restoring a checkpoint returns every touched file to its state before the
+// turn. File contents are stored once per content hash under blobs/, and the
+// store prunes its oldest checkpoints to stay within a byte and count budget.
+package checkpoint
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+)
+
+const (
+	DefaultMaxTotalBytes int64 = 32 << 20
+	DefaultMaxFileBytes  int64 = 1 << 20
+	DefaultMaxCount            = 100
+
+	indexFileName = "index.json"
+	blobsDirName  = "blobs"
+
+	// maxTreeFiles bounds how many files CaptureTree snapshots.
+	maxTreeFiles = 500
+	// maxSummaryRunes bounds the checkpoint summary shown in listings.
+	maxSummaryRunes = 80
+)
+
+var (
+	ErrNotFound      = errors.New("checkpoint not found")
+	ErrNothingToUndo = errors.New("nothing to undo")
+	// ErrNoMatchingPaths is returned by a partial restore when none of the
+	// requested paths was captured in the checkpoint.
+	ErrNoMatchingPaths = errors.New("no captured file matches the requested paths")
+)
+
+// Options bounds the size of a Store.
+type Options struct {
+	// MaxTotalBytes caps the stored file contents across all checkpoints.
+	MaxTotalBytes int64
+	// MaxFileBytes is the largest file that is snapshotted; bigger files are
+	// listed in the checkpoint but cannot be restored.
+	MaxFileBytes int64
+	// MaxCount caps the number of checkpoints kept.
+	MaxCount int
+}
+
+// File is one path captured in a checkpoint.
+type File struct {
+	Path string `json:"path"`
+	// Existed is false when the path was absent; restoring removes it.
+	Existed bool `json:"existed"`
+	// Tree marks a directory captured as a whole. Restoring removes the
+	// directory before the files captured under it are written back.
+	Tree    bool   `json:"tree,omitempty"`
+	Size    int64  `json:"size,omitempty"`
+	Mode    uint32 `json:"mode,omitempty"`
+	Hash    string `json:"hash,omitempty"`
+	Skipped string `json:"skipped,omitempty"`
+}
+
+// Checkpoint is the set of files captured by one recorder.
+type Checkpoint struct {
+	ID         string     `json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	SessionKey string     `json:"session_key,omitempty"`
+	Reason     string     `json:"reason"`
+	Summary    string     `json:"summary,omitempty"`
+	RestoredAt *time.Time `json:"restored_at,omitempty"`
+	Files      []File     `json:"files"`
+}
+
+// Size is the number of content bytes the checkpoint holds.
+func (c Checkpoint) Size() int64 {
+	var total int64
+	for _, f := range c.Files {
+		if f.Hash != "" {
+			total += f.Size
+		}
+	}
+	return total
+}
+
+// Paths returns the captured paths in capture order, without tree members.
+func (c Checkpoint) Paths() []string {
+	paths := make([]string, 0, len(c.Files))
+	var trees []string
+	for _, f := range c.Files {
+		if f.Tree {
+			trees = append(trees, f.Path)
+		}
+	}
+	for _, f := range c.Files {
+		if !f.Tree && underAny(f.Path, trees) {
+			continue
+		}
+		paths = append(paths, f.Path)
+	}
+	return paths
+}
+
+type index struct {
+	// Checkpoints are ordered oldest first.
+	Checkpoints []Checkpoint `json:"checkpoints"`
+}
+
+// Store persists checkpoints under a directory. It is safe for concurrent use
+// within a process; the index is re-read on every operation so a separate
+// process (such as the web launcher) sees current data.
+type Store struct {
+	dir  string
+	opts Options
+	now  func() time.Time
+	mu   sync.Mutex
+}
+
+// NewStore creates a store rooted at dir. Zero options take the defaults.
+func NewStore(dir string, opts Options) *Store {
+	if opts.MaxTotalBytes <= 0 {
+		opts.MaxTotalBytes = DefaultMaxTotalBytes
+	}
+	if opts.MaxFileBytes <= 0 {
+		opts.MaxFileBytes = DefaultMaxFileBytes
+	}
+	if opts.MaxFileBytes > opts.MaxTotalBytes {
+		opts.MaxFileBytes = opts.MaxTotalBytes
+	}
+	if opts.MaxCount <= 0 {
+		opts.MaxCount = DefaultMaxCount
+	}
+	return &Store{dir: dir, opts: opts, now: time.Now}
+}
+
+// DefaultDir returns the checkpoint directory inside a workspace state dir.
+func DefaultDir(stateDir string) string {
+	return filepath.Join(stateDir, "checkpoints")
+}
+
+// Dir returns the directory the store writes to.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// List returns checkpoints newest first. A non-empty sessionKey limits the
+// result to that session.
+func (s *Store) List(sessionKey string) ([]Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx, err := s.loadLocked()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Checkpoint, 0, len(idx.Checkpoints))
+	for i := len(idx.Checkpoints) - 1; i >= 0; i-- {
+		c := idx.Checkpoints[i]
+		if sessionKey != "" && c.SessionKey != sessionKey {
+			continue
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// Get returns a checkpoint by id.
+func (s *Store) Get(id string) (Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx, err := s.loadLocked()
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	i := findCheckpoint(idx, id)
+	if i < 0 {
+		return Checkpoint{}, ErrNotFound
+	}
+	return idx.Checkpoints[i], nil
+}
+
+// RestoreResult reports what a restore changed.
+type RestoreResult struct {
+	Restored []string `json:"restored"`
+	Removed  []string `json:"removed"`
+	Skipped  []string `json:"skipped,omitempty"`
+}
+
+// Restore returns the files of checkpoint id to their captured state. When
+// paths is non-empty only those paths (or trees containing them) are
+// restored; they are absolute, like the captured paths. Only a full restore
+// marks the checkpoint restored, so Undo still reaches it after a partial one.
+func (s *Store) Restore(id string, paths ...string) (RestoreResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx, err := s.loadLocked()
+	if err != nil {
+		return RestoreResult{}, err
+	}
+	i := findCheckpoint(idx, id)
+	if i < 0 {
+		return RestoreResult{}, ErrNotFound
+	}
+	return s.restoreLocked(idx, i, paths)
+}
+
+// Undo restores the newest checkpoint of a session that has not been
+// restored yet.
+func (s *Store) Undo(sessionKey string) (Checkpoint, RestoreResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx, err := s.loadLocked()
+	if err != nil {
+		return Checkpoint{}, RestoreResult{}, err
+	}
+	for i := len(idx.Checkpoints) - 1; i >= 0; i-- {
+		c := idx.Checkpoints[i]
+		if c.SessionKey != sessionKey || c.RestoredAt != nil {
+			continue
+		}
+		result, err := s.restoreLocked(idx, i, nil)
+		return idx.Checkpoints[i], result, err
+	}
+	return Checkpoint{}, RestoreResult{}, ErrNothingToUndo
+}
+
+func (s *Store) restoreLocked(idx *index, i int, only []string) (RestoreResult, error) {
+	c := idx.Checkpoints[i]
+	cleaned := make([]string, len(only))
+	for k, p := range only {
+		cleaned[k] = filepath.Clean(p)
+	}
+	only = cleaned
+	wanted := func(path string) bool {
+		if len(only) == 0 {
+			return true
+		}
+		for _, p := range only {
+			if p == path || isUnder(p, path) || isUnder(path, p) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(only) > 0 && !slices.ContainsFunc(c.Files, func(f File) bool { return wanted(f.Path) }) {
+		return RestoreResult{}, ErrNoMatchingPaths
+	}
+
+	var result RestoreResult
+	var errs []error
+	// Walk backwards so the earliest capture of a path is applied last and
+	// a tree is cleared before the files captured under it are rewritten.
+	for j := len(c.Files) - 1; j >= 0; j-- {
+		f := c.Files[j]
+		if !wanted(f.Path) {
+			continue
+		}
+		if f.Skipped != "" {
+			result.Skipped = append(result.Skipped, f.Path)
+			continue
+		}
+		switch {
+		case f.Tree:
+			if err := os.RemoveAll(f.Path); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", f.Path, err))
+				continue
+			}
+			if !f.Existed {
+				result.Removed = append(result.Removed, f.Path)
+			}
+		case !f.Existed:
+			if err := os.Remove(f.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, fmt.Errorf("%s: %w", f.Path, err))
+				continue
+			}
+			result.Removed = append(result.Removed, f.Path)
+		default:
+			data, err := s.readBlob(f.Hash)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", f.Path, err))
+				continue
+			}
+			mode := fs.FileMode(f.Mode)
+			if mode == 0 {
+				mode = 0o644
+			}
+			if err := fileutil.WriteFileAtomic(f.Path, data, mode); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", f.Path, err))
+				continue
+			}
+			result.Restored = append(result.Restored, f.Path)
+		}
+	}
+	sort.Strings(result.Restored)
+	sort.Strings(result.Removed)
+	sort.Strings(result.Skipped)
+
+	if len(only) == 0 {
+		now := s.now()
+		idx.Checkpoints[i].RestoredAt = &now
+		if err := s.saveLocked(idx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// FileDiff describes how a captured file differs from the workspace now.
+type FileDiff struct {
+	Path string `json:"path"`
+	// Status is one of "modified", "created", "deleted", "unchanged" or
+	// "skipped", describing the change made since the checkpoint.
+	Status string `json:"status"`
+	Diff   string `json:"diff,omitempty"`
+}
+
+// Diff compares every file of checkpoint id with the current workspace.
+func (s *Store) Diff(id string) ([]FileDiff, error) {
+	c, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var trees []File
+	for _, f := range c.Files {
+		if f.Tree {
+			trees = append(trees, f)
+		}
+	}
+	captured := make(map[string]File)
+	for _, f := range c.Files {
+		if _, ok := captured[f.Path]; !ok {
+			captured[f.Path] = f
+		}
+	}
+
+	var diffs []FileDiff
+	for _, f := range c.Files {
+		if f.Tree || captured[f.Path] != f {
+			continue
+		}
+		diffs = append(diffs, s.diffFile(f))
+	}
+	// Files that appeared inside a captured tree after the checkpoint.
+	for _, tree := range trees {
+		if tree.Skipped != "" {
+			diffs = append(diffs, FileDiff{Path: tree.Path, Status: "skipped"})
+			continue
+		}
+		_ = filepath.WalkDir(tree.Path, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			if _, ok := captured[path]; ok {
+				return nil
+			}
+			diffs = append(diffs, s.diffFile(File{Path: path}))
+			return nil
+		})
+	}
+	sort.SliceStable(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return diffs, nil
+}
+
+func (s *Store) diffFile(f File) FileDiff {
+	d := FileDiff{Path: f.Path}
+	if f.Skipped != "" {
+		d.Status = "skipped"
+		return d
+	}
+	var before []byte
+	if f.Existed {
+		data, err := s.readBlob(f.Hash)
+		if err != nil {
+			d.Status = "skipped"
+			return d
+		}
+		before = data
+	}
+	after, err := os.ReadFile(f.Path)
+	exists := err == nil
+	switch {
+	case !f.Existed && !exists:
+		d.Status = "unchanged"
+		return d
+	case !f.Existed:
+		d.Status = "created"
+	case !exists:
+		d.Status = "deleted"
+	case string(before) == string(after):
+		d.Status = "unchanged"
+		return d
+	default:
+		d.Status = "modified"
+	}
+	d.Diff = unifiedDiff(f.Path, before, after)
+	return d
+}
+
+// Recorder captures files for a single checkpoint. The checkpoint is created
+// lazily on the first capture, so turns that write nothing leave no trace.
+type Recorder struct {
+	store      *Store
+	workspace  string
+	sessionKey string
+	reason     string
+	summary    string
+
+	mu   sync.Mutex
+	id   string
+	seen map[string]bool
+}
+
+// Begin opens a recorder. Relative paths passed to it are resolved against
+// workspace.
+func (s *Store) Begin(workspace, sessionKey, reason, summary string) *Recorder {
+	return &Recorder{
+		store:      s,
+		workspace:  workspace,
+		sessionKey: sessionKey,
+		reason:     reason,
+		summary:    truncateRunes(strings.Join(strings.Fields(summary), " "), maxSummaryRunes),
+		seen:       make(map[string]bool),
+	}
+}
+
+// ID returns the checkpoint id, or "" when nothing has been captured yet.
+func (r *Recorder) ID() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.id
+}
+
+// Has reports whether path was already captured by this recorder.
+func (r *Recorder) Has(path string) bool {
+	abs := r.resolve(path)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.seen[abs]
+}
+
+// Record captures path with content the caller has already read. existed is
+// false when the path does not exist yet.
+func (r *Recorder) Record(path string, data []byte, existed bool) error {
+	abs := r.resolve(path)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.seen[abs] {
+		return nil
+	}
+
+	file := File{Path: abs, Existed: existed}
+	blobs := map[string][]byte{}
+	if existed {
+		r.fillContent(&file, data, blobs)
+		if info, err := os.Stat(abs); err == nil {
+			file.Mode = uint32(info.Mode().Perm())
+		}
+	}
+	if err := r.appendLocked([]File{file}, blobs); err != nil {
+		return err
+	}
+	r.seen[abs] = true
+	return nil
+}
+
+// Capture reads path from disk and records it.
+func (r *Recorder) Capture(path string) error {
+	abs := r.resolve(path)
+	if r.Has(abs) {
+		return nil
+	}
+	info, err := os.Stat(abs)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return r.Record(abs, nil, false)
+	case err != nil:
+		return err
+	case info.IsDir():
+		return r.CaptureTree(abs)
+	case info.Size() > r.store.opts.MaxFileBytes:
+		// Avoid reading a file that will not be stored anyway.
+		return r.recordSkipped(abs, info, "file too large")
+	}
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return err
+	}
+	return r.Record(abs, data, true)
+}
+
+// CaptureTree records a directory and every file under it, so a restore can
+// remove files created inside it afterwards.
+func (r *Recorder) CaptureTree(dir string) error {
+	abs := r.resolve(dir)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.seen[abs] {
+		return nil
+	}
+
+	marker := File{Path: abs, Tree: true}
+	var files []File
+	blobs := map[string][]byte{}
+
+	info, err := os.Stat(abs)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return err
+	case !info.IsDir():
+		return fmt.Errorf("%s is not a directory", abs)
+	default:
+		marker.Existed = true
+		var total int64
+		walkErr := filepath.WalkDir(abs, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			fi, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if !fi.Mode().IsRegular() {
+				return nil
+			}
+			total += fi.Size()
+			if len(files) >= maxTreeFiles || fi.Size() > r.store.opts.MaxFileBytes ||
+				total > r.store.opts.MaxTotalBytes/2 {
+				return errTreeTooLarge
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			f := File{Path: path, Existed: true, Mode: uint32(fi.Mode().Perm())}
+			r.fillContent(&f, data, blobs)
+			files = append(files, f)
+			return nil
+		})
+		if errors.Is(walkErr, errTreeTooLarge) {
+			marker.Skipped = "directory too large"
+			files, blobs = nil, map[string][]byte{}
+		} else if walkErr != nil {
+			return walkErr
+		}
+	}
+
+	// The marker goes last so a restore, which walks backwards, clears the
+	// directory before writing its files back.
+	if err := r.appendLocked(append(files, marker), blobs); err != nil {
+		return err
+	}
+	r.seen[abs] = true
+	for _, f := range files {
+		r.seen[f.Path] = true
+	}
+	return nil
+}
+
+var errTreeTooLarge = errors.New("tree too large")
+
+func (r *Recorder) recordSkipped(abs string, info fs.FileInfo, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.seen[abs] {
+		return nil
+	}
+	file := File{Path: abs, Existed: true, Size: info.Size(), Skipped: reason}
+	if err := r.appendLocked([]File{file}, nil); err != nil {
+		return err
+	}
+	r.seen[abs] = true
+	return nil
+}
+
+func (r *Recorder) fillContent(f *File, data []byte, blobs map[string][]byte) {
+	f.Size = int64(len(data))
+	if f.Size > r.store.opts.MaxFileBytes {
+		f.Skipped = "file too large"
+		return
+	}
+	sum := sha256.Sum256(data)
+	f.Hash = hex.EncodeToString(sum[:])
+	blobs[f.Hash] = data
+}
+
+func (r *Recorder) resolve(path string) string {
+	if !filepath.IsAbs(path) && r.workspace != "" {
+		path = filepath.Join(r.workspace, path)
+	}
+	return filepath.Clean(path)
+}
+
+func (r *Recorder) appendLocked(files []File, blobs map[string][]byte) error {
+	id, err := r.store.append(r.id, r, files, blobs)
+	if err != nil {
+		return err
+	}
+	r.id = id
+	return nil
+}
+
+func (s *Store) append(id string, r *Recorder, files []File, blobs map[string][]byte) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for hash, data := range blobs {
+		if err := s.writeBlob(hash, data); err != nil {
+			return "", err
+		}
+	}
+
+	idx, err := s.loadLocked()
+	if err != nil {
+		return "", err
+	}
+	i := -1
+	if id != "" {
+		i = findCheckpoint(idx, id)
+	}
+	if i < 0 {
+		// A checkpoint pruned mid-turn (or a first capture) starts afresh.
+		newID, err := newCheckpointID(s.now())
+		if err != nil {
+			return "", err
+		}
+		idx.Checkpoints = append(idx.Checkpoints, Checkpoint{
+			ID:         newID,
+			CreatedAt:  s.now(),
+			SessionKey: r.sessionKey,
+			Reason:     r.reason,
+			Summary:    r.summary,
+		})
+		i = len(idx.Checkpoints) - 1
+	}
+	idx.Checkpoints[i].Files = append(idx.Checkpoints[i].Files, files...)
+	id = idx.Checkpoints[i].ID
+
+	s.pruneLocked(idx, id)
+	if err := s.saveLocked(idx); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// pruneLocked drops the oldest checkpoints until the store fits its budget.
+// The checkpoint being written (keep) is never dropped.
+func (s *Store) pruneLocked(idx *index, keep string) {
+	for len(idx.Checkpoints) > 1 {
+		if len(idx.Checkpoints) <= s.opts.MaxCount && totalBlobBytes(idx) <= s.opts.MaxTotalBytes {
+			return
+		}
+		if idx.Checkpoints[0].ID == keep {
+			return
+		}
+		idx.Checkpoints = idx.Checkpoints[1:]
+	}
+}
+
+func totalBlobBytes(idx *index) int64 {
+	seen := make(map[string]bool)
+	var total int64
+	for _, c := range idx.Checkpoints {
+		for _, f := range c.Files {
+			if f.Hash == "" || seen[f.Hash] {
+				continue
+			}
+			seen[f.Hash] = true
+			total += f.Size
+		}
+	}
+	return total
+}
+
+func (s *Store) loadLocked() (*index, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, indexFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &index{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read checkpoint index: %w", err)
+	}
+	var idx index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("parse checkpoint index: %w", err)
+	}
+	return &idx, nil
+}
+
+func (s *Store) saveLocked(idx *index) error {
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return err
+	}
+	if err := fileutil.WriteFileAtomic(filepath.Join(s.dir, indexFileName), data, 0o600); err != nil {
+		return fmt.Errorf("write checkpoint index: %w", err)
+	}
+	s.gcBlobsLocked(idx)
+	return nil
+}
+
+// gcBlobsLocked removes blobs no checkpoint refers to any more.
+func (s *Store) gcBlobsLocked(idx *index) {
+	entries, err := os.ReadDir(filepath.Join(s.dir, blobsDirName))
+	if err != nil {
+		return
+	}
+	live := make(map[string]bool)
+	for _, c := range idx.Checkpoints {
+		for _, f := range c.Files {
+			if f.Hash != "" {
+				live[f.Hash] = true
+			}
+		}
+	}
+	for _, e := range entries {
+		if !live[e.Name()] {
+			_ = os.Remove(filepath.Join(s.dir, blobsDirName, e.Name()))
+		}
+	}
+}
+
+func (s *Store) writeBlob(hash string, data []byte) error {
+	path := filepath.Join(s.dir, blobsDirName, hash)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	if err := fileutil.WriteFileAtomic(path, data, 0o600); err != nil {
+		return fmt.Errorf("write checkpoint blob: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) readBlob(hash string) ([]byte, error) {
+	if hash == "" || strings.ContainsAny(hash, `/\.`) {
+		return nil, fmt.Errorf("invalid checkpoint blob %q", hash)
+	}
+	data, err := os.ReadFile(filepath.Join(s.dir, blobsDirName, hash))
+	if err != nil {
+		return nil, fmt.Errorf("read checkpoint blob: %w", err)
+	}
+	return data, nil
+}
+
+func findCheckpoint(idx *index, id string) int {
+	for i, c := range idx.Checkpoints {
+		if c.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func newCheckpointID(now time.Time) (string, error) {
+	var b [3]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return now.UTC().Format("20060102-150405") + "-" + hex.EncodeToString(b[:]), nil
+}
+
+func isUnder(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func underAny(path string, dirs []string) bool {
+	for _, dir := range dirs {
+		if isUnder(path, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,219 @@
+package checkpoint
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestRecorder_RestoreReturnsFilesToFirstCapture(t *testing.T) {
+	workspace := t.TempDir()
+	store := NewStore(t.TempDir(), Options{})
+	agentMD := filepath.Join(workspace, "AGENT.md")
+	writeFile(t, agentMD, "original\n")
+
+	rec := store.Begin(workspace, "s1", "turn", "rewrite the   agent\nfile")
+	assert.Empty(t, rec.ID(), "no checkpoint before the first capture")
+
+	require.NoError(t, rec.Capture("AGENT.md"))
+	writeFile(t, agentMD, "first edit\n")
+	// A second capture in the same turn keeps the original snapshot.
+	require.NoError(t, rec.Capture(agentMD))
+	writeFile(t, agentMD, "second edit\n")
+
+	require.NoError(t, rec.Record("notes/new.txt", nil, false))
+	writeFile(t, filepath.Join(workspace, "notes", "new.txt"), "created\n")
+
+	list, err := store.List("s1")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, rec.ID(), list[0].ID)
+	assert.Equal(t, "rewrite the agent file", list[0].Summary)
+	assert.Equal(t, []string{agentMD, filepath.Join(workspace, "notes", "new.txt")}, list[0].Paths())
+
+	diffs, err := store.Diff(rec.ID())
+	require.NoError(t, err)
+	require.Len(t, diffs, 2)
+	assert.Equal(t, "modified", diffs[0].Status)
+	assert.Contains(t, diffs[0].Diff, "-original")
+	assert.Contains(t, diffs[0].Diff, "+second edit")
+	assert.Equal(t, "created", diffs[1].Status)
+
+	result, err := store.Restore(rec.ID())
+	require.NoError(t, err)
+	assert.Equal(t, []string{agentMD}, result.Restored)
+	assert.Equal(t, []string{filepath.Join(workspace, "notes", "new.txt")}, result.Removed)
+	assert.Equal(t, "original\n", readFile(t, agentMD))
+	assert.NoFileExists(t, filepath.Join(workspace, "notes", "new.txt"))
+
+	got, err := store.Get(rec.ID())
+	require.NoError(t, err)
+	assert.NotNil(t, got.RestoredAt)
+}
+
+func TestStore_UndoWalksBackThroughSession(t *testing.T) {
+	workspace := t.TempDir()
+	store := NewStore(t.TempDir(), Options{})
+	path := filepath.Join(workspace, "f.txt")
+	writeFile(t, path, "v1")
+
+	first := store.Begin(workspace, "s1", "turn", "")
+	require.NoError(t, first.Capture(path))
+	writeFile(t, path, "v2")
+
+	other := store.Begin(workspace, "s2", "turn", "")
+	require.NoError(t, other.Capture(filepath.Join(workspace, "other.txt")))
+
+	second := store.Begin(workspace, "s1", "turn", "")
+	require.NoError(t, second.Capture(path))
+	writeFile(t, path, "v3")
+
+	c, _, err := store.Undo("s1")
+	require.NoError(t, err)
+	assert.Equal(t, second.ID(), c.ID)
+	assert.Equal(t, "v2", readFile(t, path))
+
+	c, _, err = store.Undo("s1")
+	require.NoError(t, err)
+	assert.Equal(t, first.ID(), c.ID)
+	assert.Equal(t, "v1", readFile(t, path))
+
+	_, _, err = store.Undo("s1")
+	assert.ErrorIs(t, err, ErrNothingToUndo)
+}
+
+func TestStore_PartialRestore(t *testing.T) {
+	workspace := t.TempDir()
+	store := NewStore(t.TempDir(), Options{})
+	notes := filepath.Join(workspace, "notes.txt")
+	todo := filepath.Join(workspace, "todo.txt")
+	writeFile(t, notes, "notes v1")
+	writeFile(t, todo, "todo v1")
+
+	rec := store.Begin(workspace, "s1", "turn", "")
+	require.NoError(t, rec.Capture(notes))
+	require.NoError(t, rec.Capture(todo))
+	writeFile(t, notes, "notes v2")
+	writeFile(t, todo, "todo v2")
+
+	_, err := store.Restore(rec.ID(), filepath.Join(workspace, "missing.txt"))
+	assert.ErrorIs(t, err, ErrNoMatchingPaths)
+
+	result, err := store.Restore(rec.ID(), notes)
+	require.NoError(t, err)
+	assert.Equal(t, []string{notes}, result.Restored)
+	assert.Equal(t, "notes v1", readFile(t, notes))
+	assert.Equal(t, "todo v2", readFile(t, todo))
+
+	got, err := store.Get(rec.ID())
+	require.NoError(t, err)
+	assert.Nil(t, got.RestoredAt, "a partial restore leaves the checkpoint undoable")
+
+	_, _, err = store.Undo("s1")
+	require.NoError(t, err)
+	assert.Equal(t, "todo v1", readFile(t, todo))
+}
+
+func TestRecorder_CaptureTree(t *testing.T) {
+	workspace := t.TempDir()
+	store := NewStore(t.TempDir(), Options{})
+	skillDir := filepath.Join(workspace, "skills", "weather")
+	writeFile(t, filepath.Join(skillDir, "SKILL.md"), "old skill")
+
+	rec := store.Begin(workspace, "", "skill install", "weather")
+	require.NoError(t, rec.CaptureTree(skillDir))
+	require.NoError(t, os.RemoveAll(skillDir))
+	writeFile(t, filepath.Join(skillDir, "SKILL.md"), "new skill")
+	writeFile(t, filepath.Join(skillDir, "scripts", "run.sh"), "echo hi")
+
+	diffs, err := store.Diff(rec.ID())
+	require.NoError(t, err)
+	statuses := map[string]string{}
+	for _, d := range diffs {
+		statuses[d.Path] = d.Status
+	}
+	assert.Equal(t, "modified", statuses[filepath.Join(skillDir, "SKILL.md")])
+	assert.Equal(t, "created", statuses[filepath.Join(skillDir, "scripts", "run.sh")])
+
+	_, err = store.Restore(rec.ID())
+	require.NoError(t, err)
+	assert.Equal(t, "old skill", readFile(t, filepath.Join(skillDir, "SKILL.md")))
+	assert.NoDirExists(t, filepath.Join(skillDir, "scripts"))
+
+	// A tree that did not exist is removed on restore.
+	fresh := filepath.Join(workspace, "skills", "fresh")
+	rec = store.Begin(workspace, "", "skill install", "fresh")
+	require.NoError(t, rec.CaptureTree(fresh))
+	writeFile(t, filepath.Join(fresh, "SKILL.md"), "x")
+	result, err := store.Restore(rec.ID())
+	require.NoError(t, err)
+	assert.Equal(t, []string{fresh}, result.Removed)
+	assert.NoDirExists(t, fresh)
+}
+
+func TestStore_RetentionBoundsSizeAndCount(t *testing.T) {
+	workspace := t.TempDir()
+	dir := t.TempDir()
+	store := NewStore(dir, Options{MaxTotalBytes: 250, MaxFileBytes: 100, MaxCount: 3})
+
+	for i := 0; i < 5; i++ {
+		path := filepath.Join(workspace, "f.txt")
+		writeFile(t, path, strings.Repeat(string(rune('a'+i)), 90))
+		require.NoError(t, store.Begin(workspace, "s", "turn", "").Capture(path))
+	}
+
+	list, err := store.List("")
+	require.NoError(t, err)
+	assert.Len(t, list, 2, "two 90-byte snapshots fit the 250-byte budget")
+
+	blobs, err := os.ReadDir(filepath.Join(dir, blobsDirName))
+	require.NoError(t, err)
+	assert.Len(t, blobs, 2, "pruned snapshots are garbage collected")
+
+	big := filepath.Join(workspace, "big.bin")
+	writeFile(t, big, strings.Repeat("x", 200))
+	rec := store.Begin(workspace, "s", "turn", "")
+	require.NoError(t, rec.Capture(big))
+	c, err := store.Get(rec.ID())
+	require.NoError(t, err)
+	assert.Equal(t, "file too large", c.Files[0].Skipped)
+
+	result, err := store.Restore(rec.ID())
+	require.NoError(t, err)
+	assert.Equal(t, []string{big}, result.Skipped)
+}
+
+func TestContextCapture(t *testing.T) {
+	workspace := t.TempDir()
+	store := NewStore(t.TempDir(), Options{})
+	path := filepath.Join(workspace, "a.txt")
+	writeFile(t, path, "a")
+
+	// Without a recorder capturing is a no-op.
+	Capture(context.Background(), path)
+
+	rec := store.Begin(workspace, "s", "turn", "")
+	ctx := WithRecorder(context.Background(), rec)
+	assert.Same(t, rec, FromContext(ctx))
+	Capture(ctx, "a.txt")
+	assert.True(t, rec.Has(path))
+	assert.NotEmpty(t, rec.ID())
+}
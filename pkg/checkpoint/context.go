@@ -0,0 +1,56 @@
+package checkpoint
+
+import (
+	"context"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+type recorderCtxKey struct{}
+
+// WithRecorder returns a context that carries r. A nil r returns ctx as is.
+func WithRecorder(ctx context.Context, r *Recorder) context.Context {
+	if r == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, recorderCtxKey{}, r)
+}
+
+// FromContext returns the recorder carried by ctx, or nil.
+func FromContext(ctx context.Context) *Recorder {
+	if ctx == nil {
+		return nil
+	}
+	r, _ := ctx.Value(recorderCtxKey{}).(*Recorder)
+	return r
+}
+
+// Capture records path with the recorder in ctx, if any. Checkpoints are best
+// effort: a failure is logged and never blocks the write that follows.
+func Capture(ctx context.Context, path string) {
+	r := FromContext(ctx)
+	if r == nil {
+		return
+	}
+	if err := r.Capture(path); err != nil {
+		logCaptureError(path, err)
+	}
+}
+
+// CaptureTree records a directory with the recorder in ctx, if any.
+func CaptureTree(ctx context.Context, dir string) {
+	r := FromContext(ctx)
+	if r == nil {
+		return
+	}
+	if err := r.CaptureTree(dir); err != nil {
+		logCaptureError(dir, err)
+	}
+}
+
+func logCaptureError(path string, err error) {
+	logger.WarnCF("checkpoint", "Failed to capture file before write", map[string]any{
+		"path":  path,
+		"error": err.Error(),
+	})
+}
@@ -0,0 +1,27 @@
+package checkpoint
+
+import (
+	"fmt"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// maxDiffBytes bounds the diff returned for a single file.
+const maxDiffBytes = 64 * 1024
+
+func unifiedDiff(path string, before, after []byte) string {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(before)),
+		B:        difflib.SplitLines(string(after)),
+		FromFile: path + " (checkpoint)",
+		ToFile:   path + " (current)",
+		Context:  3,
+	})
+	if err != nil {
+		return fmt.Sprintf("[diff unavailable: %v]", err)
+	}
+	if len(diff) > maxDiffBytes {
+		diff = diff[:maxDiffBytes] + "\n[diff truncated]\n"
+	}
+	return diff
+}
@@ -19,5 +19,10 @@ func BuiltinDefinitions() []Definition {
 		contextCommand(),
 		subagentsCommand(),
 		reloadCommand(),
+		linkCommand(),
+		unlinkCommand(),
+		undoCommand(),
+		checkpointsCommand(),
+		evolutionCommand(),
 	}
 }
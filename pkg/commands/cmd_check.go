@@ -3,8 +3,12 @@ package commands
 import (
 	"context"
 	"fmt"
+	"strings"
 )
 
+// maxListedDeliveryFailures bounds the failures shown by /check channel.
+const maxListedDeliveryFailures = 5
+
 func checkCommand() Definition {
 	return Definition{
 		Name:        "check",
@@ -12,8 +16,8 @@ func checkCommand() Definition {
 		SubCommands: []SubCommand{
 			{
 				Name:        "channel",
-				Description: "Check if a channel is available",
-				ArgsUsage:   "<name>",
+				Description: "Check if a channel is available and inspect failed deliveries",
+				ArgsUsage:   "<name> [replay [id]]",
 				Handler: func(_ context.Context, req Request, rt *Runtime) error {
 					if rt == nil || rt.SwitchChannel == nil {
 						return req.Reply(unavailableMsg)
@@ -22,12 +26,56 @@ func checkCommand() Definition {
 					if value == "" {
 						return req.Reply("Usage: /check channel <name>")
 					}
+					if nthToken(req.Text, 3) == "replay" {
+						return replayChannelDelivery(req, rt, value, nthToken(req.Text, 4))
+					}
 					if err := rt.SwitchChannel(value); err != nil {
 						return req.Reply(err.Error())
 					}
-					return req.Reply(fmt.Sprintf("Channel '%s' is available and enabled", value))
+					reply := fmt.Sprintf("Channel '%s' is available and enabled", value)
+					if rt.GetChannelDelivery != nil {
+						status, err := rt.GetChannelDelivery(value)
+						if err != nil {
+							return req.Reply(reply + "\nOutbound queue: " + err.Error())
+						}
+						reply += formatChannelDelivery(value, status)
+					}
+					return req.Reply(reply)
 				},
 			},
 		},
 	}
 }
+
+func replayChannelDelivery(req Request, rt *Runtime, channel, id string) error {
+	if rt.ReplayChannelDelivery == nil {
+		return req.Reply(unavailableMsg)
+	}
+	n, err := rt.ReplayChannelDelivery(channel, id)
+	if err != nil {
+		return req.Reply(err.Error())
+	}
+	if n == 0 {
+		return req.Reply(fmt.Sprintf("No failed deliveries to replay for channel '%s'", channel))
+	}
+	return req.Reply(fmt.Sprintf("Requeued %d failed deliveries for channel '%s'", n, channel))
+}
+
+func formatChannelDelivery(channel string, status *ChannelDeliveryStatus) string {
+	if status == nil {
+		return ""
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "\nOutbound queue: %d pending, %d failed", status.Pending, status.Failed)
+	failures := status.RecentFailures
+	if len(failures) > maxListedDeliveryFailures {
+		failures = failures[:maxListedDeliveryFailures]
+	}
+	for _, f := range failures {
+		fmt.Fprintf(&sb, "\n- %s (chat %s, %s): %s", f.ID, f.ChatID, f.FailedAt.Format("2006-01-02 15:04"), f.Error)
+	}
+	if status.Failed > 0 {
+		fmt.Fprintf(&sb, "\nUse /check channel %s replay [id] to retry.", channel)
+	}
+	return sb.String()
+}
@@ -0,0 +1,172 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// maxListedDrafts bounds the /evolution drafts listing.
+const maxListedDrafts = 15
+
+func evolutionCommand() Definition {
+	return Definition{
+		Name:        "evolution",
+		Description: "Review skill drafts learned by evolution",
+		SubCommands: []SubCommand{
+			{
+				Name:        "drafts",
+				Description: "List skill drafts",
+				Handler:     evolutionDraftsHandler(),
+			},
+			{
+				Name:        "show",
+				Description: "Show a draft's diff and evidence",
+				ArgsUsage:   "<draft-id>",
+				Handler:     evolutionShowHandler(),
+			},
+			{
+				Name:        "accept",
+				Description: "Apply a candidate draft",
+				ArgsUsage:   "<draft-id>",
+				Handler:     evolutionAcceptHandler(),
+			},
+			{
+				Name:        "reject",
+				Description: "Reject a draft",
+				ArgsUsage:   "<draft-id> [reason]",
+				Handler:     evolutionRejectHandler(),
+			},
+		},
+	}
+}
+
+func evolutionDraftsHandler() Handler {
+	return func(_ context.Context, req Request, rt *Runtime) error {
+		if rt == nil || rt.ListEvolutionDrafts == nil {
+			return req.Reply(unavailableMsg)
+		}
+		drafts, err := rt.ListEvolutionDrafts()
+		if err != nil {
+			return req.Reply("Failed to list drafts: " + err.Error())
+		}
+		if len(drafts) == 0 {
+			return req.Reply("No evolution drafts yet.")
+		}
+		var sb strings.Builder
+		sb.WriteString("Evolution drafts (newest first):\n")
+		for i, d := range drafts {
+			if i == maxListedDrafts {
+				fmt.Fprintf(&sb, "... and %d older\n", len(drafts)-i)
+				break
+			}
+			fmt.Fprintf(&sb, "- %s [%s] %s %s", d.ID, d.Status, d.ChangeKind, d.TargetSkill)
+			if d.Summary != "" {
+				fmt.Fprintf(&sb, ": %s", d.Summary)
+			}
+			sb.WriteByte('\n')
+		}
+		sb.WriteString("Use /evolution show <id>, then /evolution accept <id> or /evolution reject <id>.")
+		return req.Reply(sb.String())
+	}
+}
+
+func evolutionShowHandler() Handler {
+	return func(_ context.Context, req Request, rt *Runtime) error {
+		if rt == nil || rt.ShowEvolutionDraft == nil {
+			return req.Reply(unavailableMsg)
+		}
+		id := nthToken(req.Text, 2)
+		if id == "" {
+			return req.Reply("Usage: /evolution show <draft-id>")
+		}
+		detail, err := rt.ShowEvolutionDraft(id)
+		if err != nil {
+			return req.Reply("Failed to load draft: " + err.Error())
+		}
+		return req.Reply(formatEvolutionDraftDetail(detail))
+	}
+}
+
+func evolutionAcceptHandler() Handler {
+	return func(ctx context.Context, req Request, rt *Runtime) error {
+		if rt == nil || rt.AcceptEvolutionDraft == nil {
+			return req.Reply(unavailableMsg)
+		}
+		id := nthToken(req.Text, 2)
+		if id == "" {
+			return req.Reply("Usage: /evolution accept <draft-id>")
+		}
+		draft, err := rt.AcceptEvolutionDraft(ctx, id, draftReviewer(req))
+		if err != nil {
+			return req.Reply("Accept failed: " + err.Error())
+		}
+		if draft.Status != "accepted" {
+			msg := fmt.Sprintf("Draft %s was not applied (%s).", draft.ID, draft.Status)
+			if len(draft.Findings) > 0 {
+				msg += "\n- " + strings.Join(draft.Findings, "\n- ")
+			}
+			return req.Reply(msg)
+		}
+		return req.Reply(fmt.Sprintf("Accepted draft %s; skill %s updated.", draft.ID, draft.TargetSkill))
+	}
+}
+
+func evolutionRejectHandler() Handler {
+	return func(_ context.Context, req Request, rt *Runtime) error {
+		if rt == nil || rt.RejectEvolutionDraft == nil {
+			return req.Reply(unavailableMsg)
+		}
+		id := nthToken(req.Text, 2)
+		if id == "" {
+			return req.Reply("Usage: /evolution reject <draft-id> [reason]")
+		}
+		var reason string
+		if fields := strings.Fields(req.Text); len(fields) > 3 {
+			reason = strings.Join(fields[3:], " ")
+		}
+		draft, err := rt.RejectEvolutionDraft(id, draftReviewer(req), reason)
+		if err != nil {
+			return req.Reply("Reject failed: " + err.Error())
+		}
+		return req.Reply(fmt.Sprintf("Rejected draft %s.", draft.ID))
+	}
+}
+
+// draftReviewer identifies who decided on a draft in its review notes.
+func draftReviewer(req Request) string {
+	if req.SenderID == "" {
+		return req.Channel
+	}
+	return req.Channel + ":" + req.SenderID
+}
+
+func formatEvolutionDraftDetail(d *EvolutionDraftDetail) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Draft %s [%s] %s %s (%s)", d.ID, d.Status, d.ChangeKind, d.TargetSkill,
+		d.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	if d.Summary != "" {
+		fmt.Fprintf(&sb, "\n%s", d.Summary)
+	}
+	if d.JudgedCount > 0 {
+		fmt.Fprintf(&sb, "\nSuccess: %d/%d tasks (%.0f%%)", d.SuccessCount, d.JudgedCount,
+			100*float64(d.SuccessCount)/float64(d.JudgedCount))
+	}
+	for _, group := range []struct {
+		label string
+		lines []string
+	}{
+		{"Findings", d.Findings},
+		{"Review", d.ReviewNotes},
+		{"Evidence", d.Evidence},
+	} {
+		if len(group.lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n%s:\n- %s", group.label, strings.Join(group.lines, "\n- "))
+	}
+	if d.Diff != "" {
+		fmt.Fprintf(&sb, "\nDiff:\n```diff\n%s\n```", strings.TrimRight(d.Diff, "\n"))
+	}
+	return sb.String()
+}
@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestEvolutionDraftCommands(t *testing.T) {
+	var acceptedBy, rejectReason string
+	rt := &Runtime{
+		ListEvolutionDrafts: func() ([]EvolutionDraftInfo, error) {
+			return []EvolutionDraftInfo{
+				{ID: "draft-rule-2", Status: "candidate", ChangeKind: "append", TargetSkill: "weather", Summary: "prefer city ids"},
+				{ID: "draft-rule-1", Status: "accepted", ChangeKind: "create", TargetSkill: "weather"},
+			}, nil
+		},
+		ShowEvolutionDraft: func(id string) (*EvolutionDraftDetail, error) {
+			return &EvolutionDraftDetail{
+				EvolutionDraftInfo: EvolutionDraftInfo{
+					ID: "draft-rule-2", Status: "candidate", ChangeKind: "append", TargetSkill: "weather",
+				},
+				Diff:         "+Prefer city ids.",
+				Evidence:     []string{"2026-10-01 succeeded: weather in Paris"},
+				SuccessCount: 3,
+				JudgedCount:  4,
+			}, nil
+		},
+		AcceptEvolutionDraft: func(_ context.Context, id, reviewer string) (*EvolutionDraftInfo, error) {
+			acceptedBy = reviewer
+			return &EvolutionDraftInfo{
+				ID: id, Status: "quarantined", TargetSkill: "weather",
+				Findings: []string{"skill test failed: paris"},
+			}, nil
+		},
+		RejectEvolutionDraft: func(id, reviewer, reason string) (*EvolutionDraftInfo, error) {
+			rejectReason = reason
+			return &EvolutionDraftInfo{ID: id, Status: "rejected"}, nil
+		},
+	}
+	ex := NewExecutor(NewRegistry(BuiltinDefinitions()), rt)
+
+	var reply string
+	req := Request{
+		Channel:  "telegram",
+		SenderID: "42",
+		Text:     "/evolution drafts",
+		Reply: func(text string) error {
+			reply = text
+			return nil
+		},
+	}
+	ex.Execute(context.Background(), req)
+	if !strings.Contains(reply, "- draft-rule-2 [candidate] append weather: prefer city ids\n") ||
+		!strings.Contains(reply, "- draft-rule-1 [accepted] create weather\n") {
+		t.Fatalf("drafts reply=%q", reply)
+	}
+
+	req.Text = "/evolution show draft-rule-2"
+	ex.Execute(context.Background(), req)
+	if !strings.Contains(reply, "Success: 3/4 tasks (75%)") || !strings.Contains(reply, "+Prefer city ids.") ||
+		!strings.Contains(reply, "- 2026-10-01 succeeded: weather in Paris") {
+		t.Fatalf("show reply=%q", reply)
+	}
+
+	req.Text = "/evolution accept draft-rule-2"
+	ex.Execute(context.Background(), req)
+	if acceptedBy != "telegram:42" {
+		t.Fatalf("reviewer = %q, want telegram:42", acceptedBy)
+	}
+	if reply != "Draft draft-rule-2 was not applied (quarantined).\n- skill test failed: paris" {
+		t.Fatalf("accept reply=%q", reply)
+	}
+
+	req.Text = "/evolution reject draft-rule-2 too specific"
+	ex.Execute(context.Background(), req)
+	if rejectReason != "too specific" || reply != "Rejected draft draft-rule-2." {
+		t.Fatalf("reject reason=%q reply=%q", rejectReason, reply)
+	}
+
+	req.Text = "/evolution show"
+	ex.Execute(context.Background(), req)
+	if reply != "Usage: /evolution show <draft-id>" {
+		t.Fatalf("usage reply=%q", reply)
+	}
+}
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+func linkCommand() Definition {
+	return Definition{
+		Name:        "link",
+		Description: "Link your accounts on different channels",
+		Usage:       "/link [code|status]",
+		Handler: func(_ context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.IssueIdentityLink == nil || rt.ConfirmIdentityLink == nil {
+				return req.Reply(unavailableMsg)
+			}
+			if strings.TrimSpace(req.SenderID) == "" {
+				return req.Reply("Cannot link: sender identity is unknown on this channel.")
+			}
+
+			arg := nthToken(req.Text, 1)
+			switch {
+			case arg == "":
+				code, expiresAt, err := rt.IssueIdentityLink(req.Channel, req.SenderID)
+				if err != nil {
+					return req.Reply("Failed to create link code: " + err.Error())
+				}
+				minutes := int(time.Until(expiresAt).Round(time.Minute).Minutes())
+				return req.Reply(fmt.Sprintf(
+					"Link code: %s\nSend \"/link %s\" from your other account within %d minutes. "+
+						"Anyone who sends this code shares your memory, so do not post it in group chats.",
+					code, code, minutes,
+				))
+			case strings.EqualFold(arg, "status"):
+				if rt.GetIdentityLinks == nil {
+					return req.Reply(unavailableMsg)
+				}
+				canonical, ids := rt.GetIdentityLinks(req.Channel, req.SenderID)
+				if canonical == "" {
+					return req.Reply("This account is not linked to any other account.")
+				}
+				return req.Reply(fmt.Sprintf("Linked as %s:\n- %s", canonical, strings.Join(ids, "\n- ")))
+			default:
+				canonical, err := rt.ConfirmIdentityLink(arg, req.Channel, req.SenderID)
+				if err != nil {
+					return req.Reply("Failed to link accounts: " + err.Error())
+				}
+				return req.Reply(fmt.Sprintf("Accounts linked. You now share memory as %s.", canonical))
+			}
+		},
+	}
+}
+
+func unlinkCommand() Definition {
+	return Definition{
+		Name:        "unlink",
+		Description: "Unlink this account from your other accounts",
+		Usage:       "/unlink",
+		Handler: func(_ context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.UnlinkIdentity == nil {
+				return req.Reply(unavailableMsg)
+			}
+			canonical, err := rt.UnlinkIdentity(req.Channel, req.SenderID)
+			if err != nil {
+				return req.Reply("Failed to unlink account: " + err.Error())
+			}
+			if canonical == "" {
+				return req.Reply("This account is not linked to any other account.")
+			}
+			return req.Reply(fmt.Sprintf("This account is no longer linked to %s.", canonical))
+		},
+	}
+}
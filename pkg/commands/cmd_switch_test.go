@@ -3,7 +3,9 @@ package commands
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestSwitchModel_Success(t *testing.T) {
@@ -277,3 +279,183 @@ func TestSwitch_NoSubCommand(t *testing.T) {
 		t.Fatal("expected usage reply for bare /switch")
 	}
 }
+
+func TestCheckChannel_ShowsOutboundQueue(t *testing.T) {
+	failedAt := time.Date(2026, 10, 1, 12, 30, 0, 0, time.UTC)
+	rt := &Runtime{
+		SwitchChannel: func(value string) error { return nil },
+		GetChannelDelivery: func(channel string) (*ChannelDeliveryStatus, error) {
+			return &ChannelDeliveryStatus{
+				Pending: 2,
+				Failed:  1,
+				RecentFailures: []ChannelDeliveryFailure{{
+					ID:       "entry-1",
+					ChatID:   "42",
+					Error:    "send failed",
+					FailedAt: failedAt,
+				}},
+			}, nil
+		},
+	}
+	ex := NewExecutor(NewRegistry(BuiltinDefinitions()), rt)
+
+	var reply string
+	ex.Execute(context.Background(), Request{
+		Text: "/check channel telegram",
+		Reply: func(text string) error {
+			reply = text
+			return nil
+		},
+	})
+	want := "Channel 'telegram' is available and enabled\n" +
+		"Outbound queue: 2 pending, 1 failed\n" +
+		"- entry-1 (chat 42, 2026-10-01 12:30): send failed\n" +
+		"Use /check channel telegram replay [id] to retry."
+	if reply != want {
+		t.Fatalf("reply=%q, want=%q", reply, want)
+	}
+}
+
+func TestCheckChannel_Replay(t *testing.T) {
+	var gotChannel, gotID string
+	rt := &Runtime{
+		SwitchChannel: func(value string) error { return nil },
+		ReplayChannelDelivery: func(channel, id string) (int, error) {
+			gotChannel, gotID = channel, id
+			return 1, nil
+		},
+	}
+	ex := NewExecutor(NewRegistry(BuiltinDefinitions()), rt)
+
+	var reply string
+	ex.Execute(context.Background(), Request{
+		Text: "/check channel telegram replay entry-1",
+		Reply: func(text string) error {
+			reply = text
+			return nil
+		},
+	})
+	if gotChannel != "telegram" || gotID != "entry-1" {
+		t.Fatalf("replay args = (%q, %q)", gotChannel, gotID)
+	}
+	if reply != "Requeued 1 failed deliveries for channel 'telegram'" {
+		t.Fatalf("reply=%q", reply)
+	}
+}
+
+func TestLink_IssueAndConfirm(t *testing.T) {
+	var confirmed []string
+	rt := &Runtime{
+		IssueIdentityLink: func(channel, senderID string) (string, time.Time, error) {
+			if channel != "telegram" || senderID != "123" {
+				t.Fatalf("IssueIdentityLink(%q, %q)", channel, senderID)
+			}
+			return "ABC234", time.Now().Add(10 * time.Minute), nil
+		},
+		ConfirmIdentityLink: func(code, channel, senderID string) (string, error) {
+			confirmed = []string{code, channel, senderID}
+			return "telegram:123", nil
+		},
+	}
+	ex := NewExecutor(NewRegistry(BuiltinDefinitions()), rt)
+
+	var reply string
+	req := Request{
+		Channel:  "telegram",
+		SenderID: "123",
+		Text:     "/link",
+		Reply: func(text string) error {
+			reply = text
+			return nil
+		},
+	}
+	ex.Execute(context.Background(), req)
+	if !strings.Contains(reply, "/link ABC234") || !strings.Contains(reply, "10 minutes") {
+		t.Fatalf("issue reply=%q", reply)
+	}
+
+	req.Channel, req.SenderID, req.Text = "discord", "456", "/link ABC234"
+	ex.Execute(context.Background(), req)
+	if strings.Join(confirmed, ",") != "ABC234,discord,456" {
+		t.Fatalf("confirm args = %v", confirmed)
+	}
+	if reply != "Accounts linked. You now share memory as telegram:123." {
+		t.Fatalf("confirm reply=%q", reply)
+	}
+}
+
+func TestUnlink_NotLinked(t *testing.T) {
+	rt := &Runtime{
+		UnlinkIdentity: func(channel, senderID string) (string, error) {
+			return "", nil
+		},
+	}
+	ex := NewExecutor(NewRegistry(BuiltinDefinitions()), rt)
+
+	var reply string
+	ex.Execute(context.Background(), Request{
+		Channel:  "discord",
+		SenderID: "456",
+		Text:     "/unlink",
+		Reply: func(text string) error {
+			reply = text
+			return nil
+		},
+	})
+	if reply != "This account is not linked to any other account." {
+		t.Fatalf("reply=%q", reply)
+	}
+}
+
+func TestUndoAndCheckpoints(t *testing.T) {
+	var restoredID string
+	rt := &Runtime{
+		ListCheckpoints: func() ([]CheckpointInfo, error) {
+			return []CheckpointInfo{
+				{ID: "c2", Reason: "turn", Summary: "edit agent file", Files: []string{"/ws/AGENT.md"}},
+				{ID: "c1", Reason: "skill install", Files: []string{"/ws/skills/x"}, Restored: true},
+			}, nil
+		},
+		RestoreCheckpoint: func(id string) (*CheckpointRestore, error) {
+			restoredID = id
+			return &CheckpointRestore{ID: "c2", Restored: []string{"/ws/AGENT.md"}, Removed: []string{"/ws/new.txt"}}, nil
+		},
+	}
+	ex := NewExecutor(NewRegistry(BuiltinDefinitions()), rt)
+
+	var reply string
+	req := Request{
+		Text: "/checkpoints",
+		Reply: func(text string) error {
+			reply = text
+			return nil
+		},
+	}
+	ex.Execute(context.Background(), req)
+	if !strings.Contains(reply, "- c2 turn, 1 file(s): edit agent file\n") ||
+		!strings.Contains(reply, "- c1 skill install, 1 file(s) [restored]") {
+		t.Fatalf("list reply=%q", reply)
+	}
+
+	req.Text = "/checkpoints c2"
+	ex.Execute(context.Background(), req)
+	if !strings.Contains(reply, "Checkpoint c2 (turn,") || !strings.Contains(reply, "- /ws/AGENT.md") {
+		t.Fatalf("detail reply=%q", reply)
+	}
+
+	req.Text = "/undo"
+	ex.Execute(context.Background(), req)
+	if restoredID != "" {
+		t.Fatalf("restore id = %q, want newest", restoredID)
+	}
+	want := "Restored checkpoint c2.\nRestored:\n- /ws/AGENT.md\nRemoved:\n- /ws/new.txt"
+	if reply != want {
+		t.Fatalf("undo reply=%q", reply)
+	}
+
+	req.Text = "/undo c1"
+	ex.Execute(context.Background(), req)
+	if restoredID != "c1" {
+		t.Fatalf("restore id = %q, want c1", restoredID)
+	}
+}
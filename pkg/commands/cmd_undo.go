@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// maxListedCheckpoints bounds the /checkpoints listing.
+const maxListedCheckpoints = 10
+
+func undoCommand() Definition {
+	return Definition{
+		Name:        "undo",
+		Description: "Restore the files changed by the last turn",
+		Usage:       "/undo [checkpoint-id]",
+		Handler: func(_ context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.RestoreCheckpoint == nil {
+				return req.Reply(unavailableMsg)
+			}
+			result, err := rt.RestoreCheckpoint(nthToken(req.Text, 1))
+			if err != nil {
+				return req.Reply("Undo failed: " + err.Error())
+			}
+			return req.Reply(formatCheckpointRestore(result))
+		},
+	}
+}
+
+func checkpointsCommand() Definition {
+	return Definition{
+		Name:        "checkpoints",
+		Description: "List file checkpoints of this session",
+		Usage:       "/checkpoints [checkpoint-id]",
+		Handler: func(_ context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.ListCheckpoints == nil {
+				return req.Reply(unavailableMsg)
+			}
+			list, err := rt.ListCheckpoints()
+			if err != nil {
+				return req.Reply("Failed to list checkpoints: " + err.Error())
+			}
+
+			if id := nthToken(req.Text, 1); id != "" {
+				for _, c := range list {
+					if c.ID == id {
+						return req.Reply(formatCheckpointDetail(c))
+					}
+				}
+				return req.Reply(fmt.Sprintf("Checkpoint %s not found in this session.", id))
+			}
+
+			if len(list) == 0 {
+				return req.Reply("No checkpoints in this session. Files are captured when the agent modifies them.")
+			}
+			var sb strings.Builder
+			sb.WriteString("Checkpoints (newest first):\n")
+			for i, c := range list {
+				if i == maxListedCheckpoints {
+					fmt.Fprintf(&sb, "... and %d older\n", len(list)-i)
+					break
+				}
+				fmt.Fprintf(&sb, "- %s %s, %d file(s)", c.ID, c.Reason, len(c.Files))
+				if c.Summary != "" {
+					fmt.Fprintf(&sb, ": %s", c.Summary)
+				}
+				if c.Restored {
+					sb.WriteString(" [restored]")
+				}
+				sb.WriteByte('\n')
+			}
+			sb.WriteString("Use /undo to restore the newest one, or /undo <id>.")
+			return req.Reply(sb.String())
+		},
+	}
+}
+
+func formatCheckpointDetail(c CheckpointInfo) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Checkpoint %s (%s, %s)", c.ID, c.Reason, c.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	if c.Restored {
+		sb.WriteString(" [restored]")
+	}
+	if c.Summary != "" {
+		fmt.Fprintf(&sb, "\n%s", c.Summary)
+	}
+	for _, f := range c.Files {
+		fmt.Fprintf(&sb, "\n- %s", f)
+	}
+	return sb.String()
+}
+
+func formatCheckpointRestore(r *CheckpointRestore) string {
+	if r == nil {
+		return "Nothing was restored."
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Restored checkpoint %s.", r.ID)
+	for _, group := range []struct {
+		label string
+		paths []string
+	}{
+		{"Restored", r.Restored},
+		{"Removed", r.Removed},
+		{"Not restorable (too large)", r.Skipped},
+	} {
+		if len(group.paths) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n%s:\n- %s", group.label, strings.Join(group.paths, "\n- "))
+	}
+	return sb.String()
+}
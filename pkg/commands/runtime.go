@@ -2,6 +2,7 @@ package commands
 
 import (
 	"context"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/config"
 )
@@ -44,6 +45,64 @@ type StopResult struct {
 	TaskName string
 }
 
+// ChannelDeliveryFailure describes one dead-lettered outbound message.
+type ChannelDeliveryFailure struct {
+	ID       string
+	ChatID   string
+	Error    string
+	Attempts int
+	FailedAt time.Time
+}
+
+// ChannelDeliveryStatus describes the durable outbound queue of a channel.
+type ChannelDeliveryStatus struct {
+	Pending        int
+	Failed         int
+	RecentFailures []ChannelDeliveryFailure // newest first
+}
+
+// CheckpointInfo describes a file checkpoint taken before a turn, evolution
+// apply or skill install modified the workspace.
+type CheckpointInfo struct {
+	ID        string
+	CreatedAt time.Time
+	Reason    string
+	Summary   string
+	Files     []string
+	Restored  bool
+}
+
+// CheckpointRestore reports the files an undo or restore changed.
+type CheckpointRestore struct {
+	ID       string
+	Restored []string
+	Removed  []string
+	Skipped  []string
+}
+
+// EvolutionDraftInfo describes a skill draft learned by evolution.
+type EvolutionDraftInfo struct {
+	ID          string
+	TargetSkill string
+	ChangeKind  string
+	Status      string
+	Summary     string
+	CreatedAt   time.Time
+	ReviewedBy  string
+	ReviewNotes []string
+	Findings    []string // scan and test findings that quarantined it
+}
+
+// EvolutionDraftDetail adds the diff against the current skill and the
+// evidence behind a draft.
+type EvolutionDraftDetail struct {
+	EvolutionDraftInfo
+	Diff         string
+	Evidence     []string // one line per task record the draft was learned from
+	SuccessCount int
+	JudgedCount  int
+}
+
 // Runtime provides runtime dependencies to command handlers. It is constructed
 // per-request by the agent loop so that per-request state (like session scope)
 // can coexist with long-lived callbacks (like GetModelInfo).
@@ -64,4 +123,41 @@ type Runtime struct {
 	ClearHistory       func() error
 	ReloadConfig       func() error
 	StopActiveTurn     func() (StopResult, error)
+
+	// GetChannelDelivery reports the durable outbound queue of a channel. It
+	// is nil when the queue is disabled.
+	GetChannelDelivery func(channel string) (*ChannelDeliveryStatus, error)
+	// ReplayChannelDelivery requeues failed deliveries of a channel; an empty
+	// id replays all of them. It returns the number of requeued messages.
+	ReplayChannelDelivery func(channel, id string) (int, error)
+
+	// IssueIdentityLink creates a one-time code that links the caller's
+	// identity once it is confirmed from another channel.
+	IssueIdentityLink func(channel, senderID string) (code string, expiresAt time.Time, err error)
+	// ConfirmIdentityLink redeems a code and returns the canonical identity
+	// shared by both accounts.
+	ConfirmIdentityLink func(code, channel, senderID string) (canonical string, err error)
+	// UnlinkIdentity removes the caller's identity from its linked group and
+	// returns the group's canonical identity, or "" when it was not linked.
+	UnlinkIdentity func(channel, senderID string) (canonical string, err error)
+	// GetIdentityLinks returns the caller's canonical identity and linked ids.
+	GetIdentityLinks func(channel, senderID string) (canonical string, ids []string)
+
+	// ListCheckpoints returns the file checkpoints of the current session,
+	// newest first. It is nil when checkpoints are disabled.
+	ListCheckpoints func() ([]CheckpointInfo, error)
+	// RestoreCheckpoint restores a checkpoint of the current session; an
+	// empty id restores the newest one not yet restored.
+	RestoreCheckpoint func(id string) (*CheckpointRestore, error)
+
+	// ListEvolutionDrafts returns the evolution drafts of the current agent,
+	// newest first. It is nil when evolution is disabled.
+	ListEvolutionDrafts func() ([]EvolutionDraftInfo, error)
+	// ShowEvolutionDraft returns a draft by id or unique id prefix.
+	ShowEvolutionDraft func(id string) (*EvolutionDraftDetail, error)
+	// AcceptEvolutionDraft applies a candidate draft; the result may still
+	// be quarantined when the skill tests fail.
+	AcceptEvolutionDraft func(ctx context.Context, id, reviewer string) (*EvolutionDraftInfo, error)
+	// RejectEvolutionDraft rejects a candidate or quarantined draft.
+	RejectEvolutionDraft func(id, reviewer, reason string) (*EvolutionDraftInfo, error)
 }
@@ -48,6 +48,7 @@ type Config struct {
 	Heartbeat HeartbeatConfig `json:"heartbeat"           yaml:"-"`
 	Devices   DevicesConfig   `json:"devices"             yaml:"-"`
 	Voice     VoiceConfig     `json:"voice"               yaml:"-"`
+	Swarm     SwarmConfig     `json:"swarm,omitempty"     yaml:"swarm,omitempty"`
 	// BuildInfo contains build-time version information
 	BuildInfo BuildInfo `json:"build_info,omitempty" yaml:"-"`
 
@@ -63,6 +64,17 @@ type EvolutionConfig struct {
 	MinSuccessRatio float64  `json:"min_success_ratio,omitempty"`
 	ColdPathTrigger string   `json:"cold_path_trigger,omitempty"`
 	ColdPathTimes   []string `json:"cold_path_times,omitempty"`
+	// RequireSkillTests holds a draft back from acceptance until the target
+	// skill's test scenarios pass against the applied draft.
+	RequireSkillTests bool `json:"require_skill_tests,omitempty"`
+	// Approval is "auto" (default) or "human". With "human", apply mode
+	// leaves candidate drafts for review and applies them only once a person
+	// accepts them.
+	Approval string `json:"approval,omitempty"`
+	// RollbackMinUses is how many uses both a new skill version and the one
+	// before it need before a lower success ratio rolls the skill back.
+	// 0 means 5; a negative value turns automatic rollback off.
+	RollbackMinUses int `json:"rollback_min_uses,omitempty"`
 	// Deprecated: use MinTaskCount.
 	MinCaseCount int `json:"min_case_count,omitempty"`
 	// Deprecated: use MinSuccessRatio.
@@ -78,6 +90,10 @@ func (c EvolutionConfig) MarshalJSON() ([]byte, error) {
 		MinSuccessRatio float64  `json:"min_success_ratio,omitempty"`
 		ColdPathTrigger string   `json:"cold_path_trigger,omitempty"`
 		ColdPathTimes   []string `json:"cold_path_times,omitempty"`
+
+		RequireSkillTests bool   `json:"require_skill_tests,omitempty"`
+		Approval          string `json:"approval,omitempty"`
+		RollbackMinUses   int    `json:"rollback_min_uses,omitempty"`
 	}{
 		Enabled:         c.Enabled,
 		Mode:            c.Mode,
@@ -86,6 +102,12 @@ func (c EvolutionConfig) MarshalJSON() ([]byte, error) {
 		MinSuccessRatio: c.EffectiveMinSuccessRatio(),
 		ColdPathTrigger: strings.TrimSpace(c.ColdPathTrigger),
 		ColdPathTimes:   c.EffectiveColdPathTimes(),
+
+		RequireSkillTests: c.RequireSkillTests,
+		RollbackMinUses:   c.RollbackMinUses,
+	}
+	if c.RequiresHumanApproval() {
+		out.Approval = "human"
 	}
 	if !out.Enabled {
 		out.Mode = ""
@@ -172,7 +194,33 @@ func (c EvolutionConfig) EffectiveColdPathTimes() []string {
 }
 
 func (c EvolutionConfig) AutoAppliesDrafts() bool {
-	return c.EffectiveMode() == "apply"
+	return c.EffectiveMode() == "apply" && !c.RequiresHumanApproval()
+}
+
+func (c EvolutionConfig) EffectiveApproval() string {
+	if strings.EqualFold(strings.TrimSpace(c.Approval), "human") {
+		return "human"
+	}
+	return "auto"
+}
+
+// EffectiveRollbackMinUses returns the uses needed on both sides before an
+// automatic rollback, or 0 when automatic rollback is off.
+func (c EvolutionConfig) EffectiveRollbackMinUses() int {
+	switch {
+	case !c.Enabled || c.RollbackMinUses < 0:
+		return 0
+	case c.RollbackMinUses == 0:
+		return 5
+	default:
+		return c.RollbackMinUses
+	}
+}
+
+// RequiresHumanApproval reports whether drafts wait for a person to accept
+// them before they are applied.
+func (c EvolutionConfig) RequiresHumanApproval() bool {
+	return c.EffectiveApproval() == "human"
 }
 
 // IsolationConfig controls subprocess isolation for commands started by PicoClaw.
@@ -180,6 +228,40 @@ func (c EvolutionConfig) AutoAppliesDrafts() bool {
 type IsolationConfig struct {
 	Enabled     bool         `json:"enabled,omitempty"`
 	ExposePaths []ExposePath `json:"expose_paths,omitempty"`
+	// DefaultProfile names the resource profile applied to child processes
+	// that do not select one themselves. Empty means no limits.
+	DefaultProfile string `json:"default_profile,omitempty"`
+	// Profiles adds or replaces named resource profiles. The built-in
+	// "strict", "build" and "network" profiles are always available.
+	Profiles map[string]IsolationProfile `json:"profiles,omitempty"`
+	// CgroupParent is a delegated cgroup v2 directory under which per-process
+	// groups are created. Defaults to the cgroup of the picoclaw process.
+	CgroupParent string `json:"cgroup_parent,omitempty"`
+}
+
+// IsolationProfile limits the resources of one child process. Zero values
+// leave the corresponding limit unset. It is enforced on Linux only.
+type IsolationProfile struct {
+	// CPUSeconds caps consumed CPU time (RLIMIT_CPU).
+	CPUSeconds int `json:"cpu_seconds,omitempty"`
+	// CPUPercent caps CPU bandwidth through cgroup v2 cpu.max; 100 is one core.
+	CPUPercent int `json:"cpu_percent,omitempty"`
+	// MemoryMB caps memory through cgroup v2 memory.max, or address space
+	// (RLIMIT_AS) where cgroups are unavailable.
+	MemoryMB int `json:"memory_mb,omitempty"`
+	// MaxProcesses caps tasks through cgroup v2 pids.max.
+	MaxProcesses int `json:"max_processes,omitempty"`
+	// MaxOpenFiles caps file descriptors (RLIMIT_NOFILE).
+	MaxOpenFiles int `json:"max_open_files,omitempty"`
+	// MaxFileSizeMB caps the size of files the process writes (RLIMIT_FSIZE).
+	MaxFileSizeMB int `json:"max_file_size_mb,omitempty"`
+	// WallClockSeconds kills one-shot commands that run longer. Long-running
+	// MCP servers are exempt.
+	WallClockSeconds int `json:"wall_clock_seconds,omitempty"`
+	// Network is "host" (default) or "none" for a private network namespace.
+	Network string `json:"network,omitempty"`
+	// Seccomp selects a syscall filter preset: "" (none), "default" or "strict".
+	Seccomp string `json:"seccomp,omitempty"`
 }
 
 // ExposePath describes a host path that should remain visible inside the isolated
@@ -237,6 +319,9 @@ type ProcessHookConfig struct {
 	Env       map[string]string `json:"env,omitempty"`
 	Observe   []string          `json:"observe,omitempty"`
 	Intercept []string          `json:"intercept,omitempty"`
+	// IsolationProfile selects the isolation resource profile for the hook
+	// process; empty uses isolation.default_profile.
+	IsolationProfile string `json:"isolation_profile,omitempty"`
 }
 
 // BuildInfo contains build-time version information
@@ -270,6 +355,7 @@ type AgentsConfig struct {
 	Defaults AgentDefaults   `json:"defaults"`
 	List     []AgentConfig   `json:"list,omitempty"`
 	Dispatch *DispatchConfig `json:"dispatch,omitempty"`
+	Teams    []TeamConfig    `json:"teams,omitempty"`
 }
 
 // AgentModelConfig supports both string and structured model config.
@@ -327,6 +413,60 @@ type SubagentsConfig struct {
 	Model       *AgentModelConfig `json:"model,omitempty"`
 }
 
+// TeamConfig names agents that work on one task together through a shared
+// blackboard. The team tool runs a task with a team until its lead writes the
+// done key, nobody has unread messages, or max_rounds is reached.
+type TeamConfig struct {
+	ID      string   `json:"id"`
+	Members []string `json:"members"`
+	// Lead receives the task and answers it; defaults to the first member.
+	Lead        string `json:"lead,omitempty"`
+	MaxRounds   int    `json:"max_rounds,omitempty"`
+	DoneKey     string `json:"done_key,omitempty"`
+	TokenBudget int    `json:"token_budget,omitempty"`
+}
+
+const (
+	DefaultTeamMaxRounds = 4
+	DefaultTeamDoneKey   = "final"
+)
+
+// EffectiveLead returns the lead agent id, defaulting to the first member.
+func (t TeamConfig) EffectiveLead() string {
+	if lead := strings.TrimSpace(t.Lead); lead != "" {
+		return lead
+	}
+	if len(t.Members) > 0 {
+		return t.Members[0]
+	}
+	return ""
+}
+
+func (t TeamConfig) EffectiveMaxRounds() int {
+	if t.MaxRounds <= 0 {
+		return DefaultTeamMaxRounds
+	}
+	return t.MaxRounds
+}
+
+func (t TeamConfig) EffectiveDoneKey() string {
+	if key := strings.TrimSpace(t.DoneKey); key != "" {
+		return key
+	}
+	return DefaultTeamDoneKey
+}
+
+// FindTeam returns the team with the given id, ignoring case.
+func (c AgentsConfig) FindTeam(id string) (TeamConfig, bool) {
+	id = strings.TrimSpace(id)
+	for _, team := range c.Teams {
+		if strings.EqualFold(strings.TrimSpace(team.ID), id) {
+			return team, true
+		}
+	}
+	return TeamConfig{}, false
+}
+
 type DispatchConfig struct {
 	Rules []DispatchRule `json:"rules,omitempty"`
 }
@@ -404,6 +544,30 @@ type RoutingConfig struct {
 	Enabled    bool    `json:"enabled"`
 	LightModel string  `json:"light_model"` // model_name from model_list to use for simple tasks
 	Threshold  float64 `json:"threshold"`   // complexity score in [0,1]; score >= threshold → primary model
+	// Classifier scores messages: "rule" (default), "llm" asks the light model,
+	// "knn" compares against past turns recorded by evolution.
+	Classifier   string `json:"classifier,omitempty"`
+	KNNNeighbors int    `json:"knn_neighbors,omitempty"` // neighbors for the knn classifier; 0 uses 7
+	// Tiers replaces light_model/threshold with several cheaper models,
+	// cheapest first. The agent's primary model is the implicit top tier.
+	Tiers      []RoutingTierConfig     `json:"tiers,omitempty"`
+	Escalation RoutingEscalationConfig `json:"escalation,omitzero"`
+}
+
+// RoutingTierConfig is one model tier below the primary model.
+type RoutingTierConfig struct {
+	Name     string  `json:"name,omitempty"`     // reported in logs and events; defaults to the model name
+	Model    string  `json:"model"`              // model_name from model_list
+	MaxScore float64 `json:"max_score"`          // scores below this (and above the previous tier's) use this tier
+	MaxCost  float64 `json:"max_cost,omitempty"` // estimated USD per call; above it a cheaper tier is used
+}
+
+// RoutingEscalationConfig retries a turn on the next tier when the cheaper
+// model fails: an LLM error, an empty or truncated reply, or repeated tool
+// errors.
+type RoutingEscalationConfig struct {
+	Enabled    bool `json:"enabled"`
+	ToolErrors int  `json:"tool_errors,omitempty"` // failed tool calls on one tier before escalating; 0 uses 2
 }
 
 // SubTurnConfig configures the SubTurn execution system.
@@ -491,6 +655,30 @@ type GroupTriggerConfig struct {
 	Prefixes    []string `json:"prefixes,omitempty"`
 }
 
+// InboundLimitConfig throttles inbound messages before they reach the agent.
+// Rates are token buckets refilled per minute. Unset values use defaults, a
+// negative rate or threshold disables that check, and a reply of "-" drops
+// throttled messages silently. Replies may use the {retry_after} placeholder.
+type InboundLimitConfig struct {
+	Enabled         bool               `json:"enabled,omitempty"`
+	SenderPerMinute float64            `json:"sender_per_minute,omitempty"`
+	SenderBurst     int                `json:"sender_burst,omitempty"`
+	ChatPerMinute   float64            `json:"chat_per_minute,omitempty"`
+	ChatBurst       int                `json:"chat_burst,omitempty"`
+	CooldownReply   string             `json:"cooldown_reply,omitempty"`
+	Flood           FloodControlConfig `json:"flood,omitempty"`
+}
+
+// FloodControlConfig detects repeated identical messages and bursts of
+// mentions within WindowSeconds, muting the sender for MuteSeconds.
+type FloodControlConfig struct {
+	RepeatThreshold  int    `json:"repeat_threshold,omitempty"`
+	MentionThreshold int    `json:"mention_threshold,omitempty"`
+	WindowSeconds    int    `json:"window_seconds,omitempty"`
+	MuteSeconds      int    `json:"mute_seconds,omitempty"`
+	MuteReply        string `json:"mute_reply,omitempty"`
+}
+
 // TypingConfig controls typing indicator behavior (Phase 10).
 type TypingConfig struct {
 	Enabled bool `json:"enabled,omitempty"`
@@ -812,6 +1000,12 @@ type ModelConfig struct {
 	ExtraBody           map[string]any       `json:"extra_body,omitempty"`              // Additional fields to inject into request body
 	CustomHeaders       map[string]string    `json:"custom_headers,omitempty"`          // Additional headers to inject into every HTTP request
 
+	// Model metadata used by multi-tier routing.
+	Vision        bool    `json:"vision,omitempty"`         // accepts image input
+	ContextWindow int     `json:"context_window,omitempty"` // context size in tokens
+	InputCost     float64 `json:"input_cost,omitempty"`     // USD per 1M input tokens
+	OutputCost    float64 `json:"output_cost,omitempty"`    // USD per 1M output tokens
+
 
 	LastTestStatus   string `json:"last_test_status,omitempty" yaml:"last_test_status,omitempty"`
 	LastTestReason   string `json:"last_test_reason,omitempty" yaml:"last_test_reason,omitempty"`
@@ -1072,6 +1266,9 @@ type ExecConfig struct {
 	CustomDenyPatterns  []string `                                 json:"custom_deny_patterns"  env:"PICOCLAW_TOOLS_EXEC_CUSTOM_DENY_PATTERNS"`
 	CustomAllowPatterns []string `                                 json:"custom_allow_patterns" env:"PICOCLAW_TOOLS_EXEC_CUSTOM_ALLOW_PATTERNS"`
 	TimeoutSeconds      int      `                                 json:"timeout_seconds"       env:"PICOCLAW_TOOLS_EXEC_TIMEOUT_SECONDS"` // 0 means use default (60s)
+	// IsolationProfile selects the isolation resource profile for commands;
+	// empty uses isolation.default_profile.
+	IsolationProfile string `json:"isolation_profile,omitempty" env:"PICOCLAW_TOOLS_EXEC_ISOLATION_PROFILE"`
 }
 
 type SkillsToolsConfig struct {
@@ -1089,6 +1286,149 @@ type MediaCleanupConfig struct {
 	Interval   int `                                    json:"interval_minutes" env:"PICOCLAW_MEDIA_CLEANUP_INTERVAL"`
 }
 
+// CheckpointsConfig controls the per-turn snapshots taken before the agent
+// modifies workspace files, used by /undo and /checkpoints.
+type CheckpointsConfig struct {
+	ToolConfig `    envPrefix:"PICOCLAW_TOOLS_CHECKPOINTS_"`
+	MaxTotalMB int `                                        json:"max_total_mb" env:"PICOCLAW_TOOLS_CHECKPOINTS_MAX_TOTAL_MB"`
+	MaxFileKB  int `                                        json:"max_file_kb"  env:"PICOCLAW_TOOLS_CHECKPOINTS_MAX_FILE_KB"`
+	MaxCount   int `                                        json:"max_count"    env:"PICOCLAW_TOOLS_CHECKPOINTS_MAX_COUNT"`
+}
+
+// HTTPRequestConfig controls the http_request tool used to call REST APIs.
+type HTTPRequestConfig struct {
+	ToolConfig `yaml:"-" envPrefix:"PICOCLAW_TOOLS_HTTP_REQUEST_"`
+	// TimeoutSeconds bounds a single request including redirects. Default: 30.
+	TimeoutSeconds int `yaml:"-" json:"timeout_seconds" env:"PICOCLAW_TOOLS_HTTP_REQUEST_TIMEOUT_SECONDS"`
+	// MaxResponseBytes caps the response body read from the server. Default: 1MB.
+	MaxResponseBytes int64 `yaml:"-" json:"max_response_bytes" env:"PICOCLAW_TOOLS_HTTP_REQUEST_MAX_RESPONSE_BYTES"`
+	// PrivateHostWhitelist lists private IPs or CIDRs (e.g. a Home Assistant
+	// box on the LAN) that may be called despite SSRF protection.
+	PrivateHostWhitelist FlexibleStringSlice `yaml:"-" json:"private_host_whitelist,omitempty" env:"PICOCLAW_TOOLS_HTTP_REQUEST_PRIVATE_HOST_WHITELIST"`
+	// Hosts restricts requests to matching hosts. When empty any public host
+	// may be called, but no credential can be attached.
+	Hosts []HTTPRequestHostRule `yaml:"-" json:"hosts,omitempty"`
+	// Credentials maps a name the agent can reference to a secret value
+	// (plaintext, file:// or enc://). Values are stored in .security.yml.
+	Credentials map[string]SecureString `yaml:"credentials,omitempty" json:"credentials,omitempty"`
+}
+
+// HTTPRequestHostRule allows http_request calls to one host.
+type HTTPRequestHostRule struct {
+	// Host is a hostname, "*.example.com" or "host:port".
+	Host string `json:"host"`
+	// Methods limits the allowed HTTP methods; empty allows all.
+	Methods []string `json:"methods,omitempty"`
+	// PathPrefixes limits the allowed URL paths; empty allows all.
+	PathPrefixes []string `json:"path_prefixes,omitempty"`
+	// Credentials names the credentials that may be sent to this host.
+	Credentials []string `json:"credentials,omitempty"`
+}
+
+// RunScriptConfig controls the run_script tool, which evaluates Starlark
+// scripts in an embedded interpreter for small computations.
+type RunScriptConfig struct {
+	ToolConfig `yaml:"-" envPrefix:"PICOCLAW_TOOLS_RUN_SCRIPT_"`
+	// MaxSteps bounds the interpreter steps per run. Default: 10,000,000.
+	MaxSteps int64 `yaml:"-" json:"max_steps" env:"PICOCLAW_TOOLS_RUN_SCRIPT_MAX_STEPS"`
+	// MaxMemoryMB is an approximate heap budget per run. Default: 32.
+	MaxMemoryMB int `yaml:"-" json:"max_memory_mb" env:"PICOCLAW_TOOLS_RUN_SCRIPT_MAX_MEMORY_MB"`
+	// TimeoutSeconds bounds the wall-clock time per run. Default: 10.
+	TimeoutSeconds int `yaml:"-" json:"timeout_seconds" env:"PICOCLAW_TOOLS_RUN_SCRIPT_TIMEOUT_SECONDS"`
+	// AllowWorkspaceRead enables read_file() inside scripts, checked with the
+	// same path rules as the read_file tool. Default: false.
+	AllowWorkspaceRead bool `yaml:"-" json:"allow_workspace_read" env:"PICOCLAW_TOOLS_RUN_SCRIPT_ALLOW_WORKSPACE_READ"`
+}
+
+// HomeConfig controls the home tool and the Home Assistant subscription
+// service, which talk to one Home Assistant instance over its REST and
+// WebSocket APIs.
+type HomeConfig struct {
+	ToolConfig `yaml:"-" envPrefix:"PICOCLAW_TOOLS_HOME_"`
+	// URL is the Home Assistant base URL, e.g. http://homeassistant.local:8123.
+	URL string `yaml:"-" json:"url" env:"PICOCLAW_TOOLS_HOME_URL"`
+	// Token is a long-lived access token created on the Home Assistant
+	// profile page. Stored in .security.yml.
+	Token SecureString `yaml:"token,omitempty" json:"token,omitzero" env:"PICOCLAW_TOOLS_HOME_TOKEN"`
+	// TimeoutSeconds bounds REST calls and WebSocket commands. Default: 10.
+	TimeoutSeconds int `yaml:"-" json:"timeout_seconds" env:"PICOCLAW_TOOLS_HOME_TIMEOUT_SECONDS"`
+	// AllowedDomains lists the service domains the agent may call, e.g.
+	// "light" or "climate". Empty disables service calls.
+	AllowedDomains []string `yaml:"-" json:"allowed_domains,omitempty"`
+	// AllowedEntities optionally limits service calls to entity IDs matching
+	// these patterns ("light.*", "switch.garden_*"). Empty allows any entity
+	// in an allowed domain.
+	AllowedEntities []string `yaml:"-" json:"allowed_entities,omitempty"`
+	// Subscriptions start an agent turn when matching entities change state.
+	Subscriptions []HomeSubscriptionConfig `yaml:"-" json:"subscriptions,omitempty"`
+}
+
+// HomeSubscriptionConfig starts an agent turn when a watched entity changes.
+type HomeSubscriptionConfig struct {
+	// Name identifies the subscription in logs and in the turn's sender.
+	Name string `json:"name"`
+	// Entities lists entity IDs or patterns ("binary_sensor.*_door").
+	Entities []string `json:"entities"`
+	// To only fires when the new state equals this value, e.g. "on".
+	To string `json:"to,omitempty"`
+	// Prompt tells the agent what to do with the change.
+	Prompt string `json:"prompt,omitempty"`
+	// Channel and ChatID choose where the reply goes. Empty uses the last
+	// active chat.
+	Channel string `json:"channel,omitempty"`
+	ChatID  string `json:"chat_id,omitempty"`
+	// CooldownSeconds suppresses repeat turns for the same entity. Default: 60.
+	CooldownSeconds int `json:"cooldown_seconds,omitempty"`
+}
+
+// ModbusConfig controls the modbus tool. Only the devices listed here can be
+// addressed, and writes are limited to each device's writable ranges.
+type ModbusConfig struct {
+	ToolConfig `yaml:"-" envPrefix:"PICOCLAW_TOOLS_MODBUS_"`
+	// TimeoutMS bounds a single request/response exchange. Default: 1000.
+	TimeoutMS int `yaml:"-" json:"timeout_ms" env:"PICOCLAW_TOOLS_MODBUS_TIMEOUT_MS"`
+	// Devices is the allowlist of Modbus TCP and RTU devices.
+	Devices []ModbusDeviceConfig `yaml:"-" json:"devices,omitempty"`
+}
+
+// ModbusDeviceConfig describes one Modbus device the agent may talk to.
+// Exactly one of Address (TCP) or Port (RTU) must be set.
+type ModbusDeviceConfig struct {
+	// Name is how the agent refers to the device, e.g. "plc1".
+	Name string `json:"name"`
+	// Address is host:port of a Modbus TCP server; the port defaults to 502.
+	Address string `json:"address,omitempty"`
+	// Port is a serial port for Modbus RTU, e.g. /dev/ttyUSB0.
+	Port     string `json:"port,omitempty"`
+	Baud     int    `json:"baud,omitempty"`
+	DataBits int    `json:"data_bits,omitempty"`
+	Parity   string `json:"parity,omitempty"`
+	StopBits int    `json:"stop_bits,omitempty"`
+	// UnitIDs limits the unit (slave) IDs that may be addressed; empty
+	// allows only the default unit 1.
+	UnitIDs []int `json:"unit_ids,omitempty"`
+	// WritableRanges lists "coil:<from>-<to>" or "register:<from>-<to>"
+	// address ranges that may be written. Empty makes the device read-only.
+	WritableRanges []string `json:"writable_ranges,omitempty"`
+}
+
+// CANBusConfig controls the canbus tool. Only the SocketCAN interfaces listed
+// here can be used.
+type CANBusConfig struct {
+	ToolConfig `yaml:"-" envPrefix:"PICOCLAW_TOOLS_CANBUS_"`
+	// Interfaces is the allowlist of CAN interfaces.
+	Interfaces []CANBusInterfaceConfig `yaml:"-" json:"interfaces,omitempty"`
+}
+
+// CANBusInterfaceConfig describes one SocketCAN interface.
+type CANBusInterfaceConfig struct {
+	// Name is the network interface, e.g. "can0" or "vcan0".
+	Name string `json:"name"`
+	// SendIDs lists CAN IDs or ID ranges ("0x123", "0x100-0x1ff") that may
+	// be sent. Empty makes the interface receive-only.
+	SendIDs []string `json:"send_ids,omitempty"`
+}
+
 type ReadFileToolConfig struct {
 	Enabled         bool   `json:"enabled"`
 	Mode            string `json:"mode"`
@@ -1127,16 +1467,28 @@ type ToolsConfig struct {
 	Exec            ExecConfig         `json:"exec"              yaml:"-"`
 	Skills          SkillsToolsConfig  `json:"skills"            yaml:"skills,omitempty"`
 	MediaCleanup    MediaCleanupConfig `json:"media_cleanup"     yaml:"-"`
+	Checkpoints     CheckpointsConfig  `json:"checkpoints"       yaml:"-"`
 	MCP             MCPConfig          `json:"mcp"               yaml:"-"`
 	AppendFile      ToolConfig         `json:"append_file"       yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_APPEND_FILE_"`
+	ApplyPatch      ToolConfig         `json:"apply_patch"       yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_APPLY_PATCH_"`
+	CANBus          CANBusConfig       `json:"canbus"            yaml:"-"`
 	EditFile        ToolConfig         `json:"edit_file"         yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_EDIT_FILE_"`
 	FindSkills      ToolConfig         `json:"find_skills"       yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_FIND_SKILLS_"`
+	GlobFiles       ToolConfig         `json:"glob_files"        yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_GLOB_FILES_"`
+	GPIO            ToolConfig         `json:"gpio"              yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_GPIO_"`
+	GrepFiles       ToolConfig         `json:"grep_files"        yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_GREP_FILES_"`
+	Home            HomeConfig         `json:"home"              yaml:"home,omitempty"`
+	HTTPRequest     HTTPRequestConfig  `json:"http_request"      yaml:"http_request,omitempty"`
 	I2C             ToolConfig         `json:"i2c"               yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_I2C_"`
 	InstallSkill    ToolConfig         `json:"install_skill"     yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_INSTALL_SKILL_"`
 	ListDir         ToolConfig         `json:"list_dir"          yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_LIST_DIR_"`
 	LoadImage       ToolConfig         `json:"load_image"        yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_LOAD_IMAGE_"`
 	Message         MessageToolsConfig `json:"message"           yaml:"-"`
+	Modbus          ModbusConfig       `json:"modbus"            yaml:"-"`
+	OneWire         ToolConfig         `json:"onewire"           yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_ONEWIRE_"`
+	PWM             ToolConfig         `json:"pwm"               yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_PWM_"`
 	ReadFile        ReadFileToolConfig `json:"read_file"         yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_READ_FILE_"`
+	RunScript       RunScriptConfig    `json:"run_script"        yaml:"-"`
 	Serial          ToolConfig         `json:"serial"            yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SERIAL_"`
 	SendFile        ToolConfig         `json:"send_file"         yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SEND_FILE_"`
 	SendTTS         ToolConfig         `json:"send_tts"          yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SEND_TTS_"`
@@ -1274,6 +1626,9 @@ type MCPServerConfig struct {
 	URL string `json:"url,omitempty"`
 	// Headers are HTTP headers to send with requests (sse/http only)
 	Headers map[string]string `json:"headers,omitempty"`
+	// IsolationProfile selects the isolation resource profile for the server
+	// process (stdio only); empty uses isolation.default_profile.
+	IsolationProfile string `json:"isolation_profile,omitempty"`
 }
 
 // MCPConfig defines configuration for all MCP servers
@@ -1823,6 +2178,10 @@ func expandMultiKeyModels(models []*ModelConfig) []*ModelConfig {
 				ExtraBody:           m.ExtraBody,
 				CustomHeaders:       m.CustomHeaders,
 				UserAgent:           m.UserAgent,
+				Vision:              m.Vision,
+				ContextWindow:       m.ContextWindow,
+				InputCost:           m.InputCost,
+				OutputCost:          m.OutputCost,
 				isVirtual:           true,
 			}
 			expanded = append(expanded, additionalEntry)
@@ -1848,6 +2207,10 @@ func expandMultiKeyModels(models []*ModelConfig) []*ModelConfig {
 			ExtraBody:           m.ExtraBody,
 			CustomHeaders:       m.CustomHeaders,
 			UserAgent:           m.UserAgent,
+			Vision:              m.Vision,
+			ContextWindow:       m.ContextWindow,
+			InputCost:           m.InputCost,
+			OutputCost:          m.OutputCost,
 			APIKeys:             SimpleSecureStrings(keys[0]),
 		}
 
@@ -1876,12 +2239,28 @@ func (t *ToolsConfig) IsToolEnabled(name string) bool {
 		return t.Skills.Enabled
 	case "media_cleanup":
 		return t.MediaCleanup.Enabled
+	case "checkpoints":
+		return t.Checkpoints.Enabled
 	case "append_file":
 		return t.AppendFile.Enabled
+	case "apply_patch":
+		return t.ApplyPatch.Enabled
+	case "canbus":
+		return t.CANBus.Enabled
 	case "edit_file":
 		return t.EditFile.Enabled
 	case "find_skills":
 		return t.FindSkills.Enabled
+	case "glob_files":
+		return t.GlobFiles.Enabled
+	case "gpio":
+		return t.GPIO.Enabled
+	case "grep_files":
+		return t.GrepFiles.Enabled
+	case "home":
+		return t.Home.Enabled
+	case "http_request":
+		return t.HTTPRequest.Enabled
 	case "i2c":
 		return t.I2C.Enabled
 	case "install_skill":
@@ -1892,8 +2271,16 @@ func (t *ToolsConfig) IsToolEnabled(name string) bool {
 		return t.LoadImage.Enabled
 	case "message":
 		return t.Message.Enabled
+	case "modbus":
+		return t.Modbus.Enabled
+	case "onewire":
+		return t.OneWire.Enabled
+	case "pwm":
+		return t.PWM.Enabled
 	case "read_file":
 		return t.ReadFile.Enabled
+	case "run_script":
+		return t.RunScript.Enabled
 	case "serial":
 		return t.Serial.Enabled
 	case "spawn":
@@ -229,6 +229,7 @@ type Channel struct {
 	GroupTrigger       GroupTriggerConfig  `json:"group_trigger,omitempty" yaml:"-"`
 	Typing             TypingConfig        `json:"typing,omitempty"        yaml:"-"`
 	Placeholder        PlaceholderConfig   `json:"placeholder,omitempty"   yaml:"-"`
+	InboundLimit       InboundLimitConfig  `json:"inbound_limit,omitempty" yaml:"-"`
 	Settings           RawNode             `json:"settings,omitzero"       yaml:"settings,omitempty"`
 	extend             any
 }
@@ -546,6 +547,7 @@ var BaseFieldNames = map[string]struct{}{
 	"group_trigger":        {},
 	"typing":               {},
 	"placeholder":          {},
+	"inbound_limit":        {},
 }
 
 // ─── Internal helpers ───
@@ -442,6 +442,16 @@ func TestEvolutionConfig_ModeSemantics(t *testing.T) {
 			wantRunsCold:  true,
 			wantAutoApply: true,
 		},
+		{
+			name: "apply with human approval waits for review",
+			cfg: EvolutionConfig{
+				Enabled:  true,
+				Mode:     "apply",
+				Approval: "human",
+			},
+			wantRunsCold:  true,
+			wantAutoApply: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -472,6 +482,64 @@ func TestEvolutionConfig_ColdPathTriggerMode(t *testing.T) {
 	assert.False(t, manual.RunsColdPathAutomatically())
 }
 
+func TestEvolutionConfig_EffectiveRollbackMinUses(t *testing.T) {
+	assert.Equal(t, 5, (EvolutionConfig{Enabled: true}).EffectiveRollbackMinUses())
+	assert.Equal(t, 12, (EvolutionConfig{Enabled: true, RollbackMinUses: 12}).EffectiveRollbackMinUses())
+	assert.Equal(t, 0, (EvolutionConfig{Enabled: true, RollbackMinUses: -1}).EffectiveRollbackMinUses())
+	assert.Equal(t, 0, (EvolutionConfig{RollbackMinUses: 12}).EffectiveRollbackMinUses())
+}
+
+func TestAgentsConfig_FindTeamAppliesDefaults(t *testing.T) {
+	agents := AgentsConfig{Teams: []TeamConfig{
+		{ID: "research", Members: []string{"writer", "searcher"}},
+		{ID: "ops", Members: []string{"a", "b"}, Lead: "b", MaxRounds: 9, DoneKey: "answer"},
+	}}
+
+	team, ok := agents.FindTeam("Research")
+	assert.True(t, ok)
+	assert.Equal(t, "writer", team.EffectiveLead())
+	assert.Equal(t, DefaultTeamMaxRounds, team.EffectiveMaxRounds())
+	assert.Equal(t, DefaultTeamDoneKey, team.EffectiveDoneKey())
+
+	team, ok = agents.FindTeam("ops")
+	assert.True(t, ok)
+	assert.Equal(t, "b", team.EffectiveLead())
+	assert.Equal(t, 9, team.EffectiveMaxRounds())
+	assert.Equal(t, "answer", team.EffectiveDoneKey())
+
+	_, ok = agents.FindTeam("missing")
+	assert.False(t, ok)
+}
+
+func TestSwarmConfig_SecretLoadsFromSecurityFile(t *testing.T) {
+	dir := t.TempDir()
+	secPath := filepath.Join(dir, SecurityConfigFile)
+	if err := os.WriteFile(secPath, []byte("swarm:\n  secret: shared-secret\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+
+	cfg := DefaultConfig()
+	if err := json.Unmarshal([]byte(`{"swarm":{"enabled":true,"instance_id":"pi"}}`), cfg); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+	if err := loadSecurityConfig(cfg, secPath); err != nil {
+		t.Fatalf("loadSecurityConfig() error: %v", err)
+	}
+
+	assert.True(t, cfg.Swarm.Enabled)
+	assert.Equal(t, "pi", cfg.Swarm.EffectiveInstanceID())
+	assert.Equal(t, "shared-secret", cfg.Swarm.Secret.String())
+	assert.Equal(t, DefaultSwarmDiscoveryPort, cfg.Swarm.EffectiveDiscoveryPort())
+	assert.Equal(t, DefaultSwarmAnnounceIntervalSeconds, cfg.Swarm.EffectiveAnnounceIntervalSeconds())
+	assert.Equal(t, DefaultSwarmTimeoutSeconds, cfg.Swarm.EffectiveTimeoutSeconds())
+
+	data, err := json.Marshal(cfg.Swarm)
+	if err != nil {
+		t.Fatalf("Marshal() error: %v", err)
+	}
+	assert.NotContains(t, string(data), "shared-secret")
+}
+
 func TestEvolutionConfig_NewThresholdNamesPreferLegacyAliases(t *testing.T) {
 	cfg := EvolutionConfig{MinTaskCount: 4, MinSuccessRatio: 0.9, MinCaseCount: 1, MinSuccessRate: 0.2}
 	assert.Equal(t, 4, cfg.EffectiveMinTaskCount())
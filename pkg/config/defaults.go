@@ -324,6 +324,14 @@ func DefaultConfig() *Config {
 				MaxAge:   30,
 				Interval: 5,
 			},
+			Checkpoints: CheckpointsConfig{
+				ToolConfig: ToolConfig{
+					Enabled: true,
+				},
+				MaxTotalMB: 32,
+				MaxFileKB:  1024,
+				MaxCount:   100,
+			},
 			Web: WebToolsConfig{
 				ToolConfig: ToolConfig{
 					Enabled: true,
@@ -442,12 +450,36 @@ func DefaultConfig() *Config {
 			AppendFile: ToolConfig{
 				Enabled: true,
 			},
+			ApplyPatch: ToolConfig{
+				Enabled: true,
+			},
 			EditFile: ToolConfig{
 				Enabled: true,
 			},
 			FindSkills: ToolConfig{
 				Enabled: true,
 			},
+			GlobFiles: ToolConfig{
+				Enabled: true,
+			},
+			GrepFiles: ToolConfig{
+				Enabled: true,
+			},
+			Home: HomeConfig{
+				TimeoutSeconds: 10,
+			},
+			HTTPRequest: HTTPRequestConfig{
+				TimeoutSeconds:   30,
+				MaxResponseBytes: 1 << 20,
+			},
+			CANBus: CANBusConfig{
+				ToolConfig: ToolConfig{
+					Enabled: false, // Hardware tool - Linux only
+				},
+			},
+			GPIO: ToolConfig{
+				Enabled: false, // Hardware tool - Linux only
+			},
 			I2C: ToolConfig{
 				Enabled: false, // Hardware tool - Linux only
 			},
@@ -466,11 +498,31 @@ func DefaultConfig() *Config {
 				},
 				MediaEnabled: false,
 			},
+			Modbus: ModbusConfig{
+				ToolConfig: ToolConfig{
+					Enabled: false,
+				},
+				TimeoutMS: 1000,
+			},
+			OneWire: ToolConfig{
+				Enabled: false, // Hardware tool - Linux only
+			},
+			PWM: ToolConfig{
+				Enabled: false, // Hardware tool - Linux only
+			},
 			ReadFile: ReadFileToolConfig{
 				Enabled:         true,
 				Mode:            ReadFileModeBytes,
 				MaxReadFileSize: 64 * 1024, // 64KB
 			},
+			RunScript: RunScriptConfig{
+				ToolConfig: ToolConfig{
+					Enabled: true,
+				},
+				MaxSteps:       10_000_000,
+				MaxMemoryMB:    32,
+				TimeoutSeconds: 10,
+			},
 			Serial: ToolConfig{
 				Enabled: false, // Hardware tool - requires host serial ports
 			},
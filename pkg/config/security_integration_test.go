@@ -646,3 +646,75 @@ skills:
 		assert.Equal(t, "legacy-github-token", registry.AuthToken.String())
 	})
 }
+
+func TestSecurityConfigHTTPRequestCredentials(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.json")
+	configContent := `{
+  "version": 3,
+  "tools": {
+    "http_request": {
+      "enabled": true,
+      "hosts": [{"host": "ha.local:8123", "credentials": ["home_assistant"]}]
+    }
+  }
+}`
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0o644))
+	securityContent := `http_request:
+  credentials:
+    home_assistant: "ha-token-from-security-yml"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, SecurityConfigFile), []byte(securityContent), 0o600))
+
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	cred := cfg.Tools.HTTPRequest.Credentials["home_assistant"]
+	assert.Equal(t, "ha-token-from-security-yml", cred.String())
+	require.Len(t, cfg.Tools.HTTPRequest.Hosts, 1)
+	assert.Equal(t, "ha.local:8123", cfg.Tools.HTTPRequest.Hosts[0].Host)
+
+	// Saving keeps the secret out of config.json and loads back intact.
+	require.NoError(t, SaveConfig(configPath, cfg))
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "ha-token-from-security-yml")
+
+	cfg, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	cred = cfg.Tools.HTTPRequest.Credentials["home_assistant"]
+	assert.Equal(t, "ha-token-from-security-yml", cred.String())
+}
+
+func TestSecurityConfigHomeToken(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.json")
+	configContent := `{
+  "version": 3,
+  "tools": {
+    "home": {
+      "enabled": true,
+      "url": "http://ha.local:8123",
+      "allowed_domains": ["light"]
+    }
+  }
+}`
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0o644))
+	securityContent := `home:
+  token: "ha-long-lived-token"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, SecurityConfigFile), []byte(securityContent), 0o600))
+
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "ha-long-lived-token", cfg.Tools.Home.Token.String())
+	assert.Equal(t, []string{"light"}, cfg.Tools.Home.AllowedDomains)
+
+	require.NoError(t, SaveConfig(configPath, cfg))
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "ha-long-lived-token")
+
+	cfg, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "ha-long-lived-token", cfg.Tools.Home.Token.String())
+}
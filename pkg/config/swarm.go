@@ -0,0 +1,83 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+const (
+	// DefaultSwarmDiscoveryPort is the UDP port swarm announcements are
+	// broadcast on.
+	DefaultSwarmDiscoveryPort = 18791
+	// DefaultSwarmAnnounceIntervalSeconds is how often an instance announces
+	// itself and refreshes its static peers.
+	DefaultSwarmAnnounceIntervalSeconds = 30
+	// DefaultSwarmTimeoutSeconds bounds one remote delegation.
+	DefaultSwarmTimeoutSeconds = 600
+)
+
+// SwarmConfig lets gateways on the same network find each other and run
+// delegated tasks for one another. Remote agents are addressed as
+// agent@instance by the delegate tool.
+type SwarmConfig struct {
+	Enabled bool `yaml:"-" json:"enabled" env:"PICOCLAW_SWARM_ENABLED"`
+	// InstanceID names this instance in agent@instance targets. Default: the
+	// host name.
+	InstanceID string `yaml:"-" json:"instance_id,omitempty" env:"PICOCLAW_SWARM_INSTANCE_ID"`
+	// Secret is shared by every instance of the swarm. Requests and
+	// announcements are signed with it. Stored in .security.yml.
+	Secret SecureString `yaml:"secret,omitempty" json:"secret,omitzero" env:"PICOCLAW_SWARM_SECRET"`
+	// DiscoveryPort is the UDP port announcements are broadcast on.
+	// Default: 18791.
+	DiscoveryPort int `yaml:"-" json:"discovery_port,omitempty" env:"PICOCLAW_SWARM_DISCOVERY_PORT"`
+	// AnnounceIntervalSeconds is how often this instance announces itself.
+	// A peer that stays silent for three intervals is dropped. Default: 30.
+	AnnounceIntervalSeconds int `yaml:"-" json:"announce_interval_seconds,omitempty" env:"PICOCLAW_SWARM_ANNOUNCE_INTERVAL_SECONDS"`
+	// AdvertiseURL is the gateway base URL peers send requests to. Default:
+	// http://<first LAN IPv4 address>:<gateway port>.
+	AdvertiseURL string `yaml:"-" json:"advertise_url,omitempty" env:"PICOCLAW_SWARM_ADVERTISE_URL"`
+	// ExposeAgents limits the local agents peers may delegate to. Empty
+	// exposes every agent.
+	ExposeAgents []string `yaml:"-" json:"expose_agents,omitempty"`
+	// Peers are gateway base URLs polled directly, for instances that
+	// broadcasts do not reach.
+	Peers []string `yaml:"-" json:"peers,omitempty"`
+	// TimeoutSeconds bounds one remote delegation. Default: 600.
+	TimeoutSeconds int `yaml:"-" json:"timeout_seconds,omitempty" env:"PICOCLAW_SWARM_TIMEOUT_SECONDS"`
+}
+
+// EffectiveInstanceID returns the configured instance id, or the host name.
+func (c SwarmConfig) EffectiveInstanceID() string {
+	if id := strings.TrimSpace(c.InstanceID); id != "" {
+		return id
+	}
+	if host, err := os.Hostname(); err == nil && strings.TrimSpace(host) != "" {
+		return strings.TrimSpace(host)
+	}
+	return "picoclaw"
+}
+
+// EffectiveDiscoveryPort returns the configured discovery port, or 18791.
+func (c SwarmConfig) EffectiveDiscoveryPort() int {
+	if c.DiscoveryPort <= 0 {
+		return DefaultSwarmDiscoveryPort
+	}
+	return c.DiscoveryPort
+}
+
+// EffectiveAnnounceIntervalSeconds returns the configured announce interval,
+// or 30.
+func (c SwarmConfig) EffectiveAnnounceIntervalSeconds() int {
+	if c.AnnounceIntervalSeconds <= 0 {
+		return DefaultSwarmAnnounceIntervalSeconds
+	}
+	return c.AnnounceIntervalSeconds
+}
+
+// EffectiveTimeoutSeconds returns the configured delegation timeout, or 600.
+func (c SwarmConfig) EffectiveTimeoutSeconds() int {
+	if c.TimeoutSeconds <= 0 {
+		return DefaultSwarmTimeoutSeconds
+	}
+	return c.TimeoutSeconds
+}
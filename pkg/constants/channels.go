@@ -9,8 +9,25 @@ var internalChannels = map[string]struct{}{
 	"subagent": {},
 }
 
+// serviceChannels carry turns that another service runs on an agent, such as
+// swarm delegations from other instances. Like internal channels they have no
+// chat to deliver to, but they are not trusted with internal-only tools.
+var serviceChannels = map[string]struct{}{
+	"swarm": {},
+}
+
 // IsInternalChannel returns true if the channel is an internal channel.
 func IsInternalChannel(channel string) bool {
 	_, found := internalChannels[channel]
 	return found
 }
+
+// IsDeliverableChannel returns true if messages can be sent to the channel,
+// which also makes it eligible as the last active channel.
+func IsDeliverableChannel(channel string) bool {
+	if channel == "" || IsInternalChannel(channel) {
+		return false
+	}
+	_, service := serviceChannels[channel]
+	return !service
+}
@@ -0,0 +1,24 @@
+package constants
+
+import "testing"
+
+func TestChannelKinds(t *testing.T) {
+	cases := []struct {
+		channel     string
+		internal    bool
+		deliverable bool
+	}{
+		{channel: "telegram", deliverable: true},
+		{channel: "cli", internal: true},
+		{channel: "swarm"},
+		{channel: ""},
+	}
+	for _, tc := range cases {
+		if got := IsInternalChannel(tc.channel); got != tc.internal {
+			t.Errorf("IsInternalChannel(%q) = %v, want %v", tc.channel, got, tc.internal)
+		}
+		if got := IsDeliverableChannel(tc.channel); got != tc.deliverable {
+			t.Errorf("IsDeliverableChannel(%q) = %v, want %v", tc.channel, got, tc.deliverable)
+		}
+	}
+}
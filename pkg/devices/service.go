@@ -123,7 +123,7 @@ func (s *Service) sendNotification(ev *events.DeviceEvent) {
 	}
 
 	platform, userID := parseLastChannel(lastChannel)
-	if platform == "" || userID == "" || constants.IsInternalChannel(platform) {
+	if userID == "" || !constants.IsDeliverableChannel(platform) {
 		return
 	}
 
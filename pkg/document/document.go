@@ -0,0 +1,371 @@
+// Package document extracts readable text from PDF, Office (DOCX, XLSX,
+// PPTX), EPUB and CSV files without external tools, so the agent can read
+// attachments and downloads that are not plain text.
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Format identifies a supported document type.
+type Format string
+
+const (
+	FormatPDF  Format = "pdf"
+	FormatDOCX Format = "docx"
+	FormatXLSX Format = "xlsx"
+	FormatPPTX Format = "pptx"
+	FormatEPUB Format = "epub"
+	FormatCSV  Format = "csv"
+)
+
+// MaxSize bounds the documents accepted for extraction, and the data a
+// single archive member or PDF stream may expand to.
+const MaxSize = 64 << 20
+
+var (
+	// ErrUnsupported is returned for data that is not a supported document.
+	ErrUnsupported = errors.New("unsupported document format")
+	// ErrEncrypted is returned for password-protected documents.
+	ErrEncrypted = errors.New("document is encrypted")
+)
+
+var extFormats = map[string]Format{
+	".pdf":  FormatPDF,
+	".docx": FormatDOCX,
+	".docm": FormatDOCX,
+	".xlsx": FormatXLSX,
+	".xlsm": FormatXLSX,
+	".pptx": FormatPPTX,
+	".pptm": FormatPPTX,
+	".epub": FormatEPUB,
+	".csv":  FormatCSV,
+	".tsv":  FormatCSV,
+}
+
+var mimeFormats = map[string]Format{
+	"application/pdf":   FormatPDF,
+	"application/x-pdf": FormatPDF,
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   FormatDOCX,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         FormatXLSX,
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": FormatPPTX,
+	"application/epub+zip":      FormatEPUB,
+	"text/csv":                  FormatCSV,
+	"text/tab-separated-values": FormatCSV,
+}
+
+// Detect returns the document format of a file from its name, MIME type or
+// leading bytes, or "" when it is not a supported document. Any argument may
+// be empty.
+func Detect(name, mimeType string, head []byte) Format {
+	if mimeType != "" {
+		if mt, _, err := mime.ParseMediaType(mimeType); err == nil {
+			if f, ok := mimeFormats[strings.ToLower(mt)]; ok {
+				return f
+			}
+		}
+	}
+	if f, ok := extFormats[strings.ToLower(filepath.Ext(name))]; ok {
+		return f
+	}
+	if bytes.HasPrefix(head, []byte("%PDF-")) {
+		return FormatPDF
+	}
+	return ""
+}
+
+// Binary reports whether the format is a binary container that cannot be
+// read as text without extraction.
+func (f Format) Binary() bool {
+	return f != "" && f != FormatCSV
+}
+
+// Unit names the sections of a format: pages, sheets, slides or chapters.
+func (f Format) Unit() string {
+	switch f {
+	case FormatPDF:
+		return "page"
+	case FormatXLSX, FormatCSV:
+		return "sheet"
+	case FormatPPTX:
+		return "slide"
+	case FormatEPUB:
+		return "chapter"
+	default:
+		return "section"
+	}
+}
+
+// Section is one page, sheet, slide or chapter of a document.
+type Section struct {
+	Title string
+	Text  string
+}
+
+// Document is the extracted text of a file, split into sections.
+type Document struct {
+	Format   Format
+	Sections []Section
+}
+
+// Extract converts data of the given format to text. When format is empty
+// it is detected from the content. A parser panic on a malformed file is
+// returned as an error.
+func Extract(data []byte, format Format) (doc *Document, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			doc, err = nil, fmt.Errorf("%s: malformed document: %v", format, r)
+		}
+	}()
+	if len(data) > MaxSize {
+		return nil, fmt.Errorf("document exceeds %d bytes", MaxSize)
+	}
+	if format == "" {
+		format = detectContent(data)
+	}
+	var sections []Section
+	switch format {
+	case FormatPDF:
+		sections, err = extractPDF(data)
+	case FormatDOCX:
+		sections, err = withZip(data, extractDOCX)
+	case FormatXLSX:
+		sections, err = withZip(data, extractXLSX)
+	case FormatPPTX:
+		sections, err = withZip(data, extractPPTX)
+	case FormatEPUB:
+		sections, err = withZip(data, extractEPUB)
+	case FormatCSV:
+		sections, err = extractCSV(data)
+	default:
+		return nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", format, err)
+	}
+	return &Document{Format: format, Sections: sections}, nil
+}
+
+// detectContent recognises PDFs and the OOXML/EPUB zip layouts by content.
+func detectContent(data []byte) Format {
+	if bytes.HasPrefix(data, []byte("%PDF-")) {
+		return FormatPDF
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return ""
+	}
+	for _, f := range zr.File {
+		switch f.Name {
+		case "word/document.xml":
+			return FormatDOCX
+		case "xl/workbook.xml":
+			return FormatXLSX
+		case "ppt/presentation.xml":
+			return FormatPPTX
+		case "META-INF/container.xml":
+			return FormatEPUB
+		}
+	}
+	return ""
+}
+
+func withZip(data []byte, fn func(*zip.Reader) ([]Section, error)) ([]Section, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("not a valid archive: %w", err)
+	}
+	return fn(zr)
+}
+
+// readZipFile reads an archive member, bounded by MaxSize.
+func readZipFile(zr *zip.Reader, name string) ([]byte, error) {
+	name = strings.TrimPrefix(name, "/")
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(io.LimitReader(rc, MaxSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > MaxSize {
+			return nil, fmt.Errorf("%s expands beyond %d bytes", name, MaxSize)
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("%s: %w", name, errMissingPart)
+}
+
+var errMissingPart = errors.New("missing archive member")
+
+// Select resolves a range spec such as "1-3,5", "7-" or a sheet name to
+// section indices (0-based, in document order). An empty spec selects all.
+func (d *Document) Select(spec string) ([]int, error) {
+	n := len(d.Sections)
+	if strings.TrimSpace(spec) == "" {
+		all := make([]int, n)
+		for i := range all {
+			all[i] = i
+		}
+		return all, nil
+	}
+
+	picked := make([]bool, n)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		lo, hi, ok := parseRange(part, n)
+		if !ok {
+			idx := d.sectionByTitle(part)
+			if idx < 0 {
+				return nil, fmt.Errorf("no %s matches %q", d.Format.Unit(), part)
+			}
+			lo, hi = idx+1, idx+1
+		}
+		if lo < 1 || hi > n || lo > hi {
+			return nil, fmt.Errorf("%s range %q is outside 1-%d", d.Format.Unit(), part, n)
+		}
+		for i := lo; i <= hi; i++ {
+			picked[i-1] = true
+		}
+	}
+	var out []int
+	for i, ok := range picked {
+		if ok {
+			out = append(out, i)
+		}
+	}
+	return out, nil
+}
+
+func parseRange(part string, n int) (int, int, bool) {
+	loStr, hiStr, isRange := strings.Cut(part, "-")
+	lo, err := strconv.Atoi(strings.TrimSpace(loStr))
+	if err != nil {
+		return 0, 0, false
+	}
+	if !isRange {
+		return lo, lo, true
+	}
+	if strings.TrimSpace(hiStr) == "" {
+		return lo, n, true
+	}
+	hi, err := strconv.Atoi(strings.TrimSpace(hiStr))
+	if err != nil {
+		return 0, 0, false
+	}
+	return lo, hi, true
+}
+
+func (d *Document) sectionByTitle(title string) int {
+	for i, s := range d.Sections {
+		if s.Title != "" && strings.EqualFold(s.Title, title) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Heading labels section i, e.g. "page 3 of 10" or "sheet 2 of 3: Sales".
+func (d *Document) Heading(i int) string {
+	h := fmt.Sprintf("%s %d of %d", d.Format.Unit(), i+1, len(d.Sections))
+	if t := d.Sections[i].Title; t != "" {
+		h += ": " + t
+	}
+	return h
+}
+
+// Render joins the selected sections, each under a heading when the
+// document has more than one section.
+func (d *Document) Render(indices []int) string {
+	var sb strings.Builder
+	for _, i := range indices {
+		d.writeSection(&sb, i)
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+func (d *Document) writeSection(sb *strings.Builder, i int) {
+	if len(d.Sections) > 1 {
+		fmt.Fprintf(sb, "--- %s ---\n", d.Heading(i))
+	}
+	sb.WriteString(strings.TrimSpace(d.Sections[i].Text))
+	sb.WriteString("\n\n")
+}
+
+// RenderBudget renders the selected sections until maxBytes is reached. It
+// returns the text, the number of sections fully included and whether the
+// last included section was cut short.
+func (d *Document) RenderBudget(indices []int, maxBytes int) (string, int, bool) {
+	var sb strings.Builder
+	for n, i := range indices {
+		var part strings.Builder
+		d.writeSection(&part, i)
+		if sb.Len()+part.Len() <= maxBytes {
+			sb.WriteString(part.String())
+			continue
+		}
+		if n == 0 {
+			// Always return something: cut the first section at a rune
+			// boundary.
+			return strings.TrimSpace(truncateUTF8(part.String(), maxBytes)), 0, true
+		}
+		return strings.TrimSpace(sb.String()), n, false
+	}
+	return strings.TrimSpace(sb.String()), len(indices), false
+}
+
+// Empty reports whether no text could be extracted, as with scanned PDFs.
+func (d *Document) Empty() bool {
+	for _, s := range d.Sections {
+		if strings.TrimSpace(s.Text) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && n < len(s) && s[n]&0xC0 == 0x80 {
+		n--
+	}
+	return s[:n]
+}
+
+// FormatRanges compacts section indices (0-based) to a 1-based spec such
+// as "1-3,5".
+func FormatRanges(indices []int) string {
+	var parts []string
+	for i := 0; i < len(indices); {
+		j := i
+		for j+1 < len(indices) && indices[j+1] == indices[j]+1 {
+			j++
+		}
+		if i == j {
+			parts = append(parts, strconv.Itoa(indices[i]+1))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", indices[i]+1, indices[j]+1))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,276 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func buildZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("zip.Create(%q) error = %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("zip write error = %v", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip.Close() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+// buildPDF assembles a PDF from object bodies numbered from 1. A body given
+// as a [2]string is a stream: dictionary entries and data.
+func buildPDF(objects ...any) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	for i, obj := range objects {
+		fmt.Fprintf(&buf, "%d 0 obj\n", i+1)
+		switch o := obj.(type) {
+		case string:
+			buf.WriteString(o)
+		case [2]string:
+			fmt.Fprintf(&buf, "<< %s /Length %d >>\nstream\n%s\nendstream", o[0], len(o[1]), o[1])
+		}
+		buf.WriteString("\nendobj\n")
+	}
+	buf.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return buf.Bytes()
+}
+
+func deflate(s string) string {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	_, _ = zw.Write([]byte(s))
+	_ = zw.Close()
+	return buf.String()
+}
+
+const testCMap = `/CIDInit /ProcSet findresource begin
+12 dict begin
+begincmap
+1 begincodespacerange
+<0000> <FFFF>
+endcodespacerange
+2 beginbfchar
+<0001> <0048>
+<0002> <00E9>
+endbfchar
+1 beginbfrange
+<0003> <0005> <006C>
+endbfrange
+endcmap
+CMapName currentdict /CMap defineresource pop
+end
+end`
+
+func testPDF() []byte {
+	return buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /Resources << /Font << /F1 5 0 R /F2 6 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 7 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /Contents [8 0 R] >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding << /Differences [150 /endash] >> >>",
+		"<< /Type /Font /Subtype /Type0 /BaseFont /X /Encoding /Identity-H /ToUnicode 9 0 R >>",
+		[2]string{"", "BT /F1 12 Tf 72 720 Td (Quarterly report) Tj 0 -14 Td [(Revenue) -250 (up \\226 12%)] TJ ET"},
+		[2]string{"/Filter /FlateDecode", deflate("BT /F2 10 Tf 1 0 0 1 72 700 Tm <00010002000300040005> Tj ET")},
+		[2]string{"/Filter /FlateDecode", deflate(testCMap)},
+	)
+}
+
+func TestExtractPDF(t *testing.T) {
+	data := testPDF()
+	if got := Detect("scan", "", data); got != FormatPDF {
+		t.Fatalf("Detect() = %q, want pdf", got)
+	}
+	doc, err := Extract(data, "")
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if len(doc.Sections) != 2 {
+		t.Fatalf("sections = %d, want 2", len(doc.Sections))
+	}
+	if got, want := doc.Sections[0].Text, "Quarterly report\nRevenue up – 12%"; got != want {
+		t.Fatalf("page 1 = %q, want %q", got, want)
+	}
+	if got, want := doc.Sections[1].Text, "Hélmn"; got != want {
+		t.Fatalf("page 2 = %q, want %q", got, want)
+	}
+
+	text := doc.Render([]int{1})
+	if !strings.HasPrefix(text, "--- page 2 of 2 ---\nHélmn") {
+		t.Fatalf("Render() = %q", text)
+	}
+}
+
+func TestExtractPDF_Encrypted(t *testing.T) {
+	data := bytes.Replace(testPDF(), []byte("<< /Root 1 0 R >>"), []byte("<< /Root 1 0 R /Encrypt 10 0 R >>"), 1)
+	if _, err := Extract(data, FormatPDF); err == nil || !strings.Contains(err.Error(), ErrEncrypted.Error()) {
+		t.Fatalf("Extract() error = %v, want encrypted", err)
+	}
+}
+
+func TestExtractDOCX(t *testing.T) {
+	data := buildZip(t, map[string]string{
+		"word/document.xml": `<w:document xmlns:w="w"><w:body>
+<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Plan</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="1"/></w:numPr></w:pPr><w:r><w:t>step one</w:t></w:r></w:p>
+<w:tbl><w:tr><w:tc><w:p><w:r><w:t>Name</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Qty</w:t></w:r></w:p></w:tc></w:tr>
+<w:tr><w:tc><w:p><w:r><w:t>bolt|nut</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>4</w:t></w:r></w:p></w:tc></w:tr></w:tbl>
+</w:body></w:document>`,
+	})
+	doc, err := Extract(data, "")
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	want := "# Plan\n\n  - step one\n\n| Name | Qty |\n| --- | --- |\n| bolt\\|nut | 4 |"
+	if got := doc.Render([]int{0}); got != want {
+		t.Fatalf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestExtractXLSX(t *testing.T) {
+	data := buildZip(t, map[string]string{
+		"xl/workbook.xml": `<workbook xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Sales" r:id="rId1"/><sheet name="Notes" r:id="rId2"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Target="/xl/worksheets/sheet2.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst><si><t>Region</t></si><si><r><t>To</t></r><r><t>tal</t></r><rPh><t>x</t></rPh></si><si><t>North</t></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData>
+<row><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>
+<row><c r="A2" t="s"><v>2</v></c><c r="B2" t="b"><v>1</v></c><c r="C2"><v>42.5</v></c></row>
+</sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<worksheet><sheetData><row><c r="B1" t="inlineStr"><is><t>todo</t></is></c></row></sheetData></worksheet>`,
+	})
+	doc, err := Extract(data, FormatXLSX)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if len(doc.Sections) != 2 || doc.Sections[0].Title != "Sales" {
+		t.Fatalf("sections = %+v", doc.Sections)
+	}
+	if got, want := doc.Sections[0].Text, "| Region |  | Total |\n| --- | --- | --- |\n| North | TRUE | 42.5 |\n"; got != want {
+		t.Fatalf("sheet 1 = %q, want %q", got, want)
+	}
+	idx, err := doc.Select("notes")
+	if err != nil || len(idx) != 1 || idx[0] != 1 {
+		t.Fatalf("Select(notes) = %v, %v", idx, err)
+	}
+	if !strings.Contains(doc.Render(idx), "--- sheet 2 of 2: Notes ---\n|  | todo |") {
+		t.Fatalf("Render() = %q", doc.Render(idx))
+	}
+}
+
+func TestExtractPPTX(t *testing.T) {
+	data := buildZip(t, map[string]string{
+		"ppt/presentation.xml": `<p:presentation xmlns:p="p" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<p:sldIdLst><p:sldId r:id="rId2"/><p:sldId r:id="rId1"/></p:sldIdLst></p:presentation>`,
+		"ppt/_rels/presentation.xml.rels": `<Relationships><Relationship Id="rId1" Target="slides/slide1.xml"/>
+<Relationship Id="rId2" Target="slides/slide2.xml"/></Relationships>`,
+		"ppt/slides/slide1.xml": `<p:sld xmlns:a="a" xmlns:p="p"><a:p><a:r><a:t>Second</a:t></a:r></a:p></p:sld>`,
+		"ppt/slides/slide2.xml": `<p:sld xmlns:a="a" xmlns:p="p"><a:p><a:r><a:t>Intro</a:t></a:r></a:p><a:p><a:r><a:t>Agenda</a:t></a:r></a:p></p:sld>`,
+	})
+	doc, err := Extract(data, "")
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if len(doc.Sections) != 2 || doc.Sections[0].Title != "Intro" || doc.Sections[0].Text != "Intro\nAgenda" {
+		t.Fatalf("sections = %+v", doc.Sections)
+	}
+}
+
+func TestExtractEPUB(t *testing.T) {
+	data := buildZip(t, map[string]string{
+		"META-INF/container.xml": `<container><rootfiles><rootfile full-path="OEBPS/content.opf"/></rootfiles></container>`,
+		"OEBPS/content.opf": `<package><manifest><item id="c1" href="ch1.xhtml"/><item id="c2" href="text/ch2.xhtml"/></manifest>
+<spine><itemref idref="c1"/><itemref idref="c2"/></spine></package>`,
+		"OEBPS/ch1.xhtml":      `<html><body><h1>Beginnings</h1><p>It was a dark night.</p></body></html>`,
+		"OEBPS/text/ch2.xhtml": `<html><body><h2>Middle</h2><p>Then it rained.</p></body></html>`,
+	})
+	doc, err := Extract(data, FormatEPUB)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if len(doc.Sections) != 2 || doc.Sections[1].Title != "Middle" || !strings.Contains(doc.Sections[0].Text, "dark night") {
+		t.Fatalf("sections = %+v", doc.Sections)
+	}
+}
+
+func TestExtractCSV(t *testing.T) {
+	doc, err := Extract([]byte("\xef\xbb\xbfa;b\n1;\"x;y\"\n"), FormatCSV)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if got, want := doc.Sections[0].Text, "| a | b |\n| --- | --- |\n| 1 | x;y |\n"; got != want {
+		t.Fatalf("csv = %q, want %q", got, want)
+	}
+}
+
+func TestDocumentSelectAndBudget(t *testing.T) {
+	doc := &Document{Format: FormatPDF}
+	for i := 0; i < 5; i++ {
+		doc.Sections = append(doc.Sections, Section{Text: strings.Repeat(fmt.Sprint(i), 10)})
+	}
+	idx, err := doc.Select("4-, 1")
+	if err != nil || FormatRanges(idx) != "1,4-5" {
+		t.Fatalf("Select() = %v, %v", idx, err)
+	}
+	if _, err := doc.Select("6"); err == nil {
+		t.Fatal("Select(6) expected out of range error")
+	}
+
+	text, full, cut := doc.RenderBudget([]int{0, 1, 2}, 70)
+	if full != 2 || cut || strings.Contains(text, "page 3") {
+		t.Fatalf("RenderBudget() = %q, %d, %v", text, full, cut)
+	}
+	_, full, cut = doc.RenderBudget([]int{0}, 10)
+	if full != 0 || !cut {
+		t.Fatalf("RenderBudget(small) = %d, %v", full, cut)
+	}
+}
+
+func TestExtractUnsupported(t *testing.T) {
+	if _, err := Extract([]byte("plain text"), ""); err != ErrUnsupported {
+		t.Fatalf("Extract() error = %v, want ErrUnsupported", err)
+	}
+	if Detect("notes.txt", "text/plain", []byte("hi")) != "" {
+		t.Fatal("Detect() matched plain text")
+	}
+}
+
+func TestExtractPDF_PredictorLimits(t *testing.T) {
+	for _, parms := range []string{
+		"/Predictor 12 /Columns 9223372036854775807 /Colors 3",
+		"/Predictor 12 /Columns 100000000000",
+		"/Predictor 12 /Columns 4 /BitsPerComponent 7",
+		"/Predictor 12 /Columns 4 /Colors 1000",
+		"/Predictor 12 /Columns 1000",
+	} {
+		data := buildPDF(
+			"<< /Type /Catalog /Pages 2 0 R >>",
+			"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+			"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+			[2]string{"/Filter /FlateDecode /DecodeParms << " + parms + " >>", deflate("\x00BT ET")},
+		)
+		f := &pdfFile{}
+		parm := pdfDict{}
+		lex := &pdfLexer{data: []byte("<< " + parms + " >>")}
+		if obj, err := lex.object(); err == nil {
+			parm, _ = obj.(pdfDict)
+		}
+		if _, err := f.applyPredictor([]byte("\x00BT ET"), parm); err == nil {
+			t.Errorf("applyPredictor(%s) error = nil, want rejection", parms)
+		}
+		// Must not panic or exhaust memory.
+		_, _ = Extract(data, FormatPDF)
+	}
+}
@@ -0,0 +1,566 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// extractDOCX returns the body of word/document.xml as one section.
+// Headings are prefixed with "#" and tables rendered as Markdown.
+func extractDOCX(zr *zip.Reader) ([]Section, error) {
+	data, err := readZipFile(zr, "word/document.xml")
+	if err != nil {
+		return nil, err
+	}
+	dec := xml.NewDecoder(bytes.NewReader(data))
+
+	var (
+		out       strings.Builder
+		para      strings.Builder
+		heading   int
+		tables    []*tableBuilder
+		inText    bool
+		listDepth = -1
+	)
+	flushPara := func() {
+		text := strings.TrimSpace(para.String())
+		para.Reset()
+		if len(tables) > 0 {
+			tables[len(tables)-1].appendCell(text)
+		} else if text != "" {
+			switch {
+			case heading > 0:
+				out.WriteString(strings.Repeat("#", heading) + " ")
+			case listDepth >= 0:
+				out.WriteString(strings.Repeat("  ", listDepth) + "- ")
+			}
+			out.WriteString(text)
+			out.WriteString("\n\n")
+		}
+		heading, listDepth = 0, -1
+	}
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("word/document.xml: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				para.WriteByte('\t')
+			case "br", "cr":
+				if len(tables) > 0 {
+					para.WriteByte(' ')
+				} else {
+					para.WriteByte('\n')
+				}
+			case "pStyle":
+				heading = docxHeadingLevel(attr(t, "val"))
+			case "ilvl":
+				if n, err := strconv.Atoi(attr(t, "val")); err == nil {
+					listDepth = n
+				}
+			case "numPr":
+				if listDepth < 0 {
+					listDepth = 0
+				}
+			case "tbl":
+				tables = append(tables, &tableBuilder{})
+			case "tr":
+				if len(tables) > 0 {
+					tables[len(tables)-1].startRow()
+				}
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				flushPara()
+			case "tc":
+				if len(tables) > 0 {
+					tables[len(tables)-1].endCell()
+				}
+			case "tbl":
+				tb := tables[len(tables)-1]
+				tables = tables[:len(tables)-1]
+				rendered := renderTable(tb.rows)
+				if len(tables) > 0 {
+					// Nested table: flatten into the enclosing cell.
+					tables[len(tables)-1].appendCell(strings.ReplaceAll(rendered, "\n", " "))
+				} else if rendered != "" {
+					out.WriteString(rendered)
+					out.WriteString("\n")
+				}
+			}
+		case xml.CharData:
+			if inText {
+				para.Write(t)
+			}
+		}
+	}
+	return []Section{{Text: out.String()}}, nil
+}
+
+func docxHeadingLevel(style string) int {
+	s := strings.ToLower(style)
+	if s == "title" {
+		return 1
+	}
+	if rest, ok := strings.CutPrefix(s, "heading"); ok {
+		if n, err := strconv.Atoi(strings.TrimSpace(rest)); err == nil && n > 0 && n <= 6 {
+			return n
+		}
+	}
+	return 0
+}
+
+type tableBuilder struct {
+	rows [][]string
+	cell strings.Builder
+}
+
+func (t *tableBuilder) startRow() {
+	t.rows = append(t.rows, nil)
+}
+
+func (t *tableBuilder) appendCell(text string) {
+	if text == "" {
+		return
+	}
+	if t.cell.Len() > 0 {
+		t.cell.WriteByte(' ')
+	}
+	t.cell.WriteString(text)
+}
+
+func (t *tableBuilder) endCell() {
+	if len(t.rows) == 0 {
+		t.startRow()
+	}
+	last := len(t.rows) - 1
+	t.rows[last] = append(t.rows[last], t.cell.String())
+	t.cell.Reset()
+}
+
+// renderTable renders rows as a Markdown table using the first row as the
+// header. Ragged rows are padded.
+func renderTable(rows [][]string) string {
+	width := 0
+	for _, r := range rows {
+		width = max(width, len(r))
+	}
+	if width == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	writeRow := func(r []string) {
+		sb.WriteString("|")
+		for i := 0; i < width; i++ {
+			cell := ""
+			if i < len(r) {
+				cell = tableCell(r[i])
+			}
+			sb.WriteString(" " + cell + " |")
+		}
+		sb.WriteString("\n")
+	}
+	writeRow(rows[0])
+	sb.WriteString("|" + strings.Repeat(" --- |", width) + "\n")
+	for _, r := range rows[1:] {
+		writeRow(r)
+	}
+	return sb.String()
+}
+
+func tableCell(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// extractXLSX returns one section per worksheet in workbook order.
+func extractXLSX(zr *zip.Reader) ([]Section, error) {
+	shared, err := xlsxSharedStrings(zr)
+	if err != nil {
+		return nil, err
+	}
+	workbook, err := readZipFile(zr, "xl/workbook.xml")
+	if err != nil {
+		return nil, err
+	}
+	var wb struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.Unmarshal(workbook, &wb); err != nil {
+		return nil, fmt.Errorf("xl/workbook.xml: %w", err)
+	}
+	rels, err := readRels(zr, "xl/_rels/workbook.xml.rels", "xl")
+	if err != nil {
+		return nil, err
+	}
+
+	sections := make([]Section, 0, len(wb.Sheets))
+	for _, sheet := range wb.Sheets {
+		target, ok := rels[sheet.RID]
+		if !ok {
+			continue
+		}
+		data, err := readZipFile(zr, target)
+		if err != nil {
+			return nil, err
+		}
+		rows, err := xlsxRows(data, shared)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", target, err)
+		}
+		sections = append(sections, Section{Title: sheet.Name, Text: renderTable(rows)})
+	}
+	return sections, nil
+}
+
+func xlsxSharedStrings(zr *zip.Reader) ([]string, error) {
+	data, err := readZipFile(zr, "xl/sharedStrings.xml")
+	if errors.Is(err, errMissingPart) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var (
+		out    []string
+		cur    strings.Builder
+		inText bool
+		inPh   bool
+	)
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return out, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("xl/sharedStrings.xml: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "si":
+				cur.Reset()
+			case "t":
+				inText = true
+			case "rPh":
+				// Phonetic hints duplicate the text for East Asian input.
+				inPh = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "si":
+				out = append(out, cur.String())
+			case "t":
+				inText = false
+			case "rPh":
+				inPh = false
+			}
+		case xml.CharData:
+			if inText && !inPh {
+				cur.Write(t)
+			}
+		}
+	}
+}
+
+func xlsxRows(data []byte, shared []string) ([][]string, error) {
+	var ws struct {
+		Rows []struct {
+			Cells []struct {
+				Ref     string `xml:"r,attr"`
+				Type    string `xml:"t,attr"`
+				Value   string `xml:"v"`
+				Inline  string `xml:"is>t"`
+				Formula string `xml:"f"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.Unmarshal(data, &ws); err != nil {
+		return nil, err
+	}
+	rows := make([][]string, 0, len(ws.Rows))
+	for _, r := range ws.Rows {
+		var row []string
+		for i, c := range r.Cells {
+			col := i
+			if c.Ref != "" {
+				col = xlsxColumn(c.Ref)
+			}
+			for len(row) < col {
+				row = append(row, "")
+			}
+			var v string
+			switch c.Type {
+			case "s":
+				if n, err := strconv.Atoi(c.Value); err == nil && n >= 0 && n < len(shared) {
+					v = shared[n]
+				}
+			case "inlineStr":
+				v = c.Inline
+			case "b":
+				v = map[string]string{"0": "FALSE", "1": "TRUE"}[c.Value]
+			default:
+				v = c.Value
+			}
+			if col < len(row) {
+				row[col] = v
+			} else {
+				row = append(row, v)
+			}
+		}
+		// Skip rows without any content.
+		if strings.TrimSpace(strings.Join(row, "")) != "" {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// xlsxColumn converts the letters of a cell reference ("BC12") to a 0-based
+// column index.
+func xlsxColumn(ref string) int {
+	col := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+	}
+	return max(col-1, 0)
+}
+
+// readRels maps relationship IDs to archive paths resolved against base.
+func readRels(zr *zip.Reader, name, base string) (map[string]string, error) {
+	data, err := readZipFile(zr, name)
+	if err != nil {
+		return nil, err
+	}
+	var rels struct {
+		Items []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := xml.Unmarshal(data, &rels); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	out := make(map[string]string, len(rels.Items))
+	for _, r := range rels.Items {
+		target := r.Target
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join(base, target)
+		}
+		out[r.ID] = target
+	}
+	return out, nil
+}
+
+// extractPPTX returns one section per slide in presentation order.
+func extractPPTX(zr *zip.Reader) ([]Section, error) {
+	pres, err := readZipFile(zr, "ppt/presentation.xml")
+	if err != nil {
+		return nil, err
+	}
+	var p struct {
+		Slides []struct {
+			RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sldIdLst>sldId"`
+	}
+	if err := xml.Unmarshal(pres, &p); err != nil {
+		return nil, fmt.Errorf("ppt/presentation.xml: %w", err)
+	}
+	rels, err := readRels(zr, "ppt/_rels/presentation.xml.rels", "ppt")
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []string
+	for _, s := range p.Slides {
+		if target, ok := rels[s.RID]; ok {
+			targets = append(targets, target)
+		}
+	}
+	if len(targets) == 0 {
+		// Fall back to the slide files in numeric order.
+		for _, f := range zr.File {
+			if strings.HasPrefix(f.Name, "ppt/slides/slide") && strings.HasSuffix(f.Name, ".xml") {
+				targets = append(targets, f.Name)
+			}
+		}
+		sort.Slice(targets, func(i, j int) bool {
+			return slideNumber(targets[i]) < slideNumber(targets[j])
+		})
+	}
+
+	sections := make([]Section, 0, len(targets))
+	for _, target := range targets {
+		data, err := readZipFile(zr, target)
+		if err != nil {
+			return nil, err
+		}
+		paras, err := drawingParagraphs(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", target, err)
+		}
+		sec := Section{Text: strings.Join(paras, "\n")}
+		if len(paras) > 0 {
+			sec.Title = paras[0]
+		}
+		sections = append(sections, sec)
+	}
+	return sections, nil
+}
+
+func slideNumber(name string) int {
+	n, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(path.Base(name), "slide"), ".xml"))
+	return n
+}
+
+// drawingParagraphs collects the non-empty DrawingML paragraphs (<a:p>) of
+// a slide.
+func drawingParagraphs(data []byte) ([]string, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var (
+		paras  []string
+		cur    strings.Builder
+		inText bool
+	)
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return paras, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "br":
+				cur.WriteByte(' ')
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				if s := strings.TrimSpace(cur.String()); s != "" {
+					paras = append(paras, s)
+				}
+				cur.Reset()
+			}
+		case xml.CharData:
+			if inText {
+				cur.Write(t)
+			}
+		}
+	}
+}
+
+// extractEPUB returns one section per spine document.
+func extractEPUB(zr *zip.Reader) ([]Section, error) {
+	container, err := readZipFile(zr, "META-INF/container.xml")
+	if err != nil {
+		return nil, err
+	}
+	var c struct {
+		Rootfiles []struct {
+			FullPath string `xml:"full-path,attr"`
+		} `xml:"rootfiles>rootfile"`
+	}
+	if err := xml.Unmarshal(container, &c); err != nil || len(c.Rootfiles) == 0 {
+		return nil, fmt.Errorf("META-INF/container.xml has no rootfile")
+	}
+	opfPath := c.Rootfiles[0].FullPath
+	opfData, err := readZipFile(zr, opfPath)
+	if err != nil {
+		return nil, err
+	}
+	var opf struct {
+		Items []struct {
+			ID   string `xml:"id,attr"`
+			Href string `xml:"href,attr"`
+		} `xml:"manifest>item"`
+		Spine []struct {
+			IDRef string `xml:"idref,attr"`
+		} `xml:"spine>itemref"`
+	}
+	if err := xml.Unmarshal(opfData, &opf); err != nil {
+		return nil, fmt.Errorf("%s: %w", opfPath, err)
+	}
+	hrefs := make(map[string]string, len(opf.Items))
+	for _, it := range opf.Items {
+		hrefs[it.ID] = path.Join(path.Dir(opfPath), it.Href)
+	}
+
+	var sections []Section
+	for _, ref := range opf.Spine {
+		href, ok := hrefs[ref.IDRef]
+		if !ok {
+			continue
+		}
+		data, err := readZipFile(zr, href)
+		if err != nil {
+			continue
+		}
+		text, err := utils.HtmlToMarkdown(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", href, err)
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		sections = append(sections, Section{Title: markdownTitle(text), Text: text})
+	}
+	return sections, nil
+}
+
+// markdownTitle returns the first heading of a Markdown text.
+func markdownTitle(text string) string {
+	for _, line := range strings.SplitN(text, "\n", 20) {
+		if strings.HasPrefix(line, "#") {
+			return strings.TrimSpace(strings.TrimLeft(line, "#"))
+		}
+	}
+	return ""
+}
+
+func attr(e xml.StartElement, local string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,728 @@
+package document
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// The PDF reader is deliberately small: it locates objects by scanning for
+// "n g obj" rather than trusting the xref table (which is often damaged in
+// files passed around chat apps), expands object streams, and interprets
+// only the text operators of page content streams.
+
+type (
+	pdfName   string
+	pdfString []byte
+	pdfArray  []any
+	pdfDict   map[pdfName]any
+	pdfRef    struct{ num, gen int }
+	pdfStream struct {
+		dict pdfDict
+		raw  []byte
+	}
+	pdfKeyword string
+)
+
+var errPDFSyntax = errors.New("malformed PDF")
+
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func isPDFDelim(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if isPDFSpace(c) {
+			l.pos++
+			continue
+		}
+		if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		return
+	}
+}
+
+// token returns the next token: a value (number, string, name), a
+// structural marker ("[", "]", "<<", ">>") as pdfKeyword, or a keyword.
+// It returns io.EOF at the end of data.
+func (l *pdfLexer) token() (any, error) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, io.EOF
+	}
+	c := l.data[l.pos]
+	switch {
+	case c == '(':
+		return l.literalString(), nil
+	case c == '<':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '<' {
+			l.pos += 2
+			return pdfKeyword("<<"), nil
+		}
+		return l.hexString(), nil
+	case c == '>':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '>' {
+			l.pos += 2
+			return pdfKeyword(">>"), nil
+		}
+		l.pos++
+		return pdfKeyword(">"), nil
+	case c == '[' || c == ']' || c == '{' || c == '}':
+		l.pos++
+		return pdfKeyword(string(c)), nil
+	case c == '/':
+		return l.name(), nil
+	case c == ')':
+		l.pos++
+		return pdfKeyword(")"), nil
+	}
+
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelim(l.data[l.pos]) {
+		l.pos++
+	}
+	word := string(l.data[start:l.pos])
+	if word == "" {
+		l.pos++
+		return pdfKeyword(string(c)), nil
+	}
+	if n, err := strconv.ParseInt(word, 10, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(word, 64); err == nil {
+		return f, nil
+	}
+	switch word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	return pdfKeyword(word), nil
+}
+
+func (l *pdfLexer) name() pdfName {
+	l.pos++ // '/'
+	var buf []byte
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if isPDFSpace(c) || isPDFDelim(c) {
+			break
+		}
+		if c == '#' && l.pos+2 < len(l.data) {
+			if b, err := hex.DecodeString(string(l.data[l.pos+1 : l.pos+3])); err == nil {
+				buf = append(buf, b[0])
+				l.pos += 3
+				continue
+			}
+		}
+		buf = append(buf, c)
+		l.pos++
+	}
+	return pdfName(buf)
+}
+
+func (l *pdfLexer) literalString() pdfString {
+	l.pos++ // '('
+	var buf []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return buf
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return buf
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				buf = append(buf, '\n')
+			case 'r':
+				buf = append(buf, '\r')
+			case 't':
+				buf = append(buf, '\t')
+			case 'b':
+				buf = append(buf, '\b')
+			case 'f':
+				buf = append(buf, '\f')
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+			case '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					buf = append(buf, byte(v))
+				} else {
+					buf = append(buf, e)
+				}
+			}
+			continue
+		}
+		buf = append(buf, c)
+	}
+	return buf
+}
+
+func (l *pdfLexer) hexString() pdfString {
+	l.pos++ // '<'
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		c := l.data[l.pos]
+		if (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F') {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++ // '>'
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out, _ := hex.DecodeString(string(digits))
+	return out
+}
+
+// object parses one complete value, folding "n g R" into references.
+func (l *pdfLexer) object() (any, error) {
+	tok, err := l.token()
+	if err != nil {
+		return nil, err
+	}
+	return l.complete(tok)
+}
+
+func (l *pdfLexer) complete(tok any) (any, error) {
+	switch t := tok.(type) {
+	case pdfKeyword:
+		switch t {
+		case "[":
+			var arr pdfArray
+			for {
+				next, err := l.token()
+				if err != nil {
+					return arr, err
+				}
+				if next == pdfKeyword("]") {
+					return arr, nil
+				}
+				v, err := l.complete(next)
+				if err != nil {
+					return arr, err
+				}
+				arr = append(arr, v)
+			}
+		case "<<":
+			dict := pdfDict{}
+			for {
+				next, err := l.token()
+				if err != nil {
+					return dict, err
+				}
+				if next == pdfKeyword(">>") {
+					return dict, nil
+				}
+				key, ok := next.(pdfName)
+				if !ok {
+					continue
+				}
+				v, err := l.object()
+				if err != nil {
+					return dict, err
+				}
+				dict[key] = v
+			}
+		}
+		return t, nil
+	case int64:
+		// Look ahead for "gen R".
+		save := l.pos
+		gen, err := l.token()
+		if g, ok := gen.(int64); ok && err == nil {
+			if r, err := l.token(); err == nil && r == pdfKeyword("R") {
+				return pdfRef{int(t), int(g)}, nil
+			}
+		}
+		l.pos = save
+		return t, nil
+	}
+	return tok, nil
+}
+
+type pdfFile struct {
+	data    []byte
+	objects map[int]any
+}
+
+var pdfObjHeader = regexp.MustCompile(`(?m)(\d+)\s+(\d+)\s+obj\b`)
+
+func parsePDF(data []byte) (*pdfFile, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, "\x00\t\r\n "), []byte("%PDF-")) &&
+		!bytes.Contains(data[:min(len(data), 1024)], []byte("%PDF-")) {
+		return nil, fmt.Errorf("%w: missing %%PDF header", errPDFSyntax)
+	}
+	f := &pdfFile{data: data, objects: map[int]any{}}
+
+	var streams []int
+	skipUntil := 0
+	for _, m := range pdfObjHeader.FindAllSubmatchIndex(data, -1) {
+		if m[0] < skipUntil {
+			// Inside the data of the previous stream.
+			continue
+		}
+		num, _ := strconv.Atoi(string(data[m[2]:m[3]]))
+		l := &pdfLexer{data: data, pos: m[1]}
+		v, err := l.object()
+		if err != nil && !errors.Is(err, io.EOF) {
+			continue
+		}
+		if dict, ok := v.(pdfDict); ok {
+			if raw, end, ok := f.streamData(l, dict); ok {
+				v = &pdfStream{dict: dict, raw: raw}
+				skipUntil = end
+				streams = append(streams, num)
+			}
+		}
+		// Later definitions (incremental updates) replace earlier ones.
+		f.objects[num] = v
+	}
+	if len(f.objects) == 0 {
+		return nil, fmt.Errorf("%w: no objects found", errPDFSyntax)
+	}
+
+	for _, num := range streams {
+		s, ok := f.objects[num].(*pdfStream)
+		if !ok {
+			continue
+		}
+		switch s.dict["Type"] {
+		case pdfName("ObjStm"):
+			f.expandObjectStream(s)
+		case pdfName("XRef"):
+			if _, ok := s.dict["Encrypt"]; ok {
+				return nil, ErrEncrypted
+			}
+		}
+	}
+	if f.encrypted() {
+		return nil, ErrEncrypted
+	}
+	return f, nil
+}
+
+// streamData returns the raw bytes following a stream dictionary and the
+// offset where they end.
+func (f *pdfFile) streamData(l *pdfLexer, dict pdfDict) ([]byte, int, bool) {
+	save := l.pos
+	tok, err := l.token()
+	if err != nil || tok != pdfKeyword("stream") {
+		l.pos = save
+		return nil, 0, false
+	}
+	start := l.pos
+	if start < len(f.data) && f.data[start] == '\r' {
+		start++
+	}
+	if start < len(f.data) && f.data[start] == '\n' {
+		start++
+	}
+	if n, ok := f.directInt(dict["Length"]); ok && n >= 0 && start+n <= len(f.data) {
+		rest := bytes.TrimLeft(f.data[start+n:min(len(f.data), start+n+32)], "\r\n \t")
+		if bytes.HasPrefix(rest, []byte("endstream")) {
+			return f.data[start : start+n], start + n, true
+		}
+	}
+	end := bytes.Index(f.data[start:], []byte("endstream"))
+	if end < 0 {
+		return f.data[start:], len(f.data), true
+	}
+	return bytes.TrimRight(f.data[start:start+end], "\r\n"), start + end, true
+}
+
+// directInt resolves an integer that may be an indirect reference to an
+// object parsed earlier.
+func (f *pdfFile) directInt(v any) (int, bool) {
+	switch t := v.(type) {
+	case int64:
+		return int(t), true
+	case float64:
+		return int(t), true
+	case pdfRef:
+		if obj, ok := f.objects[t.num]; ok {
+			return f.directInt(obj)
+		}
+		// Not parsed yet: parse the referenced object in place.
+		re := regexp.MustCompile(fmt.Sprintf(`(?m)\b%d\s+%d\s+obj\b`, t.num, t.gen))
+		if loc := re.FindIndex(f.data); loc != nil {
+			l := &pdfLexer{data: f.data, pos: loc[1]}
+			if obj, err := l.object(); err == nil {
+				return f.directInt(obj)
+			}
+		}
+	}
+	return 0, false
+}
+
+func (f *pdfFile) expandObjectStream(s *pdfStream) {
+	data, err := f.decodeStream(s)
+	if err != nil {
+		return
+	}
+	n, _ := f.directInt(s.dict["N"])
+	first, _ := f.directInt(s.dict["First"])
+	if first <= 0 || first > len(data) {
+		return
+	}
+	header := &pdfLexer{data: data[:first]}
+	for i := 0; i < n; i++ {
+		numTok, err1 := header.token()
+		offTok, err2 := header.token()
+		num, ok1 := numTok.(int64)
+		off, ok2 := offTok.(int64)
+		if err1 != nil || err2 != nil || !ok1 || !ok2 {
+			return
+		}
+		if _, exists := f.objects[int(num)]; exists {
+			continue
+		}
+		pos := first + int(off)
+		if pos >= len(data) {
+			continue
+		}
+		l := &pdfLexer{data: data, pos: pos}
+		if v, err := l.object(); err == nil || errors.Is(err, io.EOF) {
+			f.objects[int(num)] = v
+		}
+	}
+}
+
+func (f *pdfFile) encrypted() bool {
+	for idx := 0; ; {
+		i := bytes.Index(f.data[idx:], []byte("trailer"))
+		if i < 0 {
+			return false
+		}
+		l := &pdfLexer{data: f.data, pos: idx + i + len("trailer")}
+		if v, err := l.object(); err == nil {
+			if d, ok := v.(pdfDict); ok {
+				if _, ok := d["Encrypt"]; ok {
+					return true
+				}
+			}
+		}
+		idx += i + len("trailer")
+	}
+}
+
+// resolve follows indirect references.
+func (f *pdfFile) resolve(v any) any {
+	for i := 0; i < 32; i++ {
+		ref, ok := v.(pdfRef)
+		if !ok {
+			return v
+		}
+		v = f.objects[ref.num]
+	}
+	return nil
+}
+
+func (f *pdfFile) dict(v any) pdfDict {
+	switch t := f.resolve(v).(type) {
+	case pdfDict:
+		return t
+	case *pdfStream:
+		return t.dict
+	}
+	return nil
+}
+
+func (f *pdfFile) array(v any) pdfArray {
+	a, _ := f.resolve(v).(pdfArray)
+	return a
+}
+
+func (f *pdfFile) stream(v any) *pdfStream {
+	s, _ := f.resolve(v).(*pdfStream)
+	return s
+}
+
+// decodeStream applies the stream's filters.
+func (f *pdfFile) decodeStream(s *pdfStream) ([]byte, error) {
+	data := s.raw
+	filters := f.resolve(s.dict["Filter"])
+	parms := f.resolve(s.dict["DecodeParms"])
+	var list []any
+	var parmList []any
+	switch t := filters.(type) {
+	case nil:
+		return data, nil
+	case pdfName:
+		list = []any{t}
+		parmList = []any{parms}
+	case pdfArray:
+		list = t
+		if pa, ok := parms.(pdfArray); ok {
+			parmList = pa
+		}
+	}
+	for i, filter := range list {
+		var parm pdfDict
+		if i < len(parmList) {
+			parm = f.dict(parmList[i])
+		}
+		var err error
+		switch f.resolve(filter) {
+		case pdfName("FlateDecode"), pdfName("Fl"):
+			data, err = inflate(data)
+			if err == nil {
+				data, err = f.applyPredictor(data, parm)
+			}
+		case pdfName("ASCIIHexDecode"), pdfName("AHx"):
+			data = (&pdfLexer{data: append(append([]byte{'<'}, data...), '>')}).hexString()
+		case pdfName("ASCII85Decode"), pdfName("A85"):
+			data, err = decodeASCII85(data)
+		default:
+			return nil, fmt.Errorf("unsupported filter %v", filter)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func inflate(data []byte) ([]byte, error) {
+	var r io.Reader
+	if zr, err := zlib.NewReader(bytes.NewReader(data)); err == nil {
+		r = zr
+	} else {
+		r = flate.NewReader(bytes.NewReader(data))
+	}
+	out, err := io.ReadAll(io.LimitReader(r, MaxSize+1))
+	if len(out) > MaxSize {
+		return nil, fmt.Errorf("stream expands beyond %d bytes", MaxSize)
+	}
+	// Truncated streams are common; keep whatever was inflated.
+	if err != nil && len(out) == 0 {
+		return nil, err
+	}
+	return out, nil
+}
+
+func decodeASCII85(data []byte) ([]byte, error) {
+	data = bytes.TrimSpace(data)
+	data = bytes.TrimPrefix(data, []byte("<~"))
+	if i := bytes.Index(data, []byte("~>")); i >= 0 {
+		data = data[:i]
+	}
+	out := make([]byte, len(data))
+	n, _, err := ascii85.Decode(out, data, true)
+	return out[:n], err
+}
+
+// Limits on predictor parameters. With these bounds a row is at most 64 MiB
+// and the size computation cannot overflow.
+const (
+	maxPredictorColors  = 32
+	maxPredictorColumns = 1 << 20
+)
+
+// applyPredictor reverses PNG row predictors (Predictor >= 10).
+func (f *pdfFile) applyPredictor(data []byte, parm pdfDict) ([]byte, error) {
+	pred, _ := f.directInt(parm["Predictor"])
+	if pred < 10 {
+		return data, nil
+	}
+	cols, ok := f.directInt(parm["Columns"])
+	if !ok || cols <= 0 {
+		cols = 1
+	}
+	colors, ok := f.directInt(parm["Colors"])
+	if !ok || colors <= 0 {
+		colors = 1
+	}
+	bpc, ok := f.directInt(parm["BitsPerComponent"])
+	if !ok || bpc <= 0 {
+		bpc = 8
+	}
+	// The parameters come from the file; bound them before sizing buffers.
+	switch {
+	case bpc != 1 && bpc != 2 && bpc != 4 && bpc != 8 && bpc != 16:
+		return nil, fmt.Errorf("invalid predictor BitsPerComponent %d", bpc)
+	case colors > maxPredictorColors:
+		return nil, fmt.Errorf("invalid predictor Colors %d", colors)
+	case cols > maxPredictorColumns:
+		return nil, fmt.Errorf("invalid predictor Columns %d", cols)
+	}
+	bpp := max(colors*bpc/8, 1)
+	rowLen := (cols*colors*bpc + 7) / 8
+	if rowLen > len(data) {
+		return nil, fmt.Errorf("predictor row of %d bytes exceeds stream of %d bytes", rowLen, len(data))
+	}
+	prev := make([]byte, rowLen)
+	var out []byte
+	for len(data) >= rowLen+1 {
+		kind, row := data[0], append([]byte(nil), data[1:rowLen+1]...)
+		data = data[rowLen+1:]
+		for i := range row {
+			var left, upLeft byte
+			if i >= bpp {
+				left, upLeft = row[i-bpp], prev[i-bpp]
+			}
+			up := prev[i]
+			switch kind {
+			case 1:
+				row[i] += left
+			case 2:
+				row[i] += up
+			case 3:
+				row[i] += byte((int(left) + int(up)) / 2)
+			case 4:
+				row[i] += paeth(left, up, upLeft)
+			}
+		}
+		out = append(out, row...)
+		prev = row
+	}
+	return out, nil
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	switch {
+	case pa <= pb && pa <= pc:
+		return a
+	case pb <= pc:
+		return b
+	}
+	return c
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// pages returns the page dictionaries in document order, with inherited
+// resources filled in.
+func (f *pdfFile) pages() []pdfDict {
+	var root pdfDict
+	for _, num := range f.sortedObjectNumbers() {
+		if d := f.dict(f.objects[num]); d != nil && d["Type"] == pdfName("Catalog") {
+			root = d
+		}
+	}
+	var pages []pdfDict
+	if root != nil {
+		f.walkPages(f.dict(root["Pages"]), nil, &pages, map[pdfRef]bool{}, 0)
+	}
+	if len(pages) > 0 {
+		return pages
+	}
+	// No usable page tree: fall back to page objects in file order.
+	for _, num := range f.sortedObjectNumbers() {
+		if d := f.dict(f.objects[num]); d != nil && d["Type"] == pdfName("Page") {
+			pages = append(pages, d)
+		}
+	}
+	return pages
+}
+
+func (f *pdfFile) walkPages(node pdfDict, resources any, out *[]pdfDict, seen map[pdfRef]bool, depth int) {
+	if node == nil || depth > 64 {
+		return
+	}
+	if r, ok := node["Resources"]; ok {
+		resources = r
+	}
+	if node["Type"] == pdfName("Page") || (node["Kids"] == nil && node["Contents"] != nil) {
+		page := pdfDict{}
+		for k, v := range node {
+			page[k] = v
+		}
+		if resources != nil {
+			page["Resources"] = resources
+		}
+		*out = append(*out, page)
+		return
+	}
+	for _, kid := range f.array(node["Kids"]) {
+		if ref, ok := kid.(pdfRef); ok {
+			if seen[ref] {
+				continue
+			}
+			seen[ref] = true
+		}
+		f.walkPages(f.dict(kid), resources, out, seen, depth+1)
+	}
+}
+
+func (f *pdfFile) sortedObjectNumbers() []int {
+	nums := make([]int, 0, len(f.objects))
+	for n := range f.objects {
+		nums = append(nums, n)
+	}
+	sort.Ints(nums)
+	return nums
+}
+
+// extractPDF returns one section per page.
+func extractPDF(data []byte) ([]Section, error) {
+	f, err := parsePDF(data)
+	if err != nil {
+		return nil, err
+	}
+	pages := f.pages()
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("%w: no pages found", errPDFSyntax)
+	}
+	sections := make([]Section, len(pages))
+	for i, page := range pages {
+		sections[i] = Section{Text: f.pageText(page)}
+	}
+	return sections, nil
+}
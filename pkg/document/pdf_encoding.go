@@ -0,0 +1,94 @@
+package document
+
+import "strings"
+
+// Byte encodings for simple PDF fonts without a ToUnicode map.
+var (
+	winAnsiEncoding  [256]rune
+	macRomanEncoding [256]rune
+	standardEncoding [256]rune
+	glyphNames       = map[string]rune{}
+)
+
+// cp1252High covers 0x80-0x9F of WinAnsiEncoding; 0 marks unused codes.
+var cp1252High = [32]rune{
+	'€', 0, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0, 'Ž', 0,
+	0, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0, 'ž', 'Ÿ',
+}
+
+const macRomanHigh = "ÄÅÇÉÑÖÜáàâäãåçéèêëíìîïñóòôöõúùûü" +
+	"†°¢£§•¶ß®©™´¨≠ÆØ∞±≤≥¥µ∂∑∏π∫ªºΩæø" +
+	"¿¡¬√ƒ≈∆«»… ÀÃÕŒœ–—“”‘’÷◊ÿŸ⁄€‹›ﬁﬂ" +
+	"‡·‚„‰ÂÊÁËÈÍÎÏÌÓÔÒÚÛÙıˆ˜¯˘˙˚¸˝˛ˇ"
+
+// Glyph names for 0x20-0x7E and 0xA0-0xFF, in code order.
+const (
+	asciiGlyphNames = "space exclam quotedbl numbersign dollar percent ampersand quotesingle " +
+		"parenleft parenright asterisk plus comma hyphen period slash " +
+		"zero one two three four five six seven eight nine colon semicolon less equal greater question " +
+		"at A B C D E F G H I J K L M N O P Q R S T U V W X Y Z " +
+		"bracketleft backslash bracketright asciicircum underscore grave " +
+		"a b c d e f g h i j k l m n o p q r s t u v w x y z braceleft bar braceright asciitilde"
+	latin1GlyphNames = "nbspace exclamdown cent sterling currency yen brokenbar section " +
+		"dieresis copyright ordfeminine guillemotleft logicalnot sfthyphen registered macron " +
+		"degree plusminus twosuperior threesuperior acute mu paragraph periodcentered " +
+		"cedilla onesuperior ordmasculine guillemotright onequarter onehalf threequarters questiondown " +
+		"Agrave Aacute Acircumflex Atilde Adieresis Aring AE Ccedilla " +
+		"Egrave Eacute Ecircumflex Edieresis Igrave Iacute Icircumflex Idieresis " +
+		"Eth Ntilde Ograve Oacute Ocircumflex Otilde Odieresis multiply " +
+		"Oslash Ugrave Uacute Ucircumflex Udieresis Yacute Thorn germandbls " +
+		"agrave aacute acircumflex atilde adieresis aring ae ccedilla " +
+		"egrave eacute ecircumflex edieresis igrave iacute icircumflex idieresis " +
+		"eth ntilde ograve oacute ocircumflex otilde odieresis divide " +
+		"oslash ugrave uacute ucircumflex udieresis yacute thorn ydieresis"
+)
+
+var extraGlyphNames = map[string]rune{
+	"quoteleft": '‘', "quoteright": '’', "quotedblleft": '“', "quotedblright": '”',
+	"quotesinglbase": '‚', "quotedblbase": '„', "guilsinglleft": '‹', "guilsinglright": '›',
+	"endash": '–', "emdash": '—', "bullet": '•', "ellipsis": '…', "minus": '−',
+	"dagger": '†', "daggerdbl": '‡', "perthousand": '‰', "trademark": '™', "Euro": '€',
+	"fi": 'ﬁ', "fl": 'ﬂ', "ff": 'ﬀ', "ffi": 'ﬃ', "ffl": 'ﬄ', "dotlessi": 'ı',
+	"OE": 'Œ', "oe": 'œ', "Scaron": 'Š', "scaron": 'š', "Zcaron": 'Ž', "zcaron": 'ž',
+	"Ydieresis": 'Ÿ', "florin": 'ƒ', "circumflex": 'ˆ', "tilde": '˜', "fraction": '⁄',
+	"space": ' ', "nbspace": ' ', "hyphen": '-', "periodcentered": '·',
+}
+
+func init() {
+	for i, name := range strings.Fields(asciiGlyphNames) {
+		glyphNames[name] = rune(0x20 + i)
+	}
+	for i, name := range strings.Fields(latin1GlyphNames) {
+		glyphNames[name] = rune(0xA0 + i)
+	}
+	for name, r := range extraGlyphNames {
+		glyphNames[name] = r
+	}
+
+	for c := 0x20; c < 0x7F; c++ {
+		winAnsiEncoding[c] = rune(c)
+		macRomanEncoding[c] = rune(c)
+		standardEncoding[c] = rune(c)
+	}
+	for i, r := range cp1252High {
+		winAnsiEncoding[0x80+i] = r
+	}
+	for c := 0xA0; c <= 0xFF; c++ {
+		winAnsiEncoding[c] = rune(c)
+	}
+	for i, r := range []rune(macRomanHigh) {
+		macRomanEncoding[0x80+i] = r
+	}
+	// StandardEncoding differs from ASCII in its quotes; its upper half is
+	// rarely used without a Differences array, so borrow WinAnsi there.
+	standardEncoding['\''] = '’'
+	standardEncoding['`'] = '‘'
+	for c := 0x80; c <= 0xFF; c++ {
+		standardEncoding[c] = winAnsiEncoding[c]
+	}
+	for _, c := range []byte{'\t', '\n', '\r'} {
+		winAnsiEncoding[c] = ' '
+		macRomanEncoding[c] = ' '
+		standardEncoding[c] = ' '
+	}
+}
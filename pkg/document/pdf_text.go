@@ -0,0 +1,524 @@
+package document
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// pdfFont maps character codes of shown strings to Unicode.
+type pdfFont struct {
+	toUnicode map[string]string // keyed by the raw code bytes
+	codeLens  []int             // code lengths from the codespace ranges
+	simple    [256]rune         // byte encoding of simple fonts
+	widths    map[int]float64   // glyph widths in 1/1000 em, simple fonts only
+	composite bool              // Type0: two-byte codes
+}
+
+func (f *pdfFile) loadFont(v any) *pdfFont {
+	d := f.dict(v)
+	font := &pdfFont{simple: winAnsiEncoding}
+	if d == nil {
+		return font
+	}
+	font.composite = d["Subtype"] == pdfName("Type0")
+	if s := f.stream(d["ToUnicode"]); s != nil {
+		if data, err := f.decodeStream(s); err == nil {
+			font.toUnicode, font.codeLens = parseCMap(data)
+		}
+	}
+	if font.composite {
+		return font
+	}
+
+	switch enc := f.resolve(d["Encoding"]).(type) {
+	case pdfName:
+		font.simple = namedEncoding(enc)
+	case pdfDict:
+		if base, ok := f.resolve(enc["BaseEncoding"]).(pdfName); ok {
+			font.simple = namedEncoding(base)
+		}
+		code := 0
+		for _, item := range f.array(enc["Differences"]) {
+			switch t := f.resolve(item).(type) {
+			case int64:
+				code = int(t)
+			case pdfName:
+				if code >= 0 && code < 256 {
+					if r, ok := glyphRune(string(t)); ok {
+						font.simple[code] = r
+					}
+				}
+				code++
+			}
+		}
+	}
+
+	if first, ok := f.directInt(d["FirstChar"]); ok {
+		font.widths = map[int]float64{}
+		for i, w := range f.array(d["Widths"]) {
+			if n, ok := pdfNumber(f.resolve(w)); ok {
+				font.widths[first+i] = n
+			}
+		}
+	}
+	return font
+}
+
+func namedEncoding(name pdfName) [256]rune {
+	switch name {
+	case "MacRomanEncoding":
+		return macRomanEncoding
+	case "StandardEncoding":
+		return standardEncoding
+	}
+	return winAnsiEncoding
+}
+
+// decode converts a shown string to text and returns it with the number of
+// character codes it contained and their total width in 1/1000 em.
+func (ft *pdfFont) decode(s []byte) (string, int, float64) {
+	var sb strings.Builder
+	codes, width := 0, 0.0
+	for len(s) > 0 {
+		n := 1
+		if ft.composite {
+			n = 2
+		}
+		matched := false
+		if ft.toUnicode != nil {
+			for _, l := range ft.codeLens {
+				if l <= len(s) {
+					if u, ok := ft.toUnicode[string(s[:l])]; ok {
+						sb.WriteString(u)
+						n, matched = l, true
+						break
+					}
+				}
+			}
+		}
+		n = min(n, len(s))
+		if !matched && !ft.composite {
+			if r := ft.simple[s[0]]; r != 0 {
+				sb.WriteRune(r)
+			}
+		}
+		if w, ok := ft.widths[int(s[0])]; ok && !ft.composite {
+			width += w
+		} else {
+			width += 500
+		}
+		codes++
+		s = s[n:]
+	}
+	return sb.String(), codes, width
+}
+
+// parseCMap reads the bfchar and bfrange mappings of a ToUnicode CMap.
+func parseCMap(data []byte) (map[string]string, []int) {
+	m := map[string]string{}
+	lens := map[int]bool{}
+	l := &pdfLexer{data: data}
+	var operands []any
+	for {
+		tok, err := l.object()
+		if err != nil {
+			break
+		}
+		kw, ok := tok.(pdfKeyword)
+		if !ok {
+			operands = append(operands, tok)
+			continue
+		}
+		switch kw {
+		case "endcodespacerange":
+			for i := 0; i+1 < len(operands); i += 2 {
+				if lo, ok := operands[i].(pdfString); ok && len(lo) > 0 {
+					lens[len(lo)] = true
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].(pdfString)
+				dst, ok2 := operands[i+1].(pdfString)
+				if ok1 && ok2 {
+					m[string(src)] = utf16BE(dst)
+					lens[len(src)] = true
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].(pdfString)
+				hi, ok2 := operands[i+1].(pdfString)
+				if !ok1 || !ok2 || len(lo) != len(hi) || len(lo) == 0 || len(lo) > 4 {
+					continue
+				}
+				lens[len(lo)] = true
+				start, end := codeValue(lo), codeValue(hi)
+				if end < start || end-start > 0xFFFF {
+					continue
+				}
+				switch dst := operands[i+2].(type) {
+				case pdfString:
+					base := append([]byte(nil), dst...)
+					for c := start; c <= end; c++ {
+						m[string(codeBytes(c, len(lo)))] = utf16BE(base)
+						incrementLast(base)
+					}
+				case pdfArray:
+					for j, item := range dst {
+						if s, ok := item.(pdfString); ok && start+j <= end {
+							m[string(codeBytes(start+j, len(lo)))] = utf16BE(s)
+						}
+					}
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+	var out []int
+	for n := 4; n >= 1; n-- {
+		// Longest first, so multi-byte codes win over their prefixes.
+		if lens[n] {
+			out = append(out, n)
+		}
+	}
+	return m, out
+}
+
+func codeValue(b []byte) int {
+	v := 0
+	for _, c := range b {
+		v = v<<8 | int(c)
+	}
+	return v
+}
+
+func codeBytes(v, n int) []byte {
+	b := make([]byte, n)
+	for i := n - 1; i >= 0; i-- {
+		b[i] = byte(v)
+		v >>= 8
+	}
+	return b
+}
+
+func incrementLast(b []byte) {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return
+		}
+	}
+}
+
+func utf16BE(b []byte) string {
+	if len(b)%2 == 1 {
+		return string(b)
+	}
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+	}
+	return string(utf16.Decode(units))
+}
+
+func pdfNumber(v any) (float64, bool) {
+	switch t := v.(type) {
+	case int64:
+		return float64(t), true
+	case float64:
+		return t, true
+	}
+	return 0, false
+}
+
+// textWriter lays out shown strings, starting a new line when the baseline
+// moves and inserting a space when a run starts well after the previous one.
+type textWriter struct {
+	sb          strings.Builder
+	haveLine    bool
+	lastY, endX float64
+}
+
+func (w *textWriter) show(text string, x, y, size, advance float64) {
+	if text == "" {
+		return
+	}
+	size = math.Max(math.Abs(size), 1)
+	switch {
+	case w.haveLine && math.Abs(y-w.lastY) > size*0.5:
+		w.sb.WriteByte('\n')
+		if math.Abs(y-w.lastY) > size*2 {
+			w.sb.WriteByte('\n')
+		}
+	case w.haveLine && x > w.endX+size*0.2:
+		w.space()
+	}
+	w.sb.WriteString(text)
+	w.haveLine, w.lastY, w.endX = true, y, x+advance
+}
+
+func (w *textWriter) space() {
+	s := w.sb.String()
+	if s != "" && !strings.HasSuffix(s, " ") && !strings.HasSuffix(s, "\n") {
+		w.sb.WriteByte(' ')
+	}
+}
+
+type pdfMatrix [6]float64
+
+var identityMatrix = pdfMatrix{1, 0, 0, 1, 0, 0}
+
+func (m pdfMatrix) mul(n pdfMatrix) pdfMatrix {
+	return pdfMatrix{
+		m[0]*n[0] + m[1]*n[2], m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2], m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4], m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+const maxFormDepth = 5
+
+// pageText runs the text operators of a page's content streams.
+func (f *pdfFile) pageText(page pdfDict) string {
+	var content []byte
+	switch c := f.resolve(page["Contents"]).(type) {
+	case *pdfStream:
+		content, _ = f.decodeStream(c)
+	case pdfArray:
+		for _, item := range c {
+			if s := f.stream(item); s != nil {
+				if data, err := f.decodeStream(s); err == nil {
+					content = append(content, data...)
+					content = append(content, '\n')
+				}
+			}
+		}
+	}
+	w := &textWriter{}
+	f.runContent(content, f.dict(page["Resources"]), w, map[string]*pdfFont{}, 0)
+	return cleanPageText(w.sb.String())
+}
+
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+func cleanPageText(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRightFunc(line, func(r rune) bool { return r == ' ' || r == '\t' || r == ' ' })
+	}
+	s = strings.Join(lines, "\n")
+	s = strings.ToValidUTF8(s, "")
+	return strings.TrimSpace(blankLines.ReplaceAllString(s, "\n\n"))
+}
+
+func (f *pdfFile) runContent(data []byte, resources pdfDict, w *textWriter, fonts map[string]*pdfFont, depth int) {
+	fontDict := f.dict(resources["Font"])
+	xobjects := f.dict(resources["XObject"])
+	var (
+		font     *pdfFont
+		fontSize = 1.0
+		leading  float64
+		tm, tlm  = identityMatrix, identityMatrix
+		ctm      = identityMatrix
+		stack    []pdfMatrix
+		operands []any
+	)
+	l := &pdfLexer{data: data}
+
+	currentFont := func() *pdfFont {
+		if font == nil {
+			font = &pdfFont{simple: winAnsiEncoding}
+		}
+		return font
+	}
+	showString := func(s pdfString) {
+		text, _, width := currentFont().decode(s)
+		m := tm.mul(ctm)
+		scale := math.Hypot(m[0], m[1])
+		advance := width / 1000 * fontSize * scale
+		w.show(text, m[4], m[5], fontSize*math.Hypot(m[2], m[3]), advance)
+		// Advance the text matrix so the next run is placed after this one.
+		tm = pdfMatrix{1, 0, 0, 1, width / 1000 * fontSize, 0}.mul(tm)
+	}
+	moveLine := func(tx, ty float64) {
+		tlm = pdfMatrix{1, 0, 0, 1, tx, ty}.mul(tlm)
+		tm = tlm
+	}
+	num := func(i int) float64 {
+		if i < 0 || i >= len(operands) {
+			return 0
+		}
+		n, _ := pdfNumber(operands[i])
+		return n
+	}
+
+	for {
+		tok, err := l.object()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			return
+		}
+		op, ok := tok.(pdfKeyword)
+		if !ok {
+			operands = append(operands, tok)
+			continue
+		}
+		switch op {
+		case "q":
+			stack = append(stack, ctm)
+		case "Q":
+			if len(stack) > 0 {
+				ctm, stack = stack[len(stack)-1], stack[:len(stack)-1]
+			}
+		case "cm":
+			if len(operands) >= 6 {
+				ctm = pdfMatrix{num(0), num(1), num(2), num(3), num(4), num(5)}.mul(ctm)
+			}
+		case "BT":
+			tm, tlm = identityMatrix, identityMatrix
+		case "ET":
+		case "Tf":
+			if len(operands) >= 2 {
+				if name, ok := operands[0].(pdfName); ok {
+					key := string(name)
+					if _, seen := fonts[key]; !seen {
+						fonts[key] = f.loadFont(fontDict[name])
+					}
+					font = fonts[key]
+				}
+				fontSize = num(1)
+			}
+		case "TL":
+			leading = num(0)
+		case "Td":
+			moveLine(num(0), num(1))
+		case "TD":
+			leading = -num(1)
+			moveLine(num(0), num(1))
+		case "Tm":
+			if len(operands) >= 6 {
+				tlm = pdfMatrix{num(0), num(1), num(2), num(3), num(4), num(5)}
+				tm = tlm
+			}
+		case "T*":
+			moveLine(0, -leading)
+		case "Tj":
+			if len(operands) > 0 {
+				if s, ok := operands[len(operands)-1].(pdfString); ok {
+					showString(s)
+				}
+			}
+		case "'", "\"":
+			moveLine(0, -leading)
+			if len(operands) > 0 {
+				if s, ok := operands[len(operands)-1].(pdfString); ok {
+					showString(s)
+				}
+			}
+		case "TJ":
+			if len(operands) == 0 {
+				break
+			}
+			arr, _ := operands[len(operands)-1].(pdfArray)
+			for _, item := range arr {
+				switch t := item.(type) {
+				case pdfString:
+					showString(t)
+				default:
+					if n, ok := pdfNumber(t); ok {
+						if n < -200 {
+							w.space()
+						}
+						tm = pdfMatrix{1, 0, 0, 1, -n / 1000 * fontSize, 0}.mul(tm)
+					}
+				}
+			}
+		case "Do":
+			if depth >= maxFormDepth || len(operands) == 0 {
+				break
+			}
+			name, _ := operands[0].(pdfName)
+			xo := f.stream(xobjects[name])
+			if xo == nil || xo.dict["Subtype"] != pdfName("Form") {
+				break
+			}
+			data, err := f.decodeStream(xo)
+			if err != nil {
+				break
+			}
+			res := f.dict(xo.dict["Resources"])
+			if res == nil {
+				res = resources
+			}
+			f.runContent(data, res, w, map[string]*pdfFont{}, depth+1)
+		case "BI":
+			// Inline image: skip the binary data up to EI.
+			if i := inlineImageEnd(data, l.pos); i > 0 {
+				l.pos = i
+			} else {
+				return
+			}
+		}
+		operands = operands[:0]
+	}
+}
+
+var inlineImageEI = regexp.MustCompile(`\sEI(\s|$)`)
+
+func inlineImageEnd(data []byte, from int) int {
+	id := bytes.Index(data[from:], []byte("ID"))
+	if id < 0 {
+		return -1
+	}
+	start := from + id + 2
+	loc := inlineImageEI.FindIndex(data[start:])
+	if loc == nil {
+		return -1
+	}
+	return start + loc[1]
+}
+
+// glyphRune maps an Adobe glyph name to a rune.
+func glyphRune(name string) (rune, bool) {
+	if r, ok := glyphNames[name]; ok {
+		return r, true
+	}
+	if utf8.RuneCountInString(name) == 1 {
+		r, _ := utf8.DecodeRuneInString(name)
+		return r, true
+	}
+	for _, prefix := range []string{"uni", "u"} {
+		if hexPart, ok := strings.CutPrefix(name, prefix); ok && len(hexPart) >= 4 && len(hexPart) <= 6 {
+			if b := codeValueHex(hexPart[:4]); b >= 0 {
+				return rune(b), true
+			}
+		}
+	}
+	return 0, false
+}
+
+func codeValueHex(s string) int {
+	v := 0
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			v = v*16 + int(c-'0')
+		case c >= 'A' && c <= 'F':
+			v = v*16 + int(c-'A'+10)
+		case c >= 'a' && c <= 'f':
+			v = v*16 + int(c-'a'+10)
+		default:
+			return -1
+		}
+	}
+	return v
+}
@@ -45,6 +45,26 @@ const (
 	// KindAgentError is emitted when agent execution reports an error.
 	KindAgentError Kind = "agent.error"
 
+	// KindTeamTaskStart is emitted when a team starts working on a task.
+	KindTeamTaskStart Kind = "team.task.start"
+	// KindTeamTaskEnd is emitted when a team task reaches its termination condition.
+	KindTeamTaskEnd Kind = "team.task.end"
+	// KindTeamMessagePosted is emitted when a team member posts to another member.
+	KindTeamMessagePosted Kind = "team.message.posted"
+	// KindTeamBlackboardUpdated is emitted when a team member writes a blackboard key.
+	KindTeamBlackboardUpdated Kind = "team.blackboard.updated"
+
+	// KindSwarmPeerUp is emitted when a swarm peer is discovered or its agents change.
+	KindSwarmPeerUp Kind = "swarm.peer.up"
+	// KindSwarmPeerDown is emitted when a swarm peer stops announcing itself.
+	KindSwarmPeerDown Kind = "swarm.peer.down"
+	// KindSwarmDelegateStart is emitted when a remote delegation is sent or accepted.
+	KindSwarmDelegateStart Kind = "swarm.delegate.start"
+	// KindSwarmDelegateProgress is emitted when a delegating instance receives remote progress.
+	KindSwarmDelegateProgress Kind = "swarm.delegate.progress"
+	// KindSwarmDelegateEnd is emitted when a remote delegation finishes.
+	KindSwarmDelegateEnd Kind = "swarm.delegate.end"
+
 	// KindChannelLifecycleStarted is emitted when a channel starts.
 	KindChannelLifecycleStarted Kind = "channel.lifecycle.started"
 	// KindChannelLifecycleInitialized is emitted when a channel is initialized.
@@ -63,6 +83,9 @@ const (
 	KindChannelMessageOutboundSent Kind = "channel.message.outbound_sent"
 	// KindChannelMessageOutboundFailed is emitted when an outbound channel message fails.
 	KindChannelMessageOutboundFailed Kind = "channel.message.outbound_failed"
+	// KindChannelMessageOutboundDeferred is emitted when a failed outbound message
+	// is persisted to the outbox or moved to its dead-letter storage.
+	KindChannelMessageOutboundDeferred Kind = "channel.message.outbound_deferred"
 	// KindChannelRateLimited is emitted when channel rate limiting blocks delivery.
 	KindChannelRateLimited Kind = "channel.rate_limited"
 
@@ -125,6 +148,15 @@ var knownKinds = []Kind{
 	KindAgentSubTurnResultDelivered,
 	KindAgentSubTurnOrphan,
 	KindAgentError,
+	KindTeamTaskStart,
+	KindTeamTaskEnd,
+	KindTeamMessagePosted,
+	KindTeamBlackboardUpdated,
+	KindSwarmPeerUp,
+	KindSwarmPeerDown,
+	KindSwarmDelegateStart,
+	KindSwarmDelegateProgress,
+	KindSwarmDelegateEnd,
 	KindChannelLifecycleStarted,
 	KindChannelLifecycleInitialized,
 	KindChannelLifecycleStartFailed,
@@ -134,6 +166,7 @@ var knownKinds = []Kind{
 	KindChannelMessageOutboundQueued,
 	KindChannelMessageOutboundSent,
 	KindChannelMessageOutboundFailed,
+	KindChannelMessageOutboundDeferred,
 	KindChannelRateLimited,
 	KindBusPublishFailed,
 	KindBusMessageDropped,
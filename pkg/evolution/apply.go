@@ -10,13 +10,16 @@ import (
 
 	"gopkg.in/yaml.v3"
 
+	"github.com/sipeed/picoclaw/pkg/checkpoint"
 	"github.com/sipeed/picoclaw/pkg/fileutil"
+	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/skills"
 )
 
 type Applier struct {
-	paths Paths
-	now   func() time.Time
+	paths       Paths
+	now         func() time.Time
+	checkpoints *checkpoint.Store
 }
 
 func NewApplier(paths Paths, now func() time.Time) *Applier {
@@ -29,6 +32,13 @@ func NewApplier(paths Paths, now func() time.Time) *Applier {
 	}
 }
 
+// WithCheckpoints makes the applier capture each skill file into store before
+// overwriting it, so the change can be undone from the checkpoint list.
+func (a *Applier) WithCheckpoints(store *checkpoint.Store) *Applier {
+	a.checkpoints = store
+	return a
+}
+
 func (a *Applier) ApplyDraft(ctx context.Context, workspace string, draft SkillDraft) error {
 	rollback, err := a.applyDraftWithRollback(ctx, workspace, draft)
 	if err != nil {
@@ -76,15 +86,53 @@ func (a *Applier) applyDraftWithRollback(
 	}
 
 	skillPath := filepath.Join(skillDir, "SKILL.md")
+	a.captureCheckpoint(ctx, workspace, skillPath, "evolution apply", draft.TargetSkillName)
 	if err := fileutil.WriteFileAtomic(skillPath, []byte(renderedBody), 0o644); err != nil {
 		return nil, err
 	}
+	recordEvolvedSkill(workspace, draft.TargetSkillName)
 
 	return func() error {
-		return a.rollbackSkill(skillPath, backupPath, hadOriginal)
+		if err := a.rollbackSkill(skillPath, backupPath, hadOriginal); err != nil {
+			return err
+		}
+		recordEvolvedSkill(workspace, draft.TargetSkillName)
+		return nil
 	}, nil
 }
 
+// recordEvolvedSkill tells skills.lock that evolution wrote the skill's
+// content, so a locked skill is not reported as tampered afterwards.
+func recordEvolvedSkill(workspace, skillName string) {
+	if err := skills.RecordEvolution(workspace, skillName); err != nil {
+		logger.WarnCF("evolution", "Failed to record evolved skill in skills.lock", map[string]any{
+			"workspace":    workspace,
+			"target_skill": skillName,
+			"error":        err.Error(),
+		})
+	}
+}
+
+// captureCheckpoint records skillPath with the recorder in ctx, or in a
+// checkpoint of its own when the change runs outside a turn.
+func (a *Applier) captureCheckpoint(ctx context.Context, workspace, skillPath, reason, skillName string) {
+	if checkpoint.FromContext(ctx) != nil {
+		checkpoint.Capture(ctx, skillPath)
+		return
+	}
+	if a.checkpoints == nil {
+		return
+	}
+	rec := a.checkpoints.Begin(workspace, "", reason, skillName)
+	if err := rec.Capture(skillPath); err != nil {
+		logger.WarnCF("evolution", "Failed to checkpoint skill before "+reason, map[string]any{
+			"workspace":    workspace,
+			"target_skill": skillName,
+			"error":        err.Error(),
+		})
+	}
+}
+
 func (a *Applier) backupCurrentSkill(
 	workspace, skillName string,
 ) (currentBody, backupPath string, hadOriginal bool, err error) {
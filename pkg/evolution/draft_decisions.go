@@ -0,0 +1,180 @@
+package evolution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrDraftNotFound  = errors.New("draft not found")
+	ErrDraftAmbiguous = errors.New("draft id is ambiguous")
+	ErrDraftDecided   = errors.New("draft was already decided")
+)
+
+// DraftDetail is what a reviewer needs to decide on a draft: the change
+// against the current skill and the task records it was learned from.
+type DraftDetail struct {
+	Draft   SkillDraft       `json:"draft"`
+	Preview DraftPreview     `json:"preview"`
+	Pattern *LearningRecord  `json:"pattern,omitempty"`
+	Tasks   []LearningRecord `json:"tasks,omitempty"`
+	// SuccessCount and JudgedCount count evidence tasks with a success
+	// verdict; SuccessRatio is their ratio.
+	SuccessCount int     `json:"success_count"`
+	JudgedCount  int     `json:"judged_count"`
+	SuccessRatio float64 `json:"success_ratio"`
+	// PreviewError is set when the draft no longer renders against the
+	// current skill.
+	PreviewError string `json:"preview_error,omitempty"`
+}
+
+// ListDrafts returns the drafts of a workspace, newest first.
+func (rt *Runtime) ListDrafts(workspace string) ([]SkillDraft, error) {
+	drafts, err := rt.storeForWorkspace(workspace).LoadDrafts()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]SkillDraft, 0, len(drafts))
+	for _, draft := range drafts {
+		if draft.WorkspaceID == workspace {
+			out = append(out, draft)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+// FindDraft returns the workspace draft whose id is id or starts with it.
+func (rt *Runtime) FindDraft(workspace, id string) (SkillDraft, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return SkillDraft{}, ErrDraftNotFound
+	}
+	drafts, err := rt.ListDrafts(workspace)
+	if err != nil {
+		return SkillDraft{}, err
+	}
+	var matches []SkillDraft
+	for _, draft := range drafts {
+		if draft.ID == id {
+			return draft, nil
+		}
+		if strings.HasPrefix(draft.ID, id) {
+			matches = append(matches, draft)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return SkillDraft{}, fmt.Errorf("%w: %s", ErrDraftNotFound, id)
+	case 1:
+		return matches[0], nil
+	default:
+		return SkillDraft{}, fmt.Errorf("%w: %s matches %d drafts", ErrDraftAmbiguous, id, len(matches))
+	}
+}
+
+// DraftDetail loads a draft with its diff preview and evidence.
+func (rt *Runtime) DraftDetail(workspace, id string) (*DraftDetail, error) {
+	draft, err := rt.FindDraft(workspace, id)
+	if err != nil {
+		return nil, err
+	}
+	detail := &DraftDetail{Draft: draft}
+	if preview, previewErr := BuildDraftPreview(workspace, draft); previewErr != nil {
+		detail.PreviewError = previewErr.Error()
+	} else {
+		detail.Preview = preview
+	}
+
+	store := rt.storeForWorkspace(workspace)
+	patterns, err := store.LoadPatternRecords()
+	if err != nil {
+		return nil, err
+	}
+	for i := range patterns {
+		if patterns[i].ID == draft.SourceRecordID && patterns[i].WorkspaceID == workspace {
+			detail.Pattern = &patterns[i]
+			break
+		}
+	}
+	if detail.Pattern == nil {
+		return detail, nil
+	}
+	tasks, err := store.LoadTaskRecords()
+	if err != nil {
+		return nil, err
+	}
+	detail.Tasks = draftEvidenceForRule(*detail.Pattern, tasks).TaskRecords
+	for _, task := range detail.Tasks {
+		if task.Success == nil {
+			continue
+		}
+		detail.JudgedCount++
+		if *task.Success {
+			detail.SuccessCount++
+		}
+	}
+	if detail.JudgedCount > 0 {
+		detail.SuccessRatio = float64(detail.SuccessCount) / float64(detail.JudgedCount)
+	}
+	return detail, nil
+}
+
+// AcceptDraft applies a candidate draft on a reviewer's behalf. The draft
+// still goes through the skill tests when they are required, so the
+// returned draft may come back quarantined instead of accepted.
+func (rt *Runtime) AcceptDraft(ctx context.Context, workspace, id, reviewer string) (SkillDraft, error) {
+	draft, err := rt.FindDraft(workspace, id)
+	if err != nil {
+		return SkillDraft{}, err
+	}
+	if draft.Status != DraftStatusCandidate {
+		return draft, fmt.Errorf("%w: %s is %s", ErrDraftDecided, draft.ID, draft.Status)
+	}
+	applier := rt.applierForWorkspace(workspace)
+	if applier == nil {
+		return draft, errors.New("no skill applier is configured")
+	}
+
+	rt.markReviewed(&draft, reviewer, "accepted")
+	return rt.applyCandidateDraft(ctx, workspace, rt.storeForWorkspace(workspace), applier, draft,
+		fmt.Sprintf("review-%d", rt.now().UnixNano()))
+}
+
+// RejectDraft marks a candidate or quarantined draft as rejected so it is
+// neither applied nor drafted again from the same pattern.
+func (rt *Runtime) RejectDraft(workspace, id, reviewer, reason string) (SkillDraft, error) {
+	draft, err := rt.FindDraft(workspace, id)
+	if err != nil {
+		return SkillDraft{}, err
+	}
+	if draft.Status != DraftStatusCandidate && draft.Status != DraftStatusQuarantined {
+		return draft, fmt.Errorf("%w: %s is %s", ErrDraftDecided, draft.ID, draft.Status)
+	}
+
+	note := "rejected"
+	if reason = strings.TrimSpace(reason); reason != "" {
+		note += ": " + reason
+	}
+	rt.markReviewed(&draft, reviewer, note)
+	draft.Status = DraftStatusRejected
+	if err := rt.storeForWorkspace(workspace).SaveDrafts([]SkillDraft{draft}); err != nil {
+		return draft, err
+	}
+	return draft, nil
+}
+
+func (rt *Runtime) markReviewed(draft *SkillDraft, reviewer, note string) {
+	now := rt.now()
+	reviewer = strings.TrimSpace(reviewer)
+	if reviewer == "" {
+		reviewer = "unknown"
+	}
+	draft.ReviewedBy = reviewer
+	draft.ReviewedAt = &now
+	draft.UpdatedAt = &now
+	draft.ReviewNotes = appendUniqueStrings(draft.ReviewNotes, fmt.Sprintf("%s by %s", note, reviewer))
+}
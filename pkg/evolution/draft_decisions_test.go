@@ -0,0 +1,159 @@
+package evolution_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/evolution"
+)
+
+func newDraftDecisionRuntime(t *testing.T, cfg config.EvolutionConfig) (string, *evolution.Store, *evolution.Runtime) {
+	t.Helper()
+	root := t.TempDir()
+	store := evolution.NewStore(evolution.NewPaths(root, ""))
+	now := func() time.Time { return time.Unix(1700001000, 0).UTC() }
+
+	succeeded, failed := true, false
+	records := []evolution.LearningRecord{
+		{ID: "task-1", Kind: evolution.RecordKindTask, WorkspaceID: root, Summary: "weather in Paris", Success: &succeeded},
+		{ID: "task-2", Kind: evolution.RecordKindTask, WorkspaceID: root, Summary: "weather in Rome", Success: &failed},
+		{ID: "task-3", Kind: evolution.RecordKindTask, WorkspaceID: root, Summary: "weather in Oslo", Success: &succeeded},
+		{
+			ID:            "rule-1",
+			Kind:          evolution.RecordKindPattern,
+			WorkspaceID:   root,
+			CreatedAt:     time.Unix(1700000000, 0).UTC(),
+			Summary:       "weather native-name path",
+			Status:        evolution.RecordStatus("ready"),
+			EventCount:    3,
+			TaskRecordIDs: []string{"task-1", "task-2", "task-3"},
+		},
+	}
+	if err := store.AppendLearningRecords(records); err != nil {
+		t.Fatalf("AppendLearningRecords: %v", err)
+	}
+	if err := store.SaveDrafts([]evolution.SkillDraft{{
+		ID:              "draft-rule-1",
+		WorkspaceID:     root,
+		CreatedAt:       time.Unix(1700000500, 0).UTC(),
+		SourceRecordID:  "rule-1",
+		TargetSkillName: "weather",
+		DraftType:       evolution.DraftTypeShortcut,
+		ChangeKind:      evolution.ChangeKindCreate,
+		HumanSummary:    "weather helper",
+		BodyOrPatch: "---\nname: weather\ndescription: weather helper\n---\n" +
+			"# Weather\n## Start Here\nUse native-name query first.\n",
+		Status: evolution.DraftStatusCandidate,
+	}}); err != nil {
+		t.Fatalf("SaveDrafts: %v", err)
+	}
+
+	rt, err := evolution.NewRuntime(evolution.RuntimeOptions{
+		Config:         cfg,
+		Now:            now,
+		Store:          store,
+		Applier:        evolution.NewApplier(evolution.NewPaths(root, ""), now),
+		Organizer:      evolution.NewOrganizer(evolution.OrganizerOptions{MinCaseCount: 3, MinSuccessRate: 0.7}),
+		SkillsRecaller: evolution.NewSkillsRecaller(root),
+	})
+	if err != nil {
+		t.Fatalf("NewRuntime: %v", err)
+	}
+	return root, store, rt
+}
+
+func TestRuntime_DraftDetailIncludesPreviewAndEvidence(t *testing.T) {
+	root, _, rt := newDraftDecisionRuntime(t, config.EvolutionConfig{Enabled: true, Mode: "draft"})
+
+	detail, err := rt.DraftDetail(root, "draft-r")
+	if err != nil {
+		t.Fatalf("DraftDetail: %v", err)
+	}
+	if detail.Draft.ID != "draft-rule-1" || detail.Pattern == nil || detail.Pattern.ID != "rule-1" {
+		t.Fatalf("detail = %+v", detail)
+	}
+	if len(detail.Tasks) != 3 || detail.SuccessCount != 2 || detail.JudgedCount != 3 {
+		t.Fatalf("evidence = %d tasks, %d/%d succeeded", len(detail.Tasks), detail.SuccessCount, detail.JudgedCount)
+	}
+	if !strings.Contains(detail.Preview.DiffPreview, "+Use native-name query first.") {
+		t.Fatalf("DiffPreview = %q", detail.Preview.DiffPreview)
+	}
+
+	if _, err := rt.DraftDetail(root, "missing"); !errors.Is(err, evolution.ErrDraftNotFound) {
+		t.Fatalf("DraftDetail(missing) error = %v, want ErrDraftNotFound", err)
+	}
+}
+
+func TestRuntime_HumanApprovalWaitsForAcceptDraft(t *testing.T) {
+	root, store, rt := newDraftDecisionRuntime(t, config.EvolutionConfig{
+		Enabled:  true,
+		Mode:     "apply",
+		Approval: "human",
+	})
+	skillPath := filepath.Join(root, "skills", "weather", "SKILL.md")
+
+	if err := rt.RunColdPathOnce(context.Background(), root); err != nil {
+		t.Fatalf("RunColdPathOnce: %v", err)
+	}
+	if _, err := os.Stat(skillPath); !os.IsNotExist(err) {
+		t.Fatalf("skill written before review, stat err = %v", err)
+	}
+	drafts, err := store.LoadDrafts()
+	if err != nil {
+		t.Fatalf("LoadDrafts: %v", err)
+	}
+	if len(drafts) != 1 || drafts[0].Status != evolution.DraftStatusCandidate {
+		t.Fatalf("drafts = %+v, want one candidate", drafts)
+	}
+
+	accepted, err := rt.AcceptDraft(context.Background(), root, "draft-rule-1", "telegram:42")
+	if err != nil {
+		t.Fatalf("AcceptDraft: %v", err)
+	}
+	if accepted.Status != evolution.DraftStatusAccepted || accepted.ReviewedBy != "telegram:42" ||
+		accepted.ReviewedAt == nil {
+		t.Fatalf("accepted draft = %+v", accepted)
+	}
+	if _, err := os.Stat(skillPath); err != nil {
+		t.Fatalf("expected skill after accept: %v", err)
+	}
+
+	if _, err := rt.AcceptDraft(context.Background(), root, "draft-rule-1", "telegram:42"); !errors.Is(
+		err, evolution.ErrDraftDecided) {
+		t.Fatalf("second AcceptDraft error = %v, want ErrDraftDecided", err)
+	}
+}
+
+func TestRuntime_RejectDraftRecordsReason(t *testing.T) {
+	root, store, rt := newDraftDecisionRuntime(t, config.EvolutionConfig{Enabled: true, Mode: "draft"})
+
+	rejected, err := rt.RejectDraft(root, "draft-rule-1", "web", "too specific")
+	if err != nil {
+		t.Fatalf("RejectDraft: %v", err)
+	}
+	if rejected.Status != evolution.DraftStatusRejected {
+		t.Fatalf("status = %q, want rejected", rejected.Status)
+	}
+	drafts, err := store.LoadDrafts()
+	if err != nil {
+		t.Fatalf("LoadDrafts: %v", err)
+	}
+	if len(drafts) != 1 || drafts[0].Status != evolution.DraftStatusRejected ||
+		!strings.Contains(strings.Join(drafts[0].ReviewNotes, "\n"), "rejected: too specific by web") {
+		t.Fatalf("stored drafts = %+v", drafts)
+	}
+
+	if _, err := rt.AcceptDraft(context.Background(), root, "draft-rule-1", "web"); !errors.Is(
+		err, evolution.ErrDraftDecided) {
+		t.Fatalf("AcceptDraft after reject error = %v, want ErrDraftDecided", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "skills", "weather", "SKILL.md")); !os.IsNotExist(err) {
+		t.Fatalf("rejected draft was applied, stat err = %v", err)
+	}
+}
@@ -17,6 +17,7 @@ type Paths struct {
 	SkillDrafts     string
 	ProfilesDir     string
 	BackupsDir      string
+	VersionsDir     string
 }
 
 func NewPaths(workspace, override string) Paths {
@@ -38,6 +39,7 @@ func NewPaths(workspace, override string) Paths {
 		SkillDrafts:     filepath.Join(root, "skill-drafts.json"),
 		ProfilesDir:     filepath.Join(root, "profiles"),
 		BackupsDir:      filepath.Join(root, "backups"),
+		VersionsDir:     filepath.Join(root, "versions"),
 	}
 }
 
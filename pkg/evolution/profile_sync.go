@@ -6,6 +6,10 @@ import (
 )
 
 func SaveAppliedProfile(store *Store, workspace string, draft SkillDraft, now time.Time) error {
+	return saveAppliedVersion(store, workspace, draft, now, appliedVersion{})
+}
+
+func saveAppliedVersion(store *Store, workspace string, draft SkillDraft, now time.Time, version appliedVersion) error {
 	return store.UpdateProfile(workspace, draft.TargetSkillName, func(profile *SkillProfile, exists bool) error {
 		if !exists {
 			*profile = SkillProfile{
@@ -14,6 +18,28 @@ func SaveAppliedProfile(store *Store, workspace string, draft SkillDraft, now ti
 				Origin:      "evolved",
 			}
 		}
+		if version.baselineSaved && profile.VersionEntry(BaselineVersion) == nil {
+			profile.VersionHistory = append(profile.VersionHistory, SkillVersionEntry{
+				Version:      BaselineVersion,
+				Action:       BaselineVersion,
+				Timestamp:    now,
+				Summary:      "Skill content before evolution first changed it",
+				UseCount:     profile.BaselineUseCount,
+				SuccessCount: profile.BaselineSuccessCount,
+			})
+		}
+		previous := version.previous
+		if previous == "" {
+			previous = profile.CurrentVersion
+		}
+		appliedBy := draft.ReviewedBy
+		if appliedBy == "" {
+			appliedBy = "evolution"
+		}
+		sources := version.sourceRecordIDs
+		if len(sources) == 0 && draft.SourceRecordID != "" {
+			sources = []string{draft.SourceRecordID}
+		}
 
 		profile.SkillName = draft.TargetSkillName
 		profile.WorkspaceID = workspace
@@ -30,11 +56,14 @@ func SaveAppliedProfile(store *Store, workspace string, draft SkillDraft, now ti
 			profile.RetentionScore = 1
 		}
 		profile.VersionHistory = append(profile.VersionHistory, SkillVersionEntry{
-			Version:   draft.ID,
-			Action:    string(draft.ChangeKind),
-			Timestamp: now,
-			DraftID:   draft.ID,
-			Summary:   draft.HumanSummary,
+			Version:         draft.ID,
+			Action:          string(draft.ChangeKind),
+			Timestamp:       now,
+			DraftID:         draft.ID,
+			Summary:         draft.HumanSummary,
+			AppliedBy:       appliedBy,
+			SourceRecordIDs: sources,
+			PreviousVersion: previous,
 		})
 		return nil
 	})
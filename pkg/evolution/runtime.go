@@ -34,6 +34,8 @@ type RuntimeOptions struct {
 	SuccessJudgeFactory func(workspace string) SuccessJudge
 	Applier             *Applier
 	ApplierFactory      func(workspace string) *Applier
+	DraftTester         DraftTester
+	DraftTesterFactory  func(workspace string) DraftTester
 }
 
 type Runtime struct {
@@ -51,6 +53,8 @@ type Runtime struct {
 	successJudgeFactory func(workspace string) SuccessJudge
 	applier             *Applier
 	applierFactory      func(workspace string) *Applier
+	draftTester         DraftTester
+	draftTesterFactory  func(workspace string) DraftTester
 }
 
 type TurnCaseInput struct {
@@ -68,6 +72,10 @@ type TurnCaseInput struct {
 	AttemptedSkillNames   []string
 	FinalSuccessfulPath   []string
 	SkillContextSnapshots []SkillContextSnapshot
+	// RoutingTier is "light" or "primary" when model routing picked the
+	// model for the turn, and empty when routing was off.
+	RoutingTier  string
+	RoutingModel string
 }
 
 func NewRuntime(opts RuntimeOptions) (*Runtime, error) {
@@ -103,6 +111,8 @@ func NewRuntime(opts RuntimeOptions) (*Runtime, error) {
 		successJudgeFactory: opts.SuccessJudgeFactory,
 		applier:             opts.Applier,
 		applierFactory:      opts.ApplierFactory,
+		draftTester:         opts.DraftTester,
+		draftTesterFactory:  opts.DraftTesterFactory,
 	}, nil
 }
 
@@ -128,6 +138,12 @@ func (rt *Runtime) FinalizeTurn(ctx context.Context, input TurnCaseInput) error
 		Success:        &success,
 		UsedSkillNames: append([]string(nil), usedSkillNames...),
 	}
+	if input.RoutingTier != "" {
+		record.Source = map[string]any{
+			SourceRoutingTier:  input.RoutingTier,
+			SourceRoutingModel: input.RoutingModel,
+		}
+	}
 
 	paths := NewPaths(input.Workspace, rt.cfg.StateDir)
 
@@ -176,12 +192,18 @@ func buildTaskRecordID(input TurnCaseInput, createdAt time.Time) string {
 }
 
 func buildRecordSummary(input TurnCaseInput) string {
-	if goal := summarizeText(input.UserMessage, 160); goal != "" {
+	if goal := TaskSummary(input.UserMessage); goal != "" {
 		return goal
 	}
 	return fmt.Sprintf("turn %s finished with status=%s", input.TurnID, input.Status)
 }
 
+// TaskSummary shortens a user message the way task records store it, so
+// callers can match replayed messages to their records.
+func TaskSummary(userMessage string) string {
+	return summarizeText(userMessage, 160)
+}
+
 func summarizeText(text string, maxLen int) string {
 	text = strings.TrimSpace(text)
 	if text == "" || maxLen <= 0 {
@@ -355,6 +377,7 @@ func (rt *Runtime) RunColdPathOnce(ctx context.Context, workspace string) error
 
 	recaller := rt.skillsRecallerForWorkspace(workspace)
 	applier := rt.applierForWorkspace(workspace)
+	autoApply := rt.cfg.AutoAppliesDrafts() && applier != nil
 	readyRules := filterReadyRules(patternRecords, workspace)
 	readyRules = enrichReadyRulesForDrafts(readyRules, taskRecords)
 	if len(readyRules) == 0 {
@@ -409,7 +432,7 @@ func (rt *Runtime) RunColdPathOnce(ctx context.Context, workspace string) error
 		draft.ReviewNotes = appendUniqueStrings(draft.ReviewNotes, append(review.ReviewNotes, normalizationNotes...)...)
 		draft.ScanFindings = appendUniqueStrings(draft.ScanFindings, review.Findings...)
 		changedExistingDrafts = true
-		if draft.Status != DraftStatusCandidate || mode != "apply" || applier == nil {
+		if draft.Status != DraftStatusCandidate || !autoApply {
 			if saveErr := store.SaveDrafts([]SkillDraft{draft}); saveErr != nil {
 				return saveErr
 			}
@@ -493,7 +516,7 @@ func (rt *Runtime) RunColdPathOnce(ctx context.Context, workspace string) error
 			"status":       string(draft.Status),
 			"run_id":       runID,
 		})
-		if mode == "apply" && applier != nil && draft.Status == DraftStatusCandidate {
+		if autoApply && draft.Status == DraftStatusCandidate {
 			var err error
 			draft, err = rt.applyCandidateDraft(ctx, workspace, store, applier, draft, runID)
 			if err != nil {
@@ -733,6 +756,15 @@ func (rt *Runtime) applierForWorkspace(workspace string) *Applier {
 	return rt.applier
 }
 
+func (rt *Runtime) draftTesterForWorkspace(workspace string) DraftTester {
+	if rt.draftTesterFactory != nil {
+		if tester := rt.draftTesterFactory(workspace); tester != nil {
+			return tester
+		}
+	}
+	return rt.draftTester
+}
+
 func (rt *Runtime) finalizeDraft(
 	workspace string,
 	rule LearningRecord,
@@ -1338,10 +1370,10 @@ func existingDraftSourceSet(drafts []SkillDraft, workspace string) map[string]st
 	return out
 }
 
-func (rt *Runtime) saveAppliedProfile(store *Store, workspace string, draft SkillDraft) error {
+func (rt *Runtime) saveAppliedProfile(store *Store, workspace string, draft SkillDraft, version appliedVersion) error {
 	now := rt.now()
 
-	return SaveAppliedProfile(store, workspace, draft, now)
+	return saveAppliedVersion(store, workspace, draft, now, version)
 }
 
 func (rt *Runtime) applyCandidateDraft(
@@ -1359,6 +1391,7 @@ func (rt *Runtime) applyCandidateDraft(
 		"change_kind":  string(draft.ChangeKind),
 		"run_id":       runID,
 	})
+	version := rt.prepareVersionHistory(store, applier, workspace, draft)
 	rollbackApply, err := applier.applyDraftWithRollback(ctx, workspace, draft)
 	if err != nil {
 		logger.WarnCF("evolution", "Skill draft apply failed", map[string]any{
@@ -1386,6 +1419,23 @@ func (rt *Runtime) applyCandidateDraft(
 		return draft, fmt.Errorf("%w: %v", ErrApplyDraftFailed, err)
 	}
 
+	if rt.cfg.RequireSkillTests {
+		if findings := rt.testAppliedDraft(ctx, workspace, draft, runID); len(findings) > 0 {
+			draft.Status = DraftStatusQuarantined
+			draft.ScanFindings = appendUniqueStrings(draft.ScanFindings, findings...)
+			if rollbackErr := rollbackApply(); rollbackErr != nil {
+				draft.ScanFindings = appendUniqueStrings(
+					draft.ScanFindings,
+					fmt.Sprintf("apply rollback failed: %v", rollbackErr),
+				)
+			}
+			if saveErr := store.SaveDrafts([]SkillDraft{draft}); saveErr != nil {
+				return draft, saveErr
+			}
+			return draft, nil
+		}
+	}
+
 	draft.Status = DraftStatusAccepted
 	if saveErr := store.SaveDrafts([]SkillDraft{draft}); saveErr != nil {
 		logger.WarnCF("evolution", "Skill draft save failed after apply", map[string]any{
@@ -1401,7 +1451,16 @@ func (rt *Runtime) applyCandidateDraft(
 		return draft, fmt.Errorf("%w: %v", ErrApplyDraftFailed, saveErr)
 	}
 
-	if err := rt.saveAppliedProfile(store, workspace, draft); err != nil {
+	if _, err := applier.snapshotSkillVersion(workspace, draft.TargetSkillName, draft.ID); err != nil {
+		logger.WarnCF("evolution", "Failed to snapshot applied skill version", map[string]any{
+			"workspace":    workspace,
+			"draft_id":     draft.ID,
+			"target_skill": draft.TargetSkillName,
+			"error":        err.Error(),
+			"run_id":       runID,
+		})
+	}
+	if err := rt.saveAppliedProfile(store, workspace, draft, version); err != nil {
 		logger.WarnCF("evolution", "Skill profile save failed after apply", map[string]any{
 			"workspace":    workspace,
 			"draft_id":     draft.ID,
@@ -1536,7 +1595,8 @@ func (rt *Runtime) recordSkillUsage(input TurnCaseInput, success bool) error {
 
 func (rt *Runtime) touchSkillProfile(store *Store, input TurnCaseInput, skillName string, success bool) error {
 	now := rt.now()
-	return store.UpdateProfile(input.Workspace, skillName, func(profile *SkillProfile, exists bool) error {
+	var rollbackTo, rollbackReason string
+	err := store.UpdateProfile(input.Workspace, skillName, func(profile *SkillProfile, exists bool) error {
 		if !exists {
 			*profile = SkillProfile{
 				SkillName:      skillName,
@@ -1562,8 +1622,26 @@ func (rt *Runtime) touchSkillProfile(store *Store, input TurnCaseInput, skillNam
 		profile.LastUsedAt = now
 		profile.UseCount++
 		profile.RetentionScore = nextRetentionScore(profile.RetentionScore, success)
+		if rollbackTo = recordVersionUse(profile, success, rt.cfg.EffectiveRollbackMinUses()); rollbackTo != "" {
+			current, previous := profile.VersionEntry(profile.CurrentVersion), profile.VersionEntry(rollbackTo)
+			rollbackReason = fmt.Sprintf("success ratio %.2f over %d uses is below %.2f of %s",
+				current.SuccessRatio(), current.UseCount, previous.SuccessRatio(), rollbackTo)
+		}
 		return nil
 	})
+	if err != nil || rollbackTo == "" {
+		return err
+	}
+	if _, err := rt.RollbackSkill(context.Background(), input.Workspace, skillName, rollbackTo,
+		"auto-rollback", rollbackReason); err != nil {
+		logger.WarnCF("evolution", "Automatic skill rollback failed", map[string]any{
+			"workspace": input.Workspace,
+			"skill":     skillName,
+			"to":        rollbackTo,
+			"error":     err.Error(),
+		})
+	}
+	return nil
 }
 
 func nextRetentionScore(current float64, success bool) float64 {
@@ -12,6 +12,7 @@ import (
 
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/evolution"
+	"github.com/sipeed/picoclaw/pkg/skills"
 )
 
 func TestRuntime_RunColdPathOnce_ApplyModeWritesSkillAndProfile(t *testing.T) {
@@ -1168,3 +1169,114 @@ func TestRuntime_RunColdPathOnce_ProfileSaveFailureRollsBackSkillAndQuarantinesD
 		t.Fatal("expected scan findings for profile save failure")
 	}
 }
+
+type stubDraftTester struct {
+	report *skills.ScenarioReport
+	tested []string
+}
+
+func (s *stubDraftTester) TestSkill(_ context.Context, workspace, skillName string) (*skills.ScenarioReport, error) {
+	s.tested = append(s.tested, skillName)
+	if _, err := os.Stat(filepath.Join(workspace, "skills", skillName, "SKILL.md")); err != nil {
+		return nil, err
+	}
+	return s.report, nil
+}
+
+func TestRuntime_RunColdPathOnce_RequireSkillTestsGatesAcceptance(t *testing.T) {
+	cases := []struct {
+		name       string
+		results    []skills.ScenarioResult
+		wantStatus evolution.DraftStatus
+	}{
+		{
+			name:       "passing scenarios accept the draft",
+			results:    []skills.ScenarioResult{{Name: "basic", Mode: skills.ScenarioModeScripted, Passed: true}},
+			wantStatus: evolution.DraftStatusAccepted,
+		},
+		{
+			name: "failing scenario quarantines the draft",
+			results: []skills.ScenarioResult{{
+				Name:     "basic",
+				Mode:     skills.ScenarioModeScripted,
+				Failures: []string{"answer does not match /sunny/"},
+			}},
+			wantStatus: evolution.DraftStatusQuarantined,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			store := evolution.NewStore(evolution.NewPaths(root, ""))
+			rule := evolution.LearningRecord{
+				ID:          "rule-1",
+				Kind:        evolution.RecordKindRule,
+				WorkspaceID: root,
+				CreatedAt:   time.Unix(1700000000, 0).UTC(),
+				Summary:     "weather native-name path",
+				Status:      evolution.RecordStatus("ready"),
+				EventCount:  4,
+			}
+			if err := store.AppendLearningRecords([]evolution.LearningRecord{rule}); err != nil {
+				t.Fatalf("AppendLearningRecords: %v", err)
+			}
+			tester := &stubDraftTester{report: &skills.ScenarioReport{Skill: "weather", Results: tc.results}}
+
+			rt, err := evolution.NewRuntime(evolution.RuntimeOptions{
+				Config: config.EvolutionConfig{Enabled: true, Mode: "apply", RequireSkillTests: true},
+				Now:    func() time.Time { return time.Unix(1700001000, 0).UTC() },
+				Store:  store,
+				Applier: evolution.NewApplier(evolution.NewPaths(root, ""), func() time.Time {
+					return time.Unix(1700001000, 0).UTC()
+				}),
+				DraftTester: tester,
+				DraftGenerator: stubDraftGenerator{
+					draft: evolution.SkillDraft{
+						ID:               "draft-1",
+						WorkspaceID:      root,
+						SourceRecordID:   "rule-1",
+						TargetSkillName:  "weather",
+						DraftType:        evolution.DraftTypeShortcut,
+						ChangeKind:       evolution.ChangeKindCreate,
+						HumanSummary:     "weather helper",
+						IntendedUseCases: []string{"weather native-name path"},
+						BodyOrPatch:      "---\nname: weather\ndescription: weather helper\n---\n# Weather\n## Start Here\nUse native-name query first.\n",
+					},
+				},
+				Organizer:      evolution.NewOrganizer(evolution.OrganizerOptions{MinCaseCount: 3, MinSuccessRate: 0.7}),
+				SkillsRecaller: evolution.NewSkillsRecaller(root),
+			})
+			if err != nil {
+				t.Fatalf("NewRuntime: %v", err)
+			}
+			if runErr := rt.RunColdPathOnce(context.Background(), root); runErr != nil {
+				t.Fatalf("RunColdPathOnce: %v", runErr)
+			}
+
+			if len(tester.tested) != 1 || tester.tested[0] != "weather" {
+				t.Fatalf("tested = %v, want [weather]", tester.tested)
+			}
+			drafts, err := store.LoadDrafts()
+			if err != nil {
+				t.Fatalf("LoadDrafts: %v", err)
+			}
+			if len(drafts) != 1 || drafts[0].Status != tc.wantStatus {
+				t.Fatalf("drafts = %+v, want one %q draft", drafts, tc.wantStatus)
+			}
+
+			_, statErr := os.Stat(filepath.Join(root, "skills", "weather", "SKILL.md"))
+			if tc.wantStatus == evolution.DraftStatusAccepted {
+				if statErr != nil {
+					t.Fatalf("accepted draft should stay applied: %v", statErr)
+				}
+				return
+			}
+			if !os.IsNotExist(statErr) {
+				t.Fatalf("rejected draft should be rolled back, stat err = %v", statErr)
+			}
+			if !strings.Contains(strings.Join(drafts[0].ScanFindings, "\n"), "skill test failed: basic") {
+				t.Fatalf("ScanFindings = %v, want skill test failure", drafts[0].ScanFindings)
+			}
+		})
+	}
+}
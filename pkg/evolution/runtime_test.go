@@ -670,3 +670,38 @@ func assertFinalizeTurnReactivatesSkill(
 		t.Fatalf("Status = %q, want %q", profile.Status, evolution.SkillStatusActive)
 	}
 }
+
+func TestRuntime_FinalizeTurnRecordsRoutingTier(t *testing.T) {
+	workspace := t.TempDir()
+	rt, err := evolution.NewRuntime(evolution.RuntimeOptions{
+		Config: config.EvolutionConfig{Enabled: true, Mode: "observe"},
+	})
+	if err != nil {
+		t.Fatalf("NewRuntime: %v", err)
+	}
+
+	if finalizeErr := rt.FinalizeTurn(context.Background(), evolution.TurnCaseInput{
+		Workspace:    workspace,
+		TurnID:       "turn-1",
+		SessionKey:   "session-1",
+		Status:       "completed",
+		UserMessage:  "what time is it",
+		FinalContent: "Noon.",
+		RoutingTier:  "light",
+		RoutingModel: "flash-light",
+	}); finalizeErr != nil {
+		t.Fatalf("FinalizeTurn: %v", finalizeErr)
+	}
+
+	records, err := evolution.NewStore(evolution.NewPaths(workspace, "")).LoadTaskRecords()
+	if err != nil {
+		t.Fatalf("LoadTaskRecords: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("records = %d, want 1", len(records))
+	}
+	if records[0].Source[evolution.SourceRoutingTier] != "light" ||
+		records[0].Source[evolution.SourceRoutingModel] != "flash-light" {
+		t.Fatalf("Source = %#v", records[0].Source)
+	}
+}
@@ -0,0 +1,55 @@
+package evolution
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+// DraftTester runs the test scenarios of a workspace skill. The runtime calls
+// it after a draft is written and before the draft is accepted.
+type DraftTester interface {
+	TestSkill(ctx context.Context, workspace, skillName string) (*skills.ScenarioReport, error)
+}
+
+// testAppliedDraft runs the target skill's scenarios against the applied
+// draft and returns findings that keep it from being accepted. A skill
+// without scenarios passes.
+func (rt *Runtime) testAppliedDraft(ctx context.Context, workspace string, draft SkillDraft, runID string) []string {
+	tester := rt.draftTesterForWorkspace(workspace)
+	if tester == nil {
+		return []string{"skill tests required but no scenario runner is configured"}
+	}
+	report, err := tester.TestSkill(ctx, workspace, draft.TargetSkillName)
+	if err != nil {
+		logger.WarnCF("evolution", "Skill scenarios could not run", map[string]any{
+			"workspace":    workspace,
+			"draft_id":     draft.ID,
+			"target_skill": draft.TargetSkillName,
+			"error":        err.Error(),
+			"run_id":       runID,
+		})
+		return []string{fmt.Sprintf("skill tests failed to run: %v", err)}
+	}
+
+	passed, failed, skipped := report.Counts()
+	logger.InfoCF("evolution", "Ran skill scenarios for draft", map[string]any{
+		"workspace":    workspace,
+		"draft_id":     draft.ID,
+		"target_skill": draft.TargetSkillName,
+		"passed":       passed,
+		"failed":       failed,
+		"skipped":      skipped,
+		"run_id":       runID,
+	})
+	if report.OK() {
+		return nil
+	}
+	findings := make([]string, 0, failed)
+	for _, line := range report.FailureSummary() {
+		findings = append(findings, "skill test failed: "+line)
+	}
+	return findings
+}
@@ -471,7 +471,8 @@ func isZeroSkillProfile(profile SkillProfile) bool {
 		profile.LastUsedAt.IsZero() &&
 		profile.UseCount == 0 &&
 		profile.RetentionScore == 0 &&
-		len(profile.VersionHistory) == 0
+		len(profile.VersionHistory) == 0 &&
+		profile.BaselineUseCount == 0
 }
 
 func (s *Store) LoadProfiles() ([]SkillProfile, error) {
@@ -39,6 +39,8 @@ const (
 	DraftStatusCandidate   DraftStatus = "candidate"
 	DraftStatusQuarantined DraftStatus = "quarantined"
 	DraftStatusAccepted    DraftStatus = "accepted"
+	DraftStatusRejected    DraftStatus = "rejected"
+	DraftStatusRolledBack  DraftStatus = "rolled_back"
 )
 
 type SkillStatus string
@@ -69,6 +71,13 @@ type ToolExecutionRecord struct {
 	SkillNames   []string `json:"skill_names,omitempty"`
 }
 
+// Keys of LearningRecord.Source set on task records when model routing
+// chose the turn's model.
+const (
+	SourceRoutingTier  = "routing_tier"
+	SourceRoutingModel = "routing_model"
+)
+
 type LearningRecord struct {
 	ID                   string                `json:"id"`
 	Kind                 RecordKind            `json:"kind"`
@@ -123,6 +132,8 @@ type SkillDraft struct {
 	Status             DraftStatus `json:"status"`
 	ReviewNotes        []string    `json:"review_notes,omitempty"`
 	ScanFindings       []string    `json:"scan_findings,omitempty"`
+	ReviewedBy         string      `json:"reviewed_by,omitempty"`
+	ReviewedAt         *time.Time  `json:"reviewed_at,omitempty"`
 }
 
 type SkillVersionEntry struct {
@@ -133,6 +144,15 @@ type SkillVersionEntry struct {
 	Summary        string    `json:"summary"`
 	Rollback       bool      `json:"rollback,omitempty"`
 	RollbackReason string    `json:"rollback_reason,omitempty"`
+	// AppliedBy is "evolution" for automatic applies, the reviewer for
+	// accepted drafts, or whoever asked for a rollback.
+	AppliedBy       string   `json:"applied_by,omitempty"`
+	SourceRecordIDs []string `json:"source_record_ids,omitempty"`
+	PreviousVersion string   `json:"previous_version,omitempty"`
+	// UseCount and SuccessCount count the turns that used the skill while
+	// this version was current.
+	UseCount     int `json:"use_count,omitempty"`
+	SuccessCount int `json:"success_count,omitempty"`
 }
 
 type SkillProfile struct {
@@ -150,4 +170,8 @@ type SkillProfile struct {
 	UseCount           int                 `json:"use_count"`
 	RetentionScore     float64             `json:"retention_score"`
 	VersionHistory     []SkillVersionEntry `json:"version_history"`
+	// BaselineUseCount and BaselineSuccessCount count uses before evolution
+	// first changed the skill. They seed the stats of its baseline version.
+	BaselineUseCount     int `json:"baseline_use_count,omitempty"`
+	BaselineSuccessCount int `json:"baseline_success_count,omitempty"`
 }
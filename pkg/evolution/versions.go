@@ -0,0 +1,334 @@
+package evolution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+// BaselineVersion names the skill content evolution found before it first
+// changed a skill.
+const BaselineVersion = "baseline"
+
+var ErrNoEarlierVersion = errors.New("no earlier version to roll back to")
+
+// versionActions are the history actions that make a new version current.
+// Rollback audits and lifecycle transitions refer to an existing version.
+var versionActions = map[string]struct{}{
+	BaselineVersion:           {},
+	string(ChangeKindCreate):  {},
+	string(ChangeKindAppend):  {},
+	string(ChangeKindReplace): {},
+	string(ChangeKindMerge):   {},
+}
+
+// VersionEntry returns the history entry that introduced version, or nil.
+func (p *SkillProfile) VersionEntry(version string) *SkillVersionEntry {
+	if p == nil || version == "" {
+		return nil
+	}
+	for i := len(p.VersionHistory) - 1; i >= 0; i-- {
+		entry := &p.VersionHistory[i]
+		if _, ok := versionActions[entry.Action]; ok && !entry.Rollback && entry.Version == version {
+			return entry
+		}
+	}
+	return nil
+}
+
+// Versions returns the entries that introduced a version, oldest first.
+func (p *SkillProfile) Versions() []SkillVersionEntry {
+	if p == nil {
+		return nil
+	}
+	out := make([]SkillVersionEntry, 0, len(p.VersionHistory))
+	for _, entry := range p.VersionHistory {
+		if _, ok := versionActions[entry.Action]; ok && !entry.Rollback {
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+// SuccessRatio is the share of successful uses of the version, or 0 before
+// it was used.
+func (e SkillVersionEntry) SuccessRatio() float64 {
+	if e.UseCount == 0 {
+		return 0
+	}
+	return float64(e.SuccessCount) / float64(e.UseCount)
+}
+
+// appliedVersion carries what the version history records about an apply
+// beyond the draft itself.
+type appliedVersion struct {
+	previous        string
+	baselineSaved   bool
+	sourceRecordIDs []string
+}
+
+func (a *Applier) versionSnapshotPath(workspace, skillName, version string) (string, error) {
+	if err := skills.ValidateSkillName(skillName); err != nil {
+		return "", err
+	}
+	version = strings.TrimSpace(version)
+	if version == "" || version != filepath.Base(version) || strings.HasPrefix(version, ".") {
+		return "", fmt.Errorf("invalid skill version %q", version)
+	}
+	return filepath.Join(a.paths.VersionsDir, workspaceScopeDir(workspace), skillName, version+".md"), nil
+}
+
+// snapshotSkillVersion keeps the current SKILL.md as version so a later
+// rollback can restore it. It reports false when the skill does not exist.
+func (a *Applier) snapshotSkillVersion(workspace, skillName, version string) (bool, error) {
+	path, err := a.versionSnapshotPath(workspace, skillName, version)
+	if err != nil {
+		return false, err
+	}
+	data, err := os.ReadFile(filepath.Join(workspace, "skills", skillName, "SKILL.md"))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return false, err
+	}
+	return true, fileutil.WriteFileAtomic(path, data, 0o644)
+}
+
+func (a *Applier) hasSkillVersion(workspace, skillName, version string) bool {
+	path, err := a.versionSnapshotPath(workspace, skillName, version)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// restoreSkillVersion writes a version snapshot back to the skill's SKILL.md.
+func (a *Applier) restoreSkillVersion(ctx context.Context, workspace, skillName, version string) error {
+	path, err := a.versionSnapshotPath(workspace, skillName, version)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("version %s of skill %s has no saved content", version, skillName)
+	}
+	if err != nil {
+		return err
+	}
+	skillDir := filepath.Join(workspace, "skills", skillName)
+	if err := os.MkdirAll(skillDir, 0o755); err != nil {
+		return err
+	}
+	skillPath := filepath.Join(skillDir, "SKILL.md")
+	a.captureCheckpoint(ctx, workspace, skillPath, "evolution rollback", skillName)
+	if err := fileutil.WriteFileAtomic(skillPath, data, 0o644); err != nil {
+		return err
+	}
+	recordEvolvedSkill(workspace, skillName)
+	return nil
+}
+
+// prepareVersionHistory snapshots the content a draft is about to replace so
+// it can be rolled back to. A skill evolution has not changed before is kept
+// as its baseline version.
+func (rt *Runtime) prepareVersionHistory(
+	store *Store,
+	applier *Applier,
+	workspace string,
+	draft SkillDraft,
+) appliedVersion {
+	info := appliedVersion{sourceRecordIDs: draftSourceRecordIDs(store, draft)}
+	profile, err := store.loadProfileForWorkspace(workspace, draft.TargetSkillName)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return info
+	}
+	info.previous = profile.CurrentVersion
+
+	version := info.previous
+	if version == "" {
+		version = BaselineVersion
+	} else if applier.hasSkillVersion(workspace, draft.TargetSkillName, version) {
+		return info
+	}
+	saved, err := applier.snapshotSkillVersion(workspace, draft.TargetSkillName, version)
+	if err != nil {
+		logger.WarnCF("evolution", "Failed to snapshot skill version before apply", map[string]any{
+			"workspace":    workspace,
+			"target_skill": draft.TargetSkillName,
+			"version":      version,
+			"error":        err.Error(),
+		})
+		return info
+	}
+	if saved && info.previous == "" {
+		info.previous = BaselineVersion
+		info.baselineSaved = true
+	}
+	return info
+}
+
+// draftSourceRecordIDs returns the pattern a draft was learned from and the
+// task records behind it.
+func draftSourceRecordIDs(store *Store, draft SkillDraft) []string {
+	if draft.SourceRecordID == "" {
+		return nil
+	}
+	ids := []string{draft.SourceRecordID}
+	patterns, err := store.LoadPatternRecords()
+	if err != nil {
+		return ids
+	}
+	for _, pattern := range patterns {
+		if pattern.ID == draft.SourceRecordID {
+			return appendUniqueStrings(ids, pattern.TaskRecordIDs...)
+		}
+	}
+	return ids
+}
+
+// recordVersionUse counts a use of the profile's current version and reports
+// the version to roll back to when the current one does worse than the
+// version it replaced.
+func recordVersionUse(profile *SkillProfile, success bool, minUses int) (rollbackTo string) {
+	current := profile.VersionEntry(profile.CurrentVersion)
+	if current == nil {
+		if profile.CurrentVersion == "" {
+			profile.BaselineUseCount++
+			if success {
+				profile.BaselineSuccessCount++
+			}
+		}
+		return ""
+	}
+	current.UseCount++
+	if success {
+		current.SuccessCount++
+	}
+
+	if minUses <= 0 || current.UseCount < minUses {
+		return ""
+	}
+	previous := profile.VersionEntry(current.PreviousVersion)
+	if previous == nil || previous.UseCount < minUses {
+		return ""
+	}
+	if current.SuccessRatio() < previous.SuccessRatio() {
+		return previous.Version
+	}
+	return ""
+}
+
+// RollbackSkill restores an earlier version of an evolution-managed skill.
+// An empty version restores the one the current version replaced.
+func (rt *Runtime) RollbackSkill(
+	ctx context.Context,
+	workspace, skillName, version, by, reason string,
+) (SkillVersionEntry, error) {
+	applier := rt.applierForWorkspace(workspace)
+	if applier == nil {
+		return SkillVersionEntry{}, errors.New("no skill applier is configured")
+	}
+	store := rt.storeForWorkspace(workspace)
+	profile, err := store.loadProfileForWorkspace(workspace, skillName)
+	if errors.Is(err, os.ErrNotExist) {
+		return SkillVersionEntry{}, fmt.Errorf("skill %s has no evolution history", skillName)
+	}
+	if err != nil {
+		return SkillVersionEntry{}, err
+	}
+
+	from := profile.CurrentVersion
+	if version == "" {
+		current := profile.VersionEntry(from)
+		if current == nil || current.PreviousVersion == "" {
+			return SkillVersionEntry{}, fmt.Errorf("%w: skill %s is at %q", ErrNoEarlierVersion, skillName, from)
+		}
+		version = current.PreviousVersion
+	}
+	if version == from {
+		return SkillVersionEntry{}, fmt.Errorf("skill %s is already at version %s", skillName, version)
+	}
+	if profile.VersionEntry(version) == nil {
+		return SkillVersionEntry{}, fmt.Errorf("skill %s has no version %s", skillName, version)
+	}
+	if err := applier.restoreSkillVersion(ctx, workspace, skillName, version); err != nil {
+		return SkillVersionEntry{}, err
+	}
+
+	if strings.TrimSpace(by) == "" {
+		by = "unknown"
+	}
+	entry := SkillVersionEntry{
+		Version:         version,
+		Action:          "rollback",
+		Timestamp:       rt.now(),
+		Summary:         fmt.Sprintf("Rolled back from %s to %s", from, version),
+		Rollback:        true,
+		RollbackReason:  reason,
+		AppliedBy:       by,
+		PreviousVersion: from,
+	}
+	if err := store.UpdateProfile(workspace, skillName, func(profile *SkillProfile, exists bool) error {
+		if !exists {
+			return nil
+		}
+		profile.CurrentVersion = version
+		profile.VersionHistory = append(profile.VersionHistory, entry)
+		return nil
+	}); err != nil {
+		return entry, err
+	}
+	rt.markDraftRolledBack(store, workspace, from, by, reason)
+
+	logger.InfoCF("evolution", "Rolled back skill version", map[string]any{
+		"workspace": workspace,
+		"skill":     skillName,
+		"from":      from,
+		"to":        version,
+		"by":        by,
+		"reason":    reason,
+	})
+	return entry, nil
+}
+
+// markDraftRolledBack records on the draft behind a version that it was
+// rolled back, which also keeps its pattern from being drafted again.
+func (rt *Runtime) markDraftRolledBack(store *Store, workspace, draftID, by, reason string) {
+	drafts, err := store.LoadDrafts()
+	if err != nil {
+		return
+	}
+	for _, draft := range drafts {
+		if draft.ID != draftID || draft.WorkspaceID != workspace {
+			continue
+		}
+		now := rt.now()
+		draft.Status = DraftStatusRolledBack
+		draft.UpdatedAt = &now
+		note := "rolled back by " + by
+		if reason != "" {
+			note += ": " + reason
+		}
+		draft.ReviewNotes = appendUniqueStrings(draft.ReviewNotes, note)
+		if err := store.SaveDrafts([]SkillDraft{draft}); err != nil {
+			logger.WarnCF("evolution", "Failed to mark draft rolled back", map[string]any{
+				"workspace": workspace,
+				"draft_id":  draftID,
+				"error":     err.Error(),
+			})
+		}
+		return
+	}
+}
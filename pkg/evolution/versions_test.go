@@ -0,0 +1,175 @@
+package evolution_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/evolution"
+)
+
+func newVersionsRuntime(t *testing.T, cfg config.EvolutionConfig) (string, *evolution.Store, *evolution.Runtime) {
+	t.Helper()
+	root := t.TempDir()
+	store := evolution.NewStore(evolution.NewPaths(root, ""))
+	now := func() time.Time { return time.Unix(1700001000, 0).UTC() }
+	rt, err := evolution.NewRuntime(evolution.RuntimeOptions{
+		Config:  cfg,
+		Now:     now,
+		Store:   store,
+		Applier: evolution.NewApplier(evolution.NewPaths(root, ""), now),
+	})
+	if err != nil {
+		t.Fatalf("NewRuntime: %v", err)
+	}
+	return root, store, rt
+}
+
+func saveCandidate(t *testing.T, store *evolution.Store, root, id string, kind evolution.ChangeKind, body string) {
+	t.Helper()
+	if err := store.SaveDrafts([]evolution.SkillDraft{{
+		ID:              id,
+		WorkspaceID:     root,
+		CreatedAt:       time.Unix(1700000500, 0).UTC(),
+		SourceRecordID:  "rule-1",
+		TargetSkillName: "weather",
+		DraftType:       evolution.DraftTypeShortcut,
+		ChangeKind:      kind,
+		HumanSummary:    "weather helper " + id,
+		BodyOrPatch:     body,
+		Status:          evolution.DraftStatusCandidate,
+	}}); err != nil {
+		t.Fatalf("SaveDrafts: %v", err)
+	}
+}
+
+func finalizeWeatherTurns(t *testing.T, rt *evolution.Runtime, root, status string, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if err := rt.FinalizeTurn(context.Background(), evolution.TurnCaseInput{
+			Workspace:        root,
+			TurnID:           fmt.Sprintf("turn-%s-%d", status, i),
+			Status:           status,
+			UserMessage:      "weather in Paris",
+			FinalContent:     "It is sunny.",
+			ActiveSkillNames: []string{"weather"},
+		}); err != nil {
+			t.Fatalf("FinalizeTurn: %v", err)
+		}
+	}
+}
+
+func TestRuntime_AppliedVersionRollsBackWhenSuccessRatioDrops(t *testing.T) {
+	root, store, rt := newVersionsRuntime(t, config.EvolutionConfig{Enabled: true, Mode: "draft", RollbackMinUses: 2})
+
+	skillPath := filepath.Join(root, "skills", "weather", "SKILL.md")
+	original := "---\nname: weather\ndescription: weather helper\n---\n# Weather\nAsk wttr.in.\n"
+	if err := os.MkdirAll(filepath.Dir(skillPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(skillPath, []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AppendLearningRecords([]evolution.LearningRecord{{
+		ID:            "rule-1",
+		Kind:          evolution.RecordKindPattern,
+		WorkspaceID:   root,
+		Summary:       "weather path",
+		TaskRecordIDs: []string{"task-1", "task-2"},
+	}}); err != nil {
+		t.Fatalf("AppendLearningRecords: %v", err)
+	}
+	finalizeWeatherTurns(t, rt, root, "completed", 2)
+
+	saveCandidate(t, store, root, "draft-rule-1", evolution.ChangeKindReplace,
+		"---\nname: weather\ndescription: weather helper\n---\n# Weather\nGuess the weather.\n")
+	if _, err := rt.AcceptDraft(context.Background(), root, "draft-rule-1", "cli:tester"); err != nil {
+		t.Fatalf("AcceptDraft: %v", err)
+	}
+
+	profile, err := store.LoadProfile("weather")
+	if err != nil {
+		t.Fatalf("LoadProfile: %v", err)
+	}
+	versions := profile.Versions()
+	if len(versions) != 2 || versions[0].Version != evolution.BaselineVersion || versions[0].UseCount != 2 {
+		t.Fatalf("versions = %+v, want baseline with 2 uses then draft", versions)
+	}
+	applied := versions[1]
+	if applied.Version != "draft-rule-1" || applied.PreviousVersion != evolution.BaselineVersion ||
+		applied.AppliedBy != "cli:tester" || len(applied.SourceRecordIDs) != 3 {
+		t.Fatalf("applied version = %+v", applied)
+	}
+
+	finalizeWeatherTurns(t, rt, root, "error", 2)
+
+	if data, _ := os.ReadFile(skillPath); string(data) != original {
+		t.Fatalf("SKILL.md = %q, want baseline restored", data)
+	}
+	profile, err = store.LoadProfile("weather")
+	if err != nil {
+		t.Fatalf("LoadProfile: %v", err)
+	}
+	last := profile.VersionHistory[len(profile.VersionHistory)-1]
+	if profile.CurrentVersion != evolution.BaselineVersion || !last.Rollback || last.AppliedBy != "auto-rollback" ||
+		last.PreviousVersion != "draft-rule-1" {
+		t.Fatalf("profile after rollback: current=%q last=%+v", profile.CurrentVersion, last)
+	}
+	if entry := profile.VersionEntry("draft-rule-1"); entry.UseCount != 2 || entry.SuccessCount != 0 {
+		t.Fatalf("draft version stats = %d/%d, want 0/2", entry.SuccessCount, entry.UseCount)
+	}
+	drafts, err := store.LoadDrafts()
+	if err != nil {
+		t.Fatalf("LoadDrafts: %v", err)
+	}
+	if drafts[0].Status != evolution.DraftStatusRolledBack {
+		t.Fatalf("draft status = %q, want rolled_back", drafts[0].Status)
+	}
+}
+
+func TestRuntime_RollbackSkillRestoresPreviousVersion(t *testing.T) {
+	root, store, rt := newVersionsRuntime(t, config.EvolutionConfig{Enabled: true, Mode: "draft"})
+	skillPath := filepath.Join(root, "skills", "weather", "SKILL.md")
+
+	first := "---\nname: weather\ndescription: weather helper\n---\n# Weather\nAsk wttr.in.\n"
+	saveCandidate(t, store, root, "draft-a", evolution.ChangeKindCreate, first)
+	if _, err := rt.AcceptDraft(context.Background(), root, "draft-a", "web"); err != nil {
+		t.Fatalf("AcceptDraft(draft-a): %v", err)
+	}
+	applied, err := os.ReadFile(skillPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if _, err := rt.RollbackSkill(context.Background(), root, "weather", "", "cli", ""); !errors.Is(
+		err, evolution.ErrNoEarlierVersion) {
+		t.Fatalf("RollbackSkill on first version error = %v, want ErrNoEarlierVersion", err)
+	}
+
+	saveCandidate(t, store, root, "draft-b", evolution.ChangeKindAppend,
+		"---\nname: weather\ndescription: weather helper\n---\n# Weather\nPrefer city ids.\n")
+	if _, err := rt.AcceptDraft(context.Background(), root, "draft-b", "web"); err != nil {
+		t.Fatalf("AcceptDraft(draft-b): %v", err)
+	}
+	if data, _ := os.ReadFile(skillPath); string(data) == string(applied) {
+		t.Fatal("draft-b was not applied")
+	}
+
+	entry, err := rt.RollbackSkill(context.Background(), root, "weather", "", "cli", "worse answers")
+	if err != nil {
+		t.Fatalf("RollbackSkill: %v", err)
+	}
+	if entry.Version != "draft-a" || entry.PreviousVersion != "draft-b" || entry.RollbackReason != "worse answers" {
+		t.Fatalf("rollback entry = %+v", entry)
+	}
+	if data, _ := os.ReadFile(skillPath); string(data) != string(applied) {
+		t.Fatalf("SKILL.md = %q, want draft-a content", data)
+	}
+	if _, err := rt.RollbackSkill(context.Background(), root, "weather", "draft-a", "cli", ""); err == nil {
+		t.Fatal("expected an error rolling back to the current version")
+	}
+}
@@ -28,6 +28,7 @@ import (
 	_ "github.com/sipeed/picoclaw/pkg/channels/maixcam"
 	_ "github.com/sipeed/picoclaw/pkg/channels/mqtt"
 	_ "github.com/sipeed/picoclaw/pkg/channels/onebot"
+	"github.com/sipeed/picoclaw/pkg/channels/outbox"
 	_ "github.com/sipeed/picoclaw/pkg/channels/pico"
 	_ "github.com/sipeed/picoclaw/pkg/channels/qq"
 	_ "github.com/sipeed/picoclaw/pkg/channels/slack"
@@ -45,12 +46,14 @@ import (
 	runtimeevents "github.com/sipeed/picoclaw/pkg/events"
 	"github.com/sipeed/picoclaw/pkg/health"
 	"github.com/sipeed/picoclaw/pkg/heartbeat"
+	"github.com/sipeed/picoclaw/pkg/homeassistant"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/media"
 	"github.com/sipeed/picoclaw/pkg/netbind"
 	"github.com/sipeed/picoclaw/pkg/pid"
 	"github.com/sipeed/picoclaw/pkg/providers"
 	"github.com/sipeed/picoclaw/pkg/state"
+	"github.com/sipeed/picoclaw/pkg/swarm"
 	"github.com/sipeed/picoclaw/pkg/tools"
 )
 
@@ -70,7 +73,9 @@ type services struct {
 	MediaStore       media.MediaStore
 	ChannelManager   *channels.Manager
 	DeviceService    *devices.Service
+	HomeService      *homeassistant.Service
 	HealthServer     *health.Server
+	SwarmNode        *swarm.Node
 	VoiceAgentCancel context.CancelFunc
 	manualReloadChan chan struct{}
 	reloading        atomic.Bool
@@ -482,6 +487,7 @@ func setupAndStartServices(
 		msgBus,
 		runningServices.MediaStore,
 		channels.WithRuntimeEvents(agentLoop.RuntimeEventBus()),
+		channels.WithOutbox(outbox.NewStore(outbox.DefaultDir(state.ResolveDir(cfg.WorkspacePath())))),
 	)
 	if err != nil {
 		if fms, ok := runningServices.MediaStore.(*media.FileMediaStore); ok {
@@ -538,6 +544,8 @@ func setupAndStartServices(
 
 	logChannelVoiceCapabilities(runningServices.ChannelManager, transcriber != nil, ttsAvailable)
 
+	runningServices.SwarmNode = startSwarm(cfg, agentLoop, workerPool, runningServices.ChannelManager, listenResult)
+
 	if transcriber != nil {
 		// Start Voice Agent Orchestrator after channels are ready.
 		vaCtx, vaCancel := context.WithCancel(context.Background())
@@ -564,6 +572,14 @@ func setupAndStartServices(
 		fmt.Println("✓ Device event service started")
 	}
 
+	runningServices.HomeService = homeassistant.NewService(cfg.Tools.Home, stateManager)
+	runningServices.HomeService.SetBus(msgBus)
+	if err = runningServices.HomeService.Start(context.Background()); err != nil {
+		logger.ErrorCF("homeassistant", "Error starting Home Assistant service", map[string]any{"error": err.Error()})
+	} else if cfg.Tools.Home.Enabled && len(cfg.Tools.Home.Subscriptions) > 0 {
+		fmt.Println("✓ Home Assistant subscriptions started")
+	}
+
 	return runningServices, nil
 }
 
@@ -571,7 +587,11 @@ func stopAndCleanupServices(runningServices *services, shutdownTimeout time.Dura
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer shutdownCancel()
 
-	// reload should not stop channel manager
+	// reload should not stop channel manager or the swarm node, which
+	// serves on the channel manager's listener
+	if !isReload && runningServices.SwarmNode != nil {
+		runningServices.SwarmNode.Stop()
+	}
 	if !isReload && runningServices.ChannelManager != nil {
 		runningServices.ChannelManager.StopAll(shutdownCtx)
 	}
@@ -581,6 +601,9 @@ func stopAndCleanupServices(runningServices *services, shutdownTimeout time.Dura
 	if runningServices.DeviceService != nil {
 		runningServices.DeviceService.Stop()
 	}
+	if runningServices.HomeService != nil {
+		runningServices.HomeService.Stop()
+	}
 	if runningServices.HeartbeatService != nil {
 		runningServices.HeartbeatService.Stop()
 	}
@@ -779,6 +802,14 @@ func restartServices(
 		fmt.Println("  ✓ Device event service restarted")
 	}
 
+	runningServices.HomeService = homeassistant.NewService(cfg.Tools.Home, stateManager)
+	runningServices.HomeService.SetBus(msgBus)
+	if err := runningServices.HomeService.Start(context.Background()); err != nil {
+		logger.WarnCF("homeassistant", "Failed to restart Home Assistant service", map[string]any{"error": err.Error()})
+	} else if cfg.Tools.Home.Enabled && len(cfg.Tools.Home.Subscriptions) > 0 {
+		fmt.Println("  ✓ Home Assistant subscriptions restarted")
+	}
+
 	transcriber := asr.DetectTranscriber(cfg)
 	if workerPool != nil {
 		workerPool.SetTranscriber(transcriber)
@@ -0,0 +1,144 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/config"
+	runtimeevents "github.com/sipeed/picoclaw/pkg/events"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/netbind"
+	"github.com/sipeed/picoclaw/pkg/swarm"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// swarmChannel is the channel delegated turns run on. It is a service
+// channel (see constants.IsDeliverableChannel): it is never recorded as the
+// last active channel, and it does not get internal-only tools such as exec.
+const swarmChannel = "swarm"
+
+// startSwarm joins the swarm when it is enabled: it mounts the swarm
+// endpoints on the gateway listener, starts discovery and hands the node to
+// the delegate tools. It returns nil when swarm mode is off or fails to start.
+func startSwarm(
+	cfg *config.Config,
+	agentLoop *agent.AgentLoop,
+	workerPool *agent.WorkerPool,
+	cm *channels.Manager,
+	listenResult netbind.OpenResult,
+) *swarm.Node {
+	if !cfg.Swarm.Enabled {
+		return nil
+	}
+	if loopbackOnly(listenResult.BindHosts) {
+		logger.WarnCF("swarm", "Gateway listens on loopback only; set gateway.host so peers can reach it",
+			map[string]any{"hosts": listenResult.BindHosts})
+	}
+
+	node, err := swarm.NewNode(
+		cfg.Swarm,
+		swarm.DefaultAdvertiseURL(cfg.Gateway.Port),
+		&swarmLocal{al: agentLoop},
+		swarm.WithEvents(agentLoop.RuntimeEventBus()),
+	)
+	if err == nil {
+		err = cm.HandleHTTP(swarm.PathPrefix, node.Handler())
+	}
+	if err == nil {
+		err = node.Start(context.Background())
+	}
+	if err != nil {
+		logger.ErrorCF("swarm", "Swarm mode not started", map[string]any{"error": err.Error()})
+		return nil
+	}
+
+	if workerPool != nil {
+		workerPool.SetRemoteDelegator(node)
+	} else {
+		agentLoop.SetRemoteDelegator(node)
+	}
+	fmt.Printf("✓ Swarm instance %q advertised at %s\n", node.LocalInstance(), node.URL())
+	return node
+}
+
+func loopbackOnly(hosts []string) bool {
+	if len(hosts) == 0 {
+		return false
+	}
+	for _, host := range hosts {
+		ip := net.ParseIP(strings.Trim(host, "[]"))
+		if host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return false
+		}
+	}
+	return true
+}
+
+// swarmLocal exposes the agent loop to the swarm.
+type swarmLocal struct {
+	al *agent.AgentLoop
+}
+
+func (l *swarmLocal) Agents() []swarm.AgentInfo {
+	registry := l.al.GetRegistry()
+	ids := registry.ListAgentIDs()
+	slices.Sort(ids)
+	out := make([]swarm.AgentInfo, 0, len(ids))
+	for _, id := range ids {
+		inst, ok := registry.GetAgent(id)
+		if !ok {
+			continue
+		}
+		info := swarm.AgentInfo{ID: inst.ID, Name: inst.Name, Model: inst.Model}
+		for _, name := range tools.HardwareToolNames {
+			if _, ok := inst.Tools.Get(name); ok {
+				info.HardwareTools = append(info.HardwareTools, name)
+			}
+		}
+		out = append(out, info)
+	}
+	return out
+}
+
+// RunDelegation runs the task as a turn of its own session on the target
+// agent and reports the tools the turn calls as progress.
+func (l *swarmLocal) RunDelegation(
+	ctx context.Context,
+	from string,
+	req swarm.DelegateRequest,
+	progress func(string),
+) (string, error) {
+	sessionKey := strings.ToLower(fmt.Sprintf("agent:%s:swarm-%s-%s", req.AgentID, from, req.RequestID))
+	if bus := l.al.RuntimeEventBus(); bus != nil {
+		sub, events, err := bus.Channel().
+			OfKind(runtimeevents.KindAgentToolExecStart, runtimeevents.KindAgentToolExecEnd).
+			Scope(runtimeevents.ScopeFilter{SessionKey: sessionKey}).
+			SubscribeChan(ctx, runtimeevents.SubscribeOptions{Name: "swarm-progress", Buffer: 32})
+		if err == nil {
+			defer sub.Close()
+			go forwardToolProgress(events, progress)
+		}
+	}
+	return l.al.ProcessDirectWithTarget(ctx, req.Task, sessionKey, req.AgentID, swarmChannel, from)
+}
+
+func forwardToolProgress(events <-chan runtimeevents.Event, progress func(string)) {
+	for evt := range events {
+		switch p := evt.Payload.(type) {
+		case agent.ToolExecStartPayload:
+			progress(fmt.Sprintf("running tool %s", p.Tool))
+		case agent.ToolExecEndPayload:
+			if p.IsError {
+				progress(fmt.Sprintf("tool %s failed after %s", p.Tool, p.Duration.Round(time.Millisecond)))
+			} else {
+				progress(fmt.Sprintf("tool %s finished in %s", p.Tool, p.Duration.Round(time.Millisecond)))
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package gateway
+
+import (
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestLoopbackOnly(t *testing.T) {
+	cases := []struct {
+		hosts []string
+		want  bool
+	}{
+		{hosts: nil, want: false},
+		{hosts: []string{"127.0.0.1"}, want: true},
+		{hosts: []string{"127.0.0.1", "::1"}, want: true},
+		{hosts: []string{"localhost"}, want: true},
+		{hosts: []string{"127.0.0.1", "192.168.1.20"}, want: false},
+		{hosts: []string{"0.0.0.0"}, want: false},
+	}
+	for _, tc := range cases {
+		if got := loopbackOnly(tc.hosts); got != tc.want {
+			t.Errorf("loopbackOnly(%v) = %v, want %v", tc.hosts, got, tc.want)
+		}
+	}
+}
+
+func TestSwarmLocal_AgentsListsHardwareTools(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.Workspace = t.TempDir()
+	cfg.Tools.GPIO.Enabled = true
+
+	al := agent.NewAgentLoop(cfg, bus.NewMessageBus(), &startupBlockedProvider{reason: "not used"})
+	t.Cleanup(al.Close)
+
+	agents := (&swarmLocal{al: al}).Agents()
+	if len(agents) != 1 {
+		t.Fatalf("Agents() = %+v, want the default agent", agents)
+	}
+	if got := agents[0].HardwareTools; len(got) != 1 || got[0] != "gpio" {
+		t.Fatalf("HardwareTools = %v, want [gpio]", got)
+	}
+}
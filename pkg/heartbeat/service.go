@@ -362,8 +362,8 @@ func (hs *HeartbeatService) parseLastChannel(lastChannel string) (platform, user
 
 	platform, userID = parts[0], parts[1]
 
-	// Skip internal channels
-	if constants.IsInternalChannel(platform) {
+	// Skip internal and service channels
+	if !constants.IsDeliverableChannel(platform) {
 		hs.logInfof("Skipping internal channel: %s", platform)
 		return "", ""
 	}
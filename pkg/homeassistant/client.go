@@ -0,0 +1,245 @@
+// Package homeassistant talks to a Home Assistant instance over its REST and
+// WebSocket APIs, and turns state changes into agent turns.
+package homeassistant
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultTimeout  = 10 * time.Second
+	maxResponseSize = 8 << 20
+)
+
+// ErrNotFound is returned when Home Assistant does not know an entity.
+var ErrNotFound = errors.New("entity not found")
+
+// State is an entity state as returned by /api/states.
+type State struct {
+	EntityID    string         `json:"entity_id"`
+	State       string         `json:"state"`
+	Attributes  map[string]any `json:"attributes,omitempty"`
+	LastChanged time.Time      `json:"last_changed"`
+	LastUpdated time.Time      `json:"last_updated"`
+}
+
+// FriendlyName returns the friendly_name attribute, or "" when unset.
+func (s *State) FriendlyName() string {
+	name, _ := s.Attributes["friendly_name"].(string)
+	return name
+}
+
+// Domain returns the part of the entity ID before the dot, e.g. "light".
+func (s *State) Domain() string {
+	domain, _, _ := strings.Cut(s.EntityID, ".")
+	return domain
+}
+
+// Area is an entry of the area registry.
+type Area struct {
+	AreaID string `json:"area_id"`
+	Name   string `json:"name"`
+}
+
+// Registry holds the areas and which area each entity belongs to, either
+// directly or through its device.
+type Registry struct {
+	Areas       []Area
+	EntityAreas map[string]string // entity_id -> area_id
+}
+
+// AreaName returns the display name of an area ID, or the ID itself.
+func (r *Registry) AreaName(areaID string) string {
+	for _, a := range r.Areas {
+		if a.AreaID == areaID {
+			return a.Name
+		}
+	}
+	return areaID
+}
+
+// Client is a Home Assistant API client authenticated with a long-lived
+// access token.
+type Client struct {
+	baseURL *url.URL
+	token   string
+	timeout time.Duration
+	http    *http.Client
+}
+
+// NewClient validates baseURL (http or https) and returns a client. A zero
+// timeout uses DefaultTimeout.
+func NewClient(baseURL, token string, timeout time.Duration) (*Client, error) {
+	u, err := url.Parse(strings.TrimRight(strings.TrimSpace(baseURL), "/"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid Home Assistant URL %q: want http(s)://host:port", baseURL)
+	}
+	if strings.TrimSpace(token) == "" {
+		return nil, fmt.Errorf("a Home Assistant access token is required")
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Client{
+		baseURL: u,
+		token:   token,
+		timeout: timeout,
+		http:    &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// States returns every entity state.
+func (c *Client) States(ctx context.Context) ([]State, error) {
+	var states []State
+	if err := c.do(ctx, http.MethodGet, "/api/states", nil, &states); err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+// State returns one entity state, or ErrNotFound.
+func (c *Client) State(ctx context.Context, entityID string) (*State, error) {
+	var st State
+	if err := c.do(ctx, http.MethodGet, "/api/states/"+url.PathEscape(entityID), nil, &st); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// CallService calls domain.service with data and returns the states that
+// changed while the service ran.
+func (c *Client) CallService(ctx context.Context, domain, service string, data map[string]any) ([]State, error) {
+	if data == nil {
+		data = map[string]any{}
+	}
+	var changed []State
+	p := "/api/services/" + url.PathEscape(domain) + "/" + url.PathEscape(service)
+	if err := c.do(ctx, http.MethodPost, p, data, &changed); err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+// Registry loads the area, device and entity registries over the WebSocket
+// API, which is the only API that exposes them.
+func (c *Client) Registry(ctx context.Context) (*Registry, error) {
+	ws, err := c.DialWebSocket(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer ws.Close()
+
+	var areas []Area
+	if err := ws.CommandInto(ctx, "config/area_registry/list", nil, &areas); err != nil {
+		return nil, err
+	}
+	var devices []struct {
+		ID     string `json:"id"`
+		AreaID string `json:"area_id"`
+	}
+	if err := ws.CommandInto(ctx, "config/device_registry/list", nil, &devices); err != nil {
+		return nil, err
+	}
+	var entities []struct {
+		EntityID string `json:"entity_id"`
+		AreaID   string `json:"area_id"`
+		DeviceID string `json:"device_id"`
+	}
+	if err := ws.CommandInto(ctx, "config/entity_registry/list", nil, &entities); err != nil {
+		return nil, err
+	}
+
+	deviceAreas := make(map[string]string, len(devices))
+	for _, d := range devices {
+		if d.AreaID != "" {
+			deviceAreas[d.ID] = d.AreaID
+		}
+	}
+	reg := &Registry{Areas: areas, EntityAreas: make(map[string]string, len(entities))}
+	for _, e := range entities {
+		area := e.AreaID
+		if area == "" {
+			area = deviceAreas[e.DeviceID]
+		}
+		if area != "" {
+			reg.EntityAreas[e.EntityID] = area
+		}
+	}
+	return reg, nil
+}
+
+func (c *Client) do(ctx context.Context, method, p string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	u := *c.baseURL
+	u.Path = path.Join(u.Path, p)
+	u.RawPath = ""
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("home assistant request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case resp.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("home assistant rejected the access token (401)")
+	case resp.StatusCode >= 300:
+		msg := strings.TrimSpace(string(data))
+		if len(msg) > 300 {
+			msg = msg[:300] + "..."
+		}
+		return fmt.Errorf("home assistant returned %s: %s", resp.Status, msg)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decode home assistant response: %w", err)
+	}
+	return nil
+}
+
+// MatchEntity reports whether entityID matches any of the patterns. Patterns
+// use path.Match syntax, so "light.*" matches every light.
+func MatchEntity(patterns []string, entityID string) bool {
+	for _, p := range patterns {
+		if p == entityID {
+			return true
+		}
+		if ok, _ := path.Match(p, entityID); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,314 @@
+package homeassistant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/state"
+)
+
+const testToken = "test-token"
+
+// fakeHomeAssistant is a stand-in for the Home Assistant REST and WebSocket
+// APIs with a handful of entities.
+type fakeHomeAssistant struct {
+	*httptest.Server
+	mu       sync.Mutex
+	states   map[string]*State
+	subIDs   map[*websocket.Conn]int
+	upgrader websocket.Upgrader
+}
+
+func newFakeHomeAssistant(t *testing.T) *fakeHomeAssistant {
+	t.Helper()
+	f := &fakeHomeAssistant{
+		states: map[string]*State{
+			"light.kitchen":            {EntityID: "light.kitchen", State: "on", Attributes: map[string]any{"friendly_name": "Kitchen"}},
+			"light.bedroom":            {EntityID: "light.bedroom", State: "off", Attributes: map[string]any{"friendly_name": "Bedroom Lamp"}},
+			"binary_sensor.front_door": {EntityID: "binary_sensor.front_door", State: "off", Attributes: map[string]any{"friendly_name": "Front Door"}},
+		},
+		subIDs: map[*websocket.Conn]int{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/websocket", f.serveWS)
+	mux.HandleFunc("/api/", f.serveREST)
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeHomeAssistant) serveREST(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer "+testToken {
+		http.Error(w, "401: Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/api/states":
+		out := []*State{}
+		for _, s := range f.states {
+			out = append(out, s)
+		}
+		json.NewEncoder(w).Encode(out)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/api/states/"):
+		st, ok := f.states[strings.TrimPrefix(r.URL.Path, "/api/states/")]
+		if !ok {
+			http.Error(w, `{"message": "Entity not found."}`, http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(st)
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/api/services/"):
+		var data map[string]any
+		json.NewDecoder(r.Body).Decode(&data)
+		domainService := strings.TrimPrefix(r.URL.Path, "/api/services/")
+		changed := []*State{}
+		if id, _ := data["entity_id"].(string); id != "" && f.states[id] != nil {
+			switch domainService {
+			case "light/turn_on":
+				f.states[id].State = "on"
+			case "light/turn_off":
+				f.states[id].State = "off"
+			}
+			changed = append(changed, f.states[id])
+		}
+		json.NewEncoder(w).Encode(changed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeHomeAssistant) serveWS(w http.ResponseWriter, r *http.Request) {
+	conn, err := f.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	conn.WriteJSON(map[string]any{"type": "auth_required", "ha_version": "2026.10.0"})
+	var auth map[string]any
+	if conn.ReadJSON(&auth) != nil {
+		return
+	}
+	if auth["access_token"] != testToken {
+		conn.WriteJSON(map[string]any{"type": "auth_invalid", "message": "Invalid access token"})
+		return
+	}
+	f.write(conn, map[string]any{"type": "auth_ok"})
+
+	for {
+		var msg map[string]any
+		if conn.ReadJSON(&msg) != nil {
+			return
+		}
+		id := int(msg["id"].(float64))
+		var result any
+		switch msg["type"] {
+		case "ping":
+			f.write(conn, map[string]any{"id": id, "type": "pong"})
+			continue
+		case "subscribe_events":
+			f.mu.Lock()
+			f.subIDs[conn] = id
+			f.mu.Unlock()
+		case "config/area_registry/list":
+			result = []map[string]any{{"area_id": "kitchen", "name": "Kitchen"}, {"area_id": "bedroom", "name": "Bedroom"}}
+		case "config/device_registry/list":
+			result = []map[string]any{{"id": "dev1", "area_id": "bedroom"}}
+		case "config/entity_registry/list":
+			result = []map[string]any{
+				{"entity_id": "light.kitchen", "area_id": "kitchen"},
+				{"entity_id": "light.bedroom", "device_id": "dev1"},
+			}
+		default:
+			f.write(conn, map[string]any{
+				"id": id, "type": "result", "success": false,
+				"error": map[string]any{"code": "unknown_command", "message": "Unknown command."},
+			})
+			continue
+		}
+		f.write(conn, map[string]any{"id": id, "type": "result", "success": true, "result": result})
+	}
+}
+
+func (f *fakeHomeAssistant) write(conn *websocket.Conn, msg any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	conn.WriteJSON(msg)
+}
+
+// emit sends a state_changed event to every subscribed connection.
+func (f *fakeHomeAssistant) emit(entityID, from, to string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for conn, id := range f.subIDs {
+		conn.WriteJSON(map[string]any{
+			"id": id, "type": "event",
+			"event": map[string]any{
+				"event_type": "state_changed",
+				"data": map[string]any{
+					"entity_id": entityID,
+					"old_state": map[string]any{"entity_id": entityID, "state": from},
+					"new_state": map[string]any{
+						"entity_id": entityID, "state": to,
+						"attributes":   map[string]any{"friendly_name": "Front Door"},
+						"last_changed": "2026-10-19T08:30:00Z",
+					},
+				},
+			},
+		})
+	}
+}
+
+func (f *fakeHomeAssistant) subscribers() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.subIDs)
+}
+
+func TestClient_RESTAndRegistry(t *testing.T) {
+	ha := newFakeHomeAssistant(t)
+	client, err := NewClient(ha.URL+"/", testToken, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	states, err := client.States(ctx)
+	if err != nil || len(states) != 3 {
+		t.Fatalf("States() = %d, %v", len(states), err)
+	}
+	st, err := client.State(ctx, "light.kitchen")
+	if err != nil || st.State != "on" || st.FriendlyName() != "Kitchen" || st.Domain() != "light" {
+		t.Fatalf("State() = %+v, %v", st, err)
+	}
+	if _, err := client.State(ctx, "light.garage"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("State(missing) error = %v", err)
+	}
+
+	changed, err := client.CallService(ctx, "light", "turn_on", map[string]any{"entity_id": "light.bedroom"})
+	if err != nil || len(changed) != 1 || changed[0].State != "on" {
+		t.Fatalf("CallService() = %+v, %v", changed, err)
+	}
+
+	reg, err := client.Registry(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reg.EntityAreas["light.kitchen"] != "kitchen" || reg.EntityAreas["light.bedroom"] != "bedroom" ||
+		reg.AreaName("bedroom") != "Bedroom" {
+		t.Fatalf("Registry() = %+v", reg)
+	}
+
+	bad, _ := NewClient(ha.URL, "wrong", time.Second)
+	if _, err := bad.States(ctx); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("States() with bad token error = %v", err)
+	}
+	if _, err := bad.DialWebSocket(ctx); err == nil || !strings.Contains(err.Error(), "Invalid access token") {
+		t.Fatalf("DialWebSocket() with bad token error = %v", err)
+	}
+	if _, err := NewClient("ftp://ha", testToken, 0); err == nil {
+		t.Fatal("NewClient accepted a non-http URL")
+	}
+}
+
+func TestWSConn_SubscribeAndCommandErrors(t *testing.T) {
+	ha := newFakeHomeAssistant(t)
+	client, _ := NewClient(ha.URL, testToken, time.Second)
+	ctx := context.Background()
+
+	ws, err := client.DialWebSocket(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, err := ws.SubscribeStateChanges(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ws.Command(ctx, "bogus", nil); err == nil || !strings.Contains(err.Error(), "unknown_command") {
+		t.Fatalf("Command(bogus) error = %v", err)
+	}
+
+	ha.emit("binary_sensor.front_door", "off", "on")
+	select {
+	case change := <-changes:
+		if change.EntityID != "binary_sensor.front_door" || change.Old.State != "off" || change.New.State != "on" {
+			t.Fatalf("change = %+v", change)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no state change received")
+	}
+
+	ws.Close()
+	if _, ok := <-changes; ok {
+		t.Fatal("subscription channel still open after Close")
+	}
+	if _, err := ws.Command(ctx, "ping", nil); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Command after Close error = %v", err)
+	}
+}
+
+func TestService_PublishesTurnForMatchingChange(t *testing.T) {
+	ha := newFakeHomeAssistant(t)
+	msgBus := bus.NewMessageBus()
+	defer msgBus.Close()
+
+	svc := NewService(config.HomeConfig{
+		ToolConfig:     config.ToolConfig{Enabled: true},
+		URL:            ha.URL,
+		Token:          *config.NewSecureString(testToken),
+		TimeoutSeconds: 2,
+		Subscriptions: []config.HomeSubscriptionConfig{{
+			Name:     "door",
+			Entities: []string{"binary_sensor.*_door"},
+			To:       "on",
+			Prompt:   "Tell me if nobody is home.",
+			Channel:  "telegram",
+			ChatID:   "42",
+		}},
+	}, state.NewManager(t.TempDir()))
+	svc.SetBus(msgBus)
+	if err := svc.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer svc.Stop()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for ha.subscribers() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	ha.emit("binary_sensor.front_door", "on", "off") // wrong target state
+	ha.emit("light.kitchen", "off", "on")            // not watched
+	ha.emit("binary_sensor.front_door", "off", "on")
+	ha.emit("binary_sensor.front_door", "off", "on") // within cooldown
+
+	var msg bus.InboundMessage
+	select {
+	case msg = <-msgBus.InboundChan():
+	case <-time.After(3 * time.Second):
+		t.Fatal("no inbound message published")
+	}
+	if msg.Channel != "system" || msg.ChatID != "telegram:42" || msg.SenderID != "homeassistant:door" {
+		t.Fatalf("inbound routing = %s %s %s", msg.Channel, msg.ChatID, msg.SenderID)
+	}
+	if !strings.Contains(msg.Content, `Front Door (binary_sensor.front_door) changed from "off" to "on"`) ||
+		!strings.HasSuffix(msg.Content, "Tell me if nobody is home.") {
+		t.Fatalf("content = %q", msg.Content)
+	}
+
+	select {
+	case extra := <-msgBus.InboundChan():
+		t.Fatalf("unexpected second turn: %q", extra.Content)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
@@ -0,0 +1,266 @@
+package homeassistant
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/constants"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/state"
+)
+
+const (
+	defaultCooldown   = time.Minute
+	pingInterval      = 30 * time.Second
+	minReconnectDelay = time.Second
+	maxReconnectDelay = time.Minute
+)
+
+// Service watches Home Assistant state changes and starts an agent turn for
+// each change that matches a configured subscription. Turns are published as
+// system messages so the reply goes to the subscription's chat.
+type Service struct {
+	cfg    config.HomeConfig
+	state  *state.Manager
+	bus    *bus.MessageBus
+	now    func() time.Time
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	mu     sync.RWMutex
+
+	fired map[string]time.Time // subscription/entity -> last turn
+}
+
+func NewService(cfg config.HomeConfig, stateMgr *state.Manager) *Service {
+	return &Service{
+		cfg:   cfg,
+		state: stateMgr,
+		now:   time.Now,
+		fired: make(map[string]time.Time),
+	}
+}
+
+func (s *Service) SetBus(msgBus *bus.MessageBus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bus = msgBus
+}
+
+func (s *Service) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.cfg.Enabled || len(s.cfg.Subscriptions) == 0 {
+		logger.InfoC("homeassistant", "Home Assistant subscriptions disabled or none configured")
+		return nil
+	}
+	client, err := NewClient(s.cfg.URL, s.cfg.Token.String(), time.Duration(s.cfg.TimeoutSeconds)*time.Second)
+	if err != nil {
+		return err
+	}
+
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+	go s.run(client)
+
+	logger.InfoCF("homeassistant", "Home Assistant subscription service started", map[string]any{
+		"subscriptions": len(s.cfg.Subscriptions),
+	})
+	return nil
+}
+
+func (s *Service) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel = nil
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+		logger.InfoC("homeassistant", "Home Assistant subscription service stopped")
+	}
+}
+
+// run keeps one WebSocket subscription open, reconnecting with backoff.
+func (s *Service) run(client *Client) {
+	defer close(s.done)
+	delay := minReconnectDelay
+	for {
+		connected, err := s.watch(client)
+		if s.ctx.Err() != nil {
+			return
+		}
+		if connected {
+			delay = minReconnectDelay
+		}
+		logger.WarnCF("homeassistant", "Home Assistant connection lost, reconnecting", map[string]any{
+			"error": fmt.Sprint(err),
+			"delay": delay.String(),
+		})
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+func (s *Service) watch(client *Client) (bool, error) {
+	ws, err := client.DialWebSocket(s.ctx)
+	if err != nil {
+		return false, err
+	}
+	defer ws.Close()
+	changes, err := ws.SubscribeStateChanges(s.ctx)
+	if err != nil {
+		return false, err
+	}
+
+	ping := time.NewTicker(pingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return true, nil
+		case change, ok := <-changes:
+			if !ok {
+				return true, ws.Err()
+			}
+			s.handle(change)
+		case <-ping.C:
+			// Half-open TCP connections are otherwise only noticed on write.
+			if _, err := ws.Command(s.ctx, "ping", nil); err != nil {
+				return true, err
+			}
+		}
+	}
+}
+
+func (s *Service) handle(change StateChange) {
+	if change.New == nil {
+		return
+	}
+	if change.Old != nil && change.Old.State == change.New.State {
+		return // attribute-only update
+	}
+	for _, sub := range s.cfg.Subscriptions {
+		if !MatchEntity(sub.Entities, change.EntityID) {
+			continue
+		}
+		if sub.To != "" && change.New.State != sub.To {
+			continue
+		}
+		if !s.claim(sub, change.EntityID) {
+			continue
+		}
+		s.publish(sub, change)
+	}
+}
+
+// claim applies the subscription cooldown for one entity.
+func (s *Service) claim(sub config.HomeSubscriptionConfig, entityID string) bool {
+	cooldown := defaultCooldown
+	if sub.CooldownSeconds > 0 {
+		cooldown = time.Duration(sub.CooldownSeconds) * time.Second
+	}
+	key := sub.Name + "/" + entityID
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if last, ok := s.fired[key]; ok && now.Sub(last) < cooldown {
+		return false
+	}
+	s.fired[key] = now
+	return true
+}
+
+func (s *Service) publish(sub config.HomeSubscriptionConfig, change StateChange) {
+	s.mu.RLock()
+	msgBus := s.bus
+	s.mu.RUnlock()
+	if msgBus == nil {
+		return
+	}
+
+	channel, chatID := sub.Channel, sub.ChatID
+	if channel == "" || chatID == "" {
+		channel, chatID = parseLastChannel(s.state.GetLastChannel())
+	}
+	if chatID == "" || !constants.IsDeliverableChannel(channel) {
+		logger.DebugCF("homeassistant", "No chat to deliver state change to, skipping", map[string]any{
+			"subscription": sub.Name,
+			"entity_id":    change.EntityID,
+		})
+		return
+	}
+
+	pubCtx, pubCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer pubCancel()
+	err := msgBus.PublishInbound(pubCtx, bus.InboundMessage{
+		Context: bus.InboundContext{
+			Channel:  "system",
+			ChatID:   channel + ":" + chatID,
+			ChatType: "direct",
+			SenderID: "homeassistant:" + sub.Name,
+		},
+		Content: FormatStateChange(change, sub.Prompt),
+	})
+	if err != nil {
+		logger.ErrorCF("homeassistant", "Failed to publish state change", map[string]any{
+			"subscription": sub.Name,
+			"error":        err.Error(),
+		})
+		return
+	}
+	logger.InfoCF("homeassistant", "State change turn queued", map[string]any{
+		"subscription": sub.Name,
+		"entity_id":    change.EntityID,
+		"state":        change.New.State,
+		"to":           channel,
+	})
+}
+
+// FormatStateChange renders a state change as the content of an agent turn.
+func FormatStateChange(change StateChange, prompt string) string {
+	var sb strings.Builder
+	name := change.EntityID
+	if change.New != nil && change.New.FriendlyName() != "" {
+		name = fmt.Sprintf("%s (%s)", change.New.FriendlyName(), change.EntityID)
+	}
+	from := "unknown"
+	if change.Old != nil {
+		from = change.Old.State
+	}
+	to := "removed"
+	when := time.Now()
+	if change.New != nil {
+		to = change.New.State
+		if !change.New.LastChanged.IsZero() {
+			when = change.New.LastChanged
+		}
+	}
+	fmt.Fprintf(&sb, "Home Assistant state change: %s changed from %q to %q at %s.",
+		name, from, to, when.Local().Format(time.RFC3339))
+	if prompt = strings.TrimSpace(prompt); prompt != "" {
+		sb.WriteString("\n\n")
+		sb.WriteString(prompt)
+	}
+	return sb.String()
+}
+
+func parseLastChannel(lastChannel string) (platform, chatID string) {
+	platform, chatID, ok := strings.Cut(lastChannel, ":")
+	if !ok || platform == "" || chatID == "" {
+		return "", ""
+	}
+	return platform, chatID
+}
@@ -0,0 +1,277 @@
+package homeassistant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// stateChangeBuffer is how many unread state changes a subscription queues
+// before further events are dropped.
+const stateChangeBuffer = 64
+
+// ErrClosed is returned by commands on a closed WebSocket connection.
+var ErrClosed = errors.New("home assistant websocket closed")
+
+// StateChange is one state_changed event. Old is nil for new entities and
+// New is nil for removed ones.
+type StateChange struct {
+	EntityID string `json:"entity_id"`
+	Old      *State `json:"old_state"`
+	New      *State `json:"new_state"`
+}
+
+type wsMessage struct {
+	ID      int             `json:"id"`
+	Type    string          `json:"type"`
+	Success bool            `json:"success"`
+	Result  json.RawMessage `json:"result"`
+	Error   *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+	Event *struct {
+		EventType string      `json:"event_type"`
+		Data      StateChange `json:"data"`
+	} `json:"event"`
+	Message string `json:"message"` // auth_invalid reason
+}
+
+// WSConn is an authenticated WebSocket API connection. Commands may be sent
+// concurrently; results are matched to commands by ID.
+type WSConn struct {
+	conn    *websocket.Conn
+	timeout time.Duration
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  int
+	pending map[int]chan wsMessage
+	subs    map[int]chan StateChange
+	err     error
+	done    chan struct{}
+}
+
+// DialWebSocket connects to /api/websocket and completes the auth handshake.
+func (c *Client) DialWebSocket(ctx context.Context) (*WSConn, error) {
+	u := *c.baseURL
+	if u.Scheme == "https" {
+		u.Scheme = "wss"
+	} else {
+		u.Scheme = "ws"
+	}
+	u.Path = path.Join(u.Path, "/api/websocket")
+
+	dialCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	conn, resp, err := websocket.DefaultDialer.DialContext(dialCtx, u.String(), nil)
+	if resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("home assistant websocket dial failed: %w", err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(c.timeout))
+	var hello wsMessage
+	if err := conn.ReadJSON(&hello); err != nil || hello.Type != "auth_required" {
+		conn.Close()
+		return nil, fmt.Errorf("home assistant websocket: unexpected greeting %q: %v", hello.Type, err)
+	}
+	if err := conn.WriteJSON(map[string]string{"type": "auth", "access_token": c.token}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	var auth wsMessage
+	if err := conn.ReadJSON(&auth); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("home assistant websocket auth: %w", err)
+	}
+	if auth.Type != "auth_ok" {
+		conn.Close()
+		return nil, fmt.Errorf("home assistant rejected the access token: %s", auth.Message)
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	ws := &WSConn{
+		conn:    conn,
+		timeout: c.timeout,
+		pending: make(map[int]chan wsMessage),
+		subs:    make(map[int]chan StateChange),
+		done:    make(chan struct{}),
+	}
+	go ws.readLoop()
+	return ws, nil
+}
+
+// Done is closed when the connection drops; Err then reports why.
+func (w *WSConn) Done() <-chan struct{} {
+	return w.done
+}
+
+func (w *WSConn) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+func (w *WSConn) Close() error {
+	err := w.conn.Close()
+	<-w.done
+	return err
+}
+
+// Command sends one command and waits for its result.
+func (w *WSConn) Command(ctx context.Context, typ string, fields map[string]any) (json.RawMessage, error) {
+	id, ch, err := w.register(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer w.unregister(id)
+
+	if err := w.send(id, typ, fields); err != nil {
+		return nil, err
+	}
+	return w.await(ctx, ch)
+}
+
+// CommandInto runs Command and decodes the result into out.
+func (w *WSConn) CommandInto(ctx context.Context, typ string, fields map[string]any, out any) error {
+	raw, err := w.Command(ctx, typ, fields)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("decode %s result: %w", typ, err)
+	}
+	return nil
+}
+
+// SubscribeStateChanges subscribes to state_changed events. The returned
+// channel is closed when the connection drops.
+func (w *WSConn) SubscribeStateChanges(ctx context.Context) (<-chan StateChange, error) {
+	events := make(chan StateChange, stateChangeBuffer)
+	id, ch, err := w.register(events)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.send(id, "subscribe_events", map[string]any{"event_type": "state_changed"}); err != nil {
+		w.unregister(id)
+		return nil, err
+	}
+	if _, err := w.await(ctx, ch); err != nil {
+		w.unregister(id)
+		return nil, err
+	}
+	// Keep the subscription but stop waiting for further results.
+	w.mu.Lock()
+	delete(w.pending, id)
+	w.mu.Unlock()
+	return events, nil
+}
+
+func (w *WSConn) register(events chan StateChange) (int, chan wsMessage, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return 0, nil, w.err
+	}
+	w.nextID++
+	ch := make(chan wsMessage, 1)
+	w.pending[w.nextID] = ch
+	if events != nil {
+		// Registered before sending so no event can arrive unrouted.
+		w.subs[w.nextID] = events
+	}
+	return w.nextID, ch, nil
+}
+
+func (w *WSConn) unregister(id int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.pending, id)
+	if events, ok := w.subs[id]; ok {
+		delete(w.subs, id)
+		close(events)
+	}
+}
+
+func (w *WSConn) send(id int, typ string, fields map[string]any) error {
+	msg := make(map[string]any, len(fields)+2)
+	for k, v := range fields {
+		msg[k] = v
+	}
+	msg["id"] = id
+	msg["type"] = typ
+
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	w.conn.SetWriteDeadline(time.Now().Add(w.timeout))
+	return w.conn.WriteJSON(msg)
+}
+
+func (w *WSConn) await(ctx context.Context, ch chan wsMessage) (json.RawMessage, error) {
+	timer := time.NewTimer(w.timeout)
+	defer timer.Stop()
+	select {
+	case msg := <-ch:
+		if !msg.Success {
+			if msg.Error != nil {
+				return nil, fmt.Errorf("home assistant: %s (%s)", msg.Error.Message, msg.Error.Code)
+			}
+			return nil, fmt.Errorf("home assistant command failed")
+		}
+		return msg.Result, nil
+	case <-w.done:
+		return nil, w.Err()
+	case <-timer.C:
+		return nil, fmt.Errorf("home assistant command timed out after %s", w.timeout)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (w *WSConn) readLoop() {
+	var readErr error
+	for {
+		var msg wsMessage
+		if err := w.conn.ReadJSON(&msg); err != nil {
+			readErr = err
+			break
+		}
+		w.mu.Lock()
+		switch msg.Type {
+		case "result":
+			if ch, ok := w.pending[msg.ID]; ok {
+				ch <- msg
+				delete(w.pending, msg.ID)
+			}
+		case "event":
+			if events, ok := w.subs[msg.ID]; ok && msg.Event != nil {
+				select {
+				case events <- msg.Event.Data:
+				default: // slow consumer; drop rather than stall every command
+				}
+			}
+		}
+		w.mu.Unlock()
+	}
+
+	w.mu.Lock()
+	w.err = ErrClosed
+	if readErr != nil && !websocket.IsCloseError(readErr, websocket.CloseNormalClosure) {
+		w.err = fmt.Errorf("%w: %v", ErrClosed, readErr)
+	}
+	for id, events := range w.subs {
+		delete(w.subs, id)
+		close(events)
+	}
+	w.mu.Unlock()
+	close(w.done)
+}
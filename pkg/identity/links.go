@@ -0,0 +1,416 @@
+package identity
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+)
+
+// LinkCodeTTL is how long a /link code stays valid.
+const LinkCodeTTL = 10 * time.Minute
+
+const (
+	linkCodeLength   = 6
+	linkCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+var (
+	// ErrInvalidLinkCode is returned when a code is unknown, expired or
+	// already used.
+	ErrInvalidLinkCode = errors.New("invalid or expired link code")
+	// ErrSameIdentity is returned when a code is confirmed by the identity
+	// that requested it.
+	ErrSameIdentity = errors.New("link code must be confirmed from a different account")
+	// ErrNotLinked is returned when an identity is not part of any link.
+	ErrNotLinked = errors.New("identity is not linked")
+)
+
+// linksFile is the on-disk format of a LinkStore. Links use the same
+// canonical -> ids shape as session.identity_links in config.
+type linksFile struct {
+	Links     map[string][]string `json:"links"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
+type pendingLink struct {
+	identity  string
+	expiresAt time.Time
+}
+
+// LinkStore persists identity links created at runtime (for example via the
+// /link command). Links are stored in a JSON file that is re-read whenever it
+// changes on disk, so edits made by another process (the launcher admin API)
+// are picked up without a restart. Pending link codes are kept in memory
+// only and do not survive a restart.
+type LinkStore struct {
+	path string
+	now  func() time.Time
+
+	mu      sync.Mutex
+	links   map[string][]string
+	modTime time.Time
+	codes   map[string]pendingLink
+}
+
+// NewLinkStore creates a store backed by path. The file is created lazily.
+func NewLinkStore(path string) *LinkStore {
+	return &LinkStore{
+		path:  path,
+		now:   time.Now,
+		codes: make(map[string]pendingLink),
+	}
+}
+
+// DefaultLinkStorePath returns the identity link file inside a workspace
+// state dir.
+func DefaultLinkStorePath(stateDir string) string {
+	return filepath.Join(stateDir, "identity_links.json")
+}
+
+// Links returns a copy of the stored links.
+func (s *LinkStore) Links() map[string][]string {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.loadLocked(); err != nil {
+		return nil
+	}
+	return cloneLinks(s.links)
+}
+
+// LinkedIdentities returns the canonical name and member identities of the
+// group containing channel:senderID.
+func (s *LinkStore) LinkedIdentities(channel, senderID string) (string, []string) {
+	return s.Group(linkIdentity(channel, senderID))
+}
+
+// Group returns the canonical name and members of the group containing id
+// ("channel:sender"), or "" when id is not linked.
+func (s *LinkStore) Group(id string) (string, []string) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if id == "" {
+		return "", nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.loadLocked(); err != nil {
+		return "", nil
+	}
+	canonical := findLinkGroup(s.links, id)
+	if canonical == "" {
+		return "", nil
+	}
+	return canonical, append([]string(nil), s.links[canonical]...)
+}
+
+// IssueCode creates a one-time code for channel:senderID. Confirming the code
+// from another identity links both identities. Issuing a new code replaces
+// any earlier code of the same identity.
+func (s *LinkStore) IssueCode(channel, senderID string) (string, time.Time, error) {
+	id := linkIdentity(channel, senderID)
+	if id == "" {
+		return "", time.Time{}, fmt.Errorf("sender identity is required")
+	}
+	code, err := newLinkCode()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for existing, pending := range s.codes {
+		if pending.identity == id || !now.Before(pending.expiresAt) {
+			delete(s.codes, existing)
+		}
+	}
+	expiresAt := now.Add(LinkCodeTTL)
+	s.codes[code] = pendingLink{identity: id, expiresAt: expiresAt}
+	return code, expiresAt, nil
+}
+
+// Redeem confirms code from channel:senderID and links the confirming
+// identity to the one that issued the code. The issuer's group (or the issuer
+// itself) stays canonical so its existing sessions keep their identity. It
+// returns the canonical name of the merged group.
+func (s *LinkStore) Redeem(code, channel, senderID string) (string, error) {
+	id := linkIdentity(channel, senderID)
+	if id == "" {
+		return "", fmt.Errorf("sender identity is required")
+	}
+	code = strings.ToUpper(strings.TrimSpace(code))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pending, ok := s.codes[code]
+	if !ok || !s.now().Before(pending.expiresAt) {
+		delete(s.codes, code)
+		return "", ErrInvalidLinkCode
+	}
+	if pending.identity == id {
+		return "", ErrSameIdentity
+	}
+	delete(s.codes, code)
+
+	if err := s.loadLocked(); err != nil {
+		return "", err
+	}
+	links := cloneLinks(s.links)
+	canonical := findLinkGroup(links, pending.identity)
+	if canonical == "" {
+		canonical = pending.identity
+		links[canonical] = []string{pending.identity}
+	}
+	members := []string{id}
+	if other := findLinkGroup(links, id); other != "" {
+		if other == canonical {
+			return canonical, nil
+		}
+		members = links[other]
+		delete(links, other)
+	}
+	links[canonical] = mergeLinkIDs(links[canonical], members)
+	if err := s.saveLocked(links); err != nil {
+		return "", err
+	}
+	return canonical, nil
+}
+
+// Link adds ids to the group named canonical, moving them out of any other
+// group. It is the admin counterpart of IssueCode/Redeem.
+func (s *LinkStore) Link(canonical string, ids ...string) error {
+	canonical = strings.ToLower(strings.TrimSpace(canonical))
+	if canonical == "" {
+		return fmt.Errorf("canonical identity is required")
+	}
+	normalized := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id = strings.ToLower(strings.TrimSpace(id)); id != "" {
+			normalized = append(normalized, id)
+		}
+	}
+	if len(normalized) == 0 {
+		return fmt.Errorf("at least one identity is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.loadLocked(); err != nil {
+		return err
+	}
+	links := cloneLinks(s.links)
+	for _, id := range normalized {
+		if group := findLinkGroup(links, id); group != "" && group != canonical {
+			removeLinkID(links, id)
+		}
+	}
+	links[canonical] = mergeLinkIDs(links[canonical], normalized)
+	return s.saveLocked(links)
+}
+
+// Unlink removes id ("channel:sender") from its group and returns the group's
+// canonical name. Groups left with a single identity are dropped.
+func (s *LinkStore) Unlink(id string) (string, error) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.loadLocked(); err != nil {
+		return "", err
+	}
+	links := cloneLinks(s.links)
+	canonical := removeLinkID(links, id)
+	if canonical == "" {
+		return "", ErrNotLinked
+	}
+	return canonical, s.saveLocked(links)
+}
+
+// Remove deletes the whole group named canonical.
+func (s *LinkStore) Remove(canonical string) error {
+	canonical = strings.ToLower(strings.TrimSpace(canonical))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.loadLocked(); err != nil {
+		return err
+	}
+	if _, ok := s.links[canonical]; !ok {
+		return ErrNotLinked
+	}
+	links := cloneLinks(s.links)
+	delete(links, canonical)
+	return s.saveLocked(links)
+}
+
+// loadLocked refreshes the in-memory links when the file changed on disk.
+func (s *LinkStore) loadLocked() error {
+	info, err := os.Stat(s.path)
+	if os.IsNotExist(err) {
+		s.links = map[string][]string{}
+		s.modTime = time.Time{}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if s.links != nil && info.ModTime().Equal(s.modTime) {
+		return nil
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+	var file linksFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("decode identity links: %w", err)
+	}
+	if file.Links == nil {
+		file.Links = map[string][]string{}
+	}
+	s.links = file.Links
+	s.modTime = info.ModTime()
+	return nil
+}
+
+func (s *LinkStore) saveLocked(links map[string][]string) error {
+	data, err := json.MarshalIndent(linksFile{Links: links, UpdatedAt: s.now()}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	if err := fileutil.WriteFileAtomic(s.path, data, 0o600); err != nil {
+		return err
+	}
+	s.links = links
+	if info, err := os.Stat(s.path); err == nil {
+		s.modTime = info.ModTime()
+	}
+	return nil
+}
+
+// MergeLinks combines configured links with runtime links. Configured links
+// win: runtime ids that already appear in base are ignored so an identity
+// never resolves to two canonical names.
+func MergeLinks(base, extra map[string][]string) map[string][]string {
+	unclaimed := UnclaimedLinks(extra, base)
+	if len(unclaimed) == 0 {
+		return cloneLinks(base)
+	}
+	merged := cloneLinks(base)
+	if merged == nil {
+		merged = make(map[string][]string, len(unclaimed))
+	}
+	for canonical, ids := range unclaimed {
+		merged[canonical] = append(merged[canonical], ids...)
+	}
+	return merged
+}
+
+// UnclaimedLinks returns links without the ids that already appear in
+// claimed. Groups left empty are dropped.
+func UnclaimedLinks(links, claimed map[string][]string) map[string][]string {
+	if len(links) == 0 {
+		return nil
+	}
+	taken := make(map[string]bool)
+	for _, ids := range claimed {
+		for _, id := range ids {
+			taken[strings.ToLower(strings.TrimSpace(id))] = true
+		}
+	}
+	unclaimed := make(map[string][]string, len(links))
+	for canonical, ids := range links {
+		for _, id := range ids {
+			if !taken[strings.ToLower(strings.TrimSpace(id))] {
+				unclaimed[canonical] = append(unclaimed[canonical], id)
+			}
+		}
+	}
+	if len(unclaimed) == 0 {
+		return nil
+	}
+	return unclaimed
+}
+
+func linkIdentity(channel, senderID string) string {
+	return strings.ToLower(BuildCanonicalID(channel, senderID))
+}
+
+func findLinkGroup(links map[string][]string, id string) string {
+	for canonical, ids := range links {
+		for _, member := range ids {
+			if member == id {
+				return canonical
+			}
+		}
+	}
+	return ""
+}
+
+// removeLinkID drops id from its group and returns the group's canonical
+// name, deleting groups that no longer link anything.
+func removeLinkID(links map[string][]string, id string) string {
+	canonical := findLinkGroup(links, id)
+	if canonical == "" {
+		return ""
+	}
+	kept := links[canonical][:0]
+	for _, member := range links[canonical] {
+		if member != id {
+			kept = append(kept, member)
+		}
+	}
+	if len(kept) < 2 {
+		delete(links, canonical)
+	} else {
+		links[canonical] = kept
+	}
+	return canonical
+}
+
+func mergeLinkIDs(ids, more []string) []string {
+	seen := make(map[string]bool, len(ids)+len(more))
+	merged := make([]string, 0, len(ids)+len(more))
+	for _, id := range append(append([]string(nil), ids...), more...) {
+		if !seen[id] {
+			seen[id] = true
+			merged = append(merged, id)
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
+
+func cloneLinks(src map[string][]string) map[string][]string {
+	if src == nil {
+		return nil
+	}
+	cloned := make(map[string][]string, len(src))
+	for canonical, ids := range src {
+		cloned[canonical] = append([]string(nil), ids...)
+	}
+	return cloned
+}
+
+func newLinkCode() (string, error) {
+	buf := make([]byte, linkCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate link code: %w", err)
+	}
+	for i, b := range buf {
+		buf[i] = linkCodeAlphabet[int(b)%len(linkCodeAlphabet)]
+	}
+	return string(buf), nil
+}
@@ -0,0 +1,145 @@
+package identity
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLinkStore(t *testing.T) (*LinkStore, *time.Time) {
+	t.Helper()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	s := NewLinkStore(filepath.Join(t.TempDir(), "state", "identity_links.json"))
+	s.now = func() time.Time { return now }
+	return s, &now
+}
+
+func TestLinkStore_IssueAndRedeem(t *testing.T) {
+	s, _ := newTestLinkStore(t)
+
+	code, expiresAt, err := s.IssueCode("telegram", "123")
+	if err != nil {
+		t.Fatalf("IssueCode() error = %v", err)
+	}
+	if len(code) != linkCodeLength || expiresAt.IsZero() {
+		t.Fatalf("IssueCode() = %q, %v", code, expiresAt)
+	}
+	if _, err := s.Redeem(code, "telegram", "123"); !errors.Is(err, ErrSameIdentity) {
+		t.Fatalf("Redeem(same identity) error = %v, want ErrSameIdentity", err)
+	}
+
+	canonical, err := s.Redeem(" "+strings.ToLower(code)+" ", "Discord", "456")
+	if err != nil {
+		t.Fatalf("Redeem() error = %v", err)
+	}
+	if canonical != "telegram:123" {
+		t.Fatalf("canonical = %q, want issuer identity", canonical)
+	}
+	links := s.Links()
+	if got := links["telegram:123"]; len(got) != 2 || got[0] != "discord:456" || got[1] != "telegram:123" {
+		t.Fatalf("links = %v", links)
+	}
+	if _, err := s.Redeem(code, "slack", "789"); !errors.Is(err, ErrInvalidLinkCode) {
+		t.Fatalf("second Redeem() error = %v, want ErrInvalidLinkCode", err)
+	}
+
+	// A fresh store reads the persisted links.
+	reloaded := NewLinkStore(s.path)
+	if canonical, ids := reloaded.LinkedIdentities("discord", "456"); canonical != "telegram:123" || len(ids) != 2 {
+		t.Fatalf("reloaded LinkedIdentities() = %q, %v", canonical, ids)
+	}
+}
+
+func TestLinkStore_CodeExpires(t *testing.T) {
+	s, now := newTestLinkStore(t)
+
+	code, _, err := s.IssueCode("telegram", "123")
+	if err != nil {
+		t.Fatalf("IssueCode() error = %v", err)
+	}
+	*now = now.Add(LinkCodeTTL)
+	if _, err := s.Redeem(code, "discord", "456"); !errors.Is(err, ErrInvalidLinkCode) {
+		t.Fatalf("Redeem(expired) error = %v, want ErrInvalidLinkCode", err)
+	}
+}
+
+func TestLinkStore_RedeemMergesExistingGroups(t *testing.T) {
+	s, _ := newTestLinkStore(t)
+	if err := s.Link("alice", "telegram:1", "discord:2"); err != nil {
+		t.Fatalf("Link() error = %v", err)
+	}
+	if err := s.Link("alice-work", "slack:3", "line:4"); err != nil {
+		t.Fatalf("Link() error = %v", err)
+	}
+
+	code, _, err := s.IssueCode("discord", "2")
+	if err != nil {
+		t.Fatalf("IssueCode() error = %v", err)
+	}
+	canonical, err := s.Redeem(code, "slack", "3")
+	if err != nil {
+		t.Fatalf("Redeem() error = %v", err)
+	}
+	if canonical != "alice" {
+		t.Fatalf("canonical = %q, want issuer group", canonical)
+	}
+	links := s.Links()
+	if len(links) != 1 || len(links["alice"]) != 4 {
+		t.Fatalf("links = %v, want one merged group", links)
+	}
+}
+
+func TestLinkStore_UnlinkAndRemove(t *testing.T) {
+	s, _ := newTestLinkStore(t)
+	if err := s.Link("alice", "telegram:1", "discord:2", "slack:3"); err != nil {
+		t.Fatalf("Link() error = %v", err)
+	}
+	if err := s.Link("alice", "telegram:1"); err != nil {
+		t.Fatalf("Link(existing member) error = %v", err)
+	}
+	if got := s.Links()["alice"]; len(got) != 3 {
+		t.Fatalf("relinking a member must keep the group, got %v", got)
+	}
+
+	canonical, err := s.Unlink("Discord:2")
+	if err != nil || canonical != "alice" {
+		t.Fatalf("Unlink() = %q, %v", canonical, err)
+	}
+	if _, err := s.Unlink("discord:2"); !errors.Is(err, ErrNotLinked) {
+		t.Fatalf("second Unlink() error = %v, want ErrNotLinked", err)
+	}
+	if _, err := s.Unlink("slack:3"); err != nil {
+		t.Fatalf("Unlink() error = %v", err)
+	}
+	if links := s.Links(); len(links) != 0 {
+		t.Fatalf("single-member group should be dropped, got %v", links)
+	}
+
+	if err := s.Link("bob", "telegram:5", "discord:6"); err != nil {
+		t.Fatalf("Link() error = %v", err)
+	}
+	if err := s.Remove("bob"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if err := s.Remove("bob"); !errors.Is(err, ErrNotLinked) {
+		t.Fatalf("second Remove() error = %v, want ErrNotLinked", err)
+	}
+}
+
+func TestMergeLinks_ConfiguredWins(t *testing.T) {
+	merged := MergeLinks(
+		map[string][]string{"alice": {"Telegram:1"}},
+		map[string][]string{"telegram:1": {"telegram:1", "discord:2"}, "alice": {"slack:3"}},
+	)
+	if got := merged["alice"]; len(got) != 2 {
+		t.Fatalf("merged[alice] = %v, want configured plus runtime ids", got)
+	}
+	if got := merged["telegram:1"]; len(got) != 1 || got[0] != "discord:2" {
+		t.Fatalf("merged[telegram:1] = %v, configured id must not be relinked", got)
+	}
+	if MergeLinks(nil, nil) != nil {
+		t.Fatal("MergeLinks(nil, nil) should be nil")
+	}
+}
@@ -0,0 +1,245 @@
+//go:build linux
+
+package isolation
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const cgroupMountPoint = "/sys/fs/cgroup"
+
+// mainCgroupName is the leaf picoclaw moves itself into, so that
+// controllers can be enabled on the cgroup it started in. cgroup v2 refuses
+// to enable controllers on a group that still holds processes.
+const mainCgroupName = "picoclaw-main"
+
+var cgroupControllers = []string{"memory", "pids", "cpu"}
+
+var (
+	cgroupSeq atomic.Uint64
+
+	preparedMu sync.Mutex
+	prepared   = map[string]bool{}
+)
+
+// cgroupBase returns the cgroup v2 directory under which per-process groups
+// are created: the configured parent, or the cgroup picoclaw runs in.
+func cgroupBase(isolation config.IsolationConfig) (string, error) {
+	if parent := strings.TrimSpace(isolation.CgroupParent); parent != "" {
+		if !filepath.IsAbs(parent) {
+			parent = filepath.Join(cgroupMountPoint, parent)
+		}
+		return filepath.Clean(parent), nil
+	}
+	data, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return "", err
+	}
+	return selfCgroup(data)
+}
+
+// selfCgroup parses /proc/self/cgroup. Once picoclaw has moved itself into
+// its leaf, the cgroup it started in is the leaf's parent.
+func selfCgroup(data []byte) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if rel, ok := strings.CutPrefix(scanner.Text(), "0::"); ok {
+			dir := filepath.Join(cgroupMountPoint, filepath.Clean("/"+rel))
+			if filepath.Base(dir) == mainCgroupName {
+				dir = filepath.Dir(dir)
+			}
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("no cgroup v2 hierarchy in /proc/self/cgroup")
+}
+
+// probeCgroup checks that base is a writable cgroup v2 directory offering the
+// memory, pids and cpu controllers, prepares it for child groups and creates
+// a throwaway child to confirm the controllers reach it. A successful probe
+// is remembered per base.
+func probeCgroup(base string) error {
+	preparedMu.Lock()
+	defer preparedMu.Unlock()
+	if prepared[base] {
+		return nil
+	}
+	if err := checkCgroup(base); err != nil {
+		return err
+	}
+	if err := prepareCgroup(base); err != nil {
+		return err
+	}
+	dir := filepath.Join(base, fmt.Sprintf("picoclaw-probe-%d-%d", os.Getpid(), cgroupSeq.Add(1)))
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		return fmt.Errorf("create cgroup in %s: %w", base, err)
+	}
+	defer os.Remove(dir)
+	controllers, err := os.ReadFile(filepath.Join(dir, "cgroup.controllers"))
+	if err != nil {
+		return err
+	}
+	for _, want := range cgroupControllers {
+		if !slices.Contains(strings.Fields(string(controllers)), want) {
+			return fmt.Errorf("cgroup controller %q is not delegated to children of %s", want, base)
+		}
+	}
+	prepared[base] = true
+	return nil
+}
+
+// prepareCgroup enables the controllers for the children of base. When base
+// holds processes (picoclaw's own cgroup), they are moved into a
+// picoclaw-main leaf first.
+func prepareCgroup(base string) error {
+	subtree := filepath.Join(base, "cgroup.subtree_control")
+	enabled, err := os.ReadFile(subtree)
+	if err != nil {
+		return err
+	}
+	var missing []string
+	for _, controller := range cgroupControllers {
+		if !slices.Contains(strings.Fields(string(enabled)), controller) {
+			missing = append(missing, controller)
+		}
+	}
+	if len(missing) > 0 {
+		if err := evacuateCgroup(base); err != nil {
+			return err
+		}
+		for _, controller := range missing {
+			if err := os.WriteFile(subtree, []byte("+"+controller), 0o644); err != nil {
+				return fmt.Errorf("enable cgroup controller %s in %s: %w (set isolation.cgroup_parent to a delegated cgroup)",
+					controller, base, err)
+			}
+		}
+	}
+	return nil
+}
+
+// evacuateCgroup moves picoclaw into the picoclaw-main leaf of base when it
+// runs in base itself. Other processes are left alone; if any remain,
+// enabling controllers fails and a delegated cgroup_parent is needed.
+func evacuateCgroup(base string) error {
+	procs, err := os.ReadFile(filepath.Join(base, "cgroup.procs"))
+	if err != nil {
+		return err
+	}
+	self := strconv.Itoa(os.Getpid())
+	if !slices.Contains(strings.Fields(string(procs)), self) {
+		return nil
+	}
+	leaf := filepath.Join(base, mainCgroupName)
+	if err := os.Mkdir(leaf, 0o755); err != nil && !os.IsExist(err) {
+		return fmt.Errorf("create cgroup %s: %w", leaf, err)
+	}
+	if err := os.WriteFile(filepath.Join(leaf, "cgroup.procs"), []byte(self), 0o644); err != nil {
+		return fmt.Errorf("move picoclaw into %s: %w", leaf, err)
+	}
+	return nil
+}
+
+// checkCgroup checks the file system, controllers and permissions of base.
+func checkCgroup(base string) error {
+	var fs unix.Statfs_t
+	if err := unix.Statfs(base, &fs); err != nil {
+		return err
+	}
+	if fs.Type != unix.CGROUP2_SUPER_MAGIC {
+		return fmt.Errorf("%s is not a cgroup v2 directory", base)
+	}
+	controllers, err := os.ReadFile(filepath.Join(base, "cgroup.controllers"))
+	if err != nil {
+		return err
+	}
+	available := strings.Fields(string(controllers))
+	for _, want := range cgroupControllers {
+		if !slices.Contains(available, want) {
+			return fmt.Errorf("cgroup controller %q is not available in %s", want, base)
+		}
+	}
+	if err := unix.Access(base, unix.W_OK); err != nil {
+		return fmt.Errorf("%s is not writable: %w", base, err)
+	}
+	return nil
+}
+
+// cgroupLimits converts a profile to cgroup v2 interface file values.
+func cgroupLimits(profile config.IsolationProfile) map[string]string {
+	limits := map[string]string{}
+	if profile.MemoryMB > 0 {
+		limits["memory.max"] = strconv.FormatInt(int64(profile.MemoryMB)<<20, 10)
+	}
+	if profile.MaxProcesses > 0 {
+		limits["pids.max"] = strconv.Itoa(profile.MaxProcesses)
+	}
+	if profile.CPUPercent > 0 {
+		const period = 100000
+		limits["cpu.max"] = fmt.Sprintf("%d %d", profile.CPUPercent*period/100, period)
+	}
+	return limits
+}
+
+// createCgroup makes a child group under base with the profile limits and
+// returns its path and an open directory handle for SysProcAttr.CgroupFD.
+// base must have been prepared by probeCgroup.
+func createCgroup(base string, limits map[string]string) (string, *os.File, error) {
+	dir := filepath.Join(base, fmt.Sprintf("picoclaw-%d-%d", os.Getpid(), cgroupSeq.Add(1)))
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		return "", nil, fmt.Errorf("create cgroup: %w", err)
+	}
+	for file, value := range limits {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(value), 0o644); err != nil {
+			_ = os.Remove(dir)
+			return "", nil, fmt.Errorf("set %s: %w", file, err)
+		}
+	}
+	if _, ok := limits["memory.max"]; ok {
+		// Keep the memory limit from being sidestepped through swap.
+		_ = os.WriteFile(filepath.Join(dir, "memory.swap.max"), []byte("0"), 0o644)
+	}
+	handle, err := os.Open(dir)
+	if err != nil {
+		_ = os.Remove(dir)
+		return "", nil, err
+	}
+	return dir, handle, nil
+}
+
+// killCgroup kills every process in the group (Linux 5.14+).
+func killCgroup(dir string) {
+	_ = os.WriteFile(filepath.Join(dir, "cgroup.kill"), []byte("1"), 0o644)
+}
+
+// reapCgroup removes dir once its last process has exited.
+func reapCgroup(dir string) {
+	for {
+		events, err := os.ReadFile(filepath.Join(dir, "cgroup.events"))
+		if err != nil {
+			return
+		}
+		if bytes.Contains(events, []byte("populated 0")) {
+			if err := os.Remove(dir); err != nil {
+				logger.DebugCF("isolation", "failed to remove cgroup",
+					map[string]any{"cgroup": dir, "error": err.Error()})
+			}
+			return
+		}
+		time.Sleep(time.Second)
+	}
+}
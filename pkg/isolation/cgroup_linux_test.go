@@ -0,0 +1,22 @@
+//go:build linux
+
+package isolation
+
+import "testing"
+
+func TestSelfCgroup(t *testing.T) {
+	cases := map[string]string{
+		"0::/system.slice/picoclaw.service\n":               "/sys/fs/cgroup/system.slice/picoclaw.service",
+		"0::/system.slice/picoclaw.service/picoclaw-main\n": "/sys/fs/cgroup/system.slice/picoclaw.service",
+		"12:memory:/legacy\n0::/\n":                         "/sys/fs/cgroup",
+	}
+	for data, want := range cases {
+		got, err := selfCgroup([]byte(data))
+		if err != nil || got != want {
+			t.Errorf("selfCgroup(%q) = %q, %v; want %q", data, got, err, want)
+		}
+	}
+	if _, err := selfCgroup([]byte("12:memory:/legacy\n")); err == nil {
+		t.Error("selfCgroup() without a v2 entry should fail")
+	}
+}
@@ -0,0 +1,41 @@
+package isolation
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+)
+
+// launcherArg is the hidden first argument that turns the picoclaw binary
+// into the isolation launcher.
+const launcherArg = "__picoclaw-isolation-launch"
+
+// launcherExecutable is the binary re-executed as the launcher; empty until
+// RunLauncherIfRequested has been called.
+var launcherExecutable atomic.Pointer[string]
+
+// RunLauncherIfRequested must be called first thing in main. When the process
+// was started as the isolation launcher it applies the requested limits to
+// itself and execs the target command, never returning. Otherwise it
+// registers the current binary as the launcher for later child processes.
+//
+// Going through the launcher lets rlimits and seccomp filters take effect
+// before the first instruction of the child runs. Without it, rlimits are
+// applied just after start and seccomp requires bwrap.
+func RunLauncherIfRequested() {
+	if len(os.Args) > 1 && os.Args[1] == launcherArg {
+		err := runLauncher(os.Args[2:])
+		fmt.Fprintf(os.Stderr, "picoclaw isolation launcher: %v\n", err)
+		os.Exit(127)
+	}
+	if exe, err := os.Executable(); err == nil {
+		launcherExecutable.Store(&exe)
+	}
+}
+
+func launcherPath() string {
+	if exe := launcherExecutable.Load(); exe != nil {
+		return *exe
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+//go:build linux
+
+package isolation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// launchSpec is passed from the parent to the launcher as JSON.
+type launchSpec struct {
+	Rlimits []launchRlimit `json:"rlimits,omitempty"`
+	Seccomp string         `json:"seccomp,omitempty"`
+}
+
+type launchRlimit struct {
+	Resource int    `json:"resource"`
+	Cur      uint64 `json:"cur"`
+	Max      uint64 `json:"max"`
+}
+
+// launcherArgs wraps path/args so the launcher applies spec before exec.
+func launcherArgs(launcher string, spec launchSpec, path string, args []string) ([]string, error) {
+	encoded, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	wrapped := []string{launcher, launcherArg, string(encoded), path}
+	return append(wrapped, args...), nil
+}
+
+// runLauncher applies the limits in args[0] to the current process and execs
+// args[1] with argv args[2:]. It only returns on failure.
+func runLauncher(args []string) error {
+	if len(args) < 3 {
+		return fmt.Errorf("missing launch spec or command")
+	}
+	var spec launchSpec
+	if err := json.Unmarshal([]byte(args[0]), &spec); err != nil {
+		return fmt.Errorf("invalid launch spec: %w", err)
+	}
+	// The seccomp filter is installed on this thread only, so exec must happen
+	// from the same thread.
+	runtime.LockOSThread()
+	for _, limit := range spec.Rlimits {
+		if err := setRlimit(0, limit); err != nil {
+			return err
+		}
+	}
+	if spec.Seccomp != "" {
+		filter, err := buildSeccompFilter(spec.Seccomp)
+		if err != nil {
+			return err
+		}
+		if err := installSeccompFilter(filter); err != nil {
+			return err
+		}
+	}
+	path := args[1]
+	if err := syscall.Exec(path, args[2:], os.Environ()); err != nil {
+		return fmt.Errorf("exec %s: %w", path, err)
+	}
+	return nil
+}
+
+// setRlimit lowers a resource limit of pid (0 for the current process),
+// clamping to the existing hard limit so unprivileged callers never fail by
+// asking for more than they have.
+func setRlimit(pid int, limit launchRlimit) error {
+	var current unix.Rlimit
+	if err := unix.Prlimit(pid, limit.Resource, nil, &current); err != nil {
+		return fmt.Errorf("read rlimit %d: %w", limit.Resource, err)
+	}
+	next := unix.Rlimit{Cur: min(limit.Cur, current.Max), Max: min(limit.Max, current.Max)}
+	if pid == 0 {
+		// syscall.Setrlimit also stops the Go runtime from restoring its saved
+		// RLIMIT_NOFILE on exec.
+		if err := syscall.Setrlimit(limit.Resource, &syscall.Rlimit{Cur: next.Cur, Max: next.Max}); err != nil {
+			return fmt.Errorf("set rlimit %d: %w", limit.Resource, err)
+		}
+		return nil
+	}
+	if err := unix.Prlimit(pid, limit.Resource, &next, nil); err != nil {
+		return fmt.Errorf("set rlimit %d: %w", limit.Resource, err)
+	}
+	return nil
+}
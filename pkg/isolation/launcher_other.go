@@ -0,0 +1,9 @@
+//go:build !linux
+
+package isolation
+
+import "fmt"
+
+func runLauncher(args []string) error {
+	return fmt.Errorf("the isolation launcher is only available on linux")
+}
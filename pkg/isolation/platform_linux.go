@@ -14,7 +14,12 @@ import (
 	"github.com/sipeed/picoclaw/pkg/logger"
 )
 
-func applyPlatformIsolation(cmd *exec.Cmd, isolation config.IsolationConfig, root string) error {
+func applyPlatformIsolation(
+	cmd *exec.Cmd,
+	isolation config.IsolationConfig,
+	root string,
+	profile config.IsolationProfile,
+) error {
 	if !isolation.Enabled {
 		return nil
 	}
@@ -77,10 +82,15 @@ func applyPlatformIsolation(cmd *exec.Cmd, isolation config.IsolationConfig, roo
 			"working_dir": execDir,
 			"mounts":      formatLinuxMountPlan(plan),
 		})
-	bwrapArgs, err := buildLinuxBwrapArgs(originalPath, resolvedPath, originalArgs, execDir, plan)
+	options, err := bwrapProfileOptions(cmd, profile)
 	if err != nil {
 		return err
 	}
+	bwrapArgs, err := buildLinuxBwrapArgs(originalPath, resolvedPath, originalArgs, execDir, plan, options...)
+	if err != nil {
+		cleanupPendingPlatformResources(cmd)
+		return err
+	}
 
 	cmd.Path = bwrapPath
 	cmd.Args = bwrapArgs
@@ -105,21 +115,16 @@ func formatLinuxMountPlan(plan []MountRule) []map[string]string {
 	return formatted
 }
 
-func postStartPlatformIsolation(cmd *exec.Cmd, isolation config.IsolationConfig, root string) error {
-	return nil
-}
-
-func cleanupPendingPlatformResources(cmd *exec.Cmd) {
-}
-
 // buildLinuxBwrapArgs translates the mount plan into the bubblewrap command
 // line that re-executes the original process inside the isolated mount view.
+// options carries profile flags such as --unshare-net.
 func buildLinuxBwrapArgs(
 	originalPath string,
 	resolvedPath string,
 	originalArgs []string,
 	execDir string,
 	plan []MountRule,
+	options ...string,
 ) ([]string, error) {
 	bwrapArgs := []string{
 		"bwrap",
@@ -128,6 +133,7 @@ func buildLinuxBwrapArgs(
 		"--proc", "/proc",
 		"--dev", "/dev",
 	}
+	bwrapArgs = append(bwrapArgs, options...)
 	for _, rule := range plan {
 		flag, err := linuxBindFlag(rule)
 		if err != nil {
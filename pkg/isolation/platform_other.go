@@ -8,7 +8,12 @@ import (
 	"github.com/sipeed/picoclaw/pkg/config"
 )
 
-func applyPlatformIsolation(cmd *exec.Cmd, isolation config.IsolationConfig, root string) error {
+func applyPlatformIsolation(
+	cmd *exec.Cmd,
+	isolation config.IsolationConfig,
+	root string,
+	profile config.IsolationProfile,
+) error {
 	// Unsupported platforms currently keep the command unchanged. Callers rely on
 	// Preflight and higher-level checks to surface unsupported isolation modes.
 	return nil
@@ -31,7 +31,12 @@ var (
 	procCreateRestrictedToken    = advapi32.NewProc("CreateRestrictedToken")
 )
 
-func applyPlatformIsolation(cmd *exec.Cmd, isolation config.IsolationConfig, root string) error {
+func applyPlatformIsolation(
+	cmd *exec.Cmd,
+	isolation config.IsolationConfig,
+	root string,
+	profile config.IsolationProfile,
+) error {
 	if !isolation.Enabled || cmd == nil {
 		return nil
 	}
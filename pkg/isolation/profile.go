@@ -0,0 +1,242 @@
+package isolation
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// Profile names that are always available. User profiles with the same name
+// replace the built-in definition.
+const (
+	ProfileNone    = "none"
+	ProfileStrict  = "strict"
+	ProfileBuild   = "build"
+	ProfileNetwork = "network"
+)
+
+// Network and seccomp preset values accepted in a profile.
+const (
+	NetworkHost    = "host"
+	NetworkNone    = "none"
+	SeccompDefault = "default"
+	SeccompStrict  = "strict"
+)
+
+var builtinProfiles = map[string]config.IsolationProfile{
+	ProfileStrict: {
+		CPUSeconds:       30,
+		MemoryMB:         256,
+		MaxProcesses:     32,
+		MaxOpenFiles:     256,
+		MaxFileSizeMB:    64,
+		WallClockSeconds: 60,
+		Network:          NetworkNone,
+		Seccomp:          SeccompStrict,
+	},
+	ProfileBuild: {
+		CPUSeconds:       600,
+		MemoryMB:         1024,
+		MaxProcesses:     256,
+		MaxOpenFiles:     1024,
+		MaxFileSizeMB:    1024,
+		WallClockSeconds: 1800,
+		Network:          NetworkHost,
+		Seccomp:          SeccompDefault,
+	},
+	ProfileNetwork: {
+		CPUSeconds:       60,
+		MemoryMB:         256,
+		MaxProcesses:     64,
+		MaxOpenFiles:     512,
+		MaxFileSizeMB:    64,
+		WallClockSeconds: 300,
+		Network:          NetworkHost,
+		Seccomp:          SeccompDefault,
+	},
+}
+
+// Launch selects how a child process is limited.
+type Launch struct {
+	// Profile names the resource profile; empty uses isolation.default_profile.
+	Profile string
+	// Service marks long-running processes such as MCP servers, which are
+	// exempt from the profile's wall-clock limit.
+	Service bool
+}
+
+// Report describes which isolation features the current host can enforce.
+type Report struct {
+	Platform string
+	// Filesystem is true when the filesystem isolation backend is enabled and
+	// available.
+	Filesystem bool
+	// Launcher is true when the picoclaw binary can re-exec itself to apply
+	// limits before the child starts. Without it, rlimits are applied right
+	// after start and seccomp is only available through bwrap.
+	Launcher bool
+	Rlimits  bool
+	// Cgroup is true when per-process cgroup v2 groups can be created under
+	// CgroupPath.
+	Cgroup           bool
+	CgroupPath       string
+	NetworkNamespace bool
+	Seccomp          bool
+	Profiles         []string
+	Notes            []string
+}
+
+// Profiles returns the effective profile set: the built-ins overlaid with the
+// configured profiles.
+func Profiles(isolation config.IsolationConfig) map[string]config.IsolationProfile {
+	profiles := make(map[string]config.IsolationProfile, len(builtinProfiles)+len(isolation.Profiles))
+	for name, profile := range builtinProfiles {
+		profiles[name] = profile
+	}
+	for name, profile := range isolation.Profiles {
+		profiles[strings.TrimSpace(name)] = profile
+	}
+	return profiles
+}
+
+// ResolveProfile returns the named profile from the current configuration.
+// An empty name selects isolation.default_profile; "none" or an empty default
+// returns a zero profile that leaves the process unlimited.
+func ResolveProfile(name string) (config.IsolationProfile, error) {
+	_, profile, err := resolveProfile(CurrentConfig(), name)
+	return profile, err
+}
+
+func resolveProfile(isolation config.IsolationConfig, name string) (string, config.IsolationProfile, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = strings.TrimSpace(isolation.DefaultProfile)
+	}
+	if name == "" || name == ProfileNone {
+		return "", config.IsolationProfile{}, nil
+	}
+	profile, ok := Profiles(isolation)[name]
+	if !ok {
+		return "", config.IsolationProfile{}, fmt.Errorf("unknown isolation profile %q", name)
+	}
+	if err := ValidateProfile(profile); err != nil {
+		return "", config.IsolationProfile{}, fmt.Errorf("isolation profile %q: %w", name, err)
+	}
+	return name, profile, nil
+}
+
+// ValidateProfile checks a profile for negative limits and unknown presets.
+func ValidateProfile(profile config.IsolationProfile) error {
+	limits := map[string]int{
+		"cpu_seconds":        profile.CPUSeconds,
+		"cpu_percent":        profile.CPUPercent,
+		"memory_mb":          profile.MemoryMB,
+		"max_processes":      profile.MaxProcesses,
+		"max_open_files":     profile.MaxOpenFiles,
+		"max_file_size_mb":   profile.MaxFileSizeMB,
+		"wall_clock_seconds": profile.WallClockSeconds,
+	}
+	for field, value := range limits {
+		if value < 0 {
+			return fmt.Errorf("%s must not be negative", field)
+		}
+	}
+	switch profile.Network {
+	case "", NetworkHost, NetworkNone:
+	default:
+		return fmt.Errorf("network must be %q or %q", NetworkHost, NetworkNone)
+	}
+	switch profile.Seccomp {
+	case "", SeccompDefault, SeccompStrict:
+	default:
+		return fmt.Errorf("seccomp must be %q or %q", SeccompDefault, SeccompStrict)
+	}
+	return nil
+}
+
+var warnedOnce sync.Map
+
+// warnOnce logs a degraded-enforcement warning the first time key is seen so
+// every exec call does not repeat it.
+func warnOnce(key, msg string, fields map[string]any) {
+	if _, loaded := warnedOnce.LoadOrStore(key, struct{}{}); !loaded {
+		logger.WarnCF("isolation", msg, fields)
+	}
+}
+
+func profileIsEmpty(profile config.IsolationProfile) bool {
+	return profile == config.IsolationProfile{} || profile == config.IsolationProfile{Network: NetworkHost}
+}
+
+// StartWith is Start with an explicit resource profile selection.
+func StartWith(cmd *exec.Cmd, launch Launch) error {
+	if err := prepareCommand(cmd, launch); err != nil {
+		cleanupPendingPlatformResources(cmd)
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		cleanupPendingPlatformResources(cmd)
+		return err
+	}
+	isolation := CurrentConfig()
+	root := ""
+	if isolation.Enabled {
+		var err error
+		root, err = ResolveInstanceRoot()
+		if err != nil {
+			terminateStartedCommand(cmd)
+			return err
+		}
+	}
+	if err := postStartPlatformIsolation(cmd, isolation, root); err != nil {
+		terminateStartedCommand(cmd)
+		return err
+	}
+	return nil
+}
+
+// RunWith is Run with an explicit resource profile selection.
+func RunWith(cmd *exec.Cmd, launch Launch) error {
+	if err := StartWith(cmd, launch); err != nil {
+		return err
+	}
+	return Wait(cmd)
+}
+
+// Wait waits for a command started with Start or StartWith and stops the
+// profile's wall-clock limit for it.
+func Wait(cmd *exec.Cmd) error {
+	defer stopPlatformWatchdogs(cmd)
+	return cmd.Wait()
+}
+
+// Preflight validates the configured isolation state, prepares the instance
+// runtime directories, and reports which isolation features are enforceable
+// on this host.
+func Preflight() (Report, error) {
+	isolation := CurrentConfig()
+	report := probePlatform(isolation)
+	report.Platform = runtime.GOOS + "/" + runtime.GOARCH
+	for name := range Profiles(isolation) {
+		report.Profiles = append(report.Profiles, name)
+	}
+	sort.Strings(report.Profiles)
+	if err := validateIsolation(isolation); err != nil {
+		return report, err
+	}
+	if _, _, err := resolveProfile(isolation, ""); err != nil {
+		return report, fmt.Errorf("default_profile: %w", err)
+	}
+	for _, name := range report.Profiles {
+		if err := ValidateProfile(Profiles(isolation)[name]); err != nil {
+			return report, fmt.Errorf("isolation profile %q: %w", name, err)
+		}
+	}
+	return report, nil
+}
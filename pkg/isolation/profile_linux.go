@@ -0,0 +1,301 @@
+//go:build linux
+
+package isolation
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// linuxLaunchState carries per-command resources from PrepareCommand to the
+// post-start hook.
+type linuxLaunchState struct {
+	profile   string
+	files     []*os.File
+	cgroup    string
+	rlimits   []launchRlimit
+	wallClock time.Duration
+}
+
+var linuxPendingResources sync.Map
+
+// linuxWallClocks holds the wall-clock timer of each started command until
+// Wait stops it.
+var linuxWallClocks sync.Map
+
+func pendingLinuxState(cmd *exec.Cmd) *linuxLaunchState {
+	state, _ := linuxPendingResources.LoadOrStore(cmd, &linuxLaunchState{})
+	return state.(*linuxLaunchState)
+}
+
+// bwrapProfileOptions returns the bwrap flags that enforce the network and
+// seccomp parts of a profile inside the filesystem sandbox.
+func bwrapProfileOptions(cmd *exec.Cmd, profile config.IsolationProfile) ([]string, error) {
+	var options []string
+	if profile.Network == NetworkNone {
+		options = append(options, "--unshare-net")
+	}
+	if profile.Seccomp != "" {
+		filter, err := buildSeccompFilter(profile.Seccomp)
+		if err != nil {
+			return nil, err
+		}
+		file, err := seccompFilterFile(filter)
+		if err != nil {
+			return nil, err
+		}
+		state := pendingLinuxState(cmd)
+		state.files = append(state.files, file)
+		cmd.ExtraFiles = append(cmd.ExtraFiles, file)
+		options = append(options, "--seccomp", strconv.Itoa(2+len(cmd.ExtraFiles)))
+	}
+	return options, nil
+}
+
+func applyPlatformProfile(
+	cmd *exec.Cmd,
+	isolation config.IsolationConfig,
+	name string,
+	profile config.IsolationProfile,
+) error {
+	if cmd == nil || cmd.Err != nil || cmd.Path == "" {
+		return nil
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	state := pendingLinuxState(cmd)
+	state.profile = name
+	// With filesystem isolation on, bwrap already unshared the network and
+	// installed the seccomp filter.
+	bwrapped := isolation.Enabled
+
+	if profile.Network == NetworkNone && !bwrapped {
+		if !userNamespacesAvailable() {
+			return fmt.Errorf("isolation profile %q requires a network namespace, which this host cannot create", name)
+		}
+		cmd.SysProcAttr.Cloneflags |= unix.CLONE_NEWNET
+		if os.Geteuid() != 0 {
+			cmd.SysProcAttr.Cloneflags |= unix.CLONE_NEWUSER
+			cmd.SysProcAttr.UidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getuid(), HostID: os.Getuid(), Size: 1}}
+			cmd.SysProcAttr.GidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getgid(), HostID: os.Getgid(), Size: 1}}
+			cmd.SysProcAttr.GidMappingsEnableSetgroups = false
+		}
+	}
+
+	inCgroup := false
+	if limits := cgroupLimits(profile); len(limits) > 0 {
+		base, err := cgroupBase(isolation)
+		if err == nil {
+			err = probeCgroup(base)
+		}
+		var dir string
+		var handle *os.File
+		if err == nil {
+			dir, handle, err = createCgroup(base, limits)
+		}
+		if err != nil {
+			warnOnce("cgroup:"+err.Error(), "cgroup v2 limits unavailable; falling back to rlimits",
+				map[string]any{"profile": name, "error": err.Error()})
+		} else {
+			state.files = append(state.files, handle)
+			state.cgroup = dir
+			cmd.SysProcAttr.UseCgroupFD = true
+			cmd.SysProcAttr.CgroupFD = int(handle.Fd())
+			inCgroup = true
+		}
+	}
+	if !inCgroup && (profile.MaxProcesses > 0 || profile.CPUPercent > 0) {
+		warnOnce("cgroup-only:"+name, "max_processes and cpu_percent need cgroup v2 and are not enforced",
+			map[string]any{"profile": name})
+	}
+	rlimits := profileRlimits(profile, inCgroup)
+
+	seccomp := ""
+	if profile.Seccomp != "" && !bwrapped {
+		if launcherPath() == "" {
+			return fmt.Errorf("isolation profile %q needs the picoclaw launcher or bwrap to apply seccomp", name)
+		}
+		if !seccompSupported() {
+			return fmt.Errorf("isolation profile %q requests seccomp, which is unavailable on this host", name)
+		}
+		seccomp = profile.Seccomp
+	}
+	if launcher := launcherPath(); launcher != "" && (len(rlimits) > 0 || seccomp != "") {
+		args, err := launcherArgs(launcher, launchSpec{Rlimits: rlimits, Seccomp: seccomp}, cmd.Path, cmd.Args)
+		if err != nil {
+			return err
+		}
+		cmd.Path = launcher
+		cmd.Args = args
+	} else {
+		// Without the launcher the limits land a moment after start.
+		state.rlimits = rlimits
+	}
+	state.wallClock = time.Duration(profile.WallClockSeconds) * time.Second
+	logger.DebugCF("isolation", "applied isolation profile",
+		map[string]any{
+			"profile":    name,
+			"cgroup":     state.cgroup,
+			"rlimits":    len(rlimits),
+			"seccomp":    profile.Seccomp,
+			"network":    profile.Network,
+			"wall_clock": profile.WallClockSeconds,
+		})
+	return nil
+}
+
+// profileRlimits converts a profile to rlimits. Memory falls back to the
+// address-space limit when no cgroup enforces it. RLIMIT_NPROC is not used
+// for max_processes because it counts every process of the user.
+func profileRlimits(profile config.IsolationProfile, inCgroup bool) []launchRlimit {
+	var limits []launchRlimit
+	if profile.CPUSeconds > 0 {
+		// The soft limit sends SIGXCPU, the hard limit one second later SIGKILL.
+		cpu := uint64(profile.CPUSeconds)
+		limits = append(limits, launchRlimit{Resource: unix.RLIMIT_CPU, Cur: cpu, Max: cpu + 1})
+	}
+	if profile.MaxOpenFiles > 0 {
+		n := uint64(profile.MaxOpenFiles)
+		limits = append(limits, launchRlimit{Resource: unix.RLIMIT_NOFILE, Cur: n, Max: n})
+	}
+	if profile.MaxFileSizeMB > 0 {
+		n := uint64(profile.MaxFileSizeMB) << 20
+		limits = append(limits, launchRlimit{Resource: unix.RLIMIT_FSIZE, Cur: n, Max: n})
+	}
+	if profile.MemoryMB > 0 && !inCgroup {
+		n := uint64(profile.MemoryMB) << 20
+		limits = append(limits, launchRlimit{Resource: unix.RLIMIT_AS, Cur: n, Max: n})
+	}
+	return limits
+}
+
+func postStartPlatformIsolation(cmd *exec.Cmd, isolation config.IsolationConfig, root string) error {
+	value, ok := linuxPendingResources.LoadAndDelete(cmd)
+	if !ok {
+		return nil
+	}
+	state := value.(*linuxLaunchState)
+	for _, file := range state.files {
+		_ = file.Close()
+	}
+	if cmd.Process == nil {
+		return nil
+	}
+	pid := cmd.Process.Pid
+	for _, limit := range state.rlimits {
+		if err := setRlimit(pid, limit); err != nil {
+			logger.WarnCF("isolation", "failed to apply rlimit after start",
+				map[string]any{"profile": state.profile, "pid": pid, "error": err.Error()})
+		}
+	}
+	if state.cgroup != "" {
+		go reapCgroup(state.cgroup)
+	}
+	if state.wallClock > 0 {
+		timer := time.AfterFunc(state.wallClock, func() {
+			if err := cmd.Process.Kill(); err != nil {
+				return
+			}
+			if state.cgroup != "" {
+				killCgroup(state.cgroup)
+			}
+			if cmd.SysProcAttr != nil && cmd.SysProcAttr.Setpgid {
+				_ = syscall.Kill(-pid, syscall.SIGKILL)
+			}
+			logger.WarnCF("isolation", "killed child process at wall-clock limit",
+				map[string]any{"profile": state.profile, "pid": pid, "seconds": state.wallClock.Seconds()})
+		})
+		linuxWallClocks.Store(cmd, timer)
+	}
+	return nil
+}
+
+// stopPlatformWatchdogs stops the wall-clock timer of a command that has
+// been waited for, so it cannot fire at a reused pid or process group.
+func stopPlatformWatchdogs(cmd *exec.Cmd) {
+	if timer, ok := linuxWallClocks.LoadAndDelete(cmd); ok {
+		timer.(*time.Timer).Stop()
+	}
+}
+
+func cleanupPendingPlatformResources(cmd *exec.Cmd) {
+	value, ok := linuxPendingResources.LoadAndDelete(cmd)
+	if !ok {
+		return
+	}
+	state := value.(*linuxLaunchState)
+	for _, file := range state.files {
+		_ = file.Close()
+	}
+	if state.cgroup != "" {
+		_ = os.Remove(state.cgroup)
+	}
+}
+
+// userNamespacesAvailable reports whether a network namespace can be created
+// for a child: directly as root, or inside a new user namespace otherwise.
+func userNamespacesAvailable() bool {
+	if _, err := os.Stat("/proc/self/ns/net"); err != nil {
+		return false
+	}
+	if os.Geteuid() == 0 {
+		return true
+	}
+	if data, err := os.ReadFile("/proc/sys/kernel/unprivileged_userns_clone"); err == nil &&
+		strings.TrimSpace(string(data)) == "0" {
+		return false
+	}
+	data, err := os.ReadFile("/proc/sys/user/max_user_namespaces")
+	if err != nil {
+		return false
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	return err == nil && n > 0
+}
+
+func probePlatform(isolation config.IsolationConfig) Report {
+	var report Report
+	_, bwrapErr := exec.LookPath("bwrap")
+	report.Filesystem = isolation.Enabled && bwrapErr == nil
+	if isolation.Enabled && bwrapErr != nil {
+		report.Notes = append(report.Notes, "bwrap not found; isolated commands will fail to start: "+bwrapInstallHint())
+	}
+	report.Launcher = launcherPath() != ""
+	if !report.Launcher {
+		report.Notes = append(report.Notes,
+			"launcher not registered; rlimits are applied just after start and seccomp requires bwrap")
+	}
+	report.Rlimits = true
+	base, err := cgroupBase(isolation)
+	if err == nil {
+		report.CgroupPath = base
+		err = probeCgroup(base)
+	}
+	if err == nil {
+		report.Cgroup = true
+	} else {
+		report.Notes = append(report.Notes,
+			"cgroup v2 unavailable ("+err.Error()+"); memory_mb falls back to RLIMIT_AS, max_processes and cpu_percent are not enforced")
+	}
+	report.NetworkNamespace = report.Filesystem || userNamespacesAvailable()
+	if !report.NetworkNamespace {
+		report.Notes = append(report.Notes, `profiles with "network": "none" will refuse to start commands`)
+	}
+	report.Seccomp = seccompSupported() && (report.Filesystem || report.Launcher)
+	if !report.Seccomp {
+		report.Notes = append(report.Notes, "seccomp unavailable; profiles with a seccomp preset will refuse to start commands")
+	}
+	return report
+}
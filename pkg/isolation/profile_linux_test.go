@@ -0,0 +1,243 @@
+//go:build linux
+
+package isolation
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/bpf"
+	"golang.org/x/sys/unix"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+const helperEnv = "PICOCLAW_ISOLATION_TEST_HELPER"
+
+func TestMain(m *testing.M) {
+	RunLauncherIfRequested()
+	if os.Getenv(helperEnv) == "socket" {
+		fd, err := unix.Socket(unix.AF_INET, unix.SOCK_STREAM, 0)
+		if err != nil {
+			fmt.Println("inet:", err)
+		} else {
+			unix.Close(fd)
+			fmt.Println("inet: allowed")
+		}
+		if fd, err := unix.Socket(unix.AF_UNIX, unix.SOCK_STREAM, 0); err == nil {
+			unix.Close(fd)
+			fmt.Println("unix: allowed")
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func configureProfile(t *testing.T, profile config.IsolationProfile) {
+	t.Helper()
+	t.Setenv(config.EnvHome, filepath.Join(t.TempDir(), "home"))
+	cfg := config.DefaultConfig()
+	cfg.Isolation.Profiles = map[string]config.IsolationProfile{"test": profile}
+	Configure(cfg)
+	t.Cleanup(func() { Configure(config.DefaultConfig()) })
+}
+
+func runSeccompFilter(t *testing.T, filter []unix.SockFilter, arch, nr, arg0 uint32) uint32 {
+	t.Helper()
+	raw := make([]bpf.RawInstruction, len(filter))
+	for i, ins := range filter {
+		raw[i] = bpf.RawInstruction{Op: ins.Code, Jt: ins.Jt, Jf: ins.Jf, K: ins.K}
+	}
+	prog, ok := bpf.Disassemble(raw)
+	if !ok {
+		t.Fatal("bpf.Disassemble() could not decode the filter")
+	}
+	vm, err := bpf.NewVM(prog)
+	if err != nil {
+		t.Fatalf("bpf.NewVM() error = %v", err)
+	}
+	// The bpf VM loads words big endian; the kernel loads seccomp_data in
+	// native order, so encode the fields the way the VM expects to read them.
+	data := make([]byte, 64)
+	binary.BigEndian.PutUint32(data[0:], nr)
+	binary.BigEndian.PutUint32(data[4:], arch)
+	binary.BigEndian.PutUint32(data[16:], arg0)
+	ret, err := vm.Run(data)
+	if err != nil {
+		t.Fatalf("vm.Run() error = %v", err)
+	}
+	return uint32(ret)
+}
+
+func TestBuildSeccompFilter(t *testing.T) {
+	arch, ok := seccompAuditArch()
+	if !ok {
+		t.Skip("no seccomp filter for this architecture")
+	}
+	deny := unix.SECCOMP_RET_ERRNO | uint32(unix.EPERM)
+
+	filter, err := buildSeccompFilter(SeccompDefault)
+	if err != nil {
+		t.Fatalf("buildSeccompFilter(default) error = %v", err)
+	}
+	if got := runSeccompFilter(t, filter, arch, unix.SYS_MOUNT, 0); got != deny {
+		t.Fatalf("mount = %#x, want deny", got)
+	}
+	if got := runSeccompFilter(t, filter, arch, unix.SYS_GETPID, 0); got != unix.SECCOMP_RET_ALLOW {
+		t.Fatalf("getpid = %#x, want allow", got)
+	}
+	if got := runSeccompFilter(t, filter, arch, unix.SYS_SOCKET, unix.AF_INET); got != unix.SECCOMP_RET_ALLOW {
+		t.Fatalf("default socket(AF_INET) = %#x, want allow", got)
+	}
+	if got := runSeccompFilter(t, filter, arch+1, unix.SYS_GETPID, 0); got != deny {
+		t.Fatalf("foreign arch = %#x, want deny", got)
+	}
+
+	filter, err = buildSeccompFilter(SeccompStrict)
+	if err != nil {
+		t.Fatalf("buildSeccompFilter(strict) error = %v", err)
+	}
+	if got := runSeccompFilter(t, filter, arch, unix.SYS_UNSHARE, 0); got != deny {
+		t.Fatalf("strict unshare = %#x, want deny", got)
+	}
+	if got := runSeccompFilter(t, filter, arch, unix.SYS_SOCKET, unix.AF_INET); got == unix.SECCOMP_RET_ALLOW {
+		t.Fatal("strict socket(AF_INET) allowed")
+	}
+	if got := runSeccompFilter(t, filter, arch, unix.SYS_SOCKET, unix.AF_UNIX); got != unix.SECCOMP_RET_ALLOW {
+		t.Fatalf("strict socket(AF_UNIX) = %#x, want allow", got)
+	}
+
+	if _, err := buildSeccompFilter("paranoid"); err == nil {
+		t.Fatal("buildSeccompFilter(paranoid) expected error")
+	}
+}
+
+func TestBwrapProfileOptions(t *testing.T) {
+	if !seccompSupported() {
+		t.Skip("seccomp not supported")
+	}
+	cmd := exec.Command("true")
+	options, err := bwrapProfileOptions(cmd, config.IsolationProfile{Network: NetworkNone, Seccomp: SeccompDefault})
+	if err != nil {
+		t.Fatalf("bwrapProfileOptions() error = %v", err)
+	}
+	t.Cleanup(func() { cleanupPendingPlatformResources(cmd) })
+	want := []string{"--unshare-net", "--seccomp", "3"}
+	if !slices.Equal(options, want) {
+		t.Fatalf("bwrapProfileOptions() = %v, want %v", options, want)
+	}
+	if len(cmd.ExtraFiles) != 1 {
+		t.Fatalf("ExtraFiles = %d, want 1", len(cmd.ExtraFiles))
+	}
+	args, err := buildLinuxBwrapArgs("/bin/true", "/bin/true", []string{"/bin/true"}, "", nil, options...)
+	if err != nil {
+		t.Fatalf("buildLinuxBwrapArgs() error = %v", err)
+	}
+	if !slices.Contains(args, "--unshare-net") || slices.Index(args, "--") < slices.Index(args, "--seccomp") {
+		t.Fatalf("buildLinuxBwrapArgs() = %v", args)
+	}
+}
+
+func TestStartWith_AppliesRlimitsThroughLauncher(t *testing.T) {
+	configureProfile(t, config.IsolationProfile{MaxOpenFiles: 64, MaxFileSizeMB: 1})
+	cmd := exec.Command("sh", "-c", "ulimit -n; ulimit -f")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := RunWith(cmd, Launch{Profile: "test"}); err != nil {
+		t.Fatalf("RunWith() error = %v, output = %s", err, out.String())
+	}
+	if len(cmd.Args) < 2 || cmd.Args[1] != launcherArg {
+		t.Fatalf("command was not wrapped by the launcher: %v", cmd.Args)
+	}
+	// ulimit -f counts 512-byte blocks in dash and 1024-byte blocks in bash.
+	got := strings.Fields(out.String())
+	if len(got) != 2 || got[0] != "64" || (got[1] != "2048" && got[1] != "1024") {
+		t.Fatalf("limits = %q, want 64 open files and a 1 MiB file size", out.String())
+	}
+}
+
+func TestStartWith_StrictSeccompDeniesInetSockets(t *testing.T) {
+	if !seccompSupported() {
+		t.Skip("seccomp not supported")
+	}
+	configureProfile(t, config.IsolationProfile{Seccomp: SeccompStrict})
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = append(os.Environ(), helperEnv+"=socket")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := RunWith(cmd, Launch{Profile: "test"}); err != nil {
+		t.Fatalf("RunWith() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "inet: permission denied") || !strings.Contains(out.String(), "unix: allowed") {
+		t.Fatalf("helper output = %q", out.String())
+	}
+}
+
+func TestStartWith_WallClockKillsCommand(t *testing.T) {
+	configureProfile(t, config.IsolationProfile{WallClockSeconds: 1})
+	cmd := exec.Command("sleep", "30")
+	started := time.Now()
+	err := RunWith(cmd, Launch{Profile: "test"})
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("RunWith() error = %v, want exit error", err)
+	}
+	if elapsed := time.Since(started); elapsed > 10*time.Second {
+		t.Fatalf("command ran for %v past the wall-clock limit", elapsed)
+	}
+
+	cmd = exec.Command("sleep", "2")
+	if err := RunWith(cmd, Launch{Profile: "test", Service: true}); err != nil {
+		t.Fatalf("RunWith(service) error = %v", err)
+	}
+
+	cmd = exec.Command("true")
+	if err := RunWith(cmd, Launch{Profile: "test"}); err != nil {
+		t.Fatalf("RunWith() error = %v", err)
+	}
+	if _, ok := linuxWallClocks.Load(cmd); ok {
+		t.Fatal("wall-clock timer still armed after Wait")
+	}
+}
+
+func TestPreflight_ReportsLinuxCapabilities(t *testing.T) {
+	configureProfile(t, config.IsolationProfile{})
+	report, err := Preflight()
+	if err != nil {
+		t.Fatalf("Preflight() error = %v", err)
+	}
+	if !report.Launcher || !report.Rlimits {
+		t.Fatalf("Preflight() = %+v, want launcher and rlimits", report)
+	}
+	if report.Seccomp != seccompSupported() {
+		t.Fatalf("Preflight().Seccomp = %v, want %v", report.Seccomp, seccompSupported())
+	}
+}
+
+func TestStartWith_NetworkNoneUsesPrivateNamespace(t *testing.T) {
+	if !userNamespacesAvailable() {
+		t.Skip("network namespaces not available")
+	}
+	configureProfile(t, config.IsolationProfile{Network: NetworkNone})
+	cmd := exec.Command("cat", "/proc/net/dev")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := RunWith(cmd, Launch{Profile: "test"}); err != nil {
+		t.Skipf("cannot create network namespace here: %v", err)
+	}
+	for _, line := range strings.Split(out.String(), "\n")[2:] {
+		if name, _, ok := strings.Cut(strings.TrimSpace(line), ":"); ok && name != "lo" {
+			t.Fatalf("interface %q visible inside network namespace:\n%s", name, out.String())
+		}
+	}
+}
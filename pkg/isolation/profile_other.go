@@ -0,0 +1,37 @@
+//go:build !linux
+
+package isolation
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func applyPlatformProfile(
+	cmd *exec.Cmd,
+	isolation config.IsolationConfig,
+	name string,
+	profile config.IsolationProfile,
+) error {
+	// Network and seccomp restrictions fail closed; plain resource limits are
+	// advisory until a backend exists for this platform.
+	if profile.Network == NetworkNone || profile.Seccomp != "" {
+		return fmt.Errorf("isolation profile %q requires network or seccomp isolation, which is not supported on %s",
+			name, runtime.GOOS)
+	}
+	warnOnce("profile-unsupported:"+name, "isolation profile resource limits are not enforced on this platform",
+		map[string]any{"profile": name, "os": runtime.GOOS})
+	return nil
+}
+
+func probePlatform(isolation config.IsolationConfig) Report {
+	report := Report{Filesystem: isolation.Enabled && IsSupported()}
+	report.Notes = append(report.Notes, "resource profiles are not enforced on "+runtime.GOOS)
+	return report
+}
+
+func stopPlatformWatchdogs(cmd *exec.Cmd) {
+}
@@ -0,0 +1,101 @@
+package isolation
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestResolveProfile(t *testing.T) {
+	cfg := config.DefaultConfig()
+	Configure(cfg)
+	t.Cleanup(func() { Configure(config.DefaultConfig()) })
+
+	profile, err := ResolveProfile("")
+	if err != nil || profile != (config.IsolationProfile{}) {
+		t.Fatalf("ResolveProfile(\"\") = %+v, %v; want zero profile", profile, err)
+	}
+	profile, err = ResolveProfile(ProfileStrict)
+	if err != nil {
+		t.Fatalf("ResolveProfile(strict) error = %v", err)
+	}
+	if profile.Network != NetworkNone || profile.Seccomp != SeccompStrict || profile.MemoryMB == 0 {
+		t.Fatalf("ResolveProfile(strict) = %+v", profile)
+	}
+	if _, err := ResolveProfile("missing"); err == nil || !strings.Contains(err.Error(), "unknown isolation profile") {
+		t.Fatalf("ResolveProfile(missing) error = %v", err)
+	}
+
+	cfg.Isolation.DefaultProfile = "tiny"
+	cfg.Isolation.Profiles = map[string]config.IsolationProfile{
+		"tiny":        {MaxOpenFiles: 32},
+		ProfileStrict: {CPUSeconds: 5},
+	}
+	Configure(cfg)
+	profile, err = ResolveProfile("")
+	if err != nil || profile.MaxOpenFiles != 32 {
+		t.Fatalf("ResolveProfile(default) = %+v, %v", profile, err)
+	}
+	profile, err = ResolveProfile(ProfileStrict)
+	if err != nil || profile != (config.IsolationProfile{CPUSeconds: 5}) {
+		t.Fatalf("ResolveProfile(overridden strict) = %+v, %v", profile, err)
+	}
+	profile, err = ResolveProfile(ProfileNone)
+	if err != nil || profile != (config.IsolationProfile{}) {
+		t.Fatalf("ResolveProfile(none) = %+v, %v", profile, err)
+	}
+}
+
+func TestValidateProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile config.IsolationProfile
+		wantErr string
+	}{
+		{name: "valid", profile: config.IsolationProfile{MemoryMB: 64, Network: NetworkNone, Seccomp: SeccompDefault}},
+		{name: "negative", profile: config.IsolationProfile{MemoryMB: -1}, wantErr: "memory_mb"},
+		{name: "network", profile: config.IsolationProfile{Network: "bridge"}, wantErr: "network"},
+		{name: "seccomp", profile: config.IsolationProfile{Seccomp: "paranoid"}, wantErr: "seccomp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateProfile(tt.profile)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("ValidateProfile() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("ValidateProfile() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPreflight_ReportsProfilesAndRejectsBadDefault(t *testing.T) {
+	t.Setenv(config.EnvHome, filepath.Join(t.TempDir(), "home"))
+	cfg := config.DefaultConfig()
+	cfg.Isolation.Profiles = map[string]config.IsolationProfile{"ci": {CPUSeconds: 120}}
+	Configure(cfg)
+	t.Cleanup(func() { Configure(config.DefaultConfig()) })
+
+	report, err := Preflight()
+	if err != nil {
+		t.Fatalf("Preflight() error = %v", err)
+	}
+	for _, name := range []string{"ci", ProfileBuild, ProfileNetwork, ProfileStrict} {
+		if !slices.Contains(report.Profiles, name) {
+			t.Fatalf("Preflight().Profiles = %v, missing %q", report.Profiles, name)
+		}
+	}
+	if report.Platform == "" {
+		t.Fatal("Preflight().Platform is empty")
+	}
+
+	cfg.Isolation.DefaultProfile = "missing"
+	Configure(cfg)
+	if _, err := Preflight(); err == nil || !strings.Contains(err.Error(), "default_profile") {
+		t.Fatalf("Preflight() error = %v, want default_profile error", err)
+	}
+}
@@ -319,10 +319,9 @@ func isSupportedOn(goos string) bool {
 	}
 }
 
-// Preflight validates the configured isolation state and prepares the instance
-// runtime directories before any child process is launched.
-func Preflight() error {
-	isolation := CurrentConfig()
+// validateIsolation checks the filesystem isolation settings and prepares the
+// instance runtime directories before any child process is launched.
+func validateIsolation(isolation config.IsolationConfig) error {
 	if !isolation.Enabled {
 		return nil
 	}
@@ -360,57 +359,16 @@ func Preflight() error {
 }
 
 // Start prepares isolation for the command, starts it, and applies any
-// post-start platform hooks required by the active backend.
+// post-start platform hooks required by the active backend. The default
+// resource profile applies.
 func Start(cmd *exec.Cmd) error {
-	if err := PrepareCommand(cmd); err != nil {
-		return err
-	}
-	if err := cmd.Start(); err != nil {
-		cleanupPendingPlatformResources(cmd)
-		return err
-	}
-	isolation := CurrentConfig()
-	root := ""
-	if isolation.Enabled {
-		var err error
-		root, err = ResolveInstanceRoot()
-		if err != nil {
-			terminateStartedCommand(cmd)
-			return err
-		}
-	}
-	if err := postStartPlatformIsolation(cmd, isolation, root); err != nil {
-		terminateStartedCommand(cmd)
-		return err
-	}
-	return nil
+	return StartWith(cmd, Launch{})
 }
 
 // Run is the Start-and-Wait helper that keeps the same isolation behavior as
 // Start while returning the command's final exit status.
 func Run(cmd *exec.Cmd) error {
-	if err := PrepareCommand(cmd); err != nil {
-		return err
-	}
-	if err := cmd.Start(); err != nil {
-		cleanupPendingPlatformResources(cmd)
-		return err
-	}
-	isolation := CurrentConfig()
-	root := ""
-	if isolation.Enabled {
-		var err error
-		root, err = ResolveInstanceRoot()
-		if err != nil {
-			terminateStartedCommand(cmd)
-			return err
-		}
-	}
-	if err := postStartPlatformIsolation(cmd, isolation, root); err != nil {
-		terminateStartedCommand(cmd)
-		return err
-	}
-	return cmd.Wait()
+	return RunWith(cmd, Launch{})
 }
 
 func terminateStartedCommand(cmd *exec.Cmd) {
@@ -423,21 +381,36 @@ func terminateStartedCommand(cmd *exec.Cmd) {
 }
 
 // PrepareCommand mutates the command in-place so it inherits the configured
-// isolated environment before being started by the caller.
+// isolated environment and default resource profile before being started by
+// the caller.
 func PrepareCommand(cmd *exec.Cmd) error {
+	return prepareCommand(cmd, Launch{})
+}
+
+func prepareCommand(cmd *exec.Cmd, launch Launch) error {
 	isolation := CurrentConfig()
-	if err := Preflight(); err != nil {
+	if err := validateIsolation(isolation); err != nil {
+		return err
+	}
+	name, profile, err := resolveProfile(isolation, launch.Profile)
+	if err != nil {
 		return err
 	}
+	if launch.Service {
+		profile.WallClockSeconds = 0
+	}
 	if isolation.Enabled {
 		root, err := ResolveInstanceRoot()
 		if err != nil {
 			return err
 		}
 		ApplyUserEnv(cmd, root)
-		if err := applyPlatformIsolation(cmd, isolation, root); err != nil {
+		if err := applyPlatformIsolation(cmd, isolation, root, profile); err != nil {
 			return err
 		}
 	}
-	return nil
+	if name == "" || profileIsEmpty(profile) {
+		return nil
+	}
+	return applyPlatformProfile(cmd, isolation, name, profile)
 }
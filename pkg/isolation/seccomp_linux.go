@@ -0,0 +1,196 @@
+//go:build linux
+
+package isolation
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/net/bpf"
+	"golang.org/x/sys/unix"
+)
+
+// seccompDenied lists syscalls no agent-launched tool needs: mounting,
+// kernel modules, tracing other processes, changing system time and the
+// like. They fail with EPERM rather than killing the process so tools can
+// report a readable error.
+var seccompDenied = []uint32{
+	unix.SYS_MOUNT,
+	unix.SYS_UMOUNT2,
+	unix.SYS_PIVOT_ROOT,
+	unix.SYS_FSOPEN,
+	unix.SYS_FSMOUNT,
+	unix.SYS_MOVE_MOUNT,
+	unix.SYS_OPEN_TREE,
+	unix.SYS_SWAPON,
+	unix.SYS_SWAPOFF,
+	unix.SYS_REBOOT,
+	unix.SYS_KEXEC_LOAD,
+	unix.SYS_INIT_MODULE,
+	unix.SYS_FINIT_MODULE,
+	unix.SYS_DELETE_MODULE,
+	unix.SYS_PTRACE,
+	unix.SYS_PROCESS_VM_READV,
+	unix.SYS_PROCESS_VM_WRITEV,
+	unix.SYS_BPF,
+	unix.SYS_PERF_EVENT_OPEN,
+	unix.SYS_KEYCTL,
+	unix.SYS_ADD_KEY,
+	unix.SYS_REQUEST_KEY,
+	unix.SYS_ACCT,
+	unix.SYS_SETTIMEOFDAY,
+	unix.SYS_CLOCK_SETTIME,
+	unix.SYS_CLOCK_ADJTIME,
+	unix.SYS_ADJTIMEX,
+	unix.SYS_SYSLOG,
+	unix.SYS_QUOTACTL,
+	unix.SYS_LOOKUP_DCOOKIE,
+	unix.SYS_OPEN_BY_HANDLE_AT,
+	unix.SYS_NAME_TO_HANDLE_AT,
+	unix.SYS_USERFAULTFD,
+}
+
+// seccompStrictDenied is added by the strict preset: no new namespaces.
+var seccompStrictDenied = []uint32{
+	unix.SYS_UNSHARE,
+	unix.SYS_SETNS,
+}
+
+// seccompAuditArch returns the AUDIT_ARCH value for the running binary, or
+// false when no filter is provided for this architecture.
+func seccompAuditArch() (uint32, bool) {
+	switch runtime.GOARCH {
+	case "amd64":
+		return unix.AUDIT_ARCH_X86_64, true
+	case "arm64":
+		return unix.AUDIT_ARCH_AARCH64, true
+	case "arm":
+		return unix.AUDIT_ARCH_ARM, true
+	case "riscv64":
+		return unix.AUDIT_ARCH_RISCV64, true
+	case "loong64":
+		return unix.AUDIT_ARCH_LOONGARCH64, true
+	case "mipsle":
+		return unix.AUDIT_ARCH_MIPSEL, true
+	}
+	return 0, false
+}
+
+// seccompSupported reports whether the kernel and architecture support the
+// filters built by buildSeccompFilter.
+func seccompSupported() bool {
+	if _, ok := seccompAuditArch(); !ok {
+		return false
+	}
+	status, err := os.ReadFile("/proc/self/status")
+	return err == nil && bytes.Contains(status, []byte("\nSeccomp:"))
+}
+
+// buildSeccompFilter assembles the classic BPF program for a preset.
+//
+// Layout: check the architecture, load the syscall number, jump to the
+// shared deny instruction for each blocked syscall, restrict socket() to
+// AF_UNIX for the strict preset, then allow everything else.
+func buildSeccompFilter(preset string) ([]unix.SockFilter, error) {
+	arch, ok := seccompAuditArch()
+	if !ok {
+		return nil, fmt.Errorf("seccomp filters are not available on %s", runtime.GOARCH)
+	}
+	denied := append([]uint32{}, seccompDenied...)
+	switch preset {
+	case SeccompDefault:
+	case SeccompStrict:
+		denied = append(denied, seccompStrictDenied...)
+	default:
+		return nil, fmt.Errorf("unknown seccomp preset %q", preset)
+	}
+
+	deny := bpf.RetConstant{Val: unix.SECCOMP_RET_ERRNO | uint32(unix.EPERM)}
+	prog := []bpf.Instruction{
+		bpf.LoadAbsolute{Off: 4, Size: 4}, // seccomp_data.arch
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: arch, SkipTrue: 1},
+		deny,
+		bpf.LoadAbsolute{Off: 0, Size: 4}, // seccomp_data.nr
+	}
+	// Each jump is patched once the position of the final deny is known.
+	var jumps []int
+	if runtime.GOARCH == "amd64" {
+		// Reject the x32 ABI, whose syscall numbers alias the x86_64 table.
+		jumps = append(jumps, len(prog))
+		prog = append(prog, bpf.JumpIf{Cond: bpf.JumpGreaterOrEqual, Val: 0x40000000})
+	}
+	for _, nr := range denied {
+		jumps = append(jumps, len(prog))
+		prog = append(prog, bpf.JumpIf{Cond: bpf.JumpEqual, Val: nr})
+	}
+	if preset == SeccompStrict {
+		prog = append(prog,
+			bpf.JumpIf{Cond: bpf.JumpNotEqual, Val: unix.SYS_SOCKET, SkipTrue: 3},
+			bpf.LoadAbsolute{Off: 16, Size: 4}, // low word of args[0] on little endian
+			bpf.JumpIf{Cond: bpf.JumpEqual, Val: unix.AF_UNIX, SkipTrue: 1},
+			bpf.RetConstant{Val: unix.SECCOMP_RET_ERRNO | uint32(unix.EACCES)},
+		)
+	}
+	prog = append(prog, bpf.RetConstant{Val: unix.SECCOMP_RET_ALLOW}, deny)
+	denyAt := len(prog) - 1
+	for _, at := range jumps {
+		jump := prog[at].(bpf.JumpIf)
+		skip := denyAt - at - 1
+		if skip > 255 {
+			return nil, fmt.Errorf("seccomp filter too long")
+		}
+		jump.SkipTrue = uint8(skip)
+		prog[at] = jump
+	}
+
+	raw, err := bpf.Assemble(prog)
+	if err != nil {
+		return nil, fmt.Errorf("assemble seccomp filter: %w", err)
+	}
+	filter := make([]unix.SockFilter, len(raw))
+	for i, ins := range raw {
+		filter[i] = unix.SockFilter{Code: ins.Op, Jt: ins.Jt, Jf: ins.Jf, K: ins.K}
+	}
+	return filter, nil
+}
+
+// installSeccompFilter applies filter to the calling thread. The caller must
+// hold the OS thread and exec from it so the filter carries over.
+func installSeccompFilter(filter []unix.SockFilter) error {
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("set no_new_privs: %w", err)
+	}
+	prog := unix.SockFprog{Len: uint16(len(filter)), Filter: &filter[0]}
+	if err := unix.Prctl(unix.PR_SET_SECCOMP, unix.SECCOMP_MODE_FILTER, uintptr(unsafe.Pointer(&prog)), 0, 0); err != nil {
+		return fmt.Errorf("install seccomp filter: %w", err)
+	}
+	return nil
+}
+
+// seccompFilterFile writes the filter to a memfd in the raw sock_filter
+// layout bwrap --seccomp reads.
+func seccompFilterFile(filter []unix.SockFilter) (*os.File, error) {
+	fd, err := unix.MemfdCreate("picoclaw-seccomp", 0)
+	if err != nil {
+		return nil, fmt.Errorf("create seccomp memfd: %w", err)
+	}
+	file := os.NewFile(uintptr(fd), "picoclaw-seccomp")
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.NativeEndian, filter); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("write seccomp memfd: %w", err)
+	}
+	if _, err := file.Seek(0, 0); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return file, nil
+}
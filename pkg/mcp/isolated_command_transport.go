@@ -23,6 +23,8 @@ var isolatedCommandTerminateDuration = 5 * time.Second
 type isolatedCommandTransport struct {
 	Command           *exec.Cmd
 	TerminateDuration time.Duration
+	// Profile is the isolation resource profile for the server process.
+	Profile string
 }
 
 func (t *isolatedCommandTransport) Connect(ctx context.Context) (sdkmcp.Connection, error) {
@@ -35,7 +37,9 @@ func (t *isolatedCommandTransport) Connect(ctx context.Context) (sdkmcp.Connecti
 	if err != nil {
 		return nil, err
 	}
-	if err := isolation.Start(t.Command); err != nil {
+	// MCP servers are long-running, so the profile's wall-clock limit does
+	// not apply.
+	if err := isolation.StartWith(t.Command, isolation.Launch{Profile: t.Profile, Service: true}); err != nil {
 		return nil, err
 	}
 	td := t.TerminateDuration
@@ -1,5 +1,7 @@
 package routing
 
+import "context"
+
 // Classifier evaluates a feature set and returns a complexity score in [0, 1].
 // A higher score indicates a more complex task that benefits from a heavy model.
 // The score is compared against the configured threshold: score >= threshold selects
@@ -11,6 +13,22 @@ type Classifier interface {
 	Score(f Features) float64
 }
 
+// ContextClassifier is a Classifier that also reads the message text and may
+// do I/O, such as asking a model or comparing against past turns. Score is
+// still used when only features are available.
+type ContextClassifier interface {
+	Classifier
+	ScoreMessage(ctx context.Context, msg string, f Features) float64
+}
+
+// ScoreMessage scores msg with c, using ScoreMessage when c supports it.
+func ScoreMessage(ctx context.Context, c Classifier, msg string, f Features) float64 {
+	if cc, ok := c.(ContextClassifier); ok {
+		return cc.ScoreMessage(ctx, msg, f)
+	}
+	return c.Score(f)
+}
+
 // RuleClassifier is the v1 implementation.
 // It uses a weighted sum of structural signals with no external dependencies,
 // no API calls, and sub-microsecond latency. The raw sum is capped at 1.0 so
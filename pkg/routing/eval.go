@@ -0,0 +1,138 @@
+package routing
+
+import (
+	"context"
+	"sort"
+
+	"github.com/sipeed/picoclaw/pkg/evolution"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// EvalTurn is one replayed user message with the features the router saw and,
+// when a matching example exists, whether it needed the primary model.
+type EvalTurn struct {
+	SessionKey string
+	Message    string
+	Features   Features
+	// RecordID is the matched example's ID, left out when scoring with a
+	// KNNClassifier so a turn is never its own neighbor.
+	RecordID   string
+	Labeled    bool
+	NeedsHeavy bool
+}
+
+// ReplaySessions walks session histories and returns one EvalTurn per user
+// message, with features computed from the history before it. Turns are
+// labeled from examples of the same session whose text matches the message
+// summary; each example labels at most one turn.
+func ReplaySessions(histories map[string][]providers.Message, examples []Example) []EvalTurn {
+	type exampleKey struct{ session, text string }
+	bySummary := make(map[exampleKey][]Example)
+	for _, ex := range examples {
+		key := exampleKey{ex.SessionKey, ex.Text}
+		bySummary[key] = append(bySummary[key], ex)
+	}
+
+	keys := make([]string, 0, len(histories))
+	for key := range histories {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var turns []EvalTurn
+	for _, sessionKey := range keys {
+		history := histories[sessionKey]
+		for i, msg := range history {
+			if msg.Role != "user" || msg.Content == "" {
+				continue
+			}
+			turn := EvalTurn{
+				SessionKey: sessionKey,
+				Message:    msg.Content,
+				Features:   ExtractFeatures(msg.Content, history[:i]),
+			}
+			key := exampleKey{sessionKey, evolution.TaskSummary(msg.Content)}
+			if matches := bySummary[key]; len(matches) > 0 {
+				turn.RecordID = matches[0].ID
+				turn.Labeled = true
+				turn.NeedsHeavy = matches[0].NeedsHeavy
+				bySummary[key] = matches[1:]
+			}
+			turns = append(turns, turn)
+		}
+	}
+	return turns
+}
+
+// EvalResult summarizes one classifier at one threshold.
+type EvalResult struct {
+	Classifier string
+	Threshold  float64
+	Turns      int
+	Labeled    int
+	// LightShare is the fraction of all turns that would go to the light model.
+	LightShare float64
+	// Accuracy, MissedHeavy and NeedlessHeavy are fractions of labeled turns:
+	// correct routes, turns that needed the primary model but would go light,
+	// and turns the light model handled that would go to the primary model.
+	Accuracy      float64
+	MissedHeavy   float64
+	NeedlessHeavy float64
+}
+
+// Evaluate scores every turn once and reports the routing outcome at each
+// threshold. Thresholds that are zero or negative use the router default.
+func Evaluate(
+	ctx context.Context,
+	turns []EvalTurn,
+	name string,
+	c Classifier,
+	thresholds []float64,
+) []EvalResult {
+	knn, _ := c.(*KNNClassifier)
+	scores := make([]float64, len(turns))
+	for i, turn := range turns {
+		if knn != nil {
+			scores[i] = knn.scoreExcluding(turn.Message, turn.Features, turn.RecordID)
+		} else {
+			scores[i] = ScoreMessage(ctx, c, turn.Message, turn.Features)
+		}
+	}
+
+	results := make([]EvalResult, 0, len(thresholds))
+	for _, threshold := range thresholds {
+		if threshold <= 0 {
+			threshold = defaultThreshold
+		}
+		result := EvalResult{Classifier: name, Threshold: threshold, Turns: len(turns)}
+		var light, correct, missed, needless int
+		for i, turn := range turns {
+			heavy := scores[i] >= threshold
+			if !heavy {
+				light++
+			}
+			if !turn.Labeled {
+				continue
+			}
+			result.Labeled++
+			switch {
+			case heavy == turn.NeedsHeavy:
+				correct++
+			case turn.NeedsHeavy:
+				missed++
+			default:
+				needless++
+			}
+		}
+		if result.Turns > 0 {
+			result.LightShare = float64(light) / float64(result.Turns)
+		}
+		if result.Labeled > 0 {
+			result.Accuracy = float64(correct) / float64(result.Labeled)
+			result.MissedHeavy = float64(missed) / float64(result.Labeled)
+			result.NeedlessHeavy = float64(needless) / float64(result.Labeled)
+		}
+		results = append(results, result)
+	}
+	return results
+}
@@ -0,0 +1,96 @@
+package routing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/evolution"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func taskRecord(id, session, summary, tier string, success bool) evolution.LearningRecord {
+	record := evolution.LearningRecord{
+		ID:          id,
+		Kind:        evolution.RecordKindTask,
+		SessionKey:  session,
+		Summary:     summary,
+		FinalOutput: "done",
+		Success:     &success,
+	}
+	if tier != "" {
+		record.Source = map[string]any{evolution.SourceRoutingTier: tier}
+	}
+	return record
+}
+
+func TestExamplesFromRecords_LabelsByTierAndOutcome(t *testing.T) {
+	records := []evolution.LearningRecord{
+		taskRecord("light-ok", "s", "a", "light", true),
+		taskRecord("light-fail", "s", "b", "light", false),
+		taskRecord("primary-fail", "s", "c", "primary", false),
+		taskRecord("primary-ok", "s", "d", "primary", true),
+		taskRecord("legacy-fail", "s", "e", "", false),
+		taskRecord("heartbeat", "heartbeat", "f", "light", true),
+	}
+	noOutput := taskRecord("light-empty", "s", "g", "light", true)
+	noOutput.FinalOutput = ""
+	records = append(records, noOutput)
+
+	examples, err := ExamplesFromRecords(context.Background(), records, &evolution.HeuristicSuccessJudge{})
+	if err != nil {
+		t.Fatalf("ExamplesFromRecords() error = %v", err)
+	}
+	want := map[string]bool{
+		"light-ok":     false,
+		"light-fail":   true,
+		"primary-fail": true,
+		"legacy-fail":  true,
+		"light-empty":  true,
+	}
+	if len(examples) != len(want) {
+		t.Fatalf("examples = %+v, want %d", examples, len(want))
+	}
+	for _, ex := range examples {
+		if needsHeavy, ok := want[ex.ID]; !ok || needsHeavy != ex.NeedsHeavy {
+			t.Errorf("example %s NeedsHeavy = %v, want %v (present %v)", ex.ID, ex.NeedsHeavy, needsHeavy, ok)
+		}
+	}
+}
+
+func TestReplaySessionsAndEvaluate(t *testing.T) {
+	histories := map[string][]providers.Message{
+		"s1": {
+			{Role: "user", Content: "hi"},
+			{Role: "assistant", Content: "hello"},
+			{Role: "user", Content: "hi"},
+			{Role: "assistant", Content: "hello again"},
+			{Role: "user", Content: "unlabeled"},
+		},
+	}
+	examples := []Example{
+		{ID: "r1", SessionKey: "s1", Text: "hi", NeedsHeavy: false},
+		{ID: "r2", SessionKey: "s1", Text: "hi", NeedsHeavy: true},
+		{ID: "r3", SessionKey: "other", Text: "unlabeled", NeedsHeavy: true},
+	}
+
+	turns := ReplaySessions(histories, examples)
+	if len(turns) != 3 {
+		t.Fatalf("turns = %d, want 3", len(turns))
+	}
+	if turns[0].RecordID != "r1" || turns[1].RecordID != "r2" || turns[2].Labeled {
+		t.Fatalf("turn labels = %+v", turns)
+	}
+	if turns[1].Features.ConversationDepth != 2 {
+		t.Errorf("second turn depth = %d, want 2", turns[1].Features.ConversationDepth)
+	}
+
+	results := Evaluate(context.Background(), turns, "rule", &RuleClassifier{}, []float64{0, 0.01})
+	if len(results) != 2 || results[0].Threshold != defaultThreshold {
+		t.Fatalf("results = %+v", results)
+	}
+	// The rule classifier scores these short turns 0, so every turn goes
+	// light and the "hi" that needed the primary model is missed.
+	if r := results[0]; r.LightShare != 1 || r.Labeled != 2 || r.Accuracy != 0.5 || r.MissedHeavy != 0.5 {
+		t.Errorf("default threshold result = %+v", r)
+	}
+}
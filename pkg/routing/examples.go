@@ -0,0 +1,76 @@
+package routing
+
+import (
+	"context"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/evolution"
+)
+
+// ExamplesFromRecords labels evolution task records for the kNN classifier.
+// Each record's outcome is re-checked with judge (nil trusts the recorded
+// status), then labeled by the tier that handled it, where "light" is any
+// tier below the primary model:
+//
+//	light   + success → light was enough
+//	light   + failure → needed the primary model
+//	primary + failure → needed the primary model (a hard task either way)
+//	primary + success → skipped; it says nothing about the light model
+//
+// Records written before routing was enabled count as primary. Heartbeat
+// turns are skipped.
+func ExamplesFromRecords(
+	ctx context.Context,
+	records []evolution.LearningRecord,
+	judge evolution.SuccessJudge,
+) ([]Example, error) {
+	examples := make([]Example, 0, len(records))
+	for _, record := range records {
+		if record.Kind != evolution.RecordKindTask || strings.TrimSpace(record.Summary) == "" ||
+			strings.EqualFold(strings.TrimSpace(record.SessionKey), "heartbeat") {
+			continue
+		}
+		success := record.Success != nil && *record.Success
+		if success && judge != nil {
+			decision, err := judge.JudgeTaskRecord(ctx, record)
+			if err != nil {
+				return nil, err
+			}
+			success = decision.Success
+		}
+		light := RecordTier(record) != PrimaryTier
+		if !light && success {
+			continue
+		}
+		examples = append(examples, Example{
+			ID:         record.ID,
+			SessionKey: record.SessionKey,
+			Text:       record.Summary,
+			NeedsHeavy: !success,
+		})
+	}
+	return examples, nil
+}
+
+// RecordTier returns the routing tier stored on a task record, or
+// PrimaryTier when none was recorded.
+func RecordTier(record evolution.LearningRecord) string {
+	if tier, _ := record.Source[evolution.SourceRoutingTier].(string); tier != "" {
+		return tier
+	}
+	return PrimaryTier
+}
+
+// LoadExamples reads the workspace's evolution task records and labels them
+// with ExamplesFromRecords.
+func LoadExamples(
+	ctx context.Context,
+	workspace, stateDir string,
+	judge evolution.SuccessJudge,
+) ([]Example, error) {
+	records, err := evolution.NewStore(evolution.NewPaths(workspace, stateDir)).LoadTaskRecords()
+	if err != nil {
+		return nil, err
+	}
+	return ExamplesFromRecords(ctx, records, judge)
+}
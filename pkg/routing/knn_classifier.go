@@ -0,0 +1,182 @@
+package routing
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// defaultKNNNeighbors is used when k is zero or negative.
+	defaultKNNNeighbors = 7
+	// knnMinSimilarity ignores neighbors that share little more than common
+	// letter combinations with the message.
+	knnMinSimilarity = 0.25
+	// embeddingDims is the size of the hashed n-gram vectors.
+	embeddingDims = 512
+)
+
+// Example is a past turn labeled with whether it needed the primary model.
+type Example struct {
+	// ID identifies the source record, so evaluation can leave it out.
+	ID         string
+	SessionKey string
+	// Text is the user message, shortened as in evolution task records.
+	Text       string
+	NeedsHeavy bool
+}
+
+// KNNClassifier scores a message by how often its most similar past turns
+// needed the primary model. Similarity is the cosine of hashed character
+// n-gram vectors, which needs no embedding API and works for any script.
+//
+// With fewer than k neighbors above knnMinSimilarity the score is blended
+// with the fallback classifier in proportion, so an empty or unrelated
+// history degrades to the fallback instead of guessing.
+type KNNClassifier struct {
+	examples []knnExample
+	k        int
+	fallback Classifier
+}
+
+type knnExample struct {
+	id     string
+	vec    []float32
+	target float64
+}
+
+// NewKNNClassifier indexes examples. A nil fallback uses RuleClassifier.
+func NewKNNClassifier(examples []Example, k int, fallback Classifier) *KNNClassifier {
+	if k <= 0 {
+		k = defaultKNNNeighbors
+	}
+	if fallback == nil {
+		fallback = &RuleClassifier{}
+	}
+	c := &KNNClassifier{k: k, fallback: fallback}
+	for _, ex := range examples {
+		vec := embedText(ex.Text)
+		if vec == nil {
+			continue
+		}
+		target := 0.0
+		if ex.NeedsHeavy {
+			target = 1.0
+		}
+		c.examples = append(c.examples, knnExample{id: ex.ID, vec: vec, target: target})
+	}
+	return c
+}
+
+// Len returns the number of indexed examples.
+func (c *KNNClassifier) Len() int {
+	return len(c.examples)
+}
+
+// Score has no message text to compare and returns the fallback score.
+func (c *KNNClassifier) Score(f Features) float64 {
+	return c.fallback.Score(f)
+}
+
+// ScoreMessage blends the neighbor vote with the fallback score.
+func (c *KNNClassifier) ScoreMessage(_ context.Context, msg string, f Features) float64 {
+	return c.scoreExcluding(msg, f, "")
+}
+
+// scoreExcluding ignores the example with the given ID, for leave-one-out
+// evaluation.
+func (c *KNNClassifier) scoreExcluding(msg string, f Features, excludeID string) float64 {
+	if f.HasAttachments {
+		return 1.0
+	}
+	base := c.fallback.Score(f)
+	query := embedText(msg)
+	if query == nil || len(c.examples) == 0 {
+		return base
+	}
+
+	type neighbor struct {
+		sim    float64
+		target float64
+	}
+	neighbors := make([]neighbor, 0, len(c.examples))
+	for _, ex := range c.examples {
+		if excludeID != "" && ex.id == excludeID {
+			continue
+		}
+		if sim := cosine(query, ex.vec); sim >= knnMinSimilarity {
+			neighbors = append(neighbors, neighbor{sim: sim, target: ex.target})
+		}
+	}
+	if len(neighbors) == 0 {
+		return base
+	}
+	sort.Slice(neighbors, func(i, j int) bool { return neighbors[i].sim > neighbors[j].sim })
+	if len(neighbors) > c.k {
+		neighbors = neighbors[:c.k]
+	}
+
+	var weighted, total float64
+	for _, n := range neighbors {
+		weighted += n.sim * n.target
+		total += n.sim
+	}
+	vote := weighted / total
+	confidence := float64(len(neighbors)) / float64(c.k)
+	return confidence*vote + (1-confidence)*base
+}
+
+// embedText maps text to an L2-normalized vector of hashed character
+// trigrams over lowercased, whitespace-collapsed text. It returns nil for
+// empty text.
+func embedText(text string) []float32 {
+	var runes []rune
+	space := true
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsSpace(r) {
+			if !space {
+				runes = append(runes, ' ')
+			}
+			space = true
+			continue
+		}
+		runes = append(runes, r)
+		space = false
+	}
+	if len(runes) == 0 {
+		return nil
+	}
+	// Pad so short words and single CJK characters still produce grams.
+	runes = append(append([]rune{' '}, runes...), ' ')
+
+	vec := make([]float32, embeddingDims)
+	h := fnv.New32a()
+	for i := 0; i+3 <= len(runes); i++ {
+		h.Reset()
+		h.Write([]byte(string(runes[i : i+3])))
+		vec[h.Sum32()%embeddingDims]++
+	}
+	var norm float64
+	for _, v := range vec {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		return nil
+	}
+	scale := float32(1 / math.Sqrt(norm))
+	for i := range vec {
+		vec[i] *= scale
+	}
+	return vec
+}
+
+func cosine(a, b []float32) float64 {
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot
+}
@@ -0,0 +1,51 @@
+package routing
+
+import (
+	"context"
+	"testing"
+)
+
+func TestKNNClassifier_VotesBySimilarPastTurns(t *testing.T) {
+	examples := []Example{
+		{ID: "1", Text: "what is the weather today", NeedsHeavy: false},
+		{ID: "2", Text: "what is the weather tomorrow", NeedsHeavy: false},
+		{ID: "3", Text: "prove the convergence of this series", NeedsHeavy: true},
+		{ID: "4", Text: "prove the convergence of the integral", NeedsHeavy: true},
+	}
+	c := NewKNNClassifier(examples, 2, nil)
+	ctx := context.Background()
+
+	if got := c.ScoreMessage(ctx, "prove the convergence of a series", Features{}); got < 0.9 {
+		t.Errorf("hard score = %v, want >= 0.9", got)
+	}
+	if got := c.ScoreMessage(ctx, "what is the weather", Features{}); got > 0.1 {
+		t.Errorf("easy score = %v, want <= 0.1", got)
+	}
+	// Nothing similar: the rule score decides.
+	if got := c.ScoreMessage(ctx, "zzz qqq", Features{}); got != 0 {
+		t.Errorf("unrelated score = %v, want 0", got)
+	}
+}
+
+func TestKNNClassifier_LeaveOneOutAndConfidence(t *testing.T) {
+	c := NewKNNClassifier([]Example{{ID: "only", Text: "refactor the parser", NeedsHeavy: true}}, 4, nil)
+
+	// One neighbor out of four: a quarter of the vote, the rest rule score.
+	if got := c.ScoreMessage(context.Background(), "refactor the parser", Features{}); got < 0.24 || got > 0.26 {
+		t.Errorf("score = %v, want ~0.25", got)
+	}
+	if got := c.scoreExcluding("refactor the parser", Features{}, "only"); got != 0 {
+		t.Errorf("leave-one-out score = %v, want 0", got)
+	}
+}
+
+func TestEmbedText_NormalizesCaseAndSpace(t *testing.T) {
+	a := embedText("Hello   World")
+	b := embedText("hello world")
+	if sim := cosine(a, b); sim < 0.999 {
+		t.Errorf("cosine = %v, want 1", sim)
+	}
+	if embedText(" \n ") != nil {
+		t.Error("embedText(blank) != nil")
+	}
+}
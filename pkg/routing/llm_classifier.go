@@ -0,0 +1,182 @@
+package routing
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+const (
+	// llmClassifierTimeout bounds one verdict call. Routing sits in front of
+	// every turn, so a slow judge falls back to the rule score instead.
+	llmClassifierTimeout = 5 * time.Second
+	// llmClassifierCacheSize is how many verdicts are kept; the oldest is
+	// evicted first.
+	llmClassifierCacheSize = 512
+	// llmClassifierMaxRunes caps how much of the message the judge sees.
+	llmClassifierMaxRunes = 2000
+)
+
+var llmScorePattern = regexp.MustCompile(`[01](?:\.\d+)?`)
+
+// LLMClassifier asks a model, usually the light model itself, how hard a
+// message is. The judge sees only the message text, and verdicts are cached
+// by it, so repeated questions cost one call. Errors and timeouts fall back to the fallback classifier and are
+// not cached.
+type LLMClassifier struct {
+	provider providers.LLMProvider
+	model    string
+	fallback Classifier
+	timeout  time.Duration
+
+	mu    sync.Mutex
+	cache map[string]float64
+	order []string
+}
+
+// NewLLMClassifier creates an LLM-judge classifier. A nil fallback uses
+// RuleClassifier.
+func NewLLMClassifier(provider providers.LLMProvider, model string, fallback Classifier) *LLMClassifier {
+	if fallback == nil {
+		fallback = &RuleClassifier{}
+	}
+	return &LLMClassifier{
+		provider: provider,
+		model:    strings.TrimSpace(model),
+		fallback: fallback,
+		timeout:  llmClassifierTimeout,
+		cache:    make(map[string]float64),
+	}
+}
+
+// Score has no message text to judge and returns the fallback score.
+func (c *LLMClassifier) Score(f Features) float64 {
+	return c.fallback.Score(f)
+}
+
+// ScoreMessage returns the cached or freshly judged complexity of msg.
+// Attachments still short-circuit to 1.0 without a call.
+func (c *LLMClassifier) ScoreMessage(ctx context.Context, msg string, f Features) float64 {
+	if f.HasAttachments {
+		return 1.0
+	}
+	if strings.TrimSpace(msg) == "" || c.provider == nil {
+		return c.fallback.Score(f)
+	}
+
+	key := llmCacheKey(msg)
+	c.mu.Lock()
+	score, ok := c.cache[key]
+	c.mu.Unlock()
+	if ok {
+		return score
+	}
+
+	score, err := c.judge(ctx, msg)
+	if err != nil {
+		return c.fallback.Score(f)
+	}
+	c.remember(key, score)
+	return score
+}
+
+func (c *LLMClassifier) judge(ctx context.Context, msg string) (float64, error) {
+	model := c.model
+	if model == "" {
+		model = c.provider.GetDefaultModel()
+	}
+	callCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	resp, err := c.provider.Chat(callCtx, []providers.Message{
+		{
+			Role: "system",
+			Content: "You route chat messages between a small fast model and a large capable model. " +
+				"Rate how much the message needs the large model, from 0 (greetings, lookups, " +
+				"simple rewrites) to 1 (multi-step reasoning, non-trivial code, careful analysis). " +
+				"Length alone is not difficulty: a long paste to summarize is easy, a one-line " +
+				"proof or tricky bug is hard. Return exactly one JSON object {\"score\": number}.",
+		},
+		{Role: "user", Content: buildLLMClassifierPrompt(msg)},
+	}, nil, model, map[string]any{"temperature": 0, "max_tokens": 20})
+	if err != nil {
+		return 0, err
+	}
+	if resp == nil {
+		return 0, fmt.Errorf("empty response")
+	}
+	return parseLLMScore(resp.Content)
+}
+
+func (c *LLMClassifier) remember(key string, score float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.cache[key]; ok {
+		return
+	}
+	if len(c.order) >= llmClassifierCacheSize {
+		delete(c.cache, c.order[0])
+		c.order = c.order[1:]
+	}
+	c.cache[key] = score
+	c.order = append(c.order, key)
+}
+
+// buildLLMClassifierPrompt leaves out history features such as tool calls
+// and conversation depth, which would make a cached verdict stale.
+func buildLLMClassifierPrompt(msg string) string {
+	msg = strings.TrimSpace(msg)
+	if utf8.RuneCountInString(msg) > llmClassifierMaxRunes {
+		msg = string([]rune(msg)[:llmClassifierMaxRunes]) + "\n[truncated]"
+	}
+	return "Message:\n" + msg
+}
+
+// parseLLMScore reads {"score": x} and tolerates fences or a bare number.
+func parseLLMScore(content string) (float64, error) {
+	content = strings.TrimSpace(content)
+	content = strings.TrimPrefix(content, "```json")
+	content = strings.TrimPrefix(content, "```")
+	content = strings.TrimSuffix(content, "```")
+	content = strings.TrimSpace(content)
+
+	var payload struct {
+		Score *float64 `json:"score"`
+	}
+	if err := json.Unmarshal([]byte(content), &payload); err == nil && payload.Score != nil {
+		return clampScore(*payload.Score), nil
+	}
+	if m := llmScorePattern.FindString(content); m != "" {
+		if v, err := strconv.ParseFloat(m, 64); err == nil {
+			return clampScore(v), nil
+		}
+	}
+	return 0, fmt.Errorf("no score in judge reply %q", content)
+}
+
+// llmCacheKey normalizes case and whitespace so trivially different copies
+// of a message share a verdict.
+func llmCacheKey(msg string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(msg)), " ")
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:16])
+}
+
+func clampScore(v float64) float64 {
+	switch {
+	case v < 0:
+		return 0
+	case v > 1:
+		return 1
+	}
+	return v
+}
@@ -0,0 +1,106 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+type judgeProvider struct {
+	mu      sync.Mutex
+	calls   int
+	prompt  string
+	content string
+	err     error
+}
+
+func (p *judgeProvider) Chat(
+	_ context.Context,
+	messages []providers.Message,
+	_ []providers.ToolDefinition,
+	_ string,
+	_ map[string]any,
+) (*providers.LLMResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	p.prompt = messages[len(messages)-1].Content
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &providers.LLMResponse{Content: p.content}, nil
+}
+
+func (p *judgeProvider) GetDefaultModel() string { return "light" }
+
+func TestLLMClassifier_CachesVerdictByNormalizedText(t *testing.T) {
+	p := &judgeProvider{content: "```json\n{\"score\": 0.9}\n```"}
+	c := NewLLMClassifier(p, "", nil)
+	ctx := context.Background()
+
+	if got := c.ScoreMessage(ctx, "Prove that sqrt(2) is irrational", Features{}); got != 0.9 {
+		t.Fatalf("score = %v, want 0.9", got)
+	}
+	deep := Features{RecentToolCalls: 5, ConversationDepth: 40}
+	if got := c.ScoreMessage(ctx, "  prove that SQRT(2)   is irrational ", deep); got != 0.9 {
+		t.Fatalf("cached score = %v, want 0.9", got)
+	}
+	if p.calls != 1 {
+		t.Fatalf("provider calls = %d, want 1", p.calls)
+	}
+	// The cache key is the text alone, so the judge must not see anything else.
+	if p.prompt != "Message:\nProve that sqrt(2) is irrational" {
+		t.Fatalf("judge prompt = %q, want the message only", p.prompt)
+	}
+}
+
+func TestLLMClassifier_FallsBackWithoutCaching(t *testing.T) {
+	p := &judgeProvider{err: errors.New("unavailable")}
+	c := NewLLMClassifier(p, "light", nil)
+	ctx := context.Background()
+
+	if got := c.ScoreMessage(ctx, "hello", Features{}); got != 0 {
+		t.Fatalf("fallback score = %v, want rule score 0", got)
+	}
+	p.err = nil
+	p.content = "0.6"
+	if got := c.ScoreMessage(ctx, "hello", Features{}); got != 0.6 {
+		t.Fatalf("score after recovery = %v, want 0.6", got)
+	}
+	if got := c.ScoreMessage(ctx, "look", Features{HasAttachments: true}); got != 1.0 {
+		t.Fatalf("attachment score = %v, want 1.0", got)
+	}
+	if p.calls != 2 {
+		t.Fatalf("provider calls = %d, want 2", p.calls)
+	}
+}
+
+func TestParseLLMScore(t *testing.T) {
+	cases := map[string]float64{
+		`{"score": 0.25}`: 0.25,
+		`{"score": 7}`:    1,
+		"Score: 0.4":      0.4,
+	}
+	for in, want := range cases {
+		got, err := parseLLMScore(in)
+		if err != nil || got != want {
+			t.Errorf("parseLLMScore(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := parseLLMScore("hard"); err == nil {
+		t.Error("parseLLMScore(\"hard\") succeeded, want error")
+	}
+}
+
+func TestRouter_UsesContextClassifier(t *testing.T) {
+	p := &judgeProvider{content: `{"score": 0.8}`}
+	r := NewWithClassifier(RouterConfig{LightModel: "light"}, NewLLMClassifier(p, "", nil))
+
+	model, usedLight, score := r.SelectModelContext(context.Background(), "why?", nil, "heavy")
+	if model != "heavy" || usedLight || score != 0.8 {
+		t.Fatalf("SelectModelContext = %q, %v, %v; want heavy, false, 0.8", model, usedLight, score)
+	}
+}
@@ -6,12 +6,17 @@ import (
 
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/identity"
 )
 
 // SessionPolicy describes how a routed message should be mapped to a session.
 type SessionPolicy struct {
 	Dimensions    []string
 	IdentityLinks map[string][]string
+	// SharedIdentityLinks holds the subset of IdentityLinks created at runtime
+	// (via /link). Direct chats from these identities share one session no
+	// matter which channel they arrive on.
+	SharedIdentityLinks map[string][]string
 }
 
 // ResolvedRoute is the result of agent routing.
@@ -23,9 +28,16 @@ type ResolvedRoute struct {
 	MatchedBy     string
 }
 
+// IdentityLinkSource supplies identity links learned at runtime, in the same
+// canonical -> ids shape as session.identity_links.
+type IdentityLinkSource interface {
+	Links() map[string][]string
+}
+
 // RouteResolver determines which agent handles a message.
 type RouteResolver struct {
-	cfg *config.Config
+	cfg        *config.Config
+	linkSource IdentityLinkSource
 }
 
 // NewRouteResolver creates a new route resolver.
@@ -33,6 +45,12 @@ func NewRouteResolver(cfg *config.Config) *RouteResolver {
 	return &RouteResolver{cfg: cfg}
 }
 
+// SetIdentityLinkSource adds runtime identity links on top of the configured
+// session.identity_links. Configured links win when both list an identity.
+func (r *RouteResolver) SetIdentityLinkSource(source IdentityLinkSource) {
+	r.linkSource = source
+}
+
 // ResolveRoute determines which agent handles the message from a normalized
 // inbound context and returns the session policy that should be used to
 // allocate session state.
@@ -48,7 +66,7 @@ func (r *RouteResolver) ResolveRoute(inbound bus.InboundContext) ResolvedRoute {
 			MatchedBy:     "raw.agent_id",
 		}
 	}
-	identityLinks := cloneIdentityLinks(r.cfg.Session.IdentityLinks)
+	identityLinks, _ := r.identityLinks()
 	view := buildDispatchView(inbound, identityLinks)
 
 	if rule := r.matchDispatchRule(view); rule != nil {
@@ -92,7 +110,13 @@ func (r *RouteResolver) pickAgentID(agentID string) string {
 }
 
 func (r *RouteResolver) resolveDefaultAgentID() string {
-	agents := r.cfg.Agents.List
+	return defaultAgentID(r.cfg)
+}
+
+// defaultAgentID picks the agents.list entry marked default, else the first
+// entry, else the implicit main agent.
+func defaultAgentID(cfg *config.Config) string {
+	agents := cfg.Agents.List
 	if len(agents) == 0 {
 		return DefaultAgentID
 	}
@@ -115,10 +139,23 @@ func (r *RouteResolver) sessionPolicy(rule *config.DispatchRule) SessionPolicy {
 	if rule != nil && len(rule.SessionDimensions) > 0 {
 		dimensions = rule.SessionDimensions
 	}
+	identityLinks, sharedLinks := r.identityLinks()
 	return SessionPolicy{
-		Dimensions:    normalizeSessionDimensions(dimensions),
-		IdentityLinks: cloneIdentityLinks(r.cfg.Session.IdentityLinks),
+		Dimensions:          normalizeSessionDimensions(dimensions),
+		IdentityLinks:       identityLinks,
+		SharedIdentityLinks: sharedLinks,
+	}
+}
+
+// identityLinks returns the configured links merged with runtime links, plus
+// the runtime links that survived the merge.
+func (r *RouteResolver) identityLinks() (all, shared map[string][]string) {
+	configured := r.cfg.Session.IdentityLinks
+	if r.linkSource == nil {
+		return cloneIdentityLinks(configured), nil
 	}
+	shared = identity.UnclaimedLinks(r.linkSource.Links(), configured)
+	return identity.MergeLinks(configured, shared), shared
 }
 
 func normalizeSessionDimensions(dimensions []string) []string {
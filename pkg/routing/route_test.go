@@ -253,3 +253,35 @@ func TestResolveRoute_NoDefaultUsesFirst(t *testing.T) {
 		t.Errorf("AgentID = %q, want 'alpha' (first in list)", route.AgentID)
 	}
 }
+
+type staticLinkSource map[string][]string
+
+func (s staticLinkSource) Links() map[string][]string { return s }
+
+func TestResolveRoute_RuntimeIdentityLinks(t *testing.T) {
+	cfg := testConfig([]config.AgentConfig{{ID: "main", Default: true}, {ID: "family"}})
+	cfg.Session.IdentityLinks = map[string][]string{"alice": {"telegram:1"}}
+	cfg.Agents.Dispatch = &config.DispatchConfig{
+		Rules: []config.DispatchRule{{
+			Name:  "family",
+			Agent: "family",
+			When:  config.DispatchSelector{Sender: "alice"},
+		}},
+	}
+	r := NewRouteResolver(cfg)
+	r.SetIdentityLinkSource(staticLinkSource{
+		"alice":       {"discord:2"},
+		"telegram:99": {"telegram:1", "slack:3"},
+	})
+
+	route := r.ResolveRoute(bus.InboundContext{Channel: "discord", ChatType: "direct", SenderID: "2"})
+	if route.AgentID != "family" {
+		t.Fatalf("AgentID = %q, want family via runtime link", route.AgentID)
+	}
+	if got := route.SessionPolicy.IdentityLinks["alice"]; len(got) != 2 {
+		t.Fatalf("IdentityLinks[alice] = %v, want configured and runtime ids", got)
+	}
+	if got := route.SessionPolicy.IdentityLinks["telegram:99"]; len(got) != 1 || got[0] != "slack:3" {
+		t.Fatalf("IdentityLinks[telegram:99] = %v, configured telegram:1 must not be relinked", got)
+	}
+}
@@ -1,6 +1,8 @@
 package routing
 
 import (
+	"context"
+
 	"github.com/sipeed/picoclaw/pkg/providers"
 )
 
@@ -20,8 +22,22 @@ type RouterConfig struct {
 	// score >= Threshold → primary (heavy) model.
 	// score <  Threshold → light model.
 	Threshold float64
+
+	// Tiers lists the models below the primary one, cheapest first and in
+	// ascending MaxScore order. When empty, LightModel and Threshold form a
+	// single "light" tier.
+	Tiers []Tier
+
+	// Escalate moves a turn to the next tier when the current one fails.
+	Escalate bool
+	// EscalateToolErrors is how many failed tool calls on one tier trigger
+	// escalation. Zero or negative uses defaultEscalateToolErrors.
+	EscalateToolErrors int
 }
 
+// defaultEscalateToolErrors is used when EscalateToolErrors is unset.
+const defaultEscalateToolErrors = 2
+
 // Router selects the appropriate model tier for each incoming message.
 // It is safe for concurrent use from multiple goroutines.
 type Router struct {
@@ -32,51 +48,146 @@ type Router struct {
 // New creates a Router with the given config and the default RuleClassifier.
 // If cfg.Threshold is zero or negative, defaultThreshold (0.35) is used.
 func New(cfg RouterConfig) *Router {
-	if cfg.Threshold <= 0 {
-		cfg.Threshold = defaultThreshold
-	}
-	return &Router{
-		cfg:        cfg,
-		classifier: &RuleClassifier{},
-	}
+	return NewWithClassifier(cfg, &RuleClassifier{})
 }
 
-// newWithClassifier creates a Router with a custom Classifier.
-// Intended for unit tests that need to inject a deterministic scorer.
-func newWithClassifier(cfg RouterConfig, c Classifier) *Router {
+// NewWithClassifier creates a Router with a custom Classifier, such as an
+// LLMClassifier or KNNClassifier.
+func NewWithClassifier(cfg RouterConfig, c Classifier) *Router {
 	if cfg.Threshold <= 0 {
 		cfg.Threshold = defaultThreshold
 	}
+	if cfg.EscalateToolErrors <= 0 {
+		cfg.EscalateToolErrors = defaultEscalateToolErrors
+	}
+	if len(cfg.Tiers) == 0 && cfg.LightModel != "" {
+		cfg.Tiers = []Tier{{
+			Name:         "light",
+			Model:        cfg.LightModel,
+			MaxScore:     cfg.Threshold,
+			Capabilities: Capabilities{Tools: true},
+		}}
+	}
+	if len(cfg.Tiers) > 0 {
+		cfg.LightModel = cfg.Tiers[0].Model
+	}
 	return &Router{cfg: cfg, classifier: c}
 }
 
 // SelectModel returns the model to use for this conversation turn along with
 // the computed complexity score (for logging and debugging).
 //
-//   - If score < cfg.Threshold: returns (cfg.LightModel, true, score)
-//   - Otherwise:               returns (primaryModel, false, score)
+//   - If score falls in a tier's band: returns (that tier's model, true, score)
+//   - Otherwise:                       returns (primaryModel, false, score)
 //
 // The caller is responsible for resolving the returned model name into
-// provider candidates (see AgentInstance.LightCandidates).
+// provider candidates (see AgentInstance.RoutingTiers).
 func (r *Router) SelectModel(
 	msg string,
 	history []providers.Message,
 	primaryModel string,
 ) (model string, usedLight bool, score float64) {
-	features := ExtractFeatures(msg, history)
-	score = r.classifier.Score(features)
-	if score < r.cfg.Threshold {
-		return r.cfg.LightModel, true, score
+	return r.SelectModelContext(context.Background(), msg, history, primaryModel)
+}
+
+// SelectModelContext is SelectModel with a context for classifiers that call
+// out to a model (see ContextClassifier).
+func (r *Router) SelectModelContext(
+	ctx context.Context,
+	msg string,
+	history []providers.Message,
+	primaryModel string,
+) (model string, usedLight bool, score float64) {
+	sel := r.SelectTier(ctx, msg, history, Requirements{})
+	if sel.Tier < len(r.cfg.Tiers) {
+		return r.cfg.Tiers[sel.Tier].Model, true, sel.Score
 	}
-	return primaryModel, false, score
+	return primaryModel, false, sel.Score
 }
 
-// LightModel returns the configured light model name.
+// SelectTier scores the message and picks the tier whose score band contains
+// it. If that tier cannot serve req, the next capable tier up is used. If the
+// capable tier is over its cost ceiling, the nearest cheaper tier that is
+// capable and within budget wins instead; when there is none the ceiling is
+// ignored. The primary model is always capable.
+func (r *Router) SelectTier(
+	ctx context.Context,
+	msg string,
+	history []providers.Message,
+	req Requirements,
+) TierSelection {
+	score := ScoreMessage(ctx, r.classifier, msg, ExtractFeatures(msg, history))
+	band := len(r.cfg.Tiers)
+	for i, tier := range r.cfg.Tiers {
+		if score < tier.MaxScore {
+			band = i
+			break
+		}
+	}
+
+	selected := band
+	for selected < len(r.cfg.Tiers) && !r.cfg.Tiers[selected].Supports(req) {
+		selected++
+	}
+	if selected < len(r.cfg.Tiers) && !r.cfg.Tiers[selected].WithinBudget(req) {
+		for i := selected - 1; i >= 0; i-- {
+			if r.cfg.Tiers[i].Supports(req) && r.cfg.Tiers[i].WithinBudget(req) {
+				selected = i
+				break
+			}
+		}
+	}
+	return TierSelection{Tier: selected, Band: band, Score: score}
+}
+
+// NextTier returns the first tier above current that can serve req within
+// its cost ceiling, or len(Tiers()) for the primary model. It returns -1 when
+// current is already the primary model.
+func (r *Router) NextTier(current int, req Requirements) int {
+	if current >= len(r.cfg.Tiers) {
+		return -1
+	}
+	for i := current + 1; i < len(r.cfg.Tiers); i++ {
+		if r.cfg.Tiers[i].Supports(req) && r.cfg.Tiers[i].WithinBudget(req) {
+			return i
+		}
+	}
+	return len(r.cfg.Tiers)
+}
+
+// Tiers returns the configured tiers, cheapest first.
+func (r *Router) Tiers() []Tier {
+	return r.cfg.Tiers
+}
+
+// TierName returns the name of tier i, or PrimaryTier for the primary model.
+func (r *Router) TierName(i int) string {
+	if i >= 0 && i < len(r.cfg.Tiers) {
+		return r.cfg.Tiers[i].Name
+	}
+	return PrimaryTier
+}
+
+// Escalation reports whether failed turns move up a tier and how many failed
+// tool calls trigger it.
+func (r *Router) Escalation() (enabled bool, toolErrors int) {
+	return r.cfg.Escalate, r.cfg.EscalateToolErrors
+}
+
+// LightModel returns the cheapest tier's model name.
 func (r *Router) LightModel() string {
 	return r.cfg.LightModel
 }
 
-// Threshold returns the complexity threshold in use.
+// Classifier returns the classifier scoring messages.
+func (r *Router) Classifier() Classifier {
+	return r.classifier
+}
+
+// Threshold returns the score below which the cheapest tier is used.
 func (r *Router) Threshold() float64 {
+	if len(r.cfg.Tiers) > 0 {
+		return r.cfg.Tiers[0].MaxScore
+	}
 	return r.cfg.Threshold
 }
@@ -0,0 +1,78 @@
+package routing
+
+// PrimaryTier is the tier name reported when the agent's primary model
+// handles a turn.
+const PrimaryTier = "primary"
+
+// Capabilities describes what a tier's model supports, taken from its
+// model_list metadata.
+type Capabilities struct {
+	Vision bool
+	Tools  bool
+	// ContextWindow is the model's context size in tokens. Zero means unknown
+	// and never rules the tier out.
+	ContextWindow int
+}
+
+// Requirements describes what a turn needs from a model.
+type Requirements struct {
+	Vision bool
+	Tools  bool
+	// ContextTokens is the estimated prompt size, including tool definitions.
+	ContextTokens int
+	// OutputTokens is the completion budget, used for cost estimates.
+	OutputTokens int
+}
+
+// Tier is one model tier below the primary model. Tiers are ordered from the
+// cheapest; each takes the scores between the previous tier's MaxScore and
+// its own.
+type Tier struct {
+	Name string
+	// Model is the model_name from model_list.
+	Model    string
+	MaxScore float64
+
+	Capabilities Capabilities
+	// InputCost and OutputCost are USD per million tokens; zero means free or
+	// unknown.
+	InputCost  float64
+	OutputCost float64
+	// MaxCost is the ceiling in USD for one estimated call. Zero means none.
+	MaxCost float64
+}
+
+// Supports reports whether the tier's model can serve req.
+func (t Tier) Supports(req Requirements) bool {
+	if req.Vision && !t.Capabilities.Vision {
+		return false
+	}
+	if req.Tools && !t.Capabilities.Tools {
+		return false
+	}
+	if t.Capabilities.ContextWindow > 0 && req.ContextTokens+req.OutputTokens > t.Capabilities.ContextWindow {
+		return false
+	}
+	return true
+}
+
+// EstimateCost returns the USD cost of one call of the given size.
+func (t Tier) EstimateCost(req Requirements) float64 {
+	return (float64(req.ContextTokens)*t.InputCost + float64(req.OutputTokens)*t.OutputCost) / 1e6
+}
+
+// WithinBudget reports whether one call of the given size stays under the
+// tier's cost ceiling.
+func (t Tier) WithinBudget(req Requirements) bool {
+	return t.MaxCost <= 0 || t.EstimateCost(req) <= t.MaxCost
+}
+
+// TierSelection is the outcome of Router.SelectTier.
+type TierSelection struct {
+	// Tier indexes Router.Tiers(); len(Tiers()) means the primary model.
+	Tier int
+	// Band is the tier the score alone picked, before capability and cost
+	// checks.
+	Band  int
+	Score float64
+}
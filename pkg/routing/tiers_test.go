@@ -0,0 +1,116 @@
+package routing
+
+import (
+	"context"
+	"testing"
+)
+
+func threeTierRouter(score float64, tiers ...Tier) *Router {
+	if len(tiers) == 0 {
+		tiers = []Tier{
+			{Name: "nano", Model: "nano-model", MaxScore: 0.2, Capabilities: Capabilities{Tools: true}},
+			{Name: "small", Model: "small-model", MaxScore: 0.5, Capabilities: Capabilities{Tools: true, Vision: true}},
+		}
+	}
+	return NewWithClassifier(RouterConfig{Tiers: tiers, Escalate: true}, &fixedScoreClassifier{score: score})
+}
+
+func TestSelectTier_ScoreBands(t *testing.T) {
+	cases := []struct {
+		score float64
+		want  int
+	}{
+		{0.1, 0},
+		{0.3, 1},
+		{0.7, 2},
+	}
+	for _, tc := range cases {
+		sel := threeTierRouter(tc.score).SelectTier(context.Background(), "hi", nil, Requirements{})
+		if sel.Tier != tc.want || sel.Band != tc.want {
+			t.Errorf("score %.1f: got tier %d band %d, want %d", tc.score, sel.Tier, sel.Band, tc.want)
+		}
+	}
+}
+
+func TestSelectTier_SkipsIncapableTiers(t *testing.T) {
+	r := threeTierRouter(0.1)
+
+	sel := r.SelectTier(context.Background(), "look", nil, Requirements{Vision: true})
+	if sel.Tier != 1 || sel.Band != 0 {
+		t.Errorf("vision: got tier %d band %d, want tier 1 band 0", sel.Tier, sel.Band)
+	}
+
+	r = threeTierRouter(0.1,
+		Tier{Name: "nano", Model: "nano-model", MaxScore: 0.2, Capabilities: Capabilities{ContextWindow: 1000}},
+	)
+	if sel := r.SelectTier(context.Background(), "hi", nil, Requirements{Tools: true}); sel.Tier != 1 {
+		t.Errorf("tools on a no-tools tier: got tier %d, want primary (1)", sel.Tier)
+	}
+	if sel := r.SelectTier(context.Background(), "hi", nil, Requirements{ContextTokens: 900, OutputTokens: 200}); sel.Tier != 1 {
+		t.Errorf("context overflow: got tier %d, want primary (1)", sel.Tier)
+	}
+	if sel := r.SelectTier(context.Background(), "hi", nil, Requirements{ContextTokens: 500}); sel.Tier != 0 {
+		t.Errorf("context fits: got tier %d, want 0", sel.Tier)
+	}
+}
+
+func TestSelectTier_CostCeilingFallsBackToCheaperTier(t *testing.T) {
+	r := threeTierRouter(0.3,
+		Tier{Name: "nano", Model: "nano-model", MaxScore: 0.2, Capabilities: Capabilities{Tools: true}, InputCost: 0.1},
+		Tier{
+			Name: "small", Model: "small-model", MaxScore: 0.5, Capabilities: Capabilities{Tools: true},
+			InputCost: 10, MaxCost: 0.01,
+		},
+	)
+	req := Requirements{ContextTokens: 10_000}
+	if got := r.Tiers()[1].EstimateCost(req); got != 0.1 {
+		t.Fatalf("EstimateCost = %v, want 0.1", got)
+	}
+	sel := r.SelectTier(context.Background(), "hi", nil, req)
+	if sel.Tier != 0 || sel.Band != 1 {
+		t.Errorf("got tier %d band %d, want tier 0 band 1", sel.Tier, sel.Band)
+	}
+
+	sel = r.SelectTier(context.Background(), "hi", nil, Requirements{ContextTokens: 100})
+	if sel.Tier != 1 {
+		t.Errorf("within budget: got tier %d, want 1", sel.Tier)
+	}
+}
+
+func TestNextTier(t *testing.T) {
+	r := threeTierRouter(0.1)
+	if got := r.NextTier(0, Requirements{}); got != 1 {
+		t.Errorf("NextTier(0) = %d, want 1", got)
+	}
+	if got := r.NextTier(1, Requirements{}); got != 2 {
+		t.Errorf("NextTier(1) = %d, want primary (2)", got)
+	}
+	if got := r.NextTier(2, Requirements{}); got != -1 {
+		t.Errorf("NextTier(primary) = %d, want -1", got)
+	}
+
+	r = threeTierRouter(0.1,
+		Tier{Name: "nano", Model: "nano-model", MaxScore: 0.2},
+		Tier{Name: "small", Model: "small-model", MaxScore: 0.5, InputCost: 10, MaxCost: 0.01},
+	)
+	if got := r.NextTier(0, Requirements{ContextTokens: 10_000}); got != 2 {
+		t.Errorf("NextTier over budget = %d, want primary (2)", got)
+	}
+	if got := r.TierName(2); got != PrimaryTier {
+		t.Errorf("TierName(primary) = %q, want %q", got, PrimaryTier)
+	}
+}
+
+func TestNew_LegacyLightModelBecomesSingleTier(t *testing.T) {
+	r := New(RouterConfig{LightModel: "cheap", Threshold: 0.4})
+	tiers := r.Tiers()
+	if len(tiers) != 1 || tiers[0].Name != "light" || tiers[0].Model != "cheap" || tiers[0].MaxScore != 0.4 {
+		t.Fatalf("tiers = %+v, want a single light tier", tiers)
+	}
+	if r.Threshold() != 0.4 || r.LightModel() != "cheap" {
+		t.Errorf("Threshold/LightModel = %v/%q", r.Threshold(), r.LightModel())
+	}
+	if enabled, limit := r.Escalation(); enabled || limit != defaultEscalateToolErrors {
+		t.Errorf("Escalation() = %v, %d; want false, %d", enabled, limit, defaultEscalateToolErrors)
+	}
+}
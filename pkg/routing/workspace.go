@@ -0,0 +1,51 @@
+package routing
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// AgentWorkspace returns the workspace directory of an agent. An explicit
+// workspace wins; the main or default agent uses agents.defaults.workspace and
+// other agents get a "workspace-<id>" sibling of it.
+func AgentWorkspace(agentCfg *config.AgentConfig, defaults *config.AgentDefaults) string {
+	if agentCfg != nil && strings.TrimSpace(agentCfg.Workspace) != "" {
+		return expandHome(strings.TrimSpace(agentCfg.Workspace))
+	}
+	if agentCfg == nil || agentCfg.Default || agentCfg.ID == "" ||
+		NormalizeAgentID(agentCfg.ID) == DefaultAgentID {
+		return expandHome(defaults.Workspace)
+	}
+	id := NormalizeAgentID(agentCfg.ID)
+	return filepath.Join(expandHome(defaults.Workspace), "..", "workspace-"+id)
+}
+
+// DefaultAgentWorkspace returns the workspace of the agent that takes
+// unrouted messages, the same agent RouteResolver falls back to. Per-instance
+// state such as identity links lives there.
+func DefaultAgentWorkspace(cfg *config.Config) string {
+	id := defaultAgentID(cfg)
+	for i := range cfg.Agents.List {
+		if NormalizeAgentID(cfg.Agents.List[i].ID) == id {
+			return AgentWorkspace(&cfg.Agents.List[i], &cfg.Agents.Defaults)
+		}
+	}
+	return AgentWorkspace(nil, &cfg.Agents.Defaults)
+}
+
+func expandHome(path string) string {
+	if path == "" {
+		return path
+	}
+	if path[0] == '~' {
+		home, _ := os.UserHomeDir()
+		if len(path) > 1 && path[1] == '/' {
+			return home + path[1:]
+		}
+		return home
+	}
+	return path
+}
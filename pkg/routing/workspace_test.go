@@ -0,0 +1,41 @@
+package routing
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestDefaultAgentWorkspace(t *testing.T) {
+	tests := []struct {
+		name   string
+		agents []config.AgentConfig
+		want   string
+	}{
+		{name: "no agents", want: "/tmp/picoclaw-test"},
+		{
+			name:   "marked default with own workspace",
+			agents: []config.AgentConfig{{ID: "main"}, {ID: "ops", Default: true, Workspace: "/srv/ops"}},
+			want:   "/srv/ops",
+		},
+		{
+			name:   "first entry without a default",
+			agents: []config.AgentConfig{{ID: "sales"}, {ID: "main"}},
+			want:   filepath.Join("/tmp/picoclaw-test", "..", "workspace-sales"),
+		},
+		{
+			name:   "main with own workspace",
+			agents: []config.AgentConfig{{ID: "main", Workspace: "/srv/main"}},
+			want:   "/srv/main",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(tt.agents)
+			if got := DefaultAgentWorkspace(cfg); got != tt.want {
+				t.Errorf("DefaultAgentWorkspace() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package script
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// csvModule exposes csv.decode and csv.encode. Fields stay strings; scripts
+// convert them with int() or float().
+var csvModule = &starlarkstruct.Module{
+	Name: "csv",
+	Members: starlark.StringDict{
+		"decode": starlark.NewBuiltin("csv.decode", csvDecode),
+		"encode": starlark.NewBuiltin("csv.encode", csvEncode),
+	},
+}
+
+// csvDecode parses text into a list of rows. With header=True the first row
+// names the fields and each following row becomes a dict.
+func csvDecode(
+	_ *starlark.Thread,
+	b *starlark.Builtin,
+	args starlark.Tuple,
+	kwargs []starlark.Tuple,
+) (starlark.Value, error) {
+	var text, delimiter string
+	header := false
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs,
+		"text", &text, "header?", &header, "delimiter?", &delimiter); err != nil {
+		return nil, err
+	}
+	comma, err := csvDelimiter(b.Name(), delimiter)
+	if err != nil {
+		return nil, err
+	}
+	r := csv.NewReader(strings.NewReader(strings.TrimPrefix(text, "\ufeff")))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	var names []string
+	var rows []starlark.Value
+	for {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", b.Name(), err)
+		}
+		if header && names == nil {
+			names = rec
+			continue
+		}
+		if !header {
+			fields := make([]starlark.Value, len(rec))
+			for i, f := range rec {
+				fields[i] = starlark.String(f)
+			}
+			rows = append(rows, starlark.NewList(fields))
+			continue
+		}
+		row := starlark.NewDict(len(names))
+		for i, name := range names {
+			value := ""
+			if i < len(rec) {
+				value = rec[i]
+			}
+			if err := row.SetKey(starlark.String(name), starlark.String(value)); err != nil {
+				return nil, err
+			}
+		}
+		rows = append(rows, row)
+	}
+	return starlark.NewList(rows), nil
+}
+
+// csvEncode renders a list of rows, each a list or tuple of values, as CSV.
+func csvEncode(
+	_ *starlark.Thread,
+	b *starlark.Builtin,
+	args starlark.Tuple,
+	kwargs []starlark.Tuple,
+) (starlark.Value, error) {
+	var rows starlark.Iterable
+	var delimiter string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "rows", &rows, "delimiter?", &delimiter); err != nil {
+		return nil, err
+	}
+	comma, err := csvDelimiter(b.Name(), delimiter)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = comma
+
+	iter := rows.Iterate()
+	defer iter.Done()
+	var row starlark.Value
+	for iter.Next(&row) {
+		fields, ok := row.(starlark.Iterable)
+		if !ok {
+			return nil, fmt.Errorf("%s: row is %s, want list or tuple", b.Name(), row.Type())
+		}
+		var rec []string
+		fieldIter := fields.Iterate()
+		var field starlark.Value
+		for fieldIter.Next(&field) {
+			if s, ok := starlark.AsString(field); ok {
+				rec = append(rec, s)
+			} else {
+				rec = append(rec, field.String())
+			}
+		}
+		fieldIter.Done()
+		if err := w.Write(rec); err != nil {
+			return nil, fmt.Errorf("%s: %w", b.Name(), err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	return starlark.String(buf.String()), nil
+}
+
+func csvDelimiter(fn, delimiter string) (rune, error) {
+	if delimiter == "" {
+		return ',', nil
+	}
+	r, size := utf8.DecodeRuneInString(delimiter)
+	if size != len(delimiter) || r == '"' || r == '\n' || r == '\r' {
+		return 0, fmt.Errorf("%s: invalid delimiter %q", fn, delimiter)
+	}
+	return r, nil
+}
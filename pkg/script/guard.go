@@ -0,0 +1,531 @@
+package script
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkjson"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+)
+
+// The heap watcher only samples, and a single Starlark step can allocate up
+// to a gigabyte ("a" * (1 << 29), list(range(1 << 29)), str([s] * 1000)).
+// Before the program is compiled, the operations that size their result from
+// their operands are rewritten into calls to guard builtins, which check the
+// result size against the memory budget before anything is allocated. The
+// guard names are not valid identifiers, so a script cannot refer to or
+// shadow them.
+const (
+	guardBinary  = "·binary"
+	guardOperand = "·operand"
+)
+
+// valueSize approximates the bytes a list or tuple element costs.
+const valueSize = 16
+
+// sizedBuiltins preallocate their result from the length of their arguments.
+var sizedBuiltins = []string{"list", "tuple", "sorted", "enumerate", "zip"}
+
+// formattingBuiltins build a string from the string form of their arguments.
+var formattingBuiltins = []string{"str", "repr", "print"}
+
+// guardedMethods are string methods whose result is sized from their
+// arguments; x.name(...) is rewritten to ·name(x, ...).
+var guardedMethods = []string{"replace", "join", "format"}
+
+// compile parses src, guards its sized operations and compiles it against
+// the predeclared names.
+func compile(name, src string, predeclared starlark.StringDict) (*starlark.Program, error) {
+	f, err := fileOptions.Parse(name, src, 0)
+	if err != nil {
+		return nil, err
+	}
+	guardFile(f)
+	return starlark.FileProgram(f, predeclared.Has)
+}
+
+// guardFile rewrites f in place. syntax.Walk does not cover every statement
+// kind in this version of the interpreter, so the tree is walked here.
+func guardFile(f *syntax.File) {
+	guardStmts(f.Stmts)
+}
+
+func guardStmts(stmts []syntax.Stmt) {
+	for _, stmt := range stmts {
+		switch n := stmt.(type) {
+		case *syntax.AssignStmt:
+			n.LHS, n.RHS = guardExpr(n.LHS), guardExpr(n.RHS)
+			switch n.Op {
+			case syntax.PLUS_EQ, syntax.STAR_EQ, syntax.PERCENT_EQ:
+				n.RHS = guardAugmented(n)
+			}
+		case *syntax.ExprStmt:
+			n.X = guardExpr(n.X)
+		case *syntax.ReturnStmt:
+			n.Result = guardExpr(n.Result)
+		case *syntax.IfStmt:
+			n.Cond = guardExpr(n.Cond)
+			guardStmts(n.True)
+			guardStmts(n.False)
+		case *syntax.WhileStmt:
+			n.Cond = guardExpr(n.Cond)
+			guardStmts(n.Body)
+		case *syntax.ForStmt:
+			n.X = guardExpr(n.X)
+			guardStmts(n.Body)
+		case *syntax.DefStmt:
+			guardExprs(n.Params)
+			guardStmts(n.Body)
+		}
+	}
+}
+
+func guardExprs(list []syntax.Expr) {
+	for i, e := range list {
+		list[i] = guardExpr(e)
+	}
+}
+
+// guardExpr guards the operands of e, then replaces x + y, x * y and x % y
+// with ·binary("+", x, y) and s.replace(...), s.join(...) and s.format(...)
+// with ·replace(s, ...) and so on.
+func guardExpr(e syntax.Expr) syntax.Expr {
+	switch n := e.(type) {
+	case *syntax.BinaryExpr:
+		n.X, n.Y = guardExpr(n.X), guardExpr(n.Y)
+		switch n.Op {
+		case syntax.PLUS, syntax.STAR, syntax.PERCENT:
+			return guardCall(guardBinary, n.OpPos, opLiteral(n.Op, n.OpPos), n.X, n.Y)
+		}
+	case *syntax.CallExpr:
+		n.Fn = guardExpr(n.Fn)
+		guardExprs(n.Args)
+		if dot, ok := n.Fn.(*syntax.DotExpr); ok && slices.Contains(guardedMethods, dot.Name.Name) {
+			return guardCall("·"+dot.Name.Name, n.Lparen, append([]syntax.Expr{dot.X}, n.Args...)...)
+		}
+	case *syntax.UnaryExpr:
+		if n.X != nil {
+			n.X = guardExpr(n.X)
+		}
+	case *syntax.ParenExpr:
+		n.X = guardExpr(n.X)
+	case *syntax.DotExpr:
+		n.X = guardExpr(n.X)
+	case *syntax.IndexExpr:
+		n.X, n.Y = guardExpr(n.X), guardExpr(n.Y)
+	case *syntax.SliceExpr:
+		n.X, n.Lo, n.Hi, n.Step = guardExpr(n.X), guardExpr(n.Lo), guardExpr(n.Hi), guardExpr(n.Step)
+	case *syntax.CondExpr:
+		n.Cond, n.True, n.False = guardExpr(n.Cond), guardExpr(n.True), guardExpr(n.False)
+	case *syntax.LambdaExpr:
+		guardExprs(n.Params)
+		n.Body = guardExpr(n.Body)
+	case *syntax.Comprehension:
+		n.Body = guardExpr(n.Body)
+		for _, clause := range n.Clauses {
+			switch c := clause.(type) {
+			case *syntax.ForClause:
+				c.X = guardExpr(c.X)
+			case *syntax.IfClause:
+				c.Cond = guardExpr(c.Cond)
+			}
+		}
+	case *syntax.DictExpr:
+		guardExprs(n.List)
+	case *syntax.DictEntry:
+		n.Key, n.Value = guardExpr(n.Key), guardExpr(n.Value)
+	case *syntax.ListExpr:
+		guardExprs(n.List)
+	case *syntax.TupleExpr:
+		guardExprs(n.List)
+	}
+	return e
+}
+
+// guardAugmented rewrites the right-hand side of x += y, x *= y and x %= y
+// to ·operand("+", x, y), which checks the size and returns y, so the
+// in-place update keeps its semantics. When the target has calls in it,
+// evaluating it twice could repeat side effects; only the repeat count or
+// the formatted operand is checked then.
+func guardAugmented(n *syntax.AssignStmt) syntax.Expr {
+	op := syntax.PLUS
+	switch n.Op {
+	case syntax.STAR_EQ:
+		op = syntax.STAR
+	case syntax.PERCENT_EQ:
+		op = syntax.PERCENT
+	}
+	target, ok := cloneTarget(n.LHS)
+	if !ok {
+		target = &syntax.Ident{NamePos: n.OpPos, Name: "None"}
+	}
+	return guardCall(guardOperand, n.OpPos, opLiteral(op, n.OpPos), target, n.RHS)
+}
+
+func guardCall(name string, pos syntax.Position, args ...syntax.Expr) *syntax.CallExpr {
+	return &syntax.CallExpr{
+		Fn:     &syntax.Ident{NamePos: pos, Name: name},
+		Lparen: pos,
+		Args:   args,
+		Rparen: pos,
+	}
+}
+
+func opLiteral(op syntax.Token, pos syntax.Position) *syntax.Literal {
+	return &syntax.Literal{Token: syntax.STRING, TokenPos: pos, Raw: `"` + op.String() + `"`, Value: op.String()}
+}
+
+// cloneTarget copies an assignment target made of names, attributes,
+// literals and indexes, which can be evaluated again without side effects.
+func cloneTarget(e syntax.Expr) (syntax.Expr, bool) {
+	switch e := e.(type) {
+	case *syntax.Ident:
+		return &syntax.Ident{NamePos: e.NamePos, Name: e.Name}, true
+	case *syntax.Literal:
+		c := *e
+		return &c, true
+	case *syntax.ParenExpr:
+		return cloneTarget(e.X)
+	case *syntax.DotExpr:
+		x, ok := cloneTarget(e.X)
+		if !ok {
+			return nil, false
+		}
+		return &syntax.DotExpr{X: x, Dot: e.Dot, NamePos: e.NamePos, Name: &syntax.Ident{NamePos: e.NamePos, Name: e.Name.Name}}, true
+	case *syntax.IndexExpr:
+		x, okX := cloneTarget(e.X)
+		y, okY := cloneTarget(e.Y)
+		if !okX || !okY {
+			return nil, false
+		}
+		return &syntax.IndexExpr{X: x, Lbrack: e.Lbrack, Y: y, Rbrack: e.Rbrack}, true
+	}
+	return nil, false
+}
+
+// guardBuiltins returns the guard builtins, the sized and formatting
+// universal builtins wrapped with a size check, and a json module whose
+// encode is checked, for a budget of limit bytes.
+func guardBuiltins(limit uint64) starlark.StringDict {
+	g := &sizeGuard{limit: limit}
+	out := starlark.StringDict{
+		guardBinary:  starlark.NewBuiltin("binary", g.binary),
+		guardOperand: starlark.NewBuiltin("operand", g.operand),
+		"·replace":   starlark.NewBuiltin("replace", g.replace),
+		"·join":      starlark.NewBuiltin("join", g.join),
+		"·format":    starlark.NewBuiltin("format", g.format),
+	}
+	for _, name := range sizedBuiltins {
+		out[name] = starlark.NewBuiltin(name, g.sized(starlark.Universe[name].(*starlark.Builtin)))
+	}
+	for _, name := range formattingBuiltins {
+		out[name] = starlark.NewBuiltin(name, g.formatting(starlark.Universe[name].(*starlark.Builtin)))
+	}
+	members := make(starlark.StringDict, len(starlarkjson.Module.Members))
+	for name, member := range starlarkjson.Module.Members {
+		members[name] = member
+	}
+	members["encode"] = starlark.NewBuiltin("json.encode", g.formatting(members["encode"].(*starlark.Builtin)))
+	out["json"] = &starlarkstruct.Module{Name: starlarkjson.Module.Name, Members: members}
+	return out
+}
+
+type sizeGuard struct {
+	limit uint64
+}
+
+func (g *sizeGuard) check(size uint64, what string) error {
+	if size > g.limit {
+		return &allocError{what: what, size: size, limit: g.limit}
+	}
+	return nil
+}
+
+// allocError reports an operation refused by a guard. runError maps it to
+// ErrMemoryLimit.
+type allocError struct {
+	what        string
+	size, limit uint64
+}
+
+func (e *allocError) Error() string {
+	return fmt.Sprintf("%s would allocate %d bytes (limit %d)", e.what, e.size, e.limit)
+}
+
+// resultSize estimates the size of x op y, or 0 when the result is not a
+// sequence. Overflow saturates.
+func resultSize(op string, x, y starlark.Value, limit uint64) uint64 {
+	switch op {
+	case "%":
+		if format, ok := x.(starlark.String); ok {
+			return satAdd(uint64(len(format)), formattedSize(y, limit))
+		}
+	case "+":
+		return satAdd(sizeOf(x), sizeOf(y))
+	case "*":
+		if n, ok := y.(starlark.Int); ok {
+			return satMul(sizeOf(x), repeatCount(n))
+		}
+		if n, ok := x.(starlark.Int); ok {
+			return satMul(sizeOf(y), repeatCount(n))
+		}
+	}
+	return 0
+}
+
+func (g *sizeGuard) binary(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var op string
+	var x, y starlark.Value
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 3, &op, &x, &y); err != nil {
+		return nil, err
+	}
+	if err := g.check(resultSize(op, x, y, g.limit), op); err != nil {
+		return nil, err
+	}
+	return starlark.Binary(opToken(op), x, y)
+}
+
+// operand checks target op= value and returns value. A None target stands
+// for one that could not be evaluated twice.
+func (g *sizeGuard) operand(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var op string
+	var target, value starlark.Value
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 3, &op, &target, &value); err != nil {
+		return nil, err
+	}
+	size := resultSize(op, target, value, g.limit)
+	if target == starlark.None {
+		if n, ok := value.(starlark.Int); ok && op == "*" {
+			size = repeatCount(n)
+		} else if op == "%" {
+			size = formattedSize(value, g.limit)
+		}
+	}
+	if err := g.check(size, op+"="); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// lookupMethod finds the method x.name that a guarded call stands for.
+func lookupMethod(thread *starlark.Thread, x starlark.Value, name string) (starlark.Value, error) {
+	return starlark.Call(thread, starlark.Universe["getattr"], starlark.Tuple{x, starlark.String(name)}, nil)
+}
+
+// replace bounds s.replace(old, new, count) before calling the method.
+func (g *sizeGuard) replace(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	recv := args[0]
+	method, err := lookupMethod(thread, recv, b.Name())
+	if err != nil {
+		return nil, err
+	}
+	s, isString := recv.(starlark.String)
+	if isString && len(args) >= 3 {
+		old, okOld := starlark.AsString(args[1])
+		repl, okNew := starlark.AsString(args[2])
+		if okOld && okNew && len(repl) > len(old) {
+			n := uint64(strings.Count(string(s), old))
+			if len(args) >= 4 {
+				if limit, ok := args[3].(starlark.Int); ok {
+					if c, ok := limit.Int64(); ok && c >= 0 {
+						n = min(n, uint64(c))
+					}
+				}
+			}
+			size := satAdd(uint64(len(s)), satMul(n, uint64(len(repl)-len(old))))
+			if err := g.check(size, "replace"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return starlark.Call(thread, method, args[1:], kwargs)
+}
+
+// join bounds sep.join(iterable) by the lengths of the joined strings.
+func (g *sizeGuard) join(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	recv := args[0]
+	method, err := lookupMethod(thread, recv, b.Name())
+	if err != nil {
+		return nil, err
+	}
+	if sep, ok := recv.(starlark.String); ok && len(args) == 2 {
+		if iter := starlark.Iterate(args[1]); iter != nil {
+			var size uint64
+			var elem starlark.Value
+			for size <= g.limit && iter.Next(&elem) {
+				if s, ok := elem.(starlark.String); ok {
+					size = satAdd(size, uint64(len(s)+len(sep)))
+				}
+			}
+			iter.Done()
+			if err := g.check(size, "join"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return starlark.Call(thread, method, args[1:], kwargs)
+}
+
+// format bounds s.format(...) by the format string and the string form of
+// every argument.
+func (g *sizeGuard) format(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	recv := args[0]
+	method, err := lookupMethod(thread, recv, b.Name())
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := recv.(starlark.String); ok {
+		size := satAdd(uint64(len(s)), argsSize(args[1:], kwargs, g.limit))
+		if err := g.check(size, "format"); err != nil {
+			return nil, err
+		}
+	}
+	return starlark.Call(thread, method, args[1:], kwargs)
+}
+
+// formatting wraps a builtin that builds a string from the string form of
+// its arguments, such as str, print and json.encode.
+func (g *sizeGuard) formatting(fn *starlark.Builtin) func(*starlark.Thread, *starlark.Builtin, starlark.Tuple, []starlark.Tuple) (starlark.Value, error) {
+	return func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		// str of a string returns it unchanged.
+		if len(args) == 1 && fn.Name() == "str" {
+			if _, ok := args[0].(starlark.String); ok {
+				return starlark.Call(thread, fn, args, kwargs)
+			}
+		}
+		if err := g.check(argsSize(args, kwargs, g.limit), fn.Name()); err != nil {
+			return nil, err
+		}
+		return starlark.Call(thread, fn, args, kwargs)
+	}
+}
+
+func argsSize(args starlark.Tuple, kwargs []starlark.Tuple, limit uint64) uint64 {
+	var size uint64
+	for _, arg := range args {
+		size = satAdd(size, formattedSize(arg, limit))
+	}
+	for _, kv := range kwargs {
+		size = satAdd(size, formattedSize(kv[1], limit))
+	}
+	return size
+}
+
+// formattedSize estimates the length of the string form of v as str, repr
+// and json.encode write it, counting a shared value once per reference and
+// a cycle once. It stops counting once limit is passed.
+func formattedSize(v starlark.Value, limit uint64) uint64 {
+	var size uint64
+	open := make(map[starlark.Value]bool)
+	var walk func(v starlark.Value)
+	walk = func(v starlark.Value) {
+		if size > limit {
+			return
+		}
+		switch v := v.(type) {
+		case starlark.String:
+			size = satAdd(size, uint64(len(v))+2)
+			return
+		case starlark.Bytes:
+			size = satAdd(size, uint64(len(v))+3)
+			return
+		case starlark.Int:
+			if _, ok := v.Int64(); ok {
+				size = satAdd(size, 20)
+			} else {
+				size = satAdd(size, uint64(v.BigInt().BitLen()/3+2))
+			}
+			return
+		case *starlark.List, *starlark.Dict, *starlark.Set:
+			if open[v] {
+				size = satAdd(size, 5)
+				return
+			}
+			open[v] = true
+			defer delete(open, v)
+		case starlark.Tuple:
+		default:
+			size = satAdd(size, 32)
+			return
+		}
+		size = satAdd(size, 2)
+		iter := starlark.Iterate(v)
+		defer iter.Done()
+		var elem starlark.Value
+		for size <= limit && iter.Next(&elem) {
+			size = satAdd(size, 2)
+			walk(elem)
+			if dict, ok := v.(*starlark.Dict); ok {
+				value, _, _ := dict.Get(elem)
+				walk(value)
+			}
+		}
+	}
+	walk(v)
+	return size
+}
+
+// sized wraps a builtin that preallocates from the length of its arguments.
+func (g *sizeGuard) sized(fn *starlark.Builtin) func(*starlark.Thread, *starlark.Builtin, starlark.Tuple, []starlark.Tuple) (starlark.Value, error) {
+	return func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		for _, arg := range args {
+			if n := starlark.Len(arg); n > 0 {
+				if err := g.check(satMul(uint64(n), valueSize), fn.Name()); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return starlark.Call(thread, fn, args, kwargs)
+	}
+}
+
+func sizeOf(v starlark.Value) uint64 {
+	switch v := v.(type) {
+	case starlark.String:
+		return uint64(len(v))
+	case starlark.Bytes:
+		return uint64(len(v))
+	case *starlark.List:
+		return uint64(v.Len()) * valueSize
+	case starlark.Tuple:
+		return uint64(len(v)) * valueSize
+	}
+	return 0
+}
+
+func repeatCount(n starlark.Int) uint64 {
+	if c, ok := n.Int64(); ok {
+		return uint64(max(c, 0))
+	}
+	if n.Sign() < 0 {
+		return 0
+	}
+	return ^uint64(0)
+}
+
+func opToken(op string) syntax.Token {
+	switch op {
+	case "*":
+		return syntax.STAR
+	case "%":
+		return syntax.PERCENT
+	}
+	return syntax.PLUS
+}
+
+func satAdd(a, b uint64) uint64 {
+	if a+b < a {
+		return ^uint64(0)
+	}
+	return a + b
+}
+
+func satMul(a, b uint64) uint64 {
+	if a != 0 && b > ^uint64(0)/a {
+		return ^uint64(0)
+	}
+	return a * b
+}
@@ -0,0 +1,298 @@
+// Package script runs small Starlark programs for the run_script tool. The
+// interpreter is pure Go, has no filesystem or network access unless a
+// ReadFile hook is supplied, and is bounded by execution steps, a wall-clock
+// timeout and an approximate heap budget. Operations that size their result
+// from their operands are checked against the budget before they allocate.
+package script
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/metrics"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.starlark.net/lib/math"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkjson"
+	"go.starlark.net/syntax"
+)
+
+// Default limits applied when an Options field is zero.
+const (
+	DefaultMaxSteps       = 10_000_000
+	DefaultMaxMemoryBytes = 32 << 20
+	DefaultTimeout        = 10 * time.Second
+	DefaultMaxOutputBytes = 64 << 10
+	DefaultMaxResultBytes = 256 << 10
+)
+
+// ResultVar is the global a script assigns to return a value.
+const ResultVar = "result"
+
+var (
+	ErrStepLimit   = errors.New("script exceeded the execution step limit")
+	ErrMemoryLimit = errors.New("script exceeded the memory limit")
+	ErrTimeout     = errors.New("script timed out")
+	ErrResultSize  = errors.New("script result is too large")
+)
+
+// Options bounds a single script run.
+type Options struct {
+	MaxSteps uint64
+	// MaxMemoryBytes is compared against process heap growth while the script
+	// runs, so it is approximate and can be tripped by concurrent allocations
+	// elsewhere in the process. A single repeat, concatenation, formatting
+	// operation or sized builtin whose result would exceed it fails before
+	// allocating.
+	MaxMemoryBytes uint64
+	Timeout        time.Duration
+	MaxOutputBytes int
+	MaxResultBytes int
+	// ReadFile backs the read_file builtin. When nil, read_file fails and the
+	// script has no file access.
+	ReadFile func(path string) ([]byte, error)
+}
+
+// Result is the outcome of a run. On error it still carries the print output
+// and step count gathered before the failure.
+type Result struct {
+	// Value is the JSON encoding of the script's result global, or null.
+	Value           json.RawMessage
+	Output          string
+	OutputTruncated bool
+	Steps           uint64
+}
+
+var fileOptions = &syntax.FileOptions{
+	Set:             true,
+	While:           true,
+	TopLevelControl: true,
+	GlobalReassign:  true,
+	Recursion:       true,
+}
+
+// Run executes src and returns the value the script assigned to result.
+func Run(ctx context.Context, name, src string, opts Options) (*Result, error) {
+	opts = withDefaults(opts)
+	if name == "" {
+		name = "script.star"
+	}
+	res := &Result{Value: json.RawMessage("null")}
+	out := &limitedBuffer{max: opts.MaxOutputBytes}
+	thread := &starlark.Thread{
+		Name: name,
+		Print: func(_ *starlark.Thread, msg string) {
+			out.writeLine(msg)
+		},
+	}
+	thread.SetMaxExecutionSteps(opts.MaxSteps)
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	var memExceeded atomic.Bool
+	stopWatch := watch(ctx, thread, opts.MaxMemoryBytes, &memExceeded)
+
+	env := predeclared(opts)
+	var globals starlark.StringDict
+	prog, err := compile(name, src, env)
+	if err == nil {
+		globals, err = prog.Init(thread, env)
+		globals.Freeze()
+	}
+	stopWatch()
+	res.Steps = thread.ExecutionSteps()
+	res.Output, res.OutputTruncated = out.String(), out.truncated
+	if err != nil {
+		return res, runError(ctx, err, &memExceeded)
+	}
+
+	value, ok := globals[ResultVar]
+	if !ok {
+		return res, nil
+	}
+	if size := formattedSize(value, opts.MaxMemoryBytes); size > opts.MaxMemoryBytes {
+		return res, fmt.Errorf("%w: about %d bytes (limit %d)", ErrResultSize, size, opts.MaxResultBytes)
+	}
+	encoded, err := encodeJSON(value)
+	if err != nil {
+		return res, fmt.Errorf("encode %s: %w", ResultVar, err)
+	}
+	if len(encoded) > opts.MaxResultBytes {
+		return res, fmt.Errorf("%w: %d bytes (limit %d)", ErrResultSize, len(encoded), opts.MaxResultBytes)
+	}
+	res.Value = json.RawMessage(encoded)
+	return res, nil
+}
+
+func withDefaults(opts Options) Options {
+	if opts.MaxSteps == 0 {
+		opts.MaxSteps = DefaultMaxSteps
+	}
+	if opts.MaxMemoryBytes == 0 {
+		opts.MaxMemoryBytes = DefaultMaxMemoryBytes
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.MaxOutputBytes <= 0 {
+		opts.MaxOutputBytes = DefaultMaxOutputBytes
+	}
+	if opts.MaxResultBytes <= 0 {
+		opts.MaxResultBytes = DefaultMaxResultBytes
+	}
+	return opts
+}
+
+// runError maps a cancelled thread back to the limit that stopped it and
+// keeps the Starlark backtrace for ordinary failures.
+func runError(ctx context.Context, err error, memExceeded *atomic.Bool) error {
+	switch {
+	case memExceeded.Load():
+		return ErrMemoryLimit
+	case strings.HasSuffix(err.Error(), "cancelled: too many steps"):
+		return ErrStepLimit
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return ErrTimeout
+	case ctx.Err() != nil:
+		return ctx.Err()
+	}
+	var evalErr *starlark.EvalError
+	if errors.As(err, &evalErr) {
+		var alloc *allocError
+		if errors.As(err, &alloc) {
+			return fmt.Errorf("%w\n%s", ErrMemoryLimit, evalErr.Backtrace())
+		}
+		return errors.New(evalErr.Backtrace())
+	}
+	return err
+}
+
+// watch cancels the thread when ctx ends or heap growth passes maxMemory.
+// Starlark has no allocation hook, so the heap is sampled; a GC runs before
+// declaring the limit hit so garbage the script already dropped is not
+// counted.
+func watch(ctx context.Context, thread *starlark.Thread, maxMemory uint64, exceeded *atomic.Bool) func() {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		baseline := heapBytes()
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				thread.Cancel(ctx.Err().Error())
+				return
+			case <-ticker.C:
+				if heapBytes() <= baseline+maxMemory {
+					continue
+				}
+				runtime.GC()
+				if heapBytes() > baseline+maxMemory {
+					exceeded.Store(true)
+					thread.Cancel(ErrMemoryLimit.Error())
+					return
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+func heapBytes() uint64 {
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
+
+func predeclared(opts Options) starlark.StringDict {
+	env := starlark.StringDict{
+		"json":      starlarkjson.Module,
+		"math":      math.Module,
+		"csv":       csvModule,
+		"read_file": starlark.NewBuiltin("read_file", readFileBuiltin(opts)),
+	}
+	for name, value := range guardBuiltins(opts.MaxMemoryBytes) {
+		env[name] = value
+	}
+	return env
+}
+
+func readFileBuiltin(opts Options) func(*starlark.Thread, *starlark.Builtin, starlark.Tuple, []starlark.Tuple) (starlark.Value, error) {
+	return func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var path string
+		if err := starlark.UnpackArgs(b.Name(), args, kwargs, "path", &path); err != nil {
+			return nil, err
+		}
+		if opts.ReadFile == nil {
+			return nil, fmt.Errorf("%s: file access is disabled", b.Name())
+		}
+		data, err := opts.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", b.Name(), err)
+		}
+		if uint64(len(data)) > opts.MaxMemoryBytes {
+			return nil, fmt.Errorf("%s: %s is larger than the memory limit", b.Name(), path)
+		}
+		return starlark.String(data), nil
+	}
+}
+
+// encodeJSON converts a Starlark value with json.encode on a fresh thread so
+// a cancelled script thread does not block the conversion.
+func encodeJSON(value starlark.Value) (string, error) {
+	encode := starlarkjson.Module.Members["encode"]
+	thread := &starlark.Thread{Name: "encode"}
+	thread.SetMaxExecutionSteps(DefaultMaxSteps)
+	out, err := starlark.Call(thread, encode, starlark.Tuple{value}, nil)
+	if err != nil {
+		return "", err
+	}
+	s, _ := starlark.AsString(out)
+	return s, nil
+}
+
+// limitedBuffer collects print output up to max bytes.
+type limitedBuffer struct {
+	mu        sync.Mutex
+	b         strings.Builder
+	max       int
+	truncated bool
+}
+
+func (l *limitedBuffer) writeLine(msg string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.truncated {
+		return
+	}
+	line := msg + "\n"
+	if room := l.max - l.b.Len(); len(line) > room {
+		l.b.WriteString(line[:max(room, 0)])
+		l.truncated = true
+		return
+	}
+	l.b.WriteString(line)
+}
+
+func (l *limitedBuffer) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.b.String()
+}
@@ -0,0 +1,212 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun_ReturnsResultAsJSON(t *testing.T) {
+	src := `
+rows = csv.decode("name,qty\napple,3\npear,4\n", header=True)
+total = 0
+for row in rows:
+    total += int(row["qty"])
+print("rows:", len(rows))
+result = {"total": total, "names": [r["name"] for r in rows], "sqrt": math.sqrt(16)}
+`
+	res, err := Run(context.Background(), "", src, Options{})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got, want := string(res.Value), `{"names":["apple","pear"],"sqrt":4,"total":7}`; got != want {
+		t.Fatalf("Value = %s, want %s", got, want)
+	}
+	if res.Output != "rows: 2\n" {
+		t.Fatalf("Output = %q", res.Output)
+	}
+	if res.Steps == 0 {
+		t.Fatal("Steps = 0")
+	}
+}
+
+func TestRun_NoResultIsNull(t *testing.T) {
+	res, err := Run(context.Background(), "", `x = json.decode('{"a": 1}')["a"] + 1`, Options{})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if string(res.Value) != "null" {
+		t.Fatalf("Value = %s, want null", res.Value)
+	}
+}
+
+func TestRun_ErrorIncludesBacktrace(t *testing.T) {
+	res, err := Run(context.Background(), "calc.star", "print('before')\nx = 1 // 0\n", Options{})
+	if err == nil || !strings.Contains(err.Error(), "calc.star:2") || !strings.Contains(err.Error(), "division by zero") {
+		t.Fatalf("Run() error = %v, want backtrace with position", err)
+	}
+	if res.Output != "before\n" {
+		t.Fatalf("Output = %q, want output before the failure", res.Output)
+	}
+}
+
+func TestRun_StepLimit(t *testing.T) {
+	_, err := Run(context.Background(), "", "while True:\n    pass\n", Options{MaxSteps: 10_000})
+	if !errors.Is(err, ErrStepLimit) {
+		t.Fatalf("Run() error = %v, want ErrStepLimit", err)
+	}
+}
+
+func TestRun_Timeout(t *testing.T) {
+	started := time.Now()
+	_, err := Run(context.Background(), "", "while True:\n    pass\n", Options{
+		MaxSteps: 1 << 62,
+		Timeout:  100 * time.Millisecond,
+	})
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Run() error = %v, want ErrTimeout", err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Fatalf("Run() took %v after a 100ms timeout", elapsed)
+	}
+}
+
+func TestRun_MemoryLimit(t *testing.T) {
+	src := `
+chunks = []
+for i in range(100000):
+    chunks.append("x" * 65536)
+`
+	_, err := Run(context.Background(), "", src, Options{MaxSteps: 1 << 62, MaxMemoryBytes: 8 << 20, Timeout: time.Minute})
+	if !errors.Is(err, ErrMemoryLimit) {
+		t.Fatalf("Run() error = %v, want ErrMemoryLimit", err)
+	}
+}
+
+func TestRun_ReadFile(t *testing.T) {
+	src := `result = read_file("data.txt").upper()`
+	if _, err := Run(context.Background(), "", src, Options{}); err == nil ||
+		!strings.Contains(err.Error(), "file access is disabled") {
+		t.Fatalf("Run() without ReadFile error = %v", err)
+	}
+
+	res, err := Run(context.Background(), "", src, Options{ReadFile: func(path string) ([]byte, error) {
+		if path != "data.txt" {
+			return nil, os.ErrNotExist
+		}
+		return []byte("hello"), nil
+	}})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if string(res.Value) != `"HELLO"` {
+		t.Fatalf("Value = %s", res.Value)
+	}
+}
+
+func TestRun_NoLoad(t *testing.T) {
+	if _, err := Run(context.Background(), "", `load("os.star", "system")`, Options{}); err == nil {
+		t.Fatal("Run() with load() expected error")
+	}
+}
+
+func TestRun_OutputAndResultLimits(t *testing.T) {
+	res, err := Run(context.Background(), "", "for i in range(100):\n    print('0123456789')\n", Options{MaxOutputBytes: 25})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(res.Output) != 25 || !res.OutputTruncated {
+		t.Fatalf("Output = %q (truncated=%v), want 25 bytes truncated", res.Output, res.OutputTruncated)
+	}
+
+	_, err = Run(context.Background(), "", `result = "x" * 1000`, Options{MaxResultBytes: 100})
+	if !errors.Is(err, ErrResultSize) {
+		t.Fatalf("Run() error = %v, want ErrResultSize", err)
+	}
+}
+
+func TestCSVEncode(t *testing.T) {
+	res, err := Run(context.Background(), "", `result = csv.encode([["a", 1], ["b,c", 2.5]], delimiter=";")`, Options{})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if string(res.Value) != `"a;1\nb,c;2.5\n"` {
+		t.Fatalf("Value = %s", res.Value)
+	}
+}
+
+func TestRun_OversizedSingleStepFailsBeforeAllocating(t *testing.T) {
+	for _, src := range []string{
+		`s = "a" * (1 << 29)`,
+		`s = (1 << 29) * "a"`,
+		`l = [0] * (1 << 29)`,
+		"s = \"a\" * (1 << 20)\ns += s * 64",
+		"s = \"a\" * (1 << 20)\nfor i in range(10):\n    s = s + s",
+		`l = list(range(1 << 29))`,
+		`s = ("a" * 4096).replace("", "b" * 65536)`,
+		"d = {'k': [0]}\nd['k'] *= 1 << 29",
+		"s = \"x\" * 30000000\nt = str([s] * 8)",
+		"s = \"x\" * 30000000\nt = repr([s] * 8)",
+		"s = \"x\" * 30000000\nprint([s] * 8)",
+		"s = \"x\" * 30000000\nt = json.encode([s] * 8)",
+		"s = \"x\" * 30000000\nt = \"\".join([s] * 8)",
+		"s = \"x\" * 30000000\nt = \"%s%s\" % (s, s)",
+		"s = \"x\" * 30000000\nt = \"%s\"\nt %= ([s] * 8,)",
+		"s = \"x\" * 30000000\nt = \"{}{}\".format(s, s)",
+	} {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		_, err := Run(context.Background(), "", src, Options{})
+		runtime.ReadMemStats(&after)
+		if !errors.Is(err, ErrMemoryLimit) {
+			t.Errorf("Run(%q) error = %v, want ErrMemoryLimit", src, err)
+		}
+		if grown := after.TotalAlloc - before.TotalAlloc; grown > 2*DefaultMaxMemoryBytes {
+			t.Errorf("Run(%q) allocated %d bytes", src, grown)
+		}
+	}
+}
+
+func TestRun_GuardedOperatorsKeepSemantics(t *testing.T) {
+	src := `
+a = [1]
+b = a
+b += [2]
+c = {"n": "x"}
+c["n"] *= 3
+def f(x, y=2 * 3):
+    return x + y
+result = {"alias": a, "rep": c["n"], "f": f(1), "join": "a,b".replace(",", "-"), "cat": (1,) + (2,)}
+`
+	res, err := Run(context.Background(), "", src, Options{})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got, want := string(res.Value), `{"alias":[1,2],"cat":[1,2],"f":7,"join":"a-b","rep":"xxx"}`; got != want {
+		t.Fatalf("Value = %s, want %s", got, want)
+	}
+	src = `
+s = "%s-%d" % ("a", 7)
+s %= ()
+result = [s, "-".join(["x", "y"]), "{}:{v}".format(1, v=[2]), str([1, "b"]), str("c"), repr("d"), json.encode({"k": (1,)}), 7 % 4]
+`
+	res, err = Run(context.Background(), "", src, Options{})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got, want := string(res.Value), `["a-7","x-y","1:[2]","[1, \"b\"]","c","\"d\"","{\"k\":[1]}",3]`; got != want {
+		t.Fatalf("Value = %s, want %s", got, want)
+	}
+	if _, err := Run(context.Background(), "", "s = \"x\" * 30000000\nresult = [s] * 8", Options{}); !errors.Is(err, ErrResultSize) {
+		t.Fatalf("Run() oversized result error = %v, want ErrResultSize", err)
+	}
+	if _, err := Run(context.Background(), "calc.star", `x = 1 + "a"`, Options{}); err == nil ||
+		!strings.Contains(err.Error(), "unknown binary op: int + string") {
+		t.Fatalf("Run() type error = %v", err)
+	}
+}
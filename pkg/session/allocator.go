@@ -44,6 +44,15 @@ func AllocateRouteSession(input AllocationInput) Allocation {
 
 func buildSessionScope(input AllocationInput) SessionScope {
 	inbound := input.Context
+	if senderID := linkedDirectSenderID(input); senderID != "" {
+		return SessionScope{
+			Version:    ScopeVersionV1,
+			Channel:    LinkedScopeChannel,
+			Account:    routing.NormalizeAccountID(""),
+			Dimensions: []string{"sender"},
+			Values:     map[string]string{"sender": senderID},
+		}
+	}
 	includeTopicInChatDimension := shouldPreserveTelegramForumIsolation(input)
 	scope := SessionScope{
 		Version: ScopeVersionV1,
@@ -114,6 +123,21 @@ func buildSessionScope(input AllocationInput) SessionScope {
 	return scope
 }
 
+// linkedDirectSenderID returns the canonical sender of a direct chat whose
+// identity was linked across channels, or "" when the chat keeps its
+// per-channel scope.
+func linkedDirectSenderID(input AllocationInput) string {
+	inbound := input.Context
+	if len(input.SessionPolicy.SharedIdentityLinks) == 0 ||
+		!strings.EqualFold(strings.TrimSpace(inbound.ChatType), "direct") {
+		return ""
+	}
+	if resolveLinkedPeerID(input.SessionPolicy.SharedIdentityLinks, inbound.Channel, inbound.SenderID) == "" {
+		return ""
+	}
+	return CanonicalSessionIdentityID(inbound.Channel, inbound.SenderID, input.SessionPolicy.IdentityLinks)
+}
+
 func buildLegacySessionAliases(input AllocationInput) []string {
 	aliases := []string{strings.ToLower(BuildLegacyMainAlias(input.AgentID))}
 	inbound := input.Context
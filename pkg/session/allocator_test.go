@@ -139,6 +139,40 @@ func TestAllocateRouteSession_PicoDirectAliasesIncludeLegacyChatKey(t *testing.T
 	}
 }
 
+func TestAllocateRouteSession_LinkedDirectChatsShareSession(t *testing.T) {
+	links := map[string][]string{"telegram:123": {"discord:456", "telegram:123"}}
+	policy := routing.SessionPolicy{
+		Dimensions:          []string{"chat", "sender"},
+		IdentityLinks:       links,
+		SharedIdentityLinks: links,
+	}
+	telegram := AllocateRouteSession(AllocationInput{
+		AgentID:       "main",
+		Context:       bus.InboundContext{Channel: "telegram", ChatID: "123", ChatType: "direct", SenderID: "123"},
+		SessionPolicy: policy,
+	})
+	discord := AllocateRouteSession(AllocationInput{
+		AgentID:       "main",
+		Context:       bus.InboundContext{Channel: "discord", ChatID: "dm-9", ChatType: "direct", SenderID: "456"},
+		SessionPolicy: policy,
+	})
+	if telegram.SessionKey != discord.SessionKey {
+		t.Fatalf("linked direct chats should share a session: %q != %q", telegram.SessionKey, discord.SessionKey)
+	}
+	if telegram.Scope.Channel != LinkedScopeChannel || telegram.Scope.Values["sender"] != "telegram:123" {
+		t.Fatalf("Scope = %+v, want linked scope for telegram:123", telegram.Scope)
+	}
+
+	group := AllocateRouteSession(AllocationInput{
+		AgentID:       "main",
+		Context:       bus.InboundContext{Channel: "discord", ChatID: "guild-1", ChatType: "group", SenderID: "456"},
+		SessionPolicy: policy,
+	})
+	if group.Scope.Channel != "discord" {
+		t.Fatalf("group chats keep their channel scope, got %+v", group.Scope)
+	}
+}
+
 func TestBuildOpaqueSessionKey_IsStable(t *testing.T) {
 	first := BuildOpaqueSessionKey("agent:main:direct:user123")
 	second := BuildOpaqueSessionKey("agent:main:direct:user123")
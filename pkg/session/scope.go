@@ -3,6 +3,11 @@ package session
 // ScopeVersionV1 is the first structured session-scope schema version.
 const ScopeVersionV1 = 1
 
+// LinkedScopeChannel replaces the channel of direct-chat scopes whose sender
+// was linked across channels with /link, so all linked accounts share one
+// session.
+const LinkedScopeChannel = "linked"
+
 // SessionScope describes the semantic session partition selected for a turn.
 type SessionScope struct {
 	Version    int               `json:"version"`
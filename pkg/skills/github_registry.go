@@ -297,6 +297,18 @@ func (r *GitHubRegistry) GetSkillMeta(ctx context.Context, target string) (*Skil
 	}, nil
 }
 
+// ResolveCommit returns the commit version (a branch, tag or SHA) of target
+// currently points at; an empty version means the default branch.
+func (r *GitHubRegistry) ResolveCommit(ctx context.Context, target, version string) (string, error) {
+	parsedTarget, err := r.installer.resolveGitHubTarget(ctx, target, version)
+	if err != nil {
+		return "", err
+	}
+	ref := parsedTarget.Ref
+	return r.installer.resolveCommitWithAPIBaseURL(
+		ctx, parsedTarget.Endpoints.APIBaseURL, ref.Owner, ref.RepoName, ref.Ref)
+}
+
 func (r *GitHubRegistry) DownloadAndInstall(
 	ctx context.Context,
 	target, version, targetDir string,
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
@@ -428,6 +429,16 @@ func (si *SkillInstaller) InstallFromGitHubToDir(
 		return nil, err
 	}
 	ref := target.Ref
+	// Download from the commit the ref points at so the result can be pinned.
+	// Without it (for example when rate limited) the ref itself is used.
+	commit, err := si.resolveCommitWithAPIBaseURL(ctx, target.Endpoints.APIBaseURL, ref.Owner, ref.RepoName, ref.Ref)
+	if err != nil {
+		commit = ""
+	}
+	downloadRef := ref.Ref
+	if commit != "" {
+		downloadRef = commit
+	}
 	apiSubPath := strings.Trim(ref.SubPath, "/")
 	if isSkillMarkdownPath(apiSubPath) {
 		if dir := path.Dir(apiSubPath); dir == "." {
@@ -442,7 +453,7 @@ func (si *SkillInstaller) InstallFromGitHubToDir(
 	if apiSubPath != "" {
 		apiPath = path.Join(apiPath, apiSubPath)
 	}
-	apiURL := fmt.Sprintf("%s/repos/%s?ref=%s", target.Endpoints.APIBaseURL, apiPath, url.QueryEscape(ref.Ref))
+	apiURL := fmt.Sprintf("%s/repos/%s?ref=%s", target.Endpoints.APIBaseURL, apiPath, url.QueryEscape(downloadRef))
 
 	if err := si.getGithubDirAllFiles(ctx, apiURL, skillDirectory, true); err != nil {
 		// Fallback to raw download
@@ -451,7 +462,7 @@ func (si *SkillInstaller) InstallFromGitHubToDir(
 			target.Endpoints.RawBaseURL,
 			ref.Owner,
 			ref.RepoName,
-			ref.Ref,
+			downloadRef,
 			ref.SubPath,
 			skillDirectory,
 		); downloadErr != nil {
@@ -461,7 +472,49 @@ func (si *SkillInstaller) InstallFromGitHubToDir(
 		return nil, fmt.Errorf("SKILL.md not found in repository")
 	}
 
-	return &InstallResult{Version: ref.Ref}, nil
+	return &InstallResult{Version: ref.Ref, Commit: commit}, nil
+}
+
+var commitSHAPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// resolveCommitWithAPIBaseURL returns the commit SHA a branch, tag or SHA
+// points at.
+func (si *SkillInstaller) resolveCommitWithAPIBaseURL(
+	ctx context.Context,
+	apiBaseURL, owner, repo, ref string,
+) (string, error) {
+	if commitSHAPattern.MatchString(ref) {
+		return ref, nil
+	}
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/commits/%s",
+		strings.TrimRight(apiBaseURL, "/"), owner, repo, url.PathEscape(ref))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github.sha")
+	if si.githubToken != "" {
+		req.Header.Set("Authorization", "Bearer "+si.githubToken)
+	}
+
+	resp, err := utils.DoRequestWithRetry(si.client, req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to resolve %s: HTTP %d", ref, resp.StatusCode)
+	}
+	sha := strings.TrimSpace(string(body))
+	if !commitSHAPattern.MatchString(sha) {
+		return "", fmt.Errorf("unexpected commit response for %s", ref)
+	}
+	return sha, nil
 }
 
 // downloadDir recursively downloads a directory from GitHub API
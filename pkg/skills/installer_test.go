@@ -341,7 +341,12 @@ func TestSkillInstallerInstallFromGitHubToDirSupportsBlobSkillURL(t *testing.T)
 	var server *httptest.Server
 	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
+		case "/api/v3/repos/org/repo/commits/main":
+			_, _ = w.Write([]byte("0123456789abcdef0123456789abcdef01234567"))
 		case "/api/v3/repos/org/repo/contents/.agents/skills/pr-review":
+			if got := r.URL.Query().Get("ref"); got != "0123456789abcdef0123456789abcdef01234567" {
+				t.Errorf("contents ref = %q, want resolved commit", got)
+			}
 			w.Header().Set("Content-Type", "application/json")
 			_, _ = w.Write([]byte(`[
 				{"type":"file","name":"SKILL.md","download_url":"` + server.URL + `/raw/org/repo/main/.agents/skills/pr-review/SKILL.md"},
@@ -380,6 +385,9 @@ func TestSkillInstallerInstallFromGitHubToDirSupportsBlobSkillURL(t *testing.T)
 	if result.Version != "main" {
 		t.Fatalf("version = %q, want main", result.Version)
 	}
+	if result.Commit != "0123456789abcdef0123456789abcdef01234567" {
+		t.Fatalf("commit = %q, want resolved commit", result.Commit)
+	}
 
 	content, err := os.ReadFile(filepath.Join(targetDir, "SKILL.md"))
 	if err != nil {
@@ -8,7 +8,9 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/gomarkdown/markdown"
 	"github.com/gomarkdown/markdown/ast"
@@ -26,15 +28,33 @@ const (
 )
 
 type SkillMetadata struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Requires    SkillRequirements `json:"requires,omitzero"`
 }
 
 type SkillInfo struct {
-	Name        string `json:"name"`
-	Path        string `json:"path"`
-	Source      string `json:"source"`
-	Description string `json:"description"`
+	Name        string            `json:"name"`
+	Path        string            `json:"path"`
+	Source      string            `json:"source"`
+	Description string            `json:"description"`
+	Requires    SkillRequirements `json:"requires,omitzero"`
+	// Missing lists the requirements this host does not meet.
+	Missing []MissingRequirement `json:"missing,omitempty"`
+	// Tampered is set when a locked workspace skill no longer matches the
+	// integrity hash recorded in skills.lock.
+	Tampered bool `json:"tampered,omitempty"`
+}
+
+// Available reports whether every requirement of the skill is met.
+func (info SkillInfo) Available() bool {
+	return len(info.Missing) == 0
+}
+
+// PlatformUnsupported reports whether the skill cannot run on this OS or
+// architecture at all.
+func (info SkillInfo) PlatformUnsupported() bool {
+	return slices.ContainsFunc(info.Missing, MissingRequirement.Platform)
 }
 
 func (info SkillInfo) validate() error {
@@ -60,6 +80,9 @@ type SkillsLoader struct {
 	workspaceSkills string // workspace skills (project-level)
 	globalSkills    string // global skills (~/.picoclaw/skills)
 	builtinSkills   string // builtin skills
+	checker         *RequirementChecker
+	integrity       integrityCache
+	tamperWarned    sync.Map // skill dir -> integrity already reported
 }
 
 // SkillRoots returns all unique skill root directories used by this loader.
@@ -94,9 +117,26 @@ func NewSkillsLoader(workspace string, globalSkills string, builtinSkills string
 	}
 }
 
+// SetRequirementChecker replaces the checker used to fill SkillInfo.Missing.
+// The default checks binaries, environment variables and the platform only.
+func (sl *SkillsLoader) SetRequirementChecker(checker *RequirementChecker) {
+	sl.checker = checker
+}
+
+func (sl *SkillsLoader) requirementChecker() *RequirementChecker {
+	if sl.checker != nil {
+		return sl.checker
+	}
+	return NewRequirementChecker(nil)
+}
+
+// ListSkills returns every valid skill, with unmet requirements listed in
+// SkillInfo.Missing and locked workspace skills checked against skills.lock.
 func (sl *SkillsLoader) ListSkills() []SkillInfo {
 	skills := make([]SkillInfo, 0)
 	seen := make(map[string]bool)
+	checker := sl.requirementChecker()
+	lock := sl.loadLock()
 
 	addSkills := func(dir, source string) {
 		if dir == "" {
@@ -123,6 +163,7 @@ func (sl *SkillsLoader) ListSkills() []SkillInfo {
 			if metadata != nil {
 				info.Description = metadata.Description
 				info.Name = metadata.Name
+				info.Requires = metadata.Requires
 			}
 			if err := info.validate(); err != nil {
 				slog.Warn("invalid skill from "+source, "name", info.Name, "error", err)
@@ -132,6 +173,10 @@ func (sl *SkillsLoader) ListSkills() []SkillInfo {
 				continue
 			}
 			seen[info.Name] = true
+			info.Missing = checker.Check(info.Requires)
+			if locked, ok := lock[d.Name()]; ok && source == "workspace" {
+				info.Tampered = sl.isTampered(filepath.Join(dir, d.Name()), locked)
+			}
 			skills = append(skills, info)
 		}
 	}
@@ -144,6 +189,56 @@ func (sl *SkillsLoader) ListSkills() []SkillInfo {
 	return skills
 }
 
+func (sl *SkillsLoader) loadLock() map[string]LockedSkill {
+	if sl.workspace == "" {
+		return nil
+	}
+	lf, err := LoadLockFile(sl.workspace)
+	if err != nil {
+		logger.WarnCF("skills", "Failed to read skills lockfile", map[string]any{"error": err.Error()})
+		return nil
+	}
+	return lf.Skills
+}
+
+// isTampered compares a skill directory with its locked hash. Each mismatch
+// is logged once per distinct content.
+func (sl *SkillsLoader) isTampered(dir string, locked LockedSkill) bool {
+	if locked.Integrity == "" {
+		return false
+	}
+	integrity, err := sl.integrity.hash(dir)
+	if err != nil {
+		logger.WarnCF("skills", "Failed to hash locked skill", map[string]any{
+			"skill": filepath.Base(dir),
+			"error": err.Error(),
+		})
+		return false
+	}
+	if locked.Matches(integrity) {
+		sl.tamperWarned.Delete(dir)
+		return false
+	}
+	if prev, ok := sl.tamperWarned.Swap(dir, integrity); !ok || prev != integrity {
+		logger.WarnCF("skills", "Skill content does not match skills.lock", map[string]any{
+			"skill":    filepath.Base(dir),
+			"expected": locked.Integrity,
+			"actual":   integrity,
+		})
+	}
+	return true
+}
+
+// WorkspaceSkill returns the workspace skill installed in skills/<dirName>.
+func (sl *SkillsLoader) WorkspaceSkill(dirName string) (SkillInfo, bool) {
+	for _, skill := range sl.ListSkills() {
+		if skill.Source == "workspace" && filepath.Base(filepath.Dir(skill.Path)) == dirName {
+			return skill, true
+		}
+	}
+	return SkillInfo{}, false
+}
+
 func (sl *SkillsLoader) LoadSkill(name string) (string, bool) {
 	if err := ValidateSkillName(name); err != nil {
 		return "", false
@@ -192,15 +287,16 @@ func (sl *SkillsLoader) LoadSkillsForContext(skillNames []string) string {
 	return strings.Join(parts, "\n\n---\n\n")
 }
 
+// BuildSkillsSummary lists the skills for the system prompt. Skills for
+// another OS or architecture are left out; skills with other unmet
+// requirements are kept but flagged so the agent can tell the user what to
+// install.
 func (sl *SkillsLoader) BuildSkillsSummary() string {
-	allSkills := sl.ListSkills()
-	if len(allSkills) == 0 {
-		return ""
-	}
-
 	var lines []string
-	lines = append(lines, "<skills>")
-	for _, s := range allSkills {
+	for _, s := range sl.ListSkills() {
+		if s.PlatformUnsupported() {
+			continue
+		}
 		escapedName := escapeXML(s.Name)
 		escapedDesc := escapeXML(s.Description)
 		escapedPath := escapeXML(s.Path)
@@ -210,13 +306,39 @@ func (sl *SkillsLoader) BuildSkillsSummary() string {
 		lines = append(lines, fmt.Sprintf("    <description>%s</description>", escapedDesc))
 		lines = append(lines, fmt.Sprintf("    <location>%s</location>", escapedPath))
 		lines = append(lines, fmt.Sprintf("    <source>%s</source>", s.Source))
+		if unavailable := UnavailableReason(s); unavailable != "" {
+			lines = append(lines, fmt.Sprintf("    <unavailable>%s</unavailable>", escapeXML(unavailable)))
+		}
+		if s.Tampered {
+			lines = append(lines, "    <warning>"+TamperedWarning+"</warning>")
+		}
 		lines = append(lines, "  </skill>")
 	}
+	if len(lines) == 0 {
+		return ""
+	}
+	lines = append([]string{"<skills>"}, lines...)
 	lines = append(lines, "</skills>")
 
 	return strings.Join(lines, "\n")
 }
 
+// TamperedWarning is shown for skills whose files differ from skills.lock.
+const TamperedWarning = "modified since install; content differs from skills.lock"
+
+// UnavailableReason summarizes a skill's unmet requirements for the prompt,
+// or returns "" when it has none.
+func UnavailableReason(info SkillInfo) string {
+	if info.Available() {
+		return ""
+	}
+	missing := make([]string, 0, len(info.Missing))
+	for _, m := range info.Missing {
+		missing = append(missing, m.String())
+	}
+	return "missing " + strings.Join(missing, ", ")
+}
+
 func (sl *SkillsLoader) getSkillMetadata(skillPath string) *SkillMetadata {
 	content, err := os.ReadFile(skillPath)
 	if err != nil {
@@ -235,6 +357,7 @@ func (sl *SkillsLoader) getSkillMetadata(skillPath string) *SkillMetadata {
 	metadata := &SkillMetadata{
 		Name:        dirName,
 		Description: bodyDescription,
+		Requires:    parseSkillRequirements(frontmatter),
 	}
 	if title != "" && namePattern.MatchString(title) && len(title) <= MaxNameLength {
 		metadata.Name = title
@@ -0,0 +1,323 @@
+package skills
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+)
+
+// LockFileName is the lockfile kept in the workspace next to skills/.
+const LockFileName = "skills.lock"
+
+// lockFileVersion is the format version written to LockFile.Version.
+const lockFileVersion = 1
+
+// originMetaFileName is written by installers after the content is hashed, so
+// it is left out of the hash.
+const originMetaFileName = ".skill-origin.json"
+
+// LockFile pins every registry-installed workspace skill to a version and a
+// content hash so an install can be reproduced on another machine.
+type LockFile struct {
+	Version int `json:"version"`
+	// Skills is keyed by the directory name under workspace/skills.
+	Skills map[string]LockedSkill `json:"skills"`
+}
+
+// LockedSkill is one pinned skill.
+type LockedSkill struct {
+	Registry string `json:"registry"`
+	Slug     string `json:"slug"`
+	Version  string `json:"version,omitempty"`
+	// Commit is the source revision for git-backed registries; frozen
+	// installs prefer it over Version.
+	Commit string `json:"commit,omitempty"`
+	// Integrity is "sha256-<hex>" over the installed files (see HashSkillDir).
+	Integrity string `json:"integrity"`
+	// EvolvedIntegrity is the hash of the content skill evolution last wrote
+	// (an apply or a rollback). That content is expected and not reported as
+	// tampering; Integrity still pins what the registry serves.
+	EvolvedIntegrity string `json:"evolved_integrity,omitempty"`
+	InstalledAt      int64  `json:"installed_at,omitempty"`
+}
+
+// Matches reports whether integrity is the installed or the evolved content.
+func (s LockedSkill) Matches(integrity string) bool {
+	return integrity == s.Integrity || (s.EvolvedIntegrity != "" && integrity == s.EvolvedIntegrity)
+}
+
+// InstallRef is the version to request from the registry to reproduce the
+// locked content.
+func (s LockedSkill) InstallRef() string {
+	if s.Commit != "" {
+		return s.Commit
+	}
+	return s.Version
+}
+
+// lockMu serializes read-modify-write cycles on lockfiles in this process.
+var lockMu sync.Mutex
+
+// LockFilePath returns the lockfile path for a workspace.
+func LockFilePath(workspace string) string {
+	return filepath.Join(workspace, LockFileName)
+}
+
+// LoadLockFile reads the workspace lockfile. A missing file yields an empty
+// lockfile.
+func LoadLockFile(workspace string) (*LockFile, error) {
+	lf := &LockFile{Version: lockFileVersion, Skills: map[string]LockedSkill{}}
+	data, err := os.ReadFile(LockFilePath(workspace))
+	if errors.Is(err, os.ErrNotExist) {
+		return lf, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", LockFileName, err)
+	}
+	if err := json.Unmarshal(data, lf); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", LockFileName, err)
+	}
+	if lf.Version > lockFileVersion {
+		return nil, fmt.Errorf("%s version %d is newer than supported version %d",
+			LockFileName, lf.Version, lockFileVersion)
+	}
+	if lf.Skills == nil {
+		lf.Skills = map[string]LockedSkill{}
+	}
+	return lf, nil
+}
+
+// Save writes the lockfile atomically.
+func (lf *LockFile) Save(workspace string) error {
+	lf.Version = lockFileVersion
+	data, err := json.MarshalIndent(lf, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fileutil.WriteFileAtomic(LockFilePath(workspace), append(data, '\n'), 0o644)
+}
+
+// Names returns the locked directory names in sorted order.
+func (lf *LockFile) Names() []string {
+	names := make([]string, 0, len(lf.Skills))
+	for name := range lf.Skills {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RecordInstall hashes workspace/skills/<dirName> and pins it in the
+// lockfile.
+func RecordInstall(workspace, dirName, registry, slug string, result *InstallResult) (LockedSkill, error) {
+	integrity, err := HashSkillDir(filepath.Join(workspace, "skills", dirName))
+	if err != nil {
+		return LockedSkill{}, err
+	}
+	entry := LockedSkill{
+		Registry:    registry,
+		Slug:        slug,
+		Integrity:   integrity,
+		InstalledAt: time.Now().UnixMilli(),
+	}
+	if result != nil {
+		entry.Version = result.Version
+		entry.Commit = result.Commit
+	}
+
+	lockMu.Lock()
+	defer lockMu.Unlock()
+	lf, err := LoadLockFile(workspace)
+	if err != nil {
+		return LockedSkill{}, err
+	}
+	lf.Skills[dirName] = entry
+	return entry, lf.Save(workspace)
+}
+
+// ForgetInstall drops a skill from the lockfile, if present.
+func ForgetInstall(workspace, dirName string) error {
+	lockMu.Lock()
+	defer lockMu.Unlock()
+	lf, err := LoadLockFile(workspace)
+	if err != nil {
+		return err
+	}
+	if _, ok := lf.Skills[dirName]; !ok {
+		return nil
+	}
+	delete(lf.Skills, dirName)
+	return lf.Save(workspace)
+}
+
+// RecordEvolution records the current content of a locked skill as written
+// by skill evolution. Skills missing from the lockfile are left alone.
+func RecordEvolution(workspace, dirName string) error {
+	lockMu.Lock()
+	defer lockMu.Unlock()
+	lf, err := LoadLockFile(workspace)
+	if err != nil {
+		return err
+	}
+	entry, ok := lf.Skills[dirName]
+	if !ok {
+		return nil
+	}
+	integrity, err := HashSkillDir(filepath.Join(workspace, "skills", dirName))
+	if err != nil {
+		return err
+	}
+	entry.EvolvedIntegrity = integrity
+	if integrity == entry.Integrity {
+		entry.EvolvedIntegrity = ""
+	}
+	lf.Skills[dirName] = entry
+	return lf.Save(workspace)
+}
+
+// HashSkillDir returns "sha256-<hex>" over the relative path and content of
+// every regular file in dir, in sorted order. The installer's origin
+// metadata is excluded.
+func HashSkillDir(dir string) (string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == originMetaFileName {
+			return nil
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("hash skill %s: %w", filepath.Base(dir), err)
+	}
+	sort.Strings(files)
+
+	h := sha256.New()
+	for _, rel := range files {
+		f, err := os.Open(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err != nil {
+			return "", fmt.Errorf("hash skill %s: %w", filepath.Base(dir), err)
+		}
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", rel, info.Size())
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", fmt.Errorf("hash skill %s: %w", filepath.Base(dir), err)
+		}
+	}
+	return "sha256-" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// CommitResolver is implemented by registries that can tell which commit a
+// version currently points at, such as GitHub.
+type CommitResolver interface {
+	ResolveCommit(ctx context.Context, slug, version string) (string, error)
+}
+
+// LatestRevision returns what a fresh install of the locked skill would get:
+// the registry's latest version, or for git-backed registries the commit the
+// locked version (usually a branch) now points at.
+func LatestRevision(ctx context.Context, registry SkillRegistry, locked LockedSkill) (string, error) {
+	if resolver, ok := registry.(CommitResolver); ok && locked.Commit != "" {
+		return resolver.ResolveCommit(ctx, locked.Slug, locked.Version)
+	}
+	meta, err := registry.GetSkillMeta(ctx, locked.Slug)
+	if err != nil {
+		return "", err
+	}
+	return meta.LatestVersion, nil
+}
+
+// LockedRevision is the value LatestRevision is compared against.
+func LockedRevision(locked LockedSkill) string {
+	if locked.Commit != "" {
+		return locked.Commit
+	}
+	return locked.Version
+}
+
+// integrityCache remembers directory hashes by a cheap fingerprint of file
+// sizes and modification times, so checking for tampering does not re-read
+// every skill on each ListSkills call.
+type integrityCache struct {
+	mu      sync.Mutex
+	entries map[string]integrityCacheEntry
+}
+
+type integrityCacheEntry struct {
+	fingerprint string
+	integrity   string
+}
+
+func (c *integrityCache) hash(dir string) (string, error) {
+	fingerprint, err := dirFingerprint(dir)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	if entry, ok := c.entries[dir]; ok && entry.fingerprint == fingerprint {
+		c.mu.Unlock()
+		return entry.integrity, nil
+	}
+	c.mu.Unlock()
+
+	integrity, err := HashSkillDir(dir)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = map[string]integrityCacheEntry{}
+	}
+	c.entries[dir] = integrityCacheEntry{fingerprint: fingerprint, integrity: integrity}
+	c.mu.Unlock()
+	return integrity, nil
+}
+
+func dirFingerprint(dir string) (string, error) {
+	var b strings.Builder
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "%s:%d:%d;", path, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	return b.String(), err
+}
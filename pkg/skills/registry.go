@@ -38,7 +38,10 @@ type SkillMeta struct {
 // InstallResult is returned by DownloadAndInstall to carry metadata
 // back to the caller for moderation and user messaging.
 type InstallResult struct {
-	Version          string
+	Version string
+	// Commit is the source revision Version resolved to, for registries that
+	// serve from git. Empty when unknown.
+	Commit           string
 	IsMalwareBlocked bool
 	IsSuspicious     bool
 	Summary          string
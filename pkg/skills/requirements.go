@@ -0,0 +1,333 @@
+package skills
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// requirementNamespaces are the metadata keys skills put their requirements
+// under, in lookup order. nanobot and openclaw are kept for skills written for
+// those runtimes.
+var requirementNamespaces = []string{"picoclaw", "nanobot", "openclaw"}
+
+// SkillRequirements is what a skill needs from the host, parsed from
+// metadata.<namespace>.requires in the SKILL.md frontmatter:
+//
+//	metadata: {"picoclaw":{"os":["linux"],"requires":{"bins":["tmux"],"env":["API_KEY"]}}}
+//
+// os and arch may sit next to requires or inside it.
+type SkillRequirements struct {
+	// Bins must all be on PATH.
+	Bins []string `json:"bins,omitempty"`
+	// AnyBins needs at least one of its entries on PATH.
+	AnyBins []string `json:"any_bins,omitempty"`
+	Env     []string `json:"env,omitempty"`
+	// Tools are PicoClaw tool names that must be enabled.
+	Tools []string `json:"tools,omitempty"`
+	// MCP are MCP server names that must be configured and enabled.
+	MCP []string `json:"mcp,omitempty"`
+	// Config are dotted config.json paths that must be set, such as
+	// "tools.web.brave.api_key".
+	Config []string `json:"config,omitempty"`
+	OS     []string `json:"os,omitempty"`
+	Arch   []string `json:"arch,omitempty"`
+}
+
+// IsZero reports whether the skill declares no requirements.
+func (r SkillRequirements) IsZero() bool {
+	return len(r.Bins) == 0 && len(r.AnyBins) == 0 && len(r.Env) == 0 && len(r.Tools) == 0 &&
+		len(r.MCP) == 0 && len(r.Config) == 0 && len(r.OS) == 0 && len(r.Arch) == 0
+}
+
+// Requirement kinds reported in MissingRequirement.Kind.
+const (
+	RequirementBin    = "bin"
+	RequirementEnv    = "env"
+	RequirementTool   = "tool"
+	RequirementMCP    = "mcp"
+	RequirementConfig = "config"
+	RequirementOS     = "os"
+	RequirementArch   = "arch"
+)
+
+// MissingRequirement is one unmet requirement.
+type MissingRequirement struct {
+	Kind string `json:"kind"`
+	// Name is the missing item; for an unmet any_bins list it is the list
+	// joined with "|", and for os/arch the supported values joined with ",".
+	Name string `json:"name"`
+}
+
+func (m MissingRequirement) String() string {
+	return m.Kind + " " + m.Name
+}
+
+// Platform reports whether the requirement is about the OS or architecture,
+// which installing something cannot fix.
+func (m MissingRequirement) Platform() bool {
+	return m.Kind == RequirementOS || m.Kind == RequirementArch
+}
+
+// Hint tells the user how to satisfy the requirement.
+func (m MissingRequirement) Hint() string {
+	switch m.Kind {
+	case RequirementBin:
+		if strings.Contains(m.Name, "|") {
+			return fmt.Sprintf("install one of %s", strings.ReplaceAll(m.Name, "|", ", "))
+		}
+		return fmt.Sprintf("install %s and make sure it is on PATH", m.Name)
+	case RequirementEnv:
+		return fmt.Sprintf("set the %s environment variable", m.Name)
+	case RequirementTool:
+		return fmt.Sprintf("enable tools.%s in config.json", m.Name)
+	case RequirementMCP:
+		return fmt.Sprintf("configure and enable tools.mcp.servers.%s", m.Name)
+	case RequirementConfig:
+		return fmt.Sprintf("set %s in config.json", m.Name)
+	case RequirementOS:
+		return fmt.Sprintf("only runs on %s (this is %s)", m.Name, runtime.GOOS)
+	case RequirementArch:
+		return fmt.Sprintf("only runs on %s (this is %s)", m.Name, runtime.GOARCH)
+	}
+	return ""
+}
+
+// parseSkillRequirements reads the requirements from SKILL.md frontmatter,
+// which may be YAML or JSON. The first namespace that declares any wins.
+func parseSkillRequirements(frontmatter string) SkillRequirements {
+	if strings.TrimSpace(frontmatter) == "" {
+		return SkillRequirements{}
+	}
+	var doc struct {
+		Metadata any `yaml:"metadata"`
+	}
+	if err := yaml.Unmarshal([]byte(frontmatter), &doc); err != nil {
+		return SkillRequirements{}
+	}
+	// Some skills quote the metadata object as a JSON string.
+	if raw, ok := doc.Metadata.(string); ok {
+		if err := yaml.Unmarshal([]byte(raw), &doc.Metadata); err != nil {
+			return SkillRequirements{}
+		}
+	}
+	metadata, _ := doc.Metadata.(map[string]any)
+	for _, ns := range requirementNamespaces {
+		section, _ := metadata[ns].(map[string]any)
+		if section == nil {
+			continue
+		}
+		requires, _ := section["requires"].(map[string]any)
+		req := SkillRequirements{
+			Bins:    stringList(requires["bins"]),
+			AnyBins: stringList(firstOf(requires, "any_bins", "anyBins")),
+			Env:     stringList(requires["env"]),
+			Tools:   stringList(requires["tools"]),
+			MCP:     stringList(firstOf(requires, "mcp", "mcp_servers")),
+			Config:  stringList(requires["config"]),
+			OS:      stringList(firstOf(requires, "os")),
+			Arch:    stringList(firstOf(requires, "arch")),
+		}
+		if len(req.OS) == 0 {
+			req.OS = stringList(section["os"])
+		}
+		if len(req.Arch) == 0 {
+			req.Arch = stringList(section["arch"])
+		}
+		if !req.IsZero() {
+			return req
+		}
+	}
+	return SkillRequirements{}
+}
+
+func firstOf(m map[string]any, keys ...string) any {
+	for _, key := range keys {
+		if v, ok := m[key]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
+// stringList accepts a list or a single string.
+func stringList(v any) []string {
+	var out []string
+	add := func(s string) {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	switch t := v.(type) {
+	case string:
+		add(t)
+	case []any:
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				add(s)
+			}
+		}
+	}
+	return out
+}
+
+// RequirementChecker decides which skill requirements this host meets. A nil
+// func leaves that kind of requirement unchecked.
+type RequirementChecker struct {
+	LookPath     func(file string) (string, error)
+	Getenv       func(key string) string
+	HasTool      func(name string) bool
+	HasMCPServer func(name string) bool
+	HasConfig    func(path string) bool
+	GOOS         string
+	GOARCH       string
+}
+
+// NewRequirementChecker checks binaries and environment variables on this
+// host, and tools, MCP servers and config keys against cfg. A nil cfg leaves
+// those unchecked.
+func NewRequirementChecker(cfg *config.Config) *RequirementChecker {
+	c := &RequirementChecker{
+		LookPath: exec.LookPath,
+		Getenv:   os.Getenv,
+		GOOS:     runtime.GOOS,
+		GOARCH:   runtime.GOARCH,
+	}
+	if cfg == nil {
+		return c
+	}
+	c.HasTool = cfg.Tools.IsToolEnabled
+	c.HasMCPServer = func(name string) bool {
+		if !cfg.Tools.MCP.Enabled {
+			return false
+		}
+		server, ok := cfg.Tools.MCP.Servers[name]
+		return ok && server.Enabled
+	}
+	c.HasConfig = func(path string) bool { return configPathSet(cfg, path) }
+	return c
+}
+
+// Check returns the requirements that are not met, in declaration order.
+func (c *RequirementChecker) Check(req SkillRequirements) []MissingRequirement {
+	if c == nil {
+		return nil
+	}
+	var missing []MissingRequirement
+	if len(req.OS) > 0 && c.GOOS != "" && !containsFold(req.OS, c.GOOS) {
+		missing = append(missing, MissingRequirement{Kind: RequirementOS, Name: strings.Join(req.OS, ",")})
+	}
+	if len(req.Arch) > 0 && c.GOARCH != "" && !containsFold(req.Arch, c.GOARCH) {
+		missing = append(missing, MissingRequirement{Kind: RequirementArch, Name: strings.Join(req.Arch, ",")})
+	}
+	if c.LookPath != nil {
+		for _, bin := range req.Bins {
+			if _, err := c.LookPath(bin); err != nil {
+				missing = append(missing, MissingRequirement{Kind: RequirementBin, Name: bin})
+			}
+		}
+		if len(req.AnyBins) > 0 && !slices.ContainsFunc(req.AnyBins, func(bin string) bool {
+			_, err := c.LookPath(bin)
+			return err == nil
+		}) {
+			missing = append(missing, MissingRequirement{Kind: RequirementBin, Name: strings.Join(req.AnyBins, "|")})
+		}
+	}
+	missing = appendUnmet(missing, RequirementEnv, req.Env, func(key string) bool {
+		return c.Getenv == nil || c.Getenv(key) != ""
+	})
+	missing = appendUnmet(missing, RequirementTool, req.Tools, c.HasTool)
+	missing = appendUnmet(missing, RequirementMCP, req.MCP, c.HasMCPServer)
+	missing = appendUnmet(missing, RequirementConfig, req.Config, c.HasConfig)
+	return missing
+}
+
+func appendUnmet(missing []MissingRequirement, kind string, names []string, ok func(string) bool) []MissingRequirement {
+	if ok == nil {
+		return missing
+	}
+	for _, name := range names {
+		if !ok(name) {
+			missing = append(missing, MissingRequirement{Kind: kind, Name: name})
+		}
+	}
+	return missing
+}
+
+func containsFold(values []string, s string) bool {
+	return slices.ContainsFunc(values, func(v string) bool { return strings.EqualFold(v, s) })
+}
+
+var secureStringType = reflect.TypeOf(config.SecureString{})
+
+// configPathSet reports whether the dotted json path in cfg holds a non-zero
+// value. Secrets count as set when they resolve to a non-empty string.
+func configPathSet(cfg *config.Config, path string) bool {
+	v := reflect.ValueOf(cfg)
+	for _, part := range strings.Split(path, ".") {
+		v = reflect.Indirect(v)
+		if !v.IsValid() {
+			return false
+		}
+		switch v.Kind() {
+		case reflect.Struct:
+			v = jsonField(v, part)
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return false
+			}
+			v = v.MapIndex(reflect.ValueOf(part).Convert(v.Type().Key()))
+		default:
+			return false
+		}
+	}
+	v = reflect.Indirect(v)
+	if !v.IsValid() {
+		return false
+	}
+	if v.Type() == secureStringType {
+		s := v.Interface().(config.SecureString)
+		return s.String() != ""
+	}
+	return !v.IsZero()
+}
+
+// jsonField finds the struct field whose json name is name, looking inside
+// embedded structs.
+func jsonField(v reflect.Value, name string) reflect.Value {
+	if !v.IsValid() || v.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if tag == "-" {
+			continue
+		}
+		if field.Anonymous && tag == "" {
+			if found := jsonField(reflect.Indirect(v.Field(i)), name); found.IsValid() {
+				return found
+			}
+			continue
+		}
+		if tag == "" {
+			tag = field.Name
+		}
+		if strings.EqualFold(tag, name) {
+			return v.Field(i)
+		}
+	}
+	return reflect.Value{}
+}
@@ -0,0 +1,173 @@
+package skills
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestParseSkillRequirements(t *testing.T) {
+	testcases := []struct {
+		name        string
+		frontmatter string
+		want        SkillRequirements
+	}{
+		{
+			name:        "nanobot-flow-map",
+			frontmatter: `metadata: {"nanobot":{"emoji":"🧵","os":["darwin","linux"],"requires":{"bins":["tmux"]}}}`,
+			want:        SkillRequirements{Bins: []string{"tmux"}, OS: []string{"darwin", "linux"}},
+		},
+		{
+			name: "picoclaw-block-map",
+			frontmatter: `metadata:
+  picoclaw:
+    requires:
+      anyBins: [rg, grep]
+      env: API_KEY
+      tools: [web_fetch]
+      mcp: [github]
+      config: [tools.web.brave.api_key]
+      arch: [arm64]`,
+			want: SkillRequirements{
+				AnyBins: []string{"rg", "grep"},
+				Env:     []string{"API_KEY"},
+				Tools:   []string{"web_fetch"},
+				MCP:     []string{"github"},
+				Config:  []string{"tools.web.brave.api_key"},
+				Arch:    []string{"arm64"},
+			},
+		},
+		{
+			name:        "quoted-json-string",
+			frontmatter: `metadata: '{"openclaw":{"requires":{"bins":["curl"]}}}'`,
+			want:        SkillRequirements{Bins: []string{"curl"}},
+		},
+		{
+			name:        "picoclaw-wins-over-nanobot",
+			frontmatter: `metadata: {"nanobot":{"requires":{"bins":["a"]}},"picoclaw":{"requires":{"bins":["b"]}}}`,
+			want:        SkillRequirements{Bins: []string{"b"}},
+		},
+		{
+			name:        "no-requirements",
+			frontmatter: `metadata: {"nanobot":{"emoji":"🦞"}}`,
+		},
+		{
+			name:        "json-frontmatter",
+			frontmatter: `{"name":"x","metadata":{"nanobot":{"requires":{"env":["TOKEN"]}}}}`,
+			want:        SkillRequirements{Env: []string{"TOKEN"}},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, parseSkillRequirements(tc.frontmatter))
+		})
+	}
+}
+
+func TestRequirementCheckerCheck(t *testing.T) {
+	checker := &RequirementChecker{
+		LookPath: func(file string) (string, error) {
+			if file == "grep" {
+				return "/usr/bin/grep", nil
+			}
+			return "", errors.New("not found")
+		},
+		Getenv:       func(key string) string { return map[string]string{"SET": "1"}[key] },
+		HasTool:      func(name string) bool { return name == "exec" },
+		HasMCPServer: func(string) bool { return false },
+		GOOS:         "linux",
+		GOARCH:       "amd64",
+	}
+
+	missing := checker.Check(SkillRequirements{
+		Bins:    []string{"grep", "tmux"},
+		AnyBins: []string{"rg", "ag"},
+		Env:     []string{"SET", "UNSET"},
+		Tools:   []string{"exec", "i2c"},
+		MCP:     []string{"github"},
+		Config:  []string{"unchecked.without.func"},
+		OS:      []string{"darwin"},
+		Arch:    []string{"AMD64"},
+	})
+	assert.Equal(t, []MissingRequirement{
+		{Kind: RequirementOS, Name: "darwin"},
+		{Kind: RequirementBin, Name: "tmux"},
+		{Kind: RequirementBin, Name: "rg|ag"},
+		{Kind: RequirementEnv, Name: "UNSET"},
+		{Kind: RequirementTool, Name: "i2c"},
+		{Kind: RequirementMCP, Name: "github"},
+	}, missing)
+	assert.True(t, missing[0].Platform())
+	assert.Equal(t, "install one of rg, ag", missing[2].Hint())
+}
+
+func TestNewRequirementCheckerConfigPaths(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Tools.MCP.Enabled = true
+	cfg.Tools.MCP.Servers = map[string]config.MCPServerConfig{
+		"github": {Enabled: true, Command: "github-mcp"},
+		"off":    {Enabled: false},
+	}
+	cfg.Tools.Web.Brave.APIKeys = config.SimpleSecureStrings("brave-key")
+
+	checker := NewRequirementChecker(cfg)
+	assert.True(t, checker.HasMCPServer("github"))
+	assert.False(t, checker.HasMCPServer("off"))
+	assert.False(t, checker.HasMCPServer("missing"))
+
+	assert.True(t, checker.HasConfig("tools.mcp.servers.github.command"))
+	assert.False(t, checker.HasConfig("tools.mcp.servers.off.command"))
+	assert.True(t, checker.HasConfig("tools.web.brave.api_keys"))
+	assert.False(t, checker.HasConfig("tools.web.brave.api_key"))
+	assert.False(t, checker.HasConfig("tools.no_such_section"))
+	assert.True(t, checker.HasConfig("tools.exec.enabled"))
+}
+
+func TestBuildSkillsSummaryFlagsUnmetRequirements(t *testing.T) {
+	tmp := t.TempDir()
+	ws := filepath.Join(tmp, "workspace")
+	skillsDir := filepath.Join(ws, "skills")
+	writeSkill := func(name, metadata string) {
+		dir := filepath.Join(skillsDir, name)
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		content := "---\nname: " + name + "\ndescription: " + name + " skill\nmetadata: " + metadata + "\n---\n\n# " + name
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte(content), 0o644))
+	}
+	writeSkill("ready", `{"nanobot":{"requires":{"bins":["sh"]}}}`)
+	writeSkill("needs-tmux", `{"nanobot":{"requires":{"bins":["tmux"]}}}`)
+	writeSkill("mac-only", `{"nanobot":{"os":["darwin"]}}`)
+
+	sl := NewSkillsLoader(ws, "", "")
+	sl.SetRequirementChecker(&RequirementChecker{
+		LookPath: func(file string) (string, error) {
+			if file == "sh" {
+				return "/bin/sh", nil
+			}
+			return "", errors.New("not found")
+		},
+		GOOS: "linux",
+	})
+
+	byName := map[string]SkillInfo{}
+	for _, skill := range sl.ListSkills() {
+		byName[skill.Name] = skill
+	}
+	require.Len(t, byName, 3)
+	assert.True(t, byName["ready"].Available())
+	assert.Equal(t, []MissingRequirement{{Kind: RequirementBin, Name: "tmux"}}, byName["needs-tmux"].Missing)
+	assert.True(t, byName["mac-only"].PlatformUnsupported())
+
+	summary := sl.BuildSkillsSummary()
+	assert.Contains(t, summary, "<name>ready</name>")
+	assert.Contains(t, summary, "<unavailable>missing bin tmux</unavailable>")
+	assert.NotContains(t, summary, "mac-only")
+	assert.Equal(t, 1, strings.Count(summary, "<unavailable>"))
+}
@@ -0,0 +1,262 @@
+package skills
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+)
+
+// ScenarioDirName is the directory next to SKILL.md that holds the skill's
+// test scenarios.
+const ScenarioDirName = "tests"
+
+// Scenario is one scripted check of a skill: a user prompt and what the agent
+// is expected to do with it.
+type Scenario struct {
+	Name   string              `json:"name,omitempty"   yaml:"name,omitempty"`
+	Prompt string              `json:"prompt"           yaml:"prompt"`
+	Script []ScriptedResponse  `json:"script,omitempty" yaml:"script,omitempty"`
+	Expect ScenarioExpectation `json:"expect"           yaml:"expect"`
+	// File is the scenario file relative to the skill directory.
+	File string `json:"-" yaml:"-"`
+}
+
+// ScriptedResponse is one reply of the mock model, consumed in order.
+type ScriptedResponse struct {
+	Content   string             `json:"content,omitempty"    yaml:"content,omitempty"`
+	ToolCalls []ScriptedToolCall `json:"tool_calls,omitempty" yaml:"tool_calls,omitempty"`
+}
+
+type ScriptedToolCall struct {
+	Name      string         `json:"name"                yaml:"name"`
+	Arguments map[string]any `json:"arguments,omitempty" yaml:"arguments,omitempty"`
+}
+
+type ScenarioExpectation struct {
+	// ToolCalls lists tools the agent must call in this order. Other calls
+	// may happen in between.
+	ToolCalls []string `json:"tool_calls,omitempty"     yaml:"tool_calls,omitempty"`
+	// AnswerMatches is a regular expression the final answer must match.
+	AnswerMatches string `json:"answer_matches,omitempty" yaml:"answer_matches,omitempty"`
+}
+
+// Scripted reports whether the scenario carries its own model responses.
+func (s Scenario) Scripted() bool {
+	return len(s.Script) > 0
+}
+
+func (s Scenario) validate() error {
+	if strings.TrimSpace(s.Prompt) == "" {
+		return errors.New("prompt is required")
+	}
+	if len(s.Expect.ToolCalls) == 0 && s.Expect.AnswerMatches == "" {
+		return errors.New("expect needs tool_calls or answer_matches")
+	}
+	if s.Expect.AnswerMatches != "" {
+		if _, err := regexp.Compile(s.Expect.AnswerMatches); err != nil {
+			return fmt.Errorf("answer_matches: %w", err)
+		}
+	}
+	for i, resp := range s.Script {
+		for _, call := range resp.ToolCalls {
+			if strings.TrimSpace(call.Name) == "" {
+				return fmt.Errorf("script[%d]: tool call without a name", i)
+			}
+		}
+	}
+	return nil
+}
+
+// Check compares what the agent did with the expectation and returns one
+// reason per unmet expectation.
+func (s Scenario) Check(toolCalls []string, answer string) []string {
+	var failures []string
+	next := 0
+	for _, call := range toolCalls {
+		if next < len(s.Expect.ToolCalls) && call == s.Expect.ToolCalls[next] {
+			next++
+		}
+	}
+	if next < len(s.Expect.ToolCalls) {
+		failures = append(failures, fmt.Sprintf("expected tool call %q (called: %s)",
+			s.Expect.ToolCalls[next], formatCalls(toolCalls)))
+	}
+	if s.Expect.AnswerMatches != "" {
+		re, err := regexp.Compile(s.Expect.AnswerMatches)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("answer_matches: %v", err))
+		} else if !re.MatchString(answer) {
+			failures = append(failures, fmt.Sprintf("answer does not match /%s/", s.Expect.AnswerMatches))
+		}
+	}
+	return failures
+}
+
+func formatCalls(calls []string) string {
+	if len(calls) == 0 {
+		return "none"
+	}
+	return strings.Join(calls, ", ")
+}
+
+// LoadScenarios reads every *.yaml, *.yml and *.json file in the skill's
+// tests directory, sorted by file name. A skill without scenarios returns nil.
+func LoadScenarios(skillDir string) ([]Scenario, error) {
+	dir := filepath.Join(skillDir, ScenarioDirName)
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var scenarios []Scenario
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		var scenario Scenario
+		if err := yaml.Unmarshal(data, &scenario); err != nil {
+			return nil, fmt.Errorf("%s/%s: %w", ScenarioDirName, name, err)
+		}
+		scenario.File = filepath.ToSlash(filepath.Join(ScenarioDirName, name))
+		if scenario.Name == "" {
+			scenario.Name = strings.TrimSuffix(name, filepath.Ext(name))
+		}
+		if err := scenario.validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", scenario.File, err)
+		}
+		scenarios = append(scenarios, scenario)
+	}
+	sort.Slice(scenarios, func(i, j int) bool { return scenarios[i].File < scenarios[j].File })
+	return scenarios, nil
+}
+
+// Scenario run modes.
+const (
+	ScenarioModeScripted = "scripted"
+	ScenarioModeModel    = "model"
+	ScenarioModeSkipped  = "skipped"
+)
+
+// ScenarioResult records one scenario run.
+type ScenarioResult struct {
+	Name      string   `json:"name"`
+	File      string   `json:"file"`
+	Mode      string   `json:"mode"`
+	Passed    bool     `json:"passed"`
+	Failures  []string `json:"failures,omitempty"`
+	ToolCalls []string `json:"tool_calls,omitempty"`
+	Answer    string   `json:"answer,omitempty"`
+	Duration  int64    `json:"duration_ms"`
+}
+
+// ScenarioReport is the outcome of running a skill's scenarios.
+type ScenarioReport struct {
+	Skill   string           `json:"skill"`
+	Model   string           `json:"model,omitempty"`
+	RanAt   time.Time        `json:"ran_at"`
+	Results []ScenarioResult `json:"results"`
+}
+
+// Counts returns how many scenarios passed, failed and were skipped.
+func (r *ScenarioReport) Counts() (passed, failed, skipped int) {
+	if r == nil {
+		return 0, 0, 0
+	}
+	for _, res := range r.Results {
+		switch {
+		case res.Mode == ScenarioModeSkipped:
+			skipped++
+		case res.Passed:
+			passed++
+		default:
+			failed++
+		}
+	}
+	return passed, failed, skipped
+}
+
+// OK reports whether no scenario failed.
+func (r *ScenarioReport) OK() bool {
+	_, failed, _ := r.Counts()
+	return failed == 0
+}
+
+// FailureSummary describes failed scenarios on one line each.
+func (r *ScenarioReport) FailureSummary() []string {
+	if r == nil {
+		return nil
+	}
+	var lines []string
+	for _, res := range r.Results {
+		if res.Mode == ScenarioModeSkipped || res.Passed {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", res.Name, strings.Join(res.Failures, "; ")))
+	}
+	return lines
+}
+
+func scenarioReportPath(workspace, skill string) string {
+	return filepath.Join(workspace, "state", "skill-tests", skill+".json")
+}
+
+// SaveScenarioReport stores the latest report for a skill under the
+// workspace state directory, outside the skill so its integrity hash is
+// unaffected.
+func SaveScenarioReport(workspace string, report *ScenarioReport) error {
+	if err := ValidateSkillName(report.Skill); err != nil {
+		return err
+	}
+	path := scenarioReportPath(workspace, report.Skill)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fileutil.WriteFileAtomic(path, append(data, '\n'), 0o644)
+}
+
+// LoadScenarioReport returns the last saved report for a skill, or nil when
+// it has never been tested.
+func LoadScenarioReport(workspace, skill string) (*ScenarioReport, error) {
+	if err := ValidateSkillName(skill); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(scenarioReportPath(workspace, skill))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var report ScenarioReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
@@ -0,0 +1,89 @@
+package skills
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadScenarios(t *testing.T) {
+	skillDir := t.TempDir()
+	none, err := LoadScenarios(skillDir)
+	require.NoError(t, err)
+	assert.Nil(t, none)
+
+	testsDir := filepath.Join(skillDir, ScenarioDirName)
+	require.NoError(t, os.MkdirAll(testsDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(testsDir, "b.json"),
+		[]byte(`{"prompt":"hi","expect":{"tool_calls":["exec"]}}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(testsDir, "a.yaml"), []byte(`name: forecast
+prompt: Weather in Paris?
+script:
+  - tool_calls:
+      - name: web_fetch
+        arguments: {url: "https://wttr.in/Paris"}
+  - content: Sunny in Paris.
+expect:
+  tool_calls: [web_fetch]
+  answer_matches: (?i)paris
+`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(testsDir, "notes.md"), []byte("ignored"), 0o644))
+
+	scenarios, err := LoadScenarios(skillDir)
+	require.NoError(t, err)
+	require.Len(t, scenarios, 2)
+	assert.Equal(t, "forecast", scenarios[0].Name)
+	assert.Equal(t, "tests/a.yaml", scenarios[0].File)
+	assert.True(t, scenarios[0].Scripted())
+	assert.Equal(t, "https://wttr.in/Paris", scenarios[0].Script[0].ToolCalls[0].Arguments["url"])
+	assert.Equal(t, "b", scenarios[1].Name)
+	assert.False(t, scenarios[1].Scripted())
+
+	require.NoError(t, os.WriteFile(filepath.Join(testsDir, "c.yaml"), []byte("prompt: hi\n"), 0o644))
+	_, err = LoadScenarios(skillDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tests/c.yaml: expect needs tool_calls or answer_matches")
+}
+
+func TestScenarioCheck(t *testing.T) {
+	s := Scenario{Expect: ScenarioExpectation{
+		ToolCalls:     []string{"read_file", "exec"},
+		AnswerMatches: `(?i)done`,
+	}}
+	assert.Empty(t, s.Check([]string{"read_file", "list_dir", "exec"}, "All done."))
+
+	failures := s.Check([]string{"exec", "read_file"}, "nope")
+	require.Len(t, failures, 2)
+	assert.Equal(t, `expected tool call "exec" (called: exec, read_file)`, failures[0])
+	assert.Equal(t, "answer does not match /(?i)done/", failures[1])
+}
+
+func TestScenarioReportRoundTrip(t *testing.T) {
+	workspace := t.TempDir()
+	report := &ScenarioReport{
+		Skill: "weather",
+		RanAt: time.Unix(1700000000, 0).UTC(),
+		Results: []ScenarioResult{
+			{Name: "a", Mode: ScenarioModeScripted, Passed: true},
+			{Name: "b", Mode: ScenarioModeModel, Failures: []string{"answer does not match /x/"}},
+			{Name: "c", Mode: ScenarioModeSkipped},
+		},
+	}
+	passed, failed, skipped := report.Counts()
+	assert.Equal(t, [3]int{1, 1, 1}, [3]int{passed, failed, skipped})
+	assert.False(t, report.OK())
+	assert.Equal(t, []string{"b: answer does not match /x/"}, report.FailureSummary())
+
+	require.NoError(t, SaveScenarioReport(workspace, report))
+	loaded, err := LoadScenarioReport(workspace, "weather")
+	require.NoError(t, err)
+	assert.Equal(t, report, loaded)
+
+	missing, err := LoadScenarioReport(workspace, "tmux")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
@@ -0,0 +1,279 @@
+package skills
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+var staticVersionPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._+-]{0,63}$`)
+
+// PublishOptions controls PublishStaticIndex.
+type PublishOptions struct {
+	// Version is used for skills whose frontmatter has no version. When both
+	// are empty the version is derived from the content hash.
+	Version string
+	// Now stamps published archives; defaults to time.Now.
+	Now func() time.Time
+}
+
+// PublishedSkill reports what PublishStaticIndex did with one skill.
+type PublishedSkill struct {
+	Slug    string
+	Version string
+	Path    string
+	// Unchanged is set when the same version with identical content was
+	// already in the index.
+	Unchanged bool
+}
+
+// PublishStaticIndex packs every skill directory under srcDir into
+// outDir/<slug>/<slug>-<version>.tar.gz and merges them into
+// outDir/index.json. Existing versions are kept, so outDir can be served as a
+// static registry that accumulates history.
+func PublishStaticIndex(srcDir, outDir string, opts PublishOptions) ([]PublishedSkill, error) {
+	now := time.Now
+	if opts.Now != nil {
+		now = opts.Now
+	}
+	if opts.Version != "" && !staticVersionPattern.MatchString(opts.Version) {
+		return nil, fmt.Errorf("invalid version %q", opts.Version)
+	}
+
+	idx, err := loadStaticIndexFile(filepath.Join(outDir, StaticIndexFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(srcDir)
+	if err != nil {
+		return nil, err
+	}
+	loader := &SkillsLoader{}
+	var published []PublishedSkill
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		skillDir := filepath.Join(srcDir, entry.Name())
+		skillFile := filepath.Join(skillDir, "SKILL.md")
+		if _, err := os.Stat(skillFile); err != nil {
+			continue
+		}
+
+		slug := entry.Name()
+		if err := utils.ValidateSkillIdentifier(slug); err != nil {
+			return nil, fmt.Errorf("skill %s: %w", slug, err)
+		}
+		info := SkillInfo{Name: slug}
+		if meta := loader.getSkillMetadata(skillFile); meta != nil {
+			info.Name, info.Description = meta.Name, meta.Description
+		}
+		if err := info.validate(); err != nil {
+			return nil, fmt.Errorf("skill %s: %w", slug, err)
+		}
+
+		archive, err := packSkillDir(skillDir)
+		if err != nil {
+			return nil, fmt.Errorf("skill %s: %w", slug, err)
+		}
+		sum := sha256.Sum256(archive)
+		digest := hex.EncodeToString(sum[:])
+
+		version, err := publishVersion(skillFile, opts.Version, digest)
+		if err != nil {
+			return nil, fmt.Errorf("skill %s: %w", slug, err)
+		}
+		relPath := path.Join(slug, slug+"-"+version+".tar.gz")
+		result := PublishedSkill{Slug: slug, Version: version, Path: relPath}
+
+		skill, ok := idx.Find(slug)
+		if !ok {
+			idx.Skills = append(idx.Skills, StaticIndexSkill{Slug: slug})
+			skill = &idx.Skills[len(idx.Skills)-1]
+		}
+		skill.DisplayName = info.Name
+		skill.Summary = info.Description
+
+		if existing, ok := skill.Version(version); ok && version != "" {
+			if existing.SHA256 != digest {
+				return nil, fmt.Errorf("skill %s version %s is already published with different content; bump the version",
+					slug, version)
+			}
+			result.Unchanged = true
+			skill.LatestVersion = version
+			published = append(published, result)
+			continue
+		}
+
+		archivePath := filepath.Join(outDir, filepath.FromSlash(relPath))
+		if err := os.MkdirAll(filepath.Dir(archivePath), 0o755); err != nil {
+			return nil, err
+		}
+		if err := fileutil.WriteFileAtomic(archivePath, archive, 0o644); err != nil {
+			return nil, err
+		}
+		skill.Versions = append(skill.Versions, StaticIndexVersion{
+			Version:     version,
+			Path:        relPath,
+			SHA256:      digest,
+			Size:        int64(len(archive)),
+			PublishedAt: now().UTC().Format(time.RFC3339),
+		})
+		skill.LatestVersion = version
+		published = append(published, result)
+	}
+	if len(published) == 0 {
+		return nil, fmt.Errorf("no skills found in %s", srcDir)
+	}
+
+	sort.Slice(idx.Skills, func(i, j int) bool { return idx.Skills[i].Slug < idx.Skills[j].Slug })
+	idx.Version = staticIndexVersion
+	idx.GeneratedAt = now().UTC().Format(time.RFC3339)
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := fileutil.WriteFileAtomic(filepath.Join(outDir, StaticIndexFileName), append(data, '\n'), 0o644); err != nil {
+		return nil, err
+	}
+	return published, nil
+}
+
+func loadStaticIndexFile(p string) (*StaticIndex, error) {
+	idx := &StaticIndex{Version: staticIndexVersion}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return idx, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, idx); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", p, err)
+	}
+	return idx, nil
+}
+
+// publishVersion picks the frontmatter version, then the fallback, then a
+// content-derived version so republishing unchanged content is a no-op.
+func publishVersion(skillFile, fallback, digest string) (string, error) {
+	content, err := os.ReadFile(skillFile)
+	if err != nil {
+		return "", err
+	}
+	frontmatter, _ := splitFrontmatter(string(content))
+	var meta struct {
+		Version any `yaml:"version"`
+	}
+	_ = yaml.Unmarshal([]byte(frontmatter), &meta)
+	version := ""
+	if meta.Version != nil {
+		version = strings.TrimSpace(fmt.Sprint(meta.Version))
+	}
+	if version == "" {
+		version = fallback
+	}
+	if version == "" {
+		version = "0.0.0-" + digest[:12]
+	}
+	if !staticVersionPattern.MatchString(version) {
+		return "", fmt.Errorf("invalid version %q", version)
+	}
+	return version, nil
+}
+
+// packSkillDir builds a reproducible tar.gz of a skill directory: sorted
+// entries, fixed timestamps and no owner information. Hidden files and
+// installer metadata are skipped.
+func packSkillDir(dir string) ([]byte, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == dir {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return fmt.Errorf("symlink %s is not allowed in a published skill", d.Name())
+		}
+		if d.Type().IsRegular() {
+			rel, err := filepath.Rel(dir, p)
+			if err != nil {
+				return err
+			}
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	epoch := time.Unix(0, 0).UTC()
+	for _, rel := range files {
+		full := filepath.Join(dir, filepath.FromSlash(rel))
+		data, err := os.ReadFile(full)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(full)
+		if err != nil {
+			return nil, err
+		}
+		mode := int64(0o644)
+		if info.Mode()&0o111 != 0 {
+			mode = 0o755
+		}
+		hdr := &tar.Header{
+			Name:     rel,
+			Mode:     mode,
+			Size:     int64(len(data)),
+			ModTime:  epoch,
+			Typeflag: tar.TypeReg,
+			Format:   tar.FormatPAX,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,447 @@
+package skills
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	// StaticIndexFileName is the manifest a static registry serves.
+	StaticIndexFileName = "index.json"
+
+	staticIndexVersion           = 1
+	defaultStaticRegistryTimeout = 30 * time.Second
+	defaultStaticIndexTTL        = 5 * time.Minute
+)
+
+func init() {
+	RegisterRegistryProviderBuilder("static", func(_ string, cfg config.SkillRegistryConfig) RegistryProvider {
+		privateCfg := staticRegistryPrivateConfig{}
+		if err := cfg.DecodeParam(&privateCfg); err != nil {
+			slog.Warn("invalid static registry private config", "error", err)
+		}
+		return StaticRegistryConfig{
+			Enabled:        cfg.Enabled,
+			BaseURL:        cfg.BaseURL,
+			AuthToken:      cfg.AuthToken.String(),
+			IndexPath:      privateCfg.IndexPath,
+			Timeout:        privateCfg.Timeout,
+			MaxArchiveSize: privateCfg.MaxArchiveSize,
+		}
+	})
+}
+
+type staticRegistryPrivateConfig struct {
+	IndexPath      string `json:"index_path"`
+	Timeout        int    `json:"timeout"`
+	MaxArchiveSize int    `json:"max_archive_size"`
+}
+
+// StaticRegistryConfig configures a registry served from a static index.
+type StaticRegistryConfig struct {
+	Enabled bool
+	// BaseURL is an http(s) URL, a file:// URL or a local directory holding
+	// index.json and the skill archives.
+	BaseURL        string
+	AuthToken      string
+	IndexPath      string // relative to BaseURL, default "index.json"
+	Timeout        int    // seconds, 0 = default (30s)
+	MaxArchiveSize int    // bytes, 0 = default (50MB)
+}
+
+func (c StaticRegistryConfig) IsEnabled() bool {
+	return c.Enabled && c.BaseURL != ""
+}
+
+func (c StaticRegistryConfig) BuildRegistry() SkillRegistry {
+	return NewStaticRegistry(c)
+}
+
+// StaticIndex is the manifest of a static registry. `picoclaw skills publish`
+// writes it; StaticRegistry reads it.
+type StaticIndex struct {
+	Version     int                `json:"version"`
+	GeneratedAt string             `json:"generated_at,omitempty"`
+	Skills      []StaticIndexSkill `json:"skills"`
+}
+
+// StaticIndexSkill is one skill in a static index.
+type StaticIndexSkill struct {
+	Slug          string `json:"slug"`
+	DisplayName   string `json:"display_name,omitempty"`
+	Summary       string `json:"summary,omitempty"`
+	LatestVersion string `json:"latest_version"`
+	// Versions lists every published archive, oldest first.
+	Versions []StaticIndexVersion `json:"versions"`
+}
+
+// StaticIndexVersion is one published archive of a skill.
+type StaticIndexVersion struct {
+	Version string `json:"version"`
+	// Path is the archive location relative to the index.
+	Path        string `json:"path"`
+	SHA256      string `json:"sha256"`
+	Size        int64  `json:"size"`
+	PublishedAt string `json:"published_at,omitempty"`
+}
+
+// Find returns the skill with the given slug.
+func (idx *StaticIndex) Find(slug string) (*StaticIndexSkill, bool) {
+	for i := range idx.Skills {
+		if idx.Skills[i].Slug == slug {
+			return &idx.Skills[i], true
+		}
+	}
+	return nil, false
+}
+
+// Version returns the named archive, or the latest when version is empty.
+func (s *StaticIndexSkill) Version(version string) (StaticIndexVersion, bool) {
+	if version == "" {
+		version = s.LatestVersion
+	}
+	for _, v := range s.Versions {
+		if v.Version == version {
+			return v, true
+		}
+	}
+	return StaticIndexVersion{}, false
+}
+
+// StaticRegistry implements SkillRegistry over a static index, so a mirror
+// can be a plain directory or any HTTP file server.
+type StaticRegistry struct {
+	base           *url.URL // nil for a local directory
+	localDir       string
+	authToken      string
+	indexPath      string
+	maxArchiveSize int64
+	client         *http.Client
+
+	mu        sync.Mutex
+	index     *StaticIndex
+	fetchedAt time.Time
+	indexTTL  time.Duration
+}
+
+// NewStaticRegistry creates a static registry client from config.
+func NewStaticRegistry(cfg StaticRegistryConfig) *StaticRegistry {
+	indexPath := cfg.IndexPath
+	if indexPath == "" {
+		indexPath = StaticIndexFileName
+	}
+	timeout := defaultStaticRegistryTimeout
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+	maxArchive := int64(defaultMaxZipSize)
+	if cfg.MaxArchiveSize > 0 {
+		maxArchive = int64(cfg.MaxArchiveSize)
+	}
+
+	r := &StaticRegistry{
+		authToken:      cfg.AuthToken,
+		indexPath:      indexPath,
+		maxArchiveSize: maxArchive,
+		client:         &http.Client{Timeout: timeout},
+		indexTTL:       defaultStaticIndexTTL,
+	}
+	if u, err := url.Parse(cfg.BaseURL); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		if !strings.HasSuffix(u.Path, "/") {
+			u.Path += "/"
+		}
+		r.base = u
+	} else if err == nil && u.Scheme == "file" {
+		r.localDir = filepath.FromSlash(u.Path)
+	} else {
+		r.localDir = cfg.BaseURL
+	}
+	return r
+}
+
+func (r *StaticRegistry) Name() string {
+	return "static"
+}
+
+func (r *StaticRegistry) ResolveInstallDirName(target string) (string, error) {
+	if err := utils.ValidateSkillIdentifier(target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// SkillURL points at the skill's archive on HTTP mirrors. Local directories
+// have no web URL.
+func (r *StaticRegistry) SkillURL(slug, version string) string {
+	if r.base == nil || slug == "" {
+		return ""
+	}
+	idx, err := r.cachedIndex()
+	if err != nil || idx == nil {
+		return ""
+	}
+	skill, ok := idx.Find(slug)
+	if !ok {
+		return ""
+	}
+	v, ok := skill.Version(version)
+	if !ok {
+		return ""
+	}
+	u, err := r.resolve(v.Path)
+	if err != nil {
+		return ""
+	}
+	return u
+}
+
+// Search ranks the index with BM25 over slug, name and summary. Scores are
+// scaled to (0, 1] so they merge sensibly with other registries in
+// RegistryManager.SearchAll.
+func (r *StaticRegistry) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	idx, err := r.loadIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = len(idx.Skills)
+	}
+
+	engine := utils.NewBM25Engine(idx.Skills, func(s StaticIndexSkill) string {
+		return strings.Join([]string{
+			s.Slug, strings.ReplaceAll(s.Slug, "-", " "), s.DisplayName, s.Summary,
+		}, " ")
+	})
+	ranked := engine.Search(query, limit)
+	results := make([]SearchResult, 0, len(ranked))
+	for _, hit := range ranked {
+		score := 1.0
+		if top := ranked[0].Score; top > 0 {
+			score = float64(hit.Score / top)
+		}
+		displayName := hit.Document.DisplayName
+		if displayName == "" {
+			displayName = hit.Document.Slug
+		}
+		results = append(results, SearchResult{
+			Score:        score,
+			Slug:         hit.Document.Slug,
+			DisplayName:  displayName,
+			Summary:      hit.Document.Summary,
+			Version:      hit.Document.LatestVersion,
+			RegistryName: r.Name(),
+		})
+	}
+	return results, nil
+}
+
+func (r *StaticRegistry) GetSkillMeta(ctx context.Context, slug string) (*SkillMeta, error) {
+	idx, err := r.loadIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	skill, ok := idx.Find(slug)
+	if !ok {
+		return nil, fmt.Errorf("skill %q not found in static registry", slug)
+	}
+	return &SkillMeta{
+		Slug:          skill.Slug,
+		DisplayName:   skill.DisplayName,
+		Summary:       skill.Summary,
+		LatestVersion: skill.LatestVersion,
+		RegistryName:  r.Name(),
+	}, nil
+}
+
+// DownloadAndInstall fetches the archive for version (latest when empty),
+// checks its SHA-256 against the index and extracts it to targetDir.
+func (r *StaticRegistry) DownloadAndInstall(
+	ctx context.Context,
+	slug, version, targetDir string,
+) (*InstallResult, error) {
+	if err := utils.ValidateSkillIdentifier(slug); err != nil {
+		return nil, fmt.Errorf("invalid slug %q: error: %s", slug, err.Error())
+	}
+	idx, err := r.loadIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	skill, ok := idx.Find(slug)
+	if !ok {
+		return nil, fmt.Errorf("skill %q not found in static registry", slug)
+	}
+	archive, ok := skill.Version(version)
+	if !ok {
+		return nil, fmt.Errorf("skill %q has no version %q", slug, version)
+	}
+
+	tmpPath, err := r.fetchToTempFile(ctx, archive.Path)
+	if err != nil {
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+	defer os.Remove(tmpPath)
+
+	sum, err := fileSHA256(tmpPath)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(sum, archive.SHA256) {
+		return nil, fmt.Errorf("checksum mismatch for %s@%s: index has %s, archive is %s",
+			slug, archive.Version, archive.SHA256, sum)
+	}
+
+	if err := utils.ExtractTarGzFile(tmpPath, targetDir); err != nil {
+		return nil, err
+	}
+	return &InstallResult{Version: archive.Version, Summary: skill.Summary}, nil
+}
+
+func (r *StaticRegistry) cachedIndex() (*StaticIndex, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.client.Timeout)
+	defer cancel()
+	return r.loadIndex(ctx)
+}
+
+// loadIndex returns the index, refetching it once the cached copy is older
+// than indexTTL.
+func (r *StaticRegistry) loadIndex(ctx context.Context) (*StaticIndex, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.index != nil && time.Since(r.fetchedAt) < r.indexTTL {
+		return r.index, nil
+	}
+
+	data, err := r.readSmall(ctx, r.indexPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read static registry index: %w", err)
+	}
+	idx := &StaticIndex{}
+	if err := json.Unmarshal(data, idx); err != nil {
+		return nil, fmt.Errorf("failed to parse static registry index: %w", err)
+	}
+	if idx.Version > staticIndexVersion {
+		return nil, fmt.Errorf("static registry index version %d is newer than supported version %d",
+			idx.Version, staticIndexVersion)
+	}
+	r.index = idx
+	r.fetchedAt = time.Now()
+	return idx, nil
+}
+
+// resolve returns the URL or local path of a file relative to the index.
+func (r *StaticRegistry) resolve(rel string) (string, error) {
+	clean := path.Clean("/" + rel)
+	if strings.Contains(rel, "\\") || clean == "/" {
+		return "", fmt.Errorf("invalid registry path %q", rel)
+	}
+	clean = strings.TrimPrefix(clean, "/")
+	if r.base == nil {
+		return filepath.Join(r.localDir, filepath.FromSlash(clean)), nil
+	}
+	ref, err := url.Parse(clean)
+	if err != nil || ref.IsAbs() || ref.Host != "" {
+		return "", fmt.Errorf("invalid registry path %q", rel)
+	}
+	return r.base.ResolveReference(ref).String(), nil
+}
+
+func (r *StaticRegistry) open(ctx context.Context, rel string) (io.ReadCloser, error) {
+	location, err := r.resolve(rel)
+	if err != nil {
+		return nil, err
+	}
+	if r.base == nil {
+		return os.Open(location)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	if r.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+r.authToken)
+	}
+	resp, err := utils.DoRequestWithRetry(r.client, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("HTTP %d fetching %s", resp.StatusCode, rel)
+	}
+	return resp.Body, nil
+}
+
+func (r *StaticRegistry) readSmall(ctx context.Context, rel string) ([]byte, error) {
+	body, err := r.open(ctx, rel)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	data, err := io.ReadAll(io.LimitReader(body, defaultMaxResponseSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > defaultMaxResponseSize {
+		return nil, fmt.Errorf("%s exceeds %d bytes", rel, defaultMaxResponseSize)
+	}
+	return data, nil
+}
+
+func (r *StaticRegistry) fetchToTempFile(ctx context.Context, rel string) (string, error) {
+	body, err := r.open(ctx, rel)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	tmpFile, err := os.CreateTemp("", "picoclaw-dl-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+
+	written, err := io.Copy(tmpFile, io.LimitReader(body, r.maxArchiveSize+1))
+	closeErr := tmpFile.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return "", fmt.Errorf("download write failed: %w", err)
+	}
+	if written > r.maxArchiveSize {
+		_ = os.Remove(tmpPath)
+		return "", fmt.Errorf("download too large: %d bytes (max %d)", written, r.maxArchiveSize)
+	}
+	return tmpPath, nil
+}
+
+func fileSHA256(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
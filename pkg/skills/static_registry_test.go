@@ -0,0 +1,160 @@
+package skills
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func writeStaticSkill(t *testing.T, srcDir, name, frontmatter, body string) {
+	t.Helper()
+	dir := filepath.Join(srcDir, name)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "scripts"), 0o755))
+	content := "---\nname: " + name + "\n" + frontmatter + "---\n\n" + body
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte(content), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "scripts", "run.sh"), []byte("#!/bin/sh\necho "+name+"\n"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".skill-origin.json"), []byte(`{}`), 0o600))
+}
+
+func publishTestRegistry(t *testing.T) (string, string) {
+	t.Helper()
+	srcDir := t.TempDir()
+	outDir := t.TempDir()
+	writeStaticSkill(t, srcDir, "weather", "description: Get weather forecasts for a city\nversion: 1.0.0\n", "# Weather")
+	writeStaticSkill(t, srcDir, "tmux", "description: Control tmux sessions and panes\n", "# Tmux")
+
+	published, err := PublishStaticIndex(srcDir, outDir, PublishOptions{
+		Version: "0.1.0",
+		Now:     func() time.Time { return time.Unix(1700000000, 0) },
+	})
+	require.NoError(t, err)
+	require.Len(t, published, 2)
+	return srcDir, outDir
+}
+
+func TestPublishStaticIndex(t *testing.T) {
+	srcDir, outDir := publishTestRegistry(t)
+
+	idx, err := loadStaticIndexFile(filepath.Join(outDir, StaticIndexFileName))
+	require.NoError(t, err)
+	require.Len(t, idx.Skills, 2)
+	assert.Equal(t, "tmux", idx.Skills[0].Slug)
+	assert.Equal(t, "0.1.0", idx.Skills[0].LatestVersion, "fallback version applies without frontmatter version")
+	weather, ok := idx.Find("weather")
+	require.True(t, ok)
+	assert.Equal(t, "1.0.0", weather.LatestVersion)
+	assert.Equal(t, "Get weather forecasts for a city", weather.Summary)
+	require.Len(t, weather.Versions, 1)
+	assert.Equal(t, "weather/weather-1.0.0.tar.gz", weather.Versions[0].Path)
+	assert.FileExists(t, filepath.Join(outDir, "weather", "weather-1.0.0.tar.gz"))
+
+	// Republishing identical content is a no-op; the archive is reproducible.
+	published, err := PublishStaticIndex(srcDir, outDir, PublishOptions{Version: "0.1.0"})
+	require.NoError(t, err)
+	for _, p := range published {
+		assert.True(t, p.Unchanged, p.Slug)
+	}
+
+	// Changing content without bumping the version is refused.
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "weather", "scripts", "run.sh"), []byte("echo v2\n"), 0o755))
+	_, err = PublishStaticIndex(srcDir, outDir, PublishOptions{Version: "0.1.0"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bump the version")
+
+	writeStaticSkill(t, srcDir, "weather", "description: Get weather forecasts for a city\nversion: 1.1.0\n", "# Weather")
+	_, err = PublishStaticIndex(srcDir, outDir, PublishOptions{Version: "0.1.0"})
+	require.NoError(t, err)
+	idx, err = loadStaticIndexFile(filepath.Join(outDir, StaticIndexFileName))
+	require.NoError(t, err)
+	weather, _ = idx.Find("weather")
+	assert.Equal(t, "1.1.0", weather.LatestVersion)
+	assert.Len(t, weather.Versions, 2)
+}
+
+func TestStaticRegistryLocalDirectory(t *testing.T) {
+	_, outDir := publishTestRegistry(t)
+	registry := NewStaticRegistry(StaticRegistryConfig{Enabled: true, BaseURL: outDir})
+	ctx := context.Background()
+
+	results, err := registry.Search(ctx, "tmux panes", 5)
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	assert.Equal(t, "tmux", results[0].Slug)
+	assert.Equal(t, 1.0, results[0].Score)
+	assert.Equal(t, "static", results[0].RegistryName)
+
+	meta, err := registry.GetSkillMeta(ctx, "weather")
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", meta.LatestVersion)
+
+	targetDir := filepath.Join(t.TempDir(), "weather")
+	result, err := registry.DownloadAndInstall(ctx, "weather", "", targetDir)
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", result.Version)
+	assert.FileExists(t, filepath.Join(targetDir, "SKILL.md"))
+	info, err := os.Stat(filepath.Join(targetDir, "scripts", "run.sh"))
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&0o100, "executable bit is preserved")
+	assert.NoFileExists(t, filepath.Join(targetDir, ".skill-origin.json"))
+
+	_, err = registry.DownloadAndInstall(ctx, "weather", "9.9.9", filepath.Join(t.TempDir(), "x"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no version")
+}
+
+func TestStaticRegistryHTTPRejectsChecksumMismatch(t *testing.T) {
+	_, outDir := publishTestRegistry(t)
+	server := httptest.NewServer(http.FileServer(http.Dir(outDir)))
+	defer server.Close()
+
+	manager := NewRegistryManagerFromToolsConfig(config.SkillsToolsConfig{
+		Registries: config.SkillsRegistriesConfig{
+			&config.SkillRegistryConfig{Name: "static", Enabled: true, BaseURL: server.URL + "/"},
+		},
+	})
+	registry := manager.GetRegistry("static")
+	require.NotNil(t, registry)
+	ctx := context.Background()
+
+	targetDir := filepath.Join(t.TempDir(), "tmux")
+	result, err := registry.DownloadAndInstall(ctx, "tmux", "0.1.0", targetDir)
+	require.NoError(t, err)
+	assert.Equal(t, "0.1.0", result.Version)
+	assert.Equal(t, server.URL+"/tmux/tmux-0.1.0.tar.gz", registry.SkillURL("tmux", "0.1.0"))
+
+	// Tamper with the archive on the mirror; a fresh client must refuse it.
+	archive := filepath.Join(outDir, "tmux", "tmux-0.1.0.tar.gz")
+	require.NoError(t, os.WriteFile(archive, []byte("not the published archive"), 0o644))
+	fresh := NewStaticRegistry(StaticRegistryConfig{Enabled: true, BaseURL: server.URL})
+	_, err = fresh.DownloadAndInstall(ctx, "tmux", "", filepath.Join(t.TempDir(), "tmux"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch")
+}
+
+func TestStaticRegistryRejectsUnsafeIndexPaths(t *testing.T) {
+	outDir := t.TempDir()
+	idx := StaticIndex{Version: 1, Skills: []StaticIndexSkill{{
+		Slug:          "evil",
+		LatestVersion: "1",
+		Versions:      []StaticIndexVersion{{Version: "1", Path: "http://attacker.example/evil.tar.gz"}},
+	}}}
+	data, err := json.Marshal(idx)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(outDir, StaticIndexFileName), data, 0o644))
+
+	server := httptest.NewServer(http.FileServer(http.Dir(outDir)))
+	defer server.Close()
+	registry := NewStaticRegistry(StaticRegistryConfig{Enabled: true, BaseURL: server.URL})
+	_, err = registry.DownloadAndInstall(context.Background(), "evil", "", filepath.Join(t.TempDir(), "evil"))
+	require.Error(t, err)
+}
@@ -84,6 +84,12 @@ func NewManager(workspace string) *Manager {
 	return sm
 }
 
+// ResolveDir returns the state directory for workspace, honoring the
+// PICOCLAW_STATE_DIR override. A leading ~ in workspace is expanded.
+func ResolveDir(workspace string) string {
+	return resolveStateDir(expandHome(workspace))
+}
+
 func resolveStateDir(workspace string) string {
 	if envDir := strings.TrimSpace(os.Getenv(pkgroot.StateDirEnv)); envDir != "" {
 		return expandHome(envDir)
@@ -0,0 +1,136 @@
+package swarm
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Request headers carrying the caller's identity and signature.
+const (
+	HeaderInstance  = "X-Picoclaw-Swarm-Instance"
+	HeaderTimestamp = "X-Picoclaw-Swarm-Timestamp"
+	HeaderSignature = "X-Picoclaw-Swarm-Signature"
+)
+
+// MaxClockSkew is how far a signed timestamp may be from the local clock.
+const MaxClockSkew = 5 * time.Minute
+
+// ErrUnauthorized is returned when a signature is missing, wrong, stale or
+// replayed.
+var ErrUnauthorized = errors.New("swarm: unauthorized")
+
+// signature is the hex HMAC-SHA256 of parts joined by newlines.
+func signature(secret string, parts ...string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func bodyDigest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+func checkTimestamp(unix int64, now time.Time) error {
+	skew := now.Sub(time.Unix(unix, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > MaxClockSkew {
+		return fmt.Errorf("%w: timestamp is %s off", ErrUnauthorized, skew.Round(time.Second))
+	}
+	return nil
+}
+
+// signRequest sets the signature headers of req, which carries body.
+func signRequest(req *http.Request, secret, instance string, body []byte, now time.Time) {
+	ts := strconv.FormatInt(now.Unix(), 10)
+	req.Header.Set(HeaderInstance, instance)
+	req.Header.Set(HeaderTimestamp, ts)
+	req.Header.Set(HeaderSignature, signature(secret, req.Method, req.URL.Path, instance, ts, bodyDigest(body)))
+}
+
+// verifyRequest checks the signature headers of r, which carried body, and
+// returns the calling instance.
+func verifyRequest(r *http.Request, secret string, body []byte, now time.Time) (string, error) {
+	instance := r.Header.Get(HeaderInstance)
+	ts := r.Header.Get(HeaderTimestamp)
+	sig := r.Header.Get(HeaderSignature)
+	if instance == "" || ts == "" || sig == "" {
+		return "", fmt.Errorf("%w: missing signature headers", ErrUnauthorized)
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("%w: bad timestamp", ErrUnauthorized)
+	}
+	if err := checkTimestamp(unix, now); err != nil {
+		return "", err
+	}
+	expected := signature(secret, r.Method, r.URL.Path, instance, ts, bodyDigest(body))
+	if !hmac.Equal([]byte(sig), []byte(expected)) {
+		return "", fmt.Errorf("%w: bad signature", ErrUnauthorized)
+	}
+	return instance, nil
+}
+
+// sign sets the signature of a.
+func (a *Announcement) sign(secret string) {
+	a.Signature = ""
+	data, _ := json.Marshal(a)
+	a.Signature = signature(secret, string(data))
+}
+
+// verify checks the signature and timestamp of a.
+func (a Announcement) verify(secret string, now time.Time) error {
+	sig := a.Signature
+	a.Signature = ""
+	data, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	if sig == "" || !hmac.Equal([]byte(sig), []byte(signature(secret, string(data)))) {
+		return fmt.Errorf("%w: bad announcement signature", ErrUnauthorized)
+	}
+	return checkTimestamp(a.Timestamp, now)
+}
+
+// eventSignature binds a stream event to the request it answers, so a
+// response cannot be forged or spliced into another stream.
+func eventSignature(secret, requestSig string, ev StreamEvent) string {
+	return signature(secret, requestSig, strconv.Itoa(ev.Seq), ev.Type, ev.Text)
+}
+
+// replayGuard remembers request signatures for twice the clock skew, which
+// is as long as a signed request stays valid.
+type replayGuard struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// check records sig and reports whether it was already used.
+func (g *replayGuard) check(sig string, now time.Time) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.seen == nil {
+		g.seen = make(map[string]time.Time)
+	}
+	for s, at := range g.seen {
+		if now.Sub(at) > 2*MaxClockSkew {
+			delete(g.seen, s)
+		}
+	}
+	if _, ok := g.seen[sig]; ok {
+		return fmt.Errorf("%w: replayed request", ErrUnauthorized)
+	}
+	g.seen[sig] = now
+	return nil
+}
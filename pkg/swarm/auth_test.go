@@ -0,0 +1,91 @@
+package swarm
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifyRequest(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	body := []byte(`{"agent_id":"sensors","task":"read"}`)
+	newReq := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, DelegatePath, strings.NewReader(string(body)))
+		signRequest(req, "s3cret", "desktop", body, now)
+		return req
+	}
+
+	from, err := verifyRequest(newReq(), "s3cret", body, now.Add(time.Minute))
+	if err != nil || from != "desktop" {
+		t.Fatalf("verifyRequest() = %q, %v", from, err)
+	}
+
+	cases := map[string]func() (*http.Request, string, []byte, time.Time){
+		"wrong secret": func() (*http.Request, string, []byte, time.Time) {
+			return newReq(), "other", body, now
+		},
+		"tampered body": func() (*http.Request, string, []byte, time.Time) {
+			return newReq(), "s3cret", []byte(`{"agent_id":"sensors","task":"rm -rf"}`), now
+		},
+		"spoofed instance": func() (*http.Request, string, []byte, time.Time) {
+			req := newReq()
+			req.Header.Set(HeaderInstance, "laptop")
+			return req, "s3cret", body, now
+		},
+		"stale": func() (*http.Request, string, []byte, time.Time) {
+			return newReq(), "s3cret", body, now.Add(MaxClockSkew + time.Second)
+		},
+		"unsigned": func() (*http.Request, string, []byte, time.Time) {
+			return httptest.NewRequest(http.MethodPost, DelegatePath, nil), "s3cret", body, now
+		},
+	}
+	for name, build := range cases {
+		t.Run(name, func(t *testing.T) {
+			req, secret, b, at := build()
+			if _, err := verifyRequest(req, secret, b, at); !errors.Is(err, ErrUnauthorized) {
+				t.Fatalf("verifyRequest() error = %v, want ErrUnauthorized", err)
+			}
+		})
+	}
+}
+
+func TestAnnouncementSignature(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	ann := Announcement{
+		Version:   ProtocolVersion,
+		Instance:  "pi",
+		URL:       "http://192.168.1.20:18790",
+		Agents:    []AgentInfo{{ID: "sensors", HardwareTools: []string{"i2c"}}},
+		Timestamp: now.Unix(),
+	}
+	ann.sign("s3cret")
+
+	if err := ann.verify("s3cret", now); err != nil {
+		t.Fatalf("verify() = %v", err)
+	}
+	if err := ann.verify("other", now); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("verify() with wrong secret = %v", err)
+	}
+	forged := ann
+	forged.URL = "http://10.0.0.66:18790"
+	if err := forged.verify("s3cret", now); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("verify() of altered announcement = %v", err)
+	}
+}
+
+func TestReplayGuard(t *testing.T) {
+	var guard replayGuard
+	now := time.Unix(1_800_000_000, 0)
+	if err := guard.check("abc", now); err != nil {
+		t.Fatalf("first check = %v", err)
+	}
+	if err := guard.check("abc", now.Add(time.Second)); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("replayed check = %v", err)
+	}
+	if err := guard.check("abc", now.Add(2*MaxClockSkew+time.Second)); err != nil {
+		t.Fatalf("check after the window = %v", err)
+	}
+}
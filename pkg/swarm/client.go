@@ -0,0 +1,125 @@
+package swarm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxStreamLine bounds one line of a delegation stream, which holds the
+// whole answer for result events.
+const maxStreamLine = 4 << 20
+
+// Client sends signed requests to other instances.
+type Client struct {
+	instance string
+	secret   string
+	http     *http.Client
+	now      func() time.Time
+}
+
+// NewClient returns a client that signs requests as instance. Requests are
+// bounded by their context only, since delegations stream for as long as
+// the remote turn runs.
+func NewClient(instance, secret string) *Client {
+	return &Client{instance: instance, secret: secret, http: &http.Client{}, now: time.Now}
+}
+
+// Info fetches and verifies the announcement of the instance at baseURL.
+func (c *Client) Info(ctx context.Context, baseURL string) (*Announcement, error) {
+	resp, err := c.do(ctx, http.MethodGet, baseURL, InfoPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var ann Announcement
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxStreamLine)).Decode(&ann); err != nil {
+		return nil, fmt.Errorf("decode swarm info: %w", err)
+	}
+	if err := ann.verify(c.secret, c.now()); err != nil {
+		return nil, err
+	}
+	return &ann, nil
+}
+
+// Delegate runs req on the instance at baseURL and waits for the answer.
+// onEvent, when set, receives every verified stream event as it arrives.
+func (c *Client) Delegate(
+	ctx context.Context,
+	baseURL string,
+	req DelegateRequest,
+	onEvent func(StreamEvent),
+) (string, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.do(ctx, http.MethodPost, baseURL, DelegatePath, body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	requestSig := resp.Request.Header.Get(HeaderSignature)
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLine)
+	seq := 0
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var ev StreamEvent
+		if err := json.Unmarshal(line, &ev); err != nil {
+			return "", fmt.Errorf("decode swarm stream: %w", err)
+		}
+		seq++
+		if ev.Seq != seq || !hmac.Equal([]byte(ev.Signature), []byte(eventSignature(c.secret, requestSig, ev))) {
+			return "", fmt.Errorf("%w: bad stream event %d", ErrUnauthorized, ev.Seq)
+		}
+		if onEvent != nil {
+			onEvent(ev)
+		}
+		switch ev.Type {
+		case EventResult:
+			return ev.Text, nil
+		case EventError:
+			return "", errors.New(ev.Text)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("read swarm stream: %w", err)
+	}
+	return "", errors.New("swarm stream ended without a result")
+}
+
+func (c *Client) do(ctx context.Context, method, baseURL, path string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(baseURL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	signRequest(req, c.secret, c.instance, body, c.now())
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
@@ -0,0 +1,169 @@
+package swarm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// maxAnnouncementBytes bounds one announcement datagram.
+const maxAnnouncementBytes = 64 * 1024
+
+// peerTTLIntervals is how many announce intervals a peer may stay silent
+// before it is dropped.
+const peerTTLIntervals = 3
+
+// Start listens for announcements on the discovery port and announces this
+// instance until Stop. Static peers are polled on the same schedule.
+func (n *Node) Start(ctx context.Context) error {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{Port: n.cfg.EffectiveDiscoveryPort()})
+	if err != nil {
+		return fmt.Errorf("listen for swarm announcements: %w", err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	n.conn = conn
+	n.cancel = cancel
+
+	n.wg.Add(2)
+	go func() {
+		defer n.wg.Done()
+		n.readLoop()
+	}()
+	go func() {
+		defer n.wg.Done()
+		n.announceLoop(ctx)
+	}()
+
+	logger.InfoCF("swarm", "Swarm discovery started", map[string]any{
+		"instance": n.instance,
+		"url":      n.url,
+		"port":     n.cfg.EffectiveDiscoveryPort(),
+	})
+	return nil
+}
+
+// Stop ends discovery and waits for its goroutines.
+func (n *Node) Stop() {
+	if n.cancel == nil {
+		return
+	}
+	n.cancel()
+	_ = n.conn.Close()
+	n.wg.Wait()
+	n.cancel = nil
+}
+
+func (n *Node) readLoop() {
+	buf := make([]byte, maxAnnouncementBytes)
+	for {
+		size, src, err := n.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		var ann Announcement
+		if err := json.Unmarshal(buf[:size], &ann); err != nil {
+			continue
+		}
+		if err := n.accept(ann); err != nil {
+			logger.DebugCF("swarm", "Ignored announcement", map[string]any{
+				"from":  src.String(),
+				"error": err.Error(),
+			})
+		}
+	}
+}
+
+func (n *Node) announceLoop(ctx context.Context) {
+	ticker := time.NewTicker(n.interval)
+	defer ticker.Stop()
+	for {
+		n.broadcast()
+		n.pollStaticPeers(ctx)
+		n.prune()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (n *Node) broadcast() {
+	data, err := json.Marshal(n.announcement())
+	if err != nil {
+		return
+	}
+	dst := &net.UDPAddr{IP: net.IPv4bcast, Port: n.cfg.EffectiveDiscoveryPort()}
+	if _, err := n.conn.WriteToUDP(data, dst); err != nil {
+		logger.DebugCF("swarm", "Announcement broadcast failed", map[string]any{"error": err.Error()})
+	}
+}
+
+func (n *Node) pollStaticPeers(ctx context.Context) {
+	for _, url := range n.cfg.Peers {
+		pollCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		ann, err := n.client.Info(pollCtx, url)
+		cancel()
+		if err != nil {
+			logger.DebugCF("swarm", "Static peer poll failed", map[string]any{"url": url, "error": err.Error()})
+			continue
+		}
+		if err := n.accept(*ann); err != nil {
+			logger.DebugCF("swarm", "Ignored static peer", map[string]any{"url": url, "error": err.Error()})
+		}
+	}
+}
+
+// accept verifies ann and records its instance as a peer.
+func (n *Node) accept(ann Announcement) error {
+	if ann.Version != ProtocolVersion {
+		return fmt.Errorf("protocol version %d", ann.Version)
+	}
+	if ann.Instance == n.instance {
+		return nil
+	}
+	if ann.Instance == "" || ann.URL == "" {
+		return fmt.Errorf("announcement without instance or url")
+	}
+	now := n.now()
+	if err := ann.verify(n.secret, now); err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	prev, known := n.peers[ann.Instance]
+	n.peers[ann.Instance] = Peer{Announcement: ann, LastSeen: now}
+	n.mu.Unlock()
+
+	if !known || prev.URL != ann.URL || !sameAgents(prev.Agents, ann.Agents) {
+		logger.InfoCF("swarm", "Swarm peer up", map[string]any{
+			"instance": ann.Instance,
+			"url":      ann.URL,
+			"agents":   len(ann.Agents),
+		})
+		n.publishPeer(true, ann)
+	}
+	return nil
+}
+
+// prune drops peers that have not announced themselves for peerTTLIntervals.
+func (n *Node) prune() {
+	cutoff := n.now().Add(-peerTTLIntervals * n.interval)
+	var lost []Announcement
+	n.mu.Lock()
+	for id, peer := range n.peers {
+		if peer.LastSeen.Before(cutoff) {
+			delete(n.peers, id)
+			lost = append(lost, peer.Announcement)
+		}
+	}
+	n.mu.Unlock()
+	for _, ann := range lost {
+		logger.InfoCF("swarm", "Swarm peer down", map[string]any{"instance": ann.Instance})
+		n.publishPeer(false, ann)
+	}
+}
@@ -0,0 +1,157 @@
+package swarm
+
+import (
+	"context"
+	"time"
+
+	runtimeevents "github.com/sipeed/picoclaw/pkg/events"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// Directions of a delegation, seen from the instance publishing the event.
+const (
+	directionOutbound = "outbound"
+	directionInbound  = "inbound"
+)
+
+// PeerPayload describes a peer that came up, changed or went away.
+type PeerPayload struct {
+	Instance string   `json:"instance"`
+	URL      string   `json:"url"`
+	Agents   []string `json:"agents"`
+}
+
+// DelegatePayload describes a delegation that starts. Instance is the peer:
+// the target for outbound delegations and the caller for inbound ones.
+type DelegatePayload struct {
+	RequestID string `json:"request_id"`
+	Direction string `json:"direction"`
+	Instance  string `json:"instance"`
+	AgentID   string `json:"agent_id"`
+	TaskLen   int    `json:"task_len"`
+}
+
+// ProgressPayload carries a progress note a peer streamed back.
+type ProgressPayload struct {
+	RequestID string `json:"request_id"`
+	Instance  string `json:"instance"`
+	AgentID   string `json:"agent_id"`
+	Message   string `json:"message"`
+}
+
+// DelegateEndPayload describes how a delegation ended.
+type DelegateEndPayload struct {
+	RequestID  string `json:"request_id"`
+	Direction  string `json:"direction"`
+	Instance   string `json:"instance"`
+	AgentID    string `json:"agent_id"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	ResultLen  int    `json:"result_len"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+func (n *Node) publishPeer(up bool, ann Announcement) {
+	agents := make([]string, 0, len(ann.Agents))
+	for _, agent := range ann.Agents {
+		agents = append(agents, agent.ID)
+	}
+	kind, severity := runtimeevents.KindSwarmPeerUp, runtimeevents.SeverityInfo
+	if !up {
+		kind, severity = runtimeevents.KindSwarmPeerDown, runtimeevents.SeverityWarn
+	}
+	n.publish(kind, runtimeevents.Scope{}, "", severity, PeerPayload{
+		Instance: ann.Instance,
+		URL:      ann.URL,
+		Agents:   agents,
+	}, map[string]any{"instance": ann.Instance, "agents": len(agents)})
+}
+
+func (n *Node) publishStart(ctx context.Context, direction, instance string, req DelegateRequest) {
+	n.publish(runtimeevents.KindSwarmDelegateStart, delegateScope(ctx, direction), req.RequestID,
+		runtimeevents.SeverityInfo, DelegatePayload{
+			RequestID: req.RequestID,
+			Direction: direction,
+			Instance:  instance,
+			AgentID:   req.AgentID,
+			TaskLen:   len(req.Task),
+		}, map[string]any{"direction": direction, "instance": instance, "agent": req.AgentID})
+}
+
+func (n *Node) publishProgress(ctx context.Context, instance string, req DelegateRequest, message string) {
+	n.publish(runtimeevents.KindSwarmDelegateProgress, delegateScope(ctx, directionOutbound), req.RequestID,
+		runtimeevents.SeverityDebug, ProgressPayload{
+			RequestID: req.RequestID,
+			Instance:  instance,
+			AgentID:   req.AgentID,
+			Message:   message,
+		}, map[string]any{"instance": instance, "agent": req.AgentID})
+}
+
+func (n *Node) publishEnd(
+	ctx context.Context,
+	direction, instance string,
+	req DelegateRequest,
+	resultLen int,
+	duration time.Duration,
+	err error,
+) {
+	payload := DelegateEndPayload{
+		RequestID:  req.RequestID,
+		Direction:  direction,
+		Instance:   instance,
+		AgentID:    req.AgentID,
+		Status:     "ok",
+		ResultLen:  resultLen,
+		DurationMS: duration.Milliseconds(),
+	}
+	severity := runtimeevents.SeverityInfo
+	if err != nil {
+		payload.Status, payload.Error = "error", err.Error()
+		severity = runtimeevents.SeverityWarn
+	}
+	n.publish(runtimeevents.KindSwarmDelegateEnd, delegateScope(ctx, direction), req.RequestID, severity, payload,
+		map[string]any{
+			"direction":   direction,
+			"instance":    instance,
+			"agent":       req.AgentID,
+			"status":      payload.Status,
+			"duration_ms": payload.DurationMS,
+		})
+}
+
+// delegateScope is the calling turn for outbound delegations; inbound ones
+// run in a turn of their own, which publishes its own events.
+func delegateScope(ctx context.Context, direction string) runtimeevents.Scope {
+	if direction != directionOutbound {
+		return runtimeevents.Scope{}
+	}
+	return runtimeevents.Scope{
+		AgentID:    tools.ToolAgentID(ctx),
+		SessionKey: tools.ToolSessionKey(ctx),
+		Channel:    tools.ToolChannel(ctx),
+		ChatID:     tools.ToolChatID(ctx),
+	}
+}
+
+func (n *Node) publish(
+	kind runtimeevents.Kind,
+	scope runtimeevents.Scope,
+	requestID string,
+	severity runtimeevents.Severity,
+	payload any,
+	attrs map[string]any,
+) {
+	if n == nil || n.events == nil {
+		return
+	}
+	n.events.PublishNonBlocking(runtimeevents.Event{
+		Kind:        kind,
+		Source:      runtimeevents.Source{Component: "swarm", Name: n.instance},
+		Scope:       scope,
+		Correlation: runtimeevents.Correlation{RequestID: requestID},
+		Severity:    severity,
+		Payload:     payload,
+		Attrs:       attrs,
+	})
+}
@@ -0,0 +1,213 @@
+package swarm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	runtimeevents "github.com/sipeed/picoclaw/pkg/events"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/routing"
+)
+
+// Node is this instance's membership in the swarm. It announces the local
+// agents, keeps the table of peers, serves delegations from peers and sends
+// delegations to them.
+type Node struct {
+	cfg      config.SwarmConfig
+	instance string
+	secret   string
+	url      string
+	timeout  time.Duration
+	interval time.Duration
+	local    Local
+	client   *Client
+	events   runtimeevents.Bus
+	now      func() time.Time
+	seq      atomic.Uint64
+	replay   replayGuard
+
+	mu    sync.RWMutex
+	peers map[string]Peer
+
+	conn   *net.UDPConn
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Option configures a Node.
+type Option func(*Node)
+
+// WithEvents publishes peer and delegation events to bus.
+func WithEvents(bus runtimeevents.Bus) Option {
+	return func(n *Node) {
+		n.events = bus
+	}
+}
+
+// NewNode returns a node for cfg. advertiseURL is used when cfg does not set
+// one; it is the gateway base URL peers send requests to.
+func NewNode(cfg config.SwarmConfig, advertiseURL string, local Local, opts ...Option) (*Node, error) {
+	secret := cfg.Secret.String()
+	if strings.TrimSpace(secret) == "" {
+		return nil, errors.New("swarm.secret is required")
+	}
+	if local == nil {
+		return nil, errors.New("swarm node needs a local instance")
+	}
+	if url := strings.TrimSpace(cfg.AdvertiseURL); url != "" {
+		advertiseURL = url
+	}
+	advertiseURL = strings.TrimRight(strings.TrimSpace(advertiseURL), "/")
+	if advertiseURL == "" {
+		return nil, errors.New("swarm advertise URL is unknown; set swarm.advertise_url")
+	}
+
+	n := &Node{
+		cfg:      cfg,
+		instance: cfg.EffectiveInstanceID(),
+		secret:   secret,
+		url:      advertiseURL,
+		timeout:  time.Duration(cfg.EffectiveTimeoutSeconds()) * time.Second,
+		interval: time.Duration(cfg.EffectiveAnnounceIntervalSeconds()) * time.Second,
+		local:    local,
+		now:      time.Now,
+		peers:    make(map[string]Peer),
+	}
+	n.client = NewClient(n.instance, secret)
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n, nil
+}
+
+// LocalInstance returns the id of this instance.
+func (n *Node) LocalInstance() string {
+	return n.instance
+}
+
+// URL returns the base URL this instance advertises.
+func (n *Node) URL() string {
+	return n.url
+}
+
+// Peers returns the known peers sorted by instance id.
+func (n *Node) Peers() []Peer {
+	n.mu.RLock()
+	out := make([]Peer, 0, len(n.peers))
+	for _, peer := range n.peers {
+		out = append(out, peer)
+	}
+	n.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Instance < out[j].Instance })
+	return out
+}
+
+// RemoteAgents describes every agent of every known peer.
+func (n *Node) RemoteAgents() []string {
+	var out []string
+	for _, peer := range n.Peers() {
+		for _, agent := range peer.Agents {
+			out = append(out, agent.describe(peer.Instance))
+		}
+	}
+	return out
+}
+
+// DelegateRemote runs task on agentID of the peer instance and returns its
+// answer. Progress reported by the peer is published as runtime events. When
+// ctx belongs to a delegated turn, the request carries the chain it came
+// through and is refused if it would revisit an instance or exceed MaxHops.
+func (n *Node) DelegateRemote(ctx context.Context, instance, agentID, task string) (string, error) {
+	chain := append(slices.Clone(chainFrom(ctx)), n.instance)
+	if err := checkChain(chain, instance); err != nil {
+		return "", err
+	}
+	n.mu.RLock()
+	peer, ok := n.peers[instance]
+	n.mu.RUnlock()
+	if !ok {
+		known := make([]string, 0)
+		for _, p := range n.Peers() {
+			known = append(known, p.Instance)
+		}
+		return "", fmt.Errorf("unknown swarm instance %q (known: %s)", instance, strings.Join(known, ", "))
+	}
+	agentID = routing.NormalizeAgentID(agentID)
+	if _, ok := peer.Agent(agentID); !ok {
+		return "", fmt.Errorf("instance %q does not expose agent %q", instance, agentID)
+	}
+
+	req := DelegateRequest{
+		RequestID: n.instance + "-" + strconv.FormatInt(n.now().UnixNano(), 36) + "-" +
+			strconv.FormatUint(n.seq.Add(1), 10),
+		AgentID: agentID,
+		Task:    task,
+		Chain:   chain,
+	}
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+
+	n.publishStart(ctx, directionOutbound, instance, req)
+	start := time.Now()
+	answer, err := n.client.Delegate(ctx, peer.URL, req, func(ev StreamEvent) {
+		if ev.Type == EventProgress {
+			n.publishProgress(ctx, instance, req, ev.Text)
+		}
+	})
+	n.publishEnd(ctx, directionOutbound, instance, req, len(answer), time.Since(start), err)
+	return answer, err
+}
+
+// announcement returns the signed announcement of this instance.
+func (n *Node) announcement() Announcement {
+	ann := Announcement{
+		Version:   ProtocolVersion,
+		Instance:  n.instance,
+		URL:       n.url,
+		Agents:    n.exposedAgents(),
+		Timestamp: n.now().Unix(),
+	}
+	ann.sign(n.secret)
+	return ann
+}
+
+// DefaultAdvertiseURL returns http://<first LAN IPv4 address>:port, or ""
+// when the host has no such address.
+func DefaultAdvertiseURL(port int) string {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return ""
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok {
+				continue
+			}
+			ip := ipNet.IP.To4()
+			if ip == nil || ip.IsLoopback() || ip.IsLinkLocalUnicast() {
+				continue
+			}
+			return "http://" + net.JoinHostPort(ip.String(), strconv.Itoa(port))
+		}
+	}
+	logger.WarnCF("swarm", "No LAN address found to advertise", nil)
+	return ""
+}
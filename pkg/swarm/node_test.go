@@ -0,0 +1,254 @@
+package swarm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	runtimeevents "github.com/sipeed/picoclaw/pkg/events"
+)
+
+type fakeLocal struct {
+	agents []AgentInfo
+
+	mu    sync.Mutex
+	from  string
+	req   DelegateRequest
+	chain []string
+}
+
+func (l *fakeLocal) Agents() []AgentInfo {
+	return l.agents
+}
+
+func (l *fakeLocal) RunDelegation(
+	ctx context.Context,
+	from string,
+	req DelegateRequest,
+	progress func(string),
+) (string, error) {
+	l.mu.Lock()
+	l.from, l.req, l.chain = from, req, chainFrom(ctx)
+	l.mu.Unlock()
+	if req.Task == "fail" {
+		return "", errors.New("sensor offline")
+	}
+	progress("running tool i2c")
+	return "21.5C", nil
+}
+
+func newTestNode(t *testing.T, instance, secret, url string, local Local, opts ...Option) *Node {
+	t.Helper()
+	cfg := config.SwarmConfig{Enabled: true, InstanceID: instance}
+	cfg.Secret.Set(secret)
+	node, err := NewNode(cfg, url, local, opts...)
+	if err != nil {
+		t.Fatalf("NewNode(%s) error: %v", instance, err)
+	}
+	return node
+}
+
+// newPair starts the pi instance behind an HTTP server and a desktop
+// instance that knows pi through a static peer poll.
+func newPair(t *testing.T, piSecret string, opts ...Option) (*Node, *Node, *fakeLocal) {
+	t.Helper()
+	local := &fakeLocal{agents: []AgentInfo{
+		{ID: "sensors", Model: "qwen", HardwareTools: []string{"gpio", "i2c"}},
+		{ID: "private"},
+	}}
+	var pi *Node
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pi.Handler().ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	pi = newTestNode(t, "pi", piSecret, server.URL, local)
+	pi.cfg.ExposeAgents = []string{"Sensors"}
+
+	desktop := newTestNode(t, "desktop", "s3cret", "http://desktop:18790", &fakeLocal{}, opts...)
+	desktop.cfg.Peers = []string{server.URL}
+	desktop.pollStaticPeers(context.Background())
+	return desktop, pi, local
+}
+
+func TestNode_DelegateRemoteStreamsResult(t *testing.T) {
+	bus := runtimeevents.NewBus()
+	defer bus.Close()
+	_, events, err := bus.Channel().KindPrefix("swarm.").
+		SubscribeChan(context.Background(), runtimeevents.SubscribeOptions{Name: "test", Buffer: 16})
+	if err != nil {
+		t.Fatalf("SubscribeChan() error: %v", err)
+	}
+
+	desktop, _, local := newPair(t, "s3cret", WithEvents(bus))
+
+	peers := desktop.Peers()
+	if len(peers) != 1 || peers[0].Instance != "pi" || len(peers[0].Agents) != 1 {
+		t.Fatalf("Peers() = %+v, want pi exposing only sensors", peers)
+	}
+	if got := desktop.RemoteAgents(); len(got) != 1 || got[0] != "sensors@pi (model: qwen; hardware: gpio, i2c)" {
+		t.Fatalf("RemoteAgents() = %v", got)
+	}
+
+	answer, err := desktop.DelegateRemote(context.Background(), "pi", "sensors", "read the temperature")
+	if err != nil {
+		t.Fatalf("DelegateRemote() error: %v", err)
+	}
+	if answer != "21.5C" {
+		t.Fatalf("answer = %q", answer)
+	}
+	local.mu.Lock()
+	if local.from != "desktop" || local.req.AgentID != "sensors" || local.req.Task != "read the temperature" {
+		t.Fatalf("remote saw from=%q req=%+v", local.from, local.req)
+	}
+	local.mu.Unlock()
+
+	kinds := map[runtimeevents.Kind]runtimeevents.Event{}
+	deadline := time.After(2 * time.Second)
+	for len(kinds) < 4 {
+		select {
+		case evt := <-events:
+			kinds[evt.Kind] = evt
+		case <-deadline:
+			t.Fatalf("missing swarm events, got %v", kinds)
+		}
+	}
+	progress, ok := kinds[runtimeevents.KindSwarmDelegateProgress].Payload.(ProgressPayload)
+	if !ok || progress.Message != "running tool i2c" {
+		t.Fatalf("progress payload = %+v", kinds[runtimeevents.KindSwarmDelegateProgress].Payload)
+	}
+	end, ok := kinds[runtimeevents.KindSwarmDelegateEnd].Payload.(DelegateEndPayload)
+	if !ok || end.Status != "ok" || end.Direction != directionOutbound || end.ResultLen != len("21.5C") {
+		t.Fatalf("end payload = %+v", kinds[runtimeevents.KindSwarmDelegateEnd].Payload)
+	}
+}
+
+func TestNode_DelegateRemoteErrors(t *testing.T) {
+	desktop, _, _ := newPair(t, "s3cret")
+
+	if _, err := desktop.DelegateRemote(context.Background(), "pi", "sensors", "fail"); err == nil ||
+		err.Error() != "sensor offline" {
+		t.Fatalf("remote failure error = %v", err)
+	}
+	if _, err := desktop.DelegateRemote(context.Background(), "pi", "private", "x"); err == nil ||
+		!strings.Contains(err.Error(), "does not expose") {
+		t.Fatalf("unexposed agent error = %v", err)
+	}
+	if _, err := desktop.DelegateRemote(context.Background(), "laptop", "main", "x"); err == nil ||
+		!strings.Contains(err.Error(), "unknown swarm instance") {
+		t.Fatalf("unknown instance error = %v", err)
+	}
+}
+
+func TestNode_DelegationChainIsBounded(t *testing.T) {
+	desktop, pi, local := newPair(t, "s3cret")
+
+	if _, err := desktop.DelegateRemote(context.Background(), "pi", "sensors", "read"); err != nil {
+		t.Fatalf("DelegateRemote() error: %v", err)
+	}
+	local.mu.Lock()
+	if len(local.chain) != 1 || local.chain[0] != "desktop" {
+		t.Fatalf("delegated turn chain = %v, want [desktop]", local.chain)
+	}
+	local.mu.Unlock()
+
+	// A turn delegated by pi must not hand the task back to pi.
+	ctx := withChain(context.Background(), []string{"pi"})
+	if _, err := desktop.DelegateRemote(ctx, "pi", "sensors", "read"); err == nil ||
+		!strings.Contains(err.Error(), "delegation loop") {
+		t.Fatalf("re-entry error = %v, want delegation loop", err)
+	}
+
+	// The receiver enforces the same rules on requests it did not build.
+	for _, chain := range [][]string{
+		{"pi", "desktop"},
+		{"a", "b", "c", "desktop"},
+	} {
+		req := DelegateRequest{RequestID: strings.Join(chain, "-"), AgentID: "sensors", Task: "read", Chain: chain}
+		if _, err := desktop.client.Delegate(context.Background(), pi.url, req, nil); err == nil ||
+			!strings.Contains(err.Error(), "508") {
+			t.Fatalf("Delegate(chain %v) error = %v, want 508 Loop Detected", chain, err)
+		}
+	}
+}
+
+func TestNode_RejectsPeersWithAnotherSecret(t *testing.T) {
+	desktop, pi, _ := newPair(t, "not-the-same")
+	if peers := desktop.Peers(); len(peers) != 0 {
+		t.Fatalf("Peers() = %+v, want none", peers)
+	}
+
+	// Even with a peer entry, the other instance refuses the request.
+	desktop.peers["pi"] = Peer{Announcement: Announcement{
+		Instance: "pi",
+		URL:      pi.URL(),
+		Agents:   []AgentInfo{{ID: "sensors"}},
+	}}
+	_, err := desktop.DelegateRemote(context.Background(), "pi", "sensors", "x")
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("DelegateRemote() error = %v, want 401", err)
+	}
+}
+
+func TestNode_ReplayedDelegationIsRejected(t *testing.T) {
+	local := &fakeLocal{agents: []AgentInfo{{ID: "sensors"}}}
+	pi := newTestNode(t, "pi", "s3cret", "http://pi:18790", local)
+	body, _ := json.Marshal(DelegateRequest{RequestID: "r1", AgentID: "sensors", Task: "read"})
+
+	req := httptest.NewRequest(http.MethodPost, DelegatePath, strings.NewReader(string(body)))
+	signRequest(req, "s3cret", "desktop", body, time.Now())
+	first := httptest.NewRecorder()
+	pi.Handler().ServeHTTP(first, req)
+	if first.Code != http.StatusOK || !strings.Contains(first.Body.String(), `"type":"result"`) {
+		t.Fatalf("first delegation = %d %s", first.Code, first.Body.String())
+	}
+
+	replay := httptest.NewRequest(http.MethodPost, DelegatePath, strings.NewReader(string(body)))
+	replay.Header = req.Header.Clone()
+	second := httptest.NewRecorder()
+	pi.Handler().ServeHTTP(second, replay)
+	if second.Code != http.StatusUnauthorized {
+		t.Fatalf("replayed delegation = %d, want 401", second.Code)
+	}
+}
+
+func TestNode_AcceptAndPrunePeers(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	node := newTestNode(t, "desktop", "s3cret", "http://desktop:18790", &fakeLocal{})
+	node.now = func() time.Time { return now }
+
+	ann := Announcement{
+		Version:   ProtocolVersion,
+		Instance:  "pi",
+		URL:       "http://pi:18790",
+		Agents:    []AgentInfo{{ID: "sensors"}},
+		Timestamp: now.Unix(),
+	}
+	ann.sign("s3cret")
+	if err := node.accept(ann); err != nil {
+		t.Fatalf("accept() = %v", err)
+	}
+	own := node.announcement()
+	if err := node.accept(own); err != nil || len(node.Peers()) != 1 {
+		t.Fatalf("own announcement should be skipped, err=%v peers=%+v", err, node.Peers())
+	}
+
+	now = now.Add(peerTTLIntervals*node.interval + time.Second)
+	node.prune()
+	if peers := node.Peers(); len(peers) != 0 {
+		t.Fatalf("Peers() after TTL = %+v, want none", peers)
+	}
+}
+
+func TestNewNode_RequiresSecret(t *testing.T) {
+	_, err := NewNode(config.SwarmConfig{Enabled: true}, "http://pi:18790", &fakeLocal{})
+	if err == nil || !strings.Contains(err.Error(), "secret") {
+		t.Fatalf("NewNode() error = %v, want missing secret", err)
+	}
+}
@@ -0,0 +1,255 @@
+package swarm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/routing"
+)
+
+// maxRequestBytes bounds the body of a delegation request.
+const maxRequestBytes = 1 << 20
+
+// Event types of a delegation response stream. A stream starts with
+// accepted, carries any number of progress events and ends with exactly one
+// result or error.
+const (
+	EventAccepted = "accepted"
+	EventProgress = "progress"
+	EventResult   = "result"
+	EventError    = "error"
+)
+
+// MaxHops bounds how many instances a task may pass through before the one
+// that runs it, so agents delegating to each other cannot loop.
+const MaxHops = 3
+
+// DelegateRequest is the body of a delegation request.
+type DelegateRequest struct {
+	RequestID string `json:"request_id"`
+	AgentID   string `json:"agent_id"`
+	Task      string `json:"task"`
+	// Chain lists the instances the task passed through, the originating
+	// instance first and the sender last.
+	Chain []string `json:"chain,omitempty"`
+}
+
+type chainKey struct{}
+
+// withChain records in ctx the instances a delegated turn's task passed
+// through, so delegations the turn makes extend the chain.
+func withChain(ctx context.Context, chain []string) context.Context {
+	return context.WithValue(ctx, chainKey{}, chain)
+}
+
+func chainFrom(ctx context.Context) []string {
+	chain, _ := ctx.Value(chainKey{}).([]string)
+	return chain
+}
+
+// checkChain refuses a chain that already contains instance or that is
+// longer than MaxHops.
+func checkChain(chain []string, instance string) error {
+	if slices.Contains(chain, instance) {
+		return fmt.Errorf("delegation loop: %s already handled this task (%s)", instance, strings.Join(chain, " -> "))
+	}
+	if len(chain) > MaxHops {
+		return fmt.Errorf("delegation chain %s is longer than %d hops", strings.Join(chain, " -> "), MaxHops)
+	}
+	return nil
+}
+
+// StreamEvent is one line of a delegation response stream. Seq counts from
+// 1 and Signature ties the event to the request it answers.
+type StreamEvent struct {
+	Seq       int    `json:"seq"`
+	Type      string `json:"type"`
+	Text      string `json:"text,omitempty"`
+	Signature string `json:"sig"`
+}
+
+// Local is this instance as the swarm sees it: the agents it has and how it
+// runs a task delegated by a peer. progress may be called from any
+// goroutine until RunDelegation returns.
+type Local interface {
+	Agents() []AgentInfo
+	RunDelegation(ctx context.Context, from string, req DelegateRequest, progress func(string)) (string, error)
+}
+
+// Handler serves the swarm endpoints. Mount it under PathPrefix.
+func (n *Node) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(InfoPath, n.handleInfo)
+	mux.HandleFunc(DelegatePath, n.handleDelegate)
+	return mux
+}
+
+func (n *Node) handleInfo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if _, err := verifyRequest(r, n.secret, nil, n.now()); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(n.announcement())
+}
+
+func (n *Node) handleDelegate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBytes+1))
+	if err != nil {
+		http.Error(w, "read request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxRequestBytes {
+		http.Error(w, "request too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	now := n.now()
+	from, err := verifyRequest(r, n.secret, body, now)
+	if err == nil {
+		err = n.replay.check(r.Header.Get(HeaderSignature), now)
+	}
+	if err != nil {
+		logger.WarnCF("swarm", "Rejected delegation request", map[string]any{
+			"remote": r.RemoteAddr,
+			"error":  err.Error(),
+		})
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var req DelegateRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, "decode request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.AgentID = routing.NormalizeAgentID(req.AgentID)
+	if len(req.Chain) == 0 || req.Chain[len(req.Chain)-1] != from {
+		req.Chain = append(req.Chain, from)
+	}
+	if err := checkChain(req.Chain, n.instance); err != nil {
+		http.Error(w, err.Error(), http.StatusLoopDetected)
+		return
+	}
+	if strings.TrimSpace(req.Task) == "" {
+		http.Error(w, "task is required", http.StatusBadRequest)
+		return
+	}
+	if !n.exposes(req.AgentID) {
+		http.Error(w, fmt.Sprintf("agent %q is not exposed by instance %q", req.AgentID, n.instance),
+			http.StatusNotFound)
+		return
+	}
+
+	// The gateway server's write timeout is shorter than most turns.
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	stream := &eventWriter{w: w, secret: n.secret, requestSig: r.Header.Get(HeaderSignature)}
+	stream.write(EventAccepted, "")
+
+	ctx, cancel := context.WithTimeout(withChain(r.Context(), req.Chain), n.timeout)
+	defer cancel()
+	n.publishStart(ctx, directionInbound, from, req)
+	start := time.Now()
+
+	type outcome struct {
+		answer string
+		err    error
+	}
+	progress := make(chan string, 32)
+	done := make(chan outcome, 1)
+	go func() {
+		answer, err := n.local.RunDelegation(ctx, from, req, func(msg string) {
+			select {
+			case progress <- msg:
+			default:
+			}
+		})
+		done <- outcome{answer: answer, err: err}
+	}()
+
+	for {
+		select {
+		case msg := <-progress:
+			stream.write(EventProgress, msg)
+		case out := <-done:
+			if out.err != nil {
+				stream.write(EventError, out.err.Error())
+			} else {
+				stream.write(EventResult, out.answer)
+			}
+			n.publishEnd(ctx, directionInbound, from, req, len(out.answer), time.Since(start), out.err)
+			return
+		}
+	}
+}
+
+// exposes reports whether peers may delegate to the local agent id.
+func (n *Node) exposes(id string) bool {
+	for _, agent := range n.exposedAgents() {
+		if agent.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// exposedAgents returns the local agents limited to the expose list.
+func (n *Node) exposedAgents() []AgentInfo {
+	agents := n.local.Agents()
+	if len(n.cfg.ExposeAgents) == 0 {
+		return agents
+	}
+	allowed := make(map[string]bool, len(n.cfg.ExposeAgents))
+	for _, id := range n.cfg.ExposeAgents {
+		allowed[routing.NormalizeAgentID(id)] = true
+	}
+	out := agents[:0:0]
+	for _, agent := range agents {
+		if allowed[agent.ID] {
+			out = append(out, agent)
+		}
+	}
+	return out
+}
+
+// eventWriter writes signed stream events and flushes each one.
+type eventWriter struct {
+	w          http.ResponseWriter
+	secret     string
+	requestSig string
+	seq        int
+	failed     bool
+}
+
+func (s *eventWriter) write(typ, text string) {
+	if s.failed {
+		return
+	}
+	s.seq++
+	ev := StreamEvent{Seq: s.seq, Type: typ, Text: text}
+	ev.Signature = eventSignature(s.secret, s.requestSig, ev)
+	if err := json.NewEncoder(s.w).Encode(ev); err != nil {
+		s.failed = true
+		return
+	}
+	if err := http.NewResponseController(s.w).Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		s.failed = true
+	}
+}
@@ -0,0 +1,87 @@
+// Package swarm lets picoclaw gateways on one network find each other and
+// run tasks for each other's agents. Instances announce their agents over
+// UDP broadcast and serve delegated tasks on the gateway HTTP listener,
+// streaming progress back as newline-delimited JSON. Announcements, requests
+// and response streams are signed with a secret the instances share.
+package swarm
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ProtocolVersion is the version of announcements and requests this package
+// speaks. Announcements of other versions are ignored.
+const ProtocolVersion = 1
+
+// HTTP endpoints served on the gateway listener.
+const (
+	PathPrefix   = "/swarm/v1/"
+	InfoPath     = PathPrefix + "info"
+	DelegatePath = PathPrefix + "delegate"
+)
+
+// AgentInfo describes an agent an instance exposes to the swarm.
+type AgentInfo struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name,omitempty"`
+	Model         string   `json:"model,omitempty"`
+	HardwareTools []string `json:"hardware_tools,omitempty"`
+}
+
+// Announcement is what an instance tells the swarm about itself.
+type Announcement struct {
+	Version   int         `json:"version"`
+	Instance  string      `json:"instance"`
+	URL       string      `json:"url"`
+	Agents    []AgentInfo `json:"agents"`
+	Timestamp int64       `json:"ts"`
+	Signature string      `json:"sig,omitempty"`
+}
+
+// Peer is another instance of the swarm and the last announcement it made.
+type Peer struct {
+	Announcement
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// Agent returns the peer's agent with id.
+func (p Peer) Agent(id string) (AgentInfo, bool) {
+	for _, agent := range p.Agents {
+		if agent.ID == id {
+			return agent, true
+		}
+	}
+	return AgentInfo{}, false
+}
+
+// describe renders the agent as an agent@instance target with its
+// capabilities, for tool descriptions.
+func (a AgentInfo) describe(instance string) string {
+	var caps []string
+	if a.Model != "" {
+		caps = append(caps, "model: "+a.Model)
+	}
+	if len(a.HardwareTools) > 0 {
+		caps = append(caps, "hardware: "+strings.Join(a.HardwareTools, ", "))
+	}
+	target := fmt.Sprintf("%s@%s", a.ID, instance)
+	if len(caps) == 0 {
+		return target
+	}
+	return fmt.Sprintf("%s (%s)", target, strings.Join(caps, "; "))
+}
+
+func sameAgents(a, b []AgentInfo) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].ID != b[i].ID || a[i].Name != b[i].Name || a[i].Model != b[i].Model ||
+			strings.Join(a[i].HardwareTools, ",") != strings.Join(b[i].HardwareTools, ",") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,144 @@
+// Package team lets a named group of agents work on one task together. The
+// agents share a blackboard scoped to the task: a key/value store plus an
+// append-only log of the messages they post to each other. A Coordinator
+// runs members in rounds until a termination condition is met.
+package team
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Broadcast addresses a message to every member of the team.
+const Broadcast = "*"
+
+// Entry is a blackboard value and the member that wrote it last.
+type Entry struct {
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+	Author    string    `json:"author"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Message is one entry of the blackboard log.
+type Message struct {
+	Seq     int       `json:"seq"`
+	From    string    `json:"from"`
+	To      string    `json:"to"`
+	Content string    `json:"content"`
+	Time    time.Time `json:"time"`
+}
+
+// Blackboard is the state a team shares while working on one task. It is
+// safe for concurrent use.
+type Blackboard struct {
+	taskID string
+	now    func() time.Time
+
+	mu       sync.RWMutex
+	values   map[string]Entry
+	log      []Message
+	onPost   func(Message)
+	onUpdate func(Entry)
+}
+
+// NewBlackboard returns an empty blackboard for taskID.
+func NewBlackboard(taskID string) *Blackboard {
+	return &Blackboard{
+		taskID: taskID,
+		now:    time.Now,
+		values: make(map[string]Entry),
+	}
+}
+
+// TaskID returns the task the blackboard belongs to.
+func (b *Blackboard) TaskID() string {
+	return b.taskID
+}
+
+// observe sets callbacks run after every post and every write, outside the
+// blackboard lock.
+func (b *Blackboard) observe(onPost func(Message), onUpdate func(Entry)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onPost = onPost
+	b.onUpdate = onUpdate
+}
+
+// Set stores value under key on behalf of author.
+func (b *Blackboard) Set(author, key, value string) Entry {
+	entry := Entry{Key: strings.TrimSpace(key), Value: value, Author: author, UpdatedAt: b.now()}
+	b.mu.Lock()
+	b.values[entry.Key] = entry
+	onUpdate := b.onUpdate
+	b.mu.Unlock()
+	if onUpdate != nil {
+		onUpdate(entry)
+	}
+	return entry
+}
+
+// Get returns the entry stored under key.
+func (b *Blackboard) Get(key string) (Entry, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	entry, ok := b.values[strings.TrimSpace(key)]
+	return entry, ok
+}
+
+// Values returns every entry, sorted by key.
+func (b *Blackboard) Values() []Entry {
+	b.mu.RLock()
+	out := make([]Entry, 0, len(b.values))
+	for _, entry := range b.values {
+		out = append(out, entry)
+	}
+	b.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// Post appends a message from one member to another, or to Broadcast.
+func (b *Blackboard) Post(from, to, content string) Message {
+	b.mu.Lock()
+	msg := Message{
+		Seq:     len(b.log) + 1,
+		From:    from,
+		To:      to,
+		Content: content,
+		Time:    b.now(),
+	}
+	b.log = append(b.log, msg)
+	onPost := b.onPost
+	b.mu.Unlock()
+	if onPost != nil {
+		onPost(msg)
+	}
+	return msg
+}
+
+// Log returns every message posted so far, oldest first.
+func (b *Blackboard) Log() []Message {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return append([]Message(nil), b.log...)
+}
+
+// Inbox returns the messages addressed to member, directly or by broadcast,
+// that were posted after seq. A member's own broadcasts are left out.
+func (b *Blackboard) Inbox(member string, after int) []Message {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var out []Message
+	for _, msg := range b.log {
+		if msg.Seq <= after {
+			continue
+		}
+		if msg.To == member || (msg.To == Broadcast && msg.From != member) {
+			out = append(out, msg)
+		}
+	}
+	return out
+}
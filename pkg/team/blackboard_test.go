@@ -0,0 +1,46 @@
+package team
+
+import "testing"
+
+func TestBlackboard_InboxFiltersByRecipientAndCursor(t *testing.T) {
+	board := NewBlackboard("task-1")
+	board.Post("coordinator", "lead", "plan the trip")
+	board.Post("lead", "researcher", "find flights")
+	board.Post("lead", Broadcast, "deadline is friday")
+	board.Post("researcher", "lead", "found two")
+
+	inbox := board.Inbox("researcher", 0)
+	if len(inbox) != 2 || inbox[0].Seq != 2 || inbox[1].Seq != 3 {
+		t.Fatalf("researcher inbox = %+v, want messages 2 and 3", inbox)
+	}
+	if inbox := board.Inbox("lead", 1); len(inbox) != 1 || inbox[0].Content != "found two" {
+		t.Fatalf("lead inbox after 1 = %+v, want only the researcher reply", inbox)
+	}
+	if inbox := board.Inbox("researcher", 3); len(inbox) != 0 {
+		t.Fatalf("researcher inbox after 3 = %+v, want empty", inbox)
+	}
+}
+
+func TestBlackboard_SetOverwritesAndNotifies(t *testing.T) {
+	board := NewBlackboard("task-1")
+	var updates []Entry
+	var posts []Message
+	board.observe(func(msg Message) { posts = append(posts, msg) }, func(e Entry) { updates = append(updates, e) })
+
+	board.Set("a", "notes", "first")
+	board.Set("b", "notes", "second")
+	board.Set("a", "budget", "100")
+	board.Post("a", "b", "hi")
+
+	entry, ok := board.Get("notes")
+	if !ok || entry.Value != "second" || entry.Author != "b" {
+		t.Fatalf("notes = %+v, %v", entry, ok)
+	}
+	values := board.Values()
+	if len(values) != 2 || values[0].Key != "budget" || values[1].Key != "notes" {
+		t.Fatalf("values = %+v, want budget and notes sorted", values)
+	}
+	if len(updates) != 3 || len(posts) != 1 {
+		t.Fatalf("observed %d updates and %d posts, want 3 and 1", len(updates), len(posts))
+	}
+}
@@ -0,0 +1,225 @@
+package team
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	runtimeevents "github.com/sipeed/picoclaw/pkg/events"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/routing"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// coordinatorSender is the author of the message that hands the task to the
+// lead.
+const coordinatorSender = "coordinator"
+
+// Reasons a team task stops.
+const (
+	StopDone      = "done"
+	StopIdle      = "idle"
+	StopMaxRounds = "max_rounds"
+	StopCanceled  = "canceled"
+)
+
+// Runner runs one turn of a team member. extra holds the blackboard tools
+// bound to the member; the returned string is the member's final answer.
+type Runner interface {
+	RunMember(ctx context.Context, agentID, prompt string, extra []tools.Tool) (string, error)
+}
+
+// RunnerFunc adapts a function to Runner.
+type RunnerFunc func(ctx context.Context, agentID, prompt string, extra []tools.Tool) (string, error)
+
+func (f RunnerFunc) RunMember(ctx context.Context, agentID, prompt string, extra []tools.Tool) (string, error) {
+	return f(ctx, agentID, prompt, extra)
+}
+
+// Result is the outcome of a team task.
+type Result struct {
+	TaskID     string    `json:"task_id"`
+	Team       string    `json:"team"`
+	Answer     string    `json:"answer"`
+	StopReason string    `json:"stop_reason"`
+	Rounds     int       `json:"rounds"`
+	Messages   []Message `json:"messages"`
+	Values     []Entry   `json:"values"`
+}
+
+// Coordinator runs a team on a task. Each round it gives every member with
+// unread messages one turn, lead first. The task stops when the done key is
+// written, no member has unread messages, the round limit is reached, or ctx
+// is canceled.
+type Coordinator struct {
+	team    config.TeamConfig
+	members []string
+	lead    string
+	runner  Runner
+	events  runtimeevents.Bus
+}
+
+// NewCoordinator returns a coordinator for team. Member ids are normalized
+// and the lead is always a member.
+func NewCoordinator(team config.TeamConfig, runner Runner) (*Coordinator, error) {
+	if runner == nil {
+		return nil, errors.New("team runner is required")
+	}
+	lead := routing.NormalizeAgentID(team.EffectiveLead())
+	if strings.TrimSpace(team.EffectiveLead()) == "" {
+		return nil, fmt.Errorf("team %q has no members", team.ID)
+	}
+	members := []string{lead}
+	for _, member := range team.Members {
+		id := routing.NormalizeAgentID(member)
+		if id != lead && !containsString(members, id) {
+			members = append(members, id)
+		}
+	}
+	return &Coordinator{team: team, members: members, lead: lead, runner: runner}, nil
+}
+
+// WithEvents publishes task, message and blackboard events to bus.
+func (c *Coordinator) WithEvents(bus runtimeevents.Bus) *Coordinator {
+	c.events = bus
+	return c
+}
+
+// Members returns the normalized member ids, lead first.
+func (c *Coordinator) Members() []string {
+	return append([]string(nil), c.members...)
+}
+
+// Run works on task until a termination condition is met. The result is
+// returned with the error when ctx is canceled.
+func (c *Coordinator) Run(ctx context.Context, taskID, task string) (*Result, error) {
+	board := NewBlackboard(taskID)
+	scope := runtimeevents.Scope{
+		AgentID:    tools.ToolAgentID(ctx),
+		SessionKey: tools.ToolSessionKey(ctx),
+		Channel:    tools.ToolChannel(ctx),
+		ChatID:     tools.ToolChatID(ctx),
+	}
+	board.observe(
+		func(msg Message) { c.publishMessage(scope, taskID, msg) },
+		func(entry Entry) { c.publishUpdate(scope, taskID, entry) },
+	)
+	doneKey := c.team.EffectiveDoneKey()
+	result := &Result{TaskID: taskID, Team: c.team.ID}
+
+	c.publish(runtimeevents.KindTeamTaskStart, scope, runtimeevents.SeverityInfo, TaskPayload{
+		Team:    c.team.ID,
+		TaskID:  taskID,
+		Lead:    c.lead,
+		Members: c.Members(),
+		TaskLen: len(task),
+	})
+	board.Post(coordinatorSender, c.lead, task)
+
+	cursors := make(map[string]int, len(c.members))
+	var runErr error
+	for round := 1; round <= c.team.EffectiveMaxRounds() && result.StopReason == ""; round++ {
+		active := false
+		for _, member := range c.members {
+			if err := ctx.Err(); err != nil {
+				result.StopReason, runErr = StopCanceled, err
+				break
+			}
+			inbox := board.Inbox(member, cursors[member])
+			if len(inbox) == 0 {
+				continue
+			}
+			cursors[member] = inbox[len(inbox)-1].Seq
+			active = true
+			result.Rounds = round
+
+			reply, err := c.runner.RunMember(ctx, member, c.memberPrompt(member, task, taskID, inbox),
+				MemberTools(board, member, c.members, doneKey))
+			if err != nil {
+				if ctx.Err() != nil {
+					result.StopReason, runErr = StopCanceled, ctx.Err()
+					break
+				}
+				logger.WarnCF("team", "Team member turn failed", map[string]any{
+					"team":    c.team.ID,
+					"task_id": taskID,
+					"agent":   member,
+					"error":   err.Error(),
+				})
+				reply = fmt.Sprintf("(turn failed: %v)", err)
+			}
+			if member == c.lead {
+				result.Answer = reply
+			} else if strings.TrimSpace(reply) != "" {
+				board.Post(member, c.lead, reply)
+			}
+			if done, ok := board.Get(doneKey); ok {
+				result.Answer = done.Value
+				result.StopReason = StopDone
+				break
+			}
+		}
+		if !active && result.StopReason == "" {
+			result.StopReason = StopIdle
+		}
+	}
+	if result.StopReason == "" {
+		result.StopReason = StopMaxRounds
+	}
+	result.Messages = board.Log()
+	result.Values = board.Values()
+
+	severity := runtimeevents.SeverityInfo
+	if result.StopReason == StopCanceled || result.StopReason == StopMaxRounds {
+		severity = runtimeevents.SeverityWarn
+	}
+	c.publish(runtimeevents.KindTeamTaskEnd, scope, severity, TaskEndPayload{
+		Team:       c.team.ID,
+		TaskID:     taskID,
+		StopReason: result.StopReason,
+		Rounds:     result.Rounds,
+		Messages:   len(result.Messages),
+		AnswerLen:  len(result.Answer),
+	})
+	return result, runErr
+}
+
+func (c *Coordinator) memberPrompt(member, task, taskID string, inbox []Message) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "You are agent %q in team %q, working with the team on task %s.\n", member, c.team.ID, taskID)
+	sb.WriteString("Team members: ")
+	for i, id := range c.members {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(id)
+		if id == c.lead {
+			sb.WriteString(" (lead)")
+		}
+	}
+	fmt.Fprintf(&sb, "\n\nTask:\n%s\n\nNew messages for you:\n", task)
+	for _, msg := range inbox {
+		fmt.Fprintf(&sb, "[#%d from %s] %s\n", msg.Seq, msg.From, msg.Content)
+	}
+	sb.WriteString("\nUse post_to_agent to ask other members for help or share results, and " +
+		"read_blackboard to see the shared notes and message log. ")
+	if member == c.lead {
+		fmt.Fprintf(&sb, "You are the lead: when the task is complete, call post_to_agent with key %q "+
+			"and the final answer as message. Members' replies reach you as new messages.",
+			c.team.EffectiveDoneKey())
+	} else {
+		sb.WriteString("Your final reply is posted to the lead.")
+	}
+	return sb.String()
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package team
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	runtimeevents "github.com/sipeed/picoclaw/pkg/events"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+func findTool(t *testing.T, extra []tools.Tool, name string) tools.Tool {
+	t.Helper()
+	for _, tool := range extra {
+		if tool.Name() == name {
+			return tool
+		}
+	}
+	t.Fatalf("tool %q not passed to member", name)
+	return nil
+}
+
+func TestCoordinator_LeadDelegatesAndFinishes(t *testing.T) {
+	bus := runtimeevents.NewBus()
+	defer bus.Close()
+	sub, events, err := bus.Channel().KindPrefix("team.").SubscribeChan(context.Background(),
+		runtimeevents.SubscribeOptions{Name: "team", Buffer: 32})
+	if err != nil {
+		t.Fatalf("SubscribeChan: %v", err)
+	}
+	defer sub.Close()
+
+	var turns []string
+	runner := RunnerFunc(func(ctx context.Context, agentID, prompt string, extra []tools.Tool) (string, error) {
+		turns = append(turns, agentID)
+		post := findTool(t, extra, "post_to_agent")
+		switch {
+		case agentID == "lead" && strings.Contains(prompt, "from coordinator"):
+			if res := post.Execute(ctx, map[string]any{"to": "researcher", "message": "find flights"}); res.IsError {
+				t.Fatalf("post: %s", res.ForLLM)
+			}
+			return "asked the researcher", nil
+		case agentID == "researcher":
+			read := findTool(t, extra, "read_blackboard").Execute(ctx, map[string]any{})
+			if !strings.Contains(read.ForLLM, "find flights") {
+				t.Fatalf("blackboard = %q, want the lead's message", read.ForLLM)
+			}
+			return "flight AB123 at 9am", nil
+		default:
+			if !strings.Contains(prompt, "flight AB123") {
+				t.Fatalf("lead prompt misses the researcher reply:\n%s", prompt)
+			}
+			post.Execute(ctx, map[string]any{"key": "final", "message": "Take AB123."})
+			return "done", nil
+		}
+	})
+
+	coordinator, err := NewCoordinator(config.TeamConfig{
+		ID:      "travel",
+		Members: []string{"lead", "researcher", "idle"},
+	}, runner)
+	if err != nil {
+		t.Fatalf("NewCoordinator: %v", err)
+	}
+	result, err := coordinator.WithEvents(bus).Run(context.Background(), "task-1", "plan the trip")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.StopReason != StopDone || result.Answer != "Take AB123." || result.Rounds != 2 {
+		t.Fatalf("result = %+v", result)
+	}
+	if strings.Join(turns, ",") != "lead,researcher,lead" {
+		t.Fatalf("turns = %v, want lead,researcher,lead", turns)
+	}
+
+	kinds := map[runtimeevents.Kind]int{}
+	deadline := time.After(time.Second)
+	for kinds[runtimeevents.KindTeamTaskEnd] == 0 {
+		select {
+		case evt := <-events:
+			kinds[evt.Kind]++
+		case <-deadline:
+			t.Fatalf("no task end event; got %v", kinds)
+		}
+	}
+	if kinds[runtimeevents.KindTeamTaskStart] != 1 || kinds[runtimeevents.KindTeamMessagePosted] != 3 ||
+		kinds[runtimeevents.KindTeamBlackboardUpdated] != 1 {
+		t.Fatalf("event counts = %v", kinds)
+	}
+}
+
+func TestCoordinator_StopsWhenIdleOrOutOfRounds(t *testing.T) {
+	quiet := RunnerFunc(func(context.Context, string, string, []tools.Tool) (string, error) {
+		return "nothing to add", nil
+	})
+	coordinator, err := NewCoordinator(config.TeamConfig{ID: "solo", Members: []string{"a", "b"}}, quiet)
+	if err != nil {
+		t.Fatalf("NewCoordinator: %v", err)
+	}
+	result, err := coordinator.Run(context.Background(), "task-idle", "say hi")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.StopReason != StopIdle || result.Answer != "nothing to add" || result.Rounds != 1 {
+		t.Fatalf("idle result = %+v", result)
+	}
+
+	chatty := RunnerFunc(func(ctx context.Context, agentID, _ string, extra []tools.Tool) (string, error) {
+		to := "b"
+		if agentID == "b" {
+			to = "a"
+		}
+		findTool(t, extra, "post_to_agent").Execute(ctx, map[string]any{"to": to, "message": "again"})
+		return "", nil
+	})
+	coordinator, err = NewCoordinator(config.TeamConfig{ID: "loop", Members: []string{"a", "b"}, MaxRounds: 3}, chatty)
+	if err != nil {
+		t.Fatalf("NewCoordinator: %v", err)
+	}
+	result, err = coordinator.Run(context.Background(), "task-loop", "argue")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.StopReason != StopMaxRounds || result.Rounds != 3 {
+		t.Fatalf("looping result = %+v", result)
+	}
+}
+
+func TestPostToAgentTool_RejectsNonMembersAndSelf(t *testing.T) {
+	board := NewBlackboard("task-1")
+	post := MemberTools(board, "a", []string{"a", "b"}, "final")[0]
+
+	for _, args := range []map[string]any{
+		{"to": "c", "message": "hi"},
+		{"to": "a", "message": "hi"},
+		{"message": "hi"},
+		{"to": "b"},
+	} {
+		if res := post.Execute(context.Background(), args); !res.IsError {
+			t.Fatalf("args %v: expected an error, got %q", args, res.ForLLM)
+		}
+	}
+	if res := post.Execute(context.Background(), map[string]any{"to": "*", "message": "hello"}); res.IsError {
+		t.Fatalf("broadcast: %s", res.ForLLM)
+	}
+	if inbox := board.Inbox("b", 0); len(inbox) != 1 {
+		t.Fatalf("b inbox = %+v, want the broadcast", inbox)
+	}
+}
@@ -0,0 +1,127 @@
+package team
+
+import (
+	runtimeevents "github.com/sipeed/picoclaw/pkg/events"
+)
+
+// TaskPayload describes a team task that starts.
+type TaskPayload struct {
+	Team    string   `json:"team"`
+	TaskID  string   `json:"task_id"`
+	Lead    string   `json:"lead"`
+	Members []string `json:"members"`
+	TaskLen int      `json:"task_len"`
+}
+
+// TaskEndPayload describes how a team task ended.
+type TaskEndPayload struct {
+	Team       string `json:"team"`
+	TaskID     string `json:"task_id"`
+	StopReason string `json:"stop_reason"`
+	Rounds     int    `json:"rounds"`
+	Messages   int    `json:"messages"`
+	AnswerLen  int    `json:"answer_len"`
+}
+
+// MessagePayload describes a message posted to the blackboard log.
+type MessagePayload struct {
+	Team       string `json:"team"`
+	TaskID     string `json:"task_id"`
+	Seq        int    `json:"seq"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	ContentLen int    `json:"content_len"`
+}
+
+// UpdatePayload describes a blackboard write.
+type UpdatePayload struct {
+	Team     string `json:"team"`
+	TaskID   string `json:"task_id"`
+	Key      string `json:"key"`
+	Author   string `json:"author"`
+	ValueLen int    `json:"value_len"`
+}
+
+func (c *Coordinator) publishMessage(scope runtimeevents.Scope, taskID string, msg Message) {
+	c.publish(runtimeevents.KindTeamMessagePosted, scope, runtimeevents.SeverityInfo, MessagePayload{
+		Team:       c.team.ID,
+		TaskID:     taskID,
+		Seq:        msg.Seq,
+		From:       msg.From,
+		To:         msg.To,
+		ContentLen: len(msg.Content),
+	})
+}
+
+func (c *Coordinator) publishUpdate(scope runtimeevents.Scope, taskID string, entry Entry) {
+	c.publish(runtimeevents.KindTeamBlackboardUpdated, scope, runtimeevents.SeverityInfo, UpdatePayload{
+		Team:     c.team.ID,
+		TaskID:   taskID,
+		Key:      entry.Key,
+		Author:   entry.Author,
+		ValueLen: len(entry.Value),
+	})
+}
+
+func (c *Coordinator) publish(
+	kind runtimeevents.Kind,
+	scope runtimeevents.Scope,
+	severity runtimeevents.Severity,
+	payload any,
+) {
+	if c == nil || c.events == nil {
+		return
+	}
+	c.events.PublishNonBlocking(runtimeevents.Event{
+		Kind:        kind,
+		Source:      runtimeevents.Source{Component: "team", Name: c.team.ID},
+		Scope:       scope,
+		Correlation: runtimeevents.Correlation{RequestID: teamTaskID(payload)},
+		Severity:    severity,
+		Payload:     payload,
+		Attrs:       teamEventAttrs(payload),
+	})
+}
+
+func teamTaskID(payload any) string {
+	switch p := payload.(type) {
+	case TaskPayload:
+		return p.TaskID
+	case TaskEndPayload:
+		return p.TaskID
+	case MessagePayload:
+		return p.TaskID
+	case UpdatePayload:
+		return p.TaskID
+	}
+	return ""
+}
+
+func teamEventAttrs(payload any) map[string]any {
+	attrs := map[string]any{}
+	switch p := payload.(type) {
+	case TaskPayload:
+		attrs["team"] = p.Team
+		attrs["lead"] = p.Lead
+		attrs["members"] = len(p.Members)
+		attrs["task_len"] = p.TaskLen
+	case TaskEndPayload:
+		attrs["team"] = p.Team
+		attrs["stop_reason"] = p.StopReason
+		attrs["rounds"] = p.Rounds
+		attrs["messages"] = p.Messages
+		attrs["answer_len"] = p.AnswerLen
+	case MessagePayload:
+		attrs["team"] = p.Team
+		attrs["seq"] = p.Seq
+		attrs["from"] = p.From
+		attrs["to"] = p.To
+		attrs["content_len"] = p.ContentLen
+	case UpdatePayload:
+		attrs["team"] = p.Team
+		attrs["key"] = p.Key
+		attrs["author"] = p.Author
+		attrs["value_len"] = p.ValueLen
+	}
+	return attrs
+}
@@ -0,0 +1,136 @@
+package team
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	runtimeevents "github.com/sipeed/picoclaw/pkg/events"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// TeamTool runs a task with one of the configured teams and waits for its
+// answer. Members run as sub-turns of the calling turn, each as its own
+// agent, with the blackboard tools added to its tool set.
+type TeamTool struct {
+	teams   []config.TeamConfig
+	spawner tools.SubTurnSpawner
+	events  runtimeevents.Bus
+	seq     atomic.Uint64
+}
+
+func NewTeamTool(teams []config.TeamConfig) *TeamTool {
+	return &TeamTool{teams: teams}
+}
+
+func (t *TeamTool) SetSpawner(spawner tools.SubTurnSpawner) {
+	t.spawner = spawner
+}
+
+func (t *TeamTool) SetEventBus(bus runtimeevents.Bus) {
+	t.events = bus
+}
+
+func (t *TeamTool) Name() string {
+	return "team"
+}
+
+func (t *TeamTool) Description() string {
+	return "Hand a task to a team of agents and wait for the team's answer. The team lead gets " +
+		"the task and coordinates the other members through messages and a shared blackboard."
+}
+
+func (t *TeamTool) Parameters() map[string]any {
+	ids := make([]string, 0, len(t.teams))
+	var desc strings.Builder
+	desc.WriteString("The team to run the task")
+	for _, team := range t.teams {
+		ids = append(ids, team.ID)
+		fmt.Fprintf(&desc, "; %s: %s", team.ID, strings.Join(team.Members, ", "))
+	}
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"team": map[string]any{
+				"type":        "string",
+				"description": desc.String(),
+				"enum":        ids,
+			},
+			"task": map[string]any{
+				"type":        "string",
+				"description": "Clear description of the task for the team",
+			},
+		},
+		"required": []string{"team", "task"},
+	}
+}
+
+func (t *TeamTool) Execute(ctx context.Context, args map[string]any) *tools.ToolResult {
+	teamID, _ := args["team"].(string)
+	team, ok := config.AgentsConfig{Teams: t.teams}.FindTeam(teamID)
+	if !ok {
+		return tools.ErrorResult(fmt.Sprintf("unknown team %q", teamID))
+	}
+	task, _ := args["task"].(string)
+	if strings.TrimSpace(task) == "" {
+		return tools.ErrorResult("task is required and must be a non-empty string")
+	}
+	if t.spawner == nil {
+		return tools.ErrorResult("team tool not configured")
+	}
+
+	coordinator, err := NewCoordinator(team, SpawnerRunner(t.spawner, team.TokenBudget))
+	if err != nil {
+		return tools.ErrorResult(err.Error()).WithError(err)
+	}
+	coordinator.WithEvents(t.events)
+
+	taskID := fmt.Sprintf("team-%s-%d-%d", team.ID, time.Now().Unix(), t.seq.Add(1))
+	result, err := coordinator.Run(ctx, taskID, task)
+	if err != nil {
+		return tools.ErrorResult(fmt.Sprintf("team %q stopped: %v", team.ID, err)).WithError(err)
+	}
+	return tools.NewToolResult(fmt.Sprintf("[Team %q %s after %d round(s), %d message(s)]\n%s",
+		team.ID, describeStop(result.StopReason), result.Rounds, len(result.Messages), result.Answer))
+}
+
+// SpawnerRunner runs members as synchronous sub-turns. A positive
+// tokenBudget is shared by every member turn of a task.
+func SpawnerRunner(spawner tools.SubTurnSpawner, tokenBudget int) Runner {
+	var budget *atomic.Int64
+	if tokenBudget > 0 {
+		budget = &atomic.Int64{}
+		budget.Store(int64(tokenBudget))
+	}
+	return RunnerFunc(func(ctx context.Context, agentID, prompt string, extra []tools.Tool) (string, error) {
+		result, err := spawner.SpawnSubTurn(ctx, tools.SubTurnConfig{
+			TargetAgentID:      agentID,
+			SystemPrompt:       prompt,
+			ExtraTools:         extra,
+			InitialTokenBudget: budget,
+		})
+		if err != nil {
+			return "", err
+		}
+		if result == nil {
+			return "", fmt.Errorf("agent %q returned no result", agentID)
+		}
+		return result.ForLLM, nil
+	})
+}
+
+func describeStop(reason string) string {
+	switch reason {
+	case StopDone:
+		return "finished"
+	case StopIdle:
+		return "stopped with no pending messages"
+	case StopMaxRounds:
+		return "hit its round limit"
+	default:
+		return reason
+	}
+}
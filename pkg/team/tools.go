@@ -0,0 +1,154 @@
+package team
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/routing"
+	"github.com/sipeed/picoclaw/pkg/tools"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// maxBlackboardLog is how many of the latest messages read_blackboard shows.
+const maxBlackboardLog = 50
+
+// MemberTools returns the post_to_agent and read_blackboard tools bound to
+// board on behalf of member.
+func MemberTools(board *Blackboard, member string, members []string, doneKey string) []tools.Tool {
+	return []tools.Tool{
+		&PostToAgentTool{board: board, self: member, members: members, doneKey: doneKey},
+		&ReadBlackboardTool{board: board},
+	}
+}
+
+// PostToAgentTool posts a message to another team member and optionally
+// stores it under a blackboard key.
+type PostToAgentTool struct {
+	board   *Blackboard
+	self    string
+	members []string
+	doneKey string
+}
+
+func (t *PostToAgentTool) Name() string {
+	return "post_to_agent"
+}
+
+func (t *PostToAgentTool) Description() string {
+	return "Post a message to another member of your team, or to every member with to=\"*\". " +
+		"The member reads it on its next turn. Set key to also store the message on the " +
+		"shared blackboard; storing key \"" + t.doneKey + "\" ends the team task with that answer."
+}
+
+func (t *PostToAgentTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"to": map[string]any{
+				"type":        "string",
+				"description": "Agent ID of the team member to message, or \"*\" for every member",
+				"enum":        append(append([]string(nil), t.members...), Broadcast),
+			},
+			"message": map[string]any{
+				"type":        "string",
+				"description": "Message content",
+			},
+			"key": map[string]any{
+				"type":        "string",
+				"description": "Optional blackboard key to store the message under",
+			},
+		},
+		"required": []string{"message"},
+	}
+}
+
+func (t *PostToAgentTool) Execute(_ context.Context, args map[string]any) *tools.ToolResult {
+	message, _ := args["message"].(string)
+	if strings.TrimSpace(message) == "" {
+		return tools.ErrorResult("message is required and must be a non-empty string")
+	}
+	rawTo, _ := args["to"].(string)
+	key, _ := args["key"].(string)
+	key = strings.TrimSpace(key)
+	if strings.TrimSpace(rawTo) == "" && key == "" {
+		return tools.ErrorResult("set to, key, or both")
+	}
+
+	var notes []string
+	if key != "" {
+		t.board.Set(t.self, key, message)
+		notes = append(notes, fmt.Sprintf("stored under %q", key))
+	}
+	if strings.TrimSpace(rawTo) != "" {
+		to := Broadcast
+		if strings.TrimSpace(rawTo) != Broadcast {
+			to = routing.NormalizeAgentID(rawTo)
+			if !containsString(t.members, to) {
+				return tools.ErrorResult(fmt.Sprintf("%q is not a member of this team", to))
+			}
+			if to == t.self {
+				return tools.ErrorResult("cannot post to yourself")
+			}
+		}
+		msg := t.board.Post(t.self, to, message)
+		notes = append(notes, fmt.Sprintf("posted as message #%d to %s", msg.Seq, to))
+	}
+	return tools.SilentResult("Message " + strings.Join(notes, " and ") + ".")
+}
+
+// ReadBlackboardTool shows the shared blackboard of the team task.
+type ReadBlackboardTool struct {
+	board *Blackboard
+}
+
+func (t *ReadBlackboardTool) Name() string {
+	return "read_blackboard"
+}
+
+func (t *ReadBlackboardTool) Description() string {
+	return "Read the blackboard your team shares for the current task: stored values and the " +
+		"log of messages members posted to each other. Pass key to read one value."
+}
+
+func (t *ReadBlackboardTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"key": map[string]any{
+				"type":        "string",
+				"description": "Optional key to read instead of the whole blackboard",
+			},
+		},
+	}
+}
+
+func (t *ReadBlackboardTool) Execute(_ context.Context, args map[string]any) *tools.ToolResult {
+	if key, _ := args["key"].(string); strings.TrimSpace(key) != "" {
+		entry, ok := t.board.Get(key)
+		if !ok {
+			return tools.SilentResult(fmt.Sprintf("Blackboard has no value for %q.", strings.TrimSpace(key)))
+		}
+		return tools.SilentResult(fmt.Sprintf("%s (by %s):\n%s", entry.Key, entry.Author, entry.Value))
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Blackboard for task %s\n\nValues:\n", t.board.TaskID())
+	values := t.board.Values()
+	if len(values) == 0 {
+		sb.WriteString("(none)\n")
+	}
+	for _, entry := range values {
+		fmt.Fprintf(&sb, "- %s (by %s): %s\n", entry.Key, entry.Author, utils.Truncate(entry.Value, 500))
+	}
+	sb.WriteString("\nMessages:\n")
+	log := t.board.Log()
+	if len(log) > maxBlackboardLog {
+		fmt.Fprintf(&sb, "(%d earlier messages omitted)\n", len(log)-maxBlackboardLog)
+		log = log[len(log)-maxBlackboardLog:]
+	}
+	for _, msg := range log {
+		fmt.Fprintf(&sb, "#%d %s -> %s: %s\n", msg.Seq, msg.From, msg.To, utils.Truncate(msg.Content, 500))
+	}
+	return tools.SilentResult(sb.String())
+}
@@ -4,16 +4,32 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/sipeed/picoclaw/pkg/routing"
 )
 
+// RemoteDelegator runs delegated tasks on agents of other picoclaw
+// instances, addressed as agent@instance.
+type RemoteDelegator interface {
+	// LocalInstance returns the instance id of this process; targets naming
+	// it are delegated locally.
+	LocalInstance() string
+	// RemoteAgents describes the agents currently reachable on other
+	// instances, one "agent@instance (...)" entry each.
+	RemoteAgents() []string
+	DelegateRemote(ctx context.Context, instance, agentID, task string) (string, error)
+}
+
 // DelegateTool delegates a task to a specific named agent and waits for
 // the result. Unlike spawn (async, fire-and-forget) or subagent (sync but
 // generic), delegate targets a named agent and runs the task using that
-// agent's own workspace, model, and tools.
+// agent's own workspace, model, and tools. With a remote delegator set,
+// agent@instance targets an agent of another instance.
 type DelegateTool struct {
 	spawner        SubTurnSpawner
+	remoteMu       sync.RWMutex
+	remote         RemoteDelegator
 	allowlistCheck func(targetAgentID string) bool
 	selfAgentID    string
 }
@@ -26,6 +42,20 @@ func (t *DelegateTool) SetSpawner(spawner SubTurnSpawner) {
 	t.spawner = spawner
 }
 
+// SetRemoteDelegator enables agent@instance targets. The local allowlist
+// does not apply to them; each instance decides which agents it exposes.
+func (t *DelegateTool) SetRemoteDelegator(remote RemoteDelegator) {
+	t.remoteMu.Lock()
+	defer t.remoteMu.Unlock()
+	t.remote = remote
+}
+
+func (t *DelegateTool) remoteDelegator() RemoteDelegator {
+	t.remoteMu.RLock()
+	defer t.remoteMu.RUnlock()
+	return t.remote
+}
+
 func (t *DelegateTool) SetAllowlistChecker(check func(targetAgentID string) bool) {
 	t.allowlistCheck = check
 }
@@ -39,10 +69,19 @@ func (t *DelegateTool) Name() string {
 }
 
 func (t *DelegateTool) Description() string {
-	return "Delegate a task to another agent and wait for the result. " +
+	desc := "Delegate a task to another agent and wait for the result. " +
 		"Use this when another agent is better suited to handle a specific task " +
 		"based on their capabilities. The target agent runs with its own workspace, " +
 		"model, and tools."
+	remote := t.remoteDelegator()
+	if remote == nil {
+		return desc
+	}
+	desc += " Agents of other picoclaw instances are addressed as agent@instance."
+	if agents := remote.RemoteAgents(); len(agents) > 0 {
+		desc += " Reachable remote agents: " + strings.Join(agents, "; ") + "."
+	}
+	return desc
 }
 
 func (t *DelegateTool) Parameters() map[string]any {
@@ -50,8 +89,9 @@ func (t *DelegateTool) Parameters() map[string]any {
 		"type": "object",
 		"properties": map[string]any{
 			"agent_id": map[string]any{
-				"type":        "string",
-				"description": "The ID of the target agent to delegate the task to",
+				"type": "string",
+				"description": "The ID of the target agent to delegate the task to, " +
+					"or agent@instance for an agent of another instance",
 			},
 			"task": map[string]any{
 				"type":        "string",
@@ -67,13 +107,19 @@ func (t *DelegateTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 	if strings.TrimSpace(rawAgentID) == "" {
 		return ErrorResult("agent_id is required and must be a non-empty string")
 	}
+	rawAgentID, instance, remote := strings.Cut(strings.TrimSpace(rawAgentID), "@")
 	agentID := routing.NormalizeAgentID(rawAgentID)
+	instance = strings.TrimSpace(instance)
 
 	task, _ := args["task"].(string)
 	if strings.TrimSpace(task) == "" {
 		return ErrorResult("task is required and must be a non-empty string")
 	}
 
+	if delegator := t.remoteDelegator(); remote && (delegator == nil || instance != delegator.LocalInstance()) {
+		return t.delegateRemote(ctx, instance, agentID, task)
+	}
+
 	if t.selfAgentID != "" && agentID == t.selfAgentID {
 		return ErrorResult("cannot delegate to self")
 	}
@@ -102,3 +148,19 @@ func (t *DelegateTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 
 	return result
 }
+
+func (t *DelegateTool) delegateRemote(ctx context.Context, instance, agentID, task string) *ToolResult {
+	target := agentID + "@" + instance
+	if instance == "" {
+		return ErrorResult("instance is required after @ in agent_id")
+	}
+	remote := t.remoteDelegator()
+	if remote == nil {
+		return ErrorResult(fmt.Sprintf("cannot delegate to %q: swarm mode is not enabled", target))
+	}
+	answer, err := remote.DelegateRemote(ctx, instance, agentID, task)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("delegation to agent %q failed: %v", target, err)).WithError(err)
+	}
+	return NewToolResult(fmt.Sprintf("[Response from agent %q]\n%s", target, answer))
+}
@@ -298,3 +298,79 @@ type nilResultSpawner struct{}
 func (m *nilResultSpawner) SpawnSubTurn(_ context.Context, _ SubTurnConfig) (*ToolResult, error) {
 	return nil, nil
 }
+
+// delegateMockRemote records remote delegations.
+type delegateMockRemote struct {
+	local    string
+	instance string
+	agentID  string
+	task     string
+}
+
+func (m *delegateMockRemote) LocalInstance() string { return m.local }
+
+func (m *delegateMockRemote) RemoteAgents() []string { return []string{"sensors@pi (hardware: i2c)"} }
+
+func (m *delegateMockRemote) DelegateRemote(_ context.Context, instance, agentID, task string) (string, error) {
+	m.instance, m.agentID, m.task = instance, agentID, task
+	return "21.5C", nil
+}
+
+func TestDelegateTool_Execute_RemoteTarget(t *testing.T) {
+	spawner := &delegateMockSpawner{}
+	remote := &delegateMockRemote{local: "desktop"}
+	tool := NewDelegateTool()
+	tool.SetSpawner(spawner)
+	tool.SetRemoteDelegator(remote)
+	tool.SetAllowlistChecker(func(string) bool { return false })
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"agent_id": "Sensors@pi",
+		"task":     "read the temperature",
+	})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", result.ForLLM)
+	}
+	if remote.instance != "pi" || remote.agentID != "sensors" || remote.task != "read the temperature" {
+		t.Fatalf("remote delegation = %+v", remote)
+	}
+	if !strings.Contains(result.ForLLM, `[Response from agent "sensors@pi"]`) ||
+		!strings.Contains(result.ForLLM, "21.5C") {
+		t.Fatalf("ForLLM = %q", result.ForLLM)
+	}
+	if !strings.Contains(tool.Description(), "sensors@pi (hardware: i2c)") {
+		t.Fatalf("description should list remote agents: %s", tool.Description())
+	}
+}
+
+func TestDelegateTool_Execute_LocalInstanceTargetStaysLocal(t *testing.T) {
+	spawner := &delegateMockSpawner{}
+	remote := &delegateMockRemote{local: "desktop"}
+	tool := NewDelegateTool()
+	tool.SetSpawner(spawner)
+	tool.SetRemoteDelegator(remote)
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"agent_id": "beta@desktop",
+		"task":     "test",
+	})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", result.ForLLM)
+	}
+	if spawner.lastCfg.TargetAgentID != "beta" || remote.instance != "" {
+		t.Fatalf("expected local delegation to beta, spawner=%+v remote=%+v", spawner.lastCfg, remote)
+	}
+}
+
+func TestDelegateTool_Execute_RemoteTargetWithoutSwarm(t *testing.T) {
+	tool := NewDelegateTool()
+	tool.SetSpawner(&delegateMockSpawner{})
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"agent_id": "sensors@pi",
+		"task":     "test",
+	})
+	if !result.IsError || !strings.Contains(result.ForLLM, "swarm mode is not enabled") {
+		t.Fatalf("expected swarm disabled error, got: %s", result.ForLLM)
+	}
+}
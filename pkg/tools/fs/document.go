@@ -0,0 +1,121 @@
+package fstools
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/document"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+var pagesParameter = map[string]any{
+	"type": "string",
+	"description": "For PDF, DOCX, XLSX, PPTX and EPUB files: the pages, sheets, slides or chapters to read, " +
+		"e.g. \"1-3,7\", \"5-\" or a sheet name. Defaults to as many as fit the read budget, from the first.",
+}
+
+// documentFormat reports whether path is a document read_file extracts
+// rather than returning raw bytes. CSV stays plain text.
+func documentFormat(path string, head []byte) document.Format {
+	if f := document.Detect(path, "", head); f.Binary() {
+		return f
+	}
+	return ""
+}
+
+// pagesArg returns the optional "pages" argument as a string.
+func pagesArg(args map[string]any) (string, bool, error) {
+	raw, ok := args["pages"]
+	if !ok || raw == nil {
+		return "", false, nil
+	}
+	switch v := raw.(type) {
+	case string:
+		return v, true, nil
+	case float64:
+		return strconv.FormatInt(int64(v), 10), true, nil
+	case int:
+		return strconv.Itoa(v), true, nil
+	case int64:
+		return strconv.FormatInt(v, 10), true, nil
+	}
+	return "", false, fmt.Errorf("pages must be a string such as \"1-3,5\"")
+}
+
+// readDocument extracts the text of a PDF, Office or EPUB file and renders
+// the selected sections within maxBytes.
+func readDocument(r io.Reader, path string, format document.Format, pages string, maxBytes int64) *ToolResult {
+	data, err := io.ReadAll(io.LimitReader(r, document.MaxSize+1))
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to read file: %v", err))
+	}
+	if len(data) > document.MaxSize {
+		return ErrorResult(fmt.Sprintf("document is larger than %d bytes and cannot be extracted", document.MaxSize))
+	}
+	doc, err := document.Extract(data, format)
+	if errors.Is(err, document.ErrEncrypted) {
+		return ErrorResult(fmt.Sprintf("%s is password-protected; its text cannot be extracted", filepath.Base(path)))
+	}
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to extract document text: %v", err))
+	}
+
+	unit := doc.Format.Unit()
+	total := len(doc.Sections)
+	displayPath := filepath.Base(path)
+	if total == 0 || doc.Empty() {
+		return NewToolResult(fmt.Sprintf(
+			"[file: %s | document: %s | %d %ss]\n\n[NO TEXT - no text could be extracted. The document may contain only scanned images.]",
+			displayPath, doc.Format, total, unit,
+		))
+	}
+
+	indices, err := doc.Select(pages)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	text, full, cut := doc.RenderBudget(indices, int(maxBytes))
+	shown := indices[:max(full, 1)]
+
+	header := fmt.Sprintf(
+		"[file: %s | document: %s | read: %ss %s of %d]",
+		displayPath, doc.Format, unit, document.FormatRanges(shown), total,
+	)
+	switch {
+	case cut:
+		header += fmt.Sprintf(
+			"\n[TRUNCATED - %s %d exceeded the %d byte read budget and was cut.]",
+			unit, indices[0]+1, maxBytes,
+		)
+		if len(indices) > 1 {
+			header += fmt.Sprintf(" Call read_file again with pages=%q to continue.", document.FormatRanges(indices[1:]))
+		}
+	case full < len(indices):
+		header += fmt.Sprintf(
+			"\n[TRUNCATED - byte budget reached. Call read_file again with pages=%q to continue.]",
+			document.FormatRanges(indices[full:]),
+		)
+	case indices[len(indices)-1] < total-1:
+		header += fmt.Sprintf(
+			"\n[PARTIAL - more %ss remain. Call read_file again with pages=\"%d-\" to continue.]",
+			unit, indices[len(indices)-1]+2,
+		)
+	default:
+		header += "\n[END OF FILE - no further content.]"
+	}
+
+	logger.DebugCF("tool", "ReadFileTool extracted document text",
+		map[string]any{
+			"path":     path,
+			"format":   string(doc.Format),
+			"sections": len(shown),
+			"total":    total,
+			"bytes":    len(text),
+		})
+
+	return NewToolResult(header + "\n\n" + strings.TrimSpace(text))
+}
@@ -0,0 +1,81 @@
+package fstools
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestPPTX(t *testing.T, path string, slides ...string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("os.Create() error = %v", err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	files := map[string]string{
+		"ppt/presentation.xml":            `<presentation><sldIdLst/></presentation>`,
+		"ppt/_rels/presentation.xml.rels": `<Relationships/>`,
+	}
+	for i, text := range slides {
+		files[fmt.Sprintf("ppt/slides/slide%d.xml", i+1)] = `<sld><p><t>` + text + `</t></p></sld>`
+	}
+	for name, content := range files {
+		w, _ := zw.Create(name)
+		_, _ = w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip.Close() error = %v", err)
+	}
+}
+
+func TestReadFile_DocumentPages(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "deck.pptx")
+	writeTestPPTX(t, path, "Welcome", strings.Repeat("x", 80), "Summary")
+
+	for _, tool := range []interface {
+		Execute(context.Context, map[string]any) *ToolResult
+	}{
+		NewReadFileBytesTool(tmpDir, true, 100),
+		NewReadFileLinesTool(tmpDir, true, 100),
+	} {
+		result := tool.Execute(context.Background(), map[string]any{"path": "deck.pptx"})
+		if result.IsError {
+			t.Fatalf("Execute() error: %s", result.ForLLM)
+		}
+		if !strings.Contains(result.ForLLM, "document: pptx | read: slides 1 of 3") ||
+			!strings.Contains(result.ForLLM, `pages="2-3"`) ||
+			!strings.Contains(result.ForLLM, "--- slide 1 of 3: Welcome ---\nWelcome") {
+			t.Fatalf("Execute() = %s", result.ForLLM)
+		}
+
+		result = tool.Execute(context.Background(), map[string]any{"path": "deck.pptx", "pages": "3"})
+		if result.IsError || !strings.Contains(result.ForLLM, "Summary") ||
+			!strings.Contains(result.ForLLM, "END OF FILE") {
+			t.Fatalf("Execute(pages=3) = %s", result.ForLLM)
+		}
+
+		result = tool.Execute(context.Background(), map[string]any{"path": "deck.pptx", "pages": "9"})
+		if !result.IsError || !strings.Contains(result.ForLLM, "outside 1-3") {
+			t.Fatalf("Execute(pages=9) = %s", result.ForLLM)
+		}
+	}
+}
+
+func TestReadFile_PagesRejectedForText(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "notes.txt"), []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tool := NewReadFileLinesTool(tmpDir, true, MaxReadFileSize)
+	result := tool.Execute(context.Background(), map[string]any{"path": "notes.txt", "pages": "1"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "pages is only supported") {
+		t.Fatalf("Execute() = %s", result.ForLLM)
+	}
+}
@@ -69,7 +69,7 @@ func (t *EditFileTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 		return ErrorResult("new_text is required")
 	}
 
-	beforeContent, afterContent, err := editFile(t.fs, path, oldText, newText)
+	beforeContent, afterContent, err := editFile(ctx, t.fs, path, oldText, newText)
 	if err != nil {
 		return ErrorResult(err.Error())
 	}
@@ -124,7 +124,7 @@ func (t *AppendFileTool) Execute(ctx context.Context, args map[string]any) *Tool
 		return ErrorResult("content is required")
 	}
 
-	if err := appendFile(t.fs, path, content); err != nil {
+	if err := appendFile(ctx, t.fs, path, content); err != nil {
 		return ErrorResult(err.Error())
 	}
 	return SilentResult(fmt.Sprintf("Appended to %s", path))
@@ -132,7 +132,7 @@ func (t *AppendFileTool) Execute(ctx context.Context, args map[string]any) *Tool
 
 // editFile reads the file via sysFs, performs the replacement, and writes back.
 // It uses a fileSystem interface, allowing the same logic for both restricted and unrestricted modes.
-func editFile(sysFs fileSystem, path, oldText, newText string) ([]byte, []byte, error) {
+func editFile(ctx context.Context, sysFs fileSystem, path, oldText, newText string) ([]byte, []byte, error) {
 	content, err := sysFs.ReadFile(path)
 	if err != nil {
 		return nil, nil, err
@@ -143,6 +143,7 @@ func editFile(sysFs fileSystem, path, oldText, newText string) ([]byte, []byte,
 		return nil, nil, err
 	}
 
+	captureBeforeWrite(ctx, sysFs, path)
 	if err := sysFs.WriteFile(path, newContent); err != nil {
 		return nil, nil, err
 	}
@@ -151,13 +152,14 @@ func editFile(sysFs fileSystem, path, oldText, newText string) ([]byte, []byte,
 }
 
 // appendFile reads the existing content (if any) via sysFs, appends new content, and writes back.
-func appendFile(sysFs fileSystem, path, appendContent string) error {
+func appendFile(ctx context.Context, sysFs fileSystem, path, appendContent string) error {
 	content, err := sysFs.ReadFile(path)
 	if err != nil && !errors.Is(err, fs.ErrNotExist) {
 		return err
 	}
 
 	newContent := append(content, []byte(appendContent)...)
+	captureBeforeWrite(ctx, sysFs, path)
 	return sysFs.WriteFile(path, newContent)
 }
 
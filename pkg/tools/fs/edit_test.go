@@ -9,6 +9,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/checkpoint"
 )
 
 // TestEditTool_EditFile_Success verifies successful file editing
@@ -462,3 +465,36 @@ func TestEditFileTool_Restricted_FileNotFound(t *testing.T) {
 	assert.True(t, result.IsError)
 	assert.Contains(t, result.ForLLM, "not found")
 }
+
+// TestFileTools_CheckpointBeforeWrite verifies that write tools capture the
+// original content into the turn checkpoint before modifying a file.
+func TestFileTools_CheckpointBeforeWrite(t *testing.T) {
+	workspace := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "AGENT.md"), []byte("be nice"), 0o644))
+
+	store := checkpoint.NewStore(t.TempDir(), checkpoint.Options{})
+	rec := store.Begin(workspace, "s1", "turn", "")
+	ctx := checkpoint.WithRecorder(context.Background(), rec)
+
+	result := NewEditFileTool(workspace, true).Execute(ctx, map[string]any{
+		"path": "AGENT.md", "old_text": "nice", "new_text": "rude",
+	})
+	require.False(t, result.IsError, result.ForLLM)
+	result = NewWriteFileTool(workspace, true).Execute(ctx, map[string]any{
+		"path": "AGENT.md", "content": "overwritten", "overwrite": true,
+	})
+	require.False(t, result.IsError, result.ForLLM)
+	result = NewAppendFileTool(workspace, true).Execute(ctx, map[string]any{
+		"path": "new.txt", "content": "hello",
+	})
+	require.False(t, result.IsError, result.ForLLM)
+
+	restored, err := store.Restore(rec.ID())
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(workspace, "new.txt")}, restored.Removed)
+
+	data, err := os.ReadFile(filepath.Join(workspace, "AGENT.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "be nice", string(data))
+	assert.NoFileExists(t, filepath.Join(workspace, "new.txt"))
+}
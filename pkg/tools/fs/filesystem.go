@@ -18,6 +18,7 @@ import (
 	"time"
 	"unicode/utf8"
 
+	"github.com/sipeed/picoclaw/pkg/checkpoint"
 	"github.com/sipeed/picoclaw/pkg/fileutil"
 	"github.com/sipeed/picoclaw/pkg/logger"
 )
@@ -340,11 +341,13 @@ func (t *ReadFileLinesTool) Name() string {
 }
 
 func (t *ReadFileTool) Description() string {
-	return "Read the contents of a file. Supports pagination via `offset` and `length`."
+	return "Read the contents of a file. Supports pagination via `offset` and `length`. " +
+		"PDF, DOCX, XLSX, PPTX and EPUB files are returned as extracted text; select parts with `pages`."
 }
 
 func (t *ReadFileLinesTool) Description() string {
-	return "Read a UTF-8 text file from the filesystem. Output always includes line numbers in the format `LINE_NUMBER|LINE_CONTENT` (1-indexed). Supports partial reads via `start_line` and `max_lines` for large text files."
+	return "Read a UTF-8 text file from the filesystem. Output always includes line numbers in the format `LINE_NUMBER|LINE_CONTENT` (1-indexed). Supports partial reads via `start_line` and `max_lines` for large text files. " +
+		"PDF, DOCX, XLSX, PPTX and EPUB files are returned as extracted text; select parts with `pages`."
 }
 
 func (t *ReadFileTool) Parameters() map[string]any {
@@ -365,6 +368,7 @@ func (t *ReadFileTool) Parameters() map[string]any {
 				"description": "Maximum number of bytes to read.",
 				"default":     t.maxSize,
 			},
+			"pages": pagesParameter,
 		},
 		"required": []string{"path"},
 	}
@@ -387,6 +391,7 @@ func (t *ReadFileLinesTool) Parameters() map[string]any {
 				"type":        "integer",
 				"description": "Maximum number of lines to read.",
 			},
+			"pages": pagesParameter,
 		},
 		"required": []string{"path"},
 	}
@@ -419,6 +424,11 @@ func (t *ReadFileTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 		length = t.maxSize
 	}
 
+	pages, hasPages, err := pagesArg(args)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+
 	file, err := t.fs.Open(path)
 	if err != nil {
 		return ErrorResult(err.Error())
@@ -436,6 +446,15 @@ func (t *ReadFileTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 	sniff := make([]byte, 512)
 	sniffN, _ := file.Read(sniff)
 
+	if format := documentFormat(path, sniff[:sniffN]); format != "" {
+		if offset > 0 {
+			return ErrorResult("offset is not supported for documents; use pages")
+		}
+		return readDocument(io.MultiReader(bytes.NewReader(sniff[:sniffN]), file), path, format, pages, length)
+	} else if hasPages {
+		return ErrorResult("pages is only supported for PDF, DOCX, XLSX, PPTX and EPUB files; use offset and length")
+	}
+
 	// Reset read position to beginning before applying the caller's offset.
 	if seeker, ok := file.(io.Seeker); ok {
 		_, err = seeker.Seek(0, io.SeekStart)
@@ -556,6 +575,11 @@ func (t *ReadFileLinesTool) Execute(ctx context.Context, args map[string]any) *T
 		return ErrorResult("limit is not supported in line mode; use max_lines")
 	}
 
+	pages, hasPages, err := pagesArg(args)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+
 	limit := int64(-1)
 	if raw, exists := args["max_lines"]; exists && raw != nil {
 		limit, err = getInt64Arg(args, "max_lines", -1)
@@ -583,6 +607,14 @@ func (t *ReadFileLinesTool) Execute(ctx context.Context, args map[string]any) *T
 		return ErrorResult(fmt.Sprintf("failed to read file: %v", readErr))
 	}
 	sample = sample[:sampleN]
+	if format := documentFormat(path, sample); format != "" {
+		if startLine > 1 || limit > 0 {
+			return ErrorResult("start_line and max_lines are not supported for documents; use pages")
+		}
+		return readDocument(io.MultiReader(bytes.NewReader(sample), file), path, format, pages, t.maxSize)
+	} else if hasPages {
+		return ErrorResult("pages is only supported for PDF, DOCX, XLSX, PPTX and EPUB files; use start_line and max_lines")
+	}
 	if isBinaryReadFileData(sample) {
 		return ErrorResult("file appears to be binary; switch read_file mode to 'bytes' for byte-based inspection")
 	}
@@ -980,6 +1012,7 @@ func (t *WriteFileTool) Execute(ctx context.Context, args map[string]any) *ToolR
 		}
 	}
 
+	captureBeforeWrite(ctx, t.fs, path)
 	if err := t.fs.WriteFile(path, []byte(content)); err != nil {
 		return ErrorResult(err.Error())
 	}
@@ -1052,6 +1085,7 @@ type fileSystem interface {
 	WriteFile(path string, data []byte) error
 	ReadDir(path string) ([]os.DirEntry, error)
 	Open(path string) (fs.File, error)
+	Remove(path string) error
 }
 
 // hostFs is an unrestricted fileReadWriter that operates directly on the host filesystem.
@@ -1081,6 +1115,10 @@ func (h *hostFs) WriteFile(path string, data []byte) error {
 	return fileutil.WriteFileAtomic(path, data, 0o600)
 }
 
+func (h *hostFs) Remove(path string) error {
+	return os.Remove(path)
+}
+
 func (h *hostFs) Open(path string) (fs.File, error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -1209,6 +1247,12 @@ func (r *sandboxFs) ReadDir(path string) ([]os.DirEntry, error) {
 	return entries, err
 }
 
+func (r *sandboxFs) Remove(path string) error {
+	return r.execute(path, func(root *os.Root, relPath string) error {
+		return root.Remove(relPath)
+	})
+}
+
 func (r *sandboxFs) Open(path string) (fs.File, error) {
 	var f fs.File
 	err := r.execute(path, func(root *os.Root, relPath string) error {
@@ -1262,6 +1306,13 @@ func (w *whitelistFs) ReadDir(path string) ([]os.DirEntry, error) {
 	return w.sandbox.ReadDir(path)
 }
 
+func (w *whitelistFs) Remove(path string) error {
+	if w.matches(path) {
+		return w.host.Remove(path)
+	}
+	return w.sandbox.Remove(path)
+}
+
 func (w *whitelistFs) Open(path string) (fs.File, error) {
 	if w.matches(path) {
 		return w.host.Open(path)
@@ -1282,6 +1333,32 @@ func buildFs(workspace string, restrict bool, patterns []*regexp.Regexp) fileSys
 	return sandbox
 }
 
+// captureBeforeWrite snapshots path into the turn checkpoint carried by ctx,
+// if any. The file is read through sysFs so a restricted tool never captures
+// a file it could not read itself.
+func captureBeforeWrite(ctx context.Context, sysFs fileSystem, path string) {
+	rec := checkpoint.FromContext(ctx)
+	if rec == nil || rec.Has(path) {
+		return
+	}
+	data, err := sysFs.ReadFile(path)
+	switch {
+	case err == nil:
+		err = rec.Record(path, data, true)
+	case errors.Is(err, fs.ErrNotExist):
+		err = rec.Record(path, nil, false)
+	default:
+		// The write that follows reports the access error.
+		return
+	}
+	if err != nil {
+		logger.WarnCF("tool", "Failed to checkpoint file before write", map[string]any{
+			"path":  path,
+			"error": err.Error(),
+		})
+	}
+}
+
 func normalizeRootRelPath(relPath string) string {
 	return normalizeRootRelPathForSeparator(relPath, os.PathSeparator)
 }
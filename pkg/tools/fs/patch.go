@@ -0,0 +1,955 @@
+package fstools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// patchOp is the change a filePatch makes.
+type patchOp int
+
+const (
+	patchUpdate patchOp = iota
+	patchAdd
+	patchDelete
+)
+
+// filePatch is the parsed change to one file.
+type filePatch struct {
+	op   patchOp
+	path string
+	// newPath is set when the file is renamed.
+	newPath string
+	hunks   []patchHunk
+}
+
+// target is the path the file ends up at.
+func (p filePatch) target() string {
+	if p.newPath != "" {
+		return p.newPath
+	}
+	return p.path
+}
+
+// hunkLine is one line of a hunk; kind is ' ', '-' or '+'.
+type hunkLine struct {
+	kind byte
+	text string
+}
+
+type patchHunk struct {
+	// oldStart is the 1-based line the hunk starts at in the original file,
+	// or 0 when the format carries no line numbers.
+	oldStart int
+	// anchor is a line (e.g. a function signature) the hunk follows.
+	anchor string
+	// atEOF anchors the hunk at the end of the file.
+	atEOF bool
+	// noNewline is set when the result must not end with a newline.
+	noNewline bool
+	lines     []hunkLine
+}
+
+func (h patchHunk) side(kind byte) []string {
+	var out []string
+	for _, line := range h.lines {
+		if line.kind == ' ' || line.kind == kind {
+			out = append(out, line.text)
+		}
+	}
+	return out
+}
+
+func (h patchHunk) header() string {
+	if h.oldStart > 0 {
+		return fmt.Sprintf("@@ -%d @@", h.oldStart)
+	}
+	if h.anchor != "" {
+		return "@@ " + h.anchor
+	}
+	return "@@"
+}
+
+// parsePatch parses either a unified diff or the structured
+// "*** Begin Patch" format.
+func parsePatch(text string) ([]filePatch, error) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "*** ") {
+			return parseStructuredPatch(lines)
+		}
+		break
+	}
+	return parseUnifiedDiff(lines)
+}
+
+const (
+	structuredBegin  = "*** Begin Patch"
+	structuredEnd    = "*** End Patch"
+	structuredAdd    = "*** Add File: "
+	structuredDelete = "*** Delete File: "
+	structuredUpdate = "*** Update File: "
+	structuredMove   = "*** Move to: "
+	structuredEOF    = "*** End of File"
+)
+
+// parseStructuredPatch parses the envelope format:
+//
+//	*** Begin Patch
+//	*** Update File: path
+//	*** Move to: new/path        (optional)
+//	@@ optional anchor line
+//	 context
+//	-removed
+//	+added
+//	*** Add File: path
+//	+content
+//	*** Delete File: path
+//	*** End Patch
+func parseStructuredPatch(lines []string) ([]filePatch, error) {
+	var patches []filePatch
+	var cur *filePatch
+	var hunk *patchHunk
+
+	flushHunk := func() {
+		if cur != nil && hunk != nil && len(hunk.lines) > 0 {
+			cur.hunks = append(cur.hunks, *hunk)
+		}
+		hunk = nil
+	}
+	flushFile := func() {
+		flushHunk()
+		if cur != nil {
+			patches = append(patches, *cur)
+		}
+		cur = nil
+	}
+
+	for i, line := range lines {
+		switch {
+		case strings.TrimSpace(line) == structuredBegin:
+			continue
+		case strings.TrimSpace(line) == structuredEnd:
+			flushFile()
+			return patches, validatePatches(patches)
+		case strings.HasPrefix(line, structuredAdd):
+			flushFile()
+			cur = &filePatch{op: patchAdd, path: strings.TrimSpace(line[len(structuredAdd):])}
+			hunk = &patchHunk{}
+		case strings.HasPrefix(line, structuredDelete):
+			flushFile()
+			cur = &filePatch{op: patchDelete, path: strings.TrimSpace(line[len(structuredDelete):])}
+		case strings.HasPrefix(line, structuredUpdate):
+			flushFile()
+			cur = &filePatch{op: patchUpdate, path: strings.TrimSpace(line[len(structuredUpdate):])}
+		case strings.HasPrefix(line, structuredMove):
+			if cur == nil || cur.op != patchUpdate {
+				return nil, fmt.Errorf("line %d: %q must follow an Update File header", i+1, structuredMove)
+			}
+			cur.newPath = strings.TrimSpace(line[len(structuredMove):])
+		case strings.TrimSpace(line) == structuredEOF:
+			if hunk == nil {
+				return nil, fmt.Errorf("line %d: %q outside a hunk", i+1, structuredEOF)
+			}
+			hunk.atEOF = true
+		case cur == nil:
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: expected a file header, got %q", i+1, line)
+		case cur.op == patchDelete:
+			if strings.TrimSpace(line) != "" {
+				return nil, fmt.Errorf("line %d: Delete File takes no content", i+1)
+			}
+		case cur.op == patchAdd:
+			if !strings.HasPrefix(line, "+") {
+				return nil, fmt.Errorf("line %d: Add File content lines must start with '+'", i+1)
+			}
+			hunk.lines = append(hunk.lines, hunkLine{kind: '+', text: line[1:]})
+		case strings.HasPrefix(line, "@@"):
+			flushHunk()
+			hunk = &patchHunk{anchor: strings.TrimSpace(strings.TrimPrefix(line, "@@"))}
+		default:
+			if hunk == nil {
+				hunk = &patchHunk{}
+			}
+			hl, err := parseHunkLine(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			hunk.lines = append(hunk.lines, hl)
+		}
+	}
+	flushFile()
+	return patches, validatePatches(patches)
+}
+
+// parseUnifiedDiff parses `diff -u` / `git diff` output, including new,
+// deleted and renamed files.
+func parseUnifiedDiff(lines []string) ([]filePatch, error) {
+	var patches []filePatch
+	var cur *filePatch
+	var hunk *patchHunk
+	var renameFrom string
+	// oldLeft and newLeft count the lines the current hunk header announced
+	// and that have not been read yet. While any remain, "--- " and "+++ "
+	// lines are hunk content rather than the next file's header.
+	var oldLeft, newLeft int
+
+	flushHunk := func() {
+		if cur != nil && hunk != nil {
+			cur.hunks = append(cur.hunks, *hunk)
+		}
+		hunk = nil
+		oldLeft, newLeft = 0, 0
+	}
+	flushFile := func() {
+		flushHunk()
+		if cur != nil {
+			patches = append(patches, *cur)
+		}
+		cur = nil
+		renameFrom = ""
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case hunk != nil && (oldLeft > 0 || newLeft > 0) && (line == "" || strings.ContainsRune(" -+", rune(line[0]))):
+			hl, err := parseHunkLine(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			hunk.lines = append(hunk.lines, hl)
+			if hl.kind != '+' {
+				oldLeft--
+			}
+			if hl.kind != '-' {
+				newLeft--
+			}
+		case strings.HasPrefix(line, "diff "):
+			flushFile()
+			cur = &filePatch{op: patchUpdate}
+			if oldPath, newPath, ok := parseGitDiffHeader(line); ok {
+				cur.path = oldPath
+				if newPath != oldPath {
+					cur.newPath = newPath
+				}
+			}
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			if cur == nil || len(cur.hunks) > 0 || hunk != nil {
+				flushFile()
+				cur = &filePatch{op: patchUpdate}
+			}
+			oldPath := unifiedPath(line[4:], "a/")
+			newPath := unifiedPath(lines[i+1][4:], "b/")
+			i++
+			switch {
+			case oldPath == "":
+				cur.op, cur.path, cur.newPath = patchAdd, newPath, ""
+			case newPath == "":
+				cur.op, cur.path, cur.newPath = patchDelete, oldPath, ""
+			default:
+				cur.path = oldPath
+				cur.newPath = ""
+				if newPath != oldPath {
+					cur.newPath = newPath
+				}
+			}
+		case strings.HasPrefix(line, "@@"):
+			if cur == nil || cur.path == "" {
+				return nil, fmt.Errorf("line %d: hunk without a file header", i+1)
+			}
+			flushHunk()
+			start, oldCount, newCount, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			hunk = &patchHunk{oldStart: start}
+			oldLeft, newLeft = oldCount, newCount
+		case hunk != nil && strings.HasPrefix(line, `\`):
+			// "\ No newline at end of file" refers to the previous line.
+			if n := len(hunk.lines); n > 0 && hunk.lines[n-1].kind != '-' {
+				hunk.noNewline = true
+			}
+		case hunk != nil && (line == "" || strings.ContainsRune(" -+", rune(line[0]))):
+			hl, err := parseHunkLine(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			hunk.lines = append(hunk.lines, hl)
+		case cur != nil && strings.HasPrefix(line, "new file mode"):
+			cur.op = patchAdd
+		case cur != nil && strings.HasPrefix(line, "deleted file mode"):
+			cur.op = patchDelete
+		case cur != nil && strings.HasPrefix(line, "rename from "):
+			renameFrom = strings.TrimSpace(strings.TrimPrefix(line, "rename from "))
+			cur.path = renameFrom
+		case cur != nil && strings.HasPrefix(line, "rename to "):
+			if to := strings.TrimSpace(strings.TrimPrefix(line, "rename to ")); to != renameFrom {
+				cur.newPath = to
+			}
+		default:
+			// index lines, similarity, mode changes and free text between
+			// files carry nothing we apply.
+			flushHunk()
+		}
+	}
+	flushFile()
+
+	if len(patches) == 0 {
+		return nil, fmt.Errorf("no file changes found; expected a unified diff or a *** Begin Patch block")
+	}
+	for i := range patches {
+		if patches[i].op == patchDelete {
+			patches[i].hunks = nil
+		}
+	}
+	return patches, validatePatches(patches)
+}
+
+func parseHunkLine(line string) (hunkLine, error) {
+	if line == "" {
+		// Editors and models often strip the space of empty context lines.
+		return hunkLine{kind: ' '}, nil
+	}
+	switch line[0] {
+	case ' ', '-', '+':
+		return hunkLine{kind: line[0], text: line[1:]}, nil
+	default:
+		return hunkLine{}, fmt.Errorf("hunk lines must start with ' ', '-' or '+', got %q", line)
+	}
+}
+
+// parseGitDiffHeader reads the paths of "diff --git a/x b/y".
+func parseGitDiffHeader(line string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(line, "diff --git ")
+	if !ok {
+		return "", "", false
+	}
+	if !strings.HasPrefix(rest, "a/") {
+		return "", "", false
+	}
+	idx := strings.LastIndex(rest, " b/")
+	if idx < 0 {
+		return "", "", false
+	}
+	return rest[2:idx], rest[idx+3:], true
+}
+
+// unifiedPath cleans a ---/+++ path, returning "" for /dev/null.
+func unifiedPath(raw, prefix string) string {
+	if tab := strings.IndexByte(raw, '\t'); tab >= 0 {
+		raw = raw[:tab]
+	}
+	raw = strings.TrimSpace(raw)
+	if raw == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(raw, prefix)
+}
+
+// parseHunkHeader reads "@@ -l,s +l,s @@" and returns the old start line
+// and the old and new line counts. For a pure insertion (old count 0) the
+// position is the line after which to insert, so it is shifted to point at
+// the next line. When either count is unreadable both are returned as 0,
+// leaving the end of the hunk to the line prefixes.
+func parseHunkHeader(line string) (start, oldCount, newCount int, err error) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") {
+		return 0, 0, 0, fmt.Errorf("malformed hunk header %q", line)
+	}
+	start, oldCount, ok := parseHunkRange(strings.TrimPrefix(fields[1], "-"))
+	if !ok {
+		return 0, 0, 0, fmt.Errorf("malformed hunk header %q", line)
+	}
+	if oldCount == 0 {
+		start++
+	}
+	newCount = -1
+	if newRange, isNew := strings.CutPrefix(fields[2], "+"); isNew {
+		_, newCount, _ = parseHunkRange(newRange)
+	}
+	if oldCount < 0 || newCount < 0 {
+		oldCount, newCount = 0, 0
+	}
+	return max(start, 1), oldCount, newCount, nil
+}
+
+// parseHunkRange reads "l,s" or "l", whose count is 1. The count is -1 when
+// it is unreadable; ok is false when the line number is.
+func parseHunkRange(r string) (start, count int, ok bool) {
+	startStr, countStr, hasCount := strings.Cut(r, ",")
+	start, err := strconv.Atoi(startStr)
+	if err != nil {
+		return 0, -1, false
+	}
+	if !hasCount {
+		return start, 1, true
+	}
+	if count, err = strconv.Atoi(countStr); err != nil || count < 0 {
+		return start, -1, true
+	}
+	return start, count, true
+}
+
+func validatePatches(patches []filePatch) error {
+	if len(patches) == 0 {
+		return fmt.Errorf("patch contains no file changes")
+	}
+	seen := make(map[string]bool)
+	for _, p := range patches {
+		if p.path == "" {
+			return fmt.Errorf("patch has a file change without a path")
+		}
+		for _, path := range []string{p.path, p.newPath} {
+			if path == "" {
+				continue
+			}
+			if seen[path] {
+				return fmt.Errorf("%s: file appears more than once in the patch", path)
+			}
+			seen[path] = true
+		}
+		if p.op == patchUpdate && len(p.hunks) == 0 && p.newPath == "" {
+			return fmt.Errorf("%s: update has no hunks", p.path)
+		}
+	}
+	return nil
+}
+
+// textFile is a file split into lines with its line ending style preserved.
+type textFile struct {
+	lines       []string
+	crlf        bool
+	trailingEOL bool
+}
+
+func splitTextFile(content string) textFile {
+	f := textFile{crlf: strings.Contains(content, "\r\n")}
+	if f.crlf {
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+	}
+	if content == "" {
+		return f
+	}
+	f.trailingEOL = strings.HasSuffix(content, "\n")
+	f.lines = strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	return f
+}
+
+func (f textFile) String() string {
+	if len(f.lines) == 0 {
+		return ""
+	}
+	eol := "\n"
+	if f.crlf {
+		eol = "\r\n"
+	}
+	out := strings.Join(f.lines, eol)
+	if f.trailingEOL {
+		out += eol
+	}
+	return out
+}
+
+// hunkResult records where a hunk landed and how much fuzz it needed.
+type hunkResult struct {
+	line int
+	fuzz int
+}
+
+// applyHunks applies hunks in order. Each hunk is looked up near its expected
+// line, first exactly and then with increasing tolerance: trailing
+// whitespace, surrounding whitespace, and finally up to maxFuzz context lines
+// dropped from either end of the hunk.
+func applyHunks(f textFile, hunks []patchHunk, maxFuzz int) (textFile, []hunkResult, error) {
+	lines := append([]string(nil), f.lines...)
+	results := make([]hunkResult, 0, len(hunks))
+	from := 0
+	delta := 0
+
+	for i, h := range hunks {
+		if h.anchor != "" {
+			idx := findAnchor(lines, h.anchor, from)
+			if idx < 0 {
+				return f, nil, fmt.Errorf("hunk %d (%s): anchor line not found", i+1, h.header())
+			}
+			from = idx + 1
+		}
+
+		hint := -1
+		if h.oldStart > 0 {
+			hint = h.oldStart - 1 + delta
+		}
+
+		pos, trimmed, fuzz, ok := locateHunk(lines, h, from, hint, maxFuzz)
+		if !ok {
+			return f, nil, fmt.Errorf("hunk %d (%s) does not match the file%s", i+1, h.header(), hunkExcerpt(h))
+		}
+
+		oldLines := trimmed.side('-')
+		newLines := trimmed.side('+')
+		updated := make([]string, 0, len(lines)-len(oldLines)+len(newLines))
+		updated = append(updated, lines[:pos]...)
+		updated = append(updated, newLines...)
+		updated = append(updated, lines[pos+len(oldLines):]...)
+		atEnd := pos+len(oldLines) == len(lines)
+		lines = updated
+
+		switch {
+		case atEnd && h.oldStart > 0:
+			// Unified diffs mark a missing final newline explicitly.
+			f.trailingEOL = !h.noNewline
+		case atEnd && len(f.lines) == 0:
+			f.trailingEOL = !h.noNewline
+		}
+		results = append(results, hunkResult{line: pos + 1, fuzz: fuzz})
+		from = pos + len(newLines)
+		delta += len(newLines) - len(oldLines)
+	}
+
+	f.lines = lines
+	return f, results, nil
+}
+
+// locateHunk finds where hunk h applies, returning the position, the hunk
+// with any context lines that had to be dropped removed, and the fuzz used.
+func locateHunk(lines []string, h patchHunk, from, hint, maxFuzz int) (int, patchHunk, int, bool) {
+	for trim := 0; trim <= maxFuzz; trim++ {
+		trimmed, ok := trimHunkContext(h, trim)
+		if !ok {
+			break
+		}
+		old := trimmed.side('-')
+		if len(old) == 0 {
+			return insertionPoint(lines, trimmed, from, hint), trimmed, trim, true
+		}
+		for level := 0; level < 3; level++ {
+			if pos := findLines(lines, old, from, hint, level, trimmed.atEOF); pos >= 0 {
+				return pos, trimmed, trim + level, true
+			}
+		}
+	}
+	return 0, h, 0, false
+}
+
+// trimHunkContext drops up to n context lines from each end of the hunk.
+func trimHunkContext(h patchHunk, n int) (patchHunk, bool) {
+	if n == 0 {
+		return h, true
+	}
+	lines := h.lines
+	dropped := false
+	for i := 0; i < n && len(lines) > 0 && lines[0].kind == ' '; i++ {
+		lines = lines[1:]
+		dropped = true
+		if h.oldStart > 0 {
+			h.oldStart++
+		}
+	}
+	for i := 0; i < n && len(lines) > 0 && lines[len(lines)-1].kind == ' '; i++ {
+		lines = lines[:len(lines)-1]
+		dropped = true
+	}
+	// Never reduce a hunk to bare additions by fuzzing away all context.
+	hasOld := false
+	for _, line := range lines {
+		if line.kind == '-' || line.kind == ' ' {
+			hasOld = true
+			break
+		}
+	}
+	if !dropped || !hasOld {
+		return h, false
+	}
+	h.lines = lines
+	return h, true
+}
+
+func insertionPoint(lines []string, h patchHunk, from, hint int) int {
+	switch {
+	case h.atEOF:
+		return len(lines)
+	case hint >= 0:
+		return max(from, min(hint, len(lines)))
+	default:
+		return len(lines)
+	}
+}
+
+// findLines returns the start of old in lines at or after from, preferring the
+// match closest to hint (or the first one when there is no hint).
+func findLines(lines, old []string, from, hint, level int, atEOF bool) int {
+	best := -1
+	last := len(lines) - len(old)
+	if atEOF {
+		if last >= from && linesEqual(lines[last:], old, level) {
+			return last
+		}
+		return -1
+	}
+	for pos := from; pos <= last; pos++ {
+		if !linesEqual(lines[pos:pos+len(old)], old, level) {
+			continue
+		}
+		if hint < 0 {
+			return pos
+		}
+		if best < 0 || abs(pos-hint) < abs(best-hint) {
+			best = pos
+		}
+		if pos >= hint {
+			break
+		}
+	}
+	return best
+}
+
+func linesEqual(a, b []string, level int) bool {
+	for i := range b {
+		if normalizePatchLine(a[i], level) != normalizePatchLine(b[i], level) {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizePatchLine(line string, level int) string {
+	switch level {
+	case 0:
+		return line
+	case 1:
+		return strings.TrimRight(line, " \t")
+	default:
+		return strings.TrimSpace(line)
+	}
+}
+
+func findAnchor(lines []string, anchor string, from int) int {
+	anchor = strings.TrimSpace(anchor)
+	for i := from; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == anchor {
+			return i
+		}
+	}
+	for i := from; i < len(lines); i++ {
+		if strings.Contains(lines[i], anchor) {
+			return i
+		}
+	}
+	return -1
+}
+
+// hunkExcerpt quotes the first lines a hunk expects so the model can see what
+// did not match.
+func hunkExcerpt(h patchHunk) string {
+	old := h.side('-')
+	if len(old) == 0 {
+		return ""
+	}
+	if len(old) > 3 {
+		old = old[:3]
+	}
+	return "; expected lines:\n" + strings.Join(old, "\n")
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+const (
+	maxPatchBytes     = 1024 * 1024
+	maxPatchFiles     = 100
+	defaultPatchFuzz  = 2
+	maxPatchFuzzLimit = 3
+)
+
+// ApplyPatchTool applies a unified diff or a structured multi-file patch.
+// Every hunk is validated before anything is written, and a failed write
+// rolls back the files already changed.
+type ApplyPatchTool struct {
+	fs fileSystem
+}
+
+// NewApplyPatchTool creates a new ApplyPatchTool with optional directory restriction.
+func NewApplyPatchTool(workspace string, restrict bool, allowPaths ...[]*regexp.Regexp) *ApplyPatchTool {
+	var patterns []*regexp.Regexp
+	if len(allowPaths) > 0 {
+		patterns = allowPaths[0]
+	}
+	return &ApplyPatchTool{fs: buildFs(workspace, restrict, patterns)}
+}
+
+func (t *ApplyPatchTool) Name() string {
+	return "apply_patch"
+}
+
+func (t *ApplyPatchTool) Description() string {
+	return "Apply a patch to one or more files atomically: either every change applies or none does. " +
+		"Accepts a unified diff (diff -u / git diff, including new, deleted and renamed files) or the structured format:\n" +
+		"*** Begin Patch\n*** Update File: path\n*** Move to: new/path (optional)\n@@ optional anchor line\n context\n-removed\n+added\n" +
+		"*** Add File: path\n+line\n*** Delete File: path\n*** End Patch\n" +
+		"Hunks are matched near their stated position with tolerance for whitespace and small context drift. Prefer this over repeated edit_file calls for multi-hunk or multi-file changes."
+}
+
+func (t *ApplyPatchTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"patch": map[string]any{
+				"type":        "string",
+				"description": "The patch text, as a unified diff or a *** Begin Patch block",
+			},
+			"fuzz": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Context lines a hunk may drop from each end to still match (default %d, max %d)", defaultPatchFuzz, maxPatchFuzzLimit),
+			},
+		},
+		"required": []string{"patch"},
+	}
+}
+
+func (t *ApplyPatchTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	text, ok := args["patch"].(string)
+	if !ok || strings.TrimSpace(text) == "" {
+		return ErrorResult("patch is required")
+	}
+	if len(text) > maxPatchBytes {
+		return ErrorResult(fmt.Sprintf("patch is too large (%d bytes, max %d)", len(text), maxPatchBytes))
+	}
+	fuzz, err := getInt64Arg(args, "fuzz", defaultPatchFuzz)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	if fuzz < 0 || fuzz > maxPatchFuzzLimit {
+		return ErrorResult(fmt.Sprintf("fuzz must be between 0 and %d", maxPatchFuzzLimit))
+	}
+
+	patches, err := parsePatch(text)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("invalid patch: %v", err))
+	}
+	if len(patches) > maxPatchFiles {
+		return ErrorResult(fmt.Sprintf("patch touches too many files (%d, max %d)", len(patches), maxPatchFiles))
+	}
+
+	changes, err := planPatch(t.fs, patches, int(fuzz))
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("patch not applied: %v", err))
+	}
+	if err := ctx.Err(); err != nil {
+		return ErrorResult(fmt.Sprintf("patch not applied: %v", err))
+	}
+	if err := commitPatch(ctx, t.fs, changes); err != nil {
+		return ErrorResult(fmt.Sprintf("patch not applied: %v", err))
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Applied patch to %d file(s):\n", len(changes))
+	for _, c := range changes {
+		sb.WriteString(c.summary())
+		sb.WriteByte('\n')
+	}
+	return SilentResult(strings.TrimRight(sb.String(), "\n"))
+}
+
+// fileChange is a validated change, ready to be written.
+type fileChange struct {
+	patch    filePatch
+	before   []byte
+	after    []byte
+	added    int
+	removed  int
+	fuzzNote []string
+}
+
+func (c fileChange) summary() string {
+	p := c.patch
+	switch {
+	case p.op == patchAdd:
+		return fmt.Sprintf("A %s (+%d)", p.path, c.added)
+	case p.op == patchDelete:
+		return fmt.Sprintf("D %s", p.path)
+	}
+	line := fmt.Sprintf("M %s (+%d -%d)", p.path, c.added, c.removed)
+	if p.newPath != "" {
+		line = fmt.Sprintf("R %s -> %s (+%d -%d)", p.path, p.newPath, c.added, c.removed)
+	}
+	if len(c.fuzzNote) > 0 {
+		line += " [" + strings.Join(c.fuzzNote, "; ") + "]"
+	}
+	return line
+}
+
+// planPatch checks every file and hunk against the current tree and computes
+// the resulting contents without writing anything.
+func planPatch(sysFs fileSystem, patches []filePatch, fuzz int) ([]fileChange, error) {
+	changes := make([]fileChange, 0, len(patches))
+	for _, p := range patches {
+		c := fileChange{patch: p}
+		switch p.op {
+		case patchAdd:
+			if err := requireAbsent(sysFs, p.path); err != nil {
+				return nil, err
+			}
+			var lines []string
+			noNewline := false
+			for _, h := range p.hunks {
+				lines = append(lines, h.side('+')...)
+				noNewline = noNewline || h.noNewline
+			}
+			f := textFile{lines: lines, trailingEOL: len(lines) > 0 && !noNewline}
+			c.after = []byte(f.String())
+			c.added = len(lines)
+
+		case patchDelete:
+			before, err := sysFs.ReadFile(p.path)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", p.path, err)
+			}
+			c.before = before
+			c.removed = len(splitTextFile(string(before)).lines)
+
+		case patchUpdate:
+			before, err := sysFs.ReadFile(p.path)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", p.path, err)
+			}
+			if isBinaryReadFileData(before) {
+				return nil, fmt.Errorf("%s: cannot patch a binary file", p.path)
+			}
+			if p.newPath != "" {
+				if err := requireAbsent(sysFs, p.newPath); err != nil {
+					return nil, err
+				}
+			}
+			result, hunkResults, err := applyHunks(splitTextFile(string(before)), p.hunks, fuzz)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", p.path, err)
+			}
+			c.before = before
+			c.after = []byte(result.String())
+			for _, h := range p.hunks {
+				for _, line := range h.lines {
+					switch line.kind {
+					case '+':
+						c.added++
+					case '-':
+						c.removed++
+					}
+				}
+			}
+			for i, r := range hunkResults {
+				if r.fuzz > 0 {
+					c.fuzzNote = append(c.fuzzNote, fmt.Sprintf("hunk %d at line %d with fuzz %d", i+1, r.line, r.fuzz))
+				}
+			}
+		}
+		changes = append(changes, c)
+	}
+	return changes, nil
+}
+
+func requireAbsent(sysFs fileSystem, path string) error {
+	_, err := sysFs.ReadFile(path)
+	switch {
+	case err == nil:
+		return fmt.Errorf("%s: file already exists", path)
+	case errors.Is(err, fs.ErrNotExist):
+		return nil
+	default:
+		return fmt.Errorf("%s: %w", path, err)
+	}
+}
+
+// patchUndo restores one path to its state before the patch.
+type patchUndo struct {
+	path     string
+	original []byte
+	existed  bool
+}
+
+// commitPatch writes the planned changes, restoring every touched path if any
+// write fails part way through.
+func commitPatch(ctx context.Context, sysFs fileSystem, changes []fileChange) error {
+	for _, c := range changes {
+		captureBeforeWrite(ctx, sysFs, c.patch.path)
+		if c.patch.newPath != "" {
+			captureBeforeWrite(ctx, sysFs, c.patch.newPath)
+		}
+	}
+
+	var undo []patchUndo
+	rollback := func(cause error) error {
+		var failed []string
+		for i := len(undo) - 1; i >= 0; i-- {
+			u := undo[i]
+			var err error
+			if u.existed {
+				err = sysFs.WriteFile(u.path, u.original)
+			} else {
+				err = sysFs.Remove(u.path)
+			}
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				failed = append(failed, u.path)
+			}
+		}
+		if len(failed) > 0 {
+			logger.ErrorCF("tool", "apply_patch rollback incomplete", map[string]any{
+				"paths": failed,
+				"error": cause.Error(),
+			})
+			return fmt.Errorf("%w (rollback failed for %s)", cause, strings.Join(failed, ", "))
+		}
+		return cause
+	}
+
+	for _, c := range changes {
+		p := c.patch
+		switch p.op {
+		case patchAdd:
+			undo = append(undo, patchUndo{path: p.path})
+			if err := sysFs.WriteFile(p.path, c.after); err != nil {
+				return rollback(fmt.Errorf("%s: %w", p.path, err))
+			}
+		case patchDelete:
+			undo = append(undo, patchUndo{path: p.path, original: c.before, existed: true})
+			if err := sysFs.Remove(p.path); err != nil {
+				return rollback(fmt.Errorf("%s: %w", p.path, err))
+			}
+		case patchUpdate:
+			if p.newPath == "" {
+				undo = append(undo, patchUndo{path: p.path, original: c.before, existed: true})
+				if err := sysFs.WriteFile(p.path, c.after); err != nil {
+					return rollback(fmt.Errorf("%s: %w", p.path, err))
+				}
+				continue
+			}
+			undo = append(undo, patchUndo{path: p.newPath})
+			if err := sysFs.WriteFile(p.newPath, c.after); err != nil {
+				return rollback(fmt.Errorf("%s: %w", p.newPath, err))
+			}
+			undo = append(undo, patchUndo{path: p.path, original: c.before, existed: true})
+			if err := sysFs.Remove(p.path); err != nil {
+				return rollback(fmt.Errorf("%s: %w", p.path, err))
+			}
+		}
+	}
+	return nil
+}
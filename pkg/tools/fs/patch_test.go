@@ -0,0 +1,306 @@
+package fstools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readFixture(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestApplyPatchTool_UnifiedDiffMultiFile(t *testing.T) {
+	workspace := t.TempDir()
+	writeSearchFixture(t, workspace, map[string]string{
+		"a.go":   "package a\n\nfunc A() int {\n\treturn 1\n}\n",
+		"b.txt":  "one\ntwo\nthree\n",
+		"old.md": "# Old\nbody\n",
+		"gone":   "bye\n",
+	})
+
+	patch := `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -3,3 +3,3 @@
+ func A() int {
+-	return 1
++	return 2
+ }
+--- a/b.txt
++++ b/b.txt
+@@ -1,3 +1,4 @@
+ one
++one and a half
+ two
+ three
+diff --git a/new.txt b/new.txt
+new file mode 100644
+--- /dev/null
++++ b/new.txt
+@@ -0,0 +1,2 @@
++hello
++world
+diff --git a/gone b/gone
+deleted file mode 100644
+--- a/gone
++++ /dev/null
+@@ -1 +0,0 @@
+-bye
+diff --git a/old.md b/docs/new.md
+similarity index 80%
+rename from old.md
+rename to docs/new.md
+--- a/old.md
++++ b/docs/new.md
+@@ -1,2 +1,2 @@
+-# Old
++# New
+ body
+`
+
+	tool := NewApplyPatchTool(workspace, true)
+	result := tool.Execute(context.Background(), map[string]any{"patch": patch})
+	require.False(t, result.IsError, result.ForLLM)
+
+	assert.Equal(t, "package a\n\nfunc A() int {\n\treturn 2\n}\n", readFixture(t, workspace, "a.go"))
+	assert.Equal(t, "one\none and a half\ntwo\nthree\n", readFixture(t, workspace, "b.txt"))
+	assert.Equal(t, "hello\nworld\n", readFixture(t, workspace, "new.txt"))
+	assert.Equal(t, "# New\nbody\n", readFixture(t, workspace, "docs/new.md"))
+	assert.NoFileExists(t, filepath.Join(workspace, "gone"))
+	assert.NoFileExists(t, filepath.Join(workspace, "old.md"))
+
+	assert.Contains(t, result.ForLLM, "Applied patch to 5 file(s)")
+	assert.Contains(t, result.ForLLM, "M a.go (+1 -1)")
+	assert.Contains(t, result.ForLLM, "A new.txt (+2)")
+	assert.Contains(t, result.ForLLM, "D gone")
+	assert.Contains(t, result.ForLLM, "R old.md -> docs/new.md (+1 -1)")
+}
+
+func TestApplyPatchTool_HunkLinesThatLookLikeFileHeaders(t *testing.T) {
+	workspace := t.TempDir()
+	writeSearchFixture(t, workspace, map[string]string{
+		"q.sql":   "select 1;\n-- old note\nselect 2;\n",
+		"next.md": "a\n",
+	})
+
+	// Removing "-- old note" and adding "++ new note" produce lines that
+	// read as a "--- " / "+++ " file header pair.
+	patch := `--- a/q.sql
++++ b/q.sql
+@@ -1,3 +1,3 @@
+ select 1;
+--- old note
++++ new note
+ select 2;
+--- a/next.md
++++ b/next.md
+@@ -1 +1 @@
+-a
++b
+`
+	tool := NewApplyPatchTool(workspace, true)
+	result := tool.Execute(context.Background(), map[string]any{"patch": patch})
+	require.False(t, result.IsError, result.ForLLM)
+
+	assert.Equal(t, "select 1;\n++ new note\nselect 2;\n", readFixture(t, workspace, "q.sql"))
+	assert.Equal(t, "b\n", readFixture(t, workspace, "next.md"))
+	assert.NoFileExists(t, filepath.Join(workspace, "old note"))
+}
+
+func TestApplyPatchTool_StructuredFormat(t *testing.T) {
+	workspace := t.TempDir()
+	writeSearchFixture(t, workspace, map[string]string{
+		"main.go": "package main\n\nfunc a() {\n\tx := 1\n}\n\nfunc b() {\n\tx := 1\n}\n",
+		"rm.txt":  "x\n",
+		"mv.txt":  "keep\n",
+	})
+
+	patch := `*** Begin Patch
+*** Update File: main.go
+@@ func b() {
+-	x := 1
++	x := 2
+*** Add File: dir/added.txt
++first
++second
+*** Delete File: rm.txt
+*** Update File: mv.txt
+*** Move to: moved.txt
+*** End Patch`
+
+	tool := NewApplyPatchTool(workspace, true)
+	result := tool.Execute(context.Background(), map[string]any{"patch": patch})
+	require.False(t, result.IsError, result.ForLLM)
+
+	// The anchor selects the second occurrence.
+	assert.Equal(t, "package main\n\nfunc a() {\n\tx := 1\n}\n\nfunc b() {\n\tx := 2\n}\n", readFixture(t, workspace, "main.go"))
+	assert.Equal(t, "first\nsecond\n", readFixture(t, workspace, "dir/added.txt"))
+	assert.Equal(t, "keep\n", readFixture(t, workspace, "moved.txt"))
+	assert.NoFileExists(t, filepath.Join(workspace, "rm.txt"))
+	assert.NoFileExists(t, filepath.Join(workspace, "mv.txt"))
+}
+
+func TestApplyPatchTool_FuzzAndOffset(t *testing.T) {
+	workspace := t.TempDir()
+	writeSearchFixture(t, workspace, map[string]string{
+		"f.txt": "header\nextra\nalpha\nbeta  \ngamma\ndelta changed\n",
+	})
+
+	// Line numbers are off by one, "beta" has trailing spaces in the file and
+	// the last context line no longer matches.
+	patch := `--- a/f.txt
++++ b/f.txt
+@@ -2,4 +2,4 @@
+ alpha
+-beta
++BETA
+ gamma
+ delta
+`
+	tool := NewApplyPatchTool(workspace, true)
+
+	result := tool.Execute(context.Background(), map[string]any{"patch": patch, "fuzz": 0})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.ForLLM, "f.txt: hunk 1")
+
+	result = tool.Execute(context.Background(), map[string]any{"patch": patch})
+	require.False(t, result.IsError, result.ForLLM)
+	assert.Equal(t, "header\nextra\nalpha\nBETA\ngamma\ndelta changed\n", readFixture(t, workspace, "f.txt"))
+	assert.Contains(t, result.ForLLM, "with fuzz")
+}
+
+func TestApplyPatchTool_PreservesCRLFAndMissingNewline(t *testing.T) {
+	workspace := t.TempDir()
+	writeSearchFixture(t, workspace, map[string]string{"w.txt": "a\r\nb\r\nc"})
+
+	patch := `--- a/w.txt
++++ b/w.txt
+@@ -1,3 +1,3 @@
+ a
+-b
++B
+ c
+\ No newline at end of file
+`
+	tool := NewApplyPatchTool(workspace, true)
+	result := tool.Execute(context.Background(), map[string]any{"patch": patch})
+	require.False(t, result.IsError, result.ForLLM)
+	assert.Equal(t, "a\r\nB\r\nc", readFixture(t, workspace, "w.txt"))
+}
+
+func TestApplyPatchTool_ValidatesBeforeWriting(t *testing.T) {
+	workspace := t.TempDir()
+	writeSearchFixture(t, workspace, map[string]string{
+		"ok.txt":  "one\ntwo\n",
+		"bad.txt": "red\ngreen\n",
+	})
+
+	patch := `*** Begin Patch
+*** Update File: ok.txt
+-one
++ONE
+*** Add File: created.txt
++new
+*** Update File: bad.txt
+-blue
++BLUE
+*** End Patch`
+
+	tool := NewApplyPatchTool(workspace, true)
+	result := tool.Execute(context.Background(), map[string]any{"patch": patch})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.ForLLM, "bad.txt: hunk 1")
+
+	assert.Equal(t, "one\ntwo\n", readFixture(t, workspace, "ok.txt"))
+	assert.NoFileExists(t, filepath.Join(workspace, "created.txt"))
+}
+
+func TestApplyPatchTool_RejectsConflicts(t *testing.T) {
+	workspace := t.TempDir()
+	writeSearchFixture(t, workspace, map[string]string{"exists.txt": "x\n"})
+	tool := NewApplyPatchTool(workspace, true)
+
+	tests := []struct {
+		name  string
+		patch string
+		want  string
+	}{
+		{
+			name:  "add existing file",
+			patch: "*** Begin Patch\n*** Add File: exists.txt\n+y\n*** End Patch",
+			want:  "already exists",
+		},
+		{
+			name:  "delete missing file",
+			patch: "*** Begin Patch\n*** Delete File: missing.txt\n*** End Patch",
+			want:  "file not found",
+		},
+		{
+			name:  "same file twice",
+			patch: "*** Begin Patch\n*** Delete File: exists.txt\n*** Add File: exists.txt\n+y\n*** End Patch",
+			want:  "more than once",
+		},
+		{
+			name:  "not a patch",
+			patch: "just some text",
+			want:  "invalid patch",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tool.Execute(context.Background(), map[string]any{"patch": tt.patch})
+			require.True(t, result.IsError)
+			assert.Contains(t, result.ForLLM, tt.want)
+		})
+	}
+	assert.Equal(t, "x\n", readFixture(t, workspace, "exists.txt"))
+}
+
+func TestApplyPatchTool_RestrictedToWorkspace(t *testing.T) {
+	workspace := t.TempDir()
+	outside := t.TempDir()
+	target := filepath.Join(outside, "escape.txt")
+
+	tool := NewApplyPatchTool(workspace, true)
+	result := tool.Execute(context.Background(), map[string]any{
+		"patch": "*** Begin Patch\n*** Add File: " + target + "\n+pwned\n*** End Patch",
+	})
+	require.True(t, result.IsError)
+	assert.NoFileExists(t, target)
+}
+
+func TestCommitPatch_RollsBackOnWriteFailure(t *testing.T) {
+	workspace := t.TempDir()
+	writeSearchFixture(t, workspace, map[string]string{"a.txt": "a\n"})
+	sysFs := &failingFs{fileSystem: buildFs(workspace, true, nil), failOn: "b.txt"}
+
+	patches, err := parsePatch("*** Begin Patch\n*** Update File: a.txt\n-a\n+A\n*** Add File: c.txt\n+c\n*** Add File: b.txt\n+b\n*** End Patch")
+	require.NoError(t, err)
+	changes, err := planPatch(sysFs, patches, defaultPatchFuzz)
+	require.NoError(t, err)
+
+	err = commitPatch(context.Background(), sysFs, changes)
+	require.Error(t, err)
+	assert.Equal(t, "a\n", readFixture(t, workspace, "a.txt"))
+	assert.NoFileExists(t, filepath.Join(workspace, "c.txt"))
+}
+
+type failingFs struct {
+	fileSystem
+	failOn string
+}
+
+func (f *failingFs) WriteFile(path string, data []byte) error {
+	if path == f.failOn {
+		return os.ErrPermission
+	}
+	return f.fileSystem.WriteFile(path, data)
+}
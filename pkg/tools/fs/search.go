@@ -0,0 +1,658 @@
+package fstools
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// Limits keep searches cheap enough for small boards: the walk stops after
+// maxSearchFiles files, large files are skipped and output is capped.
+const (
+	defaultGrepMaxResults = 100
+	maxGrepMaxResults     = 500
+	maxGrepContextLines   = 5
+	maxGrepPatternLength  = 500
+	maxGrepFileSize       = 1 << 20 // 1MB
+	maxGrepLineLength     = 300
+	maxSearchOutputBytes  = 32 * 1024
+
+	defaultGlobMaxResults = 200
+	maxGlobMaxResults     = 1000
+
+	maxSearchFiles = 20000
+)
+
+// skippedSearchDirs are never descended into unless the search starts inside
+// them.
+var skippedSearchDirs = map[string]bool{
+	".git":         true,
+	".hg":          true,
+	".svn":         true,
+	"node_modules": true,
+}
+
+// searchScope resolves search roots with the same rules as the other
+// filesystem tools: restricted tools only see the workspace (walked through
+// os.Root, so symlinks cannot escape it) plus the allowed read paths.
+type searchScope struct {
+	workspace string
+	restrict  bool
+	patterns  []*regexp.Regexp
+}
+
+// searchRoot is an opened search root.
+type searchRoot struct {
+	fsys fs.FS
+	// dir is the starting point inside fsys.
+	dir string
+	// base is the absolute host path fsys is rooted at.
+	base      string
+	workspace string
+	closer    io.Closer
+}
+
+func newSearchScope(workspace string, restrict bool, allowPaths [][]*regexp.Regexp) searchScope {
+	var patterns []*regexp.Regexp
+	if len(allowPaths) > 0 {
+		patterns = allowPaths[0]
+	}
+	return searchScope{workspace: workspace, restrict: restrict, patterns: patterns}
+}
+
+func (s searchScope) open(searchPath string) (*searchRoot, error) {
+	if strings.TrimSpace(searchPath) == "" {
+		searchPath = "."
+	}
+
+	var absWorkspace string
+	if s.workspace != "" {
+		var err error
+		if absWorkspace, err = filepath.Abs(s.workspace); err != nil {
+			return nil, fmt.Errorf("failed to resolve workspace path: %w", err)
+		}
+	}
+
+	if !s.restrict {
+		target := searchPath
+		if !filepath.IsAbs(target) && absWorkspace != "" {
+			target = filepath.Join(absWorkspace, target)
+		}
+		target, err := filepath.Abs(target)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve path: %w", err)
+		}
+		return hostSearchRoot(target, absWorkspace)
+	}
+
+	absPath, err := validatePathWithAllowPaths(searchPath, s.workspace, true, s.patterns)
+	if err != nil {
+		return nil, err
+	}
+	if !isWithinWorkspace(absPath, absWorkspace) && isAllowedPath(absPath, s.patterns) {
+		return hostSearchRoot(absPath, absWorkspace)
+	}
+
+	rel, err := getSafeRelPath(absWorkspace, absPath)
+	if err != nil {
+		return nil, err
+	}
+	root, err := os.OpenRoot(absWorkspace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open workspace: %w", err)
+	}
+	return &searchRoot{
+		fsys:      root.FS(),
+		dir:       filepath.ToSlash(rel),
+		base:      absWorkspace,
+		workspace: absWorkspace,
+		closer:    root,
+	}, nil
+}
+
+// hostSearchRoot roots the search at target itself, or at its directory when
+// target is a file, so a file can be searched directly.
+func hostSearchRoot(target, workspace string) (*searchRoot, error) {
+	info, err := os.Stat(target)
+	if err != nil {
+		return nil, err
+	}
+	base, dir := target, "."
+	if !info.IsDir() {
+		base, dir = filepath.Dir(target), filepath.Base(target)
+	}
+	return &searchRoot{fsys: os.DirFS(base), dir: dir, base: base, workspace: workspace}, nil
+}
+
+func (r *searchRoot) Close() {
+	if r.closer != nil {
+		r.closer.Close()
+	}
+}
+
+// display returns the path shown to the model: relative to the workspace when
+// inside it, absolute otherwise.
+func (r *searchRoot) display(p string) string {
+	abs := filepath.Join(r.base, filepath.FromSlash(p))
+	if r.workspace != "" && isWithinWorkspace(abs, r.workspace) {
+		if rel, err := filepath.Rel(r.workspace, abs); err == nil {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return abs
+}
+
+// relative returns p relative to the search start, which is what include and
+// exclude globs are matched against.
+func (r *searchRoot) relative(p string) string {
+	if r.dir == "." {
+		return p
+	}
+	if rel, ok := strings.CutPrefix(p, r.dir+"/"); ok {
+		return rel
+	}
+	return path.Base(p)
+}
+
+// walk visits regular files below the search start in lexical order. fn
+// returns false to stop the walk.
+func (r *searchRoot) walk(
+	ctx context.Context,
+	exclude []string,
+	fn func(p string, d fs.DirEntry) bool,
+) (int, error) {
+	visited := 0
+	err := fs.WalkDir(r.fsys, r.dir, func(p string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			if p == r.dir {
+				return err
+			}
+			// Unreadable entries are skipped, not fatal.
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		rel := r.relative(p)
+		if d.IsDir() {
+			if p != r.dir && (skippedSearchDirs[d.Name()] || matchAnyGlob(exclude, rel)) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || matchAnyGlob(exclude, rel) {
+			return nil
+		}
+		visited++
+		if visited > maxSearchFiles || !fn(p, d) {
+			return fs.SkipAll
+		}
+		return nil
+	})
+	return visited, err
+}
+
+// matchAnyGlob reports whether rel matches one of the patterns.
+func matchAnyGlob(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches a slash-separated relative path against a glob. Patterns
+// without a slash match the base name ("*.go"); others match the whole path,
+// where "**" spans any number of directories ("src/**/test_*.py").
+func matchGlob(pattern, rel string) bool {
+	pattern = strings.TrimPrefix(filepath.ToSlash(strings.TrimSpace(pattern)), "./")
+	if pattern == "" {
+		return false
+	}
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(rel))
+		return ok
+	}
+	return matchGlobSegments(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+}
+
+func matchGlobSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(parts); i++ {
+				if matchGlobSegments(pattern[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
+
+// GrepFilesTool searches file contents with a regular expression.
+type GrepFilesTool struct {
+	scope searchScope
+}
+
+// NewGrepFilesTool creates a GrepFilesTool honoring the workspace restriction
+// and the optional read allow-paths.
+func NewGrepFilesTool(workspace string, restrict bool, allowPaths ...[]*regexp.Regexp) *GrepFilesTool {
+	return &GrepFilesTool{scope: newSearchScope(workspace, restrict, allowPaths)}
+}
+
+func (t *GrepFilesTool) Name() string {
+	return "grep_files"
+}
+
+func (t *GrepFilesTool) Description() string {
+	return "Search file contents with a regular expression (RE2 syntax). Returns matching lines as `path:line:text`, with optional context lines as `path-line-text`. Binary files, files over 1MB and VCS/node_modules directories are skipped. Use include/exclude globs to narrow the search."
+}
+
+func (t *GrepFilesTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"pattern": map[string]any{
+				"type":        "string",
+				"description": "Regular expression to search for (RE2 syntax).",
+			},
+			"path": map[string]any{
+				"type":        "string",
+				"description": "File or directory to search. Defaults to the workspace root.",
+			},
+			"include": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Only search files matching one of these globs, e.g. [\"*.go\", \"docs/**/*.md\"].",
+			},
+			"exclude": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Skip files and directories matching one of these globs.",
+			},
+			"ignore_case": map[string]any{
+				"type":        "boolean",
+				"description": "Match case-insensitively.",
+				"default":     false,
+			},
+			"fixed_string": map[string]any{
+				"type":        "boolean",
+				"description": "Treat pattern as a literal string instead of a regular expression.",
+				"default":     false,
+			},
+			"context": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Lines of context to show around each match (max %d).", maxGrepContextLines),
+				"default":     0,
+			},
+			"max_results": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Maximum number of matching lines to return (max %d).", maxGrepMaxResults),
+				"default":     defaultGrepMaxResults,
+			},
+		},
+		"required": []string{"pattern"},
+	}
+}
+
+func (t *GrepFilesTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	pattern, ok := args["pattern"].(string)
+	if !ok || pattern == "" {
+		return ErrorResult("pattern is required")
+	}
+	if len(pattern) > maxGrepPatternLength {
+		return ErrorResult(fmt.Sprintf("pattern is too long (max %d characters)", maxGrepPatternLength))
+	}
+	if fixed, _ := args["fixed_string"].(bool); fixed {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if ignoreCase, _ := args["ignore_case"].(bool); ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("invalid pattern: %v", err))
+	}
+
+	include, err := getStringListArg(args, "include")
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	exclude, err := getStringListArg(args, "exclude")
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	contextLines, err := getInt64Arg(args, "context", 0)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	contextLines = max(0, min(contextLines, maxGrepContextLines))
+	maxResults, err := getInt64Arg(args, "max_results", defaultGrepMaxResults)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	if maxResults <= 0 {
+		maxResults = defaultGrepMaxResults
+	}
+	maxResults = min(maxResults, maxGrepMaxResults)
+
+	searchPath, _ := args["path"].(string)
+	root, err := t.scope.open(searchPath)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	defer root.Close()
+
+	g := grepper{
+		re:      re,
+		context: int(contextLines),
+		limit:   int(maxResults),
+	}
+	visited, err := root.walk(ctx, exclude, func(p string, d fs.DirEntry) bool {
+		if len(include) > 0 && !matchAnyGlob(include, root.relative(p)) {
+			return true
+		}
+		if info, infoErr := d.Info(); infoErr == nil && info.Size() > maxGrepFileSize {
+			g.skippedLarge++
+			return true
+		}
+		return g.file(root, p)
+	})
+	if err != nil && !errors.Is(err, fs.SkipAll) {
+		return ErrorResult(fmt.Sprintf("search failed: %v", err))
+	}
+
+	logger.DebugCF("tool", "GrepFilesTool search completed",
+		map[string]any{
+			"path":          searchPath,
+			"files_scanned": visited,
+			"matches":       g.matches,
+			"truncated":     g.truncated,
+		})
+
+	return NewToolResult(g.result(visited))
+}
+
+// grepper accumulates grep output across files.
+type grepper struct {
+	re      *regexp.Regexp
+	context int
+	limit   int
+
+	out           strings.Builder
+	matches       int
+	files         int
+	skippedBinary int
+	skippedLarge  int
+	truncated     bool
+}
+
+// file searches one file and reports whether the walk should continue.
+func (g *grepper) file(root *searchRoot, p string) bool {
+	f, err := root.fsys.Open(p)
+	if err != nil {
+		return true
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	if sniff, _ := reader.Peek(512); isBinaryReadFileData(sniff) {
+		g.skippedBinary++
+		return true
+	}
+
+	name := root.display(p)
+	var before []string
+	after := 0
+	lastPrinted := 0
+	found := false
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxGrepFileSize)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if g.re.MatchString(line) {
+			if g.matches >= g.limit {
+				g.truncated = true
+				return false
+			}
+			if !found {
+				found = true
+				g.files++
+			}
+			if lastPrinted > 0 && lineNo-len(before) > lastPrinted+1 && g.context > 0 {
+				g.write("--\n")
+			}
+			for i, text := range before {
+				g.writeLine(name, lineNo-len(before)+i, '-', text)
+			}
+			before = before[:0]
+			g.writeLine(name, lineNo, ':', line)
+			g.matches++
+			lastPrinted = lineNo
+			after = g.context
+		} else if after > 0 {
+			g.writeLine(name, lineNo, '-', line)
+			lastPrinted = lineNo
+			after--
+		} else if g.context > 0 {
+			if len(before) == g.context {
+				before = before[1:]
+			}
+			before = append(before, line)
+		}
+		if g.truncated {
+			return false
+		}
+	}
+	return true
+}
+
+func (g *grepper) writeLine(name string, lineNo int, sep byte, text string) {
+	if runes := []rune(text); len(runes) > maxGrepLineLength {
+		text = string(runes[:maxGrepLineLength]) + "..."
+	}
+	g.write(name + string(sep) + strconv.Itoa(lineNo) + string(sep) + text + "\n")
+}
+
+func (g *grepper) write(s string) {
+	if g.out.Len()+len(s) > maxSearchOutputBytes {
+		g.truncated = true
+		return
+	}
+	g.out.WriteString(s)
+}
+
+func (g *grepper) result(visited int) string {
+	if g.matches == 0 {
+		summary := fmt.Sprintf("No matches found (%d files searched)", min(visited, maxSearchFiles))
+		return summary + g.notes(visited)
+	}
+	summary := fmt.Sprintf("[%d matches in %d files]", g.matches, g.files)
+	if g.truncated {
+		summary += "\n[TRUNCATED - narrow the search with path, include or a more specific pattern.]"
+	}
+	return g.out.String() + "\n" + summary + g.notes(visited)
+}
+
+func (g *grepper) notes(visited int) string {
+	var notes []string
+	if g.skippedBinary > 0 {
+		notes = append(notes, fmt.Sprintf("%d binary files skipped", g.skippedBinary))
+	}
+	if g.skippedLarge > 0 {
+		notes = append(notes, fmt.Sprintf("%d files over %d bytes skipped", g.skippedLarge, maxGrepFileSize))
+	}
+	if visited > maxSearchFiles {
+		notes = append(notes, fmt.Sprintf("stopped after %d files", maxSearchFiles))
+	}
+	if len(notes) == 0 {
+		return ""
+	}
+	return "\n[" + strings.Join(notes, "; ") + "]"
+}
+
+// GlobFilesTool lists files whose paths match a glob.
+type GlobFilesTool struct {
+	scope searchScope
+}
+
+// NewGlobFilesTool creates a GlobFilesTool honoring the workspace restriction
+// and the optional read allow-paths.
+func NewGlobFilesTool(workspace string, restrict bool, allowPaths ...[]*regexp.Regexp) *GlobFilesTool {
+	return &GlobFilesTool{scope: newSearchScope(workspace, restrict, allowPaths)}
+}
+
+func (t *GlobFilesTool) Name() string {
+	return "glob_files"
+}
+
+func (t *GlobFilesTool) Description() string {
+	return "Find files by path glob, e.g. `**/*.go` or `docs/*.md`. Patterns without a slash match file names at any depth; `**` matches any number of directories. VCS and node_modules directories are skipped."
+}
+
+func (t *GlobFilesTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"pattern": map[string]any{
+				"type":        "string",
+				"description": "Glob to match against paths relative to the search directory.",
+			},
+			"path": map[string]any{
+				"type":        "string",
+				"description": "Directory to search. Defaults to the workspace root.",
+			},
+			"exclude": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Skip files and directories matching one of these globs.",
+			},
+			"max_results": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Maximum number of paths to return (max %d).", maxGlobMaxResults),
+				"default":     defaultGlobMaxResults,
+			},
+		},
+		"required": []string{"pattern"},
+	}
+}
+
+func (t *GlobFilesTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	pattern, ok := args["pattern"].(string)
+	if !ok || strings.TrimSpace(pattern) == "" {
+		return ErrorResult("pattern is required")
+	}
+	if _, err := path.Match(strings.ReplaceAll(pattern, "**", "*"), ""); err != nil {
+		return ErrorResult(fmt.Sprintf("invalid pattern: %v", err))
+	}
+	exclude, err := getStringListArg(args, "exclude")
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	maxResults, err := getInt64Arg(args, "max_results", defaultGlobMaxResults)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	if maxResults <= 0 {
+		maxResults = defaultGlobMaxResults
+	}
+	maxResults = min(maxResults, maxGlobMaxResults)
+
+	searchPath, _ := args["path"].(string)
+	root, err := t.scope.open(searchPath)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	defer root.Close()
+
+	var out strings.Builder
+	found := 0
+	truncated := false
+	visited, err := root.walk(ctx, exclude, func(p string, _ fs.DirEntry) bool {
+		if !matchGlob(pattern, root.relative(p)) {
+			return true
+		}
+		if int64(found) >= maxResults {
+			truncated = true
+			return false
+		}
+		line := root.display(p) + "\n"
+		if out.Len()+len(line) > maxSearchOutputBytes {
+			truncated = true
+			return false
+		}
+		out.WriteString(line)
+		found++
+		return true
+	})
+	if err != nil && !errors.Is(err, fs.SkipAll) {
+		return ErrorResult(fmt.Sprintf("search failed: %v", err))
+	}
+
+	if found == 0 {
+		return NewToolResult(fmt.Sprintf("No files match %q", pattern))
+	}
+	summary := fmt.Sprintf("[%d files]", found)
+	if truncated {
+		summary += "\n[TRUNCATED - narrow the search with path or a more specific pattern.]"
+	} else if visited > maxSearchFiles {
+		summary += fmt.Sprintf("\n[stopped after %d files]", maxSearchFiles)
+	}
+	return NewToolResult(out.String() + "\n" + summary)
+}
+
+// getStringListArg reads an array of strings, also accepting a single
+// comma-separated string.
+func getStringListArg(args map[string]any, key string) ([]string, error) {
+	raw, exists := args[key]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+	var values []string
+	switch v := raw.(type) {
+	case string:
+		values = strings.Split(v, ",")
+	case []string:
+		values = v
+	case []any:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be an array of strings", key)
+			}
+			values = append(values, s)
+		}
+	default:
+		return nil, fmt.Errorf("%s must be an array of strings", key)
+	}
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			out = append(out, value)
+		}
+	}
+	return out, nil
+}
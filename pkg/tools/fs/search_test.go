@@ -0,0 +1,227 @@
+package fstools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSearchFixture(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+}
+
+func TestGrepFilesTool_MatchesWithIncludeAndExclude(t *testing.T) {
+	workspace := t.TempDir()
+	writeSearchFixture(t, workspace, map[string]string{
+		"main.go":             "package main\n\nfunc main() {\n\tTODO()\n}\n",
+		"pkg/util.go":         "package pkg\n// TODO: tidy\n",
+		"pkg/util_test.go":    "package pkg\n// TODO: test\n",
+		"docs/notes.md":       "TODO in docs\n",
+		".git/HEAD":           "TODO never searched\n",
+		"node_modules/x/a.go": "TODO never searched\n",
+	})
+
+	tool := NewGrepFilesTool(workspace, true)
+	result := tool.Execute(context.Background(), map[string]any{
+		"pattern": "TODO",
+		"include": []any{"*.go"},
+		"exclude": []any{"*_test.go"},
+	})
+
+	require.False(t, result.IsError, result.ForLLM)
+	assert.Contains(t, result.ForLLM, "main.go:4:\tTODO()")
+	assert.Contains(t, result.ForLLM, "pkg/util.go:2:// TODO: tidy")
+	assert.NotContains(t, result.ForLLM, "util_test.go")
+	assert.NotContains(t, result.ForLLM, "notes.md")
+	assert.NotContains(t, result.ForLLM, "never searched")
+	assert.Contains(t, result.ForLLM, "[2 matches in 2 files]")
+}
+
+func TestGrepFilesTool_ContextLines(t *testing.T) {
+	workspace := t.TempDir()
+	writeSearchFixture(t, workspace, map[string]string{
+		"a.txt": "one\ntwo\nhit\nfour\nfive\nsix\nseven\nhit again\n",
+	})
+
+	tool := NewGrepFilesTool(workspace, true)
+	result := tool.Execute(context.Background(), map[string]any{
+		"pattern": "hit",
+		"context": 1,
+	})
+
+	require.False(t, result.IsError, result.ForLLM)
+	want := "a.txt-2-two\na.txt:3:hit\na.txt-4-four\n--\na.txt-7-seven\na.txt:8:hit again\n"
+	assert.True(t, strings.HasPrefix(result.ForLLM, want), result.ForLLM)
+}
+
+func TestGrepFilesTool_CapsResultsAndSkipsBinary(t *testing.T) {
+	workspace := t.TempDir()
+	writeSearchFixture(t, workspace, map[string]string{
+		"many.txt": strings.Repeat("match\n", 20),
+		"blob.bin": "match\x00\x01\x02",
+	})
+
+	tool := NewGrepFilesTool(workspace, true)
+	result := tool.Execute(context.Background(), map[string]any{
+		"pattern":     "MATCH",
+		"ignore_case": true,
+		"max_results": 5,
+	})
+
+	require.False(t, result.IsError, result.ForLLM)
+	assert.Equal(t, 5, strings.Count(result.ForLLM, "many.txt:"))
+	assert.Contains(t, result.ForLLM, "TRUNCATED")
+	assert.NotContains(t, result.ForLLM, "blob.bin:")
+	assert.Contains(t, result.ForLLM, "1 binary files skipped")
+}
+
+func TestGrepFilesTool_FixedString(t *testing.T) {
+	workspace := t.TempDir()
+	writeSearchFixture(t, workspace, map[string]string{"a.txt": "call f(x)\ncall fx\n"})
+
+	tool := NewGrepFilesTool(workspace, true)
+	result := tool.Execute(context.Background(), map[string]any{
+		"pattern":      "f(x)",
+		"fixed_string": true,
+	})
+
+	require.False(t, result.IsError, result.ForLLM)
+	assert.Contains(t, result.ForLLM, "a.txt:1:call f(x)")
+	assert.NotContains(t, result.ForLLM, "a.txt:2:")
+}
+
+func TestGrepFilesTool_RestrictedToWorkspace(t *testing.T) {
+	workspace := t.TempDir()
+	outside := t.TempDir()
+	writeSearchFixture(t, outside, map[string]string{"secret.txt": "password=hunter2\n"})
+	writeSearchFixture(t, workspace, map[string]string{"ok.txt": "nothing here\n"})
+
+	tool := NewGrepFilesTool(workspace, true)
+	result := tool.Execute(context.Background(), map[string]any{
+		"pattern": "password",
+		"path":    outside,
+	})
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.ForLLM, "access denied")
+
+	// A symlinked directory inside the workspace must not leak the target.
+	require.NoError(t, os.Symlink(outside, filepath.Join(workspace, "link")))
+	result = tool.Execute(context.Background(), map[string]any{"pattern": "password"})
+	require.False(t, result.IsError, result.ForLLM)
+	assert.NotContains(t, result.ForLLM, "hunter2")
+}
+
+func TestGrepFilesTool_AllowReadPaths(t *testing.T) {
+	workspace := t.TempDir()
+	shared := t.TempDir()
+	writeSearchFixture(t, shared, map[string]string{"notes.txt": "shared note\n"})
+
+	patterns := []*regexp.Regexp{regexp.MustCompile("^" + regexp.QuoteMeta(shared))}
+	tool := NewGrepFilesTool(workspace, true, patterns)
+	result := tool.Execute(context.Background(), map[string]any{
+		"pattern": "shared",
+		"path":    shared,
+	})
+
+	require.False(t, result.IsError, result.ForLLM)
+	assert.Contains(t, result.ForLLM, filepath.Join(shared, "notes.txt")+":1:shared note")
+}
+
+func TestGrepFilesTool_InvalidPattern(t *testing.T) {
+	tool := NewGrepFilesTool(t.TempDir(), true)
+	result := tool.Execute(context.Background(), map[string]any{"pattern": "("})
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.ForLLM, "invalid pattern")
+}
+
+func TestGlobFilesTool(t *testing.T) {
+	workspace := t.TempDir()
+	writeSearchFixture(t, workspace, map[string]string{
+		"main.go":           "",
+		"pkg/a.go":          "",
+		"pkg/sub/b.go":      "",
+		"pkg/sub/b_test.go": "",
+		"docs/readme.md":    "",
+		".git/config.go":    "",
+	})
+
+	tool := NewGlobFilesTool(workspace, true)
+	tests := []struct {
+		name    string
+		args    map[string]any
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "base name pattern matches at any depth",
+			args:    map[string]any{"pattern": "*.go", "exclude": "*_test.go"},
+			want:    []string{"main.go", "pkg/a.go", "pkg/sub/b.go"},
+			notWant: []string{"b_test.go", "config.go"},
+		},
+		{
+			name:    "double star",
+			args:    map[string]any{"pattern": "pkg/**/*.go"},
+			want:    []string{"pkg/a.go", "pkg/sub/b.go", "pkg/sub/b_test.go"},
+			notWant: []string{"main.go"},
+		},
+		{
+			name:    "relative to path",
+			args:    map[string]any{"pattern": "sub/*.go", "path": "pkg"},
+			want:    []string{"pkg/sub/b.go"},
+			notWant: []string{"pkg/a.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tool.Execute(context.Background(), tt.args)
+			require.False(t, result.IsError, result.ForLLM)
+			for _, want := range tt.want {
+				assert.Contains(t, result.ForLLM, want+"\n")
+			}
+			for _, notWant := range tt.notWant {
+				assert.NotContains(t, result.ForLLM, notWant)
+			}
+		})
+	}
+}
+
+func TestGlobFilesTool_MaxResults(t *testing.T) {
+	workspace := t.TempDir()
+	writeSearchFixture(t, workspace, map[string]string{"a.txt": "", "b.txt": "", "c.txt": ""})
+
+	tool := NewGlobFilesTool(workspace, true)
+	result := tool.Execute(context.Background(), map[string]any{"pattern": "*.txt", "max_results": 2})
+
+	require.False(t, result.IsError, result.ForLLM)
+	assert.Contains(t, result.ForLLM, "a.txt\nb.txt\n")
+	assert.Contains(t, result.ForLLM, "TRUNCATED")
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"*.go", "a/b/c.go", true},
+		{"**/*.go", "c.go", true},
+		{"a/**", "a/b/c.go", true},
+		{"a/*.go", "a/b/c.go", false},
+		{"./a/*.go", "a/c.go", true},
+		{"", "a", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matchGlob(tt.pattern, tt.path), "%s vs %s", tt.pattern, tt.path)
+	}
+}
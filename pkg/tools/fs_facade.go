@@ -12,8 +12,11 @@ type (
 	ReadFileLinesTool = fstools.ReadFileLinesTool
 	WriteFileTool     = fstools.WriteFileTool
 	ListDirTool       = fstools.ListDirTool
+	GrepFilesTool     = fstools.GrepFilesTool
+	GlobFilesTool     = fstools.GlobFilesTool
 	EditFileTool      = fstools.EditFileTool
 	AppendFileTool    = fstools.AppendFileTool
+	ApplyPatchTool    = fstools.ApplyPatchTool
 	LoadImageTool     = fstools.LoadImageTool
 	SendFileTool      = fstools.SendFileTool
 )
@@ -63,6 +66,22 @@ func NewListDirTool(
 	return fstools.NewListDirTool(workspace, restrict, allowPaths...)
 }
 
+func NewGrepFilesTool(
+	workspace string,
+	restrict bool,
+	allowPaths ...[]*regexp.Regexp,
+) *GrepFilesTool {
+	return fstools.NewGrepFilesTool(workspace, restrict, allowPaths...)
+}
+
+func NewGlobFilesTool(
+	workspace string,
+	restrict bool,
+	allowPaths ...[]*regexp.Regexp,
+) *GlobFilesTool {
+	return fstools.NewGlobFilesTool(workspace, restrict, allowPaths...)
+}
+
 func NewEditFileTool(
 	workspace string,
 	restrict bool,
@@ -79,6 +98,14 @@ func NewAppendFileTool(
 	return fstools.NewAppendFileTool(workspace, restrict, allowPaths...)
 }
 
+func NewApplyPatchTool(
+	workspace string,
+	restrict bool,
+	allowPaths ...[]*regexp.Regexp,
+) *ApplyPatchTool {
+	return fstools.NewApplyPatchTool(workspace, restrict, allowPaths...)
+}
+
 func NewLoadImageTool(
 	workspace string,
 	restrict bool,
@@ -0,0 +1,483 @@
+package hardwaretools
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+const (
+	canEFFFlag = 0x80000000 // extended frame format (29-bit ID)
+	canRTRFlag = 0x40000000 // remote transmission request
+	canErrFlag = 0x20000000 // error frame; doubles as CAN_INV_FILTER in filters
+	canSFFMask = 0x000007FF
+	canEFFMask = 0x1FFFFFFF
+
+	defaultCANTimeoutMS  = 1000
+	defaultCANMaxFrames  = 200
+	maxCANReceiveFrames  = 100
+	maxCANCaptureFrames  = 1000
+	maxCANWaitMS         = 60000
+	canFrameSize         = 16
+	canPollInterval      = 100 * time.Millisecond
+	maxCANFiltersPerCall = 16
+)
+
+var (
+	canInterfaceNameRe = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,15}$`)
+	canFrameRe         = regexp.MustCompile(`^([0-9A-Fa-f]{3}|[0-9A-Fa-f]{8})#(?:(R)([0-8])?|((?:[0-9A-Fa-f]{2}\.?){0,8}))$`)
+	canFilterRe        = regexp.MustCompile(`^([0-9A-Fa-f]{1,8})([:~])([0-9A-Fa-f]{1,8})$`)
+)
+
+// CANBusTool sends and receives classic CAN frames on SocketCAN interfaces
+// listed in tools.canbus.interfaces.
+type CANBusTool struct {
+	interfaces map[string]*canInterface
+}
+
+type canInterface struct {
+	name string
+	send []canIDRange
+}
+
+type canIDRange struct {
+	from, to uint32
+}
+
+// canFrame is a classic CAN frame. ID holds only the identifier bits; the
+// EFF/RTR flags live in Extended and Remote.
+type canFrame struct {
+	ID       uint32
+	Extended bool
+	Remote   bool
+	Len      int // data length, or requested length for remote frames
+	Data     []byte
+	Time     time.Time
+}
+
+// canFilter mirrors struct can_filter: a frame matches when
+// (frame_id & mask) == (id & mask), inverted when id has canErrFlag set.
+type canFilter struct {
+	ID   uint32
+	Mask uint32
+}
+
+func NewCANBusTool(cfg config.CANBusConfig) (*CANBusTool, error) {
+	t := &CANBusTool{interfaces: make(map[string]*canInterface, len(cfg.Interfaces))}
+	for _, ic := range cfg.Interfaces {
+		name := strings.TrimSpace(ic.Name)
+		if !canInterfaceNameRe.MatchString(name) {
+			return nil, fmt.Errorf("canbus interface %q: invalid interface name", ic.Name)
+		}
+		if _, dup := t.interfaces[name]; dup {
+			return nil, fmt.Errorf("canbus interface %q is configured twice", name)
+		}
+		iface := &canInterface{name: name}
+		for _, raw := range ic.SendIDs {
+			r, err := parseCANIDRange(raw)
+			if err != nil {
+				return nil, fmt.Errorf("canbus interface %q: %w", name, err)
+			}
+			iface.send = append(iface.send, r)
+		}
+		t.interfaces[name] = iface
+	}
+	return t, nil
+}
+
+func parseCANIDRange(raw string) (canIDRange, error) {
+	from, to, isRange := strings.Cut(strings.TrimSpace(raw), "-")
+	lo, err := strconv.ParseUint(strings.TrimSpace(from), 0, 32)
+	if err != nil || lo > canEFFMask {
+		return canIDRange{}, fmt.Errorf("invalid send id %q", raw)
+	}
+	hi := lo
+	if isRange {
+		hi, err = strconv.ParseUint(strings.TrimSpace(to), 0, 32)
+		if err != nil || hi > canEFFMask || hi < lo {
+			return canIDRange{}, fmt.Errorf("invalid send id range %q", raw)
+		}
+	}
+	return canIDRange{from: uint32(lo), to: uint32(hi)}, nil
+}
+
+func (i *canInterface) canSend(id uint32) bool {
+	for _, r := range i.send {
+		if id >= r.from && id <= r.to {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *CANBusTool) Name() string {
+	return "canbus"
+}
+
+func (t *CANBusTool) Description() string {
+	return "Send and receive classic CAN frames via Linux SocketCAN. Only interfaces configured in tools.canbus.interfaces can be used. Actions: list (configured interfaces), send (one frame in cansend syntax such as 123#DEADBEEF; ID must be allowlisted; requires confirm), receive (wait for matching frames, returned as JSON), capture (record traffic for a duration in candump format). Filters use candump syntax: id:mask or id~mask (inverted). Linux only."
+}
+
+func (t *CANBusTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"list", "send", "receive", "capture"},
+				"description": "Action to perform: list, send, receive, or capture.",
+			},
+			"interface": map[string]any{
+				"type":        "string",
+				"description": "Configured CAN interface, e.g. can0 or vcan0. Required except for list.",
+			},
+			"frame": map[string]any{
+				"type":        "string",
+				"description": "Frame for send in cansend syntax: <id>#<data>. 3 hex digits for standard IDs, 8 for extended; data up to 8 bytes in hex (dots allowed). <id>#R or <id>#R<len> sends a remote frame.",
+			},
+			"filters": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Receive filters in candump syntax, e.g. [\"123:7FF\", \"18FEF100:1FFFFFFF\"]. A frame passes if it matches any filter. Omit to receive everything.",
+			},
+			"count": map[string]any{
+				"type":        "integer",
+				"description": "receive: number of frames to wait for (1-100). Default: 1.",
+			},
+			"timeout_ms": map[string]any{
+				"type":        "integer",
+				"description": "receive: how long to wait for frames. capture: capture duration. Default: 1000, max 60000.",
+			},
+			"max_frames": map[string]any{
+				"type":        "integer",
+				"description": "capture: stop after this many frames (1-1000). Default: 200.",
+			},
+			"confirm": map[string]any{
+				"type":        "boolean",
+				"description": "Must be true for send.",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *CANBusTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	if runtime.GOOS != "linux" {
+		return ErrorResult("CAN bus is only supported on Linux. This tool requires SocketCAN.")
+	}
+
+	action, ok := args["action"].(string)
+	if !ok || strings.TrimSpace(action) == "" {
+		return ErrorResult("action is required")
+	}
+
+	switch action {
+	case "list":
+		return t.list()
+	case "send":
+		return t.send(args)
+	case "receive":
+		return t.receive(ctx, args)
+	case "capture":
+		return t.capture(ctx, args)
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action: %s (valid: list, send, receive, capture)", action))
+	}
+}
+
+func (t *CANBusTool) list() *ToolResult {
+	if len(t.interfaces) == 0 {
+		return SilentResult("No CAN interfaces configured. Add interfaces under tools.canbus.interfaces in the config.")
+	}
+	names := make([]string, 0, len(t.interfaces))
+	for name := range t.interfaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	entries := make([]map[string]any, 0, len(names))
+	for _, name := range names {
+		iface := t.interfaces[name]
+		send := make([]string, len(iface.send))
+		for i, r := range iface.send {
+			if r.from == r.to {
+				send[i] = fmt.Sprintf("0x%X", r.from)
+			} else {
+				send[i] = fmt.Sprintf("0x%X-0x%X", r.from, r.to)
+			}
+		}
+		entries = append(entries, map[string]any{
+			"name":     name,
+			"state":    canInterfaceState(name),
+			"send_ids": send,
+		})
+	}
+	result, _ := json.MarshalIndent(map[string]any{"interfaces": entries}, "", "  ")
+	return SilentResult(string(result))
+}
+
+func (t *CANBusTool) lookup(args map[string]any) (*canInterface, *ToolResult) {
+	name, _ := args["interface"].(string)
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrorResult("interface is required; use action list to see configured interfaces")
+	}
+	iface, ok := t.interfaces[name]
+	if !ok {
+		return nil, ErrorResult(fmt.Sprintf("interface %q is not in the CAN allowlist (tools.canbus.interfaces)", name))
+	}
+	return iface, nil
+}
+
+func (t *CANBusTool) send(args map[string]any) *ToolResult {
+	confirm, _ := args["confirm"].(bool)
+	if !confirm {
+		return ErrorResult(
+			"send requires confirm: true. Please confirm with the user before putting frames on a CAN bus.",
+		)
+	}
+	iface, errResult := t.lookup(args)
+	if errResult != nil {
+		return errResult
+	}
+	raw, _ := args["frame"].(string)
+	frame, err := parseCANFrame(raw)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	if !iface.canSend(frame.ID) {
+		return ErrorResult(fmt.Sprintf(
+			"CAN ID 0x%X is not in the send_ids allowlist for %s; ask the user to extend the config if this frame is intended",
+			frame.ID, iface.name,
+		))
+	}
+
+	if err := canSendFrame(iface.name, frame); err != nil {
+		return ErrorResult(fmt.Sprintf("CAN send on %s failed: %v", iface.name, err))
+	}
+	result, _ := json.MarshalIndent(map[string]any{
+		"interface": iface.name,
+		"sent":      formatCANFrame(frame),
+	}, "", "  ")
+	return SilentResult(string(result))
+}
+
+func (t *CANBusTool) receive(ctx context.Context, args map[string]any) *ToolResult {
+	iface, errResult := t.lookup(args)
+	if errResult != nil {
+		return errResult
+	}
+	filters, errResult := parseCANFilterArgs(args)
+	if errResult != nil {
+		return errResult
+	}
+	timeout, errResult := parseCANTimeout(args)
+	if errResult != nil {
+		return errResult
+	}
+	count := 1
+	if v, ok := args["count"].(float64); ok {
+		count = int(v)
+	}
+	if count < 1 || count > maxCANReceiveFrames {
+		return ErrorResult(fmt.Sprintf("count must be between 1 and %d", maxCANReceiveFrames))
+	}
+
+	frames, err := canReceiveFrames(ctx, iface.name, filters, timeout, count)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("CAN receive on %s failed: %v", iface.name, err))
+	}
+
+	out := make([]map[string]any, len(frames))
+	for i, f := range frames {
+		entry := map[string]any{
+			"id":        canIDString(f),
+			"extended":  f.Extended,
+			"len":       f.Len,
+			"data":      strings.ToUpper(hex.EncodeToString(f.Data)),
+			"timestamp": f.Time.Format(time.RFC3339Nano),
+		}
+		if f.Remote {
+			entry["remote"] = true
+		}
+		out[i] = entry
+	}
+	result, _ := json.MarshalIndent(map[string]any{
+		"interface": iface.name,
+		"frames":    out,
+		"timed_out": len(frames) < count,
+	}, "", "  ")
+	return SilentResult(string(result))
+}
+
+func (t *CANBusTool) capture(ctx context.Context, args map[string]any) *ToolResult {
+	iface, errResult := t.lookup(args)
+	if errResult != nil {
+		return errResult
+	}
+	filters, errResult := parseCANFilterArgs(args)
+	if errResult != nil {
+		return errResult
+	}
+	duration, errResult := parseCANTimeout(args)
+	if errResult != nil {
+		return errResult
+	}
+	maxFrames := defaultCANMaxFrames
+	if v, ok := args["max_frames"].(float64); ok {
+		maxFrames = int(v)
+	}
+	if maxFrames < 1 || maxFrames > maxCANCaptureFrames {
+		return ErrorResult(fmt.Sprintf("max_frames must be between 1 and %d", maxCANCaptureFrames))
+	}
+
+	frames, err := canReceiveFrames(ctx, iface.name, filters, duration, maxFrames)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("CAN capture on %s failed: %v", iface.name, err))
+	}
+	if len(frames) == 0 {
+		return SilentResult(fmt.Sprintf("No CAN frames on %s in %dms.", iface.name, duration.Milliseconds()))
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Captured %d frame(s) on %s:\n", len(frames), iface.name)
+	for _, f := range frames {
+		sb.WriteString(formatCANDumpLine(iface.name, f))
+		sb.WriteByte('\n')
+	}
+	if len(frames) == maxFrames {
+		fmt.Fprintf(&sb, "(stopped at max_frames=%d)\n", maxFrames)
+	}
+	return SilentResult(sb.String())
+}
+
+func parseCANTimeout(args map[string]any) (time.Duration, *ToolResult) {
+	ms := defaultCANTimeoutMS
+	if v, ok := args["timeout_ms"].(float64); ok {
+		ms = int(v)
+	}
+	if ms < 1 || ms > maxCANWaitMS {
+		return 0, ErrorResult(fmt.Sprintf("timeout_ms must be between 1 and %d", maxCANWaitMS))
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+func parseCANFilterArgs(args map[string]any) ([]canFilter, *ToolResult) {
+	raw, _ := args["filters"].([]any)
+	if len(raw) > maxCANFiltersPerCall {
+		return nil, ErrorResult(fmt.Sprintf("at most %d filters are allowed", maxCANFiltersPerCall))
+	}
+	filters := make([]canFilter, 0, len(raw))
+	for i, v := range raw {
+		s, ok := v.(string)
+		if !ok {
+			return nil, ErrorResult(fmt.Sprintf("filters[%d] must be a string such as \"123:7FF\"", i))
+		}
+		f, err := parseCANFilter(s)
+		if err != nil {
+			return nil, ErrorResult(err.Error())
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+// parseCANFilter parses candump filter syntax. As in candump, an 8-digit ID
+// selects extended frames only.
+func parseCANFilter(s string) (canFilter, error) {
+	m := canFilterRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return canFilter{}, fmt.Errorf("invalid filter %q: want <id>:<mask> or <id>~<mask> in hex", s)
+	}
+	id, _ := strconv.ParseUint(m[1], 16, 32)
+	mask, _ := strconv.ParseUint(m[3], 16, 32)
+	f := canFilter{ID: uint32(id) & canEFFMask, Mask: uint32(mask) & canEFFMask}
+	if len(m[1]) == 8 {
+		f.ID |= canEFFFlag
+		f.Mask |= canEFFFlag
+	}
+	if m[2] == "~" {
+		f.ID |= canErrFlag
+	}
+	return f, nil
+}
+
+// parseCANFrame parses cansend syntax: 123#DEADBEEF, 1F334455#11.22, 123#R.
+func parseCANFrame(s string) (canFrame, error) {
+	m := canFrameRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return canFrame{}, fmt.Errorf(
+			"invalid frame %q: want <id>#<data> with a 3-digit (standard) or 8-digit (extended) hex ID and up to 8 data bytes, e.g. 123#DEADBEEF",
+			s,
+		)
+	}
+	id, _ := strconv.ParseUint(m[1], 16, 32)
+	f := canFrame{ID: uint32(id), Extended: len(m[1]) == 8}
+	if f.Extended && f.ID > canEFFMask {
+		return canFrame{}, fmt.Errorf("extended CAN ID %s exceeds 29 bits", m[1])
+	}
+	if !f.Extended && f.ID > canSFFMask {
+		return canFrame{}, fmt.Errorf("standard CAN ID %s exceeds 11 bits; use 8 hex digits for an extended ID", m[1])
+	}
+	if m[2] == "R" {
+		f.Remote = true
+		if m[3] != "" {
+			f.Len, _ = strconv.Atoi(m[3])
+		}
+		return f, nil
+	}
+	data, err := hex.DecodeString(strings.ReplaceAll(m[4], ".", ""))
+	if err != nil {
+		return canFrame{}, fmt.Errorf("invalid frame data in %q: %v", s, err)
+	}
+	f.Data = data
+	f.Len = len(data)
+	return f, nil
+}
+
+func canIDString(f canFrame) string {
+	if f.Extended {
+		return fmt.Sprintf("%08X", f.ID)
+	}
+	return fmt.Sprintf("%03X", f.ID)
+}
+
+// formatCANFrame renders a frame back in cansend syntax.
+func formatCANFrame(f canFrame) string {
+	if f.Remote {
+		if f.Len > 0 {
+			return fmt.Sprintf("%s#R%d", canIDString(f), f.Len)
+		}
+		return canIDString(f) + "#R"
+	}
+	return canIDString(f) + "#" + strings.ToUpper(hex.EncodeToString(f.Data))
+}
+
+// formatCANDumpLine renders a frame like `candump -ta`:
+//
+//	(1697712345.123456)  can0  123   [4]  DE AD BE EF
+func formatCANDumpLine(ifname string, f canFrame) string {
+	id := canIDString(f)
+	if !f.Extended {
+		id += "     " // pad standard IDs to the extended column width
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "(%d.%06d)  %s  %s   [%d] ", f.Time.Unix(), f.Time.Nanosecond()/1000, ifname, id, f.Len)
+	if f.Remote {
+		sb.WriteString(" remote request")
+		return sb.String()
+	}
+	for _, b := range f.Data {
+		fmt.Fprintf(&sb, " %02X", b)
+	}
+	return sb.String()
+}
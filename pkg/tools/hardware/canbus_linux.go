@@ -0,0 +1,173 @@
+//go:build linux
+
+package hardwaretools
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// canOpenSocket is swapped in tests to avoid needing a vcan interface.
+var canOpenSocket = openCANSocket
+
+// openCANSocket opens a CAN_RAW socket bound to ifname. A nil filter list
+// receives everything; an empty non-nil list receives nothing, which is what
+// send-only sockets use.
+func openCANSocket(ifname string, filters []canFilter) (int, error) {
+	ifi, err := net.InterfaceByName(ifname)
+	if err != nil {
+		return -1, fmt.Errorf("interface %s not found: %w", ifname, err)
+	}
+	fd, err := unix.Socket(unix.AF_CAN, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.CAN_RAW)
+	if err != nil {
+		return -1, fmt.Errorf("open CAN socket: %w", err)
+	}
+	if filters != nil {
+		raw := make([]unix.CanFilter, len(filters))
+		for i, f := range filters {
+			raw[i] = unix.CanFilter{Id: f.ID, Mask: f.Mask}
+		}
+		if err := unix.SetsockoptCanRawFilter(fd, unix.SOL_CAN_RAW, unix.CAN_RAW_FILTER, raw); err != nil {
+			unix.Close(fd)
+			return -1, fmt.Errorf("set CAN filters: %w", err)
+		}
+	}
+	if err := unix.Bind(fd, &unix.SockaddrCAN{Ifindex: ifi.Index}); err != nil {
+		unix.Close(fd)
+		return -1, fmt.Errorf("bind to %s: %w", ifname, err)
+	}
+	return fd, nil
+}
+
+func canSendFrame(ifname string, f canFrame) error {
+	fd, err := canOpenSocket(ifname, []canFilter{})
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+
+	n, err := unix.Write(fd, encodeCANFrame(f))
+	if err != nil {
+		if err == unix.ENETDOWN {
+			return fmt.Errorf("interface %s is down (ip link set %s up)", ifname, ifname)
+		}
+		if err == unix.ENOBUFS {
+			return fmt.Errorf("transmit queue full; check bus termination and that another node acknowledges frames")
+		}
+		return err
+	}
+	if n != canFrameSize {
+		return fmt.Errorf("short write: %d of %d bytes", n, canFrameSize)
+	}
+	return nil
+}
+
+// canReceiveFrames collects up to max frames passing filters until the
+// timeout elapses or ctx is cancelled. Error frames are never delivered
+// because the socket does not subscribe to them.
+func canReceiveFrames(ctx context.Context, ifname string, filters []canFilter, timeout time.Duration, max int) ([]canFrame, error) {
+	if len(filters) == 0 {
+		filters = nil
+	}
+	fd, err := canOpenSocket(ifname, filters)
+	if err != nil {
+		return nil, err
+	}
+	defer unix.Close(fd)
+
+	frames := make([]canFrame, 0, max)
+	buf := make([]byte, canFrameSize)
+	deadline := time.Now().Add(timeout)
+	for len(frames) < max {
+		if err := ctx.Err(); err != nil {
+			return frames, fmt.Errorf("cancelled: %w", err)
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			break
+		}
+		if remaining > canPollInterval {
+			remaining = canPollInterval
+		}
+		pfd := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+		n, err := unix.Poll(pfd, durationToPollTimeout(remaining))
+		if err != nil {
+			if err == unix.EINTR {
+				continue
+			}
+			return frames, err
+		}
+		if n == 0 {
+			continue
+		}
+		read, err := unix.Read(fd, buf)
+		if err != nil {
+			return frames, err
+		}
+		if read != canFrameSize {
+			continue // CAN FD frames are not handled
+		}
+		f := decodeCANFrame(buf)
+		f.Time = time.Now()
+		frames = append(frames, f)
+	}
+	return frames, nil
+}
+
+func encodeCANFrame(f canFrame) []byte {
+	buf := make([]byte, canFrameSize)
+	id := f.ID
+	if f.Extended {
+		id |= canEFFFlag
+	}
+	if f.Remote {
+		id |= canRTRFlag
+	}
+	binary.NativeEndian.PutUint32(buf[0:], id)
+	buf[4] = byte(f.Len)
+	copy(buf[8:], f.Data)
+	return buf
+}
+
+func decodeCANFrame(buf []byte) canFrame {
+	raw := binary.NativeEndian.Uint32(buf[0:])
+	f := canFrame{
+		Extended: raw&canEFFFlag != 0,
+		Remote:   raw&canRTRFlag != 0,
+		Len:      min(int(buf[4]), 8),
+	}
+	if f.Extended {
+		f.ID = raw & canEFFMask
+	} else {
+		f.ID = raw & canSFFMask
+	}
+	if !f.Remote {
+		f.Data = append([]byte(nil), buf[8:8+f.Len]...)
+	}
+	return f
+}
+
+// canInterfaceState reports the link state from sysfs, e.g. "up", "down"
+// or "missing".
+func canInterfaceState(name string) string {
+	data, err := os.ReadFile(filepath.Join("/sys/class/net", name, "operstate"))
+	if err != nil {
+		return "missing"
+	}
+	state := strings.TrimSpace(string(data))
+	if state == "unknown" {
+		// vcan and some USB adapters report unknown; fall back to the flags.
+		if ifi, err := net.InterfaceByName(name); err == nil && ifi.Flags&net.FlagUp != 0 {
+			return "up"
+		}
+	}
+	return state
+}
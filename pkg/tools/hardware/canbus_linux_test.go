@@ -0,0 +1,188 @@
+package hardwaretools
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func newTestCANBusTool(t *testing.T, ifname string, sendIDs ...string) *CANBusTool {
+	t.Helper()
+	tool, err := NewCANBusTool(config.CANBusConfig{
+		Interfaces: []config.CANBusInterfaceConfig{{Name: ifname, SendIDs: sendIDs}},
+	})
+	if err != nil {
+		t.Fatalf("NewCANBusTool() error: %v", err)
+	}
+	return tool
+}
+
+// fakeCANSocket replaces canOpenSocket with a datagram socketpair, so frames
+// written by the test arrive as if from the bus. The last filters passed to
+// open are recorded.
+func fakeCANSocket(t *testing.T) (bus int, filters *[]canFilter) {
+	t.Helper()
+	fds, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_SEQPACKET|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []canFilter
+	orig := canOpenSocket
+	canOpenSocket = func(ifname string, f []canFilter) (int, error) {
+		got = f
+		return unix.Dup(fds[0])
+	}
+	t.Cleanup(func() {
+		canOpenSocket = orig
+		unix.Close(fds[0])
+		unix.Close(fds[1])
+	})
+	return fds[1], &got
+}
+
+func TestParseCANFrameAndFilter(t *testing.T) {
+	cases := map[string]canFrame{
+		"123#DEADBEEF":      {ID: 0x123, Len: 4, Data: []byte{0xDE, 0xAD, 0xBE, 0xEF}},
+		"1F334455#11.22.33": {ID: 0x1F334455, Extended: true, Len: 3, Data: []byte{0x11, 0x22, 0x33}},
+		"7FF#":              {ID: 0x7FF, Data: []byte{}},
+		"123#R4":            {ID: 0x123, Remote: true, Len: 4},
+	}
+	for in, want := range cases {
+		got, err := parseCANFrame(in)
+		if err != nil || got.ID != want.ID || got.Extended != want.Extended || got.Remote != want.Remote ||
+			got.Len != want.Len || string(got.Data) != string(want.Data) {
+			t.Errorf("parseCANFrame(%q) = %+v, %v; want %+v", in, got, err, want)
+		}
+		if formatCANFrame(got) != strings.ReplaceAll(in, ".", "") {
+			t.Errorf("formatCANFrame(%q) = %q", in, formatCANFrame(got))
+		}
+	}
+	for _, bad := range []string{"800#00", "12#00", "123#0011223344556677889", "2FFFFFFF#00", "123#R9", "123"} {
+		if _, err := parseCANFrame(bad); err == nil {
+			t.Errorf("parseCANFrame(%q) succeeded", bad)
+		}
+	}
+
+	f, err := parseCANFilter("123~7FF")
+	if err != nil || f.ID != 0x123|canErrFlag || f.Mask != 0x7FF {
+		t.Fatalf("inverted filter = %+v, %v", f, err)
+	}
+	f, err = parseCANFilter("18FEF100:1FFFFF00")
+	if err != nil || f.ID != 0x18FEF100|canEFFFlag || f.Mask != 0x1FFFFF00|canEFFFlag {
+		t.Fatalf("extended filter = %+v, %v", f, err)
+	}
+}
+
+func TestCANBusTool_SendRequiresAllowlistedID(t *testing.T) {
+	tool := newTestCANBusTool(t, "vcan0", "0x100-0x1FF", "0x18FEF100")
+	bus, _ := fakeCANSocket(t)
+	ctx := context.Background()
+
+	cases := []struct {
+		args map[string]any
+		want string
+	}{
+		{map[string]any{"action": "send", "interface": "vcan0", "frame": "123#01"}, "confirm"},
+		{map[string]any{"action": "send", "interface": "can9", "frame": "123#01", "confirm": true}, "not in the CAN allowlist"},
+		{map[string]any{"action": "send", "interface": "vcan0", "frame": "200#01", "confirm": true}, "not in the send_ids allowlist"},
+		{map[string]any{"action": "send", "interface": "vcan0", "frame": "123#XYZ", "confirm": true}, "invalid frame"},
+		{map[string]any{"action": "receive", "interface": "vcan0", "filters": []any{"12:34:56"}}, "invalid filter"},
+		{map[string]any{"action": "receive", "interface": "vcan0", "timeout_ms": float64(60001)}, "timeout_ms"},
+	}
+	for _, tc := range cases {
+		result := tool.Execute(ctx, tc.args)
+		if !result.IsError || !strings.Contains(result.ForLLM, tc.want) {
+			t.Errorf("Execute(%v) = %s, want error containing %q", tc.args, result.ForLLM, tc.want)
+		}
+	}
+
+	result := tool.Execute(ctx, map[string]any{
+		"action": "send", "interface": "vcan0", "frame": "18FEF100#0102", "confirm": true,
+	})
+	if result.IsError {
+		t.Fatalf("send error: %s", result.ForLLM)
+	}
+	buf := make([]byte, 32)
+	n, err := unix.Read(bus, buf)
+	if err != nil || n != canFrameSize {
+		t.Fatalf("bus read = %d, %v", n, err)
+	}
+	if f := decodeCANFrame(buf[:n]); !f.Extended || f.ID != 0x18FEF100 || string(f.Data) != "\x01\x02" {
+		t.Fatalf("sent frame = %+v", f)
+	}
+}
+
+func TestCANBusTool_ReceiveAndCapture(t *testing.T) {
+	tool := newTestCANBusTool(t, "vcan0")
+	bus, filters := fakeCANSocket(t)
+	ctx := context.Background()
+
+	for _, s := range []string{"123#DEADBEEF", "1F334455#R", "7FF#"} {
+		f, _ := parseCANFrame(s)
+		if _, err := unix.Write(bus, encodeCANFrame(f)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var out map[string]any
+	result := tool.Execute(ctx, map[string]any{
+		"action": "receive", "interface": "vcan0", "count": float64(2), "filters": []any{"123:7FF", "1F334455:1FFFFFFF"},
+	})
+	if err := json.Unmarshal([]byte(result.ForLLM), &out); err != nil || result.IsError {
+		t.Fatalf("receive = %s", result.ForLLM)
+	}
+	frames := out["frames"].([]any)
+	first := frames[0].(map[string]any)
+	if len(frames) != 2 || first["id"] != "123" || first["data"] != "DEADBEEF" || out["timed_out"] != false {
+		t.Fatalf("receive frames = %v", out)
+	}
+	if len(*filters) != 2 || (*filters)[1].ID != 0x1F334455|canEFFFlag {
+		t.Fatalf("filters passed to socket = %+v", *filters)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "capture", "interface": "vcan0", "timeout_ms": float64(200)})
+	if result.IsError || !strings.Contains(result.ForLLM, "Captured 1 frame(s)") ||
+		!strings.Contains(result.ForLLM, "vcan0  7FF        [0]") || *filters != nil {
+		t.Fatalf("capture = %s (filters %v)", result.ForLLM, *filters)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	result = tool.Execute(cancelled, map[string]any{"action": "receive", "interface": "vcan0"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "cancelled") {
+		t.Fatalf("receive with cancelled context = %+v", result)
+	}
+}
+
+// TestCANBusTool_VCAN exercises the real SocketCAN path. Set up the interface
+// with: ip link add dev vcan0 type vcan && ip link set up vcan0
+func TestCANBusTool_VCAN(t *testing.T) {
+	if _, err := net.InterfaceByName("vcan0"); err != nil {
+		t.Skip("vcan0 not available")
+	}
+	tool := newTestCANBusTool(t, "vcan0", "0x321")
+
+	done := make(chan *ToolResult, 1)
+	go func() {
+		done <- tool.Execute(context.Background(), map[string]any{
+			"action": "receive", "interface": "vcan0", "filters": []any{"321:7FF"}, "timeout_ms": float64(2000),
+		})
+	}()
+	// Give the receiver time to bind before the frame goes out.
+	time.Sleep(200 * time.Millisecond)
+	if result := tool.Execute(context.Background(), map[string]any{
+		"action": "send", "interface": "vcan0", "frame": "321#CAFE", "confirm": true,
+	}); result.IsError {
+		t.Fatalf("send error: %s", result.ForLLM)
+	}
+	result := <-done
+	if result.IsError || !strings.Contains(result.ForLLM, `"data": "CAFE"`) {
+		t.Fatalf("receive = %s", result.ForLLM)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !linux
+
+package hardwaretools
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+func canSendFrame(ifname string, f canFrame) error {
+	return fmt.Errorf("CAN bus is only supported on Linux")
+}
+
+func canReceiveFrames(ctx context.Context, ifname string, filters []canFilter, timeout time.Duration, max int) ([]canFrame, error) {
+	return nil, fmt.Errorf("CAN bus is only supported on Linux")
+}
+
+func canInterfaceState(name string) string {
+	return "unsupported"
+}
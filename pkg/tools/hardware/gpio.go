@@ -0,0 +1,192 @@
+package hardwaretools
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	gpioDefaultWaitMs = 10000
+	gpioMaxWaitMs     = 60000
+	gpioConsumer      = "picoclaw"
+)
+
+// GPIOTool reads, drives and watches GPIO lines through the Linux GPIO
+// character device (/dev/gpiochipN, uAPI v2).
+type GPIOTool struct {
+	devDir string
+
+	mu sync.Mutex
+	// held keeps output lines requested by write open so their level is not
+	// lost when the request is released; keyed by "gpiochipN:offset".
+	held map[string]*gpioHeldLine
+}
+
+type gpioHeldLine struct {
+	fd     int
+	chip   string
+	offset uint32
+	name   string
+	value  int
+}
+
+func NewGPIOTool() *GPIOTool {
+	return &GPIOTool{devDir: "/dev", held: make(map[string]*gpioHeldLine)}
+}
+
+func (t *GPIOTool) Name() string {
+	return "gpio"
+}
+
+func (t *GPIOTool) Description() string {
+	return "Read, drive and watch GPIO lines via the Linux GPIO character device. Actions: detect (list chips, or the lines of one chip with their names), read (line level), write (drive a line high/low; the line stays an output until release), wait_edge (block until a rising/falling edge or timeout), release (free lines held by write). Lines can be given by offset or by name. Linux only."
+}
+
+func (t *GPIOTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"detect", "read", "write", "wait_edge", "release"},
+				"description": "Action to perform: detect, read, write, wait_edge or release",
+			},
+			"chip": map[string]any{
+				"type":        "string",
+				"description": "GPIO chip number or name (e.g. \"0\" or \"gpiochip0\"). Optional when line is a name.",
+			},
+			"line": map[string]any{
+				"type":        "string",
+				"description": "Line offset on the chip (e.g. \"17\") or line name from detect (e.g. \"LED1\"). Required for read/write/wait_edge.",
+			},
+			"value": map[string]any{
+				"type":        "integer",
+				"description": "Level to drive (0 or 1). Required for write.",
+			},
+			"bias": map[string]any{
+				"type":        "string",
+				"enum":        []string{"as-is", "pull-up", "pull-down", "disabled"},
+				"description": "Input bias for read/wait_edge. Default: as-is.",
+			},
+			"drive": map[string]any{
+				"type":        "string",
+				"enum":        []string{"push-pull", "open-drain", "open-source"},
+				"description": "Output drive for write. Default: push-pull.",
+			},
+			"edge": map[string]any{
+				"type":        "string",
+				"enum":        []string{"rising", "falling", "both"},
+				"description": "Edge to wait for. Default: both.",
+			},
+			"timeout_ms": map[string]any{
+				"type":        "integer",
+				"description": "How long wait_edge blocks (1-60000). Default: 10000.",
+			},
+			"confirm": map[string]any{
+				"type":        "boolean",
+				"description": "Must be true for write. Safety guard to prevent accidentally driving a line.",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *GPIOTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	if runtime.GOOS != "linux" {
+		return ErrorResult("GPIO is only supported on Linux. This tool requires /dev/gpiochip* device files.")
+	}
+
+	action, ok := args["action"].(string)
+	if !ok {
+		return ErrorResult("action is required")
+	}
+
+	switch action {
+	case "detect":
+		return t.detect(args)
+	case "read":
+		return t.read(args)
+	case "write":
+		return t.write(args)
+	case "wait_edge":
+		return t.waitEdge(ctx, args)
+	case "release":
+		return t.release(args)
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action: %s (valid: detect, read, write, wait_edge, release)", action))
+	}
+}
+
+var gpioChipRe = regexp.MustCompile(`^(?:gpiochip)?(\d+)$`)
+
+// parseGPIOChip normalizes "0" or "gpiochip0" to "gpiochip0". An empty
+// string means no chip was given.
+func parseGPIOChip(args map[string]any) (string, *ToolResult) {
+	raw := ""
+	switch v := args["chip"].(type) {
+	case string:
+		raw = strings.TrimSpace(v)
+	case float64:
+		raw = strconv.Itoa(int(v))
+	}
+	if raw == "" {
+		return "", nil
+	}
+	m := gpioChipRe.FindStringSubmatch(raw)
+	if m == nil {
+		return "", ErrorResult("invalid chip: use a number or gpiochipN (e.g. \"0\" or \"gpiochip0\")")
+	}
+	return "gpiochip" + m[1], nil
+}
+
+// parseGPIOLine returns either a numeric offset or a line name.
+func parseGPIOLine(args map[string]any) (offset int, name string, errResult *ToolResult) {
+	switch v := args["line"].(type) {
+	case float64:
+		if v < 0 || v != float64(int(v)) {
+			return 0, "", ErrorResult("line offset must be a non-negative integer")
+		}
+		return int(v), "", nil
+	case string:
+		v = strings.TrimSpace(v)
+		if v == "" {
+			break
+		}
+		if n, err := strconv.Atoi(v); err == nil {
+			if n < 0 {
+				return 0, "", ErrorResult("line offset must be a non-negative integer")
+			}
+			return n, "", nil
+		}
+		return -1, v, nil
+	}
+	return 0, "", ErrorResult("line is required (offset such as \"17\" or a line name from detect)")
+}
+
+func parseGPIOValue(args map[string]any) (int, *ToolResult) {
+	v, ok := args["value"].(float64)
+	if !ok || (v != 0 && v != 1) {
+		return 0, ErrorResult("value is required and must be 0 or 1")
+	}
+	return int(v), nil
+}
+
+func parseGPIOTimeout(args map[string]any) (int, *ToolResult) {
+	v, ok := args["timeout_ms"].(float64)
+	if !ok {
+		return gpioDefaultWaitMs, nil
+	}
+	if v < 1 || v > gpioMaxWaitMs {
+		return 0, ErrorResult(fmt.Sprintf("timeout_ms must be between 1 and %d", gpioMaxWaitMs))
+	}
+	return int(v), nil
+}
+
+func gpioLineKey(chip string, offset uint32) string {
+	return fmt.Sprintf("%s:%d", chip, offset)
+}
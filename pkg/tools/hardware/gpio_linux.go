@@ -0,0 +1,638 @@
+package hardwaretools
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// GPIO character device uAPI v2 constants from <linux/gpio.h>.
+// Calculated from _IOR/_IOWR(0xB4, nr, size):
+//
+//	direction<<30 | size<<16 | 0xB4<<8 | nr
+const (
+	gpioGetChipInfoIoctl     = 0x8044B401 // _IOR(0xB4, 0x01, struct gpiochip_info)
+	gpioV2GetLineInfoIoctl   = 0xC100B405 // _IOWR(0xB4, 0x05, struct gpio_v2_line_info)
+	gpioV2GetLineIoctl       = 0xC250B407 // _IOWR(0xB4, 0x07, struct gpio_v2_line_request)
+	gpioV2LineGetValuesIoctl = 0xC010B40E // _IOWR(0xB4, 0x0E, struct gpio_v2_line_values)
+	gpioV2LineSetValuesIoctl = 0xC010B40F // _IOWR(0xB4, 0x0F, struct gpio_v2_line_values)
+
+	gpioV2LineFlagUsed         = 1 << 0
+	gpioV2LineFlagActiveLow    = 1 << 1
+	gpioV2LineFlagInput        = 1 << 2
+	gpioV2LineFlagOutput       = 1 << 3
+	gpioV2LineFlagEdgeRising   = 1 << 4
+	gpioV2LineFlagEdgeFalling  = 1 << 5
+	gpioV2LineFlagOpenDrain    = 1 << 6
+	gpioV2LineFlagOpenSource   = 1 << 7
+	gpioV2LineFlagBiasPullUp   = 1 << 8
+	gpioV2LineFlagBiasPullDown = 1 << 9
+	gpioV2LineFlagBiasDisabled = 1 << 10
+
+	gpioV2LineAttrIDOutputValues = 2
+
+	gpioV2LineEventRisingEdge  = 1
+	gpioV2LineEventFallingEdge = 2
+
+	gpioV2LineEventSize = 48
+)
+
+// gpioChipInfo matches struct gpiochip_info (68 bytes).
+type gpioChipInfo struct {
+	name  [32]byte
+	label [32]byte
+	lines uint32
+}
+
+// gpioV2LineAttribute matches struct gpio_v2_line_attribute (16 bytes); the
+// trailing union holds flags, output values or the debounce period.
+type gpioV2LineAttribute struct {
+	id      uint32
+	padding uint32
+	value   uint64
+}
+
+// gpioV2LineConfigAttribute matches struct gpio_v2_line_config_attribute.
+type gpioV2LineConfigAttribute struct {
+	attr gpioV2LineAttribute
+	mask uint64
+}
+
+// gpioV2LineConfig matches struct gpio_v2_line_config (272 bytes).
+type gpioV2LineConfig struct {
+	flags    uint64
+	numAttrs uint32
+	padding  [5]uint32
+	attrs    [10]gpioV2LineConfigAttribute
+}
+
+// gpioV2LineRequest matches struct gpio_v2_line_request (592 bytes).
+type gpioV2LineRequest struct {
+	offsets         [64]uint32
+	consumer        [32]byte
+	config          gpioV2LineConfig
+	numLines        uint32
+	eventBufferSize uint32
+	padding         [5]uint32
+	fd              int32
+}
+
+// gpioV2LineValues matches struct gpio_v2_line_values.
+type gpioV2LineValues struct {
+	bits uint64
+	mask uint64
+}
+
+// gpioV2LineInfo matches struct gpio_v2_line_info (256 bytes).
+type gpioV2LineInfo struct {
+	name     [32]byte
+	consumer [32]byte
+	offset   uint32
+	numAttrs uint32
+	flags    uint64
+	attrs    [10]gpioV2LineAttribute
+	padding  [4]uint32
+}
+
+// gpioIoctl issues a GPIO ioctl; tests replace it to emulate a chip.
+var gpioIoctl = func(fd int, req uint, arg unsafe.Pointer) error {
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), uintptr(req), uintptr(arg))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+func cString(b []byte) string {
+	for i, c := range b {
+		if c == 0 {
+			return string(b[:i])
+		}
+	}
+	return string(b)
+}
+
+func (t *GPIOTool) chipPath(chip string) string {
+	return filepath.Join(t.devDir, chip)
+}
+
+func openGPIOChip(path string) (int, error) {
+	fd, err := unix.Open(path, unix.O_RDWR|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return -1, fmt.Errorf("failed to open %s: %w (check permissions and that the GPIO character device is enabled)", path, err)
+	}
+	return fd, nil
+}
+
+func gpioChipInfoOf(fd int) (gpioChipInfo, error) {
+	var info gpioChipInfo
+	err := gpioIoctl(fd, gpioGetChipInfoIoctl, unsafe.Pointer(&info))
+	return info, err
+}
+
+func gpioLineInfoOf(fd int, offset uint32) (gpioV2LineInfo, error) {
+	info := gpioV2LineInfo{offset: offset}
+	err := gpioIoctl(fd, gpioV2GetLineInfoIoctl, unsafe.Pointer(&info))
+	return info, err
+}
+
+// requestGPIOLine requests a single line and returns the line fd. When
+// output is non-nil the line starts driven to *output.
+func requestGPIOLine(chipFd int, offset uint32, flags uint64, output *int) (int, error) {
+	var req gpioV2LineRequest
+	req.offsets[0] = offset
+	req.numLines = 1
+	copy(req.consumer[:], gpioConsumer)
+	req.config.flags = flags
+	if output != nil {
+		req.config.numAttrs = 1
+		req.config.attrs[0] = gpioV2LineConfigAttribute{
+			attr: gpioV2LineAttribute{id: gpioV2LineAttrIDOutputValues, value: uint64(*output)},
+			mask: 1,
+		}
+	}
+	if err := gpioIoctl(chipFd, gpioV2GetLineIoctl, unsafe.Pointer(&req)); err != nil {
+		if errors.Is(err, unix.EBUSY) {
+			return -1, fmt.Errorf("line %d is in use by another consumer", offset)
+		}
+		return -1, err
+	}
+	return int(req.fd), nil
+}
+
+func gpioGetValue(lineFd int) (int, error) {
+	values := gpioV2LineValues{mask: 1}
+	if err := gpioIoctl(lineFd, gpioV2LineGetValuesIoctl, unsafe.Pointer(&values)); err != nil {
+		return 0, err
+	}
+	return int(values.bits & 1), nil
+}
+
+func gpioSetValue(lineFd int, value int) error {
+	values := gpioV2LineValues{bits: uint64(value), mask: 1}
+	return gpioIoctl(lineFd, gpioV2LineSetValuesIoctl, unsafe.Pointer(&values))
+}
+
+type gpioLineDesc struct {
+	Offset    uint32 `json:"offset"`
+	Name      string `json:"name,omitempty"`
+	Consumer  string `json:"consumer,omitempty"`
+	Used      bool   `json:"used"`
+	Direction string `json:"direction"`
+	ActiveLow bool   `json:"active_low,omitempty"`
+	Bias      string `json:"bias,omitempty"`
+	Drive     string `json:"drive,omitempty"`
+	Edge      string `json:"edge,omitempty"`
+}
+
+func describeGPIOLine(info gpioV2LineInfo) gpioLineDesc {
+	desc := gpioLineDesc{
+		Offset:    info.offset,
+		Name:      cString(info.name[:]),
+		Consumer:  cString(info.consumer[:]),
+		Used:      info.flags&gpioV2LineFlagUsed != 0,
+		Direction: "input",
+		ActiveLow: info.flags&gpioV2LineFlagActiveLow != 0,
+	}
+	if info.flags&gpioV2LineFlagOutput != 0 {
+		desc.Direction = "output"
+	}
+	switch {
+	case info.flags&gpioV2LineFlagBiasPullUp != 0:
+		desc.Bias = "pull-up"
+	case info.flags&gpioV2LineFlagBiasPullDown != 0:
+		desc.Bias = "pull-down"
+	case info.flags&gpioV2LineFlagBiasDisabled != 0:
+		desc.Bias = "disabled"
+	}
+	switch {
+	case info.flags&gpioV2LineFlagOpenDrain != 0:
+		desc.Drive = "open-drain"
+	case info.flags&gpioV2LineFlagOpenSource != 0:
+		desc.Drive = "open-source"
+	}
+	switch info.flags & (gpioV2LineFlagEdgeRising | gpioV2LineFlagEdgeFalling) {
+	case gpioV2LineFlagEdgeRising:
+		desc.Edge = "rising"
+	case gpioV2LineFlagEdgeFalling:
+		desc.Edge = "falling"
+	case gpioV2LineFlagEdgeRising | gpioV2LineFlagEdgeFalling:
+		desc.Edge = "both"
+	}
+	return desc
+}
+
+func (t *GPIOTool) listChips() ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(t.devDir, "gpiochip*"))
+	if err != nil {
+		return nil, err
+	}
+	chips := make([]string, 0, len(matches))
+	for _, m := range matches {
+		if name := filepath.Base(m); gpioChipRe.MatchString(name) {
+			chips = append(chips, name)
+		}
+	}
+	sort.Strings(chips)
+	return chips, nil
+}
+
+// detect lists GPIO chips, or the lines of one chip when chip is given.
+func (t *GPIOTool) detect(args map[string]any) *ToolResult {
+	chip, errResult := parseGPIOChip(args)
+	if errResult != nil {
+		return errResult
+	}
+	if chip != "" {
+		return t.detectLines(chip)
+	}
+
+	chips, err := t.listChips()
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to scan for GPIO chips: %v", err))
+	}
+	if len(chips) == 0 {
+		return SilentResult(
+			"No GPIO chips found. The kernel needs CONFIG_GPIO_CDEV and a GPIO controller enabled in the device tree.",
+		)
+	}
+
+	type chipEntry struct {
+		Chip  string `json:"chip"`
+		Label string `json:"label,omitempty"`
+		Lines uint32 `json:"lines"`
+		Error string `json:"error,omitempty"`
+	}
+	entries := make([]chipEntry, 0, len(chips))
+	for _, chip := range chips {
+		entry := chipEntry{Chip: chip}
+		fd, err := openGPIOChip(t.chipPath(chip))
+		if err != nil {
+			entry.Error = err.Error()
+			entries = append(entries, entry)
+			continue
+		}
+		info, err := gpioChipInfoOf(fd)
+		unix.Close(fd)
+		if err != nil {
+			entry.Error = err.Error()
+		} else {
+			entry.Label = cString(info.label[:])
+			entry.Lines = info.lines
+		}
+		entries = append(entries, entry)
+	}
+	result, _ := json.MarshalIndent(entries, "", "  ")
+	return SilentResult(fmt.Sprintf("Found %d GPIO chip(s):\n%s", len(entries), string(result)))
+}
+
+func (t *GPIOTool) detectLines(chip string) *ToolResult {
+	fd, err := openGPIOChip(t.chipPath(chip))
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	defer unix.Close(fd)
+	info, err := gpioChipInfoOf(fd)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to query %s: %v", chip, err))
+	}
+	lines := make([]gpioLineDesc, 0, info.lines)
+	for offset := uint32(0); offset < info.lines; offset++ {
+		li, err := gpioLineInfoOf(fd, offset)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("failed to query %s line %d: %v", chip, offset, err))
+		}
+		lines = append(lines, describeGPIOLine(li))
+	}
+	result, _ := json.MarshalIndent(map[string]any{
+		"chip":  chip,
+		"label": cString(info.label[:]),
+		"lines": lines,
+	}, "", "  ")
+	return SilentResult(string(result))
+}
+
+// resolveLine finds the chip and offset for a line given by offset or name.
+func (t *GPIOTool) resolveLine(args map[string]any) (string, uint32, string, *ToolResult) {
+	chip, errResult := parseGPIOChip(args)
+	if errResult != nil {
+		return "", 0, "", errResult
+	}
+	offset, name, errResult := parseGPIOLine(args)
+	if errResult != nil {
+		return "", 0, "", errResult
+	}
+
+	if name == "" {
+		if chip == "" {
+			return "", 0, "", ErrorResult("chip is required when line is an offset")
+		}
+		fd, err := openGPIOChip(t.chipPath(chip))
+		if err != nil {
+			return "", 0, "", ErrorResult(err.Error())
+		}
+		defer unix.Close(fd)
+		info, err := gpioChipInfoOf(fd)
+		if err != nil {
+			return "", 0, "", ErrorResult(fmt.Sprintf("failed to query %s: %v", chip, err))
+		}
+		if uint32(offset) >= info.lines {
+			return "", 0, "", ErrorResult(fmt.Sprintf("line %d out of range: %s has %d lines", offset, chip, info.lines))
+		}
+		li, err := gpioLineInfoOf(fd, uint32(offset))
+		if err != nil {
+			return "", 0, "", ErrorResult(fmt.Sprintf("failed to query %s line %d: %v", chip, offset, err))
+		}
+		return chip, uint32(offset), cString(li.name[:]), nil
+	}
+
+	chips := []string{chip}
+	if chip == "" {
+		var err error
+		if chips, err = t.listChips(); err != nil {
+			return "", 0, "", ErrorResult(fmt.Sprintf("failed to scan for GPIO chips: %v", err))
+		}
+	}
+	type match struct {
+		chip   string
+		offset uint32
+	}
+	var found []match
+	for _, c := range chips {
+		fd, err := openGPIOChip(t.chipPath(c))
+		if err != nil {
+			continue
+		}
+		info, err := gpioChipInfoOf(fd)
+		for off := uint32(0); err == nil && off < info.lines; off++ {
+			var li gpioV2LineInfo
+			if li, err = gpioLineInfoOf(fd, off); err == nil && cString(li.name[:]) == name {
+				found = append(found, match{chip: c, offset: off})
+			}
+		}
+		unix.Close(fd)
+	}
+	switch len(found) {
+	case 0:
+		return "", 0, "", ErrorResult(fmt.Sprintf("no GPIO line named %q (use detect with a chip to list line names)", name))
+	case 1:
+		return found[0].chip, found[0].offset, name, nil
+	default:
+		return "", 0, "", ErrorResult(fmt.Sprintf("line name %q exists on several chips; pass chip to choose one", name))
+	}
+}
+
+func gpioBiasFlags(args map[string]any) (uint64, *ToolResult) {
+	bias, _ := args["bias"].(string)
+	switch bias {
+	case "", "as-is":
+		return 0, nil
+	case "pull-up":
+		return gpioV2LineFlagBiasPullUp, nil
+	case "pull-down":
+		return gpioV2LineFlagBiasPullDown, nil
+	case "disabled":
+		return gpioV2LineFlagBiasDisabled, nil
+	default:
+		return 0, ErrorResult("bias must be one of: as-is, pull-up, pull-down, disabled")
+	}
+}
+
+func gpioDriveFlags(args map[string]any) (uint64, *ToolResult) {
+	drive, _ := args["drive"].(string)
+	switch drive {
+	case "", "push-pull":
+		return 0, nil
+	case "open-drain":
+		return gpioV2LineFlagOpenDrain, nil
+	case "open-source":
+		return gpioV2LineFlagOpenSource, nil
+	default:
+		return 0, ErrorResult("drive must be one of: push-pull, open-drain, open-source")
+	}
+}
+
+func gpioLineResult(chip string, offset uint32, name string, fields map[string]any) *ToolResult {
+	out := map[string]any{"chip": chip, "line": offset}
+	if name != "" {
+		out["name"] = name
+	}
+	for k, v := range fields {
+		out[k] = v
+	}
+	result, _ := json.MarshalIndent(out, "", "  ")
+	return SilentResult(string(result))
+}
+
+// read samples a line. Lines held as outputs by write report their level
+// through the held request.
+func (t *GPIOTool) read(args map[string]any) *ToolResult {
+	chip, offset, name, errResult := t.resolveLine(args)
+	if errResult != nil {
+		return errResult
+	}
+	bias, errResult := gpioBiasFlags(args)
+	if errResult != nil {
+		return errResult
+	}
+
+	t.mu.Lock()
+	held := t.held[gpioLineKey(chip, offset)]
+	t.mu.Unlock()
+	if held != nil {
+		value, err := gpioGetValue(held.fd)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("failed to read %s line %d: %v", chip, offset, err))
+		}
+		return gpioLineResult(chip, offset, name, map[string]any{"value": value, "direction": "output"})
+	}
+
+	chipFd, err := openGPIOChip(t.chipPath(chip))
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	defer unix.Close(chipFd)
+	lineFd, err := requestGPIOLine(chipFd, offset, gpioV2LineFlagInput|bias, nil)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to request %s line %d: %v", chip, offset, err))
+	}
+	defer unix.Close(lineFd)
+	value, err := gpioGetValue(lineFd)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to read %s line %d: %v", chip, offset, err))
+	}
+	return gpioLineResult(chip, offset, name, map[string]any{"value": value, "direction": "input"})
+}
+
+// write drives a line and keeps the request open so the level persists
+// until release.
+func (t *GPIOTool) write(args map[string]any) *ToolResult {
+	if confirm, _ := args["confirm"].(bool); !confirm {
+		return ErrorResult(
+			"confirm must be true for write operations. Driving a GPIO can switch relays, motors or short a pin that is wired as an output elsewhere.",
+		)
+	}
+	chip, offset, name, errResult := t.resolveLine(args)
+	if errResult != nil {
+		return errResult
+	}
+	value, errResult := parseGPIOValue(args)
+	if errResult != nil {
+		return errResult
+	}
+	drive, errResult := gpioDriveFlags(args)
+	if errResult != nil {
+		return errResult
+	}
+
+	key := gpioLineKey(chip, offset)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if held := t.held[key]; held != nil {
+		if err := gpioSetValue(held.fd, value); err != nil {
+			return ErrorResult(fmt.Sprintf("failed to set %s line %d: %v", chip, offset, err))
+		}
+		held.value = value
+		return gpioLineResult(chip, offset, name, map[string]any{"value": value, "held": true})
+	}
+
+	chipFd, err := openGPIOChip(t.chipPath(chip))
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	defer unix.Close(chipFd)
+	lineFd, err := requestGPIOLine(chipFd, offset, gpioV2LineFlagOutput|drive, &value)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to request %s line %d as output: %v", chip, offset, err))
+	}
+	t.held[key] = &gpioHeldLine{fd: lineFd, chip: chip, offset: offset, name: name, value: value}
+	return gpioLineResult(chip, offset, name, map[string]any{"value": value, "held": true})
+}
+
+// waitEdge requests a line with edge detection and blocks until an edge
+// event arrives, the timeout passes or ctx is cancelled.
+func (t *GPIOTool) waitEdge(ctx context.Context, args map[string]any) *ToolResult {
+	chip, offset, name, errResult := t.resolveLine(args)
+	if errResult != nil {
+		return errResult
+	}
+	bias, errResult := gpioBiasFlags(args)
+	if errResult != nil {
+		return errResult
+	}
+	timeoutMs, errResult := parseGPIOTimeout(args)
+	if errResult != nil {
+		return errResult
+	}
+	var edge uint64
+	switch e, _ := args["edge"].(string); e {
+	case "", "both":
+		edge = gpioV2LineFlagEdgeRising | gpioV2LineFlagEdgeFalling
+	case "rising":
+		edge = gpioV2LineFlagEdgeRising
+	case "falling":
+		edge = gpioV2LineFlagEdgeFalling
+	default:
+		return ErrorResult("edge must be one of: rising, falling, both")
+	}
+
+	t.mu.Lock()
+	held := t.held[gpioLineKey(chip, offset)]
+	t.mu.Unlock()
+	if held != nil {
+		return ErrorResult(fmt.Sprintf("%s line %d is held as an output; release it before waiting for edges", chip, offset))
+	}
+
+	chipFd, err := openGPIOChip(t.chipPath(chip))
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	lineFd, err := requestGPIOLine(chipFd, offset, gpioV2LineFlagInput|edge|bias, nil)
+	unix.Close(chipFd)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to request %s line %d for edge events: %v", chip, offset, err))
+	}
+	defer unix.Close(lineFd)
+
+	started := time.Now()
+	deadline := started.Add(time.Duration(timeoutMs) * time.Millisecond)
+	fds := []unix.PollFd{{Fd: int32(lineFd), Events: unix.POLLIN}}
+	for {
+		if err := ctx.Err(); err != nil {
+			return ErrorResult(fmt.Sprintf("wait_edge cancelled: %v", err))
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			value, _ := gpioGetValue(lineFd)
+			return gpioLineResult(chip, offset, name, map[string]any{
+				"timed_out": true,
+				"value":     value,
+				"waited_ms": time.Since(started).Milliseconds(),
+			})
+		}
+		// Poll in short slices so a cancelled turn does not wait out the timeout.
+		n, err := unix.Poll(fds, int(min(remaining, 100*time.Millisecond)/time.Millisecond)+1)
+		if err != nil {
+			if errors.Is(err, unix.EINTR) {
+				continue
+			}
+			return ErrorResult(fmt.Sprintf("failed to wait for edge: %v", err))
+		}
+		if n == 0 {
+			continue
+		}
+		buf := make([]byte, gpioV2LineEventSize)
+		if _, err := unix.Read(lineFd, buf); err != nil {
+			return ErrorResult(fmt.Sprintf("failed to read edge event: %v", err))
+		}
+		kind := "unknown"
+		switch binary.NativeEndian.Uint32(buf[8:]) {
+		case gpioV2LineEventRisingEdge:
+			kind = "rising"
+		case gpioV2LineEventFallingEdge:
+			kind = "falling"
+		}
+		return gpioLineResult(chip, offset, name, map[string]any{
+			"edge":         kind,
+			"timestamp_ns": binary.NativeEndian.Uint64(buf[0:]),
+			"waited_ms":    time.Since(started).Milliseconds(),
+		})
+	}
+}
+
+// release frees lines held as outputs, either one line or all of them.
+func (t *GPIOTool) release(args map[string]any) *ToolResult {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var keys []string
+	if _, ok := args["line"]; ok {
+		chip, offset, _, errResult := t.resolveLine(args)
+		if errResult != nil {
+			return errResult
+		}
+		key := gpioLineKey(chip, offset)
+		if t.held[key] == nil {
+			return ErrorResult(fmt.Sprintf("%s line %d is not held", chip, offset))
+		}
+		keys = []string{key}
+	} else {
+		for key := range t.held {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+	}
+	for _, key := range keys {
+		unix.Close(t.held[key].fd)
+		delete(t.held, key)
+	}
+	return SilentResult(fmt.Sprintf("Released %d GPIO line(s)", len(keys)))
+}
@@ -0,0 +1,275 @@
+package hardwaretools
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// fakeGPIOChip emulates one gpiochip behind the gpioIoctl hook. Requested
+// lines are backed by pipes so edge events can be injected.
+type fakeGPIOChip struct {
+	t      *testing.T
+	names  []string
+	mu     sync.Mutex
+	levels map[uint32]int
+	lines  map[int]*fakeGPIORequest // keyed by line fd
+}
+
+type fakeGPIORequest struct {
+	offset uint32
+	flags  uint64
+	writer int
+}
+
+func newFakeGPIOTool(t *testing.T, names ...string) (*GPIOTool, *fakeGPIOChip) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "gpiochip0"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	chip := &fakeGPIOChip{t: t, names: names, levels: map[uint32]int{}, lines: map[int]*fakeGPIORequest{}}
+	orig := gpioIoctl
+	gpioIoctl = chip.ioctl
+	t.Cleanup(func() {
+		gpioIoctl = orig
+		for fd, req := range chip.lines {
+			unix.Close(fd)
+			unix.Close(req.writer)
+		}
+	})
+	tool := NewGPIOTool()
+	tool.devDir = dir
+	return tool, chip
+}
+
+func (c *fakeGPIOChip) ioctl(fd int, req uint, arg unsafe.Pointer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch req {
+	case gpioGetChipInfoIoctl:
+		info := (*gpioChipInfo)(arg)
+		copy(info.name[:], "gpiochip0")
+		copy(info.label[:], "fake-gpio")
+		info.lines = uint32(len(c.names))
+	case gpioV2GetLineInfoIoctl:
+		info := (*gpioV2LineInfo)(arg)
+		if int(info.offset) >= len(c.names) {
+			return unix.EINVAL
+		}
+		copy(info.name[:], c.names[info.offset])
+		for lineFd, r := range c.lines {
+			if r.offset == info.offset && lineFd >= 0 {
+				info.flags = gpioV2LineFlagUsed | r.flags
+				copy(info.consumer[:], gpioConsumer)
+			}
+		}
+	case gpioV2GetLineIoctl:
+		r := (*gpioV2LineRequest)(arg)
+		if r.numLines != 1 || cString(r.consumer[:]) != gpioConsumer {
+			c.t.Errorf("line request = %d lines, consumer %q", r.numLines, cString(r.consumer[:]))
+		}
+		var p [2]int
+		if err := unix.Pipe2(p[:], unix.O_CLOEXEC); err != nil {
+			return err
+		}
+		offset := r.offsets[0]
+		if r.config.flags&gpioV2LineFlagOutput != 0 && r.config.numAttrs == 1 &&
+			r.config.attrs[0].attr.id == gpioV2LineAttrIDOutputValues {
+			c.levels[offset] = int(r.config.attrs[0].attr.value & r.config.attrs[0].mask)
+		}
+		c.lines[p[0]] = &fakeGPIORequest{offset: offset, flags: r.config.flags, writer: p[1]}
+		r.fd = int32(p[0])
+	case gpioV2LineGetValuesIoctl:
+		v := (*gpioV2LineValues)(arg)
+		v.bits = uint64(c.levels[c.lines[fd].offset])
+	case gpioV2LineSetValuesIoctl:
+		v := (*gpioV2LineValues)(arg)
+		if c.lines[fd].flags&gpioV2LineFlagOutput == 0 {
+			return unix.EPERM
+		}
+		c.levels[c.lines[fd].offset] = int(v.bits & v.mask)
+	default:
+		c.t.Fatalf("unexpected ioctl %#x", req)
+	}
+	return nil
+}
+
+func (c *fakeGPIOChip) request(offset uint32) (int, *fakeGPIORequest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for fd, r := range c.lines {
+		if r.offset == offset {
+			return fd, r
+		}
+	}
+	return -1, nil
+}
+
+func decodeGPIOResult(t *testing.T, result *ToolResult) map[string]any {
+	t.Helper()
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	var out map[string]any
+	if err := json.Unmarshal([]byte(result.ForLLM), &out); err != nil {
+		t.Fatalf("result is not JSON: %v\n%s", err, result.ForLLM)
+	}
+	return out
+}
+
+func TestGPIOStructSizesMatchKernel(t *testing.T) {
+	sizes := map[string][2]uintptr{
+		"gpiochip_info":          {unsafe.Sizeof(gpioChipInfo{}), 68},
+		"gpio_v2_line_request":   {unsafe.Sizeof(gpioV2LineRequest{}), 592},
+		"gpio_v2_line_info":      {unsafe.Sizeof(gpioV2LineInfo{}), 256},
+		"gpio_v2_line_config":    {unsafe.Sizeof(gpioV2LineConfig{}), 272},
+		"gpio_v2_line_values":    {unsafe.Sizeof(gpioV2LineValues{}), 16},
+		"gpio_v2_line_attribute": {unsafe.Sizeof(gpioV2LineAttribute{}), 16},
+	}
+	for name, s := range sizes {
+		if s[0] != s[1] {
+			t.Errorf("sizeof(%s) = %d, want %d", name, s[0], s[1])
+		}
+	}
+}
+
+func TestGPIOTool_DetectListsChipsAndLines(t *testing.T) {
+	tool, _ := newFakeGPIOTool(t, "", "LED1", "BUTTON")
+	result := tool.Execute(context.Background(), map[string]any{"action": "detect"})
+	if result.IsError || !strings.Contains(result.ForLLM, `"label": "fake-gpio"`) ||
+		!strings.Contains(result.ForLLM, `"lines": 3`) {
+		t.Fatalf("detect = %s", result.ForLLM)
+	}
+
+	out := decodeGPIOResult(t, tool.Execute(context.Background(), map[string]any{"action": "detect", "chip": "0"}))
+	lines := out["lines"].([]any)
+	if len(lines) != 3 || lines[1].(map[string]any)["name"] != "LED1" {
+		t.Fatalf("detect chip lines = %v", lines)
+	}
+}
+
+func TestGPIOTool_WriteHoldsLineUntilRelease(t *testing.T) {
+	tool, chip := newFakeGPIOTool(t, "", "LED1", "BUTTON")
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{"action": "write", "line": "LED1", "value": float64(1)})
+	if !result.IsError || !strings.Contains(result.ForLLM, "confirm") {
+		t.Fatalf("write without confirm = %+v", result)
+	}
+
+	out := decodeGPIOResult(t, tool.Execute(ctx, map[string]any{
+		"action": "write", "line": "LED1", "value": float64(1), "confirm": true,
+	}))
+	if out["chip"] != "gpiochip0" || out["line"] != float64(1) || out["held"] != true {
+		t.Fatalf("write = %v", out)
+	}
+	fd, req := chip.request(1)
+	if req == nil || req.flags&gpioV2LineFlagOutput == 0 || chip.levels[1] != 1 {
+		t.Fatalf("line 1 request = %+v, level = %d", req, chip.levels[1])
+	}
+
+	decodeGPIOResult(t, tool.Execute(ctx, map[string]any{
+		"action": "write", "chip": "gpiochip0", "line": "1", "value": float64(0), "confirm": true,
+	}))
+	if again, _ := chip.request(1); again != fd || chip.levels[1] != 0 {
+		t.Fatalf("second write re-requested the line or did not set it: fd %d→%d, level %d", fd, again, chip.levels[1])
+	}
+	out = decodeGPIOResult(t, tool.Execute(ctx, map[string]any{"action": "read", "line": "LED1"}))
+	if out["value"] != float64(0) || out["direction"] != "output" {
+		t.Fatalf("read held line = %v", out)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "wait_edge", "line": "LED1"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "release") {
+		t.Fatalf("wait_edge on held line = %+v", result)
+	}
+	result = tool.Execute(ctx, map[string]any{"action": "release"})
+	if result.IsError || !strings.Contains(result.ForLLM, "Released 1") {
+		t.Fatalf("release = %+v", result)
+	}
+	if len(tool.held) != 0 {
+		t.Fatalf("held lines after release = %v", tool.held)
+	}
+}
+
+func TestGPIOTool_ReadRequestsInputWithBias(t *testing.T) {
+	tool, chip := newFakeGPIOTool(t, "", "LED1", "BUTTON")
+	chip.levels[2] = 1
+	out := decodeGPIOResult(t, tool.Execute(context.Background(), map[string]any{
+		"action": "read", "chip": "0", "line": float64(2), "bias": "pull-up",
+	}))
+	if out["value"] != float64(1) || out["name"] != "BUTTON" {
+		t.Fatalf("read = %v", out)
+	}
+	_, req := chip.request(2)
+	if req == nil || req.flags != gpioV2LineFlagInput|gpioV2LineFlagBiasPullUp {
+		t.Fatalf("read request = %+v", req)
+	}
+
+	result := tool.Execute(context.Background(), map[string]any{"action": "read", "chip": "0", "line": "7"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "out of range") {
+		t.Fatalf("read out of range = %+v", result)
+	}
+	result = tool.Execute(context.Background(), map[string]any{"action": "read", "line": "MISSING"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "no GPIO line named") {
+		t.Fatalf("read unknown name = %+v", result)
+	}
+}
+
+func TestGPIOTool_WaitEdge(t *testing.T) {
+	tool, chip := newFakeGPIOTool(t, "", "LED1", "BUTTON")
+	ctx := context.Background()
+
+	out := decodeGPIOResult(t, tool.Execute(ctx, map[string]any{
+		"action": "wait_edge", "line": "BUTTON", "edge": "falling", "timeout_ms": float64(50),
+	}))
+	if out["timed_out"] != true {
+		t.Fatalf("wait_edge without event = %v", out)
+	}
+
+	// Queue a falling-edge event on the next line request.
+	orig := gpioIoctl
+	gpioIoctl = func(fd int, req uint, arg unsafe.Pointer) error {
+		err := orig(fd, req, arg)
+		if req == gpioV2GetLineIoctl && err == nil {
+			r := (*gpioV2LineRequest)(arg)
+			if r.config.flags&gpioV2LineFlagEdgeFalling == 0 || r.config.flags&gpioV2LineFlagEdgeRising != 0 {
+				t.Errorf("edge request flags = %#x", r.config.flags)
+			}
+			chip.mu.Lock()
+			fake := chip.lines[int(r.fd)]
+			chip.mu.Unlock()
+			event := make([]byte, gpioV2LineEventSize)
+			binary.NativeEndian.PutUint64(event[0:], 123456789)
+			binary.NativeEndian.PutUint32(event[8:], gpioV2LineEventFallingEdge)
+			binary.NativeEndian.PutUint32(event[12:], r.offsets[0])
+			if _, err := unix.Write(fake.writer, event); err != nil {
+				t.Errorf("write event: %v", err)
+			}
+		}
+		return err
+	}
+	out = decodeGPIOResult(t, tool.Execute(ctx, map[string]any{
+		"action": "wait_edge", "line": "BUTTON", "edge": "falling",
+	}))
+	if out["edge"] != "falling" || out["timestamp_ns"] != float64(123456789) {
+		t.Fatalf("wait_edge = %v", out)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	gpioIoctl = orig
+	result := tool.Execute(cancelled, map[string]any{"action": "wait_edge", "line": "BUTTON"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "cancelled") {
+		t.Fatalf("wait_edge with cancelled context = %+v", result)
+	}
+}
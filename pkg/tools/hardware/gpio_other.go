@@ -0,0 +1,30 @@
+//go:build !linux
+
+package hardwaretools
+
+import "context"
+
+// detect is a stub for non-Linux platforms.
+func (t *GPIOTool) detect(args map[string]any) *ToolResult {
+	return ErrorResult("GPIO is only supported on Linux")
+}
+
+// read is a stub for non-Linux platforms.
+func (t *GPIOTool) read(args map[string]any) *ToolResult {
+	return ErrorResult("GPIO is only supported on Linux")
+}
+
+// write is a stub for non-Linux platforms.
+func (t *GPIOTool) write(args map[string]any) *ToolResult {
+	return ErrorResult("GPIO is only supported on Linux")
+}
+
+// waitEdge is a stub for non-Linux platforms.
+func (t *GPIOTool) waitEdge(ctx context.Context, args map[string]any) *ToolResult {
+	return ErrorResult("GPIO is only supported on Linux")
+}
+
+// release is a stub for non-Linux platforms.
+func (t *GPIOTool) release(args map[string]any) *ToolResult {
+	return ErrorResult("GPIO is only supported on Linux")
+}
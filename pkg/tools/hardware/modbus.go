@@ -0,0 +1,796 @@
+package hardwaretools
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+const (
+	defaultModbusTimeout  = time.Second
+	defaultModbusTCPPort  = "502"
+	defaultModbusUnitID   = 1
+	defaultModbusRTUBaud  = 9600
+	maxModbusReadBits     = 2000
+	maxModbusReadRegs     = 125
+	maxModbusWriteBits    = 1968
+	maxModbusWriteRegs    = 123
+	maxModbusADUBytes     = 260
+	modbusExceptionFlag   = 0x80
+	modbusFuncReadCoils   = 0x01
+	modbusFuncReadInputs  = 0x02
+	modbusFuncReadHolding = 0x03
+	modbusFuncReadInRegs  = 0x04
+	modbusFuncWriteCoil   = 0x05
+	modbusFuncWriteReg    = 0x06
+	modbusFuncWriteCoils  = 0x0F
+	modbusFuncWriteRegs   = 0x10
+)
+
+var (
+	modbusDeviceNameRe = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+	modbusRangeRe      = regexp.MustCompile(`^(coil|register):(\d+)(?:-(\d+))?$`)
+
+	// modbusSerialExchange is swapped in tests to emulate an RTU device.
+	modbusSerialExchange = serialExchange
+)
+
+var modbusExceptions = map[byte]string{
+	0x01: "illegal function",
+	0x02: "illegal data address",
+	0x03: "illegal data value",
+	0x04: "server device failure",
+	0x05: "acknowledge (request accepted, processing)",
+	0x06: "server device busy",
+	0x08: "memory parity error",
+	0x0A: "gateway path unavailable",
+	0x0B: "gateway target device failed to respond",
+}
+
+// modbusTables maps the table names the agent uses to their read function
+// codes and whether values are single bits.
+var modbusTables = map[string]struct {
+	readFunc byte
+	bits     bool
+	writable bool
+}{
+	"coils":             {modbusFuncReadCoils, true, true},
+	"discrete_inputs":   {modbusFuncReadInputs, true, false},
+	"holding_registers": {modbusFuncReadHolding, false, true},
+	"input_registers":   {modbusFuncReadInRegs, false, false},
+}
+
+// ModbusTool reads and writes coils and registers on Modbus TCP and RTU
+// devices listed in tools.modbus.devices.
+type ModbusTool struct {
+	timeout time.Duration
+	devices map[string]*modbusDevice
+	txID    atomic.Uint32
+}
+
+type modbusDevice struct {
+	name     string
+	address  string        // host:port for Modbus TCP
+	serial   *serialConfig // set for Modbus RTU
+	units    []byte
+	writable []modbusRange
+}
+
+type modbusRange struct {
+	kind     string // "coil" or "register"
+	from, to uint16
+}
+
+func NewModbusTool(cfg config.ModbusConfig) (*ModbusTool, error) {
+	t := &ModbusTool{
+		timeout: defaultModbusTimeout,
+		devices: make(map[string]*modbusDevice, len(cfg.Devices)),
+	}
+	if cfg.TimeoutMS > 0 {
+		t.timeout = time.Duration(cfg.TimeoutMS) * time.Millisecond
+	}
+	for i, dc := range cfg.Devices {
+		dev, err := newModbusDevice(dc)
+		if err != nil {
+			return nil, fmt.Errorf("modbus device %d (%q): %w", i, dc.Name, err)
+		}
+		if _, dup := t.devices[dev.name]; dup {
+			return nil, fmt.Errorf("modbus device %q is configured twice", dev.name)
+		}
+		t.devices[dev.name] = dev
+	}
+	return t, nil
+}
+
+func newModbusDevice(dc config.ModbusDeviceConfig) (*modbusDevice, error) {
+	if !modbusDeviceNameRe.MatchString(dc.Name) {
+		return nil, fmt.Errorf("name must be 1-64 letters, digits, '.', '_' or '-'")
+	}
+	dev := &modbusDevice{name: dc.Name}
+
+	address := strings.TrimSpace(dc.Address)
+	port := strings.TrimSpace(dc.Port)
+	switch {
+	case address != "" && port != "":
+		return nil, fmt.Errorf("set either address (TCP) or port (RTU), not both")
+	case address != "":
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			address = net.JoinHostPort(address, defaultModbusTCPPort)
+		}
+		dev.address = address
+	case port != "":
+		normalized, err := normalizeSerialPort(port)
+		if err != nil {
+			return nil, err
+		}
+		sc := serialConfig{
+			Port:     normalized,
+			Baud:     defaultModbusRTUBaud,
+			DataBits: defaultSerialDataBits,
+			Parity:   "even",
+			StopBits: defaultSerialStopBits,
+		}
+		if dc.Baud > 0 {
+			sc.Baud = dc.Baud
+		}
+		if dc.DataBits > 0 {
+			sc.DataBits = dc.DataBits
+		}
+		if dc.Parity != "" {
+			sc.Parity = strings.ToLower(dc.Parity)
+		}
+		if dc.StopBits > 0 {
+			sc.StopBits = dc.StopBits
+		}
+		if err := validateSerialBaud(sc.Baud); err != nil {
+			return nil, err
+		}
+		if sc.Parity != "none" && sc.Parity != "even" && sc.Parity != "odd" {
+			return nil, fmt.Errorf(`parity must be one of "none", "even", or "odd"`)
+		}
+		dev.serial = &sc
+	default:
+		return nil, fmt.Errorf("address (TCP) or port (RTU) is required")
+	}
+
+	for _, u := range dc.UnitIDs {
+		if u < 1 || u > 247 {
+			return nil, fmt.Errorf("unit id %d out of range (1-247)", u)
+		}
+		dev.units = append(dev.units, byte(u))
+	}
+	if len(dev.units) == 0 {
+		dev.units = []byte{defaultModbusUnitID}
+	}
+
+	for _, raw := range dc.WritableRanges {
+		r, err := parseModbusRange(raw)
+		if err != nil {
+			return nil, err
+		}
+		dev.writable = append(dev.writable, r)
+	}
+	return dev, nil
+}
+
+func parseModbusRange(raw string) (modbusRange, error) {
+	m := modbusRangeRe.FindStringSubmatch(strings.ToLower(strings.TrimSpace(raw)))
+	if m == nil {
+		return modbusRange{}, fmt.Errorf(`invalid writable range %q (want "coil:0-15" or "register:100-120")`, raw)
+	}
+	from, err1 := strconv.ParseUint(m[2], 10, 16)
+	to := from
+	var err2 error
+	if m[3] != "" {
+		to, err2 = strconv.ParseUint(m[3], 10, 16)
+	}
+	if err1 != nil || err2 != nil || to < from {
+		return modbusRange{}, fmt.Errorf("invalid writable range %q: addresses must be 0-65535 and ascending", raw)
+	}
+	return modbusRange{kind: m[1], from: uint16(from), to: uint16(to)}, nil
+}
+
+func (d *modbusDevice) transport() string {
+	if d.serial != nil {
+		return "rtu"
+	}
+	return "tcp"
+}
+
+func (d *modbusDevice) canWrite(kind string, address, count int) bool {
+	last := address + count - 1
+	for _, r := range d.writable {
+		if r.kind == kind && address >= int(r.from) && last <= int(r.to) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *ModbusTool) Name() string {
+	return "modbus"
+}
+
+func (t *ModbusTool) Description() string {
+	return "Read and write Modbus devices over TCP or RTU (serial). Only devices configured in tools.modbus.devices can be used. Actions: list (configured devices), read (coils, discrete_inputs, holding_registers, input_registers), write (coils or holding_registers within the device's writable ranges; requires confirm). Addresses are 0-based protocol addresses, not 40001-style register numbers."
+}
+
+func (t *ModbusTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"list", "read", "write"},
+				"description": "Action to perform: list configured devices, read values, or write values.",
+			},
+			"device": map[string]any{
+				"type":        "string",
+				"description": "Configured device name. Required for read/write.",
+			},
+			"unit": map[string]any{
+				"type":        "integer",
+				"description": "Unit (slave) ID. Defaults to the device's first allowed unit.",
+			},
+			"table": map[string]any{
+				"type":        "string",
+				"enum":        []string{"coils", "discrete_inputs", "holding_registers", "input_registers"},
+				"description": "Data table to access. write supports coils and holding_registers only.",
+			},
+			"address": map[string]any{
+				"type":        "integer",
+				"description": "0-based start address (0-65535).",
+			},
+			"count": map[string]any{
+				"type":        "integer",
+				"description": "Number of values to read. Default: 1. Max 2000 bits or 125 registers.",
+			},
+			"format": map[string]any{
+				"type":        "string",
+				"enum":        []string{"uint16", "int16", "uint32", "int32", "float32"},
+				"description": "How to decode register reads. 32-bit formats combine register pairs. Default: uint16.",
+			},
+			"word_order": map[string]any{
+				"type":        "string",
+				"enum":        []string{"big", "little"},
+				"description": "Register order for 32-bit formats: big (high word first, default) or little.",
+			},
+			"values": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{},
+				"description": "Values to write: booleans or 0/1 for coils, integers 0-65535 for registers. One value uses the single-write function; several use write-multiple.",
+			},
+			"confirm": map[string]any{
+				"type":        "boolean",
+				"description": "Must be true for write operations.",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *ModbusTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, ok := args["action"].(string)
+	if !ok || strings.TrimSpace(action) == "" {
+		return ErrorResult("action is required")
+	}
+
+	switch action {
+	case "list":
+		return t.list()
+	case "read":
+		return t.read(ctx, args)
+	case "write":
+		return t.write(ctx, args)
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action: %s (valid: list, read, write)", action))
+	}
+}
+
+func (t *ModbusTool) list() *ToolResult {
+	if len(t.devices) == 0 {
+		return SilentResult("No Modbus devices configured. Add devices under tools.modbus.devices in the config.")
+	}
+	names := make([]string, 0, len(t.devices))
+	for name := range t.devices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	devices := make([]map[string]any, 0, len(names))
+	for _, name := range names {
+		d := t.devices[name]
+		entry := map[string]any{"name": d.name, "transport": d.transport()}
+		if d.serial != nil {
+			entry["port"] = d.serial.Port
+			entry["serial"] = fmt.Sprintf("%d %d%s%d", d.serial.Baud, d.serial.DataBits,
+				strings.ToUpper(d.serial.Parity[:1]), d.serial.StopBits)
+		} else {
+			entry["address"] = d.address
+		}
+		units := make([]int, len(d.units))
+		for i, u := range d.units {
+			units[i] = int(u)
+		}
+		entry["units"] = units
+		writable := make([]string, len(d.writable))
+		for i, r := range d.writable {
+			writable[i] = fmt.Sprintf("%s:%d-%d", r.kind, r.from, r.to)
+		}
+		entry["writable"] = writable
+		devices = append(devices, entry)
+	}
+	result, _ := json.MarshalIndent(map[string]any{"devices": devices}, "", "  ")
+	return SilentResult(string(result))
+}
+
+// target resolves the device and unit arguments shared by read and write.
+func (t *ModbusTool) target(args map[string]any) (*modbusDevice, byte, *ToolResult) {
+	name, _ := args["device"].(string)
+	if strings.TrimSpace(name) == "" {
+		return nil, 0, ErrorResult("device is required; use action list to see configured devices")
+	}
+	dev, ok := t.devices[strings.TrimSpace(name)]
+	if !ok {
+		return nil, 0, ErrorResult(fmt.Sprintf("device %q is not in the Modbus allowlist (tools.modbus.devices)", name))
+	}
+	unit := dev.units[0]
+	if v, ok := args["unit"].(float64); ok {
+		allowed := false
+		for _, u := range dev.units {
+			if float64(u) == v {
+				allowed, unit = true, u
+				break
+			}
+		}
+		if !allowed {
+			return nil, 0, ErrorResult(fmt.Sprintf("unit %v is not allowed for device %s (allowed: %v)", v, dev.name, dev.units))
+		}
+	}
+	return dev, unit, nil
+}
+
+func parseModbusAddress(args map[string]any) (int, *ToolResult) {
+	v, ok := args["address"].(float64)
+	if !ok || v != math.Trunc(v) || v < 0 || v > 65535 {
+		return 0, ErrorResult("address is required (0-65535)")
+	}
+	return int(v), nil
+}
+
+func (t *ModbusTool) read(ctx context.Context, args map[string]any) *ToolResult {
+	dev, unit, errResult := t.target(args)
+	if errResult != nil {
+		return errResult
+	}
+	tableName, _ := args["table"].(string)
+	table, ok := modbusTables[tableName]
+	if !ok {
+		return ErrorResult("table is required: coils, discrete_inputs, holding_registers, or input_registers")
+	}
+	address, errResult := parseModbusAddress(args)
+	if errResult != nil {
+		return errResult
+	}
+
+	format, _ := args["format"].(string)
+	if format == "" {
+		format = "uint16"
+	}
+	wordSize := 1
+	switch format {
+	case "uint16", "int16":
+	case "uint32", "int32", "float32":
+		wordSize = 2
+	default:
+		return ErrorResult("format must be one of uint16, int16, uint32, int32, float32")
+	}
+	if table.bits {
+		wordSize = 1
+	}
+
+	count := 1
+	if v, ok := args["count"].(float64); ok {
+		count = int(v)
+	}
+	quantity := count * wordSize
+	maxQuantity := maxModbusReadRegs
+	if table.bits {
+		maxQuantity = maxModbusReadBits
+	}
+	if count < 1 || quantity > maxQuantity {
+		return ErrorResult(fmt.Sprintf("count must be between 1 and %d for %s", maxQuantity/wordSize, tableName))
+	}
+	if address+quantity > 65536 {
+		return ErrorResult("address + count exceeds the 65535 address space")
+	}
+
+	req := make([]byte, 5)
+	req[0] = table.readFunc
+	binary.BigEndian.PutUint16(req[1:], uint16(address))
+	binary.BigEndian.PutUint16(req[3:], uint16(quantity))
+	resp, err := t.exchange(ctx, dev, unit, req)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("modbus read from %s failed: %v", dev.name, err))
+	}
+
+	out := map[string]any{
+		"device":  dev.name,
+		"unit":    unit,
+		"table":   tableName,
+		"address": address,
+		"count":   count,
+	}
+	if table.bits {
+		bits, err := decodeModbusBits(resp, quantity)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("modbus read from %s failed: %v", dev.name, err))
+		}
+		out["values"] = bits
+	} else {
+		regs, err := decodeModbusRegisters(resp, quantity)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("modbus read from %s failed: %v", dev.name, err))
+		}
+		wordOrder, _ := args["word_order"].(string)
+		out["format"] = format
+		out["values"] = formatModbusRegisters(regs, format, wordOrder == "little")
+		if format != "uint16" {
+			out["raw"] = regs
+		}
+	}
+	result, _ := json.MarshalIndent(out, "", "  ")
+	return SilentResult(string(result))
+}
+
+func (t *ModbusTool) write(ctx context.Context, args map[string]any) *ToolResult {
+	confirm, _ := args["confirm"].(bool)
+	if !confirm {
+		return ErrorResult(
+			"write operations require confirm: true. Please confirm with the user before changing outputs or setpoints on a Modbus device.",
+		)
+	}
+	dev, unit, errResult := t.target(args)
+	if errResult != nil {
+		return errResult
+	}
+	tableName, _ := args["table"].(string)
+	if tableName != "coils" && tableName != "holding_registers" {
+		return ErrorResult("write requires table coils or holding_registers")
+	}
+	address, errResult := parseModbusAddress(args)
+	if errResult != nil {
+		return errResult
+	}
+	rawValues, _ := args["values"].([]any)
+	if len(rawValues) == 0 {
+		return ErrorResult("values is required for write")
+	}
+
+	bits := tableName == "coils"
+	kind := "register"
+	maxValues := maxModbusWriteRegs
+	if bits {
+		kind = "coil"
+		maxValues = maxModbusWriteBits
+	}
+	if len(rawValues) > maxValues {
+		return ErrorResult(fmt.Sprintf("too many values: at most %d %ss per write", maxValues, kind))
+	}
+	if !dev.canWrite(kind, address, len(rawValues)) {
+		return ErrorResult(fmt.Sprintf(
+			"%s %d-%d on device %s is not in its writable_ranges; ask the user to extend the config if this write is intended",
+			kind, address, address+len(rawValues)-1, dev.name,
+		))
+	}
+
+	values := make([]uint16, len(rawValues))
+	for i, raw := range rawValues {
+		v, err := parseModbusWriteValue(raw, bits)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("values[%d]: %v", i, err))
+		}
+		values[i] = v
+	}
+
+	req := encodeModbusWrite(bits, uint16(address), values)
+	resp, err := t.exchange(ctx, dev, unit, req)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("modbus write to %s failed: %v", dev.name, err))
+	}
+	if len(resp) != 5 || resp[0] != req[0] || binary.BigEndian.Uint16(resp[1:]) != uint16(address) {
+		return ErrorResult(fmt.Sprintf("modbus write to %s failed: unexpected response % x", dev.name, resp))
+	}
+
+	result, _ := json.MarshalIndent(map[string]any{
+		"device":  dev.name,
+		"unit":    unit,
+		"table":   tableName,
+		"address": address,
+		"written": len(values),
+	}, "", "  ")
+	return SilentResult(string(result))
+}
+
+func parseModbusWriteValue(raw any, bit bool) (uint16, error) {
+	if b, ok := raw.(bool); ok {
+		if !bit {
+			return 0, fmt.Errorf("register values must be integers")
+		}
+		if b {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	f, ok := raw.(float64)
+	if !ok || f != math.Trunc(f) {
+		return 0, fmt.Errorf("%v is not an integer", raw)
+	}
+	if bit {
+		if f != 0 && f != 1 {
+			return 0, fmt.Errorf("coil values must be true/false or 0/1")
+		}
+		return uint16(f), nil
+	}
+	// Accept negative values as int16 so signed setpoints can be written.
+	if f < math.MinInt16 || f > math.MaxUint16 {
+		return 0, fmt.Errorf("%v is out of register range (-32768 to 65535)", f)
+	}
+	return uint16(int32(f)), nil
+}
+
+// encodeModbusWrite builds the PDU for a single or multiple write.
+func encodeModbusWrite(bits bool, address uint16, values []uint16) []byte {
+	if len(values) == 1 {
+		req := make([]byte, 5)
+		binary.BigEndian.PutUint16(req[1:], address)
+		if bits {
+			req[0] = modbusFuncWriteCoil
+			if values[0] != 0 {
+				binary.BigEndian.PutUint16(req[3:], 0xFF00)
+			}
+		} else {
+			req[0] = modbusFuncWriteReg
+			binary.BigEndian.PutUint16(req[3:], values[0])
+		}
+		return req
+	}
+
+	var data []byte
+	fn := byte(modbusFuncWriteRegs)
+	if bits {
+		fn = modbusFuncWriteCoils
+		data = make([]byte, (len(values)+7)/8)
+		for i, v := range values {
+			if v != 0 {
+				data[i/8] |= 1 << (i % 8)
+			}
+		}
+	} else {
+		data = make([]byte, 2*len(values))
+		for i, v := range values {
+			binary.BigEndian.PutUint16(data[2*i:], v)
+		}
+	}
+	req := make([]byte, 6, 6+len(data))
+	req[0] = fn
+	binary.BigEndian.PutUint16(req[1:], address)
+	binary.BigEndian.PutUint16(req[3:], uint16(len(values)))
+	req[5] = byte(len(data))
+	return append(req, data...)
+}
+
+func decodeModbusBits(resp []byte, quantity int) ([]bool, error) {
+	if len(resp) < 2 || int(resp[1]) != (quantity+7)/8 || len(resp) != 2+int(resp[1]) {
+		return nil, fmt.Errorf("unexpected response length %d for %d bits", len(resp), quantity)
+	}
+	bits := make([]bool, quantity)
+	for i := range bits {
+		bits[i] = resp[2+i/8]&(1<<(i%8)) != 0
+	}
+	return bits, nil
+}
+
+func decodeModbusRegisters(resp []byte, quantity int) ([]uint16, error) {
+	if len(resp) < 2 || int(resp[1]) != 2*quantity || len(resp) != 2+2*quantity {
+		return nil, fmt.Errorf("unexpected response length %d for %d registers", len(resp), quantity)
+	}
+	regs := make([]uint16, quantity)
+	for i := range regs {
+		regs[i] = binary.BigEndian.Uint16(resp[2+2*i:])
+	}
+	return regs, nil
+}
+
+func formatModbusRegisters(regs []uint16, format string, littleWordOrder bool) []any {
+	values := make([]any, 0, len(regs))
+	switch format {
+	case "int16":
+		for _, r := range regs {
+			values = append(values, int16(r))
+		}
+	case "uint32", "int32", "float32":
+		for i := 0; i+1 < len(regs); i += 2 {
+			hi, lo := regs[i], regs[i+1]
+			if littleWordOrder {
+				hi, lo = lo, hi
+			}
+			v := uint32(hi)<<16 | uint32(lo)
+			switch format {
+			case "uint32":
+				values = append(values, v)
+			case "int32":
+				values = append(values, int32(v))
+			default:
+				f := math.Float32frombits(v)
+				if math.IsNaN(float64(f)) || math.IsInf(float64(f), 0) {
+					values = append(values, fmt.Sprint(f))
+				} else {
+					values = append(values, f)
+				}
+			}
+		}
+	default:
+		for _, r := range regs {
+			values = append(values, r)
+		}
+	}
+	return values
+}
+
+// exchange sends one request PDU and returns the response PDU, turning
+// Modbus exception responses into errors.
+func (t *ModbusTool) exchange(ctx context.Context, dev *modbusDevice, unit byte, pdu []byte) ([]byte, error) {
+	var (
+		resp []byte
+		err  error
+	)
+	if dev.serial != nil {
+		resp, err = t.exchangeRTU(ctx, dev, unit, pdu)
+	} else {
+		resp, err = t.exchangeTCP(ctx, dev, unit, pdu)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(resp) == 0 {
+		return nil, fmt.Errorf("empty response")
+	}
+	if resp[0] == pdu[0]|modbusExceptionFlag {
+		code := byte(0)
+		if len(resp) > 1 {
+			code = resp[1]
+		}
+		name := modbusExceptions[code]
+		if name == "" {
+			name = "unknown exception"
+		}
+		return nil, fmt.Errorf("device returned exception %#02x (%s)", code, name)
+	}
+	if resp[0] != pdu[0] {
+		return nil, fmt.Errorf("response function code %#02x does not match request %#02x", resp[0], pdu[0])
+	}
+	return resp, nil
+}
+
+func (t *ModbusTool) exchangeTCP(ctx context.Context, dev *modbusDevice, unit byte, pdu []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", dev.address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	// Closing the connection unblocks I/O when the turn is cancelled.
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	txID := uint16(t.txID.Add(1))
+	adu := make([]byte, 7, 7+len(pdu))
+	binary.BigEndian.PutUint16(adu[0:], txID)
+	binary.BigEndian.PutUint16(adu[4:], uint16(len(pdu)+1))
+	adu[6] = unit
+	adu = append(adu, pdu...)
+	if _, err := conn.Write(adu); err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 7)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil, err
+	}
+	length := int(binary.BigEndian.Uint16(header[4:]))
+	switch {
+	case binary.BigEndian.Uint16(header[0:]) != txID:
+		return nil, fmt.Errorf("transaction id mismatch")
+	case binary.BigEndian.Uint16(header[2:]) != 0:
+		return nil, fmt.Errorf("not a Modbus TCP response (protocol id %d)", binary.BigEndian.Uint16(header[2:]))
+	case length < 2 || length > maxModbusADUBytes:
+		return nil, fmt.Errorf("invalid response length %d", length)
+	case header[6] != unit:
+		return nil, fmt.Errorf("response from unit %d, expected %d", header[6], unit)
+	}
+	resp := make([]byte, length-1)
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (t *ModbusTool) exchangeRTU(ctx context.Context, dev *modbusDevice, unit byte, pdu []byte) ([]byte, error) {
+	frame := make([]byte, 0, len(pdu)+3)
+	frame = append(frame, unit)
+	frame = append(frame, pdu...)
+	frame = binary.LittleEndian.AppendUint16(frame, modbusCRC16(frame))
+
+	reply, err := modbusSerialExchange(ctx, *dev.serial, frame, t.timeout, modbusRTUFrameLen)
+	if err != nil {
+		return nil, err
+	}
+	if len(reply) < 5 {
+		return nil, fmt.Errorf("short RTU reply % x", reply)
+	}
+	body := reply[:len(reply)-2]
+	if binary.LittleEndian.Uint16(reply[len(reply)-2:]) != modbusCRC16(body) {
+		return nil, fmt.Errorf("RTU reply CRC mismatch; check baud rate, parity and wiring")
+	}
+	if body[0] != unit {
+		return nil, fmt.Errorf("response from unit %d, expected %d", body[0], unit)
+	}
+	return body[1:], nil
+}
+
+// modbusRTUFrameLen returns the full length of the RTU reply frame given the
+// bytes received so far: unit, function, payload and a 2-byte CRC.
+func modbusRTUFrameLen(buf []byte) int {
+	if len(buf) < 2 {
+		return 5 // shortest reply: exception frame
+	}
+	fn := buf[1]
+	if fn&modbusExceptionFlag != 0 {
+		return 5
+	}
+	switch fn {
+	case modbusFuncReadCoils, modbusFuncReadInputs, modbusFuncReadHolding, modbusFuncReadInRegs:
+		if len(buf) < 3 {
+			return 3
+		}
+		return 3 + int(buf[2]) + 2
+	default:
+		return 8 // write echoes: unit, function, address, value/quantity, CRC
+	}
+}
+
+// modbusCRC16 is the CRC-16/MODBUS checksum (poly 0xA001 reflected, init 0xFFFF).
+func modbusCRC16(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc ^= uint16(b)
+		for range 8 {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0xA001
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,310 @@
+package hardwaretools
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// fakeModbusDevice is a Modbus server stand-in with 100 coils and 100
+// holding registers. Input tables mirror the writable ones.
+type fakeModbusDevice struct {
+	mu        sync.Mutex
+	coils     [100]bool
+	registers [100]uint16
+	requests  int
+}
+
+func (d *fakeModbusDevice) handle(pdu []byte) []byte {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.requests++
+
+	fn := pdu[0]
+	exception := func(code byte) []byte { return []byte{fn | modbusExceptionFlag, code} }
+	addr := int(binary.BigEndian.Uint16(pdu[1:]))
+	arg := int(binary.BigEndian.Uint16(pdu[3:]))
+	switch fn {
+	case modbusFuncReadCoils, modbusFuncReadInputs:
+		if addr+arg > len(d.coils) {
+			return exception(0x02)
+		}
+		resp := []byte{fn, byte((arg + 7) / 8)}
+		resp = append(resp, make([]byte, resp[1])...)
+		for i := 0; i < arg; i++ {
+			if d.coils[addr+i] {
+				resp[2+i/8] |= 1 << (i % 8)
+			}
+		}
+		return resp
+	case modbusFuncReadHolding, modbusFuncReadInRegs:
+		if addr+arg > len(d.registers) {
+			return exception(0x02)
+		}
+		resp := []byte{fn, byte(2 * arg)}
+		for i := 0; i < arg; i++ {
+			resp = binary.BigEndian.AppendUint16(resp, d.registers[addr+i])
+		}
+		return resp
+	case modbusFuncWriteCoil:
+		if addr >= len(d.coils) {
+			return exception(0x02)
+		}
+		d.coils[addr] = arg == 0xFF00
+		return pdu[:5]
+	case modbusFuncWriteReg:
+		if addr >= len(d.registers) {
+			return exception(0x02)
+		}
+		d.registers[addr] = uint16(arg)
+		return pdu[:5]
+	case modbusFuncWriteCoils:
+		for i := 0; i < arg; i++ {
+			d.coils[addr+i] = pdu[6+i/8]&(1<<(i%8)) != 0
+		}
+		return pdu[:5]
+	case modbusFuncWriteRegs:
+		for i := 0; i < arg; i++ {
+			d.registers[addr+i] = binary.BigEndian.Uint16(pdu[6+2*i:])
+		}
+		return pdu[:5]
+	default:
+		return exception(0x01)
+	}
+}
+
+// serveTCP runs a Modbus TCP listener on localhost for the test's lifetime.
+func (d *fakeModbusDevice) serveTCP(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				for {
+					header := make([]byte, 7)
+					if _, err := io.ReadFull(conn, header); err != nil {
+						return
+					}
+					pdu := make([]byte, binary.BigEndian.Uint16(header[4:])-1)
+					if _, err := io.ReadFull(conn, pdu); err != nil {
+						return
+					}
+					resp := d.handle(pdu)
+					binary.BigEndian.PutUint16(header[4:], uint16(len(resp)+1))
+					conn.Write(append(header, resp...))
+				}
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func newTestModbusTool(t *testing.T, devices ...config.ModbusDeviceConfig) *ModbusTool {
+	t.Helper()
+	tool, err := NewModbusTool(config.ModbusConfig{TimeoutMS: 2000, Devices: devices})
+	if err != nil {
+		t.Fatalf("NewModbusTool() error: %v", err)
+	}
+	return tool
+}
+
+func decodeModbusResult(t *testing.T, result *ToolResult) map[string]any {
+	t.Helper()
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	var out map[string]any
+	if err := json.Unmarshal([]byte(result.ForLLM), &out); err != nil {
+		t.Fatalf("result is not JSON: %v\n%s", err, result.ForLLM)
+	}
+	return out
+}
+
+func TestModbusTool_TCPReadWrite(t *testing.T) {
+	dev := &fakeModbusDevice{}
+	dev.registers[10], dev.registers[11] = 0x41C8, 0x0000 // 25.0 as float32
+	dev.registers[12] = 0xFFFE
+	tool := newTestModbusTool(t, config.ModbusDeviceConfig{
+		Name:           "plc1",
+		Address:        dev.serveTCP(t),
+		UnitIDs:        []int{1, 2},
+		WritableRanges: []string{"coil:0-7", "register:20-29"},
+	})
+	ctx := context.Background()
+
+	out := decodeModbusResult(t, tool.Execute(ctx, map[string]any{
+		"action": "read", "device": "plc1", "table": "holding_registers",
+		"address": float64(10), "count": float64(1), "format": "float32",
+	}))
+	if vals := out["values"].([]any); len(vals) != 1 || vals[0] != float64(25) {
+		t.Fatalf("float32 read = %v", out)
+	}
+	out = decodeModbusResult(t, tool.Execute(ctx, map[string]any{
+		"action": "read", "device": "plc1", "table": "input_registers", "address": float64(12), "format": "int16",
+	}))
+	if vals := out["values"].([]any); vals[0] != float64(-2) {
+		t.Fatalf("int16 read = %v", out)
+	}
+
+	result := tool.Execute(ctx, map[string]any{
+		"action": "write", "device": "plc1", "table": "holding_registers", "address": float64(20), "values": []any{float64(7)},
+	})
+	if !result.IsError || !strings.Contains(result.ForLLM, "confirm") {
+		t.Fatalf("write without confirm = %+v", result)
+	}
+
+	decodeModbusResult(t, tool.Execute(ctx, map[string]any{
+		"action": "write", "device": "plc1", "unit": float64(2), "table": "holding_registers",
+		"address": float64(20), "values": []any{float64(7), float64(-1), float64(65535)}, "confirm": true,
+	}))
+	if dev.registers[20] != 7 || dev.registers[21] != 0xFFFF || dev.registers[22] != 0xFFFF {
+		t.Fatalf("registers = %v", dev.registers[20:23])
+	}
+	decodeModbusResult(t, tool.Execute(ctx, map[string]any{
+		"action": "write", "device": "plc1", "table": "coils", "address": float64(3),
+		"values": []any{true}, "confirm": true,
+	}))
+	decodeModbusResult(t, tool.Execute(ctx, map[string]any{
+		"action": "write", "device": "plc1", "table": "coils", "address": float64(4),
+		"values": []any{float64(1), false, true}, "confirm": true,
+	}))
+	out = decodeModbusResult(t, tool.Execute(ctx, map[string]any{
+		"action": "read", "device": "plc1", "table": "coils", "address": float64(2), "count": float64(6),
+	}))
+	got, _ := json.Marshal(out["values"])
+	if string(got) != "[false,true,true,false,true,false]" {
+		t.Fatalf("coils = %s", got)
+	}
+}
+
+func TestModbusTool_AllowlistAndExceptions(t *testing.T) {
+	dev := &fakeModbusDevice{}
+	tool := newTestModbusTool(t, config.ModbusDeviceConfig{
+		Name:           "meter",
+		Address:        dev.serveTCP(t),
+		WritableRanges: []string{"register:0-1"},
+	})
+	ctx := context.Background()
+
+	cases := []struct {
+		args map[string]any
+		want string
+	}{
+		{map[string]any{"action": "read", "device": "other", "table": "coils", "address": float64(0)}, "not in the Modbus allowlist"},
+		{map[string]any{"action": "read", "device": "meter", "unit": float64(5), "table": "coils", "address": float64(0)}, "not allowed"},
+		{map[string]any{
+			"action": "write", "device": "meter", "table": "holding_registers", "address": float64(1),
+			"values": []any{float64(1), float64(2)}, "confirm": true,
+		}, "not in its writable_ranges"},
+		{map[string]any{
+			"action": "write", "device": "meter", "table": "coils", "address": float64(0),
+			"values": []any{true}, "confirm": true,
+		}, "not in its writable_ranges"},
+		{map[string]any{"action": "read", "device": "meter", "table": "holding_registers", "address": float64(0), "count": float64(126)}, "count must be between 1 and 125"},
+		{map[string]any{"action": "read", "device": "meter", "table": "holding_registers", "address": float64(99), "count": float64(2)}, "illegal data address"},
+	}
+	for _, tc := range cases {
+		result := tool.Execute(ctx, tc.args)
+		if !result.IsError || !strings.Contains(result.ForLLM, tc.want) {
+			t.Errorf("Execute(%v) = %s, want error containing %q", tc.args, result.ForLLM, tc.want)
+		}
+	}
+	if dev.requests != 1 {
+		t.Fatalf("device saw %d requests, want only the out-of-range read", dev.requests)
+	}
+
+	result := tool.Execute(ctx, map[string]any{"action": "list"})
+	if result.IsError || !strings.Contains(result.ForLLM, `"register:0-1"`) ||
+		!strings.Contains(result.ForLLM, `"transport": "tcp"`) {
+		t.Fatalf("list = %s", result.ForLLM)
+	}
+}
+
+func TestModbusTool_RTUFraming(t *testing.T) {
+	if got := modbusCRC16([]byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x0A}); got != 0xCDC5 {
+		t.Fatalf("crc = %#04x, want 0xcdc5", got)
+	}
+
+	dev := &fakeModbusDevice{}
+	dev.registers[0] = 1234
+	orig := modbusSerialExchange
+	t.Cleanup(func() { modbusSerialExchange = orig })
+	modbusSerialExchange = func(
+		ctx context.Context, cfg serialConfig, req []byte, timeout time.Duration, frameLen func([]byte) int,
+	) ([]byte, error) {
+		if cfg.Port != "/dev/ttyUSB0" || cfg.Baud != 19200 || cfg.Parity != "even" {
+			t.Errorf("serial config = %+v", cfg)
+		}
+		body := req[:len(req)-2]
+		if binary.LittleEndian.Uint16(req[len(req)-2:]) != modbusCRC16(body) {
+			t.Errorf("request CRC mismatch: % x", req)
+		}
+		reply := append([]byte{body[0]}, dev.handle(body[1:])...)
+		reply = binary.LittleEndian.AppendUint16(reply, modbusCRC16(reply))
+		// The caller must be able to size the reply from its first bytes.
+		if need := frameLen(reply[:3]); need != len(reply) {
+			t.Errorf("frameLen = %d, reply is %d bytes", need, len(reply))
+		}
+		return reply, nil
+	}
+
+	tool := newTestModbusTool(t, config.ModbusDeviceConfig{
+		Name: "sensor", Port: "ttyUSB0", Baud: 19200, UnitIDs: []int{17},
+		WritableRanges: []string{"register:5"},
+	})
+	out := decodeModbusResult(t, tool.Execute(context.Background(), map[string]any{
+		"action": "read", "device": "sensor", "table": "holding_registers", "address": float64(0),
+	}))
+	if vals := out["values"].([]any); vals[0] != float64(1234) || out["unit"] != float64(17) {
+		t.Fatalf("rtu read = %v", out)
+	}
+	decodeModbusResult(t, tool.Execute(context.Background(), map[string]any{
+		"action": "write", "device": "sensor", "table": "holding_registers", "address": float64(5),
+		"values": []any{float64(42)}, "confirm": true,
+	}))
+	if dev.registers[5] != 42 {
+		t.Fatalf("register 5 = %d", dev.registers[5])
+	}
+	result := tool.Execute(context.Background(), map[string]any{
+		"action": "read", "device": "sensor", "table": "coils", "address": float64(200),
+	})
+	if !result.IsError || !strings.Contains(result.ForLLM, "illegal data address") {
+		t.Fatalf("rtu exception = %+v", result)
+	}
+}
+
+func TestNewModbusTool_RejectsBadDevices(t *testing.T) {
+	for _, dc := range []config.ModbusDeviceConfig{
+		{Name: "a"},
+		{Name: "a", Address: "10.0.0.1", Port: "/dev/ttyUSB0"},
+		{Name: "bad name", Address: "10.0.0.1"},
+		{Name: "a", Address: "10.0.0.1", UnitIDs: []int{0}},
+		{Name: "a", Address: "10.0.0.1", WritableRanges: []string{"register:9-3"}},
+		{Name: "a", Port: "/etc/passwd"},
+	} {
+		if _, err := NewModbusTool(config.ModbusConfig{Devices: []config.ModbusDeviceConfig{dc}}); err == nil {
+			t.Errorf("NewModbusTool(%+v) succeeded, want error", dc)
+		}
+	}
+	tool := newTestModbusTool(t, config.ModbusDeviceConfig{Name: "a", Address: "10.0.0.1"})
+	if tool.devices["a"].address != "10.0.0.1:502" {
+		t.Fatalf("address = %s", tool.devices["a"].address)
+	}
+}
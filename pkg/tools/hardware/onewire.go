@@ -0,0 +1,226 @@
+package hardwaretools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// oneWireFamilies names the 1-Wire family codes the w1_therm driver reads.
+var oneWireFamilies = map[string]string{
+	"10": "DS18S20",
+	"22": "DS1822",
+	"28": "DS18B20",
+	"3b": "DS1825",
+	"42": "DS28EA00",
+}
+
+// oneWirePowerOnReset is the scratchpad value a DS18x20 reports before its
+// first conversion, typically a sign of insufficient power.
+const oneWirePowerOnReset = 85000
+
+var oneWireDeviceRe = regexp.MustCompile(`^[0-9a-f]{2}-[0-9a-f]{12}$`)
+
+// OneWireTool reads 1-Wire temperature sensors through the Linux w1 sysfs
+// interface (/sys/bus/w1/devices).
+type OneWireTool struct {
+	root string
+}
+
+func NewOneWireTool() *OneWireTool {
+	return &OneWireTool{root: "/sys/bus/w1/devices"}
+}
+
+func (t *OneWireTool) Name() string {
+	return "onewire"
+}
+
+func (t *OneWireTool) Description() string {
+	return "Read 1-Wire temperature sensors (DS18B20 and compatible) via the Linux w1 sysfs interface. Actions: scan (list devices on all 1-Wire buses), read (temperature of one sensor, or every temperature sensor when device is omitted). Linux only."
+}
+
+func (t *OneWireTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"scan", "read"},
+				"description": "Action to perform: scan (list devices) or read (read temperatures)",
+			},
+			"device": map[string]any{
+				"type":        "string",
+				"description": "Device ID from scan (e.g. \"28-0316a2795eff\"). Optional for read; all temperature sensors are read when omitted.",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *OneWireTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	if runtime.GOOS != "linux" {
+		return ErrorResult("1-Wire is only supported on Linux. This tool requires /sys/bus/w1.")
+	}
+
+	action, ok := args["action"].(string)
+	if !ok {
+		return ErrorResult("action is required")
+	}
+
+	switch action {
+	case "scan":
+		return t.scan()
+	case "read":
+		return t.read(ctx, args)
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action: %s (valid: scan, read)", action))
+	}
+}
+
+type oneWireDevice struct {
+	ID     string `json:"id"`
+	Family string `json:"family"`
+	Model  string `json:"model,omitempty"`
+}
+
+func (t *OneWireTool) devices() ([]oneWireDevice, error) {
+	entries, err := os.ReadDir(t.root)
+	if err != nil {
+		return nil, err
+	}
+	var devices []oneWireDevice
+	for _, e := range entries {
+		id := e.Name()
+		if !oneWireDeviceRe.MatchString(id) {
+			// Skips w1_bus_masterN and anything that is not a slave device.
+			continue
+		}
+		family := id[:2]
+		devices = append(devices, oneWireDevice{ID: id, Family: family, Model: oneWireFamilies[family]})
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i].ID < devices[j].ID })
+	return devices, nil
+}
+
+func (t *OneWireTool) scan() *ToolResult {
+	devices, err := t.devices()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return SilentResult(
+				"No 1-Wire bus found. Load the driver (modprobe w1-gpio w1-therm) and enable the w1-gpio overlay for the data pin.",
+			)
+		}
+		return ErrorResult(fmt.Sprintf("failed to scan 1-Wire devices: %v", err))
+	}
+	if len(devices) == 0 {
+		return SilentResult(
+			"No 1-Wire devices found. Check wiring, the 4.7k pull-up resistor on the data line, and sensor power.",
+		)
+	}
+	result, _ := json.MarshalIndent(devices, "", "  ")
+	return SilentResult(fmt.Sprintf("Found %d 1-Wire device(s):\n%s", len(devices), string(result)))
+}
+
+type oneWireReading struct {
+	Device       string   `json:"device"`
+	Model        string   `json:"model,omitempty"`
+	TemperatureC *float64 `json:"temperature_c,omitempty"`
+	Warning      string   `json:"warning,omitempty"`
+	Error        string   `json:"error,omitempty"`
+}
+
+func (t *OneWireTool) read(ctx context.Context, args map[string]any) *ToolResult {
+	var ids []string
+	if device, _ := args["device"].(string); device != "" {
+		device = strings.ToLower(strings.TrimSpace(device))
+		if !oneWireDeviceRe.MatchString(device) {
+			return ErrorResult("invalid device ID: expected family-serial such as \"28-0316a2795eff\"")
+		}
+		if _, err := os.Stat(filepath.Join(t.root, device)); err != nil {
+			return ErrorResult(fmt.Sprintf("1-Wire device %s not found; use scan to list devices", device))
+		}
+		ids = []string{device}
+	} else {
+		devices, err := t.devices()
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("failed to scan 1-Wire devices: %v", err))
+		}
+		for _, d := range devices {
+			if d.Model != "" {
+				ids = append(ids, d.ID)
+			}
+		}
+		if len(ids) == 0 {
+			return SilentResult("No 1-Wire temperature sensors found.")
+		}
+	}
+
+	readings := make([]oneWireReading, 0, len(ids))
+	for _, id := range ids {
+		// Each conversion takes up to 750ms, so stop early when the turn ends.
+		if err := ctx.Err(); err != nil {
+			return ErrorResult(fmt.Sprintf("1-Wire read cancelled: %v", err))
+		}
+		reading := oneWireReading{Device: id, Model: oneWireFamilies[id[:2]]}
+		milli, err := t.readTemperature(id)
+		if err != nil {
+			reading.Error = err.Error()
+		} else {
+			c := float64(milli) / 1000
+			reading.TemperatureC = &c
+			if milli == oneWirePowerOnReset {
+				reading.Warning = "85.0 °C is the power-on reset value; the sensor may be underpowered (check parasitic power wiring)"
+			}
+		}
+		readings = append(readings, reading)
+	}
+	if len(readings) == 1 && readings[0].Error != "" {
+		return ErrorResult(fmt.Sprintf("failed to read %s: %s", readings[0].Device, readings[0].Error))
+	}
+	result, _ := json.MarshalIndent(readings, "", "  ")
+	return SilentResult(string(result))
+}
+
+// readTemperature returns millidegrees Celsius from w1_slave, checking the
+// CRC line, or from the temperature attribute on kernels that provide it.
+func (t *OneWireTool) readTemperature(id string) (int64, error) {
+	dir := filepath.Join(t.root, id)
+	data, err := os.ReadFile(filepath.Join(dir, "w1_slave"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return readSysfsInt(filepath.Join(dir, "temperature"))
+		}
+		return 0, err
+	}
+	return parseW1Slave(string(data))
+}
+
+// parseW1Slave parses the two-line w1_therm output:
+//
+//	72 01 4b 46 7f ff 0e 10 57 : crc=57 YES
+//	72 01 4b 46 7f ff 0e 10 57 t=23125
+func parseW1Slave(data string) (int64, error) {
+	lines := strings.Split(strings.TrimSpace(data), "\n")
+	if len(lines) < 2 {
+		return 0, fmt.Errorf("unexpected w1_slave output %q", data)
+	}
+	if !strings.HasSuffix(strings.TrimSpace(lines[0]), "YES") {
+		return 0, fmt.Errorf("CRC check failed; check wiring and pull-up resistor")
+	}
+	_, value, ok := strings.Cut(lines[1], "t=")
+	if !ok {
+		return 0, fmt.Errorf("no temperature in w1_slave output %q", lines[1])
+	}
+	milli, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid temperature %q: %w", value, err)
+	}
+	return milli, nil
+}
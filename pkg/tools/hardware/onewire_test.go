@@ -0,0 +1,79 @@
+package hardwaretools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func newFakeOneWireTool(t *testing.T, devices map[string]map[string]string) *OneWireTool {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("1-Wire tool is Linux only")
+	}
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "w1_bus_master1"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for id, files := range devices {
+		dir := filepath.Join(root, id)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		for name, content := range files {
+			writeTestFile(t, filepath.Join(dir, name), content)
+		}
+	}
+	tool := NewOneWireTool()
+	tool.root = root
+	return tool
+}
+
+func TestOneWireTool_ScanAndReadAll(t *testing.T) {
+	tool := newFakeOneWireTool(t, map[string]map[string]string{
+		"28-0316a2795eff": {"w1_slave": "72 01 4b 46 7f ff 0e 10 57 : crc=57 YES\n72 01 4b 46 7f ff 0e 10 57 t=23125\n"},
+		"10-000802b4c7d1": {"temperature": "-1250\n"},
+		"28-00000000bad1": {"w1_slave": "ff ff ff ff ff ff ff ff ff : crc=c9 NO\nff ff ff ff ff ff ff ff ff t=-62\n"},
+		"01-00001a2b3c4d": {},
+	})
+
+	result := tool.Execute(context.Background(), map[string]any{"action": "scan"})
+	if result.IsError || !strings.Contains(result.ForLLM, "Found 4 1-Wire device(s)") ||
+		strings.Contains(result.ForLLM, "w1_bus_master") || !strings.Contains(result.ForLLM, `"model": "DS18B20"`) {
+		t.Fatalf("scan = %s", result.ForLLM)
+	}
+
+	result = tool.Execute(context.Background(), map[string]any{"action": "read"})
+	if result.IsError {
+		t.Fatalf("read error: %s", result.ForLLM)
+	}
+	for _, want := range []string{`"temperature_c": 23.125`, `"temperature_c": -1.25`, "CRC check failed"} {
+		if !strings.Contains(result.ForLLM, want) {
+			t.Fatalf("read all = %s, missing %s", result.ForLLM, want)
+		}
+	}
+	if strings.Contains(result.ForLLM, "01-00001a2b3c4d") {
+		t.Fatalf("read all included a non-temperature device: %s", result.ForLLM)
+	}
+}
+
+func TestOneWireTool_ReadOne(t *testing.T) {
+	tool := newFakeOneWireTool(t, map[string]map[string]string{
+		"28-0316a2795eff": {"w1_slave": "50 05 4b 46 7f ff 0c 10 1c : crc=1c YES\n50 05 4b 46 7f ff 0c 10 1c t=85000\n"},
+	})
+	result := tool.Execute(context.Background(), map[string]any{"action": "read", "device": "28-0316A2795EFF"})
+	if result.IsError || !strings.Contains(result.ForLLM, `"temperature_c": 85`) ||
+		!strings.Contains(result.ForLLM, "power-on reset") {
+		t.Fatalf("read = %s", result.ForLLM)
+	}
+
+	for _, device := range []string{"../../etc", "28-missing0000"} {
+		result = tool.Execute(context.Background(), map[string]any{"action": "read", "device": device})
+		if !result.IsError {
+			t.Fatalf("read(%q) = %s, want error", device, result.ForLLM)
+		}
+	}
+}
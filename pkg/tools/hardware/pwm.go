@@ -0,0 +1,387 @@
+package hardwaretools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// pwmExportWait bounds how long set waits for udev to create the pwmN
+// directory after exporting a channel.
+const pwmExportWait = time.Second
+
+// PWMTool configures PWM channels through the Linux sysfs interface
+// (/sys/class/pwm/pwmchipN).
+type PWMTool struct {
+	root string
+}
+
+func NewPWMTool() *PWMTool {
+	return &PWMTool{root: "/sys/class/pwm"}
+}
+
+func (t *PWMTool) Name() string {
+	return "pwm"
+}
+
+func (t *PWMTool) Description() string {
+	return "Configure PWM outputs (LED dimming, servos, fans) via Linux sysfs. Actions: detect (list PWM chips and exported channels), status (read one channel), set (period, duty cycle, polarity; enables the output), disable (stop the output), release (disable and unexport). Linux only."
+}
+
+func (t *PWMTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"detect", "status", "set", "disable", "release"},
+				"description": "Action to perform: detect, status, set, disable or release",
+			},
+			"chip": map[string]any{
+				"type":        "string",
+				"description": "PWM chip number (e.g. \"0\" for pwmchip0). Required except for detect.",
+			},
+			"channel": map[string]any{
+				"type":        "integer",
+				"description": "Channel on the chip (0 to npwm-1). Required except for detect.",
+			},
+			"period_ns": map[string]any{
+				"type":        "integer",
+				"description": "Period in nanoseconds (e.g. 20000000 for a 50 Hz servo). Used with set; keeps the current period if omitted.",
+			},
+			"frequency_hz": map[string]any{
+				"type":        "number",
+				"description": "Alternative to period_ns for set.",
+			},
+			"duty_cycle_ns": map[string]any{
+				"type":        "integer",
+				"description": "Active time per period in nanoseconds. Used with set.",
+			},
+			"duty_percent": map[string]any{
+				"type":        "number",
+				"description": "Alternative to duty_cycle_ns for set (0-100).",
+			},
+			"polarity": map[string]any{
+				"type":        "string",
+				"enum":        []string{"normal", "inversed"},
+				"description": "Output polarity for set. Changing it briefly disables the channel.",
+			},
+			"confirm": map[string]any{
+				"type":        "boolean",
+				"description": "Must be true for set. Safety guard to prevent accidentally driving motors or heaters.",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *PWMTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	if runtime.GOOS != "linux" {
+		return ErrorResult("PWM is only supported on Linux. This tool requires /sys/class/pwm.")
+	}
+
+	action, ok := args["action"].(string)
+	if !ok {
+		return ErrorResult("action is required")
+	}
+
+	switch action {
+	case "detect":
+		return t.detect()
+	case "status":
+		return t.status(args)
+	case "set":
+		return t.set(args)
+	case "disable":
+		return t.disable(args)
+	case "release":
+		return t.release(args)
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action: %s (valid: detect, status, set, disable, release)", action))
+	}
+}
+
+type pwmChannelState struct {
+	Chip        string `json:"chip"`
+	Channel     int    `json:"channel"`
+	PeriodNs    int64  `json:"period_ns"`
+	DutyCycleNs int64  `json:"duty_cycle_ns"`
+	DutyPercent string `json:"duty_percent,omitempty"`
+	FrequencyHz string `json:"frequency_hz,omitempty"`
+	Polarity    string `json:"polarity,omitempty"`
+	Enabled     bool   `json:"enabled"`
+}
+
+var pwmChannelDirRe = regexp.MustCompile(`^pwm(\d+)$`)
+
+func (t *PWMTool) detect() *ToolResult {
+	matches, err := filepath.Glob(filepath.Join(t.root, "pwmchip*"))
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to scan for PWM chips: %v", err))
+	}
+	if len(matches) == 0 {
+		return SilentResult(
+			"No PWM chips found. Enable the PWM controller in the device tree and configure pinmux for the PWM pins (see hardware skill).",
+		)
+	}
+
+	type chipEntry struct {
+		Chip     string            `json:"chip"`
+		Channels int               `json:"channels"`
+		Exported []pwmChannelState `json:"exported,omitempty"`
+	}
+	var chips []chipEntry
+	for _, dir := range matches {
+		id := strings.TrimPrefix(filepath.Base(dir), "pwmchip")
+		if !isValidBusID(id) {
+			continue
+		}
+		npwm, _ := readSysfsInt(filepath.Join(dir, "npwm"))
+		entry := chipEntry{Chip: id, Channels: int(npwm)}
+		children, _ := os.ReadDir(dir)
+		for _, child := range children {
+			if m := pwmChannelDirRe.FindStringSubmatch(child.Name()); m != nil {
+				channel, _ := strconv.Atoi(m[1])
+				entry.Exported = append(entry.Exported, t.readState(id, channel))
+			}
+		}
+		sort.Slice(entry.Exported, func(i, j int) bool { return entry.Exported[i].Channel < entry.Exported[j].Channel })
+		chips = append(chips, entry)
+	}
+	result, _ := json.MarshalIndent(chips, "", "  ")
+	return SilentResult(fmt.Sprintf("Found %d PWM chip(s):\n%s", len(chips), string(result)))
+}
+
+func (t *PWMTool) status(args map[string]any) *ToolResult {
+	chip, channel, errResult := t.parseChannel(args)
+	if errResult != nil {
+		return errResult
+	}
+	if _, err := os.Stat(t.channelDir(chip, channel)); err != nil {
+		return ErrorResult(fmt.Sprintf("pwmchip%s channel %d is not exported; use set to configure it", chip, channel))
+	}
+	return pwmStateResult(t.readState(chip, channel))
+}
+
+func (t *PWMTool) set(args map[string]any) *ToolResult {
+	if confirm, _ := args["confirm"].(bool); !confirm {
+		return ErrorResult(
+			"confirm must be true for set. PWM outputs can drive motors, heaters and servos; confirm with the user first.",
+		)
+	}
+	chip, channel, errResult := t.parseChannel(args)
+	if errResult != nil {
+		return errResult
+	}
+	if err := t.export(chip, channel); err != nil {
+		return ErrorResult(err.Error())
+	}
+	dir := t.channelDir(chip, channel)
+	current := t.readState(chip, channel)
+
+	period := current.PeriodNs
+	if v, ok := args["period_ns"].(float64); ok {
+		period = int64(v)
+	} else if hz, ok := args["frequency_hz"].(float64); ok {
+		if hz <= 0 {
+			return ErrorResult("frequency_hz must be positive")
+		}
+		period = int64(1e9/hz + 0.5)
+	}
+	if period <= 0 {
+		return ErrorResult("period_ns or frequency_hz is required: the channel has no period configured")
+	}
+
+	duty := current.DutyCycleNs
+	if v, ok := args["duty_cycle_ns"].(float64); ok {
+		duty = int64(v)
+	} else if pct, ok := args["duty_percent"].(float64); ok {
+		if pct < 0 || pct > 100 {
+			return ErrorResult("duty_percent must be between 0 and 100")
+		}
+		duty = int64(float64(period)*pct/100 + 0.5)
+	}
+	if duty < 0 || duty > period {
+		return ErrorResult(fmt.Sprintf("duty cycle %d ns must be between 0 and the period (%d ns)", duty, period))
+	}
+
+	if polarity, ok := args["polarity"].(string); ok && polarity != "" && polarity != current.Polarity {
+		if polarity != "normal" && polarity != "inversed" {
+			return ErrorResult("polarity must be normal or inversed")
+		}
+		// The kernel only accepts a polarity change while the channel is disabled.
+		if err := writeSysfs(filepath.Join(dir, "enable"), "0"); err != nil {
+			return ErrorResult(err.Error())
+		}
+		if err := writeSysfs(filepath.Join(dir, "polarity"), polarity); err != nil {
+			return ErrorResult(err.Error())
+		}
+	}
+
+	// duty_cycle may never exceed period, so order the writes by direction.
+	writes := [][2]string{{"period", strconv.FormatInt(period, 10)}, {"duty_cycle", strconv.FormatInt(duty, 10)}}
+	if period < current.PeriodNs {
+		writes[0], writes[1] = writes[1], writes[0]
+	}
+	for _, w := range writes {
+		if err := writeSysfs(filepath.Join(dir, w[0]), w[1]); err != nil {
+			return ErrorResult(err.Error())
+		}
+	}
+	if err := writeSysfs(filepath.Join(dir, "enable"), "1"); err != nil {
+		return ErrorResult(err.Error())
+	}
+	return pwmStateResult(t.readState(chip, channel))
+}
+
+func (t *PWMTool) disable(args map[string]any) *ToolResult {
+	chip, channel, errResult := t.parseChannel(args)
+	if errResult != nil {
+		return errResult
+	}
+	dir := t.channelDir(chip, channel)
+	if _, err := os.Stat(dir); err != nil {
+		return ErrorResult(fmt.Sprintf("pwmchip%s channel %d is not exported", chip, channel))
+	}
+	if err := writeSysfs(filepath.Join(dir, "enable"), "0"); err != nil {
+		return ErrorResult(err.Error())
+	}
+	return pwmStateResult(t.readState(chip, channel))
+}
+
+func (t *PWMTool) release(args map[string]any) *ToolResult {
+	chip, channel, errResult := t.parseChannel(args)
+	if errResult != nil {
+		return errResult
+	}
+	dir := t.channelDir(chip, channel)
+	if _, err := os.Stat(dir); err != nil {
+		return SilentResult(fmt.Sprintf("pwmchip%s channel %d is not exported", chip, channel))
+	}
+	if err := writeSysfs(filepath.Join(dir, "enable"), "0"); err != nil {
+		return ErrorResult(err.Error())
+	}
+	if err := writeSysfs(filepath.Join(t.root, "pwmchip"+chip, "unexport"), strconv.Itoa(channel)); err != nil {
+		return ErrorResult(err.Error())
+	}
+	return SilentResult(fmt.Sprintf("Released pwmchip%s channel %d", chip, channel))
+}
+
+func (t *PWMTool) parseChannel(args map[string]any) (string, int, *ToolResult) {
+	chip := ""
+	switch v := args["chip"].(type) {
+	case string:
+		chip = strings.TrimPrefix(strings.TrimSpace(v), "pwmchip")
+	case float64:
+		chip = strconv.Itoa(int(v))
+	}
+	if chip == "" {
+		return "", 0, ErrorResult("chip is required (e.g. \"0\" for pwmchip0)")
+	}
+	if !isValidBusID(chip) {
+		return "", 0, ErrorResult("invalid chip identifier: must be a number (e.g. \"0\")")
+	}
+	chipDir := filepath.Join(t.root, "pwmchip"+chip)
+	if _, err := os.Stat(chipDir); err != nil {
+		return "", 0, ErrorResult(fmt.Sprintf("pwmchip%s not found; use detect to list PWM chips", chip))
+	}
+	channelFloat, ok := args["channel"].(float64)
+	if !ok {
+		return "", 0, ErrorResult("channel is required")
+	}
+	channel := int(channelFloat)
+	npwm, err := readSysfsInt(filepath.Join(chipDir, "npwm"))
+	if err == nil && (channel < 0 || int64(channel) >= npwm) {
+		return "", 0, ErrorResult(fmt.Sprintf("channel %d out of range: pwmchip%s has %d channel(s)", channel, chip, npwm))
+	}
+	if channel < 0 {
+		return "", 0, ErrorResult("channel must not be negative")
+	}
+	return chip, channel, nil
+}
+
+func (t *PWMTool) channelDir(chip string, channel int) string {
+	return filepath.Join(t.root, "pwmchip"+chip, fmt.Sprintf("pwm%d", channel))
+}
+
+// export makes the channel directory available, waiting briefly for udev to
+// create it and fix its permissions.
+func (t *PWMTool) export(chip string, channel int) error {
+	dir := t.channelDir(chip, channel)
+	if _, err := os.Stat(dir); err == nil {
+		return nil
+	}
+	if err := writeSysfs(filepath.Join(t.root, "pwmchip"+chip, "export"), strconv.Itoa(channel)); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(pwmExportWait)
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "enable")); err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("pwmchip%s channel %d did not appear after export", chip, channel)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func (t *PWMTool) readState(chip string, channel int) pwmChannelState {
+	dir := t.channelDir(chip, channel)
+	state := pwmChannelState{Chip: chip, Channel: channel}
+	state.PeriodNs, _ = readSysfsInt(filepath.Join(dir, "period"))
+	state.DutyCycleNs, _ = readSysfsInt(filepath.Join(dir, "duty_cycle"))
+	enabled, _ := readSysfsInt(filepath.Join(dir, "enable"))
+	state.Enabled = enabled == 1
+	if data, err := os.ReadFile(filepath.Join(dir, "polarity")); err == nil {
+		state.Polarity = strings.TrimSpace(string(data))
+	}
+	if state.PeriodNs > 0 {
+		state.FrequencyHz = strconv.FormatFloat(1e9/float64(state.PeriodNs), 'f', -1, 64)
+		state.DutyPercent = strconv.FormatFloat(float64(state.DutyCycleNs)*100/float64(state.PeriodNs), 'f', 2, 64)
+	}
+	return state
+}
+
+func pwmStateResult(state pwmChannelState) *ToolResult {
+	result, _ := json.MarshalIndent(state, "", "  ")
+	return SilentResult(string(result))
+}
+
+func readSysfsInt(path string) (int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// writeSysfs writes a sysfs attribute without truncating it, which some
+// attributes reject.
+func writeSysfs(path, value string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w (check permissions)", path, err)
+	}
+	_, err = f.WriteString(value)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		if errors.Is(err, syscall.EINVAL) {
+			return fmt.Errorf("%s rejected %q: the driver does not support this value", path, value)
+		}
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
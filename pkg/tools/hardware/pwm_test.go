@@ -0,0 +1,139 @@
+package hardwaretools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// newFakePWMTool builds a sysfs-like pwmchip0 with two channels. Writing to
+// export creates the channel directory the way the kernel would.
+func newFakePWMTool(t *testing.T) (*PWMTool, string) {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("PWM tool is Linux only")
+	}
+	root := t.TempDir()
+	chip := filepath.Join(root, "pwmchip0")
+	if err := os.MkdirAll(chip, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(chip, "npwm"), "2\n")
+	writeTestFile(t, filepath.Join(chip, "export"), "")
+	writeTestFile(t, filepath.Join(chip, "unexport"), "")
+	tool := NewPWMTool()
+	tool.root = root
+	return tool, chip
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func exportFakePWMChannel(t *testing.T, chip string, channel string, period, duty string) string {
+	t.Helper()
+	dir := filepath.Join(chip, "pwm"+channel)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(dir, "period"), period)
+	writeTestFile(t, filepath.Join(dir, "duty_cycle"), duty)
+	writeTestFile(t, filepath.Join(dir, "enable"), "0")
+	writeTestFile(t, filepath.Join(dir, "polarity"), "normal")
+	return dir
+}
+
+func TestPWMTool_SetConfiguresExportedChannel(t *testing.T) {
+	tool, chip := newFakePWMTool(t)
+	dir := exportFakePWMChannel(t, chip, "1", "1000000", "500000")
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"action": "set", "chip": "0", "channel": float64(1), "frequency_hz": float64(50),
+	})
+	if !result.IsError || !strings.Contains(result.ForLLM, "confirm") {
+		t.Fatalf("set without confirm = %+v", result)
+	}
+
+	result = tool.Execute(context.Background(), map[string]any{
+		"action": "set", "chip": "0", "channel": float64(1), "frequency_hz": float64(50),
+		"duty_percent": float64(7.5), "polarity": "inversed", "confirm": true,
+	})
+	if result.IsError {
+		t.Fatalf("set error: %s", result.ForLLM)
+	}
+	if got := readTestFile(t, filepath.Join(dir, "period")); got != "20000000" {
+		t.Fatalf("period = %s", got)
+	}
+	if got := readTestFile(t, filepath.Join(dir, "duty_cycle")); got != "1500000" {
+		t.Fatalf("duty_cycle = %s", got)
+	}
+	if readTestFile(t, filepath.Join(dir, "enable")) != "1" || readTestFile(t, filepath.Join(dir, "polarity")) != "inversed" {
+		t.Fatal("channel not enabled with inversed polarity")
+	}
+	if !strings.Contains(result.ForLLM, `"duty_percent": "7.50"`) || !strings.Contains(result.ForLLM, `"frequency_hz": "50"`) {
+		t.Fatalf("set result = %s", result.ForLLM)
+	}
+
+	result = tool.Execute(context.Background(), map[string]any{
+		"action": "set", "chip": "0", "channel": float64(1), "duty_cycle_ns": float64(30000000), "confirm": true,
+	})
+	if !result.IsError || !strings.Contains(result.ForLLM, "between 0 and the period") {
+		t.Fatalf("set duty above period = %+v", result)
+	}
+
+	result = tool.Execute(context.Background(), map[string]any{"action": "disable", "chip": "0", "channel": float64(1)})
+	if result.IsError || readTestFile(t, filepath.Join(dir, "enable")) != "0" {
+		t.Fatalf("disable = %+v", result)
+	}
+}
+
+func TestPWMTool_SetWaitsForExport(t *testing.T) {
+	tool, chip := newFakePWMTool(t)
+	result := tool.Execute(context.Background(), map[string]any{
+		"action": "set", "chip": "0", "channel": float64(0), "period_ns": float64(1000), "confirm": true,
+	})
+	if !result.IsError || !strings.Contains(result.ForLLM, "did not appear after export") {
+		t.Fatalf("set without kernel export = %+v", result)
+	}
+	if got := readTestFile(t, filepath.Join(chip, "export")); got != "0" {
+		t.Fatalf("export = %q, want 0", got)
+	}
+
+	result = tool.Execute(context.Background(), map[string]any{
+		"action": "set", "chip": "0", "channel": float64(2), "period_ns": float64(1000), "confirm": true,
+	})
+	if !result.IsError || !strings.Contains(result.ForLLM, "out of range") {
+		t.Fatalf("set channel out of range = %+v", result)
+	}
+}
+
+func TestPWMTool_DetectAndRelease(t *testing.T) {
+	tool, chip := newFakePWMTool(t)
+	exportFakePWMChannel(t, chip, "0", "20000000", "1000000")
+
+	result := tool.Execute(context.Background(), map[string]any{"action": "detect"})
+	if result.IsError || !strings.Contains(result.ForLLM, `"channels": 2`) ||
+		!strings.Contains(result.ForLLM, `"period_ns": 20000000`) {
+		t.Fatalf("detect = %s", result.ForLLM)
+	}
+
+	result = tool.Execute(context.Background(), map[string]any{"action": "release", "chip": "pwmchip0", "channel": float64(0)})
+	if result.IsError || readTestFile(t, filepath.Join(chip, "unexport")) != "0" {
+		t.Fatalf("release = %+v", result)
+	}
+}
@@ -19,3 +19,13 @@ func serialRead(ctx context.Context, cfg serialConfig, length int, timeout time.
 func serialWrite(ctx context.Context, cfg serialConfig, data []byte, timeout time.Duration) (int, error) {
 	return 0, fmt.Errorf("serial is not supported on this platform")
 }
+
+func serialExchange(
+	ctx context.Context,
+	cfg serialConfig,
+	req []byte,
+	timeout time.Duration,
+	frameLen func([]byte) int,
+) ([]byte, error) {
+	return nil, fmt.Errorf("serial is not supported on this platform")
+}
@@ -135,6 +135,63 @@ func serialWrite(ctx context.Context, cfg serialConfig, data []byte, timeout tim
 	return total, nil
 }
 
+// serialExchange writes req and reads the reply on the same open port, so
+// request/response protocols such as Modbus RTU do not lose the first reply
+// bytes between two opens. frameLen reports how many bytes the reply needs
+// given what has been read so far.
+func serialExchange(
+	ctx context.Context,
+	cfg serialConfig,
+	req []byte,
+	timeout time.Duration,
+	frameLen func([]byte) int,
+) ([]byte, error) {
+	if err := serialContextErr(ctx); err != nil {
+		return nil, err
+	}
+
+	fd, err := unixSerialOpenPort(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer unixSerialClosePort(fd)
+
+	deadline := unixSerialNow().Add(timeout)
+	written := 0
+	for written < len(req) {
+		if err := serialContextErr(ctx); err != nil {
+			return nil, err
+		}
+		remaining := deadline.Sub(unixSerialNow())
+		if remaining <= 0 {
+			return nil, fmt.Errorf("timeout while writing serial data")
+		}
+		n, err := unixSerialPollWrite(fd, req[written:], minSerialPollTimeout(remaining))
+		if err != nil {
+			return nil, err
+		}
+		written += n
+	}
+
+	buf := make([]byte, 0, 256)
+	chunk := make([]byte, 256)
+	for need := frameLen(buf); len(buf) < need; need = frameLen(buf) {
+		if err := serialContextErr(ctx); err != nil {
+			return nil, err
+		}
+		remaining := deadline.Sub(unixSerialNow())
+		if remaining <= 0 {
+			return buf, fmt.Errorf("timeout after %d of %d reply bytes", len(buf), need)
+		}
+		n, err := unixSerialPollRead(fd, chunk[:need-len(buf)], minSerialPollTimeout(remaining))
+		if err != nil {
+			return buf, err
+		}
+		buf = append(buf, chunk[:n]...)
+	}
+	return buf, nil
+}
+
 func openAndConfigureSerialPort(cfg serialConfig) (int, error) {
 	fd, err := unix.Open(cfg.Port, unix.O_RDWR|unix.O_NOCTTY|unix.O_NONBLOCK, 0)
 	if err != nil {
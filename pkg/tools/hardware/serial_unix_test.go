@@ -138,3 +138,33 @@ func TestSerialWriteTimesOutAfterRepeatedEmptyPolls(t *testing.T) {
 		t.Fatalf("serialWrite() wrote %d bytes, want 0", written)
 	}
 }
+
+func TestSerialExchangeReadsUntilFrameComplete(t *testing.T) {
+	now := time.Unix(0, 0)
+	stubUnixSerialIO(t, &now)
+
+	var written []byte
+	unixSerialPollWrite = func(fd int, src []byte, timeout time.Duration) (int, error) {
+		written = append(written, src...)
+		return len(src), nil
+	}
+	reply := []byte{0x01, 0x03, 0x02, 0x12, 0x34, 0xAA, 0xBB, 0xFF}
+	unixSerialPollRead = func(fd int, dst []byte, timeout time.Duration) (int, error) {
+		now = now.Add(10 * time.Millisecond)
+		n := copy(dst, reply[:min(2, len(reply))])
+		reply = reply[n:]
+		return n, nil
+	}
+
+	got, err := serialExchange(context.Background(), serialConfig{}, []byte{0x01, 0x03}, time.Second, modbusRTUFrameLen)
+	if err != nil {
+		t.Fatalf("serialExchange() error = %v", err)
+	}
+	if string(written) != "\x01\x03" || len(got) != 7 || got[4] != 0x34 {
+		t.Fatalf("written % x, got % x", written, got)
+	}
+	// The trailing 0xFF byte must not be consumed past the frame end.
+	if len(reply) != 1 {
+		t.Fatalf("%d bytes left unread, want 1", len(reply))
+	}
+}
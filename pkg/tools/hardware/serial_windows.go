@@ -4,6 +4,7 @@ package hardwaretools
 
 import (
 	"context"
+	"fmt"
 	"sort"
 	"strings"
 	"time"
@@ -157,6 +158,53 @@ func serialWrite(ctx context.Context, cfg serialConfig, data []byte, timeout tim
 	})
 }
 
+// serialExchange writes req and reads the reply on the same handle. Each
+// ReadFile call is bounded by COMMTIMEOUTS, so the loop also re-checks the
+// overall deadline between reads.
+func serialExchange(
+	ctx context.Context,
+	cfg serialConfig,
+	req []byte,
+	timeout time.Duration,
+	frameLen func([]byte) int,
+) ([]byte, error) {
+	if err := serialContextErr(ctx); err != nil {
+		return nil, err
+	}
+
+	handle, err := openAndConfigureWindowsSerial(cfg, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer windows.CloseHandle(handle)
+
+	deadline := time.Now().Add(timeout)
+	if _, err := serialWriteAll(ctx, req, timeout, time.Now, func(chunk []byte) (int, error) {
+		var written uint32
+		err := windows.WriteFile(handle, chunk, &written, nil)
+		return int(written), err
+	}); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 0, 256)
+	chunk := make([]byte, 256)
+	for need := frameLen(buf); len(buf) < need; need = frameLen(buf) {
+		if err := serialContextErr(ctx); err != nil {
+			return nil, err
+		}
+		if time.Now().After(deadline) {
+			return buf, fmt.Errorf("timeout after %d of %d reply bytes", len(buf), need)
+		}
+		var read uint32
+		if err := windows.ReadFile(handle, chunk[:need-len(buf)], &read, nil); err != nil {
+			return buf, err
+		}
+		buf = append(buf, chunk[:read]...)
+	}
+	return buf, nil
+}
+
 func openAndConfigureWindowsSerial(cfg serialConfig, timeout time.Duration) (windows.Handle, error) {
 	handle, err := windows.CreateFile(
 		windows.StringToUTF16Ptr(cfg.Port),
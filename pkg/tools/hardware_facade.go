@@ -1,11 +1,19 @@
 package tools
 
-import hardwaretools "github.com/sipeed/picoclaw/pkg/tools/hardware"
+import (
+	"github.com/sipeed/picoclaw/pkg/config"
+	hardwaretools "github.com/sipeed/picoclaw/pkg/tools/hardware"
+)
 
 type (
-	I2CTool    = hardwaretools.I2CTool
-	SerialTool = hardwaretools.SerialTool
-	SPITool    = hardwaretools.SPITool
+	CANBusTool  = hardwaretools.CANBusTool
+	GPIOTool    = hardwaretools.GPIOTool
+	I2CTool     = hardwaretools.I2CTool
+	ModbusTool  = hardwaretools.ModbusTool
+	OneWireTool = hardwaretools.OneWireTool
+	PWMTool     = hardwaretools.PWMTool
+	SerialTool  = hardwaretools.SerialTool
+	SPITool     = hardwaretools.SPITool
 )
 
 func NewI2CTool() *I2CTool {
@@ -19,3 +27,26 @@ func NewSPITool() *SPITool {
 func NewSerialTool() *SerialTool {
 	return hardwaretools.NewSerialTool()
 }
+
+func NewGPIOTool() *GPIOTool {
+	return hardwaretools.NewGPIOTool()
+}
+
+func NewPWMTool() *PWMTool {
+	return hardwaretools.NewPWMTool()
+}
+
+func NewOneWireTool() *OneWireTool {
+	return hardwaretools.NewOneWireTool()
+}
+
+func NewModbusTool(cfg config.ModbusConfig) (*ModbusTool, error) {
+	return hardwaretools.NewModbusTool(cfg)
+}
+
+func NewCANBusTool(cfg config.CANBusConfig) (*CANBusTool, error) {
+	return hardwaretools.NewCANBusTool(cfg)
+}
+
+// HardwareToolNames lists the tools that drive local buses and pins.
+var HardwareToolNames = []string{"canbus", "gpio", "i2c", "modbus", "onewire", "pwm", "serial", "spi"}
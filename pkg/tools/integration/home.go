@@ -0,0 +1,396 @@
+package integrationtools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/homeassistant"
+)
+
+const (
+	defaultHomeEntityLimit = 100
+	maxHomeEntityLimit     = 500
+	defaultHomeWaitTimeout = 60 * time.Second
+	maxHomeWaitTimeout     = 10 * time.Minute
+)
+
+// HomeTool reads and controls a Home Assistant instance. Service calls are
+// limited to the configured domains and, optionally, entity patterns.
+type HomeTool struct {
+	client          *homeassistant.Client
+	allowedDomains  []string
+	allowedEntities []string
+}
+
+// NewHomeTool creates the home tool from its config.
+func NewHomeTool(cfg config.HomeConfig) (*HomeTool, error) {
+	client, err := homeassistant.NewClient(cfg.URL, cfg.Token.String(),
+		time.Duration(cfg.TimeoutSeconds)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	domains := make([]string, 0, len(cfg.AllowedDomains))
+	for _, d := range cfg.AllowedDomains {
+		if d = strings.ToLower(strings.TrimSpace(d)); d != "" {
+			domains = append(domains, d)
+		}
+	}
+	return &HomeTool{
+		client:          client,
+		allowedDomains:  domains,
+		allowedEntities: cfg.AllowedEntities,
+	}, nil
+}
+
+func (t *HomeTool) Name() string {
+	return "home"
+}
+
+func (t *HomeTool) Description() string {
+	return "Read and control Home Assistant. Actions: entities (list entities, filter by domain, area or " +
+		"text), areas, state (one entity with attributes), call_service (e.g. light.turn_on; only allowed " +
+		"domains), wait (block until an entity changes state)."
+}
+
+func (t *HomeTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type": "string",
+				"enum": []string{"entities", "areas", "state", "call_service", "wait"},
+			},
+			"entity_id": map[string]any{
+				"description": "Entity ID for state; one or more entity IDs for call_service; " +
+					"entity IDs or patterns like binary_sensor.*_door for wait",
+				"anyOf": []any{
+					map[string]any{"type": "string"},
+					map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+				},
+			},
+			"domain": map[string]any{
+				"type":        "string",
+				"description": "Entity domain filter for entities (e.g. light), or the service domain for call_service",
+			},
+			"area": map[string]any{
+				"type":        "string",
+				"description": "Area ID or name filter for entities",
+			},
+			"query": map[string]any{
+				"type":        "string",
+				"description": "Case-insensitive text matched against entity IDs and names for entities",
+			},
+			"limit": map[string]any{
+				"type":        "integer",
+				"description": "Maximum entities to return (default 100)",
+				"minimum":     1.0,
+			},
+			"service": map[string]any{
+				"type":        "string",
+				"description": "Service name for call_service, e.g. turn_on",
+			},
+			"data": map[string]any{
+				"type":        "object",
+				"description": "Extra service data for call_service, e.g. {\"brightness_pct\": 40}",
+			},
+			"to": map[string]any{
+				"type":        "string",
+				"description": "For wait: only return once the new state equals this value",
+			},
+			"timeout_seconds": map[string]any{
+				"type":        "integer",
+				"description": "For wait: how long to wait (default 60, max 600)",
+				"minimum":     1.0,
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *HomeTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, _ := args["action"].(string)
+	switch action {
+	case "entities":
+		return t.entities(ctx, args)
+	case "areas":
+		return t.areas(ctx)
+	case "state":
+		return t.state(ctx, args)
+	case "call_service":
+		return t.callService(ctx, args)
+	case "wait":
+		return t.wait(ctx, args)
+	case "":
+		return ErrorResult("action is required")
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action %q", action))
+	}
+}
+
+type homeEntity struct {
+	EntityID string `json:"entity_id"`
+	Name     string `json:"name,omitempty"`
+	State    string `json:"state"`
+	Area     string `json:"area,omitempty"`
+}
+
+func (t *HomeTool) entities(ctx context.Context, args map[string]any) *ToolResult {
+	domain, _ := args["domain"].(string)
+	area, _ := args["area"].(string)
+	query, _ := args["query"].(string)
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	area = strings.TrimSpace(area)
+	query = strings.ToLower(strings.TrimSpace(query))
+	limit, err := getInt64Arg(args, "limit", defaultHomeEntityLimit)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	limit = min(max(limit, 1), maxHomeEntityLimit)
+
+	states, err := t.client.States(ctx)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	// Areas come from the registry; without it only the area filter fails.
+	reg, regErr := t.client.Registry(ctx)
+	if regErr != nil && area != "" {
+		return ErrorResult(fmt.Sprintf("cannot filter by area: %v", regErr))
+	}
+
+	matched := make([]homeEntity, 0)
+	for i := range states {
+		st := &states[i]
+		if domain != "" && st.Domain() != domain {
+			continue
+		}
+		e := homeEntity{EntityID: st.EntityID, Name: st.FriendlyName(), State: st.State}
+		if reg != nil {
+			if id := reg.EntityAreas[st.EntityID]; id != "" {
+				e.Area = reg.AreaName(id)
+				if area != "" && !strings.EqualFold(area, id) && !strings.EqualFold(area, e.Area) {
+					continue
+				}
+			} else if area != "" {
+				continue
+			}
+		}
+		if query != "" && !strings.Contains(strings.ToLower(e.EntityID), query) &&
+			!strings.Contains(strings.ToLower(e.Name), query) {
+			continue
+		}
+		matched = append(matched, e)
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].EntityID < matched[j].EntityID })
+
+	result := map[string]any{"total": len(matched)}
+	if int64(len(matched)) > limit {
+		matched = matched[:limit]
+		result["truncated"] = true
+	}
+	result["entities"] = matched
+	return homeJSONResult(result, fmt.Sprintf("Home Assistant: %d entities", len(matched)))
+}
+
+func (t *HomeTool) areas(ctx context.Context) *ToolResult {
+	reg, err := t.client.Registry(ctx)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	counts := make(map[string]int, len(reg.Areas))
+	for _, areaID := range reg.EntityAreas {
+		counts[areaID]++
+	}
+	type areaInfo struct {
+		AreaID   string `json:"area_id"`
+		Name     string `json:"name"`
+		Entities int    `json:"entities"`
+	}
+	areas := make([]areaInfo, 0, len(reg.Areas))
+	for _, a := range reg.Areas {
+		areas = append(areas, areaInfo{AreaID: a.AreaID, Name: a.Name, Entities: counts[a.AreaID]})
+	}
+	sort.Slice(areas, func(i, j int) bool { return areas[i].Name < areas[j].Name })
+	return homeJSONResult(map[string]any{"areas": areas}, fmt.Sprintf("Home Assistant: %d areas", len(areas)))
+}
+
+func (t *HomeTool) state(ctx context.Context, args map[string]any) *ToolResult {
+	entityID, _ := args["entity_id"].(string)
+	if strings.TrimSpace(entityID) == "" {
+		return ErrorResult("entity_id is required")
+	}
+	st, err := t.client.State(ctx, strings.TrimSpace(entityID))
+	if errors.Is(err, homeassistant.ErrNotFound) {
+		return ErrorResult(fmt.Sprintf("entity %q not found; use action=entities to list entity IDs", entityID))
+	}
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	return homeJSONResult(st, fmt.Sprintf("%s is %s", st.EntityID, st.State))
+}
+
+func (t *HomeTool) callService(ctx context.Context, args map[string]any) *ToolResult {
+	domain, _ := args["domain"].(string)
+	service, _ := args["service"].(string)
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	service = strings.TrimSpace(service)
+	if domain == "" || service == "" {
+		return ErrorResult("domain and service are required for call_service")
+	}
+	if !slices.Contains(t.allowedDomains, domain) {
+		if len(t.allowedDomains) == 0 {
+			return ErrorResult("service calls are disabled; add domains to tools.home.allowed_domains")
+		}
+		return ErrorResult(fmt.Sprintf("domain %q is not in the home allowed_domains (%s)",
+			domain, strings.Join(t.allowedDomains, ", ")))
+	}
+
+	entityIDs, err := stringListArg(args, "entity_id")
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	data := map[string]any{}
+	if raw, ok := args["data"]; ok && raw != nil {
+		m, ok := raw.(map[string]any)
+		if !ok {
+			return ErrorResult("data must be an object")
+		}
+		for k, v := range m {
+			data[k] = v
+		}
+	}
+	if v, ok := data["entity_id"]; ok {
+		fromData, err := stringListArg(map[string]any{"entity_id": v}, "entity_id")
+		if err != nil {
+			return ErrorResult("data.entity_id must be a string or an array of strings")
+		}
+		entityIDs = append(entityIDs, fromData...)
+		delete(data, "entity_id")
+	}
+
+	if len(t.allowedEntities) > 0 {
+		// Area and device targets would reach entities the allowlist never saw.
+		for _, key := range []string{"area_id", "device_id", "floor_id", "label_id"} {
+			if _, ok := data[key]; ok {
+				return ErrorResult(fmt.Sprintf("%s targets are not allowed when allowed_entities is set; "+
+					"pass entity_id instead", key))
+			}
+		}
+		if len(entityIDs) == 0 {
+			return ErrorResult("entity_id is required because allowed_entities is set")
+		}
+		for _, id := range entityIDs {
+			if id == "all" || !homeassistant.MatchEntity(t.allowedEntities, id) {
+				return ErrorResult(fmt.Sprintf("entity %q is not in the home allowed_entities", id))
+			}
+		}
+	}
+	switch len(entityIDs) {
+	case 0:
+	case 1:
+		data["entity_id"] = entityIDs[0]
+	default:
+		data["entity_id"] = entityIDs
+	}
+
+	changed, err := t.client.CallService(ctx, domain, service, data)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	states := make([]homeEntity, 0, len(changed))
+	for i := range changed {
+		states = append(states, homeEntity{
+			EntityID: changed[i].EntityID,
+			Name:     changed[i].FriendlyName(),
+			State:    changed[i].State,
+		})
+	}
+	target := strings.Join(entityIDs, ", ")
+	if target == "" {
+		target = "no explicit target"
+	}
+	return homeJSONResult(map[string]any{
+		"service": domain + "." + service,
+		"changed": states,
+	}, fmt.Sprintf("Called %s.%s (%s)", domain, service, target))
+}
+
+func (t *HomeTool) wait(ctx context.Context, args map[string]any) *ToolResult {
+	patterns, err := stringListArg(args, "entity_id")
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	if len(patterns) == 0 {
+		return ErrorResult("entity_id is required for wait")
+	}
+	to, _ := args["to"].(string)
+	secs, err := getInt64Arg(args, "timeout_seconds", int64(defaultHomeWaitTimeout/time.Second))
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	timeout := time.Duration(secs) * time.Second
+	if timeout <= 0 || timeout > maxHomeWaitTimeout {
+		return ErrorResult(fmt.Sprintf("timeout_seconds must be between 1 and %d", int(maxHomeWaitTimeout/time.Second)))
+	}
+
+	ws, err := t.client.DialWebSocket(ctx)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	defer ws.Close()
+	changes, err := ws.SubscribeStateChanges(ctx)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ErrorResult("wait cancelled")
+		case <-timer.C:
+			return homeJSONResult(map[string]any{"timed_out": true},
+				fmt.Sprintf("No matching state change within %s", timeout))
+		case change, ok := <-changes:
+			if !ok {
+				return ErrorResult(fmt.Sprintf("home assistant connection lost: %v", ws.Err()))
+			}
+			if change.New == nil || !homeassistant.MatchEntity(patterns, change.EntityID) {
+				continue
+			}
+			if change.Old != nil && change.Old.State == change.New.State {
+				continue
+			}
+			if to != "" && change.New.State != to {
+				continue
+			}
+			from := ""
+			if change.Old != nil {
+				from = change.Old.State
+			}
+			return homeJSONResult(map[string]any{
+				"timed_out":    false,
+				"entity_id":    change.EntityID,
+				"name":         change.New.FriendlyName(),
+				"from":         from,
+				"to":           change.New.State,
+				"last_changed": change.New.LastChanged,
+			}, fmt.Sprintf("%s changed to %s", change.EntityID, change.New.State))
+		}
+	}
+}
+
+func homeJSONResult(v any, forUser string) *ToolResult {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to marshal result: %v", err))
+	}
+	return &ToolResult{ForLLM: string(out), ForUser: forUser}
+}
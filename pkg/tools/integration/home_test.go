@@ -0,0 +1,222 @@
+package integrationtools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// newFakeHomeAssistant serves the parts of the Home Assistant API the home
+// tool uses. Service calls are recorded; subscribers receive one front door
+// event shortly after subscribing.
+func newFakeHomeAssistant(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	states := `[
+		{"entity_id": "light.kitchen", "state": "on", "attributes": {"friendly_name": "Kitchen Light"}},
+		{"entity_id": "light.bedroom", "state": "off", "attributes": {"friendly_name": "Bedroom Lamp"}},
+		{"entity_id": "sensor.outside_temperature", "state": "12.5", "attributes": {"unit_of_measurement": "°C"}}
+	]`
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/states", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(states))
+	})
+	mux.HandleFunc("/api/services/", func(w http.ResponseWriter, r *http.Request) {
+		var data map[string]any
+		json.NewDecoder(r.Body).Decode(&data)
+		body, _ := json.Marshal(data)
+		mu.Lock()
+		calls = append(calls, strings.TrimPrefix(r.URL.Path, "/api/services/")+" "+string(body))
+		mu.Unlock()
+		w.Write([]byte(`[{"entity_id": "light.kitchen", "state": "off"}]`))
+	})
+	mux.HandleFunc("/api/websocket", func(w http.ResponseWriter, r *http.Request) {
+		var upgrader websocket.Upgrader
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.WriteJSON(map[string]any{"type": "auth_required"})
+		var auth map[string]any
+		if conn.ReadJSON(&auth) != nil {
+			return
+		}
+		conn.WriteJSON(map[string]any{"type": "auth_ok"})
+		for {
+			var msg map[string]any
+			if conn.ReadJSON(&msg) != nil {
+				return
+			}
+			id := msg["id"]
+			var result any
+			switch msg["type"] {
+			case "config/area_registry/list":
+				result = []map[string]any{{"area_id": "kitchen", "name": "Kitchen"}, {"area_id": "bedroom", "name": "Bedroom"}}
+			case "config/device_registry/list":
+				result = []map[string]any{}
+			case "config/entity_registry/list":
+				result = []map[string]any{
+					{"entity_id": "light.kitchen", "area_id": "kitchen"},
+					{"entity_id": "light.bedroom", "area_id": "bedroom"},
+				}
+			}
+			conn.WriteJSON(map[string]any{"id": id, "type": "result", "success": true, "result": result})
+			if msg["type"] == "subscribe_events" {
+				for _, change := range [][3]string{
+					{"light.kitchen", "on", "off"},
+					{"binary_sensor.front_door", "off", "off"},
+					{"binary_sensor.front_door", "off", "on"},
+				} {
+					conn.WriteJSON(map[string]any{"id": id, "type": "event", "event": map[string]any{
+						"event_type": "state_changed",
+						"data": map[string]any{
+							"entity_id": change[0],
+							"old_state": map[string]any{"entity_id": change[0], "state": change[1]},
+							"new_state": map[string]any{"entity_id": change[0], "state": change[2]},
+						},
+					}})
+				}
+			}
+		}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func newTestHomeTool(t *testing.T, url string, domains, entities []string) *HomeTool {
+	t.Helper()
+	tool, err := NewHomeTool(config.HomeConfig{
+		URL:             url,
+		Token:           *config.NewSecureString("token"),
+		TimeoutSeconds:  2,
+		AllowedDomains:  domains,
+		AllowedEntities: entities,
+	})
+	if err != nil {
+		t.Fatalf("NewHomeTool() error = %v", err)
+	}
+	return tool
+}
+
+func TestHomeTool_EntitiesAndAreas(t *testing.T) {
+	server, _ := newFakeHomeAssistant(t)
+	tool := newTestHomeTool(t, server.URL, nil, nil)
+	ctx := context.Background()
+
+	var out struct {
+		Total    int          `json:"total"`
+		Entities []homeEntity `json:"entities"`
+	}
+	result := tool.Execute(ctx, map[string]any{"action": "entities", "domain": "light", "area": "bedroom"})
+	if result.IsError || json.Unmarshal([]byte(result.ForLLM), &out) != nil {
+		t.Fatalf("entities = %s", result.ForLLM)
+	}
+	if out.Total != 1 || out.Entities[0].EntityID != "light.bedroom" || out.Entities[0].Area != "Bedroom" {
+		t.Fatalf("entities filtered by area = %+v", out)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "entities", "query": "LIGHT", "limit": float64(1)})
+	if result.IsError || !strings.Contains(result.ForLLM, `"truncated": true`) ||
+		!strings.Contains(result.ForLLM, `"total": 2`) {
+		t.Fatalf("entities with query and limit = %s", result.ForLLM)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "areas"})
+	if result.IsError || !strings.Contains(result.ForLLM, `"name": "Kitchen"`) ||
+		!strings.Contains(result.ForLLM, `"entities": 1`) {
+		t.Fatalf("areas = %s", result.ForLLM)
+	}
+}
+
+func TestHomeTool_CallServiceAllowlists(t *testing.T) {
+	server, calls := newFakeHomeAssistant(t)
+	ctx := context.Background()
+
+	disabled := newTestHomeTool(t, server.URL, nil, nil)
+	result := disabled.Execute(ctx, map[string]any{"action": "call_service", "domain": "light", "service": "turn_off"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "service calls are disabled") {
+		t.Fatalf("call_service without allowed domains = %s", result.ForLLM)
+	}
+
+	tool := newTestHomeTool(t, server.URL, []string{"light", "Switch"}, []string{"light.kitchen", "switch.garden_*"})
+	cases := []struct {
+		args map[string]any
+		want string
+	}{
+		{map[string]any{"domain": "lock", "service": "unlock", "entity_id": "lock.front"}, "not in the home allowed_domains"},
+		{map[string]any{"domain": "light", "service": "turn_off"}, "entity_id is required"},
+		{map[string]any{"domain": "light", "service": "turn_off", "entity_id": "light.bedroom"}, "not in the home allowed_entities"},
+		{map[string]any{"domain": "light", "service": "turn_off", "entity_id": "all"}, "not in the home allowed_entities"},
+		{map[string]any{
+			"domain": "light", "service": "turn_off", "entity_id": "light.kitchen",
+			"data": map[string]any{"area_id": "bedroom"},
+		}, "area_id targets are not allowed"},
+		{map[string]any{
+			"domain": "switch", "service": "turn_on", "entity_id": "switch.garden_pump",
+			"data": map[string]any{"entity_id": []any{"switch.alarm"}},
+		}, "not in the home allowed_entities"},
+	}
+	for _, tc := range cases {
+		tc.args["action"] = "call_service"
+		result := tool.Execute(ctx, tc.args)
+		if !result.IsError || !strings.Contains(result.ForLLM, tc.want) {
+			t.Errorf("Execute(%v) = %s, want error containing %q", tc.args, result.ForLLM, tc.want)
+		}
+	}
+	if len(*calls) != 0 {
+		t.Fatalf("rejected calls reached Home Assistant: %v", *calls)
+	}
+
+	result = tool.Execute(ctx, map[string]any{
+		"action": "call_service", "domain": "light", "service": "turn_off",
+		"entity_id": "light.kitchen", "data": map[string]any{"transition": float64(2)},
+	})
+	if result.IsError || !strings.Contains(result.ForLLM, `"state": "off"`) {
+		t.Fatalf("call_service = %s", result.ForLLM)
+	}
+	if len(*calls) != 1 || (*calls)[0] != `light/turn_off {"entity_id":"light.kitchen","transition":2}` {
+		t.Fatalf("calls = %v", *calls)
+	}
+}
+
+func TestHomeTool_WaitForStateChange(t *testing.T) {
+	server, _ := newFakeHomeAssistant(t)
+	tool := newTestHomeTool(t, server.URL, nil, nil)
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"action": "wait", "entity_id": "binary_sensor.*_door", "to": "on", "timeout_seconds": float64(5),
+	})
+	if result.IsError || !strings.Contains(result.ForLLM, `"entity_id": "binary_sensor.front_door"`) ||
+		!strings.Contains(result.ForLLM, `"from": "off"`) || !strings.Contains(result.ForLLM, `"timed_out": false`) {
+		t.Fatalf("wait = %s", result.ForLLM)
+	}
+
+	start := time.Now()
+	result = tool.Execute(context.Background(), map[string]any{
+		"action": "wait", "entity_id": "light.bedroom", "timeout_seconds": float64(1),
+	})
+	if result.IsError || !strings.Contains(result.ForLLM, `"timed_out": true`) || time.Since(start) > 3*time.Second {
+		t.Fatalf("wait timeout = %s", result.ForLLM)
+	}
+
+	result = tool.Execute(context.Background(), map[string]any{
+		"action": "wait", "entity_id": "light.bedroom", "timeout_seconds": float64(601),
+	})
+	if !result.IsError || !strings.Contains(result.ForLLM, "timeout_seconds") {
+		t.Fatalf("wait with long timeout = %s", result.ForLLM)
+	}
+}
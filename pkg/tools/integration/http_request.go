@@ -0,0 +1,623 @@
+package integrationtools
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	defaultHTTPRequestTimeout  = 30 * time.Second
+	defaultHTTPRequestMaxBytes = 1 << 20
+	defaultHTTPRequestMaxChars = 20000
+	redactedCredential         = "[REDACTED]"
+)
+
+// credentialHostKey marks requests carrying a credential so redirects to
+// another host are refused instead of leaking it.
+type credentialHostKey struct{}
+
+// HTTPRequestTool calls HTTP APIs with structured arguments. Every call goes
+// through the SSRF-safe client; credentials are referenced by name and only
+// attached for hosts whose allow rule lists them.
+type HTTPRequestTool struct {
+	client      *http.Client
+	whitelist   *utils.PrivateHostWhitelist
+	rules       []config.HTTPRequestHostRule
+	credentials map[string]string
+	maxBytes    int64
+}
+
+// NewHTTPRequestTool creates the http_request tool from its config.
+func NewHTTPRequestTool(cfg config.HTTPRequestConfig, proxy string) (*HTTPRequestTool, error) {
+	whitelist, err := utils.NewPrivateHostWhitelist(cfg.PrivateHostWhitelist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse http_request private host whitelist: %w", err)
+	}
+	timeout := defaultHTTPRequestTimeout
+	if cfg.TimeoutSeconds > 0 {
+		timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+	client, err := utils.CreateSafeHTTPClient(utils.SafeHTTPClientOptions{
+		ProxyURL:             proxy,
+		Timeout:              timeout,
+		PrivateHostWhitelist: cfg.PrivateHostWhitelist,
+		MaxRedirects:         maxRedirects,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client for http_request: %w", err)
+	}
+
+	t := &HTTPRequestTool{
+		client:      client,
+		whitelist:   whitelist,
+		rules:       cfg.Hosts,
+		credentials: make(map[string]string, len(cfg.Credentials)),
+		maxBytes:    cfg.MaxResponseBytes,
+	}
+	if t.maxBytes <= 0 {
+		t.maxBytes = defaultHTTPRequestMaxBytes
+	}
+	for name, value := range cfg.Credentials {
+		if v := value.String(); v != "" {
+			t.credentials[name] = v
+		}
+	}
+
+	safeRedirect := client.CheckRedirect
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if err := safeRedirect(req, via); err != nil {
+			return err
+		}
+		if req.Context().Value(credentialHostKey{}) != nil && req.URL.Host != via[0].URL.Host {
+			return fmt.Errorf("refusing to follow redirect to %s with a credential attached", req.URL.Host)
+		}
+		if _, err := t.matchRule(req.Method, req.URL); err != nil {
+			return fmt.Errorf("redirect blocked: %w", err)
+		}
+		return nil
+	}
+	return t, nil
+}
+
+func (t *HTTPRequestTool) Name() string {
+	return "http_request"
+}
+
+func (t *HTTPRequestTool) Description() string {
+	return "Call an HTTP API and return the status, headers and body. Supports any method, custom headers, " +
+		"JSON or form bodies, and extracting values from JSON responses with paths like data.items[0].id. " +
+		"Use credential to attach a configured secret by name instead of writing tokens into headers."
+}
+
+func (t *HTTPRequestTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"url": map[string]any{
+				"type":        "string",
+				"description": "Request URL (http or https)",
+			},
+			"method": map[string]any{
+				"type":        "string",
+				"description": "HTTP method, default GET",
+				"enum":        []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			},
+			"headers": map[string]any{
+				"type":                 "object",
+				"description":          "Request headers",
+				"additionalProperties": map[string]any{"type": "string"},
+			},
+			"query": map[string]any{
+				"type":                 "object",
+				"description":          "Query parameters appended to the URL",
+				"additionalProperties": map[string]any{"type": "string"},
+			},
+			"json": map[string]any{
+				"description": "Body encoded as JSON (sets Content-Type: application/json)",
+			},
+			"form": map[string]any{
+				"type":                 "object",
+				"description":          "Body encoded as application/x-www-form-urlencoded",
+				"additionalProperties": map[string]any{"type": "string"},
+			},
+			"body": map[string]any{
+				"type":        "string",
+				"description": "Raw request body; set Content-Type in headers",
+			},
+			"credential": map[string]any{
+				"type":        "string",
+				"description": "Name of a configured credential to attach",
+			},
+			"auth": map[string]any{
+				"type":        "string",
+				"description": "How the credential is attached: bearer (default), basic (value user:password), header or query",
+				"enum":        []string{"bearer", "basic", "header", "query"},
+			},
+			"auth_name": map[string]any{
+				"type":        "string",
+				"description": "Header or query parameter name for auth=header (default X-API-Key) or auth=query (default api_key)",
+			},
+			"extract": map[string]any{
+				"type":        "array",
+				"description": "JSON paths to extract from a JSON response instead of returning the whole body, e.g. [\"state\", \"attributes.friendly_name\", \"items[*].id\"]",
+				"items":       map[string]any{"type": "string"},
+			},
+			"max_chars": map[string]any{
+				"type":        "integer",
+				"description": "Maximum body characters to return (default 20000)",
+				"minimum":     100.0,
+			},
+		},
+		"required": []string{"url"},
+	}
+}
+
+func (t *HTTPRequestTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	rawURL, _ := args["url"].(string)
+	if strings.TrimSpace(rawURL) == "" {
+		return ErrorResult("url is required")
+	}
+	method := http.MethodGet
+	if m, ok := args["method"].(string); ok && strings.TrimSpace(m) != "" {
+		method = strings.ToUpper(strings.TrimSpace(m))
+	}
+
+	if err := utils.ValidateSafeHTTPURL(rawURL, t.whitelist, nil); err != nil {
+		return ErrorResult(err.Error())
+	}
+	u, _ := url.Parse(rawURL)
+	query, err := stringMapArg(args, "query")
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	if len(query) > 0 {
+		q := u.Query()
+		for k, v := range query {
+			q.Set(k, v)
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	rule, err := t.matchRule(method, u)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+
+	body, contentType, err := requestBody(args)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	headers, err := stringMapArg(args, "headers")
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+
+	var secret string
+	if name, _ := args["credential"].(string); name != "" {
+		secret, err = t.credential(name, rule)
+		if err != nil {
+			return ErrorResult(err.Error())
+		}
+		ctx = context.WithValue(ctx, credentialHostKey{}, u.Host)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to create request: %v", err))
+	}
+	utils.AllowConfiguredProxyFirstHop(req, t.client.Transport)
+	req.Header.Set("User-Agent", fmt.Sprintf(userAgentHonest, config.Version))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if secret != "" {
+		authName, _ := args["auth_name"].(string)
+		authType, _ := args["auth"].(string)
+		if err := applyCredential(req, secret, authType, authName); err != nil {
+			return ErrorResult(err.Error())
+		}
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return ErrorResult(redactSecret(fmt.Sprintf("request failed: %v", err), secret))
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(http.MaxBytesReader(nil, resp.Body, t.maxBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return ErrorResult(fmt.Sprintf("failed to read response: size exceeded %d bytes limit", t.maxBytes))
+		}
+		return ErrorResult(fmt.Sprintf("failed to read response: %v", err))
+	}
+
+	maxChars := defaultHTTPRequestMaxChars
+	if mc, err := getInt64Arg(args, "max_chars", 0); err == nil && mc >= 100 {
+		maxChars = int(mc)
+	}
+	paths, err := stringListArg(args, "extract")
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+
+	result := map[string]any{
+		"status":  resp.StatusCode,
+		"headers": responseHeaders(resp.Header),
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	isJSON := mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+
+	switch {
+	case len(paths) > 0:
+		var doc any
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return ErrorResult(fmt.Sprintf("cannot extract %v: response is not JSON (status %d)", paths, resp.StatusCode))
+		}
+		extracted := make(map[string]any, len(paths))
+		for _, p := range paths {
+			v, err := extractJSONPath(doc, p)
+			if err != nil {
+				extracted[p] = map[string]any{"error": err.Error()}
+				continue
+			}
+			extracted[p] = v
+		}
+		result["extracted"] = extracted
+	case isJSON && json.Valid(data):
+		if len(data) <= maxChars {
+			result["json"] = json.RawMessage(data)
+			break
+		}
+		fallthrough
+	default:
+		text := string(data)
+		if len(text) > maxChars {
+			cut := maxChars
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+			text = text[:cut]
+			result["truncated"] = true
+		}
+		result["body"] = text
+	}
+
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to marshal result: %v", err))
+	}
+	return &ToolResult{
+		ForLLM:  redactSecret(string(out), secret),
+		ForUser: fmt.Sprintf("%s %s -> %d (%d bytes)", method, redactSecret(u.Redacted(), secret), resp.StatusCode, len(data)),
+	}
+}
+
+// matchRule returns the host rule allowing the request, or nil when no rules
+// are configured and any public host may be called.
+func (t *HTTPRequestTool) matchRule(method string, u *url.URL) (*config.HTTPRequestHostRule, error) {
+	if len(t.rules) == 0 {
+		return nil, nil
+	}
+	var hostMatched bool
+	for i := range t.rules {
+		rule := &t.rules[i]
+		if !hostRuleMatches(rule.Host, u) {
+			continue
+		}
+		hostMatched = true
+		if len(rule.Methods) > 0 && !containsFold(rule.Methods, method) {
+			continue
+		}
+		if len(rule.PathPrefixes) > 0 && !hasAnyPrefix(u.Path, rule.PathPrefixes) {
+			continue
+		}
+		return rule, nil
+	}
+	if hostMatched {
+		return nil, fmt.Errorf("%s %s is not allowed by the http_request host rules", method, u.Path)
+	}
+	return nil, fmt.Errorf("host %s is not in the http_request allow list", u.Host)
+}
+
+func (t *HTTPRequestTool) credential(name string, rule *config.HTTPRequestHostRule) (string, error) {
+	secret, ok := t.credentials[name]
+	if !ok {
+		names := make([]string, 0, len(t.credentials))
+		for n := range t.credentials {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return "", fmt.Errorf("unknown credential %q (configured: %s)", name, strings.Join(names, ", "))
+	}
+	if rule == nil || !containsFold(rule.Credentials, name) {
+		return "", fmt.Errorf("credential %q is not allowed for this host", name)
+	}
+	return secret, nil
+}
+
+func hostRuleMatches(pattern string, u *url.URL) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if pattern == "" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if _, _, err := net.SplitHostPort(pattern); err == nil {
+		host = strings.ToLower(u.Host)
+		if u.Port() == "" {
+			host = net.JoinHostPort(host, defaultPort(u.Scheme))
+		}
+	}
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(host, "."+suffix)
+	}
+	return host == pattern
+}
+
+func defaultPort(scheme string) string {
+	if scheme == "https" {
+		return "443"
+	}
+	return "80"
+}
+
+func requestBody(args map[string]any) (io.Reader, string, error) {
+	var (
+		body        io.Reader
+		contentType string
+		count       int
+	)
+	if v, ok := args["json"]; ok && v != nil {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid json body: %w", err)
+		}
+		body, contentType = bytes.NewReader(data), "application/json"
+		count++
+	}
+	if _, ok := args["form"]; ok {
+		form, err := stringMapArg(args, "form")
+		if err != nil {
+			return nil, "", err
+		}
+		values := url.Values{}
+		for k, v := range form {
+			values.Set(k, v)
+		}
+		body, contentType = strings.NewReader(values.Encode()), "application/x-www-form-urlencoded"
+		count++
+	}
+	if s, ok := args["body"].(string); ok {
+		body, contentType = strings.NewReader(s), ""
+		count++
+	}
+	if count > 1 {
+		return nil, "", fmt.Errorf("only one of json, form or body may be set")
+	}
+	return body, contentType, nil
+}
+
+func applyCredential(req *http.Request, secret, authType, authName string) error {
+	switch strings.ToLower(strings.TrimSpace(authType)) {
+	case "", "bearer":
+		req.Header.Set("Authorization", "Bearer "+secret)
+	case "basic":
+		req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(secret)))
+	case "header":
+		if authName == "" {
+			authName = "X-API-Key"
+		}
+		req.Header.Set(authName, secret)
+	case "query":
+		if authName == "" {
+			authName = "api_key"
+		}
+		q := req.URL.Query()
+		q.Set(authName, secret)
+		req.URL.RawQuery = q.Encode()
+	default:
+		return fmt.Errorf("unsupported auth %q: use bearer, basic, header or query", authType)
+	}
+	return nil
+}
+
+func responseHeaders(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for k, v := range h {
+		if strings.EqualFold(k, "Set-Cookie") {
+			continue
+		}
+		out[k] = strings.Join(v, ", ")
+	}
+	return out
+}
+
+func redactSecret(s, secret string) string {
+	if secret == "" {
+		return s
+	}
+	s = strings.ReplaceAll(s, secret, redactedCredential)
+	if escaped := url.QueryEscape(secret); escaped != secret {
+		s = strings.ReplaceAll(s, escaped, redactedCredential)
+	}
+	return s
+}
+
+func stringMapArg(args map[string]any, key string) (map[string]string, error) {
+	raw, ok := args[key]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	m, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be an object", key)
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		switch val := v.(type) {
+		case string:
+			out[k] = val
+		case float64, bool:
+			out[k] = fmt.Sprint(val)
+		default:
+			return nil, fmt.Errorf("%s.%s must be a string", key, k)
+		}
+	}
+	return out, nil
+}
+
+func stringListArg(args map[string]any, key string) ([]string, error) {
+	switch v := args[key].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if v == "" {
+			return nil, nil
+		}
+		return []string{v}, nil
+	case []string:
+		return v, nil
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be an array of strings", key)
+			}
+			out = append(out, s)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("%s must be an array of strings", key)
+	}
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(strings.TrimSpace(item), s) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// extractJSONPath walks a decoded JSON document with a small path syntax:
+// "a.b", "items[0]", "items[-1]", "items[*].id" and `["key.with.dots"]`.
+// A leading "$" is accepted and ignored.
+func extractJSONPath(doc any, path string) (any, error) {
+	tokens, err := splitJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return walkJSONPath(doc, tokens, path)
+}
+
+func walkJSONPath(cur any, tokens []string, path string) (any, error) {
+	for i, tok := range tokens {
+		switch {
+		case tok == "[*]":
+			arr, ok := cur.([]any)
+			if !ok {
+				return nil, fmt.Errorf("%s: [*] applied to a non-array", path)
+			}
+			out := make([]any, 0, len(arr))
+			for _, item := range arr {
+				v, err := walkJSONPath(item, tokens[i+1:], path)
+				if err != nil {
+					continue
+				}
+				out = append(out, v)
+			}
+			return out, nil
+		case strings.HasPrefix(tok, "["):
+			arr, ok := cur.([]any)
+			if !ok {
+				return nil, fmt.Errorf("%s: index %s applied to a non-array", path, tok)
+			}
+			n, err := strconv.Atoi(tok[1 : len(tok)-1])
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid index %s", path, tok)
+			}
+			if n < 0 {
+				n += len(arr)
+			}
+			if n < 0 || n >= len(arr) {
+				return nil, fmt.Errorf("%s: index %s out of range (length %d)", path, tok, len(arr))
+			}
+			cur = arr[n]
+		default:
+			obj, ok := cur.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%s: key %q applied to a non-object", path, tok)
+			}
+			v, ok := obj[tok]
+			if !ok {
+				return nil, fmt.Errorf("%s: key %q not found", path, tok)
+			}
+			cur = v
+		}
+	}
+	return cur, nil
+}
+
+func splitJSONPath(path string) ([]string, error) {
+	p := strings.TrimSpace(path)
+	p = strings.TrimPrefix(p, "$")
+	var tokens []string
+	for len(p) > 0 {
+		switch p[0] {
+		case '.':
+			p = p[1:]
+		case '[':
+			end := strings.IndexByte(p, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("%s: unclosed [", path)
+			}
+			inner := strings.TrimSpace(p[1:end])
+			if len(inner) >= 2 && (inner[0] == '"' || inner[0] == '\'') && inner[len(inner)-1] == inner[0] {
+				tokens = append(tokens, inner[1:len(inner)-1])
+			} else {
+				tokens = append(tokens, "["+inner+"]")
+			}
+			p = p[end+1:]
+		default:
+			end := strings.IndexAny(p, ".[")
+			if end < 0 {
+				end = len(p)
+			}
+			tokens = append(tokens, p[:end])
+			p = p[end:]
+		}
+	}
+	return tokens, nil
+}
@@ -0,0 +1,208 @@
+package integrationtools
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func newTestHTTPRequestTool(t *testing.T, rules []config.HTTPRequestHostRule) *HTTPRequestTool {
+	t.Helper()
+	cfg := config.HTTPRequestConfig{
+		PrivateHostWhitelist: config.FlexibleStringSlice{"127.0.0.1"},
+		Hosts:                rules,
+		Credentials: map[string]config.SecureString{
+			"ha": *config.NewSecureString("s3cret-token"),
+		},
+	}
+	tool, err := NewHTTPRequestTool(cfg, "")
+	if err != nil {
+		t.Fatalf("NewHTTPRequestTool() error = %v", err)
+	}
+	return tool
+}
+
+func TestHTTPRequestTool_JSONBodyCredentialAndExtract(t *testing.T) {
+	var gotAuth, gotBody, gotContentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotContentType = r.Header.Get("Content-Type")
+		data, _ := io.ReadAll(r.Body)
+		gotBody = string(data)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"entity":{"state":"on","echo":"s3cret-token"},"items":[{"id":1},{"id":2}]}`))
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	tool := newTestHTTPRequestTool(t, []config.HTTPRequestHostRule{
+		{Host: host, Methods: []string{"POST"}, PathPrefixes: []string{"/api/"}, Credentials: []string{"ha"}},
+	})
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"url":        server.URL + "/api/services/light/turn_on",
+		"method":     "post",
+		"json":       map[string]any{"entity_id": "light.kitchen"},
+		"credential": "ha",
+		"extract":    []any{"entity.state", "items[*].id", "entity.echo", "missing"},
+	})
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	if gotAuth != "Bearer s3cret-token" {
+		t.Fatalf("Authorization = %q", gotAuth)
+	}
+	if gotContentType != "application/json" || gotBody != `{"entity_id":"light.kitchen"}` {
+		t.Fatalf("body = %q (%s)", gotBody, gotContentType)
+	}
+
+	var out struct {
+		Status    int            `json:"status"`
+		Extracted map[string]any `json:"extracted"`
+	}
+	if err := json.Unmarshal([]byte(result.ForLLM), &out); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v\n%s", err, result.ForLLM)
+	}
+	if out.Status != http.StatusCreated || out.Extracted["entity.state"] != "on" {
+		t.Fatalf("result = %+v", out)
+	}
+	if ids, _ := out.Extracted["items[*].id"].([]any); len(ids) != 2 {
+		t.Fatalf("items[*].id = %v", out.Extracted["items[*].id"])
+	}
+	if out.Extracted["entity.echo"] != redactedCredential {
+		t.Fatalf("credential echoed back unredacted: %v", out.Extracted["entity.echo"])
+	}
+	if _, ok := out.Extracted["missing"].(map[string]any); !ok {
+		t.Fatalf("missing path = %v, want error entry", out.Extracted["missing"])
+	}
+}
+
+func TestHTTPRequestTool_TruncatesOnRuneBoundary(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte(strings.Repeat("é", 100)))
+	}))
+	defer server.Close()
+
+	tool := newTestHTTPRequestTool(t, []config.HTTPRequestHostRule{
+		{Host: strings.TrimPrefix(server.URL, "http://")},
+	})
+	result := tool.Execute(context.Background(), map[string]any{
+		"url":       server.URL + "/",
+		"max_chars": float64(101),
+	})
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+
+	var out struct {
+		Body      string `json:"body"`
+		Truncated bool   `json:"truncated"`
+	}
+	if err := json.Unmarshal([]byte(result.ForLLM), &out); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v\n%s", err, result.ForLLM)
+	}
+	if !out.Truncated || out.Body != strings.Repeat("é", 50) {
+		t.Fatalf("body = %q (truncated %v), want 50 whole runes", out.Body, out.Truncated)
+	}
+}
+
+func TestHTTPRequestTool_HostRules(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	tool := newTestHTTPRequestTool(t, []config.HTTPRequestHostRule{
+		{Host: host, Methods: []string{"GET"}},
+	})
+
+	tests := []struct {
+		name string
+		args map[string]any
+		want string
+	}{
+		{"method not allowed", map[string]any{"url": server.URL + "/x", "method": "DELETE"}, "not allowed"},
+		{"host not listed", map[string]any{"url": "http://127.0.0.1:1/x"}, "allow list"},
+		{"credential not bound to host", map[string]any{"url": server.URL + "/x", "credential": "ha"}, "not allowed for this host"},
+		{"unknown credential", map[string]any{"url": server.URL + "/x", "credential": "nope"}, "unknown credential"},
+		{"private host", map[string]any{"url": "http://10.0.0.1/x"}, "private"},
+		{"two bodies", map[string]any{"url": server.URL + "/x", "body": "a", "form": map[string]any{"b": "c"}}, "only one"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tool.Execute(context.Background(), tt.args)
+			if !result.IsError || !strings.Contains(result.ForLLM, tt.want) {
+				t.Fatalf("Execute() = %q (error=%v), want error containing %q", result.ForLLM, result.IsError, tt.want)
+			}
+		})
+	}
+
+	result := tool.Execute(context.Background(), map[string]any{"url": server.URL + "/x", "query": map[string]any{"q": "1"}})
+	if result.IsError || !strings.Contains(result.ForLLM, `"body": "ok"`) {
+		t.Fatalf("allowed GET = %s", result.ForLLM)
+	}
+}
+
+func TestHTTPRequestTool_RefusesCredentialOnCrossHostRedirect(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("redirect target reached with Authorization=%q", r.Header.Get("Authorization"))
+	}))
+	defer target.Close()
+	redirector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL+"/steal", http.StatusFound)
+	}))
+	defer redirector.Close()
+
+	tool := newTestHTTPRequestTool(t, []config.HTTPRequestHostRule{
+		{Host: strings.TrimPrefix(redirector.URL, "http://"), Credentials: []string{"ha"}},
+		{Host: strings.TrimPrefix(target.URL, "http://")},
+	})
+	result := tool.Execute(context.Background(), map[string]any{
+		"url":        redirector.URL + "/",
+		"credential": "ha",
+		"auth":       "header",
+	})
+	if !result.IsError || !strings.Contains(result.ForLLM, "redirect") {
+		t.Fatalf("Execute() = %s, want redirect refusal", result.ForLLM)
+	}
+	if strings.Contains(result.ForLLM, "s3cret-token") {
+		t.Fatalf("error leaks credential: %s", result.ForLLM)
+	}
+}
+
+func TestExtractJSONPath(t *testing.T) {
+	var doc any
+	_ = json.Unmarshal([]byte(`{"a":{"b.c":[10,20,30]},"list":[{"n":"x"},{"n":"y"}]}`), &doc)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{`$.a["b.c"][0]`, `10`},
+		{`a["b.c"][-1]`, `30`},
+		{`list[*].n`, `["x","y"]`},
+		{`list[1]`, `{"n":"y"}`},
+	}
+	for _, tt := range tests {
+		got, err := extractJSONPath(doc, tt.path)
+		if err != nil {
+			t.Fatalf("extractJSONPath(%q) error = %v", tt.path, err)
+		}
+		data, _ := json.Marshal(got)
+		if string(data) != tt.want {
+			t.Fatalf("extractJSONPath(%q) = %s, want %s", tt.path, data, tt.want)
+		}
+	}
+	if _, err := extractJSONPath(doc, "list[5]"); err == nil {
+		t.Fatal("expected out of range error")
+	}
+}
@@ -24,6 +24,14 @@ type SendCallbackWithContext func(
 	mediaParts []bus.MediaPart,
 ) error
 
+// InteractiveSendCallback delivers a message that carries buttons, a select
+// menu or a confirmation card.
+type InteractiveSendCallback func(
+	ctx context.Context,
+	channel, chatID, content, replyToMessageID string,
+	payload *bus.InteractivePayload,
+) error
+
 type messageMediaArg struct {
 	Path     string
 	Type     string
@@ -37,15 +45,16 @@ type sentTarget struct {
 }
 
 type MessageTool struct {
-	sendCallback      SendCallbackWithContext
-	workspace         string
-	restrict          bool
-	maxFileSize       int
-	mediaStore        media.MediaStore
-	allowPaths        []*regexp.Regexp
-	localMediaEnabled bool
-	mu                sync.Mutex
-	sentTargets       map[string][]sentTarget
+	sendCallback        SendCallbackWithContext
+	interactiveCallback InteractiveSendCallback
+	workspace           string
+	restrict            bool
+	maxFileSize         int
+	mediaStore          media.MediaStore
+	allowPaths          []*regexp.Regexp
+	localMediaEnabled   bool
+	mu                  sync.Mutex
+	sentTargets         map[string][]sentTarget
 }
 
 func NewMessageTool() *MessageTool {
@@ -84,6 +93,9 @@ func (t *MessageTool) Parameters() map[string]any {
 			"description": "Optional: reply target message ID for channels that support threaded replies",
 		},
 	}
+	if t.interactiveCallback != nil {
+		properties["interactive"] = messageInteractiveSchema()
+	}
 	params := map[string]any{
 		"type":       "object",
 		"properties": properties,
@@ -112,12 +124,18 @@ func (t *MessageTool) Parameters() map[string]any {
 				"required": []string{"path"},
 			},
 		}
-		delete(params, "required")
-		params["anyOf"] = []map[string]any{
-			{"required": []string{"content"}},
-			{"required": []string{"media"}},
+	}
+	// Any one of content, media or interactive is enough to send.
+	var payloads []map[string]any
+	for _, name := range []string{"content", "media", "interactive"} {
+		if _, ok := properties[name]; ok {
+			payloads = append(payloads, map[string]any{"required": []string{name}})
 		}
 	}
+	if len(payloads) > 1 {
+		delete(params, "required")
+		params["anyOf"] = payloads
+	}
 	return params
 }
 
@@ -177,6 +195,12 @@ func (t *MessageTool) SetSendCallback(callback SendCallbackWithContext) {
 	t.sendCallback = callback
 }
 
+// SetInteractiveCallback enables the "interactive" argument. Without it the
+// tool only sends text and media.
+func (t *MessageTool) SetInteractiveCallback(callback InteractiveSendCallback) {
+	t.interactiveCallback = callback
+}
+
 func (t *MessageTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
 	content, _ := args["content"].(string)
 	content = strings.TrimSpace(content)
@@ -190,8 +214,20 @@ func (t *MessageTool) Execute(ctx context.Context, args map[string]any) *ToolRes
 			IsError: true,
 		}
 	}
-	if content == "" && len(mediaArgs) == 0 {
-		return &ToolResult{ForLLM: "content or media is required", IsError: true}
+	interactive, err := parseMessageInteractiveArg(args["interactive"])
+	if err != nil {
+		return &ToolResult{ForLLM: err.Error(), IsError: true}
+	}
+	if content == "" && len(mediaArgs) == 0 && interactive == nil {
+		return &ToolResult{ForLLM: "content, media or interactive is required", IsError: true}
+	}
+	if interactive != nil {
+		if t.interactiveCallback == nil {
+			return &ToolResult{ForLLM: "interactive messages are not supported", IsError: true}
+		}
+		if len(mediaArgs) > 0 {
+			return &ToolResult{ForLLM: "interactive cannot be combined with media", IsError: true}
+		}
 	}
 
 	channel, _ := args["channel"].(string)
@@ -209,7 +245,7 @@ func (t *MessageTool) Execute(ctx context.Context, args map[string]any) *ToolRes
 		return &ToolResult{ForLLM: "No target channel/chat specified", IsError: true}
 	}
 
-	if t.sendCallback == nil {
+	if t.sendCallback == nil && interactive == nil {
 		return &ToolResult{ForLLM: "Message sending not configured", IsError: true}
 	}
 
@@ -218,7 +254,12 @@ func (t *MessageTool) Execute(ctx context.Context, args map[string]any) *ToolRes
 		return &ToolResult{ForLLM: err.Error(), IsError: true, Err: err}
 	}
 
-	if err := t.sendCallback(ctx, channel, chatID, content, replyToMessageID, parts); err != nil {
+	if interactive != nil {
+		err = t.interactiveCallback(ctx, channel, chatID, content, replyToMessageID, interactive)
+	} else {
+		err = t.sendCallback(ctx, channel, chatID, content, replyToMessageID, parts)
+	}
+	if err != nil {
 		return &ToolResult{
 			ForLLM:  fmt.Sprintf("sending message: %v", err),
 			IsError: true,
@@ -235,6 +276,12 @@ func (t *MessageTool) Execute(ctx context.Context, args map[string]any) *ToolRes
 	if len(parts) > 0 {
 		status = fmt.Sprintf("Message with %d media attachment(s) sent to %s:%s", len(parts), channel, chatID)
 	}
+	if interactive != nil {
+		status = fmt.Sprintf(
+			"Interactive message with %d option(s) sent to %s:%s; the user's choice will arrive as an interactive action",
+			len(interactive.Choices()), channel, chatID,
+		)
+	}
 
 	return &ToolResult{
 		ForLLM: status,
@@ -242,6 +289,163 @@ func (t *MessageTool) Execute(ctx context.Context, args map[string]any) *ToolRes
 	}
 }
 
+func messageInteractiveSchema() map[string]any {
+	button := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"id":    map[string]any{"type": "string", "description": "Action ID reported back when pressed."},
+			"label": map[string]any{"type": "string", "description": "Button text."},
+			"value": map[string]any{"type": "string", "description": "Optional value reported with the action."},
+			"style": map[string]any{"type": "string", "enum": []string{"", "primary", "danger"}},
+			"url":   map[string]any{"type": "string", "description": "Optional link; link buttons do not report back."},
+			"row":   map[string]any{"type": "integer", "description": "Optional 0-based row index. Defaults to 0."},
+		},
+		"required": []string{"id", "label"},
+	}
+	option := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"id":    map[string]any{"type": "string"},
+			"label": map[string]any{"type": "string"},
+			"value": map[string]any{"type": "string"},
+		},
+		"required": []string{"id", "label"},
+	}
+	return map[string]any{
+		"type": "object",
+		"description": "Optional interactive elements. Channels without native buttons show numbered options " +
+			"instead. The user's choice arrives as a new message starting with [interactive action: <id>].",
+		"properties": map[string]any{
+			"kind": map[string]any{
+				"type": "string",
+				"enum": []string{bus.InteractiveKindButtons, bus.InteractiveKindSelect, bus.InteractiveKindConfirm},
+			},
+			"title":   map[string]any{"type": "string"},
+			"buttons": map[string]any{"type": "array", "items": button},
+			"select": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"id":          map[string]any{"type": "string"},
+					"placeholder": map[string]any{"type": "string"},
+					"options":     map[string]any{"type": "array", "items": option},
+				},
+				"required": []string{"id", "options"},
+			},
+			"confirm_id": map[string]any{
+				"type":        "string",
+				"description": "For kind=confirm: action ID prefix; reports <id>:confirm or <id>:cancel.",
+			},
+		},
+		"required": []string{"kind"},
+	}
+}
+
+func parseMessageInteractiveArg(raw any) (*bus.InteractivePayload, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	obj, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("interactive must be an object")
+	}
+	kind, _ := obj["kind"].(string)
+	title, _ := obj["title"].(string)
+	kind = strings.TrimSpace(kind)
+
+	var payload *bus.InteractivePayload
+	switch kind {
+	case bus.InteractiveKindConfirm:
+		id, _ := obj["confirm_id"].(string)
+		if strings.TrimSpace(id) == "" {
+			id = "confirm"
+		}
+		payload = bus.NewConfirmationPayload(strings.TrimSpace(id), title, "", "")
+	case bus.InteractiveKindButtons:
+		rows, err := parseMessageButtons(obj["buttons"])
+		if err != nil {
+			return nil, err
+		}
+		payload = &bus.InteractivePayload{Kind: kind, Title: title, Rows: rows}
+	case bus.InteractiveKindSelect:
+		sel, err := parseMessageSelect(obj["select"])
+		if err != nil {
+			return nil, err
+		}
+		payload = &bus.InteractivePayload{Kind: kind, Title: title, Select: sel}
+	default:
+		return nil, fmt.Errorf("interactive.kind must be one of buttons, select, confirm")
+	}
+	if err := payload.Validate(); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+func parseMessageButtons(raw any) ([][]bus.InteractiveButton, error) {
+	items, ok := raw.([]any)
+	if !ok || len(items) == 0 {
+		return nil, fmt.Errorf("interactive.buttons must be a non-empty array")
+	}
+	var rows [][]bus.InteractiveButton
+	for i, item := range items {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("interactive.buttons[%d] must be an object", i)
+		}
+		btn := bus.InteractiveButton{
+			ID:    stringArg(obj, "id"),
+			Label: stringArg(obj, "label"),
+			Value: stringArg(obj, "value"),
+			Style: stringArg(obj, "style"),
+			URL:   stringArg(obj, "url"),
+		}
+		row := 0
+		if v, ok := obj["row"].(float64); ok && v > 0 {
+			row = int(v)
+		}
+		if row > len(rows) {
+			row = len(rows) // rows are dense; skip gaps
+		}
+		if row == len(rows) {
+			rows = append(rows, nil)
+		}
+		rows[row] = append(rows[row], btn)
+	}
+	return rows, nil
+}
+
+func parseMessageSelect(raw any) (*bus.InteractiveSelect, error) {
+	obj, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("interactive.select must be an object")
+	}
+	items, _ := obj["options"].([]any)
+	sel := &bus.InteractiveSelect{
+		ID:          stringArg(obj, "id"),
+		Placeholder: stringArg(obj, "placeholder"),
+	}
+	if sel.ID == "" {
+		return nil, fmt.Errorf("interactive.select.id is required")
+	}
+	for i, item := range items {
+		opt, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("interactive.select.options[%d] must be an object", i)
+		}
+		sel.Options = append(sel.Options, bus.InteractiveOption{
+			ID:    stringArg(opt, "id"),
+			Label: stringArg(opt, "label"),
+			Value: stringArg(opt, "value"),
+		})
+	}
+	return sel, nil
+}
+
+func stringArg(obj map[string]any, key string) string {
+	v, _ := obj[key].(string)
+	return strings.TrimSpace(v)
+}
+
 func parseMessageMediaArgs(raw any) ([]messageMediaArg, error) {
 	if raw == nil {
 		return nil, nil
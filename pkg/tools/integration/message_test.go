@@ -166,8 +166,8 @@ func TestMessageTool_Execute_MissingContent(t *testing.T) {
 	if !result.IsError {
 		t.Error("Expected IsError=true for missing content/media")
 	}
-	if result.ForLLM != "content or media is required" {
-		t.Errorf("Expected ForLLM 'content or media is required', got '%s'", result.ForLLM)
+	if result.ForLLM != "content, media or interactive is required" {
+		t.Errorf("Expected ForLLM 'content, media or interactive is required', got '%s'", result.ForLLM)
 	}
 }
 
@@ -467,3 +467,133 @@ func TestMessageTool_Execute_WithMedia(t *testing.T) {
 		t.Fatal("expected media type to be inferred")
 	}
 }
+
+func TestMessageTool_InteractiveParameterHiddenWithoutCallback(t *testing.T) {
+	tool := NewMessageTool()
+	props := tool.Parameters()["properties"].(map[string]any)
+	if _, ok := props["interactive"]; ok {
+		t.Fatal("interactive parameter should be hidden without an interactive callback")
+	}
+
+	tool.SetInteractiveCallback(func(context.Context, string, string, string, string, *bus.InteractivePayload) error {
+		return nil
+	})
+	props = tool.Parameters()["properties"].(map[string]any)
+	if _, ok := props["interactive"]; !ok {
+		t.Fatal("interactive parameter should be exposed with an interactive callback")
+	}
+}
+
+func TestMessageTool_Execute_Interactive(t *testing.T) {
+	tool := NewMessageTool()
+	tool.SetSendCallback(func(context.Context, string, string, string, string, []bus.MediaPart) error {
+		t.Fatal("plain send callback should not be used for interactive messages")
+		return nil
+	})
+	var got *bus.InteractivePayload
+	tool.SetInteractiveCallback(func(
+		ctx context.Context,
+		channel, chatID, content, replyToMessageID string,
+		payload *bus.InteractivePayload,
+	) error {
+		got = payload
+		return nil
+	})
+
+	ctx := WithToolContext(context.Background(), "test-channel", "test-chat-id")
+	result := tool.Execute(ctx, map[string]any{
+		"content": "Deploy now?",
+		"interactive": map[string]any{
+			"kind": "buttons",
+			"buttons": []any{
+				map[string]any{"id": "prod", "label": "Production", "style": "danger"},
+				map[string]any{"id": "staging", "label": "Staging"},
+				map[string]any{"id": "later", "label": "Later", "row": float64(1)},
+			},
+		},
+	})
+	if result.IsError {
+		t.Fatalf("Execute() error = %s", result.ForLLM)
+	}
+	if got == nil || len(got.Rows) != 2 || len(got.Rows[0]) != 2 || got.Rows[1][0].ID != "later" {
+		t.Fatalf("payload = %+v", got)
+	}
+}
+
+func TestMessageTool_Execute_InteractiveConfirm(t *testing.T) {
+	tool := NewMessageTool()
+	var got *bus.InteractivePayload
+	tool.SetInteractiveCallback(func(
+		_ context.Context,
+		_, _, _, _ string,
+		payload *bus.InteractivePayload,
+	) error {
+		got = payload
+		return nil
+	})
+
+	ctx := WithToolContext(context.Background(), "test-channel", "test-chat-id")
+	result := tool.Execute(ctx, map[string]any{
+		"content":     "Delete the branch?",
+		"interactive": map[string]any{"kind": "confirm", "confirm_id": "delete-branch"},
+	})
+	if result.IsError {
+		t.Fatalf("Execute() error = %s", result.ForLLM)
+	}
+	if _, ok := got.Lookup("delete-branch:confirm"); !ok {
+		t.Fatalf("payload = %+v, want delete-branch:confirm", got)
+	}
+}
+
+func TestMessageTool_Execute_InteractiveOnly(t *testing.T) {
+	tool := NewMessageTool()
+	var got *bus.InteractivePayload
+	tool.SetInteractiveCallback(func(
+		_ context.Context,
+		_, _, _, _ string,
+		payload *bus.InteractivePayload,
+	) error {
+		got = payload
+		return nil
+	})
+
+	params := tool.Parameters()
+	if _, ok := params["required"]; ok {
+		t.Fatalf("required = %v, want content, media or interactive through anyOf", params["required"])
+	}
+	if anyOf, _ := params["anyOf"].([]map[string]any); len(anyOf) != 2 {
+		t.Fatalf("anyOf = %v, want content or interactive", params["anyOf"])
+	}
+
+	ctx := WithToolContext(context.Background(), "test-channel", "test-chat-id")
+	result := tool.Execute(ctx, map[string]any{
+		"interactive": map[string]any{"kind": "confirm", "confirm_id": "restart", "title": "Restart the service?"},
+	})
+	if result.IsError {
+		t.Fatalf("Execute() error = %s", result.ForLLM)
+	}
+	if got == nil || got.Title != "Restart the service?" {
+		t.Fatalf("payload = %+v, want the confirmation card", got)
+	}
+}
+
+func TestMessageTool_Execute_InteractiveInvalid(t *testing.T) {
+	tool := NewMessageTool()
+	tool.SetInteractiveCallback(func(context.Context, string, string, string, string, *bus.InteractivePayload) error {
+		t.Fatal("callback should not be called for invalid payloads")
+		return nil
+	})
+
+	ctx := WithToolContext(context.Background(), "test-channel", "test-chat-id")
+	tests := []map[string]any{
+		{"kind": "buttons"},
+		{"kind": "select", "select": map[string]any{"id": "s", "options": []any{}}},
+		{"kind": "wheel"},
+	}
+	for _, interactive := range tests {
+		result := tool.Execute(ctx, map[string]any{"content": "x", "interactive": interactive})
+		if !result.IsError {
+			t.Fatalf("Execute(%v) should fail", interactive)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/sipeed/picoclaw/pkg/checkpoint"
 	"github.com/sipeed/picoclaw/pkg/fileutil"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/skills"
@@ -146,6 +147,8 @@ func (t *InstallSkillTool) Execute(ctx context.Context, args map[string]any) *To
 		}
 	} else {
 		if _, statErr := os.Stat(targetDir); statErr == nil {
+			// Capture the previous install before it is moved aside.
+			checkpoint.CaptureTree(ctx, targetDir)
 			backupDir = filepath.Join(skillsDir, fmt.Sprintf(".%s.picoclaw-backup-%d", dirName, time.Now().UnixNano()))
 			if renameErr := os.Rename(targetDir, backupDir); renameErr != nil {
 				return ErrorResult(fmt.Sprintf("failed to prepare reinstall for %q: %v", slug, renameErr))
@@ -155,6 +158,9 @@ func (t *InstallSkillTool) Execute(ctx context.Context, args map[string]any) *To
 		}
 	}
 
+	// Snapshot a fresh target's absence so the install can be undone.
+	checkpoint.CaptureTree(ctx, targetDir)
+
 	// Ensure skills directory exists.
 	if mkdirErr := os.MkdirAll(skillsDir, 0o755); mkdirErr != nil {
 		restorePreviousInstall()
@@ -241,6 +247,16 @@ func (t *InstallSkillTool) Execute(ctx context.Context, args map[string]any) *To
 		}
 	}
 
+	normalizedSlug, _ := skills.BuildInstallMetadataForRegistryInstance(registry, slug, result.Version)
+	if _, err := skills.RecordInstall(t.workspace, dirName, registry.Name(), normalizedSlug, result); err != nil {
+		logger.WarnCF("tool", "Failed to update skills lockfile",
+			map[string]any{
+				"tool":  "install_skill",
+				"skill": dirName,
+				"error": err.Error(),
+			})
+	}
+
 	// Build result with moderation warning if suspicious.
 	var output string
 	if result.IsSuspicious {
@@ -253,6 +269,12 @@ func (t *InstallSkillTool) Execute(ctx context.Context, args map[string]any) *To
 		output += fmt.Sprintf("Description: %s\n", result.Summary)
 	}
 	output += "\nThe skill is now available and can be loaded in the current session."
+	if skill, ok := skills.NewSkillsLoader(t.workspace, "", "").WorkspaceSkill(dirName); ok && !skill.Available() {
+		output += fmt.Sprintf(
+			"\n\n⚠️ Warning: the skill has unmet requirements (%s). Tell the user what to install before using it.",
+			skills.UnavailableReason(skill),
+		)
+	}
 
 	return SilentResult(output)
 }
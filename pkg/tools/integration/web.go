@@ -21,6 +21,7 @@ import (
 	kagiopenapi "github.com/kagisearch/kagi-openapi-golang"
 
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/document"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/utils"
 )
@@ -2075,7 +2076,7 @@ func (t *WebFetchTool) Name() string {
 }
 
 func (t *WebFetchTool) Description() string {
-	return "Fetch a URL and extract readable content (HTML to text). Use this to get weather info, news, articles, or any web content."
+	return "Fetch a URL and extract readable content (HTML to text; PDF, Office, EPUB and CSV documents to text). Use this to get weather info, news, articles, or any web content."
 }
 
 func (t *WebFetchTool) Parameters() map[string]any {
@@ -2220,8 +2221,18 @@ func (t *WebFetchTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 	}
 
 	var text, extractor string
+	docFormat := fetchedDocumentFormat(parsedURL.Path, mediaType, body)
 
 	switch {
+	case docFormat != "":
+		doc, err := document.Extract(body, docFormat)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("failed to extract %s document: %v", docFormat, err))
+		}
+		all, _ := doc.Select("")
+		text = doc.Render(all)
+		extractor = string(docFormat)
+
 	case mediaType == "application/json":
 		var jsonData any
 		if err := json.Unmarshal(body, &jsonData); err != nil {
@@ -2291,6 +2302,20 @@ func (t *WebFetchTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 	}
 }
 
+// fetchedDocumentFormat recognizes PDF, Office, EPUB and CSV responses by
+// content type, or by URL extension when the server only sends a generic
+// binary type.
+func fetchedDocumentFormat(urlPath, mediaType string, body []byte) document.Format {
+	if format := document.Detect("", mediaType, body); format != "" {
+		return format
+	}
+	switch mediaType {
+	case "application/octet-stream", "binary/octet-stream", "application/x-download", "application/download":
+		return document.Detect(urlPath, "", body)
+	}
+	return ""
+}
+
 func looksLikeHTML(body string) bool {
 	if body == "" {
 		return false
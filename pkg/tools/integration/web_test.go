@@ -96,6 +96,43 @@ func TestWebTool_WebFetch_JSON(t *testing.T) {
 	}
 }
 
+// TestWebTool_WebFetch_Document verifies documents are extracted by content type
+// or, for generic binary responses, by URL extension
+func TestWebTool_WebFetch_Document(t *testing.T) {
+	withPrivateWebFetchHostsAllowed(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/report.pdf" {
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write([]byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n" +
+				"2 0 obj\n<< /Type /Pages /Kids [3 0 R] >>\nendobj\n" +
+				"3 0 obj\n<< /Type /Page /Contents 4 0 R >>\nendobj\n" +
+				"4 0 obj\n<< /Length 32 >>\nstream\nBT 72 700 Td (Hello PDF) Tj ET\nendstream\nendobj\n"))
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Write([]byte("name,qty\nbolt,4\n"))
+	}))
+	defer server.Close()
+
+	tool, err := NewWebFetchTool(50000, format, testFetchLimit)
+	if err != nil {
+		t.Fatalf("Failed to create web fetch tool: %v", err)
+	}
+
+	result := tool.Execute(context.Background(), map[string]any{"url": server.URL + "/data"})
+	if result.IsError || !strings.Contains(result.ForLLM, `"extractor": "csv"`) ||
+		!strings.Contains(result.ForLLM, `| bolt | 4 |`) {
+		t.Fatalf("csv fetch = %s", result.ForLLM)
+	}
+
+	result = tool.Execute(context.Background(), map[string]any{"url": server.URL + "/report.pdf"})
+	if result.IsError || !strings.Contains(result.ForLLM, `"extractor": "pdf"`) ||
+		!strings.Contains(result.ForLLM, "Hello PDF") {
+		t.Fatalf("pdf fetch = %s", result.ForLLM)
+	}
+}
+
 // TestWebTool_WebFetch_InvalidURL verifies error handling for invalid URL
 func TestWebTool_WebFetch_InvalidURL(t *testing.T) {
 	tool, err := NewWebFetchTool(50000, format, testFetchLimit)
@@ -12,6 +12,7 @@ import (
 
 type (
 	SendCallbackWithContext  = integrationtools.SendCallbackWithContext
+	InteractiveSendCallback  = integrationtools.InteractiveSendCallback
 	ReactionCallback         = integrationtools.ReactionCallback
 	MCPManager               = integrationtools.MCPManager
 	MCPTool                  = integrationtools.MCPTool
@@ -36,6 +37,8 @@ type (
 	WebSearchTool            = integrationtools.WebSearchTool
 	WebSearchToolOptions     = integrationtools.WebSearchToolOptions
 	WebFetchTool             = integrationtools.WebFetchTool
+	HTTPRequestTool          = integrationtools.HTTPRequestTool
+	HomeTool                 = integrationtools.HomeTool
 )
 
 func NewMCPTool(manager MCPManager, serverName string, tool *mcp.Tool) *MCPTool {
@@ -66,6 +69,14 @@ func NewAPIKeyPool(keys []string) *APIKeyPool {
 	return integrationtools.NewAPIKeyPool(keys)
 }
 
+func NewHTTPRequestTool(cfg config.HTTPRequestConfig, proxy string) (*HTTPRequestTool, error) {
+	return integrationtools.NewHTTPRequestTool(cfg, proxy)
+}
+
+func NewHomeTool(cfg config.HomeConfig) (*HomeTool, error) {
+	return integrationtools.NewHomeTool(cfg)
+}
+
 func WebSearchToolOptionsFromConfig(cfg *config.Config) WebSearchToolOptions {
 	return integrationtools.WebSearchToolOptionsFromConfig(cfg)
 }
@@ -0,0 +1,143 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/script"
+	fstools "github.com/sipeed/picoclaw/pkg/tools/fs"
+)
+
+// RunScriptTool evaluates Starlark scripts in the embedded interpreter. It is
+// meant for computations that would otherwise need exec and a Python install.
+type RunScriptTool struct {
+	workspace          string
+	restrict           bool
+	allowPaths         []*regexp.Regexp
+	allowWorkspaceRead bool
+	options            script.Options
+}
+
+// NewRunScriptTool creates the run_script tool. restrict and allowPaths apply
+// to read_file() calls, which are only available when cfg.AllowWorkspaceRead
+// is set.
+func NewRunScriptTool(
+	workspace string,
+	restrict bool,
+	cfg config.RunScriptConfig,
+	allowPaths ...[]*regexp.Regexp,
+) *RunScriptTool {
+	t := &RunScriptTool{
+		workspace:          workspace,
+		restrict:           restrict,
+		allowWorkspaceRead: cfg.AllowWorkspaceRead,
+		options: script.Options{
+			Timeout: time.Duration(cfg.TimeoutSeconds) * time.Second,
+		},
+	}
+	if len(allowPaths) > 0 {
+		t.allowPaths = allowPaths[0]
+	}
+	if cfg.MaxSteps > 0 {
+		t.options.MaxSteps = uint64(cfg.MaxSteps)
+	}
+	if cfg.MaxMemoryMB > 0 {
+		t.options.MaxMemoryBytes = uint64(cfg.MaxMemoryMB) << 20
+	}
+	if t.allowWorkspaceRead {
+		t.options.ReadFile = t.readFile
+	}
+	return t
+}
+
+func (t *RunScriptTool) Name() string {
+	return "run_script"
+}
+
+func (t *RunScriptTool) Description() string {
+	desc := "Run a Starlark (Python-like) script for calculations, unit conversion and data reshaping. " +
+		"Assign the value to return to the global `result`; it is returned as JSON together with print() output. " +
+		"Available modules: json (encode/decode), math, csv (decode(text, header=False, delimiter=','), encode(rows)). " +
+		"There is no network access, no imports and no shell."
+	if t.allowWorkspaceRead {
+		desc += " read_file(path) returns a workspace file as a string."
+	} else {
+		desc += " There is no file access."
+	}
+	return desc
+}
+
+func (t *RunScriptTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"code": map[string]any{
+				"type":        "string",
+				"description": "Starlark source to run. Set `result` to the value to return.",
+			},
+		},
+		"required": []string{"code"},
+	}
+}
+
+type runScriptOutput struct {
+	Result          json.RawMessage `json:"result"`
+	Output          string          `json:"output,omitempty"`
+	OutputTruncated bool            `json:"output_truncated,omitempty"`
+	Steps           uint64          `json:"steps"`
+}
+
+func (t *RunScriptTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	code, _ := args["code"].(string)
+	if code == "" {
+		return ErrorResult("code is required")
+	}
+	res, err := script.Run(ctx, "script.star", code, t.options)
+	if err != nil {
+		msg := fmt.Sprintf("script failed: %v", err)
+		if res != nil && res.Output != "" {
+			msg += "\n\nprint output before the failure:\n" + res.Output
+		}
+		return ErrorResult(msg).WithError(err)
+	}
+	data, err := json.Marshal(runScriptOutput{
+		Result:          res.Value,
+		Output:          res.Output,
+		OutputTruncated: res.OutputTruncated,
+		Steps:           res.Steps,
+	})
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to encode script result: %v", err))
+	}
+	return NewToolResult(string(data))
+}
+
+// readFile serves read_file() through the same path validation as the
+// read_file tool and refuses files larger than the memory budget before
+// reading them.
+func (t *RunScriptTool) readFile(path string) ([]byte, error) {
+	resolved, err := fstools.ValidatePathWithAllowPaths(path, t.workspace, t.restrict, t.allowPaths)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+	limit := t.options.MaxMemoryBytes
+	if limit == 0 {
+		limit = script.DefaultMaxMemoryBytes
+	}
+	if uint64(info.Size()) > limit {
+		return nil, fmt.Errorf("%s is %d bytes, larger than the script memory limit", path, info.Size())
+	}
+	return os.ReadFile(resolved)
+}
@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestRunScriptTool_ReturnsJSON(t *testing.T) {
+	tool := NewRunScriptTool(t.TempDir(), true, config.RunScriptConfig{})
+	result := tool.Execute(context.Background(), map[string]any{
+		"code": "print('converting')\nresult = {'km': 26.2 * 1.609344}",
+	})
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	var out struct {
+		Result map[string]float64 `json:"result"`
+		Output string             `json:"output"`
+		Steps  uint64             `json:"steps"`
+	}
+	if err := json.Unmarshal([]byte(result.ForLLM), &out); err != nil {
+		t.Fatalf("ForLLM is not JSON: %v\n%s", err, result.ForLLM)
+	}
+	if km := out.Result["km"]; km < 42.16 || km > 42.17 {
+		t.Fatalf("result.km = %v", km)
+	}
+	if out.Output != "converting\n" || out.Steps == 0 {
+		t.Fatalf("output = %q, steps = %d", out.Output, out.Steps)
+	}
+}
+
+func TestRunScriptTool_ScriptErrorAndLimits(t *testing.T) {
+	tool := NewRunScriptTool(t.TempDir(), true, config.RunScriptConfig{MaxSteps: 1000})
+	result := tool.Execute(context.Background(), map[string]any{"code": "print('start')\nfail('boom')"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "boom") || !strings.Contains(result.ForLLM, "start") {
+		t.Fatalf("Execute() = %+v, want script error with print output", result)
+	}
+	result = tool.Execute(context.Background(), map[string]any{"code": "while True:\n    pass"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "step limit") {
+		t.Fatalf("Execute() = %+v, want step limit error", result)
+	}
+}
+
+func TestRunScriptTool_WorkspaceRead(t *testing.T) {
+	workspace := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workspace, "data.csv"), []byte("a,b\n1,2\n3,4\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	if err := os.WriteFile(outside, []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	code := "result = 0\nfor r in csv.decode(read_file('data.csv'), header=True):\n    result += int(r['b'])"
+
+	disabled := NewRunScriptTool(workspace, true, config.RunScriptConfig{})
+	if result := disabled.Execute(context.Background(), map[string]any{"code": code}); !result.IsError ||
+		!strings.Contains(result.ForLLM, "file access is disabled") {
+		t.Fatalf("Execute() without allow_workspace_read = %+v", result)
+	}
+
+	tool := NewRunScriptTool(workspace, true, config.RunScriptConfig{AllowWorkspaceRead: true})
+	result := tool.Execute(context.Background(), map[string]any{"code": code})
+	if result.IsError || !strings.Contains(result.ForLLM, `"result":6`) {
+		t.Fatalf("Execute() = %+v, want result 6", result)
+	}
+	result = tool.Execute(context.Background(), map[string]any{"code": fmt.Sprintf("result = read_file(%q)", outside)})
+	if !result.IsError || !strings.Contains(result.ForLLM, "access denied") {
+		t.Fatalf("Execute() outside workspace = %+v, want access denied", result)
+	}
+}
@@ -45,6 +45,7 @@ type ExecTool struct {
 	allowedPathPatterns []*regexp.Regexp
 	restrictToWorkspace bool
 	allowRemote         bool
+	isolationProfile    string
 	sessionManager      *SessionManager
 }
 
@@ -194,8 +195,12 @@ func NewExecToolWithConfig(
 	}
 
 	var timeout time.Duration
-	if cfg != nil && cfg.Tools.Exec.TimeoutSeconds > 0 {
-		timeout = time.Duration(cfg.Tools.Exec.TimeoutSeconds) * time.Second
+	isolationProfile := ""
+	if cfg != nil {
+		if cfg.Tools.Exec.TimeoutSeconds > 0 {
+			timeout = time.Duration(cfg.Tools.Exec.TimeoutSeconds) * time.Second
+		}
+		isolationProfile = cfg.Tools.Exec.IsolationProfile
 	}
 
 	return &ExecTool{
@@ -207,6 +212,7 @@ func NewExecToolWithConfig(
 		allowedPathPatterns: allowedPathPatterns,
 		restrictToWorkspace: restrict,
 		allowRemote:         allowRemote,
+		isolationProfile:    isolationProfile,
 		sessionManager:      getSessionManager(),
 	}, nil
 }
@@ -412,7 +418,7 @@ func (t *ExecTool) runSync(ctx context.Context, command, cwd string) *ToolResult
 
 	// Route shell execution through the shared isolation entry point so exec tool
 	// subprocesses receive the same isolation policy as other integrations.
-	if err := isolation.Start(cmd); err != nil {
+	if err := isolation.StartWith(cmd, isolation.Launch{Profile: t.isolationProfile}); err != nil {
 		return ErrorResult(fmt.Sprintf("failed to start command: %v", err))
 	}
 
@@ -428,7 +434,7 @@ func (t *ExecTool) runSync(ctx context.Context, command, cwd string) *ToolResult
 				done <- fmt.Errorf("panic in cmd.Wait: %v", r)
 			}
 		}()
-		done <- cmd.Wait()
+		done <- isolation.Wait(cmd)
 	}()
 
 	var err error
@@ -566,8 +572,10 @@ func (t *ExecTool) runBackground(ctx context.Context, command, cwd string, ptyEn
 	}
 
 	// Background sessions use the same startup path so isolation stays consistent
-	// with synchronous exec runs.
-	if err := isolation.Start(cmd); err != nil {
+	// with synchronous exec runs. They are services: they live until killed, so
+	// the profile's wall-clock limit does not apply.
+	launch := isolation.Launch{Profile: t.isolationProfile, Service: true}
+	if err := isolation.StartWith(cmd, launch); err != nil {
 		if session.ptyMaster != nil {
 			_ = session.ptyMaster.Close()
 		}
@@ -596,7 +604,7 @@ func (t *ExecTool) runBackground(ctx context.Context, command, cwd string, ptyEn
 					session.mu.Unlock()
 				}
 			}()
-			cmd.Wait() // Wait for process to exit
+			_ = isolation.Wait(cmd) // Wait for process to exit
 			session.mu.Lock()
 			if cmd.ProcessState != nil {
 				session.ExitCode = cmd.ProcessState.ExitCode()
@@ -700,7 +708,7 @@ func (t *ExecTool) runBackground(ctx context.Context, command, cwd string, ptyEn
 			if stdinWriter != nil {
 				_ = stdinWriter.Close()
 			}
-			cmd.Wait()
+			_ = isolation.Wait(cmd)
 
 			session.mu.Lock()
 			if cmd.ProcessState != nil {
@@ -31,6 +31,7 @@ type SubTurnConfig struct {
 	InitialMessages    []providers.Message
 	InitialTokenBudget *atomic.Int64 // Shared token budget for team members; nil if no budget
 	TargetAgentID      string        // If set, run as this agent (its workspace, model, tools)
+	ExtraTools         []Tool        // Registered on top of the child agent's own tools
 }
 
 type SubagentTask struct {
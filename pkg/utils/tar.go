@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// ExtractTarGzFile extracts a gzip-compressed tar archive from disk to
+// targetDir.
+//
+// Security: rejects path traversal attempts, symlinks, hard links and
+// device files.
+func ExtractTarGzFile(archivePath string, targetDir string) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("invalid tar.gz: %w", err)
+	}
+	defer gz.Close()
+
+	logger.DebugCF("tar", "Extracting tar.gz", map[string]any{
+		"archive_path": archivePath,
+		"target_dir":   targetDir,
+	})
+
+	if err := os.MkdirAll(targetDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create target dir: %w", err)
+	}
+	targetDirClean := filepath.Clean(targetDir)
+
+	reader := tar.NewReader(gz)
+	for {
+		hdr, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid tar.gz: %w", err)
+		}
+
+		// Path traversal protection.
+		cleanName := filepath.Clean(filepath.FromSlash(hdr.Name))
+		if strings.HasPrefix(cleanName, "..") || filepath.IsAbs(cleanName) {
+			return fmt.Errorf("tar entry has unsafe path: %q", hdr.Name)
+		}
+		destPath := filepath.Join(targetDir, cleanName)
+		if !strings.HasPrefix(filepath.Clean(destPath), targetDirClean+string(filepath.Separator)) &&
+			filepath.Clean(destPath) != targetDirClean {
+			return fmt.Errorf("tar entry escapes target dir: %q", hdr.Name)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(destPath, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(destPath), 0o755); err != nil {
+				return err
+			}
+			if err := extractTarEntry(reader, hdr, destPath); err != nil {
+				return err
+			}
+		case tar.TypeXGlobalHeader:
+			continue
+		default:
+			return fmt.Errorf("tar contains unsupported entry %q; only files and directories are allowed", hdr.Name)
+		}
+	}
+}
+
+// extractTarEntry writes one regular tar entry to destPath, with a size check.
+func extractTarEntry(reader io.Reader, hdr *tar.Header, destPath string) error {
+	const maxFileSize = 5 * 1024 * 1024 // 5MB, same as ZIP entries
+
+	if hdr.Size > maxFileSize {
+		return fmt.Errorf("tar entry %q is too large (%d bytes)", hdr.Name, hdr.Size)
+	}
+
+	mode := os.FileMode(0o644)
+	if hdr.FileInfo().Mode()&0o111 != 0 {
+		mode = 0o755
+	}
+	outFile, err := os.OpenFile(destPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return fmt.Errorf("failed to create file %q: %w", destPath, err)
+	}
+
+	written, err := io.CopyN(outFile, reader, maxFileSize+1)
+	closeErr := outFile.Close()
+	if err != nil && err != io.EOF {
+		_ = os.Remove(destPath)
+		return fmt.Errorf("failed to extract %q: %w", hdr.Name, err)
+	}
+	if written > maxFileSize {
+		_ = os.Remove(destPath)
+		return fmt.Errorf("tar entry %q exceeds max size (%d bytes)", hdr.Name, written)
+	}
+	if closeErr != nil {
+		_ = os.Remove(destPath)
+		return fmt.Errorf("failed to close %q: %w", destPath, closeErr)
+	}
+	return nil
+}
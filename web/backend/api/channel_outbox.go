@@ -0,0 +1,139 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/sipeed/picoclaw/pkg/channels/outbox"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/state"
+)
+
+type channelOutboxResponse struct {
+	Channel string         `json:"channel"`
+	Pending []outbox.Entry `json:"pending"`
+	Dead    []outbox.Entry `json:"dead"`
+}
+
+// handleGetChannelOutbox lists queued and failed outbound deliveries.
+//
+//	GET /api/channels/{name}/outbox
+func (h *Handler) handleGetChannelOutbox(w http.ResponseWriter, r *http.Request) {
+	channelName := r.PathValue("name")
+	store, ok := h.channelOutboxStore(w, channelName)
+	if !ok {
+		return
+	}
+
+	pending, err := store.Pending(channelName)
+	if err != nil {
+		http.Error(w, "Failed to read outbox", http.StatusInternalServerError)
+		return
+	}
+	dead, err := store.Dead(channelName)
+	if err != nil {
+		http.Error(w, "Failed to read outbox", http.StatusInternalServerError)
+		return
+	}
+	resp := channelOutboxResponse{
+		Channel: channelName,
+		Pending: append([]outbox.Entry{}, pending...),
+		Dead:    append([]outbox.Entry{}, dead...),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// handleReplayChannelOutbox requeues failed deliveries. An empty body or id
+// replays every failed delivery of the channel. The running gateway picks the
+// requeued entries up on its next outbox poll.
+//
+//	POST /api/channels/{name}/outbox/replay
+func (h *Handler) handleReplayChannelOutbox(w http.ResponseWriter, r *http.Request) {
+	channelName := r.PathValue("name")
+	store, ok := h.channelOutboxStore(w, channelName)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	replayed := 1
+	var err error
+	if req.ID == "" {
+		replayed, err = store.ReplayChannel(channelName)
+	} else {
+		_, err = store.Replay(channelName, req.ID)
+	}
+	if errors.Is(err, outbox.ErrNotFound) {
+		http.Error(w, "Outbox entry not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to replay outbox", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"replayed": replayed})
+}
+
+// handleDiscardChannelOutbox deletes a failed delivery.
+//
+//	DELETE /api/channels/{name}/outbox/{id}
+func (h *Handler) handleDiscardChannelOutbox(w http.ResponseWriter, r *http.Request) {
+	channelName := r.PathValue("name")
+	store, ok := h.channelOutboxStore(w, channelName)
+	if !ok {
+		return
+	}
+
+	id := r.PathValue("id")
+	dead, err := store.Dead(channelName)
+	if err != nil {
+		http.Error(w, "Failed to read outbox", http.StatusInternalServerError)
+		return
+	}
+	found := false
+	for _, entry := range dead {
+		if entry.ID == id {
+			found = true
+			break
+		}
+	}
+	if !found {
+		http.Error(w, "Outbox entry not found", http.StatusNotFound)
+		return
+	}
+	if err := store.Discard(id); err != nil && !errors.Is(err, outbox.ErrNotFound) {
+		http.Error(w, "Failed to discard outbox entry", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// channelOutboxStore opens the outbox of the configured workspace, writing an
+// error response and returning false when the channel or config is invalid.
+func (h *Handler) channelOutboxStore(w http.ResponseWriter, channelName string) (*outbox.Store, bool) {
+	if _, ok := findChannelCatalogItem(channelName); !ok {
+		http.Error(w, "Channel not found", http.StatusNotFound)
+		return nil, false
+	}
+	cfg, err := config.LoadConfig(h.configPath)
+	if err != nil {
+		http.Error(w, "Failed to load config", http.StatusInternalServerError)
+		return nil, false
+	}
+	return outbox.NewStore(outbox.DefaultDir(state.ResolveDir(cfg.WorkspacePath()))), true
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels/outbox"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/state"
+)
+
+func TestChannelOutbox_ListAndReplay(t *testing.T) {
+	configPath, cleanup := setupOAuthTestEnv(t)
+	defer cleanup()
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	store := outbox.NewStore(outbox.DefaultDir(state.ResolveDir(cfg.WorkspacePath())))
+	dead, err := store.DeadLetter("telegram", bus.OutboundMessage{ChatID: "42", Content: "hello"}, errors.New("boom"))
+	if err != nil {
+		t.Fatalf("DeadLetter() error = %v", err)
+	}
+	if _, err := store.DeadLetter("discord", bus.OutboundMessage{ChatID: "7", Content: "other"}, nil); err != nil {
+		t.Fatalf("DeadLetter() error = %v", err)
+	}
+
+	h := NewHandler(configPath)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/channels/telegram/outbox", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET outbox status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	var resp channelOutboxResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if len(resp.Pending) != 0 || len(resp.Dead) != 1 || resp.Dead[0].ID != dead.ID {
+		t.Fatalf("unexpected outbox response: %+v", resp)
+	}
+	if resp.Dead[0].LastError != "boom" {
+		t.Fatalf("last_error = %q, want boom", resp.Dead[0].LastError)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/channels/telegram/outbox/replay", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST replay status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	summary, err := store.Summarize("")
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if summary.Pending != 1 || summary.Dead != 1 {
+		t.Fatalf("summary = %+v, want 1 pending (telegram) and 1 dead (discord)", summary)
+	}
+}
+
+func TestChannelOutbox_ReplayRejectsOtherChannelEntry(t *testing.T) {
+	configPath, cleanup := setupOAuthTestEnv(t)
+	defer cleanup()
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	store := outbox.NewStore(outbox.DefaultDir(state.ResolveDir(cfg.WorkspacePath())))
+	entry, err := store.DeadLetter("discord", bus.OutboundMessage{ChatID: "7", Content: "hi"}, nil)
+	if err != nil {
+		t.Fatalf("DeadLetter() error = %v", err)
+	}
+
+	h := NewHandler(configPath)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	body := strings.NewReader(`{"id":"` + entry.ID + `"}`)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/channels/telegram/outbox/replay", body))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("POST replay status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/channels/discord/outbox/"+entry.ID, nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("DELETE status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if remaining, _ := store.Dead(""); len(remaining) != 0 {
+		t.Fatalf("dead entries after discard = %d, want 0", len(remaining))
+	}
+}
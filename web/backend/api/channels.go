@@ -41,10 +41,13 @@ type channelConfigResponse struct {
 	Variant           string   `json:"variant,omitempty"`
 }
 
-// registerChannelRoutes binds read-only channel catalog endpoints to the ServeMux.
+// registerChannelRoutes binds channel catalog and delivery queue endpoints to the ServeMux.
 func (h *Handler) registerChannelRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/channels/catalog", h.handleListChannelCatalog)
 	mux.HandleFunc("GET /api/channels/{name}/config", h.handleGetChannelConfig)
+	mux.HandleFunc("GET /api/channels/{name}/outbox", h.handleGetChannelOutbox)
+	mux.HandleFunc("POST /api/channels/{name}/outbox/replay", h.handleReplayChannelOutbox)
+	mux.HandleFunc("DELETE /api/channels/{name}/outbox/{id}", h.handleDiscardChannelOutbox)
 }
 
 // handleListChannelCatalog returns the channels supported by backend.
@@ -171,6 +174,9 @@ func addChannelCommonConfig(settings map[string]any, bc *config.Channel) {
 	if bc.Placeholder.Enabled || len(bc.Placeholder.Text) > 0 {
 		settings["placeholder"] = bc.Placeholder
 	}
+	if bc.InboundLimit.Enabled {
+		settings["inbound_limit"] = bc.InboundLimit
+	}
 	if _, exists := settings["streaming"]; !exists {
 		if streaming, ok := channelStreamingConfig(bc); ok {
 			if !streaming.IsZero() {
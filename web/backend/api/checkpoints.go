@@ -0,0 +1,172 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/checkpoint"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/routing"
+	"github.com/sipeed/picoclaw/pkg/state"
+)
+
+type checkpointListResponse struct {
+	Checkpoints []checkpoint.Checkpoint `json:"checkpoints"`
+}
+
+type checkpointDetailResponse struct {
+	Checkpoint checkpoint.Checkpoint `json:"checkpoint"`
+	Diff       []checkpoint.FileDiff `json:"diff"`
+}
+
+type checkpointRestoreRequest struct {
+	// Paths limits the restore to these paths, relative to the workspace or
+	// absolute inside it; empty restores all.
+	Paths []string `json:"paths"`
+}
+
+// registerCheckpointRoutes binds workspace checkpoint endpoints (/undo).
+func (h *Handler) registerCheckpointRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/agents/{id}/checkpoints", h.handleListCheckpoints)
+	mux.HandleFunc("GET /api/agents/{id}/checkpoints/{checkpoint}", h.handleGetCheckpoint)
+	mux.HandleFunc("POST /api/agents/{id}/checkpoints/{checkpoint}/restore", h.handleRestoreCheckpoint)
+}
+
+// handleListCheckpoints returns the checkpoints of an agent, newest first,
+// optionally filtered by session key.
+//
+//	GET /api/agents/{id}/checkpoints?session=<key>
+func (h *Handler) handleListCheckpoints(w http.ResponseWriter, r *http.Request) {
+	store, ok := h.checkpointStore(w, r)
+	if !ok {
+		return
+	}
+	list, err := store.List(r.URL.Query().Get("session"))
+	if err != nil {
+		http.Error(w, "Failed to read checkpoints", http.StatusInternalServerError)
+		return
+	}
+	if list == nil {
+		list = []checkpoint.Checkpoint{}
+	}
+	writeCheckpointJSON(w, checkpointListResponse{Checkpoints: list})
+}
+
+// handleGetCheckpoint returns a checkpoint with a diff of each captured file
+// against the current workspace.
+//
+//	GET /api/agents/{id}/checkpoints/{checkpoint}
+func (h *Handler) handleGetCheckpoint(w http.ResponseWriter, r *http.Request) {
+	store, ok := h.checkpointStore(w, r)
+	if !ok {
+		return
+	}
+	id := r.PathValue("checkpoint")
+	c, err := store.Get(id)
+	if err != nil {
+		writeCheckpointError(w, err)
+		return
+	}
+	diff, err := store.Diff(id)
+	if err != nil {
+		writeCheckpointError(w, err)
+		return
+	}
+	writeCheckpointJSON(w, checkpointDetailResponse{Checkpoint: c, Diff: diff})
+}
+
+// handleRestoreCheckpoint returns the files of a checkpoint to their captured
+// state.
+//
+//	POST /api/agents/{id}/checkpoints/{checkpoint}/restore
+func (h *Handler) handleRestoreCheckpoint(w http.ResponseWriter, r *http.Request) {
+	store, workspace, ok := h.agentCheckpointStore(w, r)
+	if !ok {
+		return
+	}
+	var req checkpointRestoreRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+	}
+	// Checkpoints record absolute paths, so resolve the requested ones
+	// against the workspace the same way the file tools do.
+	paths := make([]string, 0, len(req.Paths))
+	for _, p := range req.Paths {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(workspace, p)
+		}
+		p = filepath.Clean(p)
+		if rel, err := filepath.Rel(workspace, p); err != nil || rel == ".." ||
+			strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			http.Error(w, "path is outside the workspace: "+p, http.StatusBadRequest)
+			return
+		}
+		paths = append(paths, p)
+	}
+	result, err := store.Restore(r.PathValue("checkpoint"), paths...)
+	if err != nil {
+		writeCheckpointError(w, err)
+		return
+	}
+	writeCheckpointJSON(w, result)
+}
+
+// checkpointStore opens the checkpoint store of the agent in the request
+// path, the same directory the gateway writes.
+func (h *Handler) checkpointStore(w http.ResponseWriter, r *http.Request) (*checkpoint.Store, bool) {
+	store, _, ok := h.agentCheckpointStore(w, r)
+	return store, ok
+}
+
+// agentCheckpointStore is checkpointStore that also returns the agent's
+// workspace.
+func (h *Handler) agentCheckpointStore(w http.ResponseWriter, r *http.Request) (*checkpoint.Store, string, bool) {
+	cfg, err := config.LoadConfig(h.configPath)
+	if err != nil {
+		http.Error(w, "Failed to load config", http.StatusInternalServerError)
+		return nil, "", false
+	}
+	agentID := routing.NormalizeAgentID(r.PathValue("id"))
+	if agentID != routing.DefaultAgentID {
+		if _, found := findAgentConfig(cfg, agentID); !found {
+			http.Error(w, "agent not found", http.StatusNotFound)
+			return nil, "", false
+		}
+	}
+	workspace := filepath.Clean(resolveAgentWorkspaceForID(cfg, agentID))
+	return workspaceCheckpointStore(cfg, workspace), workspace, true
+}
+
+func workspaceCheckpointStore(cfg *config.Config, workspace string) *checkpoint.Store {
+	c := cfg.Tools.Checkpoints
+	return checkpoint.NewStore(checkpoint.DefaultDir(state.ResolveDir(workspace)), checkpoint.Options{
+		MaxTotalBytes: int64(c.MaxTotalMB) << 20,
+		MaxFileBytes:  int64(c.MaxFileKB) << 10,
+		MaxCount:      c.MaxCount,
+	})
+}
+
+func writeCheckpointError(w http.ResponseWriter, err error) {
+	if errors.Is(err, checkpoint.ErrNotFound) {
+		http.Error(w, "Checkpoint not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, checkpoint.ErrNoMatchingPaths) {
+		http.Error(w, "No captured file matches the requested paths", http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+func writeCheckpointJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
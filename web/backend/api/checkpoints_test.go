@@ -0,0 +1,139 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/checkpoint"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/routing"
+	"github.com/sipeed/picoclaw/pkg/state"
+)
+
+func TestCheckpoints_DiffAndRestore(t *testing.T) {
+	configPath, cleanup := setupOAuthTestEnv(t)
+	defer cleanup()
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	workspace := resolveAgentWorkspaceForID(cfg, routing.DefaultAgentID)
+	if err := os.MkdirAll(workspace, 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	target := filepath.Join(workspace, "notes.md")
+	if err := os.WriteFile(target, []byte("before\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	// The gateway records into the same store.
+	store := checkpoint.NewStore(checkpoint.DefaultDir(state.ResolveDir(workspace)), checkpoint.Options{})
+	rec := store.Begin(workspace, "session-1", "turn", "edit notes")
+	if err := rec.Capture(target); err != nil {
+		t.Fatalf("Capture() error = %v", err)
+	}
+	if err := os.WriteFile(target, []byte("after\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	h := NewHandler(configPath)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/agents/main/checkpoints?session=session-1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("list status = %d, body=%s", w.Code, w.Body.String())
+	}
+	var list checkpointListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if len(list.Checkpoints) != 1 || list.Checkpoints[0].ID != rec.ID() {
+		t.Fatalf("checkpoints = %+v, want %s", list.Checkpoints, rec.ID())
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/agents/main/checkpoints/"+rec.ID(), nil))
+	var detail checkpointDetailResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &detail); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v, body=%s", err, w.Body.String())
+	}
+	if len(detail.Diff) != 1 || detail.Diff[0].Status != "modified" {
+		t.Fatalf("diff = %+v, want one modified file", detail.Diff)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/agents/main/checkpoints/"+rec.ID()+"/restore", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("restore status = %d, body=%s", w.Code, w.Body.String())
+	}
+	if data, _ := os.ReadFile(target); string(data) != "before\n" {
+		t.Fatalf("notes.md = %q, want restored content", data)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/agents/main/checkpoints/missing/restore", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("missing restore status = %d, want 404", w.Code)
+	}
+}
+
+func TestCheckpoints_PartialRestoreResolvesWorkspacePaths(t *testing.T) {
+	configPath, cleanup := setupOAuthTestEnv(t)
+	defer cleanup()
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	workspace := resolveAgentWorkspaceForID(cfg, routing.DefaultAgentID)
+	if err := os.MkdirAll(workspace, 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	target := filepath.Join(workspace, "notes.txt")
+	if err := os.WriteFile(target, []byte("before\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	store := checkpoint.NewStore(checkpoint.DefaultDir(state.ResolveDir(workspace)), checkpoint.Options{})
+	rec := store.Begin(workspace, "session-1", "turn", "edit notes")
+	if err := rec.Capture(target); err != nil {
+		t.Fatalf("Capture() error = %v", err)
+	}
+	if err := os.WriteFile(target, []byte("after\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	h := NewHandler(configPath)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	restore := func(body string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/agents/main/checkpoints/"+rec.ID()+"/restore",
+			strings.NewReader(body))
+		mux.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := restore(`{"paths":["../outside.txt"]}`); code != http.StatusBadRequest {
+		t.Fatalf("outside restore status = %d, want 400", code)
+	}
+	if code := restore(`{"paths":["missing.txt"]}`); code != http.StatusNotFound {
+		t.Fatalf("unmatched restore status = %d, want 404", code)
+	}
+	if code := restore(`{"paths":["notes.txt"]}`); code != http.StatusOK {
+		t.Fatalf("partial restore status = %d, want 200", code)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "before\n" {
+		t.Fatalf("notes.txt = %q, want restored content", data)
+	}
+	if c, err := store.Get(rec.ID()); err != nil || c.RestoredAt != nil {
+		t.Fatalf("Get() = %+v, %v, want the checkpoint still undoable", c, err)
+	}
+}
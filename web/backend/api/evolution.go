@@ -0,0 +1,194 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/evolution"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/routing"
+)
+
+type evolutionDraftListResponse struct {
+	Approval string                 `json:"approval"`
+	Drafts   []evolution.SkillDraft `json:"drafts"`
+}
+
+type evolutionDraftDecisionRequest struct {
+	Reviewer string `json:"reviewer"`
+	// Reason is recorded in the review notes of a rejected draft.
+	Reason string `json:"reason"`
+}
+
+// registerEvolutionRoutes binds the evolution draft review endpoints
+// (/evolution drafts|show|accept|reject).
+func (h *Handler) registerEvolutionRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/agents/{id}/evolution/drafts", h.handleListEvolutionDrafts)
+	mux.HandleFunc("GET /api/agents/{id}/evolution/drafts/{draft}", h.handleGetEvolutionDraft)
+	mux.HandleFunc("POST /api/agents/{id}/evolution/drafts/{draft}/accept", h.handleAcceptEvolutionDraft)
+	mux.HandleFunc("POST /api/agents/{id}/evolution/drafts/{draft}/reject", h.handleRejectEvolutionDraft)
+}
+
+// handleListEvolutionDrafts returns the drafts of an agent, newest first,
+// optionally filtered by status.
+//
+//	GET /api/agents/{id}/evolution/drafts?status=candidate
+func (h *Handler) handleListEvolutionDrafts(w http.ResponseWriter, r *http.Request) {
+	cfg, rt, workspace, ok := h.evolutionRuntime(w, r)
+	if !ok {
+		return
+	}
+	drafts, err := rt.ListDrafts(workspace)
+	if err != nil {
+		http.Error(w, "Failed to read drafts", http.StatusInternalServerError)
+		return
+	}
+	status := evolution.DraftStatus(r.URL.Query().Get("status"))
+	filtered := make([]evolution.SkillDraft, 0, len(drafts))
+	for _, draft := range drafts {
+		if status == "" || draft.Status == status {
+			filtered = append(filtered, draft)
+		}
+	}
+	writeCheckpointJSON(w, evolutionDraftListResponse{
+		Approval: cfg.Evolution.EffectiveApproval(),
+		Drafts:   filtered,
+	})
+}
+
+// handleGetEvolutionDraft returns a draft with its diff against the current
+// skill, the task records it was learned from and their success ratio.
+//
+//	GET /api/agents/{id}/evolution/drafts/{draft}
+func (h *Handler) handleGetEvolutionDraft(w http.ResponseWriter, r *http.Request) {
+	_, rt, workspace, ok := h.evolutionRuntime(w, r)
+	if !ok {
+		return
+	}
+	detail, err := rt.DraftDetail(workspace, r.PathValue("draft"))
+	if err != nil {
+		writeEvolutionDraftError(w, err)
+		return
+	}
+	writeCheckpointJSON(w, detail)
+}
+
+// handleAcceptEvolutionDraft applies a candidate draft. The response carries
+// the updated draft, which is quarantined when required skill tests fail.
+//
+//	POST /api/agents/{id}/evolution/drafts/{draft}/accept
+func (h *Handler) handleAcceptEvolutionDraft(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeEvolutionDecision(w, r)
+	if !ok {
+		return
+	}
+	_, rt, workspace, ok := h.evolutionRuntime(w, r)
+	if !ok {
+		return
+	}
+	draft, err := rt.AcceptDraft(r.Context(), workspace, r.PathValue("draft"), req.Reviewer)
+	if err != nil {
+		writeEvolutionDraftError(w, err)
+		return
+	}
+	writeCheckpointJSON(w, draft)
+}
+
+// handleRejectEvolutionDraft rejects a candidate or quarantined draft.
+//
+//	POST /api/agents/{id}/evolution/drafts/{draft}/reject
+func (h *Handler) handleRejectEvolutionDraft(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeEvolutionDecision(w, r)
+	if !ok {
+		return
+	}
+	_, rt, workspace, ok := h.evolutionRuntime(w, r)
+	if !ok {
+		return
+	}
+	draft, err := rt.RejectDraft(workspace, r.PathValue("draft"), req.Reviewer, req.Reason)
+	if err != nil {
+		writeEvolutionDraftError(w, err)
+		return
+	}
+	writeCheckpointJSON(w, draft)
+}
+
+func decodeEvolutionDecision(w http.ResponseWriter, r *http.Request) (evolutionDraftDecisionRequest, bool) {
+	var req evolutionDraftDecisionRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return req, false
+		}
+	}
+	if req.Reviewer == "" {
+		req.Reviewer = "web"
+	}
+	return req, true
+}
+
+// evolutionRuntime opens the evolution state of the agent in the request
+// path with the same applier and skill test gate the gateway uses.
+func (h *Handler) evolutionRuntime(
+	w http.ResponseWriter,
+	r *http.Request,
+) (*config.Config, *evolution.Runtime, string, bool) {
+	cfg, err := config.LoadConfig(h.configPath)
+	if err != nil {
+		http.Error(w, "Failed to load config", http.StatusInternalServerError)
+		return nil, nil, "", false
+	}
+	agentID := routing.NormalizeAgentID(r.PathValue("id"))
+	if agentID != routing.DefaultAgentID {
+		if _, found := findAgentConfig(cfg, agentID); !found {
+			http.Error(w, "agent not found", http.StatusNotFound)
+			return nil, nil, "", false
+		}
+	}
+	workspace := resolveAgentWorkspaceForID(cfg, agentID)
+	applier := evolution.NewApplier(evolution.NewPaths(workspace, cfg.Evolution.StateDir), nil)
+	if cfg.Tools.IsToolEnabled("checkpoints") {
+		applier = applier.WithCheckpoints(workspaceCheckpointStore(cfg, workspace))
+	}
+	opts := evolution.RuntimeOptions{Config: cfg.Evolution, Applier: applier}
+	if cfg.Evolution.RequireSkillTests {
+		opts.DraftTesterFactory = func(string) evolution.DraftTester {
+			return agent.NewSkillScenarioTester(cfg, scenarioProvider(cfg))
+		}
+	}
+	rt, err := evolution.NewRuntime(opts)
+	if err != nil {
+		http.Error(w, "Failed to open evolution state", http.StatusInternalServerError)
+		return nil, nil, "", false
+	}
+	return cfg, rt, workspace, true
+}
+
+// scenarioProvider returns the default model's provider for unscripted skill
+// scenarios, or nil so that only scripted scenarios run.
+func scenarioProvider(cfg *config.Config) providers.LLMProvider {
+	modelCfg, err := cfg.GetModelConfig(cfg.Agents.Defaults.GetModelName())
+	if err != nil {
+		return nil
+	}
+	provider, _, err := providers.CreateProviderFromConfig(modelCfg)
+	if err != nil {
+		return nil
+	}
+	return provider
+}
+
+func writeEvolutionDraftError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, evolution.ErrDraftNotFound):
+		http.Error(w, "Draft not found", http.StatusNotFound)
+	case errors.Is(err, evolution.ErrDraftAmbiguous), errors.Is(err, evolution.ErrDraftDecided):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
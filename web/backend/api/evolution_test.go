@@ -0,0 +1,106 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/evolution"
+	"github.com/sipeed/picoclaw/pkg/routing"
+)
+
+func TestEvolutionDrafts_ReviewFlow(t *testing.T) {
+	configPath, cleanup := setupOAuthTestEnv(t)
+	defer cleanup()
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	workspace := resolveAgentWorkspaceForID(cfg, routing.DefaultAgentID)
+	store := evolution.NewStore(evolution.NewPaths(workspace, cfg.Evolution.StateDir))
+	body := "---\nname: weather\ndescription: weather helper\n---\n# Weather\nUse native-name query first.\n"
+	if err := store.SaveDrafts([]evolution.SkillDraft{
+		{
+			ID: "draft-rule-1", WorkspaceID: workspace, CreatedAt: time.Unix(1700000000, 0).UTC(),
+			SourceRecordID: "rule-1", TargetSkillName: "weather", DraftType: evolution.DraftTypeShortcut,
+			ChangeKind: evolution.ChangeKindCreate, HumanSummary: "weather helper", BodyOrPatch: body,
+			Status: evolution.DraftStatusCandidate,
+		},
+		{
+			ID: "draft-rule-2", WorkspaceID: workspace, CreatedAt: time.Unix(1700000100, 0).UTC(),
+			SourceRecordID: "rule-2", TargetSkillName: "news", DraftType: evolution.DraftTypeShortcut,
+			ChangeKind: evolution.ChangeKindCreate, HumanSummary: "news helper",
+			BodyOrPatch: "---\nname: news\ndescription: news helper\n---\n# News\n",
+			Status:      evolution.DraftStatusCandidate,
+		},
+	}); err != nil {
+		t.Fatalf("SaveDrafts() error = %v", err)
+	}
+
+	h := NewHandler(configPath)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/agents/main/evolution/drafts?status=candidate", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("list status = %d, body=%s", w.Code, w.Body.String())
+	}
+	var list evolutionDraftListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if len(list.Drafts) != 2 || list.Drafts[0].ID != "draft-rule-2" || list.Approval != "auto" {
+		t.Fatalf("list = %+v, want two candidates newest first", list)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/agents/main/evolution/drafts/draft-rule-1", nil))
+	var detail evolution.DraftDetail
+	if err := json.Unmarshal(w.Body.Bytes(), &detail); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v, body=%s", err, w.Body.String())
+	}
+	if !strings.Contains(detail.Preview.DiffPreview, "+Use native-name query first.") {
+		t.Fatalf("detail = %+v, want a diff preview", detail)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/agents/main/evolution/drafts/draft-rule-1/accept",
+		strings.NewReader(`{"reviewer":"alice"}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("accept status = %d, body=%s", w.Code, w.Body.String())
+	}
+	if _, err := os.Stat(filepath.Join(workspace, "skills", "weather", "SKILL.md")); err != nil {
+		t.Fatalf("expected accepted skill on disk: %v", err)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/agents/main/evolution/drafts/draft-rule-2/reject",
+		strings.NewReader(`{"reason":"not needed"}`)))
+	var rejected evolution.SkillDraft
+	if err := json.Unmarshal(w.Body.Bytes(), &rejected); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v, body=%s", err, w.Body.String())
+	}
+	if rejected.Status != evolution.DraftStatusRejected || rejected.ReviewedBy != "web" {
+		t.Fatalf("rejected = %+v", rejected)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/agents/main/evolution/drafts/draft-rule-2/accept", nil))
+	if w.Code != http.StatusConflict {
+		t.Fatalf("accept rejected draft status = %d, want %d", w.Code, http.StatusConflict)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/agents/main/evolution/drafts/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("missing draft status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
@@ -428,18 +428,33 @@ func computeConfigSignature(cfg *config.Config) string {
 	if cfg.Tools.ListDir.Enabled {
 		toolSignatures = append(toolSignatures, "list_dir")
 	}
+	if cfg.Tools.GrepFiles.Enabled {
+		toolSignatures = append(toolSignatures, "grep_files")
+	}
+	if cfg.Tools.GlobFiles.Enabled {
+		toolSignatures = append(toolSignatures, "glob_files")
+	}
 	if cfg.Tools.EditFile.Enabled {
 		toolSignatures = append(toolSignatures, "edit_file")
 	}
 	if cfg.Tools.AppendFile.Enabled {
 		toolSignatures = append(toolSignatures, "append_file")
 	}
+	if cfg.Tools.ApplyPatch.Enabled {
+		toolSignatures = append(toolSignatures, "apply_patch")
+	}
 	if cfg.Tools.Exec.Enabled {
 		toolSignatures = append(toolSignatures, "exec")
 	}
+	if cfg.Tools.RunScript.Enabled {
+		toolSignatures = append(toolSignatures, "run_script")
+	}
 	if cfg.Tools.Cron.Enabled {
 		toolSignatures = append(toolSignatures, "cron")
 	}
+	if cfg.Tools.Home.Enabled {
+		toolSignatures = append(toolSignatures, "home")
+	}
 	if cfg.Tools.Web.Enabled {
 		toolSignatures = append(toolSignatures, "web")
 		webConfig, err := json.Marshal(canonicalizeSignatureValue(reflect.ValueOf(cfg.Tools.Web)))
@@ -450,6 +465,9 @@ func computeConfigSignature(cfg *config.Config) string {
 	if cfg.Tools.WebFetch.Enabled {
 		toolSignatures = append(toolSignatures, "web_fetch")
 	}
+	if cfg.Tools.HTTPRequest.Enabled {
+		toolSignatures = append(toolSignatures, "http_request")
+	}
 	if cfg.Tools.Message.Enabled {
 		toolSignatures = append(toolSignatures, "message")
 	}
@@ -474,6 +492,21 @@ func computeConfigSignature(cfg *config.Config) string {
 	if cfg.Tools.SPI.Enabled {
 		toolSignatures = append(toolSignatures, "spi")
 	}
+	if cfg.Tools.GPIO.Enabled {
+		toolSignatures = append(toolSignatures, "gpio")
+	}
+	if cfg.Tools.PWM.Enabled {
+		toolSignatures = append(toolSignatures, "pwm")
+	}
+	if cfg.Tools.OneWire.Enabled {
+		toolSignatures = append(toolSignatures, "onewire")
+	}
+	if cfg.Tools.Modbus.Enabled {
+		toolSignatures = append(toolSignatures, "modbus")
+	}
+	if cfg.Tools.CANBus.Enabled {
+		toolSignatures = append(toolSignatures, "canbus")
+	}
 	if cfg.Tools.MCP.Enabled {
 		toolSignatures = append(toolSignatures, "mcp")
 	}
@@ -669,6 +702,7 @@ func computeChannelSignatures(channels config.ChannelsConfig) []string {
 			GroupTrigger       config.GroupTriggerConfig  `json:"group_trigger,omitempty"`
 			Typing             config.TypingConfig        `json:"typing,omitempty"`
 			Placeholder        config.PlaceholderConfig   `json:"placeholder,omitempty"`
+			InboundLimit       config.InboundLimitConfig  `json:"inbound_limit,omitempty"`
 			Settings           json.RawMessage            `json:"settings,omitempty"`
 		}{
 			Enabled:            channel.Enabled,
@@ -678,6 +712,7 @@ func computeChannelSignatures(channels config.ChannelsConfig) []string {
 			GroupTrigger:       channel.GroupTrigger,
 			Typing:             channel.Typing,
 			Placeholder:        channel.Placeholder,
+			InboundLimit:       channel.InboundLimit,
 			Settings:           normalizeChannelSettings(channel),
 		}
 
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/identity"
+	"github.com/sipeed/picoclaw/pkg/routing"
+	"github.com/sipeed/picoclaw/pkg/state"
+)
+
+type identityLinksResponse struct {
+	// Links were created at runtime via /link or this API.
+	Links map[string][]string `json:"links"`
+	// Configured links come from session.identity_links and take precedence.
+	Configured map[string][]string `json:"configured"`
+}
+
+type identityLinkRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// registerIdentityRoutes binds identity link administration endpoints.
+func (h *Handler) registerIdentityRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/identity/links", h.handleListIdentityLinks)
+	mux.HandleFunc("PUT /api/identity/links/{canonical}", h.handlePutIdentityLink)
+	mux.HandleFunc("DELETE /api/identity/links/{canonical}", h.handleDeleteIdentityLink)
+	mux.HandleFunc("DELETE /api/identity/links/{canonical}/{id}", h.handleUnlinkIdentity)
+}
+
+// handleListIdentityLinks returns runtime and configured identity links.
+//
+//	GET /api/identity/links
+func (h *Handler) handleListIdentityLinks(w http.ResponseWriter, r *http.Request) {
+	cfg, store, ok := h.identityLinkStore(w)
+	if !ok {
+		return
+	}
+	resp := identityLinksResponse{
+		Links:      store.Links(),
+		Configured: cfg.Session.IdentityLinks,
+	}
+	if resp.Links == nil {
+		resp.Links = map[string][]string{}
+	}
+	if resp.Configured == nil {
+		resp.Configured = map[string][]string{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// handlePutIdentityLink adds identities ("channel:sender_id") to a linked
+// group, creating the group when needed.
+//
+//	PUT /api/identity/links/{canonical}
+func (h *Handler) handlePutIdentityLink(w http.ResponseWriter, r *http.Request) {
+	_, store, ok := h.identityLinkStore(w)
+	if !ok {
+		return
+	}
+	var req identityLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	for _, id := range req.IDs {
+		if _, _, ok := identity.ParseCanonicalID(id); !ok {
+			http.Error(w, "Identities must use the channel:sender_id format", http.StatusBadRequest)
+			return
+		}
+	}
+	if err := store.Link(r.PathValue("canonical"), req.IDs...); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleDeleteIdentityLink removes a whole linked group.
+//
+//	DELETE /api/identity/links/{canonical}
+func (h *Handler) handleDeleteIdentityLink(w http.ResponseWriter, r *http.Request) {
+	_, store, ok := h.identityLinkStore(w)
+	if !ok {
+		return
+	}
+	writeIdentityLinkResult(w, store.Remove(r.PathValue("canonical")))
+}
+
+// handleUnlinkIdentity removes a single identity from a linked group.
+//
+//	DELETE /api/identity/links/{canonical}/{id}
+func (h *Handler) handleUnlinkIdentity(w http.ResponseWriter, r *http.Request) {
+	_, store, ok := h.identityLinkStore(w)
+	if !ok {
+		return
+	}
+	canonical, id := r.PathValue("canonical"), r.PathValue("id")
+	linked, _ := store.Group(id)
+	if !strings.EqualFold(linked, canonical) {
+		http.Error(w, "Identity link not found", http.StatusNotFound)
+		return
+	}
+	_, err := store.Unlink(id)
+	writeIdentityLinkResult(w, err)
+}
+
+func writeIdentityLinkResult(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, identity.ErrNotLinked):
+		http.Error(w, "Identity link not found", http.StatusNotFound)
+	case err != nil:
+		http.Error(w, "Failed to update identity links", http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// identityLinkStore opens the link store of the default agent workspace, the
+// same file the gateway reads.
+func (h *Handler) identityLinkStore(w http.ResponseWriter) (*config.Config, *identity.LinkStore, bool) {
+	cfg, err := config.LoadConfig(h.configPath)
+	if err != nil {
+		http.Error(w, "Failed to load config", http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	workspace := routing.DefaultAgentWorkspace(cfg)
+	return cfg, identity.NewLinkStore(identity.DefaultLinkStorePath(state.ResolveDir(workspace))), true
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/identity"
+	"github.com/sipeed/picoclaw/pkg/routing"
+	"github.com/sipeed/picoclaw/pkg/state"
+)
+
+func TestIdentityLinks_AdminRoundTrip(t *testing.T) {
+	configPath, cleanup := setupOAuthTestEnv(t)
+	defer cleanup()
+
+	h := NewHandler(configPath)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	body := strings.NewReader(`{"ids":["telegram:1","discord:2"]}`)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/api/identity/links/alice", body))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("PUT status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+
+	// The gateway reads the same file.
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	workspace := resolveAgentWorkspaceForID(cfg, routing.DefaultAgentID)
+	store := identity.NewLinkStore(identity.DefaultLinkStorePath(state.ResolveDir(workspace)))
+	if canonical, _ := store.LinkedIdentities("discord", "2"); canonical != "alice" {
+		t.Fatalf("LinkedIdentities() canonical = %q, want alice", canonical)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/identity/links", nil))
+	var resp identityLinksResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if len(resp.Links["alice"]) != 2 {
+		t.Fatalf("links = %v", resp.Links)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/identity/links/bob/telegram:1", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("DELETE from wrong group status = %d, want 404", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/identity/links/alice/telegram:1", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("DELETE member status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if links := store.Links(); len(links) != 0 {
+		t.Fatalf("links after unlink = %v, want empty", links)
+	}
+
+	rec = httptest.NewRecorder()
+	body = strings.NewReader(`{"ids":["not-canonical"]}`)
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/api/identity/links/alice", body))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("PUT invalid id status = %d, want 400", rec.Code)
+	}
+}
@@ -57,6 +57,10 @@ type modelResponse struct {
 	DisableTools        bool                        `json:"disable_tools,omitempty"`
 	ExtraBody           map[string]any              `json:"extra_body,omitempty"`
 	CustomHeaders       map[string]string           `json:"custom_headers,omitempty"`
+	Vision              bool                        `json:"vision,omitempty"`
+	ContextWindow       int                         `json:"context_window,omitempty"`
+	InputCost           float64                     `json:"input_cost,omitempty"`
+	OutputCost          float64                     `json:"output_cost,omitempty"`
 	// Meta
 	Enabled             bool   `json:"enabled"`
 	Available           bool   `json:"available"`
@@ -300,6 +304,10 @@ func (h *Handler) handleListModels(w http.ResponseWriter, r *http.Request) {
 			DisableTools:        m.DisableTools,
 			ExtraBody:           m.ExtraBody,
 			CustomHeaders:       m.CustomHeaders,
+			Vision:              m.Vision,
+			ContextWindow:       m.ContextWindow,
+			InputCost:           m.InputCost,
+			OutputCost:          m.OutputCost,
 			Enabled:             m.Enabled,
 			Available:           modelStatuses[i].Available,
 			Status:              modelStatuses[i].Status,
@@ -454,6 +462,19 @@ func (h *Handler) handleUpdateModel(w http.ResponseWriter, r *http.Request) {
 	if _, ok := rawFields["streaming"]; !ok {
 		mc.Streaming = cfg.ModelList[idx].Streaming
 	}
+	// Routing metadata is not edited in the UI yet; keep it unless sent.
+	if _, ok := rawFields["vision"]; !ok {
+		mc.Vision = cfg.ModelList[idx].Vision
+	}
+	if _, ok := rawFields["context_window"]; !ok {
+		mc.ContextWindow = cfg.ModelList[idx].ContextWindow
+	}
+	if _, ok := rawFields["input_cost"]; !ok {
+		mc.InputCost = cfg.ModelList[idx].InputCost
+	}
+	if _, ok := rawFields["output_cost"]; !ok {
+		mc.OutputCost = cfg.ModelList[idx].OutputCost
+	}
 	// Preserve the existing Provider when the caller omits it. This keeps the
 	// update API backward-compatible for clients that haven't started sending
 	// the new field yet, while still allowing explicit clearing via "".
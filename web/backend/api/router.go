@@ -95,6 +95,15 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	// Channel catalog (for frontend navigation/config pages)
 	h.registerChannelRoutes(mux)
 
+	// Cross-channel identity links (/link administration)
+	h.registerIdentityRoutes(mux)
+
+	// Workspace file checkpoints (/undo)
+	h.registerCheckpointRoutes(mux)
+
+	// Evolution draft review (/evolution)
+	h.registerEvolutionRoutes(mux)
+
 	// Skills and tools support/actions
 	h.registerSkillRoutes(mux)
 	h.registerToolRoutes(mux)
@@ -18,6 +18,7 @@ import (
 
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/fileutil"
+	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/skills"
 	"github.com/sipeed/picoclaw/pkg/utils"
 )
@@ -38,6 +39,10 @@ type skillSupportItem struct {
 	RegistryURL      string `json:"registry_url,omitempty"`
 	InstalledVersion string `json:"installed_version,omitempty"`
 	InstalledAt      int64  `json:"installed_at,omitempty"`
+	// Missing lists the skill's unmet requirements on this host.
+	Missing []skills.MissingRequirement `json:"missing,omitempty"`
+	// Tampered is set when the skill differs from its skills.lock hash.
+	Tampered bool `json:"tampered,omitempty"`
 }
 
 type skillDetailResponse struct {
@@ -414,6 +419,12 @@ func (h *Handler) handleInstallSkill(w http.ResponseWriter, r *http.Request) {
 		)
 		return
 	}
+	if _, err := skills.RecordInstall(cfg.WorkspacePath(), dirName, registry.Name(), normalizedSlug, result); err != nil {
+		logger.WarnCF("skills", "Failed to update skills lockfile", map[string]any{
+			"skill": dirName,
+			"error": err.Error(),
+		})
+	}
 
 	installedSkill := &skillSupportItem{
 		Name:             validatedSkill.Name,
@@ -425,6 +436,7 @@ func (h *Handler) handleInstallSkill(w http.ResponseWriter, r *http.Request) {
 		RegistryURL:      registryURL,
 		InstalledVersion: result.Version,
 		InstalledAt:      installedAt,
+		Missing:          validatedSkill.Missing,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -506,6 +518,10 @@ func (h *Handler) handleDeleteSkill(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, fmt.Sprintf("Failed to delete skill: %v", err), http.StatusInternalServerError)
 			return
 		}
+		if err := skills.ForgetInstall(cfg.WorkspacePath(), filepath.Base(filepath.Dir(skill.Path))); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to update %s: %v", skills.LockFileName, err), http.StatusInternalServerError)
+			return
+		}
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 		return
@@ -541,7 +557,9 @@ func ensureSkillRegistryToolEnabled(cfg *config.Config, toolName string) error {
 }
 
 func buildSkillSupportItems(cfg *config.Config) ([]skillSupportItem, error) {
-	rawSkills := newSkillsLoader(cfg.WorkspacePath()).ListSkills()
+	loader := newSkillsLoader(cfg.WorkspacePath())
+	loader.SetRequirementChecker(skills.NewRequirementChecker(cfg))
+	rawSkills := loader.ListSkills()
 	items := make([]skillSupportItem, 0, len(rawSkills))
 	for _, skill := range rawSkills {
 		item, err := enrichSkillInfo(cfg, skill)
@@ -680,6 +698,8 @@ func enrichSkillInfo(cfg *config.Config, skill skills.SkillInfo) (skillSupportIt
 		Source:      skill.Source,
 		Description: skill.Description,
 		OriginKind:  "builtin",
+		Missing:     skill.Missing,
+		Tampered:    skill.Tampered,
 	}
 
 	switch skill.Source {
@@ -88,6 +88,18 @@ var toolCatalog = []toolCatalogEntry{
 		Category:    "filesystem",
 		ConfigKey:   "list_dir",
 	},
+	{
+		Name:        "grep_files",
+		Description: "Search file contents with regular expressions inside the readable scope.",
+		Category:    "filesystem",
+		ConfigKey:   "grep_files",
+	},
+	{
+		Name:        "glob_files",
+		Description: "Find files by path glob inside the readable scope.",
+		Category:    "filesystem",
+		ConfigKey:   "glob_files",
+	},
 	{
 		Name:        "edit_file",
 		Description: "Apply targeted edits to existing files without rewriting everything.",
@@ -100,18 +112,36 @@ var toolCatalog = []toolCatalogEntry{
 		Category:    "filesystem",
 		ConfigKey:   "append_file",
 	},
+	{
+		Name:        "apply_patch",
+		Description: "Apply unified diffs or multi-file patches atomically within the writable workspace scope.",
+		Category:    "filesystem",
+		ConfigKey:   "apply_patch",
+	},
 	{
 		Name:        "exec",
 		Description: "Run shell commands inside the configured workspace sandbox.",
 		Category:    "filesystem",
 		ConfigKey:   "exec",
 	},
+	{
+		Name:        "run_script",
+		Description: "Evaluate small Starlark scripts for calculations and data reshaping without shell access.",
+		Category:    "automation",
+		ConfigKey:   "run_script",
+	},
 	{
 		Name:        "cron",
 		Description: "Schedule one-time or recurring reminders, jobs, and shell commands.",
 		Category:    "automation",
 		ConfigKey:   "cron",
 	},
+	{
+		Name:        "home",
+		Description: "Read Home Assistant entities and areas, call allowlisted services, and wait for state changes.",
+		Category:    "automation",
+		ConfigKey:   "home",
+	},
 	{
 		Name:        "web_search",
 		Description: "Search the web using the configured providers.",
@@ -124,6 +154,12 @@ var toolCatalog = []toolCatalogEntry{
 		Category:    "web",
 		ConfigKey:   "web_fetch",
 	},
+	{
+		Name:        "http_request",
+		Description: "Call REST APIs with methods, headers, bodies and named credentials.",
+		Category:    "web",
+		ConfigKey:   "http_request",
+	},
 	{
 		Name:        "message",
 		Description: "Send a follow-up message back to the active user or chat.",
@@ -178,6 +214,36 @@ var toolCatalog = []toolCatalogEntry{
 		Category:    "hardware",
 		ConfigKey:   "serial",
 	},
+	{
+		Name:        "gpio",
+		Description: "Read, drive and watch GPIO lines through the Linux GPIO character device.",
+		Category:    "hardware",
+		ConfigKey:   "gpio",
+	},
+	{
+		Name:        "pwm",
+		Description: "Configure PWM outputs through Linux sysfs.",
+		Category:    "hardware",
+		ConfigKey:   "pwm",
+	},
+	{
+		Name:        "onewire",
+		Description: "Read 1-Wire temperature sensors through the Linux w1 bus.",
+		Category:    "hardware",
+		ConfigKey:   "onewire",
+	},
+	{
+		Name:        "modbus",
+		Description: "Read and write allowlisted Modbus TCP and RTU devices.",
+		Category:    "hardware",
+		ConfigKey:   "modbus",
+	},
+	{
+		Name:        "canbus",
+		Description: "Send, receive and capture CAN frames on allowlisted SocketCAN interfaces.",
+		Category:    "hardware",
+		ConfigKey:   "canbus",
+	},
 	{
 		Name:        "tool_search_tool_regex",
 		Description: "Discover hidden MCP tools by regex search when tool discovery is enabled.",
@@ -270,7 +336,7 @@ func buildToolSupport(cfg *config.Config) []toolSupportItem {
 			status, reasonCode = resolveDiscoveryToolSupport(cfg, cfg.Tools.MCP.Discovery.UseBM25)
 		case "web_search":
 			status, reasonCode = resolveWebSearchToolSupport(cfg)
-		case "i2c", "spi":
+		case "i2c", "spi", "gpio", "pwm", "onewire", "canbus":
 			status, reasonCode = resolveHardwareToolSupport(cfg.Tools.IsToolEnabled(entry.ConfigKey))
 		case "serial":
 			status, reasonCode = resolveSerialToolSupport(cfg.Tools.IsToolEnabled(entry.ConfigKey))
@@ -342,18 +408,30 @@ func applyToolState(cfg *config.Config, toolName string, enabled bool) error {
 		cfg.Tools.WriteFile.Enabled = enabled
 	case "list_dir":
 		cfg.Tools.ListDir.Enabled = enabled
+	case "grep_files":
+		cfg.Tools.GrepFiles.Enabled = enabled
+	case "glob_files":
+		cfg.Tools.GlobFiles.Enabled = enabled
 	case "edit_file":
 		cfg.Tools.EditFile.Enabled = enabled
 	case "append_file":
 		cfg.Tools.AppendFile.Enabled = enabled
+	case "apply_patch":
+		cfg.Tools.ApplyPatch.Enabled = enabled
 	case "exec":
 		cfg.Tools.Exec.Enabled = enabled
+	case "run_script":
+		cfg.Tools.RunScript.Enabled = enabled
 	case "cron":
 		cfg.Tools.Cron.Enabled = enabled
+	case "home":
+		cfg.Tools.Home.Enabled = enabled
 	case "web_search":
 		cfg.Tools.Web.Enabled = enabled
 	case "web_fetch":
 		cfg.Tools.WebFetch.Enabled = enabled
+	case "http_request":
+		cfg.Tools.HTTPRequest.Enabled = enabled
 	case "message":
 		cfg.Tools.Message.Enabled = enabled
 	case "send_file":
@@ -385,6 +463,16 @@ func applyToolState(cfg *config.Config, toolName string, enabled bool) error {
 		cfg.Tools.SPI.Enabled = enabled
 	case "serial":
 		cfg.Tools.Serial.Enabled = enabled
+	case "gpio":
+		cfg.Tools.GPIO.Enabled = enabled
+	case "pwm":
+		cfg.Tools.PWM.Enabled = enabled
+	case "onewire":
+		cfg.Tools.OneWire.Enabled = enabled
+	case "modbus":
+		cfg.Tools.Modbus.Enabled = enabled
+	case "canbus":
+		cfg.Tools.CANBus.Enabled = enabled
 	case "tool_search_tool_regex":
 		cfg.Tools.MCP.Discovery.UseRegex = enabled
 		if enabled {